@@ -35,6 +35,10 @@ type Account struct {
 // AccountKeyWeightThreshold is the total key weight required to authorize access to an account.
 const AccountKeyWeightThreshold int = 1000
 
+// ErrInsufficientKeyWeight is returned when the keys that signed a transaction for an
+// account do not reach AccountKeyWeightThreshold.
+var ErrInsufficientKeyWeight = errors.New("flow: insufficient key weight")
+
 // An AccountKey is a public key associated with an account.
 type AccountKey struct {
 	Index          int
@@ -83,6 +87,26 @@ func (a *AccountKey) SetWeight(weight int) *AccountKey {
 	return a
 }
 
+// NewProposerOnlyKey returns an account key of weight 0 with the given public key.
+//
+// A key of weight 0 can sign as the proposer of a transaction, which requires the
+// signature of the proposal key whatever its weight, but it does not count towards
+// the weight required for the account to authorize or pay for a transaction. This
+// makes it safe to hand out to the many concurrent senders that need distinct
+// proposal keys.
+func NewProposerOnlyKey(pubKey crypto.PublicKey, hashAlgo crypto.HashAlgorithm) *AccountKey {
+	return NewAccountKey().
+		SetPublicKey(pubKey).
+		SetHashAlgo(hashAlgo).
+		SetWeight(0)
+}
+
+// IsProposerOnly returns true if this key has weight 0, and therefore can only be
+// used as a proposal key.
+func (a AccountKey) IsProposerOnly() bool {
+	return a.Weight == 0
+}
+
 // Encode returns the canonical RLP byte representation of this account key.
 func (a AccountKey) Encode() []byte {
 	temp := accountKeyWrapper{
@@ -98,7 +122,9 @@ func (a AccountKey) Encode() []byte {
 //
 // An account key can be invalid for the following reasons:
 // - It specifies an incompatible signature/hash algorithm pairing
-// - (TODO) It specifies a negative key weight
+// - It specifies a key weight that is negative or above AccountKeyWeightThreshold
+//
+// A key weight of 0 is valid, see NewProposerOnlyKey.
 func (a AccountKey) Validate() error {
 	if !crypto.CompatibleAlgorithms(a.SigAlgo, a.HashAlgo) {
 		return errors.Errorf(
@@ -107,6 +133,15 @@ func (a AccountKey) Validate() error {
 			a.HashAlgo,
 		)
 	}
+
+	if a.Weight < 0 || a.Weight > AccountKeyWeightThreshold {
+		return errors.Errorf(
+			"key weight (%d) must be between 0 and %d",
+			a.Weight,
+			AccountKeyWeightThreshold,
+		)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,93 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flow_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestAccountKey_ProposerOnly(t *testing.T) {
+	generated := test.AccountKeyGenerator().New()
+
+	key := flow.NewProposerOnlyKey(generated.PublicKey, crypto.SHA3_256)
+	assert.True(t, key.IsProposerOnly())
+	assert.NoError(t, key.Validate())
+
+	decoded, err := flow.DecodeAccountKey(key.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, 0, decoded.Weight)
+	assert.True(t, decoded.IsProposerOnly())
+
+	assert.Error(t, key.SetWeight(-1).Validate())
+	assert.Error(t, key.SetWeight(flow.AccountKeyWeightThreshold+1).Validate())
+	assert.NoError(t, key.SetWeight(flow.AccountKeyWeightThreshold).Validate())
+	assert.False(t, key.IsProposerOnly())
+}
+
+func TestTransaction_ValidateKeyWeights(t *testing.T) {
+	keys := test.AccountKeyGenerator()
+
+	full := keys.New()
+	full.Index = 0
+
+	proposer := keys.New()
+	proposer.Index = 1
+	proposer.Weight = 0
+
+	account := &flow.Account{
+		Address: flow.HexToAddress("01"),
+		Keys:    []*flow.AccountKey{full, proposer},
+	}
+
+	newTransaction := func() *flow.Transaction {
+		return flow.NewTransaction().
+			SetProposalKey(account.Address, proposer.Index, 0).
+			SetPayer(account.Address).
+			AddAuthorizer(account.Address)
+	}
+
+	t.Run("Proposer-only key", func(t *testing.T) {
+		tx := newTransaction().AddEnvelopeSignature(account.Address, proposer.Index, []byte{1})
+
+		err := tx.ValidateKeyWeights(account)
+		assert.True(t, errors.Is(err, flow.ErrInsufficientKeyWeight))
+	})
+
+	t.Run("Full weight key", func(t *testing.T) {
+		tx := newTransaction().
+			AddEnvelopeSignature(account.Address, proposer.Index, []byte{1}).
+			AddEnvelopeSignature(account.Address, full.Index, []byte{2})
+
+		assert.NoError(t, tx.ValidateKeyWeights(account))
+	})
+
+	t.Run("Unknown account", func(t *testing.T) {
+		tx := newTransaction().AddEnvelopeSignature(account.Address, full.Index, []byte{2})
+
+		assert.Error(t, tx.ValidateKeyWeights())
+	})
+}
@@ -0,0 +1,425 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package activity compiles the history of an account from the events emitted on
+// chain: token transfers, NFT movements, key changes and contract updates.
+//
+// ScanKeyUsage complements the feed with the usage of each account key in the
+// transactions of a range of blocks, to support key rotation and the detection of
+// compromised keys.
+//
+// TraceProvenance reconstructs the ownership history of an NFT, from its mint to its
+// current owner, so that marketplaces can verify where a token comes from.
+package activity
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+// A Kind is the kind of an activity entry.
+type Kind string
+
+const (
+	// TokensSent is a fungible token withdrawal from the account.
+	TokensSent Kind = "tokensSent"
+	// TokensReceived is a fungible token deposit to the account.
+	TokensReceived Kind = "tokensReceived"
+	// NFTSent is an NFT withdrawal from the account.
+	NFTSent Kind = "nftSent"
+	// NFTReceived is an NFT deposit to the account.
+	NFTReceived Kind = "nftReceived"
+	// KeyAdded is a key added to the account.
+	KeyAdded Kind = "keyAdded"
+	// KeyRemoved is a key removed from the account.
+	KeyRemoved Kind = "keyRemoved"
+	// ContractUpdated is a contract added to or updated in the account.
+	ContractUpdated Kind = "contractUpdated"
+)
+
+// An Entry is an activity of an account, derived from an event.
+type Entry struct {
+	Kind Kind `json:"kind"`
+	// Height is the height of the block the event was emitted in.
+	Height uint64 `json:"height"`
+	// BlockID is the ID of the block the event was emitted in.
+	BlockID flow.Identifier `json:"blockID"`
+	// Timestamp is the timestamp of the block the event was emitted in.
+	Timestamp time.Time `json:"timestamp"`
+	// TransactionID is the ID of the transaction that emitted the event.
+	TransactionID flow.Identifier `json:"transactionID"`
+	// TransactionIndex is the index of the transaction within its block.
+	TransactionIndex int `json:"transactionIndex"`
+	// EventIndex is the index of the event within its transaction.
+	EventIndex int `json:"eventIndex"`
+	// Contract is the qualified identifier of the token contract of transfers,
+	// e.g. "A.1654653399040a61.FlowToken".
+	Contract string `json:"contract,omitempty"`
+	// Amount is the amount of fungible token transfers.
+	Amount cadence.UFix64 `json:"amount,omitempty"`
+	// TokenID is the ID of the NFT of NFT movements.
+	TokenID uint64 `json:"tokenID,omitempty"`
+	// Counterparty is the other account of a transfer, if the transaction moved the
+	// token between two accounts. It is empty for mints, burns and transfers that
+	// cannot be paired.
+	Counterparty flow.Address `json:"counterparty"`
+	// PublicKey is the encoded account key of key changes, as reported by the event.
+	PublicKey []byte `json:"publicKey,omitempty"`
+	// ContractName is the name of the contract of contract updates, if reported by
+	// the event.
+	ContractName string `json:"contractName,omitempty"`
+}
+
+// A Collection is a non-fungible token contract.
+type Collection struct {
+	// Contract is the name of the NFT contract.
+	Contract string
+	// Address is the address of the NFT contract.
+	Address flow.Address
+}
+
+// Options configures the events included in a feed.
+type Options struct {
+	// Tokens are the fungible tokens whose transfers are included.
+	Tokens []transfer.Token
+	// Collections are the NFT contracts whose movements are included.
+	Collections []Collection
+	// MaxRange is the maximum number of blocks requested in a single event query.
+	//
+	// Defaults to 250 blocks, the limit enforced by Access nodes.
+	MaxRange uint64
+}
+
+const defaultMaxRange = 250
+
+// eventKind is how an event type is interpreted.
+type eventKind int
+
+const (
+	tokensWithdrawn eventKind = iota
+	tokensDeposited
+	nftWithdrawn
+	nftDeposited
+	nftMinted
+	keyAdded
+	keyRemoved
+	contractUpdated
+)
+
+type eventSpec struct {
+	kind     eventKind
+	contract string
+}
+
+// qualifiedIdentifier returns the identifier of a contract deployed at an address.
+func qualifiedIdentifier(address flow.Address, contract string) string {
+	return fmt.Sprintf("A.%s.%s", address.Hex(), contract)
+}
+
+func (o Options) eventSpecs() map[string]eventSpec {
+	specs := map[string]eventSpec{
+		flow.EventAccountKeyAdded:        {kind: keyAdded},
+		flow.EventAccountKeyRemoved:      {kind: keyRemoved},
+		flow.EventAccountUpdated:         {kind: contractUpdated},
+		flow.EventAccountContractAdded:   {kind: contractUpdated},
+		flow.EventAccountContractUpdated: {kind: contractUpdated},
+	}
+
+	for _, token := range o.Tokens {
+		contract := qualifiedIdentifier(token.Address, token.Contract)
+		specs[contract+".TokensWithdrawn"] = eventSpec{kind: tokensWithdrawn, contract: contract}
+		specs[contract+".TokensDeposited"] = eventSpec{kind: tokensDeposited, contract: contract}
+	}
+
+	for _, collection := range o.Collections {
+		contract := qualifiedIdentifier(collection.Address, collection.Contract)
+		specs[contract+".Withdraw"] = eventSpec{kind: nftWithdrawn, contract: contract}
+		specs[contract+".Deposit"] = eventSpec{kind: nftDeposited, contract: contract}
+	}
+
+	return specs
+}
+
+// record is an event with its block and interpretation.
+type record struct {
+	spec   eventSpec
+	block  flow.BlockEvents
+	event  flow.Event
+	fields map[string]cadence.Value
+}
+
+// Feed returns the activity of an account in the sealed blocks between the start and
+// end heights (inclusive), ordered as the events were emitted.
+//
+// Token transfers are paired within each transaction: the counterparty of a
+// withdrawal is the account of the matching deposit of the same token, and the
+// reverse for deposits.
+func Feed(
+	ctx context.Context,
+	c flow.Client,
+	address flow.Address,
+	startHeight uint64,
+	endHeight uint64,
+	options Options,
+) ([]Entry, error) {
+	records, err := fetchRecords(ctx, c, options.eventSpecs(), startHeight, endHeight, options.MaxRange)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make(map[flow.Identifier][]*record)
+	for _, r := range records {
+		transactions[r.event.TransactionID] = append(transactions[r.event.TransactionID], r)
+	}
+
+	entries := make([]Entry, 0)
+
+	for _, r := range records {
+		entry := Entry{
+			Height:           r.block.Height,
+			BlockID:          r.block.BlockID,
+			Timestamp:        r.block.BlockTimestamp,
+			TransactionID:    r.event.TransactionID,
+			TransactionIndex: r.event.TransactionIndex,
+			EventIndex:       r.event.EventIndex,
+			Contract:         r.spec.contract,
+		}
+
+		siblings := transactions[r.event.TransactionID]
+
+		switch r.spec.kind {
+		case tokensWithdrawn, tokensDeposited:
+			amount, _ := r.fields["amount"].(cadence.UFix64)
+			entry.Amount = amount
+
+			from := r.spec.kind == tokensWithdrawn
+			if !r.is(address, from) {
+				continue
+			}
+
+			entry.Kind = TokensSent
+			if !from {
+				entry.Kind = TokensReceived
+			}
+
+			entry.Counterparty = r.counterparty(siblings, "amount", amount)
+		case nftWithdrawn, nftDeposited:
+			id, _ := r.fields["id"].(cadence.UInt64)
+			entry.TokenID = uint64(id)
+
+			from := r.spec.kind == nftWithdrawn
+			if !r.is(address, from) {
+				continue
+			}
+
+			entry.Kind = NFTSent
+			if !from {
+				entry.Kind = NFTReceived
+			}
+
+			entry.Counterparty = r.counterparty(siblings, "id", id)
+		case keyAdded, keyRemoved:
+			if r.address("address") != address {
+				continue
+			}
+
+			entry.Kind = KeyAdded
+			if r.spec.kind == keyRemoved {
+				entry.Kind = KeyRemoved
+			}
+
+			entry.PublicKey = bytesValue(r.fields["publicKey"])
+		case contractUpdated:
+			if r.address("address") != address {
+				continue
+			}
+
+			entry.Kind = ContractUpdated
+			if name, ok := r.fields["contract"].(cadence.String); ok {
+				entry.ContractName = string(name)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// fetchRecords returns the events of the given types emitted in the sealed blocks
+// between the start and end heights (inclusive), ordered as they were emitted.
+func fetchRecords(
+	ctx context.Context,
+	c flow.Client,
+	specs map[string]eventSpec,
+	startHeight uint64,
+	endHeight uint64,
+	maxRange uint64,
+) ([]*record, error) {
+	if maxRange == 0 {
+		maxRange = defaultMaxRange
+	}
+
+	eventTypes := make([]string, 0, len(specs))
+	for eventType := range specs {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+
+	var records []*record
+
+	for _, eventType := range eventTypes {
+		for start := startHeight; start <= endHeight; start += maxRange {
+			end := start + maxRange - 1
+			if end > endHeight || end < start {
+				end = endHeight
+			}
+
+			blocks, err := c.GetEventsForHeightRange(ctx, flow.EventRangeQuery{
+				Type:        eventType,
+				StartHeight: start,
+				EndHeight:   end,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, block := range blocks {
+				for _, event := range block.Events {
+					value, err := event.DecodeValue()
+					if err != nil {
+						return nil, err
+					}
+
+					records = append(records, &record{
+						spec:   specs[event.Type],
+						block:  block,
+						event:  event,
+						fields: fields(value),
+					})
+				}
+			}
+
+			if end == endHeight {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.block.Height != b.block.Height {
+			return a.block.Height < b.block.Height
+		}
+		if a.event.TransactionIndex != b.event.TransactionIndex {
+			return a.event.TransactionIndex < b.event.TransactionIndex
+		}
+		return a.event.EventIndex < b.event.EventIndex
+	})
+
+	return records, nil
+}
+
+// is returns true if the account of a transfer event is the given address: the
+// "from" field of withdrawals, or the "to" field of deposits.
+func (r *record) is(address flow.Address, withdrawal bool) bool {
+	if withdrawal {
+		return r.address("from") == address
+	}
+	return r.address("to") == address
+}
+
+// counterparty returns the account of the first event of the same transaction that
+// moves the same value of the same contract in the opposite direction.
+func (r *record) counterparty(siblings []*record, field string, value cadence.Value) flow.Address {
+	withdrawal := r.spec.kind == tokensWithdrawn || r.spec.kind == nftWithdrawn
+
+	for _, s := range siblings {
+		if s == r || s.spec.contract != r.spec.contract || s.spec.kind == r.spec.kind {
+			continue
+		}
+
+		if s.fields[field] != value {
+			continue
+		}
+
+		if withdrawal {
+			return s.address("to")
+		}
+		return s.address("from")
+	}
+
+	return flow.EmptyAddress
+}
+
+// address returns the value of an address field, which may be optional.
+func (r *record) address(name string) flow.Address {
+	value := r.fields[name]
+	if optional, ok := value.(cadence.Optional); ok {
+		value = optional.Value
+	}
+
+	if address, ok := value.(cadence.Address); ok {
+		return flow.BytesToAddress(address.Bytes())
+	}
+
+	return flow.EmptyAddress
+}
+
+// fields returns the fields of an event by name.
+func fields(event cadence.Event) map[string]cadence.Value {
+	fields := make(map[string]cadence.Value, len(event.Fields))
+
+	if event.EventType == nil {
+		return fields
+	}
+
+	for i, field := range event.EventType.Fields {
+		if i < len(event.Fields) {
+			fields[field.Identifier] = event.Fields[i]
+		}
+	}
+
+	return fields
+}
+
+// bytesValue returns the bytes of a [UInt8] array value.
+func bytesValue(value cadence.Value) []byte {
+	array, ok := value.(cadence.Array)
+	if !ok {
+		return nil
+	}
+
+	b := make([]byte, 0, len(array.Values))
+	for _, v := range array.Values {
+		u, ok := v.(cadence.UInt8)
+		if !ok {
+			return nil
+		}
+		b = append(b, byte(u))
+	}
+
+	return b
+}
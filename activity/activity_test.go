@@ -0,0 +1,185 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package activity_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/activity"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+type field struct {
+	name  string
+	typ   cadence.Type
+	value cadence.Value
+}
+
+func newEvent(typeID string, txID byte, txIndex, eventIndex int, fields ...field) flow.Event {
+	eventType := &cadence.EventType{TypeID: typeID, Identifier: typeID}
+	values := make([]cadence.Value, len(fields))
+	for i, f := range fields {
+		eventType.Fields = append(eventType.Fields, cadence.Field{Identifier: f.name, Type: f.typ})
+		values[i] = f.value
+	}
+
+	return flow.Event{
+		Type:             typeID,
+		TransactionID:    flow.Identifier{txID},
+		TransactionIndex: txIndex,
+		EventIndex:       eventIndex,
+		Value:            cadence.NewEvent(values).WithType(eventType),
+	}
+}
+
+func optionalAddress(address flow.Address) field {
+	return field{typ: cadence.OptionalType{Type: cadence.AddressType{}}, value: cadence.NewOptional(cadence.NewAddress(address))}
+}
+
+func named(name string, f field) field {
+	f.name = name
+	return f
+}
+
+// eventNode is an Access API node that returns events by height.
+type eventNode map[uint64][]flow.Event
+
+func (n eventNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	if method != client.MethodGetEventsForHeightRange {
+		return nil
+	}
+
+	req := args.(*access.GetEventsForHeightRangeRequest)
+	res := reply.(*access.EventsResponse)
+
+	for height := req.StartHeight; height <= req.EndHeight; height++ {
+		result := &access.EventsResponse_Result{
+			BlockId:        []byte{byte(height)},
+			BlockHeight:    height,
+			BlockTimestamp: ptypes.TimestampNow(),
+		}
+
+		for _, event := range n[height] {
+			if event.Type != req.Type {
+				continue
+			}
+
+			m, err := convert.EventToMessage(event)
+			if err != nil {
+				return err
+			}
+			result.Events = append(result.Events, m)
+		}
+
+		res.Results = append(res.Results, result)
+	}
+
+	return nil
+}
+
+func TestFeed(t *testing.T) {
+	token, err := transfer.FlowToken(flow.Emulator)
+	require.NoError(t, err)
+
+	collection := activity.Collection{Contract: "Kitty", Address: flow.HexToAddress("0f")}
+
+	alice := flow.HexToAddress("01")
+	bob := flow.HexToAddress("02")
+	carol := flow.HexToAddress("03")
+
+	tokenEvent := "A." + token.Address.Hex() + ".FlowToken."
+	nftEvent := "A." + collection.Address.Hex() + ".Kitty."
+	amount, _ := cadence.NewUFix64("12.5")
+	otherAmount, _ := cadence.NewUFix64("1.0")
+	ufix := func(v cadence.UFix64) field { return field{name: "amount", typ: cadence.UFix64Type{}, value: v} }
+
+	node := eventNode{
+		2: {
+			// alice sends tokens to bob
+			newEvent(tokenEvent+"TokensWithdrawn", 1, 0, 0, ufix(amount), named("from", optionalAddress(alice))),
+			newEvent(tokenEvent+"TokensDeposited", 1, 0, 1, ufix(amount), named("to", optionalAddress(bob))),
+			// bob sends tokens to carol
+			newEvent(tokenEvent+"TokensWithdrawn", 2, 1, 0, ufix(otherAmount), named("from", optionalAddress(bob))),
+			newEvent(tokenEvent+"TokensDeposited", 2, 1, 1, ufix(otherAmount), named("to", optionalAddress(carol))),
+		},
+		300: {
+			// a kitty is minted to alice
+			newEvent(nftEvent+"Deposit", 3, 0, 0,
+				field{name: "id", typ: cadence.UInt64Type{}, value: cadence.NewUInt64(7)},
+				named("to", optionalAddress(alice)),
+			),
+			// alice adds a key
+			newEvent(flow.EventAccountKeyAdded, 4, 1, 0,
+				field{name: "address", typ: cadence.AddressType{}, value: cadence.NewAddress(alice)},
+				field{
+					name:  "publicKey",
+					typ:   cadence.VariableSizedArrayType{ElementType: cadence.UInt8Type{}},
+					value: cadence.NewArray([]cadence.Value{cadence.NewUInt8(1), cadence.NewUInt8(2)}),
+				},
+			),
+		},
+	}
+
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	entries, err := activity.Feed(context.Background(), c, alice, 1, 400, activity.Options{
+		Tokens:      []transfer.Token{token},
+		Collections: []activity.Collection{collection},
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, activity.TokensSent, entries[0].Kind)
+	assert.Equal(t, uint64(2), entries[0].Height)
+	assert.Equal(t, "A."+token.Address.Hex()+".FlowToken", entries[0].Contract)
+	assert.Equal(t, amount, entries[0].Amount)
+	assert.Equal(t, bob, entries[0].Counterparty)
+
+	assert.Equal(t, activity.NFTReceived, entries[1].Kind)
+	assert.Equal(t, uint64(7), entries[1].TokenID)
+	assert.Equal(t, flow.EmptyAddress, entries[1].Counterparty)
+
+	assert.Equal(t, activity.KeyAdded, entries[2].Kind)
+	assert.Equal(t, []byte{1, 2}, entries[2].PublicKey)
+
+	entries, err = activity.Feed(context.Background(), c, carol, 1, 400, activity.Options{
+		Tokens: []transfer.Token{token},
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, activity.TokensReceived, entries[0].Kind)
+	assert.Equal(t, bob, entries[0].Counterparty)
+}
@@ -0,0 +1,240 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package activity
+
+import (
+	"context"
+	"sort"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// KeyUsage is the usage of an account key in a range of blocks.
+type KeyUsage struct {
+	KeyIndex int `json:"keyIndex"`
+	// Transactions is the number of transactions the key proposed or signed.
+	Transactions int `json:"transactions"`
+	// Proposed is the number of transactions the key was the proposal key of.
+	Proposed int `json:"proposed"`
+	// Paid is the number of transactions the key signed as payer.
+	Paid int `json:"paid"`
+	// Authorized is the number of transactions the key signed as authorizer.
+	Authorized int `json:"authorized"`
+	// FirstUsedHeight and LastUsedHeight are the heights of the blocks of the first
+	// and last transactions the key proposed or signed.
+	FirstUsedHeight uint64 `json:"firstUsedHeight"`
+	LastUsedHeight  uint64 `json:"lastUsedHeight"`
+	// LastTransactionID is the ID of the last transaction the key proposed or signed.
+	LastTransactionID flow.Identifier `json:"lastTransactionID"`
+}
+
+// A KeyUsageReport is the usage of the keys of an account in a range of blocks.
+type KeyUsageReport struct {
+	Address     flow.Address `json:"address"`
+	StartHeight uint64       `json:"startHeight"`
+	EndHeight   uint64       `json:"endHeight"`
+	// Transactions is the number of transactions the account proposed, paid for
+	// or authorized.
+	Transactions int `json:"transactions"`
+	// Keys is the usage of each key that was used, ordered by key index.
+	Keys []KeyUsage `json:"keys"`
+}
+
+// Key returns the usage of the key with the given index, and false if the key was
+// not used.
+func (r KeyUsageReport) Key(keyIndex int) (KeyUsage, bool) {
+	for _, usage := range r.Keys {
+		if usage.KeyIndex == keyIndex {
+			return usage, true
+		}
+	}
+
+	return KeyUsage{}, false
+}
+
+// Unused returns the indexes of the keys of the account that are not revoked and
+// were not used, e.g. as candidates for removal.
+func (r KeyUsageReport) Unused(account *flow.Account) []int {
+	unused := make([]int, 0)
+
+	for _, key := range account.Keys {
+		if key.Revoked {
+			continue
+		}
+
+		if _, ok := r.Key(key.Index); !ok {
+			unused = append(unused, key.Index)
+		}
+	}
+
+	return unused
+}
+
+// Unexpected returns the usage of the keys that are not in the expected set, e.g.
+// to detect transactions signed with a key that should have been retired.
+func (r KeyUsageReport) Unexpected(expected ...int) []KeyUsage {
+	allowed := make(map[int]bool, len(expected))
+	for _, keyIndex := range expected {
+		allowed[keyIndex] = true
+	}
+
+	unexpected := make([]KeyUsage, 0)
+	for _, usage := range r.Keys {
+		if !allowed[usage.KeyIndex] {
+			unexpected = append(unexpected, usage)
+		}
+	}
+
+	return unexpected
+}
+
+// ScanKeyUsage reports which keys of an account proposed, paid for or authorized the
+// transactions of the blocks between the start and end heights (inclusive).
+//
+// Every block, collection and transaction in the range is requested, so scans of
+// long ranges are slow and should be run in the background.
+func ScanKeyUsage(
+	ctx context.Context,
+	c flow.Client,
+	address flow.Address,
+	startHeight uint64,
+	endHeight uint64,
+) (*KeyUsageReport, error) {
+	report := &KeyUsageReport{
+		Address:     address,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+
+	keys := make(map[int]*KeyUsage)
+
+	for height := startHeight; height <= endHeight; height++ {
+		block, err := c.GetBlockByHeight(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, guarantee := range block.CollectionGuarantees {
+			collection, err := c.GetCollection(ctx, guarantee.CollectionID)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, txID := range collection.TransactionIDs {
+				tx, err := c.GetTransaction(ctx, txID)
+				if err != nil {
+					return nil, err
+				}
+
+				if recordKeyUsage(keys, address, height, txID, tx) {
+					report.Transactions++
+				}
+			}
+		}
+
+		if height == endHeight {
+			break
+		}
+	}
+
+	report.Keys = make([]KeyUsage, 0, len(keys))
+	for _, usage := range keys {
+		report.Keys = append(report.Keys, *usage)
+	}
+
+	sort.Slice(report.Keys, func(i, j int) bool {
+		return report.Keys[i].KeyIndex < report.Keys[j].KeyIndex
+	})
+
+	return report, nil
+}
+
+// recordKeyUsage records the keys of the account used by a transaction, and returns
+// true if the account takes part in the transaction.
+func recordKeyUsage(
+	keys map[int]*KeyUsage,
+	address flow.Address,
+	height uint64,
+	txID flow.Identifier,
+	tx *flow.Transaction,
+) bool {
+	used := make(map[int]*KeyUsage)
+
+	use := func(keyIndex int) *KeyUsage {
+		usage, ok := used[keyIndex]
+		if !ok {
+			usage = &KeyUsage{}
+			used[keyIndex] = usage
+		}
+		return usage
+	}
+
+	if tx.ProposalKey.Address == address {
+		use(tx.ProposalKey.KeyIndex).Proposed++
+	}
+
+	if tx.Payer == address {
+		for _, sig := range tx.EnvelopeSignatures {
+			if sig.Address == address {
+				use(sig.KeyIndex).Paid++
+			}
+		}
+	}
+
+	for _, authorizer := range tx.Authorizers {
+		if authorizer != address {
+			continue
+		}
+
+		for _, sig := range tx.PayloadSignatures {
+			if sig.Address == address {
+				use(sig.KeyIndex).Authorized++
+			}
+		}
+
+		break
+	}
+
+	for keyIndex, tally := range used {
+		usage, ok := keys[keyIndex]
+		if !ok {
+			usage = &KeyUsage{KeyIndex: keyIndex, FirstUsedHeight: height}
+			keys[keyIndex] = usage
+		}
+
+		usage.Transactions++
+		usage.Proposed += tally.Proposed
+		usage.Paid += tally.Paid
+		usage.Authorized += tally.Authorized
+		usage.LastUsedHeight = height
+		usage.LastTransactionID = txID
+	}
+
+	if len(used) > 0 || tx.Payer == address {
+		return true
+	}
+
+	for _, authorizer := range tx.Authorizers {
+		if authorizer == address {
+			return true
+		}
+	}
+
+	return false
+}
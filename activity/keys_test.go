@@ -0,0 +1,114 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package activity_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/activity"
+	"github.com/portto/blocto-flow-go-sdk/client/mocks"
+)
+
+func TestScanKeyUsage(t *testing.T) {
+	account := flow.HexToAddress("01")
+	other := flow.HexToAddress("02")
+
+	// account proposes, pays for and authorizes with key 0
+	own := flow.NewTransaction().
+		SetProposalKey(account, 0, 1).
+		SetPayer(account).
+		AddAuthorizer(account).
+		AddEnvelopeSignature(account, 0, []byte{1})
+
+	// account pays with key 1 and authorizes with key 2
+	sponsored := flow.NewTransaction().
+		SetProposalKey(other, 0, 1).
+		SetPayer(account).
+		AddAuthorizer(account).
+		AddPayloadSignature(other, 0, []byte{2}).
+		AddPayloadSignature(account, 2, []byte{3}).
+		AddEnvelopeSignature(account, 1, []byte{4})
+
+	unrelated := flow.NewTransaction().
+		SetProposalKey(other, 0, 2).
+		SetPayer(other).
+		AddAuthorizer(other).
+		AddEnvelopeSignature(other, 0, []byte{5})
+
+	c := mocks.New()
+	for _, txs := range [][]*flow.Transaction{{own}, {unrelated}, {sponsored, own}} {
+		collection := &flow.Collection{}
+		for _, tx := range txs {
+			c.AddTransaction(tx, &flow.TransactionResult{Status: flow.TransactionStatusSealed})
+			collection.TransactionIDs = append(collection.TransactionIDs, tx.ID())
+		}
+		c.AddCollection(collection)
+
+		block := c.AddNextBlock()
+		block.CollectionGuarantees = []*flow.CollectionGuarantee{{CollectionID: collection.ID()}}
+	}
+
+	report, err := activity.ScanKeyUsage(context.Background(), c, account, 0, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Transactions)
+	assert.Equal(t, []activity.KeyUsage{
+		{
+			KeyIndex:          0,
+			Transactions:      2,
+			Proposed:          2,
+			Paid:              2,
+			FirstUsedHeight:   0,
+			LastUsedHeight:    2,
+			LastTransactionID: own.ID(),
+		},
+		{
+			KeyIndex:          1,
+			Transactions:      1,
+			Paid:              1,
+			FirstUsedHeight:   2,
+			LastUsedHeight:    2,
+			LastTransactionID: sponsored.ID(),
+		},
+		{
+			KeyIndex:          2,
+			Transactions:      1,
+			Authorized:        1,
+			FirstUsedHeight:   2,
+			LastUsedHeight:    2,
+			LastTransactionID: sponsored.ID(),
+		},
+	}, report.Keys)
+
+	assert.Equal(t, []int{3}, report.Unused(&flow.Account{
+		Address: account,
+		Keys: []*flow.AccountKey{
+			{Index: 0}, {Index: 1}, {Index: 2}, {Index: 3}, {Index: 4, Revoked: true},
+		},
+	}))
+
+	unexpected := report.Unexpected(0, 1)
+	require.Len(t, unexpected, 1)
+	assert.Equal(t, 2, unexpected[0].KeyIndex)
+}
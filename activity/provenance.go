@@ -0,0 +1,269 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrProvenanceGap is returned by Provenance.Verify if the ownership history of an
+// NFT is not continuous.
+var ErrProvenanceGap = errcode.New(errcode.ProvenanceGap, "activity: provenance has a gap")
+
+// A StepKind is the kind of a step in the ownership history of an NFT.
+type StepKind string
+
+const (
+	// Minted is the creation of the NFT.
+	Minted StepKind = "minted"
+	// Transferred is a withdrawal and a deposit of the NFT in the same transaction.
+	Transferred StepKind = "transferred"
+	// Withdrawn is a withdrawal of the NFT that is not deposited in the same
+	// transaction, e.g. to a marketplace escrow or to be burned.
+	Withdrawn StepKind = "withdrawn"
+	// Deposited is a deposit of the NFT that was not withdrawn in the same
+	// transaction.
+	Deposited StepKind = "deposited"
+)
+
+// A ProvenanceStep is a movement of an NFT.
+type ProvenanceStep struct {
+	Kind StepKind `json:"kind"`
+	// From is the account the NFT was withdrawn from. It is empty for mints and
+	// deposits.
+	From flow.Address `json:"from"`
+	// To is the account the NFT was deposited to. It is empty for withdrawals, and
+	// for mints that do not deposit the NFT in the same transaction.
+	To flow.Address `json:"to"`
+	// Height is the height of the block of the step.
+	Height uint64 `json:"height"`
+	// BlockID is the ID of the block of the step.
+	BlockID flow.Identifier `json:"blockID"`
+	// Timestamp is the timestamp of the block of the step.
+	Timestamp time.Time `json:"timestamp"`
+	// TransactionID is the ID of the transaction of the step.
+	TransactionID flow.Identifier `json:"transactionID"`
+}
+
+// Provenance is the ownership history of an NFT.
+type Provenance struct {
+	// Contract is the qualified identifier of the NFT contract, e.g.
+	// "A.0b2a3299cc857e29.TopShot".
+	Contract string `json:"contract"`
+	// TokenID is the ID of the NFT.
+	TokenID uint64 `json:"tokenID"`
+	// Steps are the movements of the NFT, ordered as they happened.
+	Steps []ProvenanceStep `json:"steps"`
+	// Owner is the account that holds the NFT after the last step. It is empty if
+	// the NFT was last withdrawn without being deposited.
+	Owner flow.Address `json:"owner"`
+}
+
+// ProvenanceOptions configures how the history of an NFT is reconstructed.
+type ProvenanceOptions struct {
+	// MintEvent is the name of the event emitted by the NFT contract when it mints
+	// an NFT, e.g. "Minted", with the ID of the NFT in an "id" field.
+	//
+	// If empty, the first deposit of the NFT that is not paired with a withdrawal is
+	// considered its mint.
+	MintEvent string
+	// MaxRange is the maximum number of blocks requested in a single event query.
+	//
+	// Defaults to 250 blocks, the limit enforced by Access nodes.
+	MaxRange uint64
+}
+
+// Mint returns the step that minted the NFT, and false if the mint is not in the
+// history, e.g. because it happened before the start height.
+func (p *Provenance) Mint() (ProvenanceStep, bool) {
+	if len(p.Steps) == 0 || p.Steps[0].Kind != Minted {
+		return ProvenanceStep{}, false
+	}
+	return p.Steps[0], true
+}
+
+// Verify checks that the history starts with the mint of the NFT and that each step
+// moves the NFT from the account that held it after the previous step.
+//
+// A marketplace should verify the provenance of an NFT before it presents the
+// history as complete.
+func (p *Provenance) Verify() error {
+	if _, ok := p.Mint(); !ok {
+		return fmt.Errorf("%w: history of NFT %d does not start with its mint", ErrProvenanceGap, p.TokenID)
+	}
+
+	var holder flow.Address
+
+	for i, step := range p.Steps {
+		if i > 0 && step.Kind == Minted {
+			return fmt.Errorf(
+				"%w: NFT %d minted again in transaction %s",
+				ErrProvenanceGap,
+				p.TokenID,
+				step.TransactionID,
+			)
+		}
+
+		if step.From != holder {
+			return fmt.Errorf(
+				"%w: NFT %d moved from %s in transaction %s, but was held by %s",
+				ErrProvenanceGap,
+				p.TokenID,
+				step.From,
+				step.TransactionID,
+				holder,
+			)
+		}
+
+		holder = step.To
+	}
+
+	return nil
+}
+
+// TraceProvenance reconstructs the ownership history of an NFT from the events of
+// its contract in the sealed blocks between the start and end heights (inclusive):
+// its mint, its transfers and its current owner.
+//
+// Withdrawals and deposits of the NFT are paired within each transaction into
+// transfers. The history is returned as found, and should be checked with Verify.
+//
+// Access nodes only serve the events of their spork. To trace an NFT minted in a
+// previous spork, query through a spork router and start at the root height of the
+// first spork:
+//
+//	router, err := client.NewSporkRouter(sporks)
+//	...
+//	provenance, err := activity.TraceProvenance(
+//		ctx,
+//		client.NewFromInvoker(router),
+//		collection,
+//		tokenID,
+//		router.Sporks()[0].RootHeight,
+//		latestHeight,
+//		activity.ProvenanceOptions{},
+//	)
+func TraceProvenance(
+	ctx context.Context,
+	c flow.Client,
+	collection Collection,
+	tokenID uint64,
+	startHeight uint64,
+	endHeight uint64,
+	options ProvenanceOptions,
+) (*Provenance, error) {
+	contract := qualifiedIdentifier(collection.Address, collection.Contract)
+
+	specs := map[string]eventSpec{
+		contract + ".Withdraw": {kind: nftWithdrawn, contract: contract},
+		contract + ".Deposit":  {kind: nftDeposited, contract: contract},
+	}
+	if options.MintEvent != "" {
+		specs[contract+"."+options.MintEvent] = eventSpec{kind: nftMinted, contract: contract}
+	}
+
+	records, err := fetchRecords(ctx, c, specs, startHeight, endHeight, options.MaxRange)
+	if err != nil {
+		return nil, err
+	}
+
+	provenance := &Provenance{
+		Contract: contract,
+		TokenID:  tokenID,
+		Steps:    make([]ProvenanceStep, 0),
+	}
+
+	var (
+		transaction flow.Identifier
+		withdrawals []*record
+		mint        = -1
+	)
+
+	// flush records the withdrawals of the current transaction that were not
+	// paired with a deposit.
+	flush := func() {
+		for _, w := range withdrawals {
+			provenance.Steps = append(provenance.Steps, w.step(Withdrawn, w.address("from"), flow.EmptyAddress))
+		}
+		withdrawals = nil
+		mint = -1
+	}
+
+	for _, r := range records {
+		if id, _ := r.fields["id"].(cadence.UInt64); uint64(id) != tokenID {
+			continue
+		}
+
+		if r.event.TransactionID != transaction {
+			flush()
+			transaction = r.event.TransactionID
+		}
+
+		switch r.spec.kind {
+		case nftMinted:
+			mint = len(provenance.Steps)
+			provenance.Steps = append(provenance.Steps, r.step(Minted, flow.EmptyAddress, flow.EmptyAddress))
+		case nftWithdrawn:
+			withdrawals = append(withdrawals, r)
+		case nftDeposited:
+			to := r.address("to")
+
+			switch {
+			case len(withdrawals) > 0:
+				w := withdrawals[0]
+				withdrawals = withdrawals[1:]
+				provenance.Steps = append(provenance.Steps, r.step(Transferred, w.address("from"), to))
+			case mint >= 0 && provenance.Steps[mint].To == flow.EmptyAddress:
+				provenance.Steps[mint].To = to
+			case options.MintEvent == "" && len(provenance.Steps) == 0:
+				provenance.Steps = append(provenance.Steps, r.step(Minted, flow.EmptyAddress, to))
+			default:
+				provenance.Steps = append(provenance.Steps, r.step(Deposited, flow.EmptyAddress, to))
+			}
+		}
+	}
+
+	flush()
+
+	if n := len(provenance.Steps); n > 0 {
+		provenance.Owner = provenance.Steps[n-1].To
+	}
+
+	return provenance, nil
+}
+
+// step returns a provenance step in the block and transaction of the record.
+func (r *record) step(kind StepKind, from, to flow.Address) ProvenanceStep {
+	return ProvenanceStep{
+		Kind:          kind,
+		From:          from,
+		To:            to,
+		Height:        r.block.Height,
+		BlockID:       r.block.BlockID,
+		Timestamp:     r.block.BlockTimestamp,
+		TransactionID: r.event.TransactionID,
+	}
+}
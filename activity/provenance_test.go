@@ -0,0 +1,204 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package activity_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/activity"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+func tokenID(id uint64) field {
+	return field{name: "id", typ: cadence.UInt64Type{}, value: cadence.NewUInt64(id)}
+}
+
+func TestTraceProvenance(t *testing.T) {
+	collection := activity.Collection{Contract: "Kitty", Address: flow.HexToAddress("0f")}
+	nftEvent := "A." + collection.Address.Hex() + ".Kitty."
+
+	alice := flow.HexToAddress("01")
+	bob := flow.HexToAddress("02")
+
+	node := eventNode{
+		5: {
+			// kitty 7 is minted to alice
+			newEvent(nftEvent+"Minted", 1, 0, 0, tokenID(7)),
+			newEvent(nftEvent+"Deposit", 1, 0, 1, tokenID(7), named("to", optionalAddress(alice))),
+			// kitty 8 is minted to bob
+			newEvent(nftEvent+"Minted", 2, 1, 0, tokenID(8)),
+			newEvent(nftEvent+"Deposit", 2, 1, 1, tokenID(8), named("to", optionalAddress(bob))),
+		},
+		300: {
+			// alice sends kitty 7 to bob
+			newEvent(nftEvent+"Withdraw", 3, 0, 0, tokenID(7), named("from", optionalAddress(alice))),
+			newEvent(nftEvent+"Deposit", 3, 0, 1, tokenID(7), named("to", optionalAddress(bob))),
+		},
+		301: {
+			// bob lists kitty 7 on a marketplace that holds it in escrow
+			newEvent(nftEvent+"Withdraw", 4, 0, 0, tokenID(7), named("from", optionalAddress(bob))),
+		},
+		302: {
+			// the marketplace delivers kitty 7 to alice
+			newEvent(nftEvent+"Deposit", 5, 0, 0, tokenID(7), named("to", optionalAddress(alice))),
+		},
+	}
+
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	t.Run("With mint event", func(t *testing.T) {
+		provenance, err := activity.TraceProvenance(context.Background(), c, collection, 7, 1, 400, activity.ProvenanceOptions{
+			MintEvent: "Minted",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "A."+collection.Address.Hex()+".Kitty", provenance.Contract)
+		assert.Equal(t, uint64(7), provenance.TokenID)
+		require.Len(t, provenance.Steps, 4)
+
+		mint, ok := provenance.Mint()
+		require.True(t, ok)
+		assert.Equal(t, uint64(5), mint.Height)
+		assert.Equal(t, flow.Identifier{1}, mint.TransactionID)
+		assert.Equal(t, alice, mint.To)
+
+		assert.Equal(t, activity.Transferred, provenance.Steps[1].Kind)
+		assert.Equal(t, alice, provenance.Steps[1].From)
+		assert.Equal(t, bob, provenance.Steps[1].To)
+
+		assert.Equal(t, activity.Withdrawn, provenance.Steps[2].Kind)
+		assert.Equal(t, bob, provenance.Steps[2].From)
+		assert.Equal(t, flow.EmptyAddress, provenance.Steps[2].To)
+
+		assert.Equal(t, activity.Deposited, provenance.Steps[3].Kind)
+		assert.Equal(t, alice, provenance.Steps[3].To)
+
+		assert.Equal(t, alice, provenance.Owner)
+		assert.NoError(t, provenance.Verify())
+	})
+
+	t.Run("Without mint event", func(t *testing.T) {
+		provenance, err := activity.TraceProvenance(context.Background(), c, collection, 8, 1, 400, activity.ProvenanceOptions{})
+		require.NoError(t, err)
+		require.Len(t, provenance.Steps, 1)
+
+		assert.Equal(t, activity.Minted, provenance.Steps[0].Kind)
+		assert.Equal(t, bob, provenance.Owner)
+		assert.NoError(t, provenance.Verify())
+	})
+
+	t.Run("Missing mint", func(t *testing.T) {
+		provenance, err := activity.TraceProvenance(context.Background(), c, collection, 7, 100, 400, activity.ProvenanceOptions{
+			MintEvent: "Minted",
+		})
+		require.NoError(t, err)
+		require.Len(t, provenance.Steps, 3)
+
+		_, ok := provenance.Mint()
+		assert.False(t, ok)
+
+		err = provenance.Verify()
+		assert.True(t, errors.Is(err, activity.ErrProvenanceGap))
+		assert.Equal(t, errcode.ProvenanceGap, errcode.Of(err))
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		provenance, err := activity.TraceProvenance(context.Background(), c, collection, 9, 1, 400, activity.ProvenanceOptions{})
+		require.NoError(t, err)
+
+		assert.Empty(t, provenance.Steps)
+		assert.Equal(t, flow.EmptyAddress, provenance.Owner)
+	})
+}
+
+func TestProvenanceVerify(t *testing.T) {
+	alice := flow.HexToAddress("01")
+	bob := flow.HexToAddress("02")
+	carol := flow.HexToAddress("03")
+
+	provenance := &activity.Provenance{
+		TokenID: 7,
+		Steps: []activity.ProvenanceStep{
+			{Kind: activity.Minted, To: alice},
+			{Kind: activity.Transferred, From: alice, To: bob},
+			// a transfer from carol means that a movement to carol is missing
+			{Kind: activity.Transferred, From: carol, To: alice},
+		},
+	}
+
+	err := provenance.Verify()
+	assert.True(t, errors.Is(err, activity.ErrProvenanceGap))
+
+	provenance.Steps[2].From = bob
+	assert.NoError(t, provenance.Verify())
+
+	provenance.Steps = append(provenance.Steps, activity.ProvenanceStep{Kind: activity.Minted, To: carol})
+	assert.True(t, errors.Is(provenance.Verify(), activity.ErrProvenanceGap))
+}
+
+func TestTraceProvenanceAcrossSporks(t *testing.T) {
+	collection := activity.Collection{Contract: "Kitty", Address: flow.HexToAddress("0f")}
+	nftEvent := "A." + collection.Address.Hex() + ".Kitty."
+
+	alice := flow.HexToAddress("01")
+	bob := flow.HexToAddress("02")
+
+	previous := eventNode{
+		5: {newEvent(nftEvent+"Deposit", 1, 0, 0, tokenID(7), named("to", optionalAddress(alice)))},
+	}
+	current := eventNode{
+		120: {
+			newEvent(nftEvent+"Withdraw", 2, 0, 0, tokenID(7), named("from", optionalAddress(alice))),
+			newEvent(nftEvent+"Deposit", 2, 0, 1, tokenID(7), named("to", optionalAddress(bob))),
+		},
+	}
+
+	router, err := client.NewSporkRouter([]client.Spork{
+		{Name: "previous", RootHeight: 1, Endpoint: client.NewEndpoint("previous", previous)},
+		{Name: "current", RootHeight: 100, Endpoint: client.NewEndpoint("current", current)},
+	})
+	require.NoError(t, err)
+
+	provenance, err := activity.TraceProvenance(
+		context.Background(),
+		client.NewFromInvoker(router),
+		collection,
+		7,
+		router.Sporks()[0].RootHeight,
+		150,
+		activity.ProvenanceOptions{},
+	)
+	require.NoError(t, err)
+	require.Len(t, provenance.Steps, 2)
+
+	assert.Equal(t, activity.Minted, provenance.Steps[0].Kind)
+	assert.Equal(t, uint64(5), provenance.Steps[0].Height)
+	assert.Equal(t, activity.Transferred, provenance.Steps[1].Kind)
+	assert.Equal(t, uint64(120), provenance.Steps[1].Height)
+	assert.Equal(t, bob, provenance.Owner)
+	assert.NoError(t, provenance.Verify())
+}
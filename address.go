@@ -102,17 +102,18 @@ const (
 
 // chainCustomizer derives the constant used to generate addresses for
 // the given chain.
+//
+// It panics if the chain is neither built in nor registered with RegisterChain.
 func chainCustomizer(chain ChainID) uint64 {
-	switch chain {
-	case Mainnet:
-		return 0
-	case Testnet:
-		return invalidCodeTestnet
-	case Emulator:
-		return invalidCodeEmulator
-	default:
+	chainsMu.RLock()
+	definition, ok := chains[chain]
+	chainsMu.RUnlock()
+
+	if !ok {
 		panic("chain ID is invalid")
 	}
+
+	return definition.AddressCustomizer
 }
 
 // EmptyAddress is the empty address (0x0000000000000000).
@@ -255,6 +256,11 @@ func (a *Address) IsValid(chain ChainID) bool {
 		return false
 	}
 
+	return isCodeWord(codeWord)
+}
+
+// isCodeWord returns true if the given GF(2)-vector is a word of the [64,45]-code.
+func isCodeWord(codeWord uint64) bool {
 	// Multiply the code word GF(2)-vector by the parity-check matrix
 	parity := uint(0)
 	for i := 0; i < linearCodeN; i++ {
@@ -0,0 +1,118 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package addressbook resolves human-readable names, such as .find names or
+// internal labels, to Flow account addresses.
+package addressbook
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrNotFound is returned by resolvers when a name does not resolve to any address.
+var ErrNotFound = errcode.New(errcode.NameNotFound, "addressbook: name not found")
+
+// A Resolver maps names to account addresses.
+//
+// Resolve returns an error wrapping ErrNotFound if the name is unknown.
+type Resolver interface {
+	Resolve(ctx context.Context, name string) (flow.Address, error)
+}
+
+// ResolverFunc is a function that implements the Resolver interface.
+type ResolverFunc func(ctx context.Context, name string) (flow.Address, error)
+
+// Resolve calls f(ctx, name).
+func (f ResolverFunc) Resolve(ctx context.Context, name string) (flow.Address, error) {
+	return f(ctx, name)
+}
+
+// A Book is a fixed set of names, e.g. internal account labels.
+type Book map[string]flow.Address
+
+// Resolve returns the address of the name in this book.
+func (b Book) Resolve(_ context.Context, name string) (flow.Address, error) {
+	address, ok := b[name]
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return address, nil
+}
+
+type chain []Resolver
+
+// Chain returns a resolver that tries each resolver in order and returns the first
+// address found.
+//
+// Errors other than ErrNotFound are returned immediately.
+func Chain(resolvers ...Resolver) Resolver {
+	return chain(resolvers)
+}
+
+func (c chain) Resolve(ctx context.Context, name string) (flow.Address, error) {
+	for _, r := range c {
+		address, err := r.Resolve(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return address, err
+	}
+
+	return flow.EmptyAddress, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Resolve returns the address of an account given either as a hex address with a
+// 0x prefix, or as a name resolved by r.
+//
+// Hex strings without a 0x prefix are treated as names, since they cannot be told
+// apart from names made only of hex digits. If r is nil, only addresses are accepted.
+func Resolve(ctx context.Context, r Resolver, s string) (flow.Address, error) {
+	if strings.HasPrefix(s, "0x") {
+		return parseAddress(s[2:])
+	}
+
+	if r == nil {
+		return flow.EmptyAddress, fmt.Errorf("addressbook: %q is not an address and no resolver is configured", s)
+	}
+
+	return r.Resolve(ctx, s)
+}
+
+func parseAddress(s string) (flow.Address, error) {
+	if len(s) == 0 || len(s) > 2*flow.AddressLength {
+		return flow.EmptyAddress, fmt.Errorf("addressbook: invalid address 0x%s", s)
+	}
+
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return flow.EmptyAddress, fmt.Errorf("addressbook: invalid address 0x%s", s)
+	}
+
+	return flow.BytesToAddress(b), nil
+}
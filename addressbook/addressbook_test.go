@@ -0,0 +1,183 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addressbook_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/addressbook"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+var alice = flow.HexToAddress("01cf0e2f2f715450")
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	book := addressbook.Book{"alice": alice}
+
+	t.Run("Hex address", func(t *testing.T) {
+		address, err := addressbook.Resolve(ctx, nil, "0x01cf0e2f2f715450")
+		require.NoError(t, err)
+		assert.Equal(t, alice, address)
+
+		address, err = addressbook.Resolve(ctx, nil, "0x1")
+		require.NoError(t, err)
+		assert.Equal(t, flow.HexToAddress("01"), address)
+
+		_, err = addressbook.Resolve(ctx, nil, "0xzz")
+		assert.Error(t, err)
+	})
+
+	t.Run("Name", func(t *testing.T) {
+		address, err := addressbook.Resolve(ctx, book, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, alice, address)
+
+		_, err = addressbook.Resolve(ctx, book, "bob")
+		assert.True(t, errors.Is(err, addressbook.ErrNotFound))
+
+		_, err = addressbook.Resolve(ctx, nil, "alice")
+		assert.Error(t, err)
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		bob := flow.HexToAddress("02")
+		r := addressbook.Chain(book, addressbook.Book{"alice": bob, "bob": bob})
+
+		address, err := r.Resolve(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, alice, address)
+
+		address, err = r.Resolve(ctx, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, bob, address)
+
+		_, err = r.Resolve(ctx, "carol")
+		assert.True(t, errors.Is(err, addressbook.ErrNotFound))
+	})
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	failing := false
+	r := addressbook.NewCache(addressbook.ResolverFunc(func(ctx context.Context, name string) (flow.Address, error) {
+		calls++
+		if failing {
+			return flow.EmptyAddress, errors.New("unavailable")
+		}
+		return addressbook.Book{"alice": alice}.Resolve(ctx, name)
+	}), time.Hour)
+
+	for i := 0; i < 3; i++ {
+		address, err := r.Resolve(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, alice, address)
+
+		_, err = r.Resolve(ctx, "bob")
+		assert.True(t, errors.Is(err, addressbook.ErrNotFound))
+	}
+	assert.Equal(t, 2, calls)
+
+	// other errors are not cached
+	failing = true
+	r.Invalidate("alice")
+	for i := 0; i < 2; i++ {
+		_, err := r.Resolve(ctx, "alice")
+		assert.EqualError(t, err, "unavailable")
+	}
+	assert.Equal(t, 4, calls)
+}
+
+func TestFindResolver(t *testing.T) {
+	ctx := context.Background()
+
+	var names []string
+	invoker := client.InvokerFunc(func(
+		_ context.Context,
+		method string,
+		args interface{},
+		reply interface{},
+		_ ...grpc.CallOption,
+	) error {
+		req := args.(*access.ExecuteScriptAtLatestBlockRequest)
+
+		arg, err := jsoncdc.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		name := string(arg.(cadence.String))
+		names = append(names, name)
+
+		result := cadence.NewOptional(nil)
+		if name == "alice" {
+			result = cadence.NewOptional(cadence.NewAddress(alice))
+		}
+
+		reply.(*access.ExecuteScriptResponse).Value = jsoncdc.MustEncode(result)
+		return nil
+	})
+
+	r := addressbook.NewFindResolver(
+		client.NewFromEndpoint(client.NewEndpoint("node", invoker)),
+		flow.HexToAddress("097bafa4e0b48eef"),
+	)
+
+	address, err := r.Resolve(ctx, "alice.find")
+	require.NoError(t, err)
+	assert.Equal(t, alice, address)
+
+	_, err = r.Resolve(ctx, "bob")
+	assert.True(t, errors.Is(err, addressbook.ErrNotFound))
+
+	assert.Equal(t, []string{"alice", "bob"}, names)
+}
+
+// scriptClient is a flow.Client that only executes scripts, with a fixed result.
+type scriptClient struct {
+	flow.Client
+	result cadence.Value
+}
+
+func (c scriptClient) ExecuteScriptAtLatestBlock(context.Context, []byte, []cadence.Value) (cadence.Value, error) {
+	return c.result, nil
+}
+
+func TestScriptResolver_Client(t *testing.T) {
+	r := addressbook.NewScriptResolver(scriptClient{
+		result: cadence.NewOptional(cadence.NewAddress(alice)),
+	}, []byte("script"))
+
+	address, err := r.Resolve(context.Background(), "alice")
+	require.NoError(t, err)
+	assert.Equal(t, alice, address)
+}
@@ -0,0 +1,92 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addressbook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A Cache is a resolver that remembers the results of another resolver.
+//
+// Addresses and ErrNotFound results are cached for the configured duration. Other
+// errors are not cached.
+type Cache struct {
+	resolver Resolver
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	address flow.Address
+	err     error
+	expires time.Time
+}
+
+var _ Resolver = &Cache{}
+
+// NewCache returns a resolver that caches the results of r for ttl.
+func NewCache(r Resolver, ttl time.Duration) *Cache {
+	return &Cache{
+		resolver: r,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// Resolve returns the cached result for the name, or resolves it.
+func (c *Cache) Resolve(ctx context.Context, name string) (flow.Address, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		return entry.address, entry.err
+	}
+
+	address, err := c.resolver.Resolve(ctx, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return address, err
+	}
+
+	c.mu.Lock()
+	c.entries[name] = cacheEntry{
+		address: address,
+		err:     err,
+		expires: now.Add(c.ttl),
+	}
+	c.mu.Unlock()
+
+	return address, err
+}
+
+// Invalidate removes the cached result for the name.
+func (c *Cache) Invalidate(name string) {
+	c.mu.Lock()
+	delete(c.entries, name)
+	c.mu.Unlock()
+}
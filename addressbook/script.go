@@ -0,0 +1,86 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package addressbook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+// A ScriptResolver resolves names by executing a script at the latest sealed block.
+type ScriptResolver struct {
+	client flow.Client
+	script []byte
+	suffix string
+}
+
+var _ Resolver = &ScriptResolver{}
+
+// NewScriptResolver returns a resolver that executes the given script with the name
+// as its only String argument. The script must return an Address?, which is nil if
+// the name is unknown.
+func NewScriptResolver(c flow.Client, code []byte) *ScriptResolver {
+	return &ScriptResolver{client: c, script: code}
+}
+
+// NewFindResolver returns a resolver for .find names, backed by the FIND contract
+// deployed at the given address.
+//
+// Names are accepted with or without the .find suffix.
+func NewFindResolver(c flow.Client, findAddress flow.Address) *ScriptResolver {
+	code := script.NewBuilder().
+		Import("FIND", findAddress).
+		Main("name: String", "Address?").
+		Body(script.Fragment{Source: "return FIND.lookupAddress(name)"}, nil).
+		MustBuild()
+
+	return &ScriptResolver{client: c, script: code, suffix: ".find"}
+}
+
+// Resolve executes the script of this resolver for the name.
+func (r *ScriptResolver) Resolve(ctx context.Context, name string) (flow.Address, error) {
+	if r.suffix != "" {
+		name = strings.TrimSuffix(name, r.suffix)
+	}
+
+	value, err := r.client.ExecuteScriptAtLatestBlock(ctx, r.script, []cadence.Value{cadence.NewString(name)})
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	if optional, ok := value.(cadence.Optional); ok {
+		if optional.Value == nil {
+			return flow.EmptyAddress, fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		value = optional.Value
+	}
+
+	address, ok := value.(cadence.Address)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("addressbook: unexpected script result %T", value)
+	}
+
+	return flow.BytesToAddress(address.Bytes()), nil
+}
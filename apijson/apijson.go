@@ -0,0 +1,485 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package apijson encodes Flow SDK types as JSON for API responses.
+//
+// The default encoding/json representation of the SDK types exposes Go field names
+// and raw byte arrays, which rarely match the contracts of existing frontends. An
+// Encoder instead produces a stable representation of the core types with a
+// configurable field naming convention, hex prefix policy and integer format:
+//
+//	enc := apijson.NewEncoder(apijson.Options{
+//		Naming:           apijson.SnakeCase,
+//		HexPrefix:        true,
+//		NumbersAsStrings: true,
+//	})
+//
+//	b, err := enc.Marshal(account)
+//
+// Values that are not SDK types are encoded with encoding/json, so SDK types can be
+// nested in slices, maps and pointers alongside other values.
+//
+// Large exports are written incrementally with an ArrayWriter, which encodes one
+// element at a time instead of the whole slice at once.
+package apijson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// Naming is a convention for JSON field names.
+type Naming int
+
+const (
+	// CamelCase names fields like "referenceBlockId".
+	CamelCase Naming = iota
+	// SnakeCase names fields like "reference_block_id".
+	SnakeCase
+)
+
+// Options configures the output of an Encoder.
+type Options struct {
+	// Naming is the naming convention of field names.
+	Naming Naming
+	// HexPrefix prefixes addresses, identifiers, keys and signatures with "0x".
+	HexPrefix bool
+	// NumbersAsStrings encodes 64-bit unsigned integers, such as heights, balances
+	// and sequence numbers, as decimal strings to avoid precision loss in JavaScript.
+	NumbersAsStrings bool
+}
+
+// An Encoder encodes SDK types as JSON with a fixed set of options.
+//
+// Encoders are safe for concurrent use.
+type Encoder struct {
+	opts Options
+}
+
+// NewEncoder returns an encoder with the given options.
+func NewEncoder(opts Options) *Encoder {
+	return &Encoder{opts: opts}
+}
+
+// Marshal returns the JSON encoding of v.
+func (e *Encoder) Marshal(v interface{}) ([]byte, error) {
+	value, err := e.Value(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// Encode writes the JSON encoding of v to w, followed by a newline.
+//
+// Slices and arrays are written one element at a time, with an ArrayWriter, rather
+// than converted in full before they are written.
+func (e *Encoder) Encode(w io.Writer, v interface{}) error {
+	if rv := reflect.ValueOf(v); streamable(rv) {
+		return e.encodeArray(w, rv)
+	}
+
+	value, err := e.Value(v)
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(value)
+}
+
+// Value converts v to a value that encoding/json marshals to the representation
+// configured for this encoder.
+//
+// It can be used to embed SDK types in larger response structures.
+func (e *Encoder) Value(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case flow.Address:
+		return e.address(v), nil
+	case flow.Identifier:
+		return e.identifier(v), nil
+	case flow.BlockHeader:
+		return e.blockHeader(v), nil
+	case flow.Block:
+		return e.block(v), nil
+	case flow.Collection:
+		return e.collection(v), nil
+	case flow.CollectionGuarantee:
+		return e.object(field{"collectionId", e.identifier(v.CollectionID)}), nil
+	case flow.Transaction:
+		return e.transaction(v)
+	case flow.ProposalKey:
+		return e.proposalKey(v), nil
+	case flow.TransactionSignature:
+		return e.signature(v), nil
+	case flow.TransactionResult:
+		return e.transactionResult(v)
+	case flow.Event:
+		return e.event(v)
+	case flow.Account:
+		return e.account(v), nil
+	case flow.AccountKey:
+		return e.accountKey(v), nil
+	case client.BlockEvents:
+		return e.blockEvents(v)
+	case json.Marshaler:
+		return v, nil
+	}
+
+	return e.reflectValue(reflect.ValueOf(v))
+}
+
+// reflectValue converts pointers, slices and maps that may contain SDK types.
+func (e *Encoder) reflectValue(rv reflect.Value) (interface{}, error) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return e.Value(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Interface(), nil
+		}
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			value, err := e.Value(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return rv.Interface(), nil
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		values := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			value, err := e.Value(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			values[iter.Key().String()] = value
+		}
+		return values, nil
+	}
+
+	return rv.Interface(), nil
+}
+
+func (e *Encoder) hex(b []byte) string {
+	if e.opts.HexPrefix {
+		return "0x" + hex.EncodeToString(b)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (e *Encoder) address(a flow.Address) string {
+	return e.hex(a.Bytes())
+}
+
+func (e *Encoder) identifier(id flow.Identifier) string {
+	return e.hex(id.Bytes())
+}
+
+func (e *Encoder) uint64(n uint64) interface{} {
+	if e.opts.NumbersAsStrings {
+		return strconv.FormatUint(n, 10)
+	}
+	return n
+}
+
+func (e *Encoder) identifiers(ids []flow.Identifier) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = e.identifier(id)
+	}
+	return values
+}
+
+func (e *Encoder) blockHeaderFields(h flow.BlockHeader) []field {
+	return []field{
+		{"id", e.identifier(h.ID)},
+		{"parentId", e.identifier(h.ParentID)},
+		{"height", e.uint64(h.Height)},
+		{"timestamp", h.Timestamp},
+	}
+}
+
+func (e *Encoder) blockHeader(h flow.BlockHeader) object {
+	return e.object(e.blockHeaderFields(h)...)
+}
+
+func (e *Encoder) block(b flow.Block) object {
+	guarantees := make([]object, len(b.CollectionGuarantees))
+	for i, g := range b.CollectionGuarantees {
+		guarantees[i] = e.object(field{"collectionId", e.identifier(g.CollectionID)})
+	}
+
+	fields := append(
+		e.blockHeaderFields(b.BlockHeader),
+		field{"collectionGuarantees", guarantees},
+	)
+
+	return e.object(fields...)
+}
+
+func (e *Encoder) collection(c flow.Collection) object {
+	return e.object(
+		field{"id", e.identifier(c.ID())},
+		field{"transactionIds", e.identifiers(c.TransactionIDs)},
+	)
+}
+
+func (e *Encoder) transaction(tx flow.Transaction) (object, error) {
+	arguments := make([]json.RawMessage, len(tx.Arguments))
+	for i, arg := range tx.Arguments {
+		if !json.Valid(arg) {
+			return nil, fmt.Errorf("apijson: transaction argument %d is not valid JSON-CDC", i)
+		}
+		arguments[i] = bytes.TrimSpace(arg)
+	}
+
+	authorizers := make([]string, len(tx.Authorizers))
+	for i, authorizer := range tx.Authorizers {
+		authorizers[i] = e.address(authorizer)
+	}
+
+	return e.object(
+		field{"id", e.identifier(tx.ID())},
+		field{"script", string(tx.Script)},
+		field{"arguments", arguments},
+		field{"referenceBlockId", e.identifier(tx.ReferenceBlockID)},
+		field{"gasLimit", e.uint64(tx.GasLimit)},
+		field{"proposalKey", e.proposalKey(tx.ProposalKey)},
+		field{"payer", e.address(tx.Payer)},
+		field{"authorizers", authorizers},
+		field{"payloadSignatures", e.signatures(tx.PayloadSignatures)},
+		field{"envelopeSignatures", e.signatures(tx.EnvelopeSignatures)},
+	), nil
+}
+
+func (e *Encoder) proposalKey(k flow.ProposalKey) object {
+	return e.object(
+		field{"address", e.address(k.Address)},
+		field{"keyIndex", k.KeyIndex},
+		field{"sequenceNumber", e.uint64(k.SequenceNumber)},
+	)
+}
+
+func (e *Encoder) signature(s flow.TransactionSignature) object {
+	return e.object(
+		field{"address", e.address(s.Address)},
+		field{"signerIndex", s.SignerIndex},
+		field{"keyIndex", s.KeyIndex},
+		field{"signature", e.hex(s.Signature)},
+	)
+}
+
+func (e *Encoder) signatures(sigs []flow.TransactionSignature) []object {
+	values := make([]object, len(sigs))
+	for i, sig := range sigs {
+		values[i] = e.signature(sig)
+	}
+	return values
+}
+
+func (e *Encoder) transactionResult(r flow.TransactionResult) (object, error) {
+	events, err := e.events(r.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	var errorMessage interface{}
+	if r.Error != nil {
+		errorMessage = r.Error.Error()
+	}
+
+	return e.object(
+		field{"status", r.Status.String()},
+		field{"errorMessage", errorMessage},
+		field{"events", events},
+	), nil
+}
+
+func (e *Encoder) event(evt flow.Event) (object, error) {
+	payload := evt.Payload
+	if payload == nil {
+		var err error
+		payload, err = jsoncdc.Encode(evt.Value)
+		if err != nil {
+			return nil, fmt.Errorf("apijson: failed to encode event payload: %w", err)
+		}
+	}
+
+	return e.object(
+		field{"type", evt.Type},
+		field{"transactionId", e.identifier(evt.TransactionID)},
+		field{"transactionIndex", evt.TransactionIndex},
+		field{"eventIndex", evt.EventIndex},
+		field{"payload", json.RawMessage(bytes.TrimSpace(payload))},
+	), nil
+}
+
+func (e *Encoder) events(events []flow.Event) ([]object, error) {
+	values := make([]object, len(events))
+	for i, evt := range events {
+		value, err := e.event(evt)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (e *Encoder) blockEvents(b client.BlockEvents) (object, error) {
+	events, err := e.events(b.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.object(
+		field{"blockId", e.identifier(b.BlockID)},
+		field{"blockHeight", e.uint64(b.Height)},
+		field{"blockTimestamp", b.BlockTimestamp},
+		field{"events", events},
+	), nil
+}
+
+func (e *Encoder) account(a flow.Account) object {
+	keys := make([]object, 0, len(a.Keys))
+	for _, key := range a.Keys {
+		if key != nil {
+			keys = append(keys, e.accountKey(*key))
+		}
+	}
+
+	return e.object(
+		field{"address", e.address(a.Address)},
+		field{"balance", e.uint64(a.Balance)},
+		field{"code", string(a.Code)},
+		field{"keys", keys},
+	)
+}
+
+func (e *Encoder) accountKey(k flow.AccountKey) object {
+	var publicKey interface{}
+	if k.PublicKey != (crypto.PublicKey{}) {
+		publicKey = e.hex(k.PublicKey.Encode())
+	}
+
+	return e.object(
+		field{"index", k.Index},
+		field{"publicKey", publicKey},
+		field{"signingAlgorithm", k.SigAlgo.String()},
+		field{"hashingAlgorithm", k.HashAlgo.String()},
+		field{"weight", k.Weight},
+		field{"sequenceNumber", e.uint64(k.SequenceNumber)},
+		field{"revoked", k.Revoked},
+	)
+}
+
+// field is a JSON object member. Names are written in camel case and converted
+// to the naming convention of the encoder.
+type field struct {
+	name  string
+	value interface{}
+}
+
+// object is a JSON object that preserves the order of its members.
+type object []field
+
+func (e *Encoder) object(fields ...field) object {
+	if e.opts.Naming == SnakeCase {
+		for i := range fields {
+			fields[i].name = snakeCase(fields[i].name)
+		}
+	}
+	return fields
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// snakeCase converts a camel case name to snake case.
+func snakeCase(name string) string {
+	var sb strings.Builder
+
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apijson_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/apijson"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func decode(t *testing.T, b []byte) map[string]interface{} {
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func TestEncoder_Naming(t *testing.T) {
+	tx := test.TransactionGenerator().New()
+
+	t.Run("Camel case", func(t *testing.T) {
+		b, err := apijson.NewEncoder(apijson.Options{}).Marshal(tx)
+		require.NoError(t, err)
+
+		m := decode(t, b)
+		assert.Equal(t, tx.ReferenceBlockID.Hex(), m["referenceBlockId"])
+		assert.Contains(t, m, "proposalKey")
+		assert.Contains(t, m["proposalKey"], "sequenceNumber")
+	})
+
+	t.Run("Snake case", func(t *testing.T) {
+		b, err := apijson.NewEncoder(apijson.Options{Naming: apijson.SnakeCase}).Marshal(tx)
+		require.NoError(t, err)
+
+		m := decode(t, b)
+		assert.Equal(t, tx.ReferenceBlockID.Hex(), m["reference_block_id"])
+		assert.Contains(t, m["proposal_key"], "sequence_number")
+		assert.Contains(t, m, "envelope_signatures")
+	})
+}
+
+func TestEncoder_HexPrefix(t *testing.T) {
+	address := flow.HexToAddress("01")
+
+	b, err := apijson.NewEncoder(apijson.Options{}).Marshal(address)
+	require.NoError(t, err)
+	assert.Equal(t, `"0000000000000001"`, string(b))
+
+	b, err = apijson.NewEncoder(apijson.Options{HexPrefix: true}).Marshal(address)
+	require.NoError(t, err)
+	assert.Equal(t, `"0x0000000000000001"`, string(b))
+}
+
+func TestEncoder_NumbersAsStrings(t *testing.T) {
+	header := test.BlockHeaderGenerator().New()
+	header.Height = 1 << 60
+
+	b, err := apijson.NewEncoder(apijson.Options{}).Marshal(header)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"height":1152921504606846976`)
+
+	b, err = apijson.NewEncoder(apijson.Options{NumbersAsStrings: true}).Marshal(header)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"height":"1152921504606846976"`)
+}
+
+func TestEncoder_Nested(t *testing.T) {
+	enc := apijson.NewEncoder(apijson.Options{Naming: apijson.SnakeCase, HexPrefix: true})
+
+	account := test.AccountGenerator().New()
+	event := test.EventGenerator().New()
+
+	b, err := enc.Marshal(map[string]interface{}{
+		"account": account,
+		"events":  []flow.Event{event},
+		"page":    2,
+	})
+	require.NoError(t, err)
+
+	m := decode(t, b)
+	assert.Equal(t, float64(2), m["page"])
+	assert.Equal(t, "0x"+account.Address.Hex(), m["account"].(map[string]interface{})["address"])
+
+	keys := m["account"].(map[string]interface{})["keys"].([]interface{})
+	require.Len(t, keys, len(account.Keys))
+	assert.Contains(t, keys[0], "signing_algorithm")
+
+	events := m["events"].([]interface{})
+	require.Len(t, events, 1)
+	assert.Equal(t, "0x"+event.TransactionID.Hex(), events[0].(map[string]interface{})["transaction_id"])
+	assert.Equal(t, "Event", events[0].(map[string]interface{})["payload"].(map[string]interface{})["type"])
+}
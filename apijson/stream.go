@@ -0,0 +1,184 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apijson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// errArrayClosed is returned when an element is written to a closed ArrayWriter.
+var errArrayClosed = errors.New("apijson: array writer is closed")
+
+// An ArrayWriter writes a JSON array to a writer one element at a time.
+//
+// Each element is encoded and written as it is added, so that exports of millions
+// of events or transactions are never held in memory in full:
+//
+//	arr := enc.NewArrayWriter(w)
+//
+//	for _, block := range blocks {
+//		for _, event := range block.Events {
+//			if err := arr.Write(event); err != nil {
+//				return err
+//			}
+//		}
+//	}
+//
+//	return arr.Close()
+//
+// An ArrayWriter is not safe for concurrent use.
+type ArrayWriter struct {
+	enc *Encoder
+	w   *bufio.Writer
+	// buf holds the encoding of the current element, and is reused for all elements.
+	buf     bytes.Buffer
+	json    *json.Encoder
+	n       int
+	closed  bool
+	lastErr error
+}
+
+// NewArrayWriter returns a writer of a JSON array of elements encoded with this
+// encoder. The array must be terminated with Close.
+func (e *Encoder) NewArrayWriter(w io.Writer) *ArrayWriter {
+	a := &ArrayWriter{
+		enc: e,
+		w:   bufio.NewWriter(w),
+	}
+	a.json = json.NewEncoder(&a.buf)
+	return a
+}
+
+// Write encodes v and writes it as the next element of the array.
+//
+// Errors are sticky: once an element fails to be written, all further calls
+// return the same error.
+func (a *ArrayWriter) Write(v interface{}) error {
+	if a.lastErr != nil {
+		return a.lastErr
+	}
+	if a.closed {
+		return errArrayClosed
+	}
+
+	value, err := a.enc.Value(v)
+	if err != nil {
+		a.lastErr = err
+		return err
+	}
+
+	a.buf.Reset()
+	if err := a.json.Encode(value); err != nil {
+		a.lastErr = err
+		return err
+	}
+
+	separator := byte(',')
+	if a.n == 0 {
+		separator = '['
+	}
+
+	// the JSON encoder terminates each value with a newline
+	encoded := bytes.TrimSuffix(a.buf.Bytes(), []byte{'\n'})
+
+	if err := a.w.WriteByte(separator); err != nil {
+		a.lastErr = err
+		return err
+	}
+	if _, err := a.w.Write(encoded); err != nil {
+		a.lastErr = err
+		return err
+	}
+
+	a.n++
+
+	return nil
+}
+
+// Len returns the number of elements written.
+func (a *ArrayWriter) Len() int {
+	return a.n
+}
+
+// Close terminates the array, followed by a newline, and flushes it to the
+// underlying writer. It does not close the underlying writer.
+func (a *ArrayWriter) Close() error {
+	if a.lastErr != nil {
+		return a.lastErr
+	}
+	if a.closed {
+		return nil
+	}
+
+	a.closed = true
+
+	end := "]\n"
+	if a.n == 0 {
+		end = "[]\n"
+	}
+
+	if _, err := a.w.WriteString(end); err != nil {
+		a.lastErr = err
+		return err
+	}
+
+	if err := a.w.Flush(); err != nil {
+		a.lastErr = err
+		return err
+	}
+
+	return nil
+}
+
+// encodeArray writes the elements of a slice or array with an ArrayWriter.
+func (e *Encoder) encodeArray(w io.Writer, rv reflect.Value) error {
+	arr := e.NewArrayWriter(w)
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := arr.Write(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return arr.Close()
+}
+
+// streamable returns true if rv is a slice or array that Encode writes with an
+// ArrayWriter. Nil slices, byte slices and JSON marshalers are encoded as a single
+// value.
+func streamable(rv reflect.Value) bool {
+	if rv.IsValid() && rv.Type().Implements(marshalerType) {
+		return false
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		return !rv.IsNil() && rv.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apijson_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/apijson"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestArrayWriter(t *testing.T) {
+	enc := apijson.NewEncoder(apijson.Options{HexPrefix: true})
+
+	events := test.EventGenerator()
+	all := []flow.Event{events.New(), events.New(), events.New()}
+
+	var buf bytes.Buffer
+	arr := enc.NewArrayWriter(&buf)
+	for _, event := range all {
+		require.NoError(t, arr.Write(event))
+	}
+	assert.Equal(t, 3, arr.Len())
+	require.NoError(t, arr.Close())
+
+	expected, err := enc.Marshal(all)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected)+"\n", buf.String())
+
+	assert.Error(t, arr.Write(all[0]))
+
+	t.Run("Empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, enc.NewArrayWriter(&buf).Close())
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	enc := apijson.NewEncoder(apijson.Options{})
+
+	transactions := test.TransactionGenerator()
+	all := []*flow.Transaction{transactions.New(), transactions.New()}
+
+	for _, v := range []interface{}{
+		all,
+		[]flow.Transaction(nil),
+		[]byte("bytes"),
+		flow.HexToAddress("01"),
+	} {
+		var buf bytes.Buffer
+		require.NoError(t, enc.Encode(&buf, v))
+
+		expected, err := enc.Marshal(v)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected)+"\n", buf.String())
+	}
+}
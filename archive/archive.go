@@ -0,0 +1,129 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package archive serializes blocks, block events and transaction results for
+// long-term storage.
+//
+// Every record starts with a header that identifies the record kind and the schema
+// version it was written with. Decoders accept all schema versions up to the
+// current one, so records written by older versions of the SDK remain readable
+// after upgrades.
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// SchemaVersion is the schema version of the records written by this package.
+const SchemaVersion = 1
+
+// A Kind is the kind of entity stored in a record.
+type Kind byte
+
+const (
+	// KindBlock is a flow.Block record.
+	KindBlock Kind = 1
+	// KindBlockEvents is a client.BlockEvents record.
+	KindBlockEvents Kind = 2
+	// KindTransactionResult is a flow.TransactionResult record.
+	KindTransactionResult Kind = 3
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindBlock:
+		return "block"
+	case KindBlockEvents:
+		return "block events"
+	case KindTransactionResult:
+		return "transaction result"
+	}
+	return fmt.Sprintf("kind %d", byte(k))
+}
+
+var (
+	// ErrInvalidRecord is returned when decoding data that is not an archive record.
+	ErrInvalidRecord = errors.New("archive: invalid record")
+	// ErrUnsupportedVersion is returned when decoding a record written with a newer schema.
+	ErrUnsupportedVersion = errors.New("archive: unsupported schema version")
+)
+
+// A Codec encodes entities to records and decodes them back.
+//
+// Decoding an encoded entity returns an equal entity. Timestamps are decoded in UTC.
+type Codec interface {
+	EncodeBlock(block flow.Block) ([]byte, error)
+	DecodeBlock(data []byte) (*flow.Block, error)
+	EncodeBlockEvents(events client.BlockEvents) ([]byte, error)
+	DecodeBlockEvents(data []byte) (*client.BlockEvents, error)
+	EncodeTransactionResult(result flow.TransactionResult) ([]byte, error)
+	DecodeTransactionResult(data []byte) (*flow.TransactionResult, error)
+}
+
+var magic = []byte("FLAR")
+
+const headerLength = 6
+
+// Header is the header of a record.
+type Header struct {
+	Version byte
+	Kind    Kind
+}
+
+// ReadHeader returns the header of a record.
+func ReadHeader(data []byte) (Header, error) {
+	if len(data) < headerLength || !bytes.Equal(data[:len(magic)], magic) {
+		return Header{}, ErrInvalidRecord
+	}
+
+	return Header{
+		Version: data[4],
+		Kind:    Kind(data[5]),
+	}, nil
+}
+
+// appendHeader returns a record of the given kind with the payload.
+func appendHeader(kind Kind, payload []byte) []byte {
+	record := make([]byte, 0, headerLength+len(payload))
+	record = append(record, magic...)
+	record = append(record, SchemaVersion, byte(kind))
+	return append(record, payload...)
+}
+
+// payload checks the header of a record and returns its payload.
+func payload(kind Kind, data []byte) ([]byte, error) {
+	header, err := ReadHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Version == 0 || header.Version > SchemaVersion {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, header.Version)
+	}
+
+	if header.Kind != kind {
+		return nil, fmt.Errorf("archive: expected %s record, got %s", kind, header.Kind)
+	}
+
+	return data[headerLength:], nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archive_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/archive"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// v1Block is the first generated test block, encoded with schema version 1.
+const v1Block = "464c415201010a2002020202020202020202020202020202020202020202020202020202020202021220030303030303" +
+	"03030303030303030303030303030303030303030303030303031801220608a9c9e4f6052a220a200202020202020202" +
+	"0202020202020202020202020202020202020202020202022a220a200303030303030303030303030303030303030303" +
+	"0303030303030303030303032a220a200404040404040404040404040404040404040404040404040404040404040404"
+
+func TestProtobuf(t *testing.T) {
+	codec := archive.Protobuf
+
+	t.Run("Block", func(t *testing.T) {
+		block := test.BlockGenerator().New()
+		block.Seals = []*flow.BlockSeal{{}, {}}
+
+		data, err := codec.EncodeBlock(*block)
+		require.NoError(t, err)
+
+		decoded, err := codec.DecodeBlock(data)
+		require.NoError(t, err)
+		assert.Equal(t, block, decoded)
+	})
+
+	t.Run("Block events", func(t *testing.T) {
+		header := test.BlockHeaderGenerator().New()
+		events := test.EventGenerator()
+
+		blockEvents := client.BlockEvents{
+			BlockID:        header.ID,
+			Height:         header.Height,
+			BlockTimestamp: header.Timestamp,
+			Events:         []flow.Event{events.New(), events.New()},
+		}
+
+		data, err := codec.EncodeBlockEvents(blockEvents)
+		require.NoError(t, err)
+
+		decoded, err := codec.DecodeBlockEvents(data)
+		require.NoError(t, err)
+		assert.Equal(t, blockEvents, *decoded)
+	})
+
+	t.Run("Transaction result", func(t *testing.T) {
+		result := test.TransactionResultGenerator().New()
+
+		data, err := codec.EncodeTransactionResult(result)
+		require.NoError(t, err)
+
+		decoded, err := codec.DecodeTransactionResult(data)
+		require.NoError(t, err)
+		assert.Equal(t, result, *decoded)
+	})
+
+	t.Run("Version 1 records stay readable", func(t *testing.T) {
+		data, err := hex.DecodeString(v1Block)
+		require.NoError(t, err)
+
+		header, err := archive.ReadHeader(data)
+		require.NoError(t, err)
+		assert.Equal(t, archive.Header{Version: 1, Kind: archive.KindBlock}, header)
+
+		decoded, err := codec.DecodeBlock(data)
+		require.NoError(t, err)
+		assert.Equal(t, test.BlockGenerator().New(), decoded)
+	})
+
+	t.Run("Invalid records", func(t *testing.T) {
+		block := test.BlockGenerator().New()
+
+		data, err := codec.EncodeBlock(*block)
+		require.NoError(t, err)
+
+		_, err = codec.DecodeTransactionResult(data)
+		assert.EqualError(t, err, "archive: expected transaction result record, got block")
+
+		future := append([]byte{}, data...)
+		future[4] = archive.SchemaVersion + 1
+		_, err = codec.DecodeBlock(future)
+		assert.True(t, errors.Is(err, archive.ErrUnsupportedVersion))
+
+		_, err = codec.DecodeBlock([]byte("not a record"))
+		assert.Equal(t, archive.ErrInvalidRecord, err)
+	})
+}
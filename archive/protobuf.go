@@ -0,0 +1,168 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package archive
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+)
+
+// Protobuf is a codec that stores entities as the protobuf messages of the Access API.
+//
+// The Access API messages are versioned by the Flow protocol and only evolve in
+// backward-compatible ways, which makes them a stable storage format.
+var Protobuf Codec = protobufCodec{}
+
+type protobufCodec struct{}
+
+func (protobufCodec) EncodeBlock(block flow.Block) ([]byte, error) {
+	m, err := convert.BlockToMessage(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// seals carry no data yet, only their number is preserved
+	for range block.Seals {
+		m.BlockSeals = append(m.BlockSeals, &entities.BlockSeal{})
+	}
+
+	return marshal(KindBlock, m)
+}
+
+func (protobufCodec) DecodeBlock(data []byte) (*flow.Block, error) {
+	m := new(entities.Block)
+	if err := unmarshal(KindBlock, data, m); err != nil {
+		return nil, err
+	}
+
+	block, err := convert.MessageToBlock(m)
+	if err != nil {
+		return nil, fmt.Errorf("archive: invalid block: %w", err)
+	}
+
+	for range m.GetBlockSeals() {
+		block.Seals = append(block.Seals, &flow.BlockSeal{})
+	}
+
+	return &block, nil
+}
+
+func (protobufCodec) EncodeBlockEvents(events client.BlockEvents) ([]byte, error) {
+	timestamp, err := ptypes.TimestampProto(events.BlockTimestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &access.EventsResponse_Result{
+		BlockId:        events.BlockID.Bytes(),
+		BlockHeight:    events.Height,
+		BlockTimestamp: timestamp,
+		Events:         make([]*entities.Event, len(events.Events)),
+	}
+
+	for i, event := range events.Events {
+		m.Events[i], err = convert.EventToMessage(event)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return marshal(KindBlockEvents, m)
+}
+
+func (protobufCodec) DecodeBlockEvents(data []byte) (*client.BlockEvents, error) {
+	m := new(access.EventsResponse_Result)
+	if err := unmarshal(KindBlockEvents, data, m); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := ptypes.Timestamp(m.GetBlockTimestamp())
+	if err != nil {
+		return nil, fmt.Errorf("archive: invalid block timestamp: %w", err)
+	}
+
+	events := &client.BlockEvents{
+		BlockID:        flow.HashToID(m.GetBlockId()),
+		Height:         m.GetBlockHeight(),
+		BlockTimestamp: timestamp,
+		Events:         make([]flow.Event, len(m.GetEvents())),
+	}
+
+	for i, event := range m.GetEvents() {
+		events.Events[i], err = convert.MessageToEvent(event)
+		if err != nil {
+			return nil, fmt.Errorf("archive: invalid event: %w", err)
+		}
+	}
+
+	return events, nil
+}
+
+func (protobufCodec) EncodeTransactionResult(result flow.TransactionResult) ([]byte, error) {
+	m, err := convert.TransactionResultToMessage(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(KindTransactionResult, m)
+}
+
+func (protobufCodec) DecodeTransactionResult(data []byte) (*flow.TransactionResult, error) {
+	m := new(access.TransactionResultResponse)
+	if err := unmarshal(KindTransactionResult, data, m); err != nil {
+		return nil, err
+	}
+
+	result, err := convert.MessageToTransactionResult(m)
+	if err != nil {
+		return nil, fmt.Errorf("archive: invalid transaction result: %w", err)
+	}
+
+	return &result, nil
+}
+
+func marshal(kind Kind, m proto.Message) ([]byte, error) {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendHeader(kind, b), nil
+}
+
+func unmarshal(kind Kind, data []byte, m proto.Message) error {
+	b, err := payload(kind, data)
+	if err != nil {
+		return err
+	}
+
+	if err := proto.Unmarshal(b, m); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+	}
+
+	return nil
+}
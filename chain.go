@@ -0,0 +1,154 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flow
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Names of the core contracts of the built-in chains.
+const (
+	ContractFungibleToken      = "FungibleToken"
+	ContractFlowToken          = "FlowToken"
+	ContractFlowFees           = "FlowFees"
+	ContractFlowStorageFees    = "FlowStorageFees"
+	ContractFlowServiceAccount = "FlowServiceAccount"
+)
+
+// A Chain defines a Flow network: its chain ID, how its account addresses are
+// generated and where its core contracts are deployed.
+//
+// Mainnet, Testnet and Emulator are built in. Other networks, e.g. canary, localnet
+// or private networks, are registered with RegisterChain.
+type Chain struct {
+	ID ChainID
+	// AddressCustomizer is the constant combined with the code words of the
+	// address linear code to generate the addresses of the chain.
+	//
+	// It must not itself be a code word, so that the addresses of the chain are
+	// never valid Mainnet addresses.
+	AddressCustomizer uint64
+	// Contracts maps the names of the core contracts of the chain, e.g.
+	// ContractFungibleToken, to their addresses.
+	Contracts map[string]Address
+}
+
+// Contract returns the address of the core contract with the given name, and false
+// if the chain has no such contract.
+func (c Chain) Contract(name string) (Address, bool) {
+	address, ok := c.Contracts[name]
+	return address, ok
+}
+
+// ErrInvalidChain is returned when registering an invalid chain definition.
+var ErrInvalidChain = errors.New("flow: invalid chain")
+
+var (
+	chainsMu sync.RWMutex
+	chains   = map[ChainID]Chain{
+		Mainnet: {
+			ID:                Mainnet,
+			AddressCustomizer: 0,
+			Contracts: map[string]Address{
+				ContractFungibleToken:      HexToAddress("f233dcee88fe0abe"),
+				ContractFlowToken:          HexToAddress("1654653399040a61"),
+				ContractFlowFees:           HexToAddress("f919ee77447b7497"),
+				ContractFlowStorageFees:    HexToAddress("e467b9dd11fa00df"),
+				ContractFlowServiceAccount: HexToAddress("e467b9dd11fa00df"),
+			},
+		},
+		Testnet: {
+			ID:                Testnet,
+			AddressCustomizer: invalidCodeTestnet,
+			Contracts: map[string]Address{
+				ContractFungibleToken:      HexToAddress("9a0766d93b6608b7"),
+				ContractFlowToken:          HexToAddress("7e60df042a9c0868"),
+				ContractFlowFees:           HexToAddress("912d5440f7e3769e"),
+				ContractFlowStorageFees:    HexToAddress("8c5303eaa26202d6"),
+				ContractFlowServiceAccount: HexToAddress("8c5303eaa26202d6"),
+			},
+		},
+		Emulator: {
+			ID:                Emulator,
+			AddressCustomizer: invalidCodeEmulator,
+			Contracts: map[string]Address{
+				ContractFungibleToken:      HexToAddress("ee82856bf20e2aa6"),
+				ContractFlowToken:          HexToAddress("0ae53cb6e3f42a79"),
+				ContractFlowFees:           HexToAddress("e5a8b7f23e8b548f"),
+				ContractFlowStorageFees:    HexToAddress("f8d6e0586b0a20c7"),
+				ContractFlowServiceAccount: HexToAddress("f8d6e0586b0a20c7"),
+			},
+		},
+	}
+)
+
+// RegisterChain registers a chain definition, so that address generation and
+// validation, token transfers, fee decoding and script templates work on it.
+// Registering a chain ID again replaces its definition.
+//
+// An error wrapping ErrInvalidChain is returned if the chain ID is empty or built
+// in, or if the address customizer is a code word.
+func RegisterChain(chain Chain) error {
+	if chain.ID == "" {
+		return fmt.Errorf("%w: empty chain ID", ErrInvalidChain)
+	}
+
+	switch chain.ID {
+	case Mainnet, Testnet, Emulator:
+		return fmt.Errorf("%w: %s is built in", ErrInvalidChain, chain.ID)
+	}
+
+	if isCodeWord(chain.AddressCustomizer) {
+		return fmt.Errorf("%w: address customizer of %s is a code word", ErrInvalidChain, chain.ID)
+	}
+
+	contracts := make(map[string]Address, len(chain.Contracts))
+	for name, address := range chain.Contracts {
+		contracts[name] = address
+	}
+	chain.Contracts = contracts
+
+	chainsMu.Lock()
+	defer chainsMu.Unlock()
+	chains[chain.ID] = chain
+
+	return nil
+}
+
+// LookupChain returns the definition of the chain with the given ID, and false if
+// the chain is neither built in nor registered.
+func LookupChain(id ChainID) (Chain, bool) {
+	chainsMu.RLock()
+	chain, ok := chains[id]
+	chainsMu.RUnlock()
+
+	if !ok {
+		return Chain{}, false
+	}
+
+	contracts := make(map[string]Address, len(chain.Contracts))
+	for name, address := range chain.Contracts {
+		contracts[name] = address
+	}
+	chain.Contracts = contracts
+
+	return chain, true
+}
@@ -0,0 +1,85 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flow_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+func TestRegisterChain(t *testing.T) {
+	localnet := flow.ChainID("flow-localnet-test")
+
+	fungibleToken := flow.HexToAddress("f8d6e0586b0a20c7")
+
+	err := flow.RegisterChain(flow.Chain{
+		ID:                localnet,
+		AddressCustomizer: 0x1035ce4eff92ae01,
+		Contracts:         map[string]flow.Address{flow.ContractFungibleToken: fungibleToken},
+	})
+	require.NoError(t, err)
+
+	chain, ok := flow.LookupChain(localnet)
+	require.True(t, ok)
+
+	address, ok := chain.Contract(flow.ContractFungibleToken)
+	assert.True(t, ok)
+	assert.Equal(t, fungibleToken, address)
+
+	t.Run("Addresses", func(t *testing.T) {
+		generator := flow.NewAddressGenerator(localnet)
+
+		for i := 0; i < 10; i++ {
+			address := generator.NextAddress()
+			assert.True(t, address.IsValid(localnet))
+			assert.False(t, address.IsValid(flow.Mainnet))
+			assert.False(t, address.IsValid(flow.Testnet))
+		}
+
+		assert.NotEqual(t, flow.ServiceAddress(flow.Emulator), flow.ServiceAddress(localnet))
+	})
+
+	t.Run("Definitions are copied", func(t *testing.T) {
+		chain.Contracts[flow.ContractFungibleToken] = flow.EmptyAddress
+
+		chain, _ := flow.LookupChain(localnet)
+		assert.Equal(t, fungibleToken, chain.Contracts[flow.ContractFungibleToken])
+	})
+
+	t.Run("Invalid chains", func(t *testing.T) {
+		for _, chain := range []flow.Chain{
+			{ID: ""},
+			{ID: flow.Mainnet, AddressCustomizer: 0x1035ce4eff92ae01},
+			{ID: "flow-codeword", AddressCustomizer: 0},
+		} {
+			err := flow.RegisterChain(chain)
+			assert.True(t, errors.Is(err, flow.ErrInvalidChain), chain.ID)
+		}
+	})
+
+	t.Run("Unknown chains", func(t *testing.T) {
+		_, ok := flow.LookupChain("flow-unknown")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,101 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package checkpoint persists the progress of resumable components, such as event
+// subscribers, indexers and replayers.
+//
+// Progress is recorded as a Checkpoint: the height of the last processed block and
+// an optional cursor within it. A Store saves the height and cursor of a named
+// component atomically.
+//
+// ResumeIterator and SaveIterator keep the position of a client.EventIterator in a
+// Store, and a ReplayStore shares the transactions of client.ReplayRegistry
+// instances through one.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// A Checkpoint is the progress of a component.
+type Checkpoint struct {
+	// Height is the height of the last processed block.
+	Height uint64 `json:"height"`
+	// Cursor is the position within the block at Height, if the block was not fully
+	// processed, e.g. the key of the last processed event.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// A Store saves checkpoints by component name.
+//
+// Implementations must save the height and cursor of a checkpoint atomically, and
+// must be safe for concurrent use.
+type Store interface {
+	// Get returns the checkpoint of a component, and false if none is saved.
+	Get(ctx context.Context, name string) (Checkpoint, bool, error)
+	// Set saves the checkpoint of a component.
+	Set(ctx context.Context, name string, checkpoint Checkpoint) error
+}
+
+// A MemoryStore is a store that keeps checkpoints in memory.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	checkpoints map[string]Checkpoint
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{checkpoints: make(map[string]Checkpoint)}
+}
+
+// Get returns the checkpoint of a component.
+func (s *MemoryStore) Get(_ context.Context, name string) (Checkpoint, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	checkpoint, ok := s.checkpoints[name]
+	return checkpoint, ok, nil
+}
+
+// Set saves the checkpoint of a component.
+func (s *MemoryStore) Set(_ context.Context, name string, checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[name] = checkpoint
+	return nil
+}
+
+// encode and decode convert checkpoints to the single value saved by stores that
+// do not have a native representation for them.
+func encode(checkpoint Checkpoint) ([]byte, error) {
+	return json.Marshal(checkpoint)
+}
+
+func decode(data []byte) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint: invalid checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
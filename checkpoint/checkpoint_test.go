@@ -0,0 +1,290 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/checkpoint"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// redisMap is a RedisClient backed by a map.
+type redisMap map[string]string
+
+func (m redisMap) Get(_ context.Context, key string) (string, bool, error) {
+	value, ok := m[key]
+	return value, ok, nil
+}
+
+func (m redisMap) Set(_ context.Context, key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func testStore(t *testing.T, store checkpoint.Store) {
+	ctx := context.Background()
+
+	_, ok, err := store.Get(ctx, "indexer")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Set(ctx, "indexer", checkpoint.Checkpoint{Height: 42, Cursor: "abc:1"}))
+	require.NoError(t, store.Set(ctx, "replayer", checkpoint.Checkpoint{Height: 7}))
+	require.NoError(t, store.Set(ctx, "indexer", checkpoint.Checkpoint{Height: 43}))
+
+	saved, ok, err := store.Get(ctx, "indexer")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, checkpoint.Checkpoint{Height: 43}, saved)
+
+	saved, ok, err = store.Get(ctx, "replayer")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, checkpoint.Checkpoint{Height: 7}, saved)
+}
+
+func TestStores(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		testStore(t, checkpoint.NewMemoryStore())
+	})
+
+	t.Run("File", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "checkpoint")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		store, err := checkpoint.NewFileStore(dir)
+		require.NoError(t, err)
+		testStore(t, store)
+
+		// checkpoints survive a new store on the same directory
+		reopened, err := checkpoint.NewFileStore(dir)
+		require.NoError(t, err)
+		saved, ok, err := reopened.Get(context.Background(), "indexer")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(43), saved.Height)
+
+		err = store.Set(context.Background(), "../escape", checkpoint.Checkpoint{})
+		assert.Error(t, err)
+	})
+
+	t.Run("Redis", func(t *testing.T) {
+		client := redisMap{}
+		testStore(t, checkpoint.NewRedisStore(client, "flow:checkpoint:"))
+		assert.Contains(t, client, "flow:checkpoint:indexer")
+	})
+}
+
+// eventNode is an Access API node at a fixed sealed height, with two events per block.
+type eventNode struct {
+	height uint64
+	events map[uint64][]flow.Event
+}
+
+func newEventNode(height uint64) *eventNode {
+	events := test.EventGenerator()
+	node := &eventNode{height: height, events: make(map[uint64][]flow.Event)}
+	for h := uint64(1); h <= height; h++ {
+		node.events[h] = []flow.Event{events.New(), events.New()}
+	}
+	return node
+}
+
+func (n *eventNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.height}
+	case client.MethodGetEventsForHeightRange:
+		req := args.(*access.GetEventsForHeightRangeRequest)
+		res := reply.(*access.EventsResponse)
+		for height := req.StartHeight; height <= req.EndHeight; height++ {
+			result := &access.EventsResponse_Result{
+				BlockHeight:    height,
+				BlockTimestamp: ptypes.TimestampNow(),
+			}
+			for _, event := range n.events[height] {
+				m, err := convert.EventToMessage(event)
+				if err != nil {
+					return err
+				}
+				result.Events = append(result.Events, m)
+			}
+			res.Results = append(res.Results, result)
+		}
+	}
+	return nil
+}
+
+func TestProcessEvents(t *testing.T) {
+	node := newEventNode(3)
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+	store := checkpoint.NewMemoryStore()
+	config := client.PollConfig{MinInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+	var handled []string
+	errFailed := errors.New("failed")
+
+	// the first run fails on the second event of block 2
+	err := checkpoint.ProcessEvents(
+		context.Background(), c, store, "indexer", "test.Event", 1, config,
+		func(block client.BlockEvents, event flow.Event) error {
+			if event.ID() == node.events[2][1].ID() {
+				return errFailed
+			}
+			handled = append(handled, event.ID())
+			return nil
+		},
+	)
+	require.True(t, errors.Is(err, errFailed))
+
+	saved, _, err := store.Get(context.Background(), "indexer")
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint.Checkpoint{
+		Height: 2,
+		Cursor: client.NewEventKey(node.events[2][0]).String(),
+	}, saved)
+
+	// the second run resumes with the failed event
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err = checkpoint.ProcessEvents(
+		ctx, c, store, "indexer", "test.Event", 1, config,
+		func(block client.BlockEvents, event flow.Event) error {
+			handled = append(handled, event.ID())
+			if block.Height == 3 && len(handled) == 6 {
+				cancel()
+			}
+			return nil
+		},
+	)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	var expected []string
+	for h := uint64(1); h <= 3; h++ {
+		for _, event := range node.events[h] {
+			expected = append(expected, event.ID())
+		}
+	}
+	assert.Equal(t, expected, handled)
+}
+
+func TestIterator(t *testing.T) {
+	node := newEventNode(3)
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+	store := checkpoint.NewMemoryStore()
+	ctx := context.Background()
+
+	query := client.EventRangeQuery{Type: "test.Event", StartHeight: 1}
+
+	// the first iterator stops after the first event of block 2
+	it := c.EventIterator(query)
+	require.NoError(t, checkpoint.ResumeIterator(ctx, store, "indexer", it))
+
+	var handled []string
+	for len(handled) < 3 {
+		event, err := it.Next(ctx)
+		require.NoError(t, err)
+		handled = append(handled, event.ID())
+	}
+	require.NoError(t, checkpoint.SaveIterator(ctx, store, "indexer", it))
+
+	saved, _, err := store.Get(ctx, "indexer")
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint.Checkpoint{Height: 2, Cursor: "2:1"}, saved)
+
+	// the second iterator resumes with the second event of block 2
+	it = c.EventIterator(query)
+	require.NoError(t, checkpoint.ResumeIterator(ctx, store, "indexer", it))
+
+	for {
+		event, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		handled = append(handled, event.ID())
+	}
+
+	var expected []string
+	for h := uint64(1); h <= 3; h++ {
+		for _, event := range node.events[h] {
+			expected = append(expected, event.ID())
+		}
+	}
+	assert.Equal(t, expected, handled)
+
+	// checkpoints of fully processed blocks resume at the next block
+	require.NoError(t, store.Set(ctx, "indexer", checkpoint.Checkpoint{Height: 2}))
+
+	it = c.EventIterator(query)
+	require.NoError(t, checkpoint.ResumeIterator(ctx, store, "indexer", it))
+	assert.Equal(t, client.EventCursor{Height: 3}, it.Cursor())
+}
+
+func TestReplayStore(t *testing.T) {
+	store := checkpoint.NewReplayStore(checkpoint.NewMemoryStore(), "replay:")
+	mainnet := client.NewReplayRegistry("mainnet", time.Hour, store)
+	testnet := client.NewReplayRegistry("testnet", time.Hour, store)
+
+	txID := flow.HexToID("01")
+
+	require.NoError(t, testnet.Record(txID))
+	require.NoError(t, testnet.Record(txID))
+
+	err := mainnet.Record(txID)
+	var replayErr *client.ReplayError
+	require.True(t, errors.As(err, &replayErr))
+	assert.Equal(t, "testnet", replayErr.Previous.Environment)
+
+	// expired entries are replaced
+	now := time.Now()
+	entry, ok, err := store.Record(client.ReplayEntry{
+		TransactionID: txID,
+		Environment:   "mainnet",
+		ExpiresAt:     now.Add(3 * time.Hour),
+	}, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "mainnet", entry.Environment)
+}
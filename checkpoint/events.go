@@ -0,0 +1,106 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// An EventHandler processes an event emitted in a block.
+type EventHandler func(block client.BlockEvents, event flow.Event) error
+
+// ProcessEvents subscribes to events of the given type and passes each event to the
+// handler, in order. The checkpoint of the named component is saved after each
+// handled event, with the event key as cursor, and after each block.
+//
+// Processing resumes after the checkpoint saved in the store, or starts at
+// startHeight if none is saved. An event is handled again only if the process stopped
+// between the handler returning and the checkpoint being saved.
+//
+// ProcessEvents returns when the context is cancelled, a request fails, the handler
+// fails or a checkpoint cannot be saved.
+func ProcessEvents(
+	ctx context.Context,
+	c flow.Client,
+	store Store,
+	name string,
+	eventType string,
+	startHeight uint64,
+	config client.PollConfig,
+	handler EventHandler,
+) error {
+	saved, ok, err := store.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("checkpoint: failed to read checkpoint: %w", err)
+	}
+
+	if ok {
+		startHeight = saved.Height
+		if saved.Cursor == "" {
+			startHeight++
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	blocks, errs := client.SubscribeEvents(ctx, c, eventType, startHeight, config)
+
+	for block := range blocks {
+		events := block.Events
+		if ok && block.Height == saved.Height {
+			events = after(events, saved.Cursor)
+		}
+
+		for _, event := range events {
+			if err := handler(block, event); err != nil {
+				return err
+			}
+
+			err := store.Set(ctx, name, Checkpoint{
+				Height: block.Height,
+				Cursor: client.NewEventKey(event).String(),
+			})
+			if err != nil {
+				return fmt.Errorf("checkpoint: failed to save checkpoint: %w", err)
+			}
+		}
+
+		if err := store.Set(ctx, name, Checkpoint{Height: block.Height}); err != nil {
+			return fmt.Errorf("checkpoint: failed to save checkpoint: %w", err)
+		}
+	}
+
+	return <-errs
+}
+
+// after returns the events that follow the event with the given key. All events are
+// returned if none has the key.
+func after(events []flow.Event, cursor string) []flow.Event {
+	for i, event := range events {
+		if client.NewEventKey(event).String() == cursor {
+			return events[i+1:]
+		}
+	}
+	return events
+}
@@ -0,0 +1,118 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// A FileStore is a store that saves each checkpoint to a JSON file in a directory.
+//
+// Files are replaced atomically by renaming a temporary file.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+var _ Store = &FileStore{}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// NewFileStore returns a store that saves checkpoints in the given directory, which
+// is created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(name string) (string, error) {
+	if !validName.MatchString(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("checkpoint: invalid name %q", name)
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
+
+// Get returns the checkpoint of a component.
+func (s *FileStore) Get(_ context.Context, name string) (Checkpoint, bool, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	checkpoint, err := decode(data)
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	return checkpoint, true, nil
+}
+
+// Set saves the checkpoint of a component.
+func (s *FileStore) Set(_ context.Context, name string, checkpoint Checkpoint) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := encode(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := ioutil.TempFile(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
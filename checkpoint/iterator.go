@@ -0,0 +1,69 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// ResumeIterator moves an event iterator to the checkpoint of the named component,
+// if one is saved. The checkpoint must have been saved with SaveIterator.
+func ResumeIterator(ctx context.Context, store Store, name string, it *client.EventIterator) error {
+	saved, ok, err := store.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("checkpoint: failed to read checkpoint: %w", err)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	cursor := client.EventCursor{Height: saved.Height + 1}
+	if saved.Cursor != "" {
+		cursor, err = client.ParseEventCursor(saved.Cursor)
+		if err != nil {
+			return fmt.Errorf("checkpoint: invalid checkpoint of %s: %w", name, err)
+		}
+	}
+
+	return it.Seek(cursor)
+}
+
+// SaveIterator saves the position of an event iterator as the checkpoint of the
+// named component.
+//
+// A position at the start of a block is saved as the height of the previous block,
+// which was fully processed. Other positions are saved with the iterator cursor.
+func SaveIterator(ctx context.Context, store Store, name string, it *client.EventIterator) error {
+	cursor := it.Cursor()
+
+	checkpoint := Checkpoint{Height: cursor.Height, Cursor: cursor.String()}
+	if cursor.Index == 0 && cursor.Height > 0 {
+		checkpoint = Checkpoint{Height: cursor.Height - 1}
+	}
+
+	if err := store.Set(ctx, name, checkpoint); err != nil {
+		return fmt.Errorf("checkpoint: failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+)
+
+// A PostgresStore is a store that saves checkpoints in a PostgreSQL table.
+//
+// The store uses database/sql, so any PostgreSQL driver can be used to open the
+// database. Each checkpoint is a single row, updated with an upsert.
+type PostgresStore struct {
+	db    *sql.DB
+	table string
+}
+
+var _ Store = &PostgresStore{}
+
+var validTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// NewPostgresStore returns a store that saves checkpoints in the given table.
+//
+// Use CreateTable to create the table if it does not exist.
+func NewPostgresStore(db *sql.DB, table string) (*PostgresStore, error) {
+	if !validTable.MatchString(table) {
+		return nil, fmt.Errorf("checkpoint: invalid table name %q", table)
+	}
+	return &PostgresStore{db: db, table: table}, nil
+}
+
+// CreateTable creates the checkpoint table if it does not exist.
+func (s *PostgresStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (
+			name TEXT PRIMARY KEY,
+			height BIGINT NOT NULL,
+			cursor TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+		s.table,
+	))
+	return err
+}
+
+// Get returns the checkpoint of a component.
+func (s *PostgresStore) Get(ctx context.Context, name string) (Checkpoint, bool, error) {
+	var (
+		height int64
+		cursor string
+	)
+
+	err := s.db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(`SELECT height, cursor FROM %s WHERE name = $1`, s.table),
+		name,
+	).Scan(&height, &cursor)
+	if err == sql.ErrNoRows {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	return Checkpoint{Height: uint64(height), Cursor: cursor}, true, nil
+}
+
+// Set saves the checkpoint of a component.
+func (s *PostgresStore) Set(ctx context.Context, name string, checkpoint Checkpoint) error {
+	_, err := s.db.ExecContext(
+		ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (name, height, cursor, updated_at) VALUES ($1, $2, $3, now())
+			ON CONFLICT (name) DO UPDATE SET height = $2, cursor = $3, updated_at = now()`,
+			s.table,
+		),
+		name,
+		int64(checkpoint.Height),
+		checkpoint.Cursor,
+	)
+	return err
+}
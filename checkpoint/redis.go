@@ -0,0 +1,87 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+)
+
+// A RedisClient is the subset of a Redis client used by a RedisStore.
+//
+// It is satisfied by a small adapter over any Redis client library, e.g. for
+// go-redis:
+//
+//	type adapter struct{ *redis.Client }
+//
+//	func (a adapter) Get(ctx context.Context, key string) (string, bool, error) {
+//		value, err := a.Client.Get(ctx, key).Result()
+//		if err == redis.Nil {
+//			return "", false, nil
+//		}
+//		return value, err == nil, err
+//	}
+//
+//	func (a adapter) Set(ctx context.Context, key, value string) error {
+//		return a.Client.Set(ctx, key, value, 0).Err()
+//	}
+type RedisClient interface {
+	// Get returns the value of a key, and false if the key does not exist.
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Set sets the value of a key.
+	Set(ctx context.Context, key, value string) error
+}
+
+// A RedisStore is a store that saves each checkpoint as a single Redis string.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+var _ Store = &RedisStore{}
+
+// NewRedisStore returns a store that saves checkpoints in Redis, under keys made of
+// the given prefix followed by the component name.
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Get returns the checkpoint of a component.
+func (s *RedisStore) Get(ctx context.Context, name string) (Checkpoint, bool, error) {
+	value, ok, err := s.client.Get(ctx, s.prefix+name)
+	if err != nil || !ok {
+		return Checkpoint{}, false, err
+	}
+
+	checkpoint, err := decode([]byte(value))
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+
+	return checkpoint, true, nil
+}
+
+// Set saves the checkpoint of a component.
+func (s *RedisStore) Set(ctx context.Context, name string, checkpoint Checkpoint) error {
+	data, err := encode(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, s.prefix+name, string(data))
+}
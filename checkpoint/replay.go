@@ -0,0 +1,89 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"context"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// A ReplayStore is a client.ReplayStore that records transactions in a checkpoint
+// store, e.g. to share a Redis or Postgres store between the replay registries of
+// several environments.
+//
+// Each transaction is saved under the prefix and its ID, with the environment as
+// cursor and the expiry, in Unix seconds, as height. Expired entries are
+// overwritten rather than discarded.
+//
+// Checkpoint stores cannot add an entry only if it is absent, so processes sharing
+// the store must not record the same transaction concurrently.
+type ReplayStore struct {
+	store  Store
+	prefix string
+}
+
+var _ client.ReplayStore = &ReplayStore{}
+
+// NewReplayStore returns a replay store that records transactions in the given
+// checkpoint store, under names starting with prefix.
+func NewReplayStore(store Store, prefix string) *ReplayStore {
+	return &ReplayStore{store: store, prefix: prefix}
+}
+
+// Record records the entry, unless the transaction was recorded for another
+// environment and has not expired, as described by client.ReplayStore.
+func (s *ReplayStore) Record(entry client.ReplayEntry, now time.Time) (client.ReplayEntry, bool, error) {
+	ctx := context.Background()
+	name := s.prefix + entry.TransactionID.String()
+
+	saved, ok, err := s.store.Get(ctx, name)
+	if err != nil {
+		return client.ReplayEntry{}, false, err
+	}
+
+	if ok {
+		recorded := client.ReplayEntry{
+			TransactionID: entry.TransactionID,
+			Environment:   saved.Cursor,
+			ExpiresAt:     time.Unix(int64(saved.Height), 0),
+		}
+
+		if recorded.ExpiresAt.After(now) {
+			if recorded.Environment != entry.Environment {
+				return recorded, false, nil
+			}
+
+			if !entry.ExpiresAt.After(recorded.ExpiresAt) {
+				return entry, true, nil
+			}
+		}
+	}
+
+	err = s.store.Set(ctx, name, Checkpoint{
+		Height: uint64(entry.ExpiresAt.Unix()),
+		Cursor: entry.Environment,
+	})
+	if err != nil {
+		return client.ReplayEntry{}, false, err
+	}
+
+	return entry, true, nil
+}
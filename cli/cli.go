@@ -0,0 +1,189 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cli provides Flow commands that can be embedded in other command-line tools.
+//
+// Each command declares its flags in a standard library flag.FlagSet and runs
+// against a shared Config, which holds the Access API client and the signing
+// account. Commands can be executed directly with Execute, or mounted in a
+// cobra command tree:
+//
+//	for _, c := range cli.Commands(config) {
+//		c := c
+//		cmd := &cobra.Command{
+//			Use:   c.Use,
+//			Short: c.Short,
+//			Args:  cobra.ExactArgs(c.Args),
+//			RunE: func(cmd *cobra.Command, args []string) error {
+//				return c.Run(cmd.Context(), args)
+//			},
+//		}
+//		cmd.Flags().AddGoFlagSet(c.Flags)
+//		root.AddCommand(cmd)
+//	}
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/addressbook"
+	"github.com/portto/blocto-flow-go-sdk/apijson"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/keystore"
+)
+
+// DefaultGasLimit is the gas limit of transactions sent by commands if none is configured.
+const DefaultGasLimit uint64 = 9999
+
+// A Signer is an account key that signs the transactions sent by commands.
+//
+// The account is the proposer, payer and sole authorizer of every transaction.
+type Signer struct {
+	Address  flow.Address
+	KeyIndex int
+	Signer   crypto.Signer
+}
+
+// NewSignerFromKeystore returns the signer for the key with the given label in the
+// named account of a keys.json file.
+//
+// Encrypted keys must be decrypted before they are used.
+func NewSignerFromKeystore(file *keystore.File, account, label string) (*Signer, error) {
+	acct, key, err := file.Key(account, label)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := key.Signer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		Address:  acct.Address,
+		KeyIndex: key.Index,
+		Signer:   signer,
+	}, nil
+}
+
+// Config is the environment shared by all commands.
+type Config struct {
+	// Client is the Access API client used by commands.
+	Client *client.Client
+	// Signer signs transactions. It is only required by commands that send transactions.
+	Signer *Signer
+	// Out receives the output of commands. Defaults to os.Stdout.
+	Out io.Writer
+	// GasLimit is the gas limit of transactions. Defaults to DefaultGasLimit.
+	GasLimit uint64
+	// PollInterval is the time between two status requests while waiting for a
+	// transaction to be sealed. Defaults to 1 second.
+	PollInterval time.Duration
+	// Resolver resolves account names given in place of addresses. If nil, accounts
+	// must be given as hex addresses.
+	Resolver addressbook.Resolver
+}
+
+// ErrNoSigner is returned by commands that send transactions when no signer is configured.
+var ErrNoSigner = errcode.New(errcode.NoSigner, "cli: no signer configured")
+
+// A Command is a Flow operation with its flags.
+type Command struct {
+	// Use is the one-line usage message, starting with the command name.
+	Use string
+	// Short is a short description of the command.
+	Short string
+	// Args is the exact number of positional arguments of the command.
+	Args int
+	// Flags are the flags of the command.
+	Flags *flag.FlagSet
+	// Run executes the command with the given positional arguments, once flags are parsed.
+	Run func(ctx context.Context, args []string) error
+}
+
+// Name returns the name of the command.
+func (c *Command) Name() string {
+	return strings.Fields(c.Use)[0]
+}
+
+// Execute parses the flags in args and runs the command with the remaining arguments.
+func (c *Command) Execute(ctx context.Context, args []string) error {
+	if err := c.Flags.Parse(args); err != nil {
+		return err
+	}
+
+	if c.Flags.NArg() != c.Args {
+		return fmt.Errorf("cli: %s expects %d argument(s), got %d (usage: %s)", c.Name(), c.Args, c.Flags.NArg(), c.Use)
+	}
+
+	return c.Run(ctx, c.Flags.Args())
+}
+
+// Commands returns all commands of this package.
+func Commands(config Config) []*Command {
+	return []*Command{
+		NewGetAccountCommand(config),
+		NewExecuteScriptCommand(config),
+		NewSendTransactionCommand(config),
+		NewCreateAccountCommand(config),
+		NewDeployContractCommand(config),
+	}
+}
+
+func newCommand(use, short string, args int) *Command {
+	name := strings.Fields(use)[0]
+
+	return &Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		Flags: flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+}
+
+func (c Config) out() io.Writer {
+	if c.Out == nil {
+		return os.Stdout
+	}
+	return c.Out
+}
+
+// address returns the address of an account argument, given either as a hex address
+// or, if a resolver is configured, as a 0x-prefixed address or a name.
+func (c Config) address(ctx context.Context, s string) (flow.Address, error) {
+	if c.Resolver == nil {
+		return flow.HexToAddress(s), nil
+	}
+	return addressbook.Resolve(ctx, c.Resolver, s)
+}
+
+var encoder = apijson.NewEncoder(apijson.Options{HexPrefix: true})
+
+// print writes the JSON encoding of v to the output.
+func (c Config) print(v interface{}) error {
+	return encoder.Encode(c.out(), v)
+}
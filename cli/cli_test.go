@@ -0,0 +1,204 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/addressbook"
+	"github.com/portto/blocto-flow-go-sdk/cli"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// fakeNode is an Access API node that serves a single account and seals every
+// transaction it receives.
+type fakeNode struct {
+	account *flow.Account
+	sent    []*entities.Transaction
+	args    [][]byte
+}
+
+func (n *fakeNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: 1}
+	case client.MethodGetAccountAtLatestBlock:
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(*n.account)
+	case client.MethodExecuteScriptAtLatestBlock:
+		n.args = args.(*access.ExecuteScriptAtLatestBlockRequest).Arguments
+		reply.(*access.ExecuteScriptResponse).Value = jsoncdc.MustEncode(cadence.NewInt(42))
+	case client.MethodSendTransaction:
+		n.sent = append(n.sent, args.(*access.SendTransactionRequest).Transaction)
+	case client.MethodGetTransactionResult:
+		reply.(*access.TransactionResultResponse).Status = entities.TransactionStatus_SEALED
+	}
+
+	return nil
+}
+
+func writeFile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "*.cdc")
+	require.NoError(t, err)
+
+	_, err = f.WriteString(contents)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	return f.Name()
+}
+
+func setup(t *testing.T) (*fakeNode, cli.Config, *bytes.Buffer) {
+	key, signer := test.AccountKeyGenerator().NewWithSigner()
+	account := test.AccountGenerator().New()
+	account.Keys = []*flow.AccountKey{key}
+
+	node := &fakeNode{account: account}
+	out := &bytes.Buffer{}
+
+	config := cli.Config{
+		Client: client.NewFromEndpoint(client.NewEndpoint("node", node)),
+		Signer: &cli.Signer{
+			Address:  account.Address,
+			KeyIndex: key.Index,
+			Signer:   signer,
+		},
+		Out:          out,
+		PollInterval: time.Millisecond,
+	}
+
+	return node, config, out
+}
+
+func TestGetAccountCommand(t *testing.T) {
+	node, config, out := setup(t)
+
+	err := cli.NewGetAccountCommand(config).Execute(context.Background(), []string{node.account.Address.Hex()})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), `"address":"0x`+node.account.Address.Hex()+`"`)
+
+	t.Run("Resolves names", func(t *testing.T) {
+		node, config, out := setup(t)
+		config.Resolver = addressbook.Book{"alice": node.account.Address}
+
+		err := cli.NewGetAccountCommand(config).Execute(context.Background(), []string{"alice"})
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), `"address":"0x`+node.account.Address.Hex()+`"`)
+
+		err = cli.NewGetAccountCommand(config).Execute(context.Background(), []string{"bob"})
+		assert.True(t, errors.Is(err, addressbook.ErrNotFound))
+	})
+}
+
+func TestExecuteScriptCommand(t *testing.T) {
+	node, config, out := setup(t)
+
+	path := writeFile(t, "pub fun main(a: Int): Int { return a }")
+	defer os.Remove(path)
+
+	err := cli.NewExecuteScriptCommand(config).Execute(
+		context.Background(),
+		[]string{"--arg", `{"type":"Int","value":"42"}`, path},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, node.args, 1)
+	assert.JSONEq(t, `{"type":"Int","value":"42"}`, string(node.args[0]))
+	assert.JSONEq(t, `{"type":"Int","value":"42"}`, out.String())
+}
+
+func TestSendTransactionCommand(t *testing.T) {
+	t.Run("Signs and waits for seal", func(t *testing.T) {
+		node, config, out := setup(t)
+
+		path := writeFile(t, "transaction { prepare(signer: AuthAccount) {} }")
+		defer os.Remove(path)
+
+		err := cli.NewSendTransactionCommand(config).Execute(context.Background(), []string{path})
+		require.NoError(t, err)
+
+		require.Len(t, node.sent, 1)
+
+		tx, err := convert.MessageToTransaction(node.sent[0])
+		require.NoError(t, err)
+
+		assert.Equal(t, node.account.Address, tx.Payer)
+		assert.Equal(t, []flow.Address{node.account.Address}, tx.Authorizers)
+		assert.Equal(t, uint64(42), tx.ProposalKey.SequenceNumber)
+		assert.Len(t, tx.EnvelopeSignatures, 1)
+
+		assert.Contains(t, out.String(), "Transaction ID: "+tx.ID().Hex())
+		assert.Contains(t, out.String(), `"status":"SEALED"`)
+	})
+
+	t.Run("No signer", func(t *testing.T) {
+		_, config, _ := setup(t)
+		config.Signer = nil
+
+		path := writeFile(t, "transaction {}")
+		defer os.Remove(path)
+
+		err := cli.NewSendTransactionCommand(config).Execute(context.Background(), []string{path})
+		assert.Equal(t, cli.ErrNoSigner, err)
+	})
+}
+
+func TestCommand_Execute(t *testing.T) {
+	_, config, _ := setup(t)
+
+	commands := cli.Commands(config)
+
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name()
+	}
+	assert.Equal(t, []string{
+		"get-account",
+		"execute-script",
+		"send-transaction",
+		"create-account",
+		"deploy-contract",
+	}, names)
+
+	err := cli.NewGetAccountCommand(config).Execute(context.Background(), nil)
+	assert.EqualError(t, err, "cli: get-account expects 1 argument(s), got 0 (usage: get-account <address>)")
+}
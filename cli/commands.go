@@ -0,0 +1,330 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/templates"
+)
+
+// NewGetAccountCommand returns a command that prints an account.
+func NewGetAccountCommand(config Config) *Command {
+	cmd := newCommand("get-account <address>", "Get an account", 1)
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		address, err := config.address(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		account, err := config.Client.GetAccount(ctx, address)
+		if err != nil {
+			return err
+		}
+
+		return config.print(account)
+	}
+
+	return cmd
+}
+
+// NewExecuteScriptCommand returns a command that executes a script file and prints its result.
+func NewExecuteScriptCommand(config Config) *Command {
+	cmd := newCommand("execute-script <file>", "Execute a script", 1)
+
+	var arguments valuesFlag
+	cmd.Flags.Var(&arguments, "arg", "JSON-Cadence encoded script argument (repeatable)")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		script, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		value, err := config.Client.ExecuteScriptAtLatestBlock(ctx, script, arguments)
+		if err != nil {
+			return err
+		}
+
+		b, err := jsoncdc.Encode(value)
+		if err != nil {
+			return err
+		}
+
+		_, err = config.out().Write(b)
+		return err
+	}
+
+	return cmd
+}
+
+// NewSendTransactionCommand returns a command that sends a transaction file signed by the
+// configured signer.
+func NewSendTransactionCommand(config Config) *Command {
+	cmd := newCommand("send-transaction <file>", "Send a transaction", 1)
+
+	var arguments valuesFlag
+	cmd.Flags.Var(&arguments, "arg", "JSON-Cadence encoded transaction argument (repeatable)")
+	wait := cmd.Flags.Bool("wait", true, "wait for the transaction to be sealed")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		script, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		if config.Signer == nil {
+			return ErrNoSigner
+		}
+
+		tx := flow.NewTransaction().
+			SetScript(script).
+			AddAuthorizer(config.Signer.Address)
+
+		for _, arg := range arguments {
+			if err := tx.AddArgument(arg); err != nil {
+				return err
+			}
+		}
+
+		_, err = config.send(ctx, tx, *wait)
+		return err
+	}
+
+	return cmd
+}
+
+// NewCreateAccountCommand returns a command that creates an account paid for by the
+// configured signer, and prints the address of the new account.
+func NewCreateAccountCommand(config Config) *Command {
+	cmd := newCommand("create-account", "Create an account", 0)
+
+	var keys stringsFlag
+	cmd.Flags.Var(&keys, "key", "hex-encoded public key of the new account (repeatable)")
+	sigAlgo := cmd.Flags.String("sig-algo", crypto.ECDSA_P256.String(), "signature algorithm of the keys")
+	hashAlgo := cmd.Flags.String("hash-algo", crypto.SHA3_256.String(), "hash algorithm of the keys")
+	codePath := cmd.Flags.String("code", "", "path to the code to deploy to the new account")
+
+	cmd.Run = func(ctx context.Context, _ []string) error {
+		if config.Signer == nil {
+			return ErrNoSigner
+		}
+
+		accountKeys, err := parseAccountKeys(keys, *sigAlgo, *hashAlgo)
+		if err != nil {
+			return err
+		}
+
+		var code []byte
+		if *codePath != "" {
+			code, err = ioutil.ReadFile(*codePath)
+			if err != nil {
+				return err
+			}
+		}
+
+		tx := templates.CreateAccount(accountKeys, code, config.Signer.Address)
+
+		result, err := config.send(ctx, tx, true)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range result.Events {
+			if event.Type == flow.EventAccountCreated {
+				address := flow.AccountCreatedEvent(event).Address()
+				_, err := fmt.Fprintf(config.out(), "Account created: 0x%s\n", address.Hex())
+				return err
+			}
+		}
+
+		return fmt.Errorf("cli: transaction did not emit %s", flow.EventAccountCreated)
+	}
+
+	return cmd
+}
+
+// NewDeployContractCommand returns a command that deploys code to the account of the
+// configured signer.
+func NewDeployContractCommand(config Config) *Command {
+	cmd := newCommand("deploy-contract <file>", "Deploy code to the signer account", 1)
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		code, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		if config.Signer == nil {
+			return ErrNoSigner
+		}
+
+		tx := templates.UpdateAccountCode(config.Signer.Address, code)
+
+		_, err = config.send(ctx, tx, true)
+		return err
+	}
+
+	return cmd
+}
+
+// send completes, signs and sends a transaction, prints its ID, and optionally waits
+// for it to be sealed and prints its result.
+//
+// An error is returned if the sealed transaction failed.
+func (c Config) send(ctx context.Context, tx *flow.Transaction, wait bool) (*flow.TransactionResult, error) {
+	signer := c.Signer
+
+	header, err := c.Client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := c.Client.GetAccount(ctx, signer.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var key *flow.AccountKey
+	for _, k := range account.Keys {
+		if k.Index == signer.KeyIndex {
+			key = k
+		}
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("cli: account %s has no key at index %d", signer.Address, signer.KeyIndex)
+	}
+
+	gasLimit := c.GasLimit
+	if gasLimit == 0 {
+		gasLimit = DefaultGasLimit
+	}
+
+	tx.SetReferenceBlockID(header.ID).
+		SetGasLimit(gasLimit).
+		SetProposalKey(signer.Address, key.Index, key.SequenceNumber).
+		SetPayer(signer.Address)
+
+	err = tx.SignEnvelope(signer.Address, key.Index, signer.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.Client.SendTransaction(ctx, *tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(c.out(), "Transaction ID: %s\n", tx.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	if !wait {
+		return nil, nil
+	}
+
+	result, err := c.Client.WaitForSealConsistent(ctx, tx.ID(), client.ConsistencyConfig{
+		PollInterval: c.PollInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.print(result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return result, fmt.Errorf("cli: transaction failed: %w", result.Error)
+	}
+
+	return result, nil
+}
+
+func parseAccountKeys(keys []string, sigAlgo, hashAlgo string) ([]*flow.AccountKey, error) {
+	sig := crypto.StringToSignatureAlgorithm(sigAlgo)
+	if sig == crypto.UnknownSignatureAlgorithm {
+		return nil, fmt.Errorf("cli: unknown signature algorithm %q", sigAlgo)
+	}
+
+	hash := crypto.StringToHashAlgorithm(hashAlgo)
+	if hash == crypto.UnknownHashAlgorithm {
+		return nil, fmt.Errorf("cli: unknown hash algorithm %q", hashAlgo)
+	}
+
+	accountKeys := make([]*flow.AccountKey, len(keys))
+	for i, key := range keys {
+		publicKey, err := crypto.DecodePublicKeyHex(sig, strings.TrimPrefix(key, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("cli: invalid public key %d: %w", i, err)
+		}
+
+		accountKeys[i] = flow.NewAccountKey().
+			SetPublicKey(publicKey).
+			SetHashAlgo(hash).
+			SetWeight(flow.AccountKeyWeightThreshold)
+	}
+
+	return accountKeys, nil
+}
+
+// valuesFlag is a repeatable flag of JSON-Cadence encoded values.
+type valuesFlag []cadence.Value
+
+func (f *valuesFlag) String() string {
+	values := make([]string, len(*f))
+	for i, value := range *f {
+		values[i] = fmt.Sprint(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func (f *valuesFlag) Set(s string) error {
+	value, err := jsoncdc.Decode([]byte(s))
+	if err != nil {
+		return err
+	}
+
+	*f = append(*f, value)
+	return nil
+}
+
+// stringsFlag is a repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+// NewTransferCommand returns a command that transfers an amount of a token from the
+// signer to a recipient, given as an address or as a name resolved by the configured
+// resolver.
+//
+// The command is not part of Commands, since the token depends on the network.
+func NewTransferCommand(config Config, token transfer.Token) *Command {
+	cmd := newCommand("transfer <recipient> <amount>", "Transfer tokens", 2)
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if config.Signer == nil {
+			return ErrNoSigner
+		}
+
+		amount, err := transfer.ParseTokenAmount(args[1], transfer.MaxDecimals)
+		if err != nil {
+			return err
+		}
+
+		address, err := config.address(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		orchestrator := &transfer.Orchestrator{
+			Client: config.Client,
+			Token:  token,
+			Sender: transfer.Signer{
+				Address:  config.Signer.Address,
+				KeyIndex: config.Signer.KeyIndex,
+				Signer:   config.Signer.Signer,
+			},
+			GasLimit:     config.GasLimit,
+			PollInterval: config.PollInterval,
+		}
+
+		op := transfer.NewOperation(address, amount)
+		runErr := orchestrator.Run(ctx, op)
+
+		if op.TransferTransaction != nil {
+			var tx flow.Transaction
+			if err := tx.DecodeFromBytes(op.TransferTransaction); err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintf(config.out(), "Transaction ID: %s\n", tx.ID()); err != nil {
+				return err
+			}
+		}
+
+		return runErr
+	}
+
+	return cmd
+}
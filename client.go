@@ -0,0 +1,100 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flow
+
+import (
+	"context"
+
+	"github.com/onflow/cadence"
+)
+
+// A Client is a client for the Flow Access API.
+//
+// The client package implements Client over gRPC. Code that depends on Client
+// rather than on a concrete client can be given mocks, wrappers that add e.g.
+// caching or metrics, or clients that use other transports.
+type Client interface {
+	// Ping checks that the Access API is available.
+	Ping(ctx context.Context) error
+
+	// GetLatestBlockHeader gets the latest sealed or unsealed block header.
+	GetLatestBlockHeader(ctx context.Context, isSealed bool) (*BlockHeader, error)
+	// GetBlockHeaderByID gets a block header by ID.
+	GetBlockHeaderByID(ctx context.Context, blockID Identifier) (*BlockHeader, error)
+	// GetBlockHeaderByHeight gets a block header by height.
+	GetBlockHeaderByHeight(ctx context.Context, height uint64) (*BlockHeader, error)
+
+	// GetLatestBlock gets the full payload of the latest sealed or unsealed block.
+	GetLatestBlock(ctx context.Context, isSealed bool) (*Block, error)
+	// GetBlockByID gets a full block by ID.
+	GetBlockByID(ctx context.Context, blockID Identifier) (*Block, error)
+	// GetBlockByHeight gets a full block by height.
+	GetBlockByHeight(ctx context.Context, height uint64) (*Block, error)
+
+	// GetCollection gets a collection by ID.
+	GetCollection(ctx context.Context, colID Identifier) (*Collection, error)
+	// GetFullCollection gets a collection by ID, with the bodies of its transactions.
+	GetFullCollection(ctx context.Context, colID Identifier) (*FullCollection, error)
+
+	// SendTransaction submits a transaction to the network.
+	SendTransaction(ctx context.Context, tx Transaction) error
+	// GetTransaction gets a transaction by ID.
+	GetTransaction(ctx context.Context, txID Identifier) (*Transaction, error)
+	// GetTransactionResult gets the result of a transaction.
+	GetTransactionResult(ctx context.Context, txID Identifier) (*TransactionResult, error)
+
+	// GetAccount is an alias for GetAccountAtLatestBlock.
+	GetAccount(ctx context.Context, address Address) (*Account, error)
+	// GetAccountAtLatestBlock gets an account by address at the latest sealed block.
+	GetAccountAtLatestBlock(ctx context.Context, address Address) (*Account, error)
+	// GetAccountAtBlockHeight gets an account by address at the given block height.
+	GetAccountAtBlockHeight(ctx context.Context, address Address, height uint64) (*Account, error)
+
+	// ExecuteScriptAtLatestBlock executes a read-only Cadence script against the
+	// latest sealed execution state.
+	ExecuteScriptAtLatestBlock(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error)
+	// ExecuteScriptAtBlockID executes a read-only Cadence script against the
+	// execution state at the block with the given ID.
+	ExecuteScriptAtBlockID(
+		ctx context.Context,
+		blockID Identifier,
+		script []byte,
+		arguments []cadence.Value,
+	) (cadence.Value, error)
+	// ExecuteScriptAtBlockHeight executes a read-only Cadence script against the
+	// execution state at the given block height.
+	ExecuteScriptAtBlockHeight(
+		ctx context.Context,
+		height uint64,
+		script []byte,
+		arguments []cadence.Value,
+	) (cadence.Value, error)
+
+	// GetEventsForHeightRange gets events for all sealed blocks between the start
+	// and end block heights (inclusive) with the given type.
+	GetEventsForHeightRange(ctx context.Context, query EventRangeQuery) ([]BlockEvents, error)
+	// GetEventsForBlockIDs gets events with the given type from the specified blocks.
+	GetEventsForBlockIDs(ctx context.Context, eventType string, blockIDs []Identifier) ([]BlockEvents, error)
+
+	// GetNetworkParameters gets the chain ID of the network served by the Access API.
+	GetNetworkParameters(ctx context.Context) (ChainID, error)
+
+	// Close closes the connection of the client.
+	Close() error
+}
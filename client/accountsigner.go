@@ -0,0 +1,206 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// AccountSignerConfig configures NewAccountSigner.
+type AccountSignerConfig struct {
+	// Addresses are the accounts checked for the key, in order.
+	Addresses []flow.Address
+	// RegistryScript, if set, is a script that returns the accounts a public key was
+	// added to, e.g. from an on-chain key index. It is executed with the public key,
+	// hex-encoded, as its only String argument, and must return an [Address]. Its
+	// accounts are checked after Addresses.
+	RegistryScript []byte
+	// Pool configures the proposer key pool of the signer. Its Address, PublicKey,
+	// Signer, HashAlgo, AuthorizerKeyIndex and AuthorizerSigner are set by
+	// NewAccountSigner.
+	Pool ProposerKeyPoolConfig
+}
+
+// An AccountSigner signs for the account key that a private key controls.
+//
+// Transactions proposed by the signer lease their proposal key from Proposer,
+// which tracks the sequence numbers of all keys of the account with the same
+// public key.
+type AccountSigner struct {
+	// Address is the account of the key.
+	Address flow.Address
+	// KeyIndex is the index of the key.
+	KeyIndex int
+	// HashAlgo is the hash algorithm of the key.
+	HashAlgo crypto.HashAlgorithm
+	// Signer signs with the private key.
+	Signer crypto.Signer
+	// Proposer manages the sequence numbers of the key. It is closed with Close once
+	// the signer is no longer used.
+	Proposer *ProposerKeyPool
+}
+
+// NewAccountSigner returns a signer for the account key that a private key controls,
+// for applications that only know their key material.
+//
+// The accounts of the config are checked for a usable key with the public key of
+// the private key, as described in FindAccountKey. An error wrapping
+// ErrKeyNotFound is returned if no account has one, and an error is returned if
+// several accounts have one, as the signer would be ambiguous.
+func NewAccountSigner(
+	ctx context.Context,
+	c *Client,
+	privateKey crypto.PrivateKey,
+	config AccountSignerConfig,
+) (*AccountSigner, error) {
+	publicKey := privateKey.PublicKey()
+
+	addresses, err := c.candidateAccounts(ctx, publicKey, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		matches []flow.Address
+		found   *flow.AccountKey
+	)
+
+	for _, address := range addresses {
+		key, err := c.FindAccountKey(ctx, address, publicKey)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		if found == nil {
+			found = key
+		}
+		matches = append(matches, address)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: no account has key %x", ErrKeyNotFound, publicKey.Encode())
+	case 1:
+	default:
+		names := make([]string, len(matches))
+		for i, address := range matches {
+			names[i] = address.Hex()
+		}
+		return nil, fmt.Errorf(
+			"client: key %x controls several accounts (%s)",
+			publicKey.Encode(),
+			strings.Join(names, ", "),
+		)
+	}
+
+	signer := crypto.NewInMemorySigner(privateKey, found.HashAlgo)
+
+	poolConfig := config.Pool
+	poolConfig.Address = matches[0]
+	poolConfig.PublicKey = publicKey
+	poolConfig.Signer = signer
+	poolConfig.HashAlgo = found.HashAlgo
+	poolConfig.AuthorizerKeyIndex = found.Index
+	poolConfig.AuthorizerSigner = signer
+
+	proposer, err := NewProposerKeyPool(ctx, c, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountSigner{
+		Address:  matches[0],
+		KeyIndex: found.Index,
+		HashAlgo: found.HashAlgo,
+		Signer:   signer,
+		Proposer: proposer,
+	}, nil
+}
+
+// candidateAccounts returns the accounts of the config and of its registry script,
+// without duplicates.
+func (c *Client) candidateAccounts(
+	ctx context.Context,
+	publicKey crypto.PublicKey,
+	config AccountSignerConfig,
+) ([]flow.Address, error) {
+	addresses := append([]flow.Address(nil), config.Addresses...)
+
+	if config.RegistryScript != nil {
+		value, err := c.ExecuteScriptAtLatestBlock(
+			ctx,
+			config.RegistryScript,
+			[]cadence.Value{cadence.NewString(hex.EncodeToString(publicKey.Encode()))},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		array, ok := value.(cadence.Array)
+		if !ok {
+			return nil, fmt.Errorf("client: unexpected registry script result %T", value)
+		}
+
+		for _, v := range array.Values {
+			address, ok := v.(cadence.Address)
+			if !ok {
+				return nil, fmt.Errorf("client: unexpected registry script address %T", v)
+			}
+			addresses = append(addresses, flow.BytesToAddress(address.Bytes()))
+		}
+	}
+
+	seen := make(map[flow.Address]bool, len(addresses))
+	unique := addresses[:0]
+	for _, address := range addresses {
+		if !seen[address] {
+			seen[address] = true
+			unique = append(unique, address)
+		}
+	}
+
+	return unique, nil
+}
+
+// Lease leases a proposal key of the signer. See ProposerKeyPool.Lease.
+func (s *AccountSigner) Lease(ctx context.Context) (*ProposerKeyLease, error) {
+	return s.Proposer.Lease(ctx)
+}
+
+// SignPayload signs the payload of a transaction with the key of the signer.
+func (s *AccountSigner) SignPayload(tx *flow.Transaction) error {
+	return tx.SignPayload(s.Address, s.KeyIndex, s.Signer)
+}
+
+// SignEnvelope signs the envelope of a transaction with the key of the signer.
+func (s *AccountSigner) SignEnvelope(tx *flow.Transaction) error {
+	return tx.SignEnvelope(s.Address, s.KeyIndex, s.Signer)
+}
@@ -0,0 +1,173 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+func TestNewAccountSigner(t *testing.T) {
+	ctx := context.Background()
+
+	newKey := func(b byte) crypto.PrivateKey {
+		privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{b}, crypto.MinSeedLength))
+		require.NoError(t, err)
+		return privateKey
+	}
+
+	otherKey := newKey(1)
+	signerKey := newKey(2)
+
+	accountKey := func(privateKey crypto.PrivateKey, index int, sequenceNumber uint64) *flow.AccountKey {
+		key := flow.NewAccountKey().
+			FromPrivateKey(privateKey).
+			SetHashAlgo(crypto.SHA2_256).
+			SetWeight(flow.AccountKeyWeightThreshold)
+		key.Index = index
+		key.SequenceNumber = sequenceNumber
+		return key
+	}
+
+	accounts := map[flow.Address]flow.Account{
+		flow.HexToAddress("01"): {
+			Address: flow.HexToAddress("01"),
+			Keys:    []*flow.AccountKey{accountKey(otherKey, 0, 0)},
+		},
+		flow.HexToAddress("02"): {
+			Address: flow.HexToAddress("02"),
+			Keys: []*flow.AccountKey{
+				accountKey(otherKey, 0, 0),
+				accountKey(signerKey, 1, 4),
+			},
+		},
+		flow.HexToAddress("03"): {
+			Address: flow.HexToAddress("03"),
+			Keys:    []*flow.AccountKey{accountKey(signerKey, 0, 0)},
+		},
+	}
+
+	var registered []cadence.Value
+
+	newClient := func(t *testing.T) *client.Client {
+		invoker := client.InvokerFunc(func(
+			_ context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			_ ...grpc.CallOption,
+		) error {
+			switch method {
+			case client.MethodGetAccountAtLatestBlock:
+				address := flow.BytesToAddress(args.(*access.GetAccountAtLatestBlockRequest).Address)
+				reply.(*access.AccountResponse).Account = convert.AccountToMessage(accounts[address])
+			case client.MethodExecuteScriptAtLatestBlock:
+				request := args.(*access.ExecuteScriptAtLatestBlockRequest)
+				require.Len(t, request.Arguments, 1)
+
+				argument, err := jsoncdc.Decode(request.Arguments[0])
+				require.NoError(t, err)
+				assert.Equal(t, cadence.NewString(hex.EncodeToString(signerKey.PublicKey().Encode())), argument)
+
+				reply.(*access.ExecuteScriptResponse).Value, err = jsoncdc.Encode(cadence.NewArray(registered))
+				require.NoError(t, err)
+			default:
+				t.Fatalf("unexpected method %s", method)
+			}
+			return nil
+		})
+
+		return client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+	}
+
+	t.Run("Provided address", func(t *testing.T) {
+		signer, err := client.NewAccountSigner(ctx, newClient(t), signerKey, client.AccountSignerConfig{
+			Addresses: []flow.Address{flow.HexToAddress("01"), flow.HexToAddress("02")},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, flow.HexToAddress("02"), signer.Address)
+		assert.Equal(t, 1, signer.KeyIndex)
+		assert.Equal(t, crypto.SHA2_256, signer.HashAlgo)
+
+		lease, err := signer.Lease(ctx)
+		require.NoError(t, err)
+		defer lease.Release()
+
+		assert.Equal(t, 1, lease.KeyIndex)
+		assert.Equal(t, uint64(4), lease.SequenceNumber)
+
+		tx := lease.SetProposalKey(flow.NewTransaction()).SetPayer(signer.Address)
+		require.NoError(t, signer.SignEnvelope(tx))
+		require.Len(t, tx.EnvelopeSignatures, 1)
+
+		valid, err := signerKey.PublicKey().Verify(
+			tx.EnvelopeSignatures[0].Signature,
+			tx.EnvelopeMessage(),
+			crypto.NewSHA2_256(),
+		)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("Registry script", func(t *testing.T) {
+		registered = []cadence.Value{cadence.NewAddress(flow.HexToAddress("03"))}
+
+		signer, err := client.NewAccountSigner(ctx, newClient(t), signerKey, client.AccountSignerConfig{
+			RegistryScript: []byte("pub fun main(publicKey: String): [Address] { return [] }"),
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, flow.HexToAddress("03"), signer.Address)
+		assert.Equal(t, 0, signer.KeyIndex)
+	})
+
+	t.Run("Several accounts", func(t *testing.T) {
+		registered = []cadence.Value{cadence.NewAddress(flow.HexToAddress("03"))}
+
+		_, err := client.NewAccountSigner(ctx, newClient(t), signerKey, client.AccountSignerConfig{
+			Addresses:      []flow.Address{flow.HexToAddress("02")},
+			RegistryScript: []byte("pub fun main(publicKey: String): [Address] { return [] }"),
+		})
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, client.ErrKeyNotFound))
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, err := client.NewAccountSigner(ctx, newClient(t), signerKey, client.AccountSignerConfig{
+			Addresses: []flow.Address{flow.HexToAddress("01")},
+		})
+		assert.True(t, errors.Is(err, client.ErrKeyNotFound))
+	})
+}
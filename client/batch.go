@@ -0,0 +1,112 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/onflow/cadence"
+)
+
+// DefaultScriptParallelism is the default maximum number of scripts of a batch
+// executed at once.
+const DefaultScriptParallelism = 8
+
+// A ScriptResult is the result of a script of a batch: its value, or the error it
+// failed with.
+type ScriptResult struct {
+	Value cadence.Value
+	Err   error
+}
+
+// WithScriptParallelism returns a client sharing the connection of c whose
+// ExecuteScripts executes at most parallelism scripts at once.
+//
+// Defaults to DefaultScriptParallelism.
+func (c *Client) WithScriptParallelism(parallelism int) *Client {
+	if parallelism <= 0 {
+		parallelism = DefaultScriptParallelism
+	}
+
+	batching := *c
+	batching.scripts = parallelism
+	return &batching
+}
+
+// ExecuteScripts executes scripts concurrently against the latest sealed execution
+// state, e.g. to read the balances of many accounts, and returns their results in
+// the order of the requests.
+//
+// A script that fails does not stop the others: its error is returned in its
+// result. Scripts that are not started when ctx is done fail with the error of ctx.
+//
+// Each script may read a different block. Use a Snapshot for results that must be
+// consistent with each other.
+func (c *Client) ExecuteScripts(ctx context.Context, requests []ScriptRequest) []ScriptResult {
+	results := make([]ScriptResult, len(requests))
+	indexes := make(chan int)
+
+	workers := c.scripts
+	if workers <= 0 {
+		workers = DefaultScriptParallelism
+	}
+	if workers > len(requests) {
+		workers = len(requests)
+	}
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					results[i] = ScriptResult{Err: err}
+					continue
+				}
+
+				value, err := c.ExecuteScriptAtLatestBlock(ctx, requests[i].Script, requests[i].Arguments)
+				results[i] = ScriptResult{Value: value, Err: err}
+			}
+		}()
+	}
+
+	next := 0
+
+feed:
+	for ; next < len(requests); next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	for i := next; i < len(requests); i++ {
+		results[i] = ScriptResult{Err: ctx.Err()}
+	}
+
+	return results
+}
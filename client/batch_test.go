@@ -0,0 +1,113 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestClient_ExecuteScripts(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maximum  int
+	)
+
+	invoker := client.InvokerFunc(func(
+		_ context.Context,
+		method string,
+		args interface{},
+		reply interface{},
+		_ ...grpc.CallOption,
+	) error {
+		require.Equal(t, client.MethodExecuteScriptAtLatestBlock, method)
+
+		mu.Lock()
+		inFlight++
+		if inFlight > maximum {
+			maximum = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		argument, err := jsoncdc.Decode(args.(*access.ExecuteScriptAtLatestBlockRequest).Arguments[0])
+		require.NoError(t, err)
+
+		if argument.(cadence.Int).Int() == 3 {
+			return errors.New("script failed")
+		}
+
+		reply.(*access.ExecuteScriptResponse).Value, err = jsoncdc.Encode(argument)
+		return err
+	})
+
+	c := client.NewFromEndpoint(client.NewEndpoint("node", invoker)).WithScriptParallelism(3)
+
+	requests := make([]client.ScriptRequest, 10)
+	for i := range requests {
+		requests[i] = client.ScriptRequest{
+			Script:    []byte("pub fun main(n: Int): Int { return n }"),
+			Arguments: []cadence.Value{cadence.NewInt(i)},
+		}
+	}
+
+	results := c.ExecuteScripts(ctx, requests)
+	require.Len(t, results, len(requests))
+
+	for i, result := range results {
+		if i == 3 {
+			assert.Error(t, result.Err)
+			continue
+		}
+		require.NoError(t, result.Err)
+		assert.Equal(t, cadence.NewInt(i), result.Value)
+	}
+
+	assert.True(t, maximum <= 3)
+	assert.True(t, maximum > 1)
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		for _, result := range c.ExecuteScripts(ctx, requests) {
+			assert.Error(t, result.Err)
+		}
+	})
+}
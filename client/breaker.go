@@ -0,0 +1,246 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrCircuitOpen is returned by a circuit breaker for calls made while its circuit
+// is open, without calling the Access API.
+var ErrCircuitOpen = errcode.New(errcode.CircuitOpen, "client: circuit breaker is open")
+
+// Defaults of CircuitBreakerConfig.
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// A CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed means that calls are sent to the Access API.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means that calls fail with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen means that a single probe call is sent to the Access API to
+	// check whether it recovered, and other calls fail with ErrCircuitOpen.
+	CircuitHalfOpen
+)
+
+// String returns the string representation of this state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed calls after which the
+	// circuit opens. See DefaultBreakerThreshold.
+	FailureThreshold int
+	// Cooldown is the time the circuit stays open before a probe call is sent. See
+	// DefaultBreakerCooldown.
+	Cooldown time.Duration
+	// IsFailure returns true if a call failed because the Access API could not serve
+	// it. Defaults to calls that failed because the Access API is unavailable or
+	// timed out.
+	IsFailure func(err error) bool
+	// OnStateChange, if set, is called each time the circuit changes state.
+	OnStateChange func(state CircuitState)
+}
+
+// A CircuitBreaker stops the calls to an Access API after consecutive failures, so
+// that a dead node fails calls right away instead of holding each of them until it
+// times out.
+//
+// After FailureThreshold consecutive failures, the circuit opens and calls fail
+// with ErrCircuitOpen for Cooldown. The next call is then sent as a probe: its
+// success closes the circuit, and its failure opens it again.
+//
+// A circuit breaker is installed with WithCircuitBreaker, or as a middleware.
+// Clients with several endpoints should give each endpoint its own breaker with
+// Endpoint.WithCircuitBreaker.
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker returns a closed circuit breaker.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = DefaultBreakerThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultBreakerCooldown
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isBreakerFailure
+	}
+
+	return &CircuitBreaker{config: config}
+}
+
+// Middleware returns a middleware that sends calls through the circuit breaker.
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			probe, err := b.allow()
+			if err != nil {
+				return err
+			}
+
+			err = next.Invoke(ctx, method, args, reply, opts...)
+			b.record(probe, err)
+
+			return err
+		})
+	}
+}
+
+// WithCircuitBreaker returns a copy of the endpoint whose calls go through a new
+// circuit breaker, so that each endpoint of a router has its own circuit.
+//
+// Closing the copy closes the endpoint.
+func (e *Endpoint) WithCircuitBreaker(config CircuitBreakerConfig) *Endpoint {
+	return &Endpoint{
+		Address: e.Address,
+		Invoker: Chain(e.Invoker, NewCircuitBreaker(config).Middleware()),
+		close:   e.Close,
+	}
+}
+
+// State returns the state of the circuit.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// allow returns an error if a call may not be sent, and true if the call is the
+// probe of a half-open circuit.
+func (b *CircuitBreaker) allow() (bool, error) {
+	b.mu.Lock()
+
+	switch {
+	case b.state == CircuitClosed:
+		b.mu.Unlock()
+		return false, nil
+	case b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.Cooldown:
+		notify := b.transition(CircuitHalfOpen)
+		b.mu.Unlock()
+		notify()
+		return true, nil
+	}
+
+	// the circuit is open, or a probe is in flight
+	b.mu.Unlock()
+	return false, ErrCircuitOpen
+}
+
+// record records the outcome of a call.
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mu.Lock()
+
+	notify := func() {}
+
+	switch {
+	case err != nil && b.config.IsFailure(err):
+		b.failures++
+		if probe || b.failures >= b.config.FailureThreshold {
+			b.openedAt = time.Now()
+			notify = b.transition(CircuitOpen)
+		}
+	case probe && isCanceled(err):
+		// the probe was cancelled by the caller: the next call probes again
+		notify = b.transition(CircuitOpen)
+	default:
+		// the Access API served the call, even if it rejected it
+		b.failures = 0
+		if probe {
+			notify = b.transition(CircuitClosed)
+		}
+	}
+
+	b.mu.Unlock()
+	notify()
+}
+
+// transition changes the state of the circuit, and returns a function that reports
+// the change, to call once the lock is released.
+func (b *CircuitBreaker) transition(state CircuitState) func() {
+	if b.state == state || b.config.OnStateChange == nil {
+		b.state = state
+		return func() {}
+	}
+
+	b.state = state
+	return func() { b.config.OnStateChange(state) }
+}
+
+// isBreakerFailure returns true if a call failed because the Access API is
+// unavailable or timed out. Calls cancelled by the caller are not failures.
+func isBreakerFailure(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+
+	return false
+}
+
+// isCanceled returns true if a call was cancelled by the caller.
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+}
@@ -0,0 +1,142 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// failingNode is an Access API node that fails all calls with the given error.
+type failingNode struct {
+	mu    sync.Mutex
+	err   error
+	calls int32
+}
+
+func (n *failingNode) setError(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.err = err
+}
+
+func (n *failingNode) Invoke(context.Context, string, interface{}, interface{}, ...grpc.CallOption) error {
+	atomic.AddInt32(&n.calls, 1)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	t.Run("Opens after consecutive failures and recovers", func(t *testing.T) {
+		node := &failingNode{err: unavailable}
+
+		var (
+			mu     sync.Mutex
+			states []client.CircuitState
+		)
+
+		breaker := client.NewCircuitBreaker(client.CircuitBreakerConfig{
+			FailureThreshold: 2,
+			Cooldown:         20 * time.Millisecond,
+			OnStateChange: func(state client.CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+				states = append(states, state)
+			},
+		})
+		c := client.NewFromEndpoint(client.NewEndpoint("node", client.Chain(node, breaker.Middleware())))
+
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, codes.Unavailable, status.Code(c.Ping(ctx)))
+		}
+		assert.Equal(t, client.CircuitOpen, breaker.State())
+
+		// calls fail without reaching the node
+		err := c.Ping(ctx)
+		assert.True(t, errors.Is(err, client.ErrCircuitOpen))
+		assert.Equal(t, errcode.CircuitOpen, errcode.Of(err))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&node.calls))
+
+		// a failed probe opens the circuit again
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, client.CircuitHalfOpen, breaker.State())
+		assert.Equal(t, codes.Unavailable, status.Code(c.Ping(ctx)))
+		assert.True(t, errors.Is(c.Ping(ctx), client.ErrCircuitOpen))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&node.calls))
+
+		// a successful probe closes it
+		node.setError(nil)
+		time.Sleep(30 * time.Millisecond)
+		require.NoError(t, c.Ping(ctx))
+		assert.Equal(t, client.CircuitClosed, breaker.State())
+		require.NoError(t, c.Ping(ctx))
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []client.CircuitState{
+			client.CircuitOpen,
+			client.CircuitHalfOpen,
+			client.CircuitOpen,
+			client.CircuitHalfOpen,
+			client.CircuitClosed,
+		}, states)
+	})
+
+	t.Run("Ignores rejected calls", func(t *testing.T) {
+		node := &failingNode{err: status.Error(codes.NotFound, "not found")}
+
+		endpoint := client.NewEndpoint("node", node).WithCircuitBreaker(client.CircuitBreakerConfig{
+			FailureThreshold: 1,
+		})
+		c := client.NewFromEndpoint(endpoint)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, codes.NotFound, status.Code(c.Ping(ctx)))
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&node.calls))
+	})
+
+	t.Run("Counts timeouts", func(t *testing.T) {
+		node := &failingNode{err: context.DeadlineExceeded}
+
+		breaker := client.NewCircuitBreaker(client.CircuitBreakerConfig{FailureThreshold: 1})
+		c := client.NewFromEndpoint(client.NewEndpoint("node", client.Chain(node, breaker.Middleware())))
+
+		assert.Error(t, c.Ping(ctx))
+		assert.Equal(t, client.CircuitOpen, breaker.State())
+	})
+}
@@ -0,0 +1,175 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// ChunkConfig configures how a client splits event queries over large height ranges.
+type ChunkConfig struct {
+	// MaxRange is the maximum number of blocks requested in a single event query.
+	//
+	// Defaults to 250 blocks, the limit enforced by Access nodes.
+	MaxRange uint64
+	// Parallelism is the maximum number of queries in flight for a single range.
+	//
+	// Defaults to 1: chunks are queried one after the other.
+	Parallelism int
+}
+
+func (c ChunkConfig) withDefaults() ChunkConfig {
+	if c.MaxRange == 0 {
+		c.MaxRange = defaultMaxPollRange
+	}
+
+	if c.Parallelism <= 0 {
+		c.Parallelism = 1
+	}
+
+	return c
+}
+
+// WithEventChunking returns a client sharing the connection of c whose
+// GetEventsForHeightRange splits height ranges larger than config.MaxRange into
+// chunks, queries the chunks, and returns the events of all blocks in order:
+//
+//	blocks, err := c.WithEventChunking(client.ChunkConfig{Parallelism: 4}).
+//		GetEventsForHeightRange(ctx, client.EventRangeQuery{
+//			Type:        "flow.AccountCreated",
+//			StartHeight: 1000,
+//			EndHeight:   20000,
+//		})
+//
+// If a chunk fails, the queries of the other chunks are canceled and the error is
+// returned.
+func (c *Client) WithEventChunking(config ChunkConfig) *Client {
+	config = config.withDefaults()
+
+	chunking := *c
+	chunking.chunking = &config
+	return &chunking
+}
+
+// getEventsInChunks queries the events of a height range in chunks of at most
+// MaxRange blocks, with up to Parallelism queries in flight.
+//
+// Chunks are generated as they are queried, so that a large range does not
+// allocate the bounds of all its chunks up front.
+func (c *Client) getEventsInChunks(ctx context.Context, query EventRangeQuery) ([]BlockEvents, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type chunk struct {
+		index int
+		query EventRangeQuery
+	}
+
+	chunks := make(chan chunk)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		results  = make(map[int][]BlockEvents)
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	for w := 0; w < c.chunking.Parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for chunk := range chunks {
+				// the range failed or was canceled
+				if ctx.Err() != nil {
+					continue
+				}
+
+				blocks, err := c.getEventsForHeightRange(ctx, chunk.query)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+
+				mu.Lock()
+				results[chunk.index] = blocks
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var count int
+
+feed:
+	for start := query.StartHeight; ; start += c.chunking.MaxRange {
+		end := start + c.chunking.MaxRange - 1
+		if end >= query.EndHeight || end < start {
+			end = query.EndHeight
+		}
+
+		next := chunk{
+			index: count,
+			query: EventRangeQuery{
+				Type:        query.Type,
+				StartHeight: start,
+				EndHeight:   end,
+			},
+		}
+
+		select {
+		case chunks <- next:
+			count++
+		case <-ctx.Done():
+			break feed
+		}
+
+		if end == query.EndHeight {
+			break
+		}
+	}
+
+	close(chunks)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// the parent context was canceled before all chunks were queried
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var n int
+	for _, blocks := range results {
+		n += len(blocks)
+	}
+
+	all := make([]BlockEvents, 0, n)
+	for i := 0; i < count; i++ {
+		all = append(all, results[i]...)
+	}
+
+	return all, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// rangeNode is a fake Access API node that serves event queries of at most 250
+// blocks, with one empty block result per height.
+type rangeNode struct {
+	mu       sync.Mutex
+	ranges   [][2]uint64
+	inFlight int
+	peak     int
+	fail     uint64
+}
+
+func (n *rangeNode) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	req := args.(*access.GetEventsForHeightRangeRequest)
+
+	n.mu.Lock()
+	n.ranges = append(n.ranges, [2]uint64{req.StartHeight, req.EndHeight})
+	n.inFlight++
+	if n.inFlight > n.peak {
+		n.peak = n.inFlight
+	}
+	n.mu.Unlock()
+
+	defer func() {
+		n.mu.Lock()
+		n.inFlight--
+		n.mu.Unlock()
+	}()
+
+	// let parallel queries overlap
+	time.Sleep(5 * time.Millisecond)
+
+	if req.EndHeight-req.StartHeight >= 250 {
+		return status.Error(codes.InvalidArgument, "height range too large")
+	}
+
+	if n.fail != 0 && req.StartHeight <= n.fail && n.fail <= req.EndHeight {
+		return status.Error(codes.Internal, "failed")
+	}
+
+	res := reply.(*access.EventsResponse)
+	for height := req.StartHeight; height <= req.EndHeight; height++ {
+		res.Results = append(res.Results, &access.EventsResponse_Result{
+			BlockHeight:    height,
+			BlockTimestamp: ptypes.TimestampNow(),
+		})
+	}
+
+	return nil
+}
+
+func TestClient_WithEventChunking(t *testing.T) {
+	ctx := context.Background()
+	query := client.EventRangeQuery{Type: "flow.AccountCreated", StartHeight: 100, EndHeight: 1099}
+
+	t.Run("Sequential", func(t *testing.T) {
+		node := &rangeNode{}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		_, err := c.GetEventsForHeightRange(ctx, query)
+		require.Error(t, err)
+
+		node.ranges = nil
+
+		blocks, err := c.WithEventChunking(client.ChunkConfig{}).GetEventsForHeightRange(ctx, query)
+		require.NoError(t, err)
+
+		require.Len(t, blocks, 1000)
+		for i, block := range blocks {
+			assert.Equal(t, query.StartHeight+uint64(i), block.Height)
+		}
+
+		assert.Equal(t, [][2]uint64{{100, 349}, {350, 599}, {600, 849}, {850, 1099}}, node.ranges)
+		assert.Equal(t, 1, node.peak)
+	})
+
+	t.Run("Parallel", func(t *testing.T) {
+		node := &rangeNode{}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).
+			WithEventChunking(client.ChunkConfig{MaxRange: 100, Parallelism: 4})
+
+		blocks, err := c.GetEventsForHeightRange(ctx, query)
+		require.NoError(t, err)
+
+		require.Len(t, blocks, 1000)
+		for i, block := range blocks {
+			assert.Equal(t, query.StartHeight+uint64(i), block.Height)
+		}
+
+		sort.Slice(node.ranges, func(i, j int) bool { return node.ranges[i][0] < node.ranges[j][0] })
+		assert.Len(t, node.ranges, 10)
+		assert.Equal(t, [2]uint64{1000, 1099}, node.ranges[9])
+		assert.True(t, node.peak > 1 && node.peak <= 4)
+	})
+
+	t.Run("Small range", func(t *testing.T) {
+		node := &rangeNode{}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).
+			WithEventChunking(client.ChunkConfig{})
+
+		blocks, err := c.GetEventsForHeightRange(ctx, client.EventRangeQuery{StartHeight: 10, EndHeight: 259})
+		require.NoError(t, err)
+		assert.Len(t, blocks, 250)
+		assert.Equal(t, [][2]uint64{{10, 259}}, node.ranges)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		node := &rangeNode{fail: 700}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).
+			WithEventChunking(client.ChunkConfig{MaxRange: 100, Parallelism: 2})
+
+		_, err := c.GetEventsForHeightRange(ctx, query)
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+
+	t.Run("Unbounded range", func(t *testing.T) {
+		node := &rangeNode{fail: 1}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).
+			WithEventChunking(client.ChunkConfig{MaxRange: 1})
+
+		// chunks are generated as they are queried, and stop at the first error
+		_, err := c.GetEventsForHeightRange(ctx, client.EventRangeQuery{StartHeight: 0, EndHeight: math.MaxUint64})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Len(t, node.ranges, 2)
+	})
+}
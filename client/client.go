@@ -29,8 +29,8 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"github.com/golang/protobuf/ptypes"
-	"time"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow/protobuf/go/flow/access"
@@ -47,10 +47,20 @@ type RPCClient interface {
 
 // A Client is a gRPC Client for the Flow Access API.
 type Client struct {
-	rpcClient RPCClient
-	close     func() error
+	rpcClient  RPCClient
+	invoker    Invoker
+	close      func() error
+	lazyEvents bool
+	pool       *Pool
+	chunking   *ChunkConfig
+	// scripts is the maximum number of scripts of a batch executed at once.
+	scripts int
+	// endpoint is the address of the node serving all calls, if known.
+	endpoint string
 }
 
+var _ flow.Client = (*Client)(nil)
+
 // New initializes a Flow client with the default gRPC provider.
 //
 // An error will be returned if the host is unreachable.
@@ -60,12 +70,20 @@ func New(addr string, opts ...grpc.DialOption) (*Client, error) {
 		return nil, err
 	}
 
+	return newFromConn(addr, conn), nil
+}
+
+// newFromConn returns a client that sends all calls over the given connection, and
+// closes it when the client is closed.
+func newFromConn(addr string, conn *grpc.ClientConn) *Client {
 	grpcClient := access.NewAccessAPIClient(conn)
 
 	return &Client{
 		rpcClient: grpcClient,
+		invoker:   conn,
 		close:     func() error { return conn.Close() },
-	}, nil
+		endpoint:  addr,
+	}
 }
 
 // NewFromRPCClient initializes a Flow client using a pre-configured gRPC provider.
@@ -76,12 +94,57 @@ func NewFromRPCClient(rpcClient RPCClient) *Client {
 	}
 }
 
+// RPC returns the Access API client that the client sends its calls through, as an
+// escape hatch for the Access API methods that the client does not wrap. Its calls
+// go through the same connection, middleware and retries as the calls of the client.
+//
+// Requests and responses are protobuf messages, converted from and to SDK types with
+// the client/convert package:
+//
+//	res, err := c.RPC().GetTransaction(ctx, &access.GetTransactionRequest{
+//		Id: convert.IdentifierToMessage(txID),
+//	})
+//	...
+//	tx, err := convert.MessageToTransaction(res.GetTransaction())
+func (c *Client) RPC() RPCClient {
+	return c.rpcClient
+}
+
+// Invoke calls an Access API method by its full gRPC name, e.g. a method that Access
+// nodes serve but that is not part of the Access API client of the SDK yet:
+//
+//	err := c.Invoke(ctx, "/flow.access.AccessAPI/GetExecutionResultForBlockID", req, res)
+//
+// The call goes through the connection and middleware of the client, but is not
+// retried. Errors are returned as RPCError values. An error is returned for clients
+// created with NewFromRPCClient, which have no connection to call it on.
+func (c *Client) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	if c.invoker == nil {
+		return fmt.Errorf("client: cannot invoke %s without a connection", method)
+	}
+
+	if err := c.invoker.Invoke(ctx, method, args, reply, opts...); err != nil {
+		return newRPCError(ctx, err)
+	}
+
+	return nil
+}
+
 // Close closes the client connection.
 func (c *Client) Close() error {
 	return c.close()
 }
 
 // Ping is used to check if the access node is alive and healthy.
+//
+// PingNode also measures the latency of the ping, and HealthCheck checks that the
+// node serves the latest sealed block in time.
 func (c *Client) Ping(ctx context.Context) error {
 	_, err := c.rpcClient.Ping(ctx, &access.PingRequest{})
 	return err
@@ -98,7 +161,7 @@ func (c *Client) GetLatestBlockHeader(
 
 	res, err := c.rpcClient.GetLatestBlockHeader(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
 	return getBlockHeaderResult(res)
@@ -112,7 +175,7 @@ func (c *Client) GetBlockHeaderByID(ctx context.Context, blockID flow.Identifier
 
 	res, err := c.rpcClient.GetBlockHeaderByID(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
 	return getBlockHeaderResult(res)
@@ -126,7 +189,7 @@ func (c *Client) GetBlockHeaderByHeight(ctx context.Context, height uint64) (*fl
 
 	res, err := c.rpcClient.GetBlockHeaderByHeight(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
 	return getBlockHeaderResult(res)
@@ -149,10 +212,10 @@ func (c *Client) GetLatestBlock(ctx context.Context, isSealed bool) (*flow.Block
 
 	res, err := c.rpcClient.GetLatestBlock(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
-	return getBlockResult(res)
+	return c.getBlockResult(res)
 }
 
 // GetBlockByID gets a full block by ID.
@@ -163,10 +226,10 @@ func (c *Client) GetBlockByID(ctx context.Context, blockID flow.Identifier) (*fl
 
 	res, err := c.rpcClient.GetBlockByID(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
-	return getBlockResult(res)
+	return c.getBlockResult(res)
 }
 
 // GetBlockByHeight gets a full block by height.
@@ -177,19 +240,22 @@ func (c *Client) GetBlockByHeight(ctx context.Context, height uint64) (*flow.Blo
 
 	res, err := c.rpcClient.GetBlockByHeight(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
-	return getBlockResult(res)
+	return c.getBlockResult(res)
 }
 
-func getBlockResult(res *access.BlockResponse) (*flow.Block, error) {
-	block, err := convert.MessageToBlock(res.GetBlock())
+func (c *Client) getBlockResult(res *access.BlockResponse) (*flow.Block, error) {
+	block := c.pool.block()
+
+	err := convert.MessageToBlockInto(res.GetBlock(), block)
 	if err != nil {
+		c.pool.ReleaseBlock(block)
 		return nil, newMessageToEntityError(entityBlock, err)
 	}
 
-	return &block, nil
+	return block, nil
 }
 
 // GetCollection gets a collection by ID.
@@ -200,7 +266,7 @@ func (c *Client) GetCollection(ctx context.Context, colID flow.Identifier) (*flo
 
 	res, err := c.rpcClient.GetCollectionByID(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
 	result, err := convert.MessageToCollection(res.GetCollection())
@@ -224,7 +290,7 @@ func (c *Client) SendTransaction(ctx context.Context, tx flow.Transaction) error
 
 	_, err = c.rpcClient.SendTransaction(ctx, req)
 	if err != nil {
-		return newRPCError(err)
+		return newRPCError(ctx, err)
 	}
 
 	return nil
@@ -238,15 +304,18 @@ func (c *Client) GetTransaction(ctx context.Context, txID flow.Identifier) (*flo
 
 	res, err := c.rpcClient.GetTransaction(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
-	result, err := convert.MessageToTransaction(res.GetTransaction())
+	result := c.pool.transaction()
+
+	err = convert.MessageToTransactionInto(res.GetTransaction(), result)
 	if err != nil {
+		c.pool.ReleaseTransaction(result)
 		return nil, newMessageToEntityError(entityTransaction, err)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 // GetTransactionResult gets the result of a transaction.
@@ -257,7 +326,7 @@ func (c *Client) GetTransactionResult(ctx context.Context, txID flow.Identifier)
 
 	res, err := c.rpcClient.GetTransactionResult(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
 	result, err := convert.MessageToTransactionResult(res)
@@ -281,7 +350,35 @@ func (c *Client) GetAccountAtLatestBlock(ctx context.Context, address flow.Addre
 
 	res, err := c.rpcClient.GetAccountAtLatestBlock(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
+	}
+
+	account, err := convert.MessageToAccount(res.GetAccount())
+	if err != nil {
+		return nil, newMessageToEntityError(entityAccount, err)
+	}
+
+	return &account, nil
+}
+
+// GetAccountAtBlockHeight gets an account by address at the given block height, e.g.
+// to inspect the keys, balance or contracts the account had in the past.
+//
+// Access nodes only serve the heights of their spork, and may prune the state of
+// older blocks: accounts at other heights are reported as not found.
+func (c *Client) GetAccountAtBlockHeight(
+	ctx context.Context,
+	address flow.Address,
+	height uint64,
+) (*flow.Account, error) {
+	req := &access.GetAccountAtBlockHeightRequest{
+		Address:     address.Bytes(),
+		BlockHeight: height,
+	}
+
+	res, err := c.rpcClient.GetAccountAtBlockHeight(ctx, req)
+	if err != nil {
+		return nil, newRPCError(ctx, err)
 	}
 
 	account, err := convert.MessageToAccount(res.GetAccount())
@@ -293,6 +390,8 @@ func (c *Client) GetAccountAtLatestBlock(ctx context.Context, address flow.Addre
 }
 
 // ExecuteScriptAtLatestBlock executes a read-only Cadence script against the latest sealed execution state.
+//
+// A failure of the Cadence runtime is returned as a ScriptError.
 func (c *Client) ExecuteScriptAtLatestBlock(
 	ctx context.Context,
 	script []byte,
@@ -310,7 +409,7 @@ func (c *Client) ExecuteScriptAtLatestBlock(
 
 	res, err := c.rpcClient.ExecuteScriptAtLatestBlock(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newScriptError(ctx, err)
 	}
 
 	return executeScriptResult(res)
@@ -318,6 +417,8 @@ func (c *Client) ExecuteScriptAtLatestBlock(
 
 // ExecuteScriptAtBlockID executes a ready-only Cadence script against the execution state
 // at the block with the given ID.
+//
+// A failure of the Cadence runtime is returned as a ScriptError.
 func (c *Client) ExecuteScriptAtBlockID(
 	ctx context.Context,
 	blockID flow.Identifier,
@@ -338,7 +439,7 @@ func (c *Client) ExecuteScriptAtBlockID(
 
 	res, err := c.rpcClient.ExecuteScriptAtBlockID(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newScriptError(ctx, err)
 	}
 
 	return executeScriptResult(res)
@@ -346,6 +447,8 @@ func (c *Client) ExecuteScriptAtBlockID(
 
 // ExecuteScriptAtBlockHeight executes a ready-only Cadence script against the execution state
 // at the given block height.
+//
+// A failure of the Cadence runtime is returned as a ScriptError.
 func (c *Client) ExecuteScriptAtBlockHeight(
 	ctx context.Context,
 	height uint64,
@@ -366,7 +469,7 @@ func (c *Client) ExecuteScriptAtBlockHeight(
 
 	res, err := c.rpcClient.ExecuteScriptAtBlockHeight(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newScriptError(ctx, err)
 	}
 
 	return executeScriptResult(res)
@@ -382,26 +485,26 @@ func executeScriptResult(res *access.ExecuteScriptResponse) (cadence.Value, erro
 }
 
 // EventRangeQuery defines a query for Flow events.
-type EventRangeQuery struct {
-	// The event type to search for. If empty, no filtering by type is done.
-	Type string
-	// The block height to begin looking for events
-	StartHeight uint64
-	// The block height to end looking for events (inclusive)
-	EndHeight uint64
-}
+type EventRangeQuery = flow.EventRangeQuery
 
 // BlockEvents are the events that occurred in a specific block.
-type BlockEvents struct {
-	BlockID flow.Identifier
-	Height  uint64
-	BlockTimestamp time.Time
-	Events  []flow.Event
-}
+type BlockEvents = flow.BlockEvents
 
 // GetEventsForHeightRange retrieves events for all sealed blocks between the start and end block
 // heights (inclusive) with the given type.
+//
+// A client returned by WithEventChunking splits ranges larger than the limit of
+// Access nodes into several queries.
 func (c *Client) GetEventsForHeightRange(ctx context.Context, query EventRangeQuery) ([]BlockEvents, error) {
+	if c.chunking != nil && query.EndHeight >= query.StartHeight &&
+		query.EndHeight-query.StartHeight >= c.chunking.MaxRange {
+		return c.getEventsInChunks(ctx, query)
+	}
+
+	return c.getEventsForHeightRange(ctx, query)
+}
+
+func (c *Client) getEventsForHeightRange(ctx context.Context, query EventRangeQuery) ([]BlockEvents, error) {
 	req := &access.GetEventsForHeightRangeRequest{
 		Type:        query.Type,
 		StartHeight: query.StartHeight,
@@ -410,10 +513,10 @@ func (c *Client) GetEventsForHeightRange(ctx context.Context, query EventRangeQu
 
 	res, err := c.rpcClient.GetEventsForHeightRange(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
-	return getEventsResult(res)
+	return c.getEventsResult(res)
 }
 
 // GetEventsForBlockIDs retrieves events with the given type from the specified block IDs.
@@ -429,32 +532,50 @@ func (c *Client) GetEventsForBlockIDs(
 
 	res, err := c.rpcClient.GetEventsForBlockIDs(ctx, req)
 	if err != nil {
-		return nil, newRPCError(err)
+		return nil, newRPCError(ctx, err)
 	}
 
-	return getEventsResult(res)
+	return c.getEventsResult(res)
+}
+
+// WithLazyEvents returns a client sharing the connection of c that decodes the
+// payloads of events returned by GetEventsForHeightRange and GetEventsForBlockIDs
+// on first access, rather than when the response is received.
+//
+// Values of lazy events are read with Event.DecodeValue or Event.Field.
+func (c *Client) WithLazyEvents() *Client {
+	lazy := *c
+	lazy.lazyEvents = true
+	return &lazy
 }
 
-func getEventsResult(res *access.EventsResponse) ([]BlockEvents, error) {
+func (c *Client) getEventsResult(res *access.EventsResponse) ([]BlockEvents, error) {
 	resultMessages := res.GetResults()
 
 	results := make([]BlockEvents, len(resultMessages))
 	for i, result := range resultMessages {
 		eventMessages := result.GetEvents()
 
-		events := make([]flow.Event, len(eventMessages))
+		events := c.pool.eventSlice(len(eventMessages))[:len(eventMessages)]
+
+		for j, m := range eventMessages {
+			if c.lazyEvents {
+				events[j] = convert.MessageToLazyEvent(m)
+				continue
+			}
 
-		for i, m := range eventMessages {
 			evt, err := convert.MessageToEvent(m)
 			if err != nil {
+				c.pool.ReleaseEvents(append(results[:i], BlockEvents{Events: events})...)
 				return nil, newMessageToEntityError(entityEvent, err)
 			}
 
-			events[i] = evt
+			events[j] = evt
 		}
 
 		blockTimestamp, err := ptypes.Timestamp(result.BlockTimestamp)
 		if err != nil {
+			c.pool.ReleaseEvents(append(results[:i], BlockEvents{Events: events})...)
 			return nil, newMessageToEntityError(entityEvent, err)
 		}
 		results[i] = BlockEvents{
@@ -467,3 +588,13 @@ func getEventsResult(res *access.EventsResponse) ([]BlockEvents, error) {
 
 	return results, nil
 }
+
+// GetNetworkParameters gets the chain ID of the network served by the Access node.
+func (c *Client) GetNetworkParameters(ctx context.Context) (flow.ChainID, error) {
+	res, err := c.rpcClient.GetNetworkParameters(ctx, &access.GetNetworkParametersRequest{})
+	if err != nil {
+		return "", newRPCError(ctx, err)
+	}
+
+	return flow.ChainID(res.GetChainId()), nil
+}
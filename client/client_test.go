@@ -20,6 +20,7 @@ package client_test
 
 import (
 	"context"
+	"errors"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/onflow/cadence"
 	jsoncdc "github.com/onflow/cadence/encoding/json"
@@ -28,6 +29,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"testing"
@@ -433,6 +435,40 @@ func TestClient_GetAccountAtLatestBlock(t *testing.T) {
 	}))
 }
 
+func TestClient_GetAccountAtBlockHeight(t *testing.T) {
+	accounts := test.AccountGenerator()
+	addresses := test.AddressGenerator()
+
+	t.Run("Success", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		expectedAccount := accounts.New()
+		response := &access.AccountResponse{
+			Account: convert.AccountToMessage(*expectedAccount),
+		}
+
+		rpc.On("GetAccountAtBlockHeight", ctx, &access.GetAccountAtBlockHeightRequest{
+			Address:     expectedAccount.Address.Bytes(),
+			BlockHeight: 42,
+		}).Return(response, nil)
+
+		account, err := c.GetAccountAtBlockHeight(ctx, expectedAccount.Address, 42)
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedAccount, account)
+	}))
+
+	t.Run("Not found error", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		address := addresses.New()
+
+		rpc.On("GetAccountAtBlockHeight", ctx, mock.Anything).
+			Return(nil, errNotFound)
+
+		account, err := c.GetAccountAtBlockHeight(ctx, address, 42)
+		assert.Error(t, err)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, account)
+	}))
+}
+
 func TestClient_ExecuteScriptAtLatestBlock(t *testing.T) {
 	t.Run("Success", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
 		expectedValue := cadence.NewInt(42)
@@ -675,6 +711,43 @@ func TestClient_GetEventsForHeightRange(t *testing.T) {
 		}),
 	)
 
+	t.Run(
+		"Lazy events",
+		clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+			event := events.New()
+			eventMsg, _ := convert.EventToMessage(event)
+
+			response := &access.EventsResponse{
+				Results: []*access.EventsResponse_Result{
+					{
+						BlockId:        ids.New().Bytes(),
+						BlockHeight:    1,
+						BlockTimestamp: ptypes.TimestampNow(),
+						Events:         []*entities.Event{eventMsg},
+					},
+				},
+			}
+
+			rpc.On("GetEventsForHeightRange", ctx, mock.Anything).Return(response, nil)
+
+			blocks, err := c.WithLazyEvents().GetEventsForHeightRange(ctx, client.EventRangeQuery{
+				Type:        "foo",
+				StartHeight: 1,
+				EndHeight:   1,
+			})
+			require.NoError(t, err)
+			require.Len(t, blocks, 1)
+
+			lazy := blocks[0].Events[0]
+			assert.Equal(t, event.ID(), lazy.ID())
+			assert.Equal(t, eventMsg.Payload, lazy.Payload)
+
+			value, err := lazy.DecodeValue()
+			require.NoError(t, err)
+			assert.Equal(t, event.Value.Fields, value.Fields)
+		}),
+	)
+
 	t.Run("Internal error", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
 		rpc.On("GetEventsForHeightRange", ctx, mock.Anything).
 			Return(nil, errInternal)
@@ -779,3 +852,38 @@ func TestClient_GetEventsForBlockIDs(t *testing.T) {
 		assert.Empty(t, blocks)
 	}))
 }
+
+func TestClient_RPC(t *testing.T) {
+	ctx := context.Background()
+
+	var methods []string
+	node := client.InvokerFunc(func(_ context.Context, method string, _, reply interface{}, _ ...grpc.CallOption) error {
+		methods = append(methods, method)
+
+		if res, ok := reply.(*access.GetNetworkParametersResponse); ok {
+			res.ChainId = string(flow.Testnet)
+			return nil
+		}
+		return status.Error(codes.Unimplemented, "unknown method")
+	})
+
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	res, err := c.RPC().GetNetworkParameters(ctx, &access.GetNetworkParametersRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, string(flow.Testnet), res.GetChainId())
+
+	err = c.Invoke(ctx, "/flow.access.AccessAPI/GetLatestProtocolStateSnapshot", &access.PingRequest{}, &access.PingResponse{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	var rpcErr client.RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+
+	assert.Equal(t, []string{
+		client.MethodGetNetworkParameters,
+		"/flow.access.AccessAPI/GetLatestProtocolStateSnapshot",
+	}, methods)
+
+	err = client.NewFromRPCClient(c.RPC()).Invoke(ctx, client.MethodPing, &access.PingRequest{}, &access.PingResponse{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// collectionParallelism is the maximum number of transactions of a collection that
+// are fetched at once.
+const collectionParallelism = 8
+
+// GetFullCollection gets a collection by ID, with the bodies of its transactions in
+// collection order, e.g. to walk from the collection guarantees of a block to its
+// transactions.
+//
+// The Access API does not serve full collections, so the collection is fetched
+// first and its transactions are fetched by ID, with several requests in flight.
+func (c *Client) GetFullCollection(ctx context.Context, colID flow.Identifier) (*flow.FullCollection, error) {
+	collection, err := c.GetCollection(ctx, colID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	transactions := make([]*flow.Transaction, len(collection.TransactionIDs))
+	indexes := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	workers := collectionParallelism
+	if workers > len(transactions) {
+		workers = len(transactions)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				txID := collection.TransactionIDs[i]
+
+				tx, err := c.GetTransaction(ctx, txID)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("client: failed to get transaction %s of collection %s: %w", txID, colID, err)
+						cancel()
+					})
+					continue
+				}
+
+				transactions[i] = tx
+			}
+		}()
+	}
+
+feed:
+	for i := range transactions {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// the parent context was canceled before all transactions were fetched
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &flow.FullCollection{Transactions: transactions}, nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestClient_GetFullCollection(t *testing.T) {
+	ctx := context.Background()
+
+	generator := test.TransactionGenerator()
+
+	var (
+		transactions []*flow.Transaction
+		collection   flow.Collection
+	)
+	for i := 0; i < 20; i++ {
+		tx := generator.New()
+		transactions = append(transactions, tx)
+		collection.TransactionIDs = append(collection.TransactionIDs, tx.ID())
+	}
+
+	newClient := func(failed flow.Identifier) *client.Client {
+		byID := make(map[flow.Identifier]*flow.Transaction)
+		for _, tx := range transactions {
+			byID[tx.ID()] = tx
+		}
+
+		invoker := client.InvokerFunc(func(
+			_ context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			_ ...grpc.CallOption,
+		) error {
+			switch method {
+			case client.MethodGetCollectionByID:
+				reply.(*access.CollectionResponse).Collection = convert.CollectionToMessage(collection)
+			case client.MethodGetTransaction:
+				id := flow.HashToID(args.(*access.GetTransactionRequest).Id)
+				if id == failed {
+					return errors.New("unavailable")
+				}
+
+				message, err := convert.TransactionToMessage(*byID[id])
+				if err != nil {
+					return err
+				}
+				reply.(*access.TransactionResponse).Transaction = message
+			default:
+				t.Fatalf("unexpected method %s", method)
+			}
+			return nil
+		})
+
+		return client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		full, err := newClient(flow.EmptyID).GetFullCollection(ctx, collection.ID())
+		require.NoError(t, err)
+
+		require.Len(t, full.Transactions, len(transactions))
+		for i, tx := range full.Transactions {
+			assert.Equal(t, transactions[i].ID(), tx.ID())
+		}
+		assert.Equal(t, collection.ID(), full.ID())
+	})
+
+	t.Run("Transaction error", func(t *testing.T) {
+		_, err := newClient(transactions[7].ID()).GetFullCollection(ctx, collection.ID())
+		assert.Error(t, err)
+	})
+}
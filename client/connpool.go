@@ -0,0 +1,227 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// A ConnPool is a fixed set of connections to the same Access API node.
+//
+// Each gRPC connection multiplexes calls over a single HTTP/2 transport, whose
+// stream limit and flow control become a bottleneck for services making thousands
+// of concurrent calls, e.g. script executions. A pool spreads calls across its
+// connections, and sends each call over the connection with the fewest calls in
+// flight.
+//
+// The calls in flight on each connection can be capped with SetMaxInFlight, so that
+// a saturated pool rejects calls with a BackpressureError instead of queuing them
+// on the transports.
+type ConnPool struct {
+	addr     string
+	conns    []*grpc.ClientConn
+	inFlight []int32
+	next     uint32
+
+	maxInFlight int32
+	metrics     MetricsRecorder
+	total       int32
+	// latency is a moving average of the call durations in nanoseconds, from which
+	// the delay suggested to rejected calls is derived.
+	latency int64
+}
+
+// defaultPoolRetryAfter is the delay suggested to calls rejected by a saturated
+// pool before any call completed.
+const defaultPoolRetryAfter = 100 * time.Millisecond
+
+var _ Invoker = &ConnPool{}
+
+// DialPool dials size connections to the Access API node at the given address.
+//
+// If any connection cannot be dialed, all previously dialed connections are closed.
+func DialPool(addr string, size int, opts ...grpc.DialOption) (*ConnPool, error) {
+	return dialPool(context.Background(), addr, size, opts...)
+}
+
+func dialPool(ctx context.Context, addr string, size int, opts ...grpc.DialOption) (*ConnPool, error) {
+	if size <= 0 {
+		return nil, errors.New("client: pool size must be positive")
+	}
+
+	pool := &ConnPool{
+		addr:     addr,
+		conns:    make([]*grpc.ClientConn, 0, size),
+		inFlight: make([]int32, size),
+	}
+
+	for i := 0; i < size; i++ {
+		conn, err := grpc.DialContext(ctx, addr, opts...)
+		if err != nil {
+			_ = pool.Close()
+			return nil, err
+		}
+
+		pool.conns = append(pool.conns, conn)
+	}
+
+	return pool, nil
+}
+
+// NewPooled initializes a Flow client that maintains size connections to the
+// Access API at the given address, and spreads calls across them.
+func NewPooled(addr string, size int, opts ...grpc.DialOption) (*Client, error) {
+	pool, err := DialPool(addr, size, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFromPool(pool), nil
+}
+
+// newFromPool returns a client that sends calls over the connections of the
+// given pool, and closes it when the client is closed.
+func newFromPool(pool *ConnPool) *Client {
+	return &Client{
+		rpcClient: NewRPCClient(pool),
+		invoker:   pool,
+		close:     pool.Close,
+		endpoint:  pool.addr,
+	}
+}
+
+// SetMaxInFlight caps the number of calls in flight on each connection of the
+// pool. Calls made while all connections are at the cap fail right away with a
+// BackpressureError, which suggests retrying after about the average call duration.
+//
+// A cap of zero or less does not limit calls. The cap must be set before the pool
+// is used.
+func (p *ConnPool) SetMaxInFlight(perConnection int) {
+	if perConnection < 0 {
+		perConnection = 0
+	}
+	p.maxInFlight = int32(perConnection)
+}
+
+// SetMetrics reports the number of calls in flight on the pool to the recorder, if
+// it is a QueueMetricsRecorder, under the name QueueConnPool.
+//
+// The recorder must be set before the pool is used.
+func (p *ConnPool) SetMetrics(recorder MetricsRecorder) {
+	p.metrics = recorder
+}
+
+// Invoke sends a call over the connection with the fewest calls in flight.
+func (p *ConnPool) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	i := p.pick()
+
+	if inFlight := atomic.AddInt32(&p.inFlight[i], 1); p.maxInFlight > 0 && inFlight > p.maxInFlight {
+		atomic.AddInt32(&p.inFlight[i], -1)
+		return &BackpressureError{
+			Message: fmt.Sprintf("%d calls in flight on each connection of the pool", p.maxInFlight),
+			Delay:   p.retryAfter(),
+		}
+	}
+
+	p.recordInFlight(ctx, atomic.AddInt32(&p.total, 1))
+
+	start := time.Now()
+	defer func() {
+		atomic.AddInt32(&p.inFlight[i], -1)
+		p.recordInFlight(ctx, atomic.AddInt32(&p.total, -1))
+		p.observeLatency(time.Since(start))
+	}()
+
+	return p.conns[i].Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *ConnPool) recordInFlight(ctx context.Context, total int32) {
+	if p.metrics != nil {
+		recordQueue(ctx, p.metrics, QueueMetrics{Queue: QueueConnPool, InFlight: int(total)})
+	}
+}
+
+// observeLatency adds the duration of a call to the moving average, with a weight
+// of 1/8. Concurrent updates may be lost, which only makes the average less smooth.
+func (p *ConnPool) observeLatency(d time.Duration) {
+	average := atomic.LoadInt64(&p.latency)
+	if average == 0 {
+		atomic.StoreInt64(&p.latency, int64(d))
+		return
+	}
+	atomic.StoreInt64(&p.latency, average+(int64(d)-average)/8)
+}
+
+// retryAfter returns the delay suggested to rejected calls: the average duration of
+// a call, after which a call in flight has likely completed.
+func (p *ConnPool) retryAfter() time.Duration {
+	if average := time.Duration(atomic.LoadInt64(&p.latency)); average > 0 {
+		return average
+	}
+	return defaultPoolRetryAfter
+}
+
+// pick returns the index of the connection with the fewest calls in flight.
+//
+// Connections are scanned from a rotating start, so that idle connections share
+// the calls evenly. The counter is reduced before the conversion to int, which
+// would be negative once the counter exceeds math.MaxInt32 on 32-bit platforms.
+func (p *ConnPool) pick() int {
+	start := int(atomic.AddUint32(&p.next, 1) % uint32(len(p.conns)))
+
+	best := start
+	bestInFlight := atomic.LoadInt32(&p.inFlight[start])
+
+	for offset := 1; offset < len(p.conns) && bestInFlight > 0; offset++ {
+		i := (start + offset) % len(p.conns)
+		if inFlight := atomic.LoadInt32(&p.inFlight[i]); inFlight < bestInFlight {
+			best, bestInFlight = i, inFlight
+		}
+	}
+
+	return best
+}
+
+// Size returns the number of connections in the pool.
+func (p *ConnPool) Size() int {
+	return len(p.conns)
+}
+
+// Close closes all connections of the pool.
+func (p *ConnPool) Close() error {
+	var result error
+	for _, conn := range p.conns {
+		if err := conn.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// blockingServer is an Access API server whose pings block until released, and
+// that reports the client address of each ping.
+type blockingServer struct {
+	access.UnimplementedAccessAPIServer
+	peers   chan string
+	release chan struct{}
+}
+
+func (s *blockingServer) Ping(ctx context.Context, _ *access.PingRequest) (*access.PingResponse, error) {
+	p, _ := peer.FromContext(ctx)
+	s.peers <- p.Addr.String()
+	<-s.release
+	return &access.PingResponse{}, nil
+}
+
+func TestNewPooled(t *testing.T) {
+	ctx := context.Background()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	ping := &blockingServer{
+		peers:   make(chan string, 10),
+		release: make(chan struct{}),
+	}
+	access.RegisterAccessAPIServer(server, ping)
+
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	const size = 3
+
+	t.Run("Spreads calls", func(t *testing.T) {
+		c, err := client.NewPooled(lis.Addr().String(), size, grpc.WithInsecure())
+		require.NoError(t, err)
+		defer c.Close()
+
+		errs := make(chan error, size)
+		peers := make(map[string]bool)
+
+		// each call waits while the previous ones are in flight, so that it is sent
+		// over an idle connection
+		for i := 0; i < size; i++ {
+			go func() { errs <- c.Ping(ctx) }()
+			peers[<-ping.peers] = true
+		}
+
+		assert.Len(t, peers, size)
+
+		for i := 0; i < size; i++ {
+			ping.release <- struct{}{}
+			assert.NoError(t, <-errs)
+		}
+	})
+
+	t.Run("With connections option", func(t *testing.T) {
+		c, err := client.NewClient(lis.Addr().String(), client.WithInsecure(), client.WithConnections(2))
+		require.NoError(t, err)
+		defer c.Close()
+
+		errs := make(chan error, 2)
+		peers := make(map[string]bool)
+
+		for i := 0; i < 2; i++ {
+			go func() { errs <- c.Ping(ctx) }()
+			peers[<-ping.peers] = true
+		}
+
+		assert.Len(t, peers, 2)
+
+		for i := 0; i < 2; i++ {
+			ping.release <- struct{}{}
+			assert.NoError(t, <-errs)
+		}
+	})
+
+	t.Run("Max in flight", func(t *testing.T) {
+		metrics := client.NewMetrics()
+
+		c, err := client.NewClient(
+			lis.Addr().String(),
+			client.WithInsecure(),
+			client.WithMaxInFlight(1),
+			client.WithMetrics(metrics),
+		)
+		require.NoError(t, err)
+		defer c.Close()
+
+		errs := make(chan error, 1)
+		go func() { errs <- c.Ping(ctx) }()
+		<-ping.peers
+
+		assert.Contains(t, metricsOutput(t, metrics), `flow_client_queue_in_flight{queue="conn_pool"} 1`)
+
+		// the only connection is at its cap
+		err = c.Ping(ctx)
+		assert.True(t, errors.Is(err, client.ErrBackpressure))
+
+		retryAfter, ok := client.RetryAfter(err)
+		assert.True(t, ok)
+		assert.True(t, retryAfter > 0)
+
+		ping.release <- struct{}{}
+		require.NoError(t, <-errs)
+
+		assert.Contains(t, metricsOutput(t, metrics), `flow_client_queue_in_flight{queue="conn_pool"} 0`)
+
+		go func() { errs <- c.Ping(ctx) }()
+		<-ping.peers
+		ping.release <- struct{}{}
+		assert.NoError(t, <-errs)
+	})
+
+	t.Run("Max in flight with reconnect", func(t *testing.T) {
+		_, err := client.NewClient(
+			lis.Addr().String(),
+			client.WithInsecure(),
+			client.WithMaxInFlight(1),
+			client.WithReconnect(client.ReconnectConfig{}),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid size", func(t *testing.T) {
+		_, err := client.NewPooled(lis.Addr().String(), 0, grpc.WithInsecure())
+		assert.Error(t, err)
+	})
+}
+
+func metricsOutput(t *testing.T, metrics *client.Metrics) string {
+	var b bytes.Buffer
+	_, err := metrics.WriteTo(&b)
+	require.NoError(t, err)
+	return b.String()
+}
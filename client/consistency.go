@@ -0,0 +1,116 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+const defaultPollInterval = time.Second
+
+// ConsistencyConfig configures WaitForSealConsistent.
+type ConsistencyConfig struct {
+	// ReadClient is the client that will serve reads after the transaction is sealed.
+	//
+	// Defaults to the client that waits for the transaction.
+	ReadClient *Client
+	// PollInterval is the time between two status requests.
+	//
+	// Defaults to 1 second.
+	PollInterval time.Duration
+}
+
+// WaitForSealConsistent waits for a transaction to be sealed, and then waits until
+// the read client reports a latest sealed block height greater than or equal to the
+// height at which the transaction was observed to be sealed.
+//
+// This provides read-your-writes consistency when reads and writes are served by
+// different Access nodes, e.g. behind a load balancer or a Router: account reads
+// made through the read client after this function returns reflect the effects of
+// the transaction.
+//
+// The Access API does not report the height of the block that sealed a transaction,
+// so the latest sealed height of this client at the time the sealed status is
+// observed is used instead. This height is never lower than the sealing height.
+//
+// The transaction result is returned as soon as it is available if the transaction
+// expires. Execution errors are reported in the Error field of the result.
+//
+// WaitForSealConsistent is equivalent to WaitForSeal with WithPollInterval and
+// WithReadConsistency.
+func (c *Client) WaitForSealConsistent(
+	ctx context.Context,
+	txID flow.Identifier,
+	config ConsistencyConfig,
+) (*flow.TransactionResult, error) {
+	return c.WaitForSeal(ctx, txID, config.waitOptions()...)
+}
+
+// waitOptions returns the options of WaitForSeal that implement the configuration.
+func (config ConsistencyConfig) waitOptions() []WaitOption {
+	return []WaitOption{
+		WithPollInterval(config.PollInterval),
+		WithReadConsistency(config.ReadClient),
+	}
+}
+
+// WaitForSealedHeight waits until the latest sealed block reported by the client is
+// at least the given height, and returns its header.
+//
+// If pollInterval is zero, the latest sealed block is requested every second.
+func (c *Client) WaitForSealedHeight(
+	ctx context.Context,
+	height uint64,
+	pollInterval time.Duration,
+) (*flow.BlockHeader, error) {
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	for {
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Height >= height {
+			return header, nil
+		}
+
+		if err := sleep(ctx, pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep pauses for the given duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// sequenceNode is an Access API node that reports the given transaction statuses
+// and sealed heights in sequence, repeating the last one.
+type sequenceNode struct {
+	statuses []entities.TransactionStatus
+	heights  []uint64
+}
+
+func (n *sequenceNode) Invoke(
+	_ context.Context,
+	method string,
+	_ interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	switch method {
+	case client.MethodGetTransactionResult:
+		reply.(*access.TransactionResultResponse).Status = n.statuses[0]
+		if len(n.statuses) > 1 {
+			n.statuses = n.statuses[1:]
+		}
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.heights[0]}
+		if len(n.heights) > 1 {
+			n.heights = n.heights[1:]
+		}
+	}
+
+	return nil
+}
+
+func TestClient_WaitForSealConsistent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Waits for read client to catch up", func(t *testing.T) {
+		writer := &sequenceNode{
+			statuses: []entities.TransactionStatus{
+				entities.TransactionStatus_PENDING,
+				entities.TransactionStatus_SEALED,
+			},
+			heights: []uint64{10},
+		}
+		reader := &sequenceNode{heights: []uint64{8, 9, 10, 11}}
+
+		c := client.NewFromEndpoint(client.NewEndpoint("writer", writer))
+
+		result, err := c.WaitForSealConsistent(ctx, flow.EmptyID, client.ConsistencyConfig{
+			ReadClient:   client.NewFromEndpoint(client.NewEndpoint("reader", reader)),
+			PollInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		assert.Equal(t, []entities.TransactionStatus{entities.TransactionStatus_SEALED}, writer.statuses)
+		assert.Equal(t, []uint64{11}, reader.heights)
+	})
+
+	t.Run("Expired transaction", func(t *testing.T) {
+		writer := &sequenceNode{
+			statuses: []entities.TransactionStatus{entities.TransactionStatus_EXPIRED},
+			heights:  []uint64{10},
+		}
+
+		c := client.NewFromEndpoint(client.NewEndpoint("writer", writer))
+
+		result, err := c.WaitForSealConsistent(ctx, flow.EmptyID, client.ConsistencyConfig{
+			PollInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusExpired, result.Status)
+	})
+
+	t.Run("Context cancelled", func(t *testing.T) {
+		reader := &sequenceNode{heights: []uint64{1}}
+		c := client.NewFromEndpoint(client.NewEndpoint("reader", reader))
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := c.WaitForSealedHeight(ctx, 2, time.Millisecond)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
@@ -0,0 +1,336 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// A ContractRef identifies a contract deployed to an account.
+type ContractRef struct {
+	Address flow.Address
+	Name    string
+}
+
+// String returns the string representation of this contract reference.
+func (r ContractRef) String() string {
+	return fmt.Sprintf("A.%s.%s", r.Address.Hex(), r.Name)
+}
+
+// A ContractChange is an observed change of the code of a contract.
+type ContractChange struct {
+	Contract ContractRef
+	// OldHash is the SHA3-256 hash of the code before the change, or nil if the
+	// contract was not deployed.
+	OldHash []byte
+	// NewHash is the SHA3-256 hash of the code after the change, or nil if the
+	// contract was removed.
+	NewHash []byte
+	// Height is the height of the block in which the change was observed. It is
+	// zero for changes found by comparing code hashes.
+	Height uint64
+}
+
+// ContractWatchConfig configures a ContractWatcher.
+type ContractWatchConfig struct {
+	// Interval is the time between two code hash comparisons in Run.
+	//
+	// Defaults to 1 minute.
+	Interval time.Duration
+	// OnChange is called for each change of a watched contract.
+	OnChange func(change ContractChange)
+}
+
+const defaultContractWatchInterval = time.Minute
+
+// contractCodeScript returns the code of a contract, or nil if the account has no
+// contract with the given name.
+const contractCodeScript = `
+pub fun main(address: Address, name: String): [UInt8]? {
+    return getAccount(address).contracts.get(name: name)?.code
+}
+`
+
+// A ContractWatcher reports upgrades of the contracts a service depends on.
+//
+// Changes are found in two ways: Check compares the hashes of the deployed code of
+// the watched contracts with the previously observed hashes, and HandleEvents reads
+// the contract events emitted by the network, e.g. delivered by SubscribeEvents.
+// Either or both may be used; a change is reported once even if it is found both
+// ways.
+type ContractWatcher struct {
+	client    *Client
+	contracts []ContractRef
+	config    ContractWatchConfig
+
+	mu       sync.Mutex
+	observed bool
+	hashes   map[ContractRef][]byte
+}
+
+// NewContractWatcher returns a watcher for the given contracts.
+func NewContractWatcher(c *Client, contracts []ContractRef, config ContractWatchConfig) *ContractWatcher {
+	if config.Interval == 0 {
+		config.Interval = defaultContractWatchInterval
+	}
+
+	return &ContractWatcher{
+		client:    c,
+		contracts: append([]ContractRef(nil), contracts...),
+		config:    config,
+		hashes:    make(map[ContractRef][]byte),
+	}
+}
+
+// Hash returns the last observed code hash of a watched contract, and false if the
+// contract is not deployed or was not observed yet.
+func (w *ContractWatcher) Hash(contract ContractRef) ([]byte, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	hash, ok := w.hashes[contract]
+	return hash, ok
+}
+
+// Check fetches the code of the watched contracts, and returns the contracts whose
+// code hash changed since the previous observation, after calling OnChange for
+// each of them.
+//
+// The first observation is recorded as the reference and reports no change.
+func (w *ContractWatcher) Check(ctx context.Context) ([]ContractChange, error) {
+	hashes := make(map[ContractRef][]byte, len(w.contracts))
+
+	for _, contract := range w.contracts {
+		hash, err := w.codeHash(ctx, contract)
+		if err != nil {
+			return nil, err
+		}
+		hashes[contract] = hash
+	}
+
+	w.mu.Lock()
+
+	var changes []ContractChange
+	for _, contract := range w.contracts {
+		if w.observed {
+			if change, ok := w.update(contract, hashes[contract], 0); ok {
+				changes = append(changes, change)
+			}
+		} else {
+			w.set(contract, hashes[contract])
+		}
+	}
+
+	w.observed = true
+	w.mu.Unlock()
+
+	w.notify(changes)
+
+	return changes, nil
+}
+
+// HandleEvents reads the AccountContractAdded and AccountContractUpdated events of
+// a block, and returns the watched contracts they change, after calling OnChange
+// for each of them.
+func (w *ContractWatcher) HandleEvents(block BlockEvents) ([]ContractChange, error) {
+	var changes []ContractChange
+
+	for _, event := range block.Events {
+		if event.Type != flow.EventAccountContractAdded && event.Type != flow.EventAccountContractUpdated {
+			continue
+		}
+
+		contract, hash, err := contractEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		if !w.watches(contract) {
+			continue
+		}
+
+		w.mu.Lock()
+		change, ok := w.update(contract, hash, block.Height)
+		w.mu.Unlock()
+
+		if ok {
+			changes = append(changes, change)
+		}
+	}
+
+	w.notify(changes)
+
+	return changes, nil
+}
+
+// Run compares the code hashes of the watched contracts at the configured interval
+// until the context is canceled.
+//
+// Errors from the node are ignored, and the comparison is retried at the next
+// interval.
+func (w *ContractWatcher) Run(ctx context.Context) error {
+	for {
+		_, _ = w.Check(ctx)
+
+		if err := sleep(ctx, w.config.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+// codeHash returns the SHA3-256 hash of the code of a contract, or nil if the
+// contract is not deployed.
+func (w *ContractWatcher) codeHash(ctx context.Context, contract ContractRef) ([]byte, error) {
+	value, err := w.client.ExecuteScriptAtLatestBlock(
+		ctx,
+		[]byte(contractCodeScript),
+		[]cadence.Value{cadence.NewAddress(contract.Address), cadence.NewString(contract.Name)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if optional, ok := value.(cadence.Optional); ok {
+		if optional.Value == nil {
+			return nil, nil
+		}
+		value = optional.Value
+	}
+
+	code, err := byteArray(value)
+	if err != nil {
+		return nil, fmt.Errorf("client: unexpected code of contract %s: %w", contract, err)
+	}
+
+	return crypto.NewSHA3_256().ComputeHash(code), nil
+}
+
+func (w *ContractWatcher) watches(contract ContractRef) bool {
+	for _, watched := range w.contracts {
+		if watched == contract {
+			return true
+		}
+	}
+	return false
+}
+
+// update records the hash of a contract, and returns the change if it differs from
+// the previously observed hash. The caller must hold the lock.
+func (w *ContractWatcher) update(contract ContractRef, hash []byte, height uint64) (ContractChange, bool) {
+	old := w.hashes[contract]
+	if bytes.Equal(old, hash) {
+		return ContractChange{}, false
+	}
+
+	w.set(contract, hash)
+
+	return ContractChange{
+		Contract: contract,
+		OldHash:  old,
+		NewHash:  hash,
+		Height:   height,
+	}, true
+}
+
+func (w *ContractWatcher) set(contract ContractRef, hash []byte) {
+	if hash == nil {
+		delete(w.hashes, contract)
+		return
+	}
+	w.hashes[contract] = hash
+}
+
+func (w *ContractWatcher) notify(changes []ContractChange) {
+	if w.config.OnChange == nil {
+		return
+	}
+
+	for _, change := range changes {
+		w.config.OnChange(change)
+	}
+}
+
+// contractEvent returns the contract and code hash of an AccountContractAdded or
+// AccountContractUpdated event.
+func contractEvent(event flow.Event) (ContractRef, []byte, error) {
+	address, ok, err := event.Field("address")
+	if err != nil || !ok {
+		return ContractRef{}, nil, fmt.Errorf("client: invalid %s event: missing address", event.Type)
+	}
+
+	name, ok, err := event.Field("contract")
+	if err != nil || !ok {
+		return ContractRef{}, nil, fmt.Errorf("client: invalid %s event: missing contract", event.Type)
+	}
+
+	codeHash, ok, err := event.Field("codeHash")
+	if err != nil || !ok {
+		return ContractRef{}, nil, fmt.Errorf("client: invalid %s event: missing codeHash", event.Type)
+	}
+
+	addressValue, ok := address.(cadence.Address)
+	if !ok {
+		return ContractRef{}, nil, fmt.Errorf("client: invalid %s event: address is %T", event.Type, address)
+	}
+
+	nameValue, ok := name.(cadence.String)
+	if !ok {
+		return ContractRef{}, nil, fmt.Errorf("client: invalid %s event: contract is %T", event.Type, name)
+	}
+
+	hash, err := byteArray(codeHash)
+	if err != nil {
+		return ContractRef{}, nil, fmt.Errorf("client: invalid %s event: %w", event.Type, err)
+	}
+
+	contract := ContractRef{
+		Address: flow.BytesToAddress(addressValue.Bytes()),
+		Name:    string(nameValue),
+	}
+
+	return contract, hash, nil
+}
+
+// byteArray returns the bytes of a [UInt8] value.
+func byteArray(value cadence.Value) ([]byte, error) {
+	array, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("expected [UInt8], got %T", value)
+	}
+
+	b := make([]byte, len(array.Values))
+	for i, v := range array.Values {
+		u, ok := v.(cadence.UInt8)
+		if !ok {
+			return nil, fmt.Errorf("expected [UInt8], got element %T", v)
+		}
+		b[i] = uint8(u)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,191 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// contractNode is a fake Access API node that returns the code of deployed contracts.
+type contractNode struct {
+	mu   sync.Mutex
+	code map[string][]byte
+}
+
+func (n *contractNode) deploy(name string, code []byte) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if code == nil {
+		delete(n.code, name)
+		return
+	}
+	n.code[name] = code
+}
+
+func (n *contractNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	req := args.(*access.ExecuteScriptAtLatestBlockRequest)
+
+	name, err := jsoncdc.Decode(req.Arguments[1])
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	code, ok := n.code[string(name.(cadence.String))]
+	n.mu.Unlock()
+
+	result := cadence.NewOptional(nil)
+	if ok {
+		values := make([]cadence.Value, len(code))
+		for i, b := range code {
+			values[i] = cadence.NewUInt8(b)
+		}
+		result = cadence.NewOptional(cadence.NewArray(values))
+	}
+
+	reply.(*access.ExecuteScriptResponse).Value, err = jsoncdc.Encode(result)
+	return err
+}
+
+func codeHash(code string) []byte {
+	return crypto.NewSHA3_256().ComputeHash([]byte(code))
+}
+
+func contractUpdatedEvent(address flow.Address, name string, hash []byte) flow.Event {
+	values := make([]cadence.Value, len(hash))
+	for i, b := range hash {
+		values[i] = cadence.NewUInt8(b)
+	}
+
+	return flow.Event{
+		Type: flow.EventAccountContractUpdated,
+		Value: cadence.NewEvent([]cadence.Value{
+			cadence.NewAddress(address),
+			cadence.NewArray(values),
+			cadence.NewString(name),
+		}).WithType(&cadence.EventType{
+			TypeID: flow.EventAccountContractUpdated,
+			Fields: []cadence.Field{
+				{Identifier: "address", Type: cadence.AddressType{}},
+				{Identifier: "codeHash", Type: cadence.VariableSizedArrayType{ElementType: cadence.UInt8Type{}}},
+				{Identifier: "contract", Type: cadence.StringType{}},
+			},
+		}),
+	}
+}
+
+func TestContractWatcher(t *testing.T) {
+	ctx := context.Background()
+
+	address := flow.HexToAddress("01")
+	token := client.ContractRef{Address: address, Name: "Token"}
+	market := client.ContractRef{Address: address, Name: "Market"}
+
+	node := &contractNode{code: map[string][]byte{"Token": []byte("v1")}}
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	var notified []client.ContractChange
+	watcher := client.NewContractWatcher(c, []client.ContractRef{token, market}, client.ContractWatchConfig{
+		OnChange: func(change client.ContractChange) {
+			notified = append(notified, change)
+		},
+	})
+
+	// the first check records the reference
+	changes, err := watcher.Check(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	hash, ok := watcher.Hash(token)
+	assert.True(t, ok)
+	assert.Equal(t, codeHash("v1"), hash)
+
+	_, ok = watcher.Hash(market)
+	assert.False(t, ok)
+
+	t.Run("Code hash", func(t *testing.T) {
+		node.deploy("Token", []byte("v2"))
+		node.deploy("Market", []byte("v1"))
+
+		changes, err := watcher.Check(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []client.ContractChange{
+			{Contract: token, OldHash: codeHash("v1"), NewHash: codeHash("v2")},
+			{Contract: market, NewHash: codeHash("v1")},
+		}, changes)
+
+		changes, err = watcher.Check(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		changes, err := watcher.HandleEvents(client.BlockEvents{
+			Height: 42,
+			Events: []flow.Event{
+				contractUpdatedEvent(address, "Token", codeHash("v3")),
+				contractUpdatedEvent(address, "Other", codeHash("v1")),
+				{Type: flow.EventAccountCreated},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []client.ContractChange{
+			{Contract: token, OldHash: codeHash("v2"), NewHash: codeHash("v3"), Height: 42},
+		}, changes)
+
+		// a change reported by an event is not reported again by a check
+		node.deploy("Token", []byte("v3"))
+
+		changes, err = watcher.Check(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		node.deploy("Market", nil)
+
+		changes, err := watcher.Check(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []client.ContractChange{
+			{Contract: market, OldHash: codeHash("v1")},
+		}, changes)
+	})
+
+	assert.Len(t, notified, 4)
+}
@@ -19,7 +19,6 @@
 package convert
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
@@ -31,9 +30,10 @@ import (
 
 	"github.com/portto/blocto-flow-go-sdk"
 	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
 )
 
-var ErrEmptyMessage = errors.New("protobuf message is empty")
+var ErrEmptyMessage = errcode.New(errcode.EmptyMessage, "protobuf message is empty")
 
 func AccountToMessage(a flow.Account) *entities.Account {
 	accountKeys := make([]*entities.AccountKey, len(a.Keys))
@@ -124,36 +124,70 @@ func BlockToMessage(b flow.Block) (*entities.Block, error) {
 }
 
 func MessageToBlock(m *entities.Block) (flow.Block, error) {
+	var block flow.Block
+
+	err := MessageToBlockInto(m, &block)
+	if err != nil {
+		return flow.Block{}, err
+	}
+
+	return block, nil
+}
+
+// MessageToBlockInto converts a block message into b, reusing the collection
+// guarantees held by b.
+func MessageToBlockInto(m *entities.Block, b *flow.Block) error {
 	var timestamp time.Time
 	var err error
 
 	if m.GetTimestamp() != nil {
 		timestamp, err = ptypes.Timestamp(m.GetTimestamp())
 		if err != nil {
-			return flow.Block{}, err
+			return err
 		}
 	}
 
-	header := &flow.BlockHeader{
+	b.BlockHeader = flow.BlockHeader{
 		ID:        flow.HashToID(m.GetId()),
 		ParentID:  flow.HashToID(m.GetParentId()),
 		Height:    m.GetHeight(),
 		Timestamp: timestamp,
 	}
 
-	guarantees, err := MessagesToCollectionGuarantees(m.GetCollectionGuarantees())
-	if err != nil {
-		return flow.Block{}, err
+	messages := m.GetCollectionGuarantees()
+
+	guarantees := b.CollectionGuarantees[:0]
+	if guarantees == nil {
+		guarantees = make([]*flow.CollectionGuarantee, 0, len(messages))
+	}
+
+	for _, item := range messages {
+		guarantee, err := MessageToCollectionGuarantee(item)
+		if err != nil {
+			return err
+		}
+
+		if len(guarantees) < cap(guarantees) {
+			guarantees = guarantees[:len(guarantees)+1]
+		} else {
+			guarantees = append(guarantees, nil)
+		}
+
+		g := guarantees[len(guarantees)-1]
+		if g == nil {
+			g = &flow.CollectionGuarantee{}
+			guarantees[len(guarantees)-1] = g
+		}
+
+		*g = guarantee
 	}
 
-	payload := &flow.BlockPayload{
+	b.BlockPayload = flow.BlockPayload{
 		CollectionGuarantees: guarantees,
+		Seals:                b.Seals[:0],
 	}
 
-	return flow.Block{
-		BlockHeader:  *header,
-		BlockPayload: *payload,
-	}, nil
+	return nil
 }
 
 func BlockHeaderToMessage(b flow.BlockHeader) (*entities.BlockHeader, error) {
@@ -288,9 +322,14 @@ func MessagesToCollectionGuarantees(l []*entities.CollectionGuarantee) ([]*flow.
 }
 
 func EventToMessage(e flow.Event) (*entities.Event, error) {
-	payload, err := CadenceValueToMessage(e.Value)
-	if err != nil {
-		return nil, err
+	payload := e.Payload
+
+	if payload == nil {
+		var err error
+		payload, err = CadenceValueToMessage(e.Value)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &entities.Event{
@@ -322,6 +361,18 @@ func MessageToEvent(m *entities.Event) (flow.Event, error) {
 	}, nil
 }
 
+// MessageToLazyEvent converts an event message to an event whose value is decoded
+// on first access. The payload of the message is retained, not copied.
+func MessageToLazyEvent(m *entities.Event) flow.Event {
+	return flow.NewLazyEvent(
+		m.GetType(),
+		flow.HashToID(m.GetTransactionId()),
+		int(m.GetTransactionIndex()),
+		int(m.GetEventIndex()),
+		m.GetPayload(),
+	)
+}
+
 func IdentifierToMessage(i flow.Identifier) []byte {
 	return i.Bytes()
 }
@@ -392,11 +443,29 @@ func TransactionToMessage(t flow.Transaction) (*entities.Transaction, error) {
 }
 
 func MessageToTransaction(m *entities.Transaction) (flow.Transaction, error) {
+	var t flow.Transaction
+
+	err := MessageToTransactionInto(m, &t)
+	if err != nil {
+		return flow.Transaction{}, err
+	}
+
+	return t, nil
+}
+
+// MessageToTransactionInto converts a transaction message into t, reusing the
+// argument, authorizer and signature slices held by t.
+func MessageToTransactionInto(m *entities.Transaction, t *flow.Transaction) error {
 	if m == nil {
-		return flow.Transaction{}, ErrEmptyMessage
+		return ErrEmptyMessage
 	}
 
-	t := flow.NewTransaction()
+	*t = flow.Transaction{
+		Arguments:          t.Arguments[:0],
+		Authorizers:        t.Authorizers[:0],
+		PayloadSignatures:  t.PayloadSignatures[:0],
+		EnvelopeSignatures: t.EnvelopeSignatures[:0],
+	}
 
 	t.SetScript(m.GetScript())
 	t.SetReferenceBlockID(flow.HashToID(m.GetReferenceBlockId()))
@@ -435,7 +504,7 @@ func MessageToTransaction(m *entities.Transaction) (flow.Transaction, error) {
 		t.AddEnvelopeSignature(addr, int(sig.GetKeyId()), sig.GetSignature())
 	}
 
-	return *t, nil
+	return nil
 }
 
 func TransactionResultToMessage(result flow.TransactionResult) (*access.TransactionResultResponse, error) {
@@ -485,9 +554,9 @@ func MessageToTransactionResult(m *access.TransactionResultResponse) (flow.Trans
 	if statusCode != 0 {
 		errorMsg := m.GetErrorMessage()
 		if errorMsg != "" {
-			err = errors.New(errorMsg)
+			err = errcode.New(errcode.TransactionFailed, errorMsg)
 		} else {
-			err = errors.New("transaction execution failed")
+			err = errcode.New(errcode.TransactionFailed, "transaction execution failed")
 		}
 	}
 
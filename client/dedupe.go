@@ -0,0 +1,197 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// An EventKey uniquely identifies an event on the chain.
+type EventKey struct {
+	TransactionID flow.Identifier
+	EventIndex    int
+}
+
+// NewEventKey returns the key of the given event.
+func NewEventKey(event flow.Event) EventKey {
+	return EventKey{
+		TransactionID: event.TransactionID,
+		EventIndex:    event.EventIndex,
+	}
+}
+
+// String returns the string representation of this key.
+func (k EventKey) String() string {
+	return fmt.Sprintf("%s:%d", k.TransactionID, k.EventIndex)
+}
+
+// A DedupeStore records the keys of processed events.
+//
+// Implementations backed by durable storage allow an EventDeduper to skip events
+// that were processed before a restart.
+type DedupeStore interface {
+	// Contains returns true if the given key was added and has not been pruned.
+	Contains(key EventKey) (bool, error)
+	// Add records the key of an event emitted in the block at the given height.
+	Add(key EventKey, height uint64) error
+	// Prune removes all keys of events emitted in blocks below the given height.
+	Prune(height uint64) error
+}
+
+// MemoryDedupeStore is a DedupeStore that keeps keys in memory.
+type MemoryDedupeStore struct {
+	mu   sync.Mutex
+	keys map[EventKey]uint64
+}
+
+var _ DedupeStore = &MemoryDedupeStore{}
+
+// NewMemoryDedupeStore returns an empty in-memory dedupe store.
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{
+		keys: make(map[EventKey]uint64),
+	}
+}
+
+// Contains returns true if the given key was added and has not been pruned.
+func (s *MemoryDedupeStore) Contains(key EventKey) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+// Add records the key of an event emitted in the block at the given height.
+func (s *MemoryDedupeStore) Add(key EventKey, height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key] = height
+	return nil
+}
+
+// Prune removes all keys of events emitted in blocks below the given height.
+func (s *MemoryDedupeStore) Prune(height uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, h := range s.keys {
+		if h < height {
+			delete(s.keys, key)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of keys in the store.
+func (s *MemoryDedupeStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.keys)
+}
+
+// An EventDeduper passes each event to a handler at most once.
+//
+// Subscriptions deliver events at least once: after a reconnect or a restart from
+// a checkpoint, blocks that were already delivered may be delivered again. An
+// EventDeduper sits between a subscription and the event handlers, and skips the
+// events that were already processed successfully.
+//
+// Keys are kept for a window of blocks behind the highest height handled so far.
+// Events redelivered from further back than the window are not detected.
+type EventDeduper struct {
+	store  DedupeStore
+	window uint64
+
+	mu        sync.Mutex
+	maxHeight uint64
+}
+
+// NewEventDeduper returns a deduper that remembers events for the given number of
+// blocks, using the provided store.
+//
+// If store is nil, keys are kept in memory.
+func NewEventDeduper(window uint64, store DedupeStore) *EventDeduper {
+	if store == nil {
+		store = NewMemoryDedupeStore()
+	}
+
+	return &EventDeduper{
+		store:  store,
+		window: window,
+	}
+}
+
+// Handle passes each event of the block that was not processed before to the handler,
+// in order.
+//
+// An event is recorded as processed only if the handler returns no error. Handle stops
+// at the first handler error and returns it, so that the remaining events are handled
+// when the block is delivered again.
+func (d *EventDeduper) Handle(block BlockEvents, handler func(flow.Event) error) error {
+	for _, event := range block.Events {
+		key := NewEventKey(event)
+
+		seen, err := d.store.Contains(key)
+		if err != nil {
+			return fmt.Errorf("client: failed to read dedupe store: %w", err)
+		}
+
+		if seen {
+			continue
+		}
+
+		if err := handler(event); err != nil {
+			return err
+		}
+
+		if err := d.store.Add(key, block.Height); err != nil {
+			return fmt.Errorf("client: failed to write dedupe store: %w", err)
+		}
+	}
+
+	return d.advance(block.Height)
+}
+
+// advance prunes the keys that fall out of the window once a new highest height is handled.
+func (d *EventDeduper) advance(height uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if height <= d.maxHeight {
+		return nil
+	}
+
+	d.maxHeight = height
+
+	if height <= d.window {
+		return nil
+	}
+
+	if err := d.store.Prune(height - d.window); err != nil {
+		return fmt.Errorf("client: failed to prune dedupe store: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestEventDeduper(t *testing.T) {
+	events := test.EventGenerator()
+
+	block := func(height uint64, events ...flow.Event) client.BlockEvents {
+		return client.BlockEvents{Height: height, Events: events}
+	}
+
+	t.Run("Skips redelivered events", func(t *testing.T) {
+		d := client.NewEventDeduper(10, nil)
+
+		a, b := events.New(), events.New()
+
+		var handled []flow.Event
+		handler := func(e flow.Event) error {
+			handled = append(handled, e)
+			return nil
+		}
+
+		require.NoError(t, d.Handle(block(1, a), handler))
+		require.NoError(t, d.Handle(block(1, a), handler))
+		require.NoError(t, d.Handle(block(2, a, b), handler))
+
+		assert.Equal(t, []flow.Event{a, b}, handled)
+	})
+
+	t.Run("Retries events after handler errors", func(t *testing.T) {
+		d := client.NewEventDeduper(10, nil)
+
+		a, b := events.New(), events.New()
+		errHandler := errors.New("handler failed")
+
+		var handled []flow.Event
+		fail := true
+		handler := func(e flow.Event) error {
+			if fail && client.NewEventKey(e) == client.NewEventKey(b) {
+				return errHandler
+			}
+			handled = append(handled, e)
+			return nil
+		}
+
+		assert.Equal(t, errHandler, d.Handle(block(1, a, b), handler))
+
+		fail = false
+		require.NoError(t, d.Handle(block(1, a, b), handler))
+
+		assert.Equal(t, []flow.Event{a, b}, handled)
+	})
+
+	t.Run("Prunes keys outside window", func(t *testing.T) {
+		store := client.NewMemoryDedupeStore()
+		d := client.NewEventDeduper(5, store)
+
+		noop := func(flow.Event) error { return nil }
+
+		require.NoError(t, d.Handle(block(1, events.New()), noop))
+		require.NoError(t, d.Handle(block(3, events.New()), noop))
+		assert.Equal(t, 2, store.Len())
+
+		require.NoError(t, d.Handle(block(7, events.New()), noop))
+		assert.Equal(t, 2, store.Len())
+	})
+}
@@ -0,0 +1,90 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// Load balancing policies supported by WithLoadBalancing.
+const (
+	// PickFirst sends all calls to the first reachable address of the target.
+	// This is the gRPC default.
+	PickFirst = "pick_first"
+	// RoundRobin spreads calls across all resolved addresses of the target.
+	RoundRobin = roundrobin.Name
+)
+
+// DNSTarget returns a dial target that resolves all addresses of the host through
+// DNS, e.g. the pods behind a Kubernetes headless service.
+//
+// Combine it with WithLoadBalancing(RoundRobin) to spread calls across all nodes:
+//
+//	c, err := client.New(
+//		client.DNSTarget("access.flow.svc.cluster.local", 9000),
+//		grpc.WithInsecure(),
+//		client.WithLoadBalancing(client.RoundRobin),
+//	)
+func DNSTarget(host string, port int) string {
+	return "dns:///" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// WithLoadBalancing returns a dial option that selects the gRPC load balancing policy
+// used across the addresses of the dial target.
+//
+// The policy is overridden by a service config published by the resolver, if any.
+func WithLoadBalancing(policy string) grpc.DialOption {
+	return WithServiceConfig(fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, policy))
+}
+
+// WithServiceConfig returns a dial option that sets the default gRPC service config,
+// in its JSON representation, e.g. to configure load balancing or retry policies.
+func WithServiceConfig(config string) grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(config)
+}
+
+// WithResolvers returns a dial option that registers custom name resolvers for the
+// client. A resolver is used for targets of the form <scheme>:///<endpoint>.
+//
+// Unlike resolver.Register, the resolvers are local to the client.
+func WithResolvers(resolvers ...resolver.Builder) grpc.DialOption {
+	return grpc.WithResolvers(resolvers...)
+}
+
+// NewStaticResolver returns a resolver for the given scheme that resolves every
+// target to the given addresses.
+func NewStaticResolver(scheme string, addrs ...string) resolver.Builder {
+	r := manual.NewBuilderWithScheme(scheme)
+
+	state := resolver.State{Addresses: make([]resolver.Address, len(addrs))}
+	for i, addr := range addrs {
+		state.Addresses[i] = resolver.Address{Addr: addr}
+	}
+
+	r.InitialState(state)
+
+	return r
+}
@@ -0,0 +1,86 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+type pingServer struct {
+	access.UnimplementedAccessAPIServer
+	pings int32
+}
+
+func (s *pingServer) Ping(context.Context, *access.PingRequest) (*access.PingResponse, error) {
+	atomic.AddInt32(&s.pings, 1)
+	return &access.PingResponse{}, nil
+}
+
+func startPingServer(t *testing.T) (*pingServer, string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	ping := &pingServer{}
+	access.RegisterAccessAPIServer(server, ping)
+
+	go func() { _ = server.Serve(lis) }()
+
+	return ping, lis.Addr().String(), server.Stop
+}
+
+func TestDNSTarget(t *testing.T) {
+	assert.Equal(t, "dns:///access.flow.svc:9000", client.DNSTarget("access.flow.svc", 9000))
+}
+
+func TestLoadBalancing(t *testing.T) {
+	a, addrA, stopA := startPingServer(t)
+	defer stopA()
+
+	b, addrB, stopB := startPingServer(t)
+	defer stopB()
+
+	c, err := client.New(
+		"static:///access",
+		grpc.WithInsecure(),
+		client.WithResolvers(client.NewStaticResolver("static", addrA, addrB)),
+		client.WithLoadBalancing(client.RoundRobin),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// connections become ready independently, so calls eventually reach both nodes
+	for atomic.LoadInt32(&a.pings) == 0 || atomic.LoadInt32(&b.pings) == 0 {
+		require.NoError(t, c.Ping(ctx))
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// An Endpoint is a single Access API node used by a multi-endpoint client.
+type Endpoint struct {
+	// Address is the network address of the node.
+	Address string
+	// Invoker sends calls to the node.
+	Invoker Invoker
+	close   func() error
+}
+
+// NewEndpoint returns an endpoint for the node at the given address that sends
+// calls through the provided invoker.
+func NewEndpoint(addr string, invoker Invoker) *Endpoint {
+	return &Endpoint{
+		Address: addr,
+		Invoker: invoker,
+		close:   func() error { return nil },
+	}
+}
+
+// DialEndpoint dials the Access API node at the given address and returns it as an endpoint.
+func DialEndpoint(addr string, opts ...grpc.DialOption) (*Endpoint, error) {
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Endpoint{
+		Address: addr,
+		Invoker: conn,
+		close:   func() error { return conn.Close() },
+	}, nil
+}
+
+// DialEndpoints dials each of the given addresses and returns them as endpoints.
+//
+// If any address cannot be dialed, all previously dialed endpoints are closed.
+func DialEndpoints(addrs []string, opts ...grpc.DialOption) ([]*Endpoint, error) {
+	endpoints := make([]*Endpoint, 0, len(addrs))
+
+	for _, addr := range addrs {
+		endpoint, err := DialEndpoint(addr, opts...)
+		if err != nil {
+			_ = closeEndpoints(endpoints)
+			return nil, err
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
+
+// NewFromEndpoint initializes a Flow client that sends all calls to a single endpoint.
+//
+// The endpoint is not closed when the client is closed.
+func NewFromEndpoint(endpoint *Endpoint) *Client {
+	c := NewFromRPCClient(NewRPCClient(endpoint.Invoker))
+	c.invoker = endpoint.Invoker
+	c.endpoint = endpoint.Address
+	return c
+}
+
+// Close closes the connection to this endpoint.
+func (e *Endpoint) Close() error {
+	if e.close == nil {
+		return nil
+	}
+	return e.close()
+}
+
+func closeEndpoints(endpoints []*Endpoint) error {
+	var result error
+	for _, endpoint := range endpoints {
+		if err := endpoint.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// ErrNoEndpoint is returned by a router when no endpoint is available to serve a call.
+var ErrNoEndpoint = errcode.New(errcode.NoEndpoint, "client: no endpoint available")
+
+// A Router selects the endpoint that serves each Access API call.
+type Router interface {
+	// Route returns the endpoint that should serve a call to the given method.
+	Route(ctx context.Context, method string) (*Endpoint, error)
+}
+
+// routerInvoker is an invoker that dispatches each call to the endpoint chosen by a router.
+type routerInvoker struct {
+	router Router
+}
+
+func (r routerInvoker) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	endpoint, err := r.router.Route(ctx, method)
+	if err != nil {
+		return err
+	}
+
+	recordEndpoint(ctx, endpoint.Address)
+
+	return endpoint.Invoker.Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewRouterInvoker returns an invoker that dispatches each call to the endpoint
+// selected by the given router.
+func NewRouterInvoker(router Router) Invoker {
+	return routerInvoker{router: router}
+}
+
+// NewFromRouter initializes a Flow client that sends each call to the endpoint
+// selected by the given router.
+//
+// If the router implements io.Closer, it is closed when the client is closed.
+func NewFromRouter(router Router) *Client {
+	closeFunc := func() error { return nil }
+	if closer, ok := router.(io.Closer); ok {
+		closeFunc = closer.Close
+	}
+
+	invoker := NewRouterInvoker(router)
+
+	return &Client{
+		rpcClient: NewRPCClient(invoker),
+		invoker:   invoker,
+		close:     closeFunc,
+	}
+}
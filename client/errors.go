@@ -1,9 +1,18 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
 )
 
 const errorMessagePrefix = "client: "
@@ -14,16 +23,30 @@ func errorMessage(format string, a ...interface{}) string {
 
 // An RPCError is an error returned by an RPC call to an Access API.
 //
-// An RPC error can be unwrapped to produce the original gRPC error.
+// An RPC error can be unwrapped to produce the original gRPC error, and is matched
+// with errors.Is against the SDK error it maps to, so that callers do not match the
+// text of errors:
+//
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
 type RPCError struct {
 	GRPCErr error
+	// RequestID is the request ID attached to the context of the call, if any.
+	RequestID string
 }
 
-func newRPCError(gRPCErr error) RPCError {
-	return RPCError{GRPCErr: gRPCErr}
+func newRPCError(ctx context.Context, gRPCErr error) RPCError {
+	return RPCError{
+		GRPCErr:   gRPCErr,
+		RequestID: RequestID(ctx),
+	}
 }
 
 func (e RPCError) Error() string {
+	if e.RequestID != "" {
+		return errorMessage("%s (request ID %s)", e.GRPCErr.Error(), e.RequestID)
+	}
 	return errorMessage(e.GRPCErr.Error())
 }
 
@@ -31,6 +54,86 @@ func (e RPCError) Unwrap() error {
 	return e.GRPCErr
 }
 
+// ErrorCode returns the SDK error code that corresponds to the gRPC status code of this error,
+// or the code of the wrapped error if it is not a gRPC error, e.g. an error returned by
+// a middleware.
+func (e RPCError) ErrorCode() errcode.Code {
+	if _, ok := status.FromError(e.GRPCErr); !ok {
+		if code := errcode.Of(e.GRPCErr); code != errcode.Unknown {
+			return code
+		}
+	}
+
+	if kind := e.kind(); kind != nil {
+		return errcode.Of(kind)
+	}
+
+	if status.Code(e.GRPCErr) == codes.DeadlineExceeded {
+		return errcode.RPCDeadlineExceeded
+	}
+	return errcode.RPC
+}
+
+// Is returns true if target is the SDK error that this error maps to: ErrNotFound,
+// ErrInvalidArgument, ErrConnection, ErrRateLimited or ErrMessageTooLarge.
+func (e RPCError) Is(target error) bool {
+	kind := e.kind()
+	return kind != nil && kind == target
+}
+
+// kind returns the SDK error that this error maps to, or nil if there is none.
+//
+// Errors are mapped by their gRPC status code, and by their message for the errors
+// that some Access nodes and proxies report with a less specific code.
+func (e RPCError) kind() error {
+	code := status.Code(e.GRPCErr)
+	message := strings.ToLower(status.Convert(e.GRPCErr).Message())
+
+	switch {
+	case code == codes.ResourceExhausted && isMessageTooLarge(e.GRPCErr):
+		return ErrMessageTooLarge
+	case code == codes.ResourceExhausted,
+		(code == codes.Unavailable || code == codes.Unknown) && containsAny(message, rateLimitMessages):
+		return ErrRateLimited
+	case code == codes.Unavailable:
+		return ErrConnection
+	case code == codes.NotFound:
+		return ErrNotFound
+	case code == codes.InvalidArgument:
+		return ErrInvalidArgument
+	case code == codes.Internal || code == codes.Unknown:
+		if containsAny(message, notFoundMessages) && !containsAny(message, scriptErrorMarkers) {
+			return ErrNotFound
+		}
+	}
+
+	return nil
+}
+
+// rateLimitMessages mark the errors of calls rejected by a rate limiter, e.g. the
+// HTTP 429 responses of a proxy, which gRPC reports as Unavailable.
+var rateLimitMessages = []string{
+	"rate limit",
+	"too many requests",
+}
+
+// notFoundMessages mark the errors of calls for unknown entities that Access nodes
+// report as internal errors.
+var notFoundMessages = []string{
+	"key not found",
+	"could not find",
+	"does not exist",
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, strings.ToLower(substring)) {
+			return true
+		}
+	}
+	return false
+}
+
 // GRPCStatus returns the gRPC status for this error.
 //
 // This function satisfies the interface defined in the status.FromError function.
@@ -39,6 +142,108 @@ func (e RPCError) GRPCStatus() *status.Status {
 	return s
 }
 
+// ErrBackpressure matches the errors of calls rejected because the Access API, or a
+// proxy in front of it, is overloaded or rate limits the client:
+//
+//	if errors.Is(err, client.ErrBackpressure) {
+//		retryAfter, _ := client.RetryAfter(err)
+//		...
+//	}
+var ErrBackpressure = errcode.New(errcode.RPCBackpressure, "client: access API is overloaded")
+
+// A BackpressureError is returned when the client itself rejects a call instead of
+// queuing it, e.g. because its rate limiter or connection pool is saturated.
+//
+// It matches ErrBackpressure, and RetryAfter returns its delay. Middlewares see it
+// as a ResourceExhausted status, like a rejection by the Access API, so that
+// WithRetry backs off for the delay.
+type BackpressureError struct {
+	Message string
+	// Delay is the suggested delay before the call is retried.
+	Delay time.Duration
+}
+
+func (e *BackpressureError) Error() string {
+	return fmt.Sprintf("client: %s, retry after %s", e.Message, e.Delay)
+}
+
+// Is returns true if target is ErrBackpressure.
+func (e *BackpressureError) Is(target error) bool {
+	return target == ErrBackpressure
+}
+
+// ErrorCode returns errcode.RPCBackpressure.
+func (e *BackpressureError) ErrorCode() errcode.Code {
+	return errcode.RPCBackpressure
+}
+
+// GRPCStatus returns a ResourceExhausted status with the suggested delay as retry
+// information.
+func (e *BackpressureError) GRPCStatus() *status.Status {
+	s := status.New(codes.ResourceExhausted, e.Error())
+	if e.Delay <= 0 {
+		return s
+	}
+
+	detailed, err := s.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(e.Delay)})
+	if err != nil {
+		return s
+	}
+
+	return detailed
+}
+
+// ErrRateLimited is an alias of ErrBackpressure.
+var ErrRateLimited = ErrBackpressure
+
+// ErrMessageTooLarge matches the errors of calls that sent or received a message
+// larger than the maximum message size, e.g. the results of event queries over
+// many blocks. The limit is raised with WithMaxMessageSize.
+var ErrMessageTooLarge = errcode.New(errcode.RPCMessageTooLarge, "client: message larger than max size")
+
+// ErrNotFound matches the errors of calls for entities that the Access API does not
+// know, e.g. an account that does not exist or a block that is not indexed yet.
+var ErrNotFound = errcode.New(errcode.RPCNotFound, "client: not found")
+
+// ErrInvalidArgument matches the errors of calls that the Access API rejected as
+// invalid, e.g. a malformed transaction.
+var ErrInvalidArgument = errcode.New(errcode.RPCInvalidArgument, "client: invalid argument")
+
+// ErrConnection matches the errors of calls that could not reach the Access API.
+// They may succeed when retried.
+var ErrConnection = errcode.New(errcode.RPCUnavailable, "client: access API unavailable")
+
+// isMessageTooLarge returns true if err is the error returned by gRPC for a
+// message over the size limit.
+//
+// gRPC reports these errors with the ResourceExhausted status code, like
+// backpressure errors, and only distinguishes them by their message.
+func isMessageTooLarge(err error) bool {
+	return status.Code(err) == codes.ResourceExhausted &&
+		strings.Contains(status.Convert(err).Message(), "larger than max")
+}
+
+// RetryAfter returns the delay after which the call that failed with err may be
+// retried, as suggested by the Access API, and false if no delay was suggested.
+func RetryAfter(err error) (time.Duration, bool) {
+	var statusErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &statusErr) {
+		return 0, false
+	}
+
+	for _, detail := range statusErr.GRPCStatus().Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			delay, err := ptypes.Duration(info.GetRetryDelay())
+			if err != nil {
+				return 0, false
+			}
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
 const (
 	entityBlock             = "flow.Block"
 	entityBlockHeader       = "flow.BlockHeader"
@@ -71,6 +276,11 @@ func (e EntityToMessageError) Unwrap() error {
 	return e.Err
 }
 
+// ErrorCode returns errcode.EntityToMessage.
+func (e EntityToMessageError) ErrorCode() errcode.Code {
+	return errcode.EntityToMessage
+}
+
 // A MessageToEntityError indicates that a protobuf message could not be converted to an SDK entity.
 type MessageToEntityError struct {
 	Entity string
@@ -91,3 +301,8 @@ func (e MessageToEntityError) Error() string {
 func (e MessageToEntityError) Unwrap() error {
 	return e.Err
 }
+
+// ErrorCode returns errcode.MessageToEntity.
+func (e MessageToEntityError) ErrorCode() errcode.Code {
+	return errcode.MessageToEntity
+}
@@ -0,0 +1,99 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+func TestRPCError_Is(t *testing.T) {
+	sentinels := []error{
+		client.ErrNotFound,
+		client.ErrInvalidArgument,
+		client.ErrConnection,
+		client.ErrRateLimited,
+		client.ErrMessageTooLarge,
+	}
+
+	tests := []struct {
+		err      error
+		expected error
+	}{
+		{status.Error(codes.NotFound, "account not found"), client.ErrNotFound},
+		{status.Error(codes.Internal, "failed to get collection: key not found"), client.ErrNotFound},
+		{status.Error(codes.InvalidArgument, "invalid signature"), client.ErrInvalidArgument},
+		{status.Error(codes.Unavailable, "connection refused"), client.ErrConnection},
+		{
+			status.Error(codes.Unavailable, "unexpected HTTP status code received from server: 429 (Too Many Requests)"),
+			client.ErrRateLimited,
+		},
+		{status.Error(codes.ResourceExhausted, "overloaded"), client.ErrRateLimited},
+		{
+			status.Error(codes.ResourceExhausted, "grpc: received message larger than max (5 vs. 4)"),
+			client.ErrMessageTooLarge,
+		},
+		{status.Error(codes.Internal, "Execution failed:\nerror: could not find variable"), nil},
+		{status.Error(codes.Internal, "internal server error"), nil},
+	}
+
+	for _, test := range tests {
+		err := fmt.Errorf("wrapped: %w", client.RPCError{GRPCErr: test.err})
+
+		for _, sentinel := range sentinels {
+			assert.Equal(t, sentinel == test.expected, errors.Is(err, sentinel), "%s is %s", test.err, sentinel)
+		}
+
+		if test.expected != nil {
+			assert.Equal(t, errcode.Of(test.expected), errcode.Of(err))
+		}
+	}
+
+	assert.True(t, errors.Is(client.ErrRateLimited, client.ErrBackpressure))
+}
+
+func TestBackpressureError(t *testing.T) {
+	err := &client.BackpressureError{Message: "rate limiter saturated", Delay: 250 * time.Millisecond}
+
+	assert.True(t, errors.Is(err, client.ErrBackpressure))
+	assert.Equal(t, errcode.RPCBackpressure, errcode.Of(err))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	retryAfter, ok := client.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, retryAfter)
+
+	// errors returned by middlewares are wrapped by the client
+	wrapped := client.RPCError{GRPCErr: err}
+	assert.True(t, errors.Is(wrapped, client.ErrBackpressure))
+	assert.Equal(t, errcode.RPCBackpressure, errcode.Of(wrapped))
+
+	retryAfter, ok = client.RetryAfter(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, retryAfter)
+}
@@ -0,0 +1,144 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// DefaultHealthThreshold is the default time within which an Access node must
+// answer a health check.
+const DefaultHealthThreshold = 2 * time.Second
+
+// ErrUnhealthy is returned when an Access node fails a health check.
+var ErrUnhealthy = errcode.New(errcode.NodeUnhealthy, "client: access node is unhealthy")
+
+// A PingResult describes the Access node that answered a ping.
+type PingResult struct {
+	// Endpoint is the address of the node, if known.
+	Endpoint string
+	// Latency is the round-trip time of the ping.
+	Latency time.Duration
+	// ChainID is the network served by the node.
+	ChainID flow.ChainID
+}
+
+// PingNode pings the Access node like Ping, and returns the round-trip latency of
+// the ping and the network served by the node.
+func (c *Client) PingNode(ctx context.Context) (*PingResult, error) {
+	var recorder endpointRecorder
+	ctx = context.WithValue(ctx, endpointRecorderKey{}, &recorder)
+
+	start := time.Now()
+	if err := c.Ping(ctx); err != nil {
+		return nil, err
+	}
+	latency := time.Since(start)
+
+	chainID, err := c.GetNetworkParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PingResult{
+		Endpoint: recorder.endpoint(c),
+		Latency:  latency,
+		ChainID:  chainID,
+	}, nil
+}
+
+// A HealthStatus is the result of a health check.
+type HealthStatus struct {
+	// Latency is the time the node took to return its latest sealed block header.
+	Latency time.Duration
+	// Height is the height of the latest sealed block.
+	Height uint64
+	// BlockAge is the time since the latest sealed block was proposed, and zero if
+	// the node did not report its timestamp.
+	BlockAge time.Duration
+}
+
+// HealthCheck checks that the Access node returns its latest sealed block header
+// within threshold, e.g. to answer the health probes of a load balancer. A
+// threshold of zero or less defaults to DefaultHealthThreshold.
+//
+// The block header is always fetched from the node, bypassing LatestBlockCache. An
+// error wrapping ErrUnhealthy is returned if the call fails or is too slow.
+func (c *Client) HealthCheck(ctx context.Context, threshold time.Duration) (*HealthStatus, error) {
+	if threshold <= 0 {
+		threshold = DefaultHealthThreshold
+	}
+
+	ctx, cancel := context.WithTimeout(withoutLatestCache(ctx), threshold)
+	defer cancel()
+
+	start := time.Now()
+
+	header, err := c.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnhealthy, err)
+	}
+
+	latency := time.Since(start)
+	if latency > threshold {
+		return nil, fmt.Errorf("%w: latest block header took %s, over %s", ErrUnhealthy, latency, threshold)
+	}
+
+	health := &HealthStatus{
+		Latency: latency,
+		Height:  header.Height,
+	}
+	if !header.Timestamp.IsZero() {
+		health.BlockAge = time.Since(header.Timestamp)
+	}
+
+	return health, nil
+}
+
+// HealthHandler returns an HTTP handler for load balancer health probes. It runs
+// HealthCheck with the given threshold, and answers 200 OK if the check passes and
+// 503 Service Unavailable otherwise, with the result of the check as JSON.
+func HealthHandler(c *Client, threshold time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		health, err := c.HealthCheck(r.Context(), threshold)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"healthy": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"healthy":   true,
+			"latencyMs": health.Latency.Milliseconds(),
+			"height":    health.Height,
+		})
+	})
+}
@@ -0,0 +1,114 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// healthNode is an Access API node that answers after a delay.
+type healthNode struct {
+	delay  time.Duration
+	height uint64
+}
+
+func (n *healthNode) Invoke(
+	ctx context.Context,
+	method string,
+	_ interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	select {
+	case <-time.After(n.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	switch method {
+	case client.MethodGetNetworkParameters:
+		reply.(*access.GetNetworkParametersResponse).ChainId = string(flow.Testnet)
+	case client.MethodGetLatestBlockHeader:
+		n.height++
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.height}
+	}
+	return nil
+}
+
+func TestClient_PingNode(t *testing.T) {
+	c := client.NewFromEndpoint(client.NewEndpoint("node", &healthNode{delay: 10 * time.Millisecond}))
+
+	result, err := c.PingNode(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "node", result.Endpoint)
+	assert.Equal(t, flow.Testnet, result.ChainID)
+	assert.True(t, result.Latency >= 10*time.Millisecond)
+}
+
+func TestClient_HealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Healthy", func(t *testing.T) {
+		node := &healthNode{}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", client.Chain(node, client.LatestBlockCache(time.Minute))))
+
+		for height := uint64(1); height <= 2; height++ {
+			health, err := c.HealthCheck(ctx, 0)
+			require.NoError(t, err)
+			assert.Equal(t, height, health.Height)
+		}
+	})
+
+	t.Run("Slow", func(t *testing.T) {
+		c := client.NewFromEndpoint(client.NewEndpoint("node", &healthNode{delay: 100 * time.Millisecond}))
+
+		_, err := c.HealthCheck(ctx, 10*time.Millisecond)
+		assert.True(t, errors.Is(err, client.ErrUnhealthy))
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		for delay, code := range map[time.Duration]int{
+			0:                      http.StatusOK,
+			100 * time.Millisecond: http.StatusServiceUnavailable,
+		} {
+			c := client.NewFromEndpoint(client.NewEndpoint("node", &healthNode{delay: delay}))
+
+			rec := httptest.NewRecorder()
+			client.HealthHandler(c, 10*time.Millisecond).ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+			assert.Equal(t, code, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		}
+	})
+}
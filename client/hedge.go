@@ -0,0 +1,209 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+const defaultHedgeDelay = 100 * time.Millisecond
+
+// LatencySensitiveMethods are the reads that benefit most from hedging: account
+// reads and script executions, whose latency depends on the execution state of the
+// node serving them.
+var LatencySensitiveMethods = []string{
+	MethodGetAccount,
+	MethodGetAccountAtLatestBlock,
+	MethodGetAccountAtBlockHeight,
+	MethodExecuteScriptAtLatestBlock,
+	MethodExecuteScriptAtBlockID,
+	MethodExecuteScriptAtBlockHeight,
+}
+
+// HedgeConfig configures hedged reads.
+type HedgeConfig struct {
+	// Delay is the time to wait for a response from an endpoint before a read is
+	// also sent to the next endpoint.
+	//
+	// Defaults to 100 milliseconds.
+	Delay time.Duration
+	// Methods are the reads that are hedged, e.g. LatencySensitiveMethods. Other
+	// reads are only sent to the primary endpoint.
+	//
+	// Defaults to all reads.
+	Methods []string
+}
+
+// hedgingInvoker is an invoker that sends reads to a primary endpoint and, if it is
+// slow to respond, to the next endpoints in turn.
+type hedgingInvoker struct {
+	endpoints []*Endpoint
+	methods   map[string]bool
+	config    HedgeConfig
+}
+
+// hedgedResult is the response of an endpoint to a hedged read.
+type hedgedResult struct {
+	endpoint *Endpoint
+	reply    interface{}
+	err      error
+}
+
+// NewHedgingInvoker returns an invoker that sends reads to the primary endpoint
+// and, if no response is received within the configured delay, sends a duplicate
+// read to the secondary endpoint. The first successful response is used and the
+// other read is cancelled.
+//
+// Writes are only sent to the primary endpoint, as they are not idempotent. Reads
+// that fail on the primary endpoint before the delay are not hedged.
+func NewHedgingInvoker(primary, secondary *Endpoint, config HedgeConfig) Invoker {
+	return NewMultiHedgingInvoker([]*Endpoint{primary, secondary}, config)
+}
+
+// NewMultiHedgingInvoker returns an invoker that hedges reads across two or more
+// endpoints: reads are sent to the first endpoint and, each time no response is
+// received within the configured delay, a duplicate read is sent to the next
+// endpoint. The first successful response is used and the other reads are cancelled.
+//
+// Writes are only sent to the first endpoint, as they are not idempotent. Reads that
+// fail before the next delay are not hedged further.
+func NewMultiHedgingInvoker(endpoints []*Endpoint, config HedgeConfig) Invoker {
+	if config.Delay == 0 {
+		config.Delay = defaultHedgeDelay
+	}
+
+	var methods map[string]bool
+	if len(config.Methods) > 0 {
+		methods = make(map[string]bool, len(config.Methods))
+		for _, method := range config.Methods {
+			methods[method] = true
+		}
+	}
+
+	return hedgingInvoker{
+		endpoints: endpoints,
+		methods:   methods,
+		config:    config,
+	}
+}
+
+// NewHedged initializes a Flow client that hedges reads from the primary endpoint
+// with the secondary endpoint, as described by NewHedgingInvoker.
+//
+// The endpoints are not closed when the client is closed.
+func NewHedged(primary, secondary *Endpoint, config HedgeConfig) *Client {
+	return NewMultiHedged([]*Endpoint{primary, secondary}, config)
+}
+
+// NewMultiHedged initializes a Flow client that hedges reads across the endpoints,
+// as described by NewMultiHedgingInvoker:
+//
+//	c := client.NewMultiHedged(endpoints, client.HedgeConfig{
+//		Delay:   50 * time.Millisecond,
+//		Methods: client.LatencySensitiveMethods,
+//	})
+//
+// The endpoints are not closed when the client is closed.
+func NewMultiHedged(endpoints []*Endpoint, config HedgeConfig) *Client {
+	return NewFromRPCClient(NewRPCClient(NewMultiHedgingInvoker(endpoints, config)))
+}
+
+// hedged returns true if calls to the method are hedged.
+func (h hedgingInvoker) hedged(method string) bool {
+	if IsWriteMethod(method) || len(h.endpoints) < 2 {
+		return false
+	}
+	return h.methods == nil || h.methods[method]
+}
+
+func (h hedgingInvoker) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	if len(h.endpoints) == 0 {
+		return ErrNoEndpoint
+	}
+
+	if !h.hedged(method) {
+		primary := h.endpoints[0]
+		recordEndpoint(ctx, primary.Address)
+		return primary.Invoker.Invoke(ctx, method, args, reply, opts...)
+	}
+
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgedResult, len(h.endpoints))
+	call := func(endpoint *Endpoint) {
+		endpointReply := reflect.New(reflect.TypeOf(reply).Elem()).Interface()
+		err := endpoint.Invoker.Invoke(hedgeCtx, method, args, endpointReply, opts...)
+		results <- hedgedResult{endpoint: endpoint, reply: endpointReply, err: err}
+	}
+
+	go call(h.endpoints[0])
+	next := 1
+	pending := 1
+
+	timer := time.NewTimer(h.config.Delay)
+	defer timer.Stop()
+	hedge := timer.C
+
+	var firstErr error
+
+	for pending > 0 {
+		select {
+		case <-hedge:
+			go call(h.endpoints[next])
+			next++
+			pending++
+
+			hedge = nil
+			if next < len(h.endpoints) {
+				timer.Reset(h.config.Delay)
+				hedge = timer.C
+			}
+		case result := <-results:
+			pending--
+
+			if result.err == nil {
+				recordEndpoint(ctx, result.endpoint.Address)
+				reply.(proto.Message).Reset()
+				proto.Merge(reply.(proto.Message), result.reply.(proto.Message))
+				return nil
+			}
+
+			if firstErr == nil {
+				firstErr = result.err
+			}
+
+			// a read failed before the next delay
+			hedge = nil
+		}
+	}
+
+	return firstErr
+}
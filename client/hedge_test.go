@@ -0,0 +1,163 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// delayedNode is an Access API node that responds with a fixed block height after a delay.
+type delayedNode struct {
+	delay  time.Duration
+	height uint64
+	calls  int32
+}
+
+func (n *delayedNode) Invoke(
+	ctx context.Context,
+	method string,
+	_ interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	atomic.AddInt32(&n.calls, 1)
+
+	select {
+	case <-time.After(n.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.height}
+	case client.MethodSendTransaction:
+		reply.(*access.SendTransactionResponse).Id = []byte{byte(n.height)}
+	}
+
+	return nil
+}
+
+func TestClient_Hedged(t *testing.T) {
+	ctx := context.Background()
+	config := client.HedgeConfig{Delay: 20 * time.Millisecond}
+
+	t.Run("Fast primary", func(t *testing.T) {
+		primary := &delayedNode{height: 1}
+		secondary := &delayedNode{height: 2}
+
+		c := client.NewHedged(
+			client.NewEndpoint("primary", primary),
+			client.NewEndpoint("secondary", secondary),
+			config,
+		)
+
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), header.Height)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&secondary.calls))
+	})
+
+	t.Run("Slow primary", func(t *testing.T) {
+		primary := &delayedNode{height: 1, delay: time.Second}
+		secondary := &delayedNode{height: 2}
+
+		c := client.NewHedged(
+			client.NewEndpoint("primary", primary),
+			client.NewEndpoint("secondary", secondary),
+			config,
+		)
+
+		start := time.Now()
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(2), header.Height)
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	})
+
+	t.Run("Writes", func(t *testing.T) {
+		primary := &delayedNode{height: 1, delay: 50 * time.Millisecond}
+		secondary := &delayedNode{height: 2}
+
+		c := client.NewHedged(
+			client.NewEndpoint("primary", primary),
+			client.NewEndpoint("secondary", secondary),
+			config,
+		)
+
+		err := c.SendTransaction(ctx, *test.TransactionGenerator().New())
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&primary.calls))
+		assert.Equal(t, int32(0), atomic.LoadInt32(&secondary.calls))
+	})
+	t.Run("Multiple endpoints", func(t *testing.T) {
+		first := &delayedNode{height: 1, delay: time.Second}
+		second := &delayedNode{height: 2, delay: time.Second}
+		third := &delayedNode{height: 3}
+
+		c := client.NewMultiHedged([]*client.Endpoint{
+			client.NewEndpoint("first", first),
+			client.NewEndpoint("second", second),
+			client.NewEndpoint("third", third),
+		}, config)
+
+		start := time.Now()
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(3), header.Height)
+		assert.Less(t, int64(time.Since(start)), int64(time.Second))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&second.calls))
+	})
+
+	t.Run("Only hedges the configured methods", func(t *testing.T) {
+		primary := &delayedNode{height: 1, delay: 50 * time.Millisecond}
+		secondary := &delayedNode{height: 2}
+
+		c := client.NewHedged(
+			client.NewEndpoint("primary", primary),
+			client.NewEndpoint("secondary", secondary),
+			client.HedgeConfig{
+				Delay:   time.Millisecond,
+				Methods: client.LatencySensitiveMethods,
+			},
+		)
+
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(1), header.Height)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&secondary.calls))
+	})
+}
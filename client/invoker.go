@@ -0,0 +1,351 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"google.golang.org/grpc"
+)
+
+// Full gRPC method names of the Access API.
+const (
+	MethodPing                       = "/flow.access.AccessAPI/Ping"
+	MethodGetLatestBlockHeader       = "/flow.access.AccessAPI/GetLatestBlockHeader"
+	MethodGetBlockHeaderByID         = "/flow.access.AccessAPI/GetBlockHeaderByID"
+	MethodGetBlockHeaderByHeight     = "/flow.access.AccessAPI/GetBlockHeaderByHeight"
+	MethodGetLatestBlock             = "/flow.access.AccessAPI/GetLatestBlock"
+	MethodGetBlockByID               = "/flow.access.AccessAPI/GetBlockByID"
+	MethodGetBlockByHeight           = "/flow.access.AccessAPI/GetBlockByHeight"
+	MethodGetCollectionByID          = "/flow.access.AccessAPI/GetCollectionByID"
+	MethodSendTransaction            = "/flow.access.AccessAPI/SendTransaction"
+	MethodGetTransaction             = "/flow.access.AccessAPI/GetTransaction"
+	MethodGetTransactionResult       = "/flow.access.AccessAPI/GetTransactionResult"
+	MethodGetAccount                 = "/flow.access.AccessAPI/GetAccount"
+	MethodGetAccountAtLatestBlock    = "/flow.access.AccessAPI/GetAccountAtLatestBlock"
+	MethodGetAccountAtBlockHeight    = "/flow.access.AccessAPI/GetAccountAtBlockHeight"
+	MethodExecuteScriptAtLatestBlock = "/flow.access.AccessAPI/ExecuteScriptAtLatestBlock"
+	MethodExecuteScriptAtBlockID     = "/flow.access.AccessAPI/ExecuteScriptAtBlockID"
+	MethodExecuteScriptAtBlockHeight = "/flow.access.AccessAPI/ExecuteScriptAtBlockHeight"
+	MethodGetEventsForHeightRange    = "/flow.access.AccessAPI/GetEventsForHeightRange"
+	MethodGetEventsForBlockIDs       = "/flow.access.AccessAPI/GetEventsForBlockIDs"
+	MethodGetNetworkParameters       = "/flow.access.AccessAPI/GetNetworkParameters"
+)
+
+// IsWriteMethod returns true if the given Access API method mutates network state.
+//
+// All other Access API methods are read-only and can safely be served by any node.
+func IsWriteMethod(method string) bool {
+	return method == MethodSendTransaction
+}
+
+// An Invoker performs unary RPCs against an Access API.
+//
+// A *grpc.ClientConn satisfies this interface. Invokers can be composed to route or
+// otherwise decorate calls before they are sent over the wire.
+type Invoker interface {
+	Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error
+}
+
+// An InvokerFunc is a function that satisfies the Invoker interface.
+type InvokerFunc func(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error
+
+// Invoke calls f(ctx, method, args, reply, opts...).
+func (f InvokerFunc) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	return f(ctx, method, args, reply, opts...)
+}
+
+// NewRPCClient returns an RPC client that sends all Access API calls through the given invoker.
+func NewRPCClient(invoker Invoker) RPCClient {
+	return &invokerRPCClient{invoker: invoker}
+}
+
+// invokerRPCClient implements the Access API client on top of an Invoker.
+type invokerRPCClient struct {
+	invoker Invoker
+}
+
+var _ RPCClient = &invokerRPCClient{}
+
+func (c *invokerRPCClient) Ping(
+	ctx context.Context,
+	in *access.PingRequest,
+	opts ...grpc.CallOption,
+) (*access.PingResponse, error) {
+	out := new(access.PingResponse)
+	err := c.invoker.Invoke(ctx, MethodPing, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetLatestBlockHeader(
+	ctx context.Context,
+	in *access.GetLatestBlockHeaderRequest,
+	opts ...grpc.CallOption,
+) (*access.BlockHeaderResponse, error) {
+	out := new(access.BlockHeaderResponse)
+	err := c.invoker.Invoke(ctx, MethodGetLatestBlockHeader, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetBlockHeaderByID(
+	ctx context.Context,
+	in *access.GetBlockHeaderByIDRequest,
+	opts ...grpc.CallOption,
+) (*access.BlockHeaderResponse, error) {
+	out := new(access.BlockHeaderResponse)
+	err := c.invoker.Invoke(ctx, MethodGetBlockHeaderByID, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetBlockHeaderByHeight(
+	ctx context.Context,
+	in *access.GetBlockHeaderByHeightRequest,
+	opts ...grpc.CallOption,
+) (*access.BlockHeaderResponse, error) {
+	out := new(access.BlockHeaderResponse)
+	err := c.invoker.Invoke(ctx, MethodGetBlockHeaderByHeight, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetLatestBlock(
+	ctx context.Context,
+	in *access.GetLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (*access.BlockResponse, error) {
+	out := new(access.BlockResponse)
+	err := c.invoker.Invoke(ctx, MethodGetLatestBlock, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetBlockByID(
+	ctx context.Context,
+	in *access.GetBlockByIDRequest,
+	opts ...grpc.CallOption,
+) (*access.BlockResponse, error) {
+	out := new(access.BlockResponse)
+	err := c.invoker.Invoke(ctx, MethodGetBlockByID, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetBlockByHeight(
+	ctx context.Context,
+	in *access.GetBlockByHeightRequest,
+	opts ...grpc.CallOption,
+) (*access.BlockResponse, error) {
+	out := new(access.BlockResponse)
+	err := c.invoker.Invoke(ctx, MethodGetBlockByHeight, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetCollectionByID(
+	ctx context.Context,
+	in *access.GetCollectionByIDRequest,
+	opts ...grpc.CallOption,
+) (*access.CollectionResponse, error) {
+	out := new(access.CollectionResponse)
+	err := c.invoker.Invoke(ctx, MethodGetCollectionByID, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) SendTransaction(
+	ctx context.Context,
+	in *access.SendTransactionRequest,
+	opts ...grpc.CallOption,
+) (*access.SendTransactionResponse, error) {
+	out := new(access.SendTransactionResponse)
+	err := c.invoker.Invoke(ctx, MethodSendTransaction, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetTransaction(
+	ctx context.Context,
+	in *access.GetTransactionRequest,
+	opts ...grpc.CallOption,
+) (*access.TransactionResponse, error) {
+	out := new(access.TransactionResponse)
+	err := c.invoker.Invoke(ctx, MethodGetTransaction, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetTransactionResult(
+	ctx context.Context,
+	in *access.GetTransactionRequest,
+	opts ...grpc.CallOption,
+) (*access.TransactionResultResponse, error) {
+	out := new(access.TransactionResultResponse)
+	err := c.invoker.Invoke(ctx, MethodGetTransactionResult, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetAccount(
+	ctx context.Context,
+	in *access.GetAccountRequest,
+	opts ...grpc.CallOption,
+) (*access.GetAccountResponse, error) {
+	out := new(access.GetAccountResponse)
+	err := c.invoker.Invoke(ctx, MethodGetAccount, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetAccountAtLatestBlock(
+	ctx context.Context,
+	in *access.GetAccountAtLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (*access.AccountResponse, error) {
+	out := new(access.AccountResponse)
+	err := c.invoker.Invoke(ctx, MethodGetAccountAtLatestBlock, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetAccountAtBlockHeight(
+	ctx context.Context,
+	in *access.GetAccountAtBlockHeightRequest,
+	opts ...grpc.CallOption,
+) (*access.AccountResponse, error) {
+	out := new(access.AccountResponse)
+	err := c.invoker.Invoke(ctx, MethodGetAccountAtBlockHeight, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) ExecuteScriptAtLatestBlock(
+	ctx context.Context,
+	in *access.ExecuteScriptAtLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (*access.ExecuteScriptResponse, error) {
+	out := new(access.ExecuteScriptResponse)
+	err := c.invoker.Invoke(ctx, MethodExecuteScriptAtLatestBlock, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) ExecuteScriptAtBlockID(
+	ctx context.Context,
+	in *access.ExecuteScriptAtBlockIDRequest,
+	opts ...grpc.CallOption,
+) (*access.ExecuteScriptResponse, error) {
+	out := new(access.ExecuteScriptResponse)
+	err := c.invoker.Invoke(ctx, MethodExecuteScriptAtBlockID, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) ExecuteScriptAtBlockHeight(
+	ctx context.Context,
+	in *access.ExecuteScriptAtBlockHeightRequest,
+	opts ...grpc.CallOption,
+) (*access.ExecuteScriptResponse, error) {
+	out := new(access.ExecuteScriptResponse)
+	err := c.invoker.Invoke(ctx, MethodExecuteScriptAtBlockHeight, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetEventsForHeightRange(
+	ctx context.Context,
+	in *access.GetEventsForHeightRangeRequest,
+	opts ...grpc.CallOption,
+) (*access.EventsResponse, error) {
+	out := new(access.EventsResponse)
+	err := c.invoker.Invoke(ctx, MethodGetEventsForHeightRange, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetEventsForBlockIDs(
+	ctx context.Context,
+	in *access.GetEventsForBlockIDsRequest,
+	opts ...grpc.CallOption,
+) (*access.EventsResponse, error) {
+	out := new(access.EventsResponse)
+	err := c.invoker.Invoke(ctx, MethodGetEventsForBlockIDs, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *invokerRPCClient) GetNetworkParameters(
+	ctx context.Context,
+	in *access.GetNetworkParametersRequest,
+	opts ...grpc.CallOption,
+) (*access.GetNetworkParametersResponse, error) {
+	out := new(access.GetNetworkParametersResponse)
+	err := c.invoker.Invoke(ctx, MethodGetNetworkParameters, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,209 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// An EventCursor is the position of an EventIterator: the height of a block, and
+// the number of events of the block that were already returned.
+//
+// A cursor is saved as a string, e.g. in a checkpoint, and parsed with
+// ParseEventCursor to resume iterating.
+type EventCursor struct {
+	Height uint64
+	Index  int
+}
+
+// String returns the string representation of the cursor, "height:index".
+func (c EventCursor) String() string {
+	return fmt.Sprintf("%d:%d", c.Height, c.Index)
+}
+
+// ParseEventCursor parses the string representation of a cursor.
+func ParseEventCursor(s string) (EventCursor, error) {
+	var c EventCursor
+	if _, err := fmt.Sscanf(s, "%d:%d", &c.Height, &c.Index); err != nil || c.Index < 0 {
+		return EventCursor{}, fmt.Errorf("client: invalid event cursor %q", s)
+	}
+	if c.String() != s {
+		return EventCursor{}, fmt.Errorf("client: invalid event cursor %q", s)
+	}
+	return c, nil
+}
+
+// A BlockEvent is an event with the block it was emitted in.
+type BlockEvent struct {
+	flow.Event
+	BlockID        flow.Identifier
+	Height         uint64
+	BlockTimestamp time.Time
+}
+
+// An EventIterator iterates over the events of a height range one event at a time,
+// querying the blocks of the range in pages:
+//
+//	it := c.EventIterator(client.EventRangeQuery{
+//		Type:        "flow.AccountCreated",
+//		StartHeight: 1000,
+//		EndHeight:   20000,
+//	})
+//
+//	for {
+//		event, err := it.Next(ctx)
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		...
+//		save(it.Cursor().String())
+//	}
+//
+// A failed query does not move the iterator: Next returns the error, and the next
+// call queries the same blocks again. Pages whose results exceed the maximum
+// message size are split until they fit.
+//
+// An EventIterator is not safe for concurrent use.
+type EventIterator struct {
+	client   *Client
+	query    EventRangeQuery
+	pageSize uint64
+
+	cursor EventCursor
+	// blocks are the queried blocks that were not fully returned.
+	blocks []BlockEvents
+	// queried is the last height of the queried pages, if fetched is true.
+	queried uint64
+	fetched bool
+}
+
+// EventIterator returns an iterator over the events of the query, starting at its
+// start height. If the end height of the query is zero, it iterates up to the
+// latest sealed block at the time of the first call to Next.
+func (c *Client) EventIterator(query EventRangeQuery) *EventIterator {
+	return &EventIterator{
+		client:   c,
+		query:    query,
+		pageSize: defaultMaxPollRange,
+		cursor:   EventCursor{Height: query.StartHeight},
+	}
+}
+
+// Seek moves the iterator to a cursor, e.g. to resume iterating from a cursor saved
+// by a previous iterator over the same query.
+func (it *EventIterator) Seek(cursor EventCursor) error {
+	if cursor.Height < it.query.StartHeight {
+		return fmt.Errorf("client: cursor %s before start height %d", cursor, it.query.StartHeight)
+	}
+
+	it.cursor = cursor
+	it.blocks = nil
+	it.fetched = false
+	return nil
+}
+
+// Cursor returns the position of the iterator, after the last returned event.
+func (it *EventIterator) Cursor() EventCursor {
+	return it.cursor
+}
+
+// Next returns the next event, or io.EOF once all events of the range were returned.
+func (it *EventIterator) Next(ctx context.Context) (BlockEvent, error) {
+	for {
+		for len(it.blocks) > 0 {
+			block := it.blocks[0]
+
+			if block.Height == it.cursor.Height && it.cursor.Index < len(block.Events) {
+				event := block.Events[it.cursor.Index]
+				it.cursor.Index++
+
+				return BlockEvent{
+					Event:          event,
+					BlockID:        block.BlockID,
+					Height:         block.Height,
+					BlockTimestamp: block.BlockTimestamp,
+				}, nil
+			}
+
+			it.blocks = it.blocks[1:]
+			if block.Height >= it.cursor.Height {
+				it.cursor = EventCursor{Height: block.Height + 1}
+			}
+		}
+
+		// all blocks of the queried pages were returned, including blocks that the
+		// Access API did not return because they have no events
+		if it.fetched && it.cursor.Height <= it.queried {
+			it.cursor = EventCursor{Height: it.queried + 1}
+		}
+
+		if err := it.fetch(ctx); err != nil {
+			return BlockEvent{}, err
+		}
+	}
+}
+
+// fetch queries the next page of blocks, and returns io.EOF past the end height.
+func (it *EventIterator) fetch(ctx context.Context) error {
+	if it.query.EndHeight == 0 {
+		header, err := it.client.GetLatestBlockHeader(ctx, true)
+		if err != nil {
+			return err
+		}
+		it.query.EndHeight = header.Height
+	}
+
+	if it.cursor.Height > it.query.EndHeight {
+		return io.EOF
+	}
+
+	for {
+		end := it.cursor.Height + it.pageSize - 1
+		if end > it.query.EndHeight || end < it.cursor.Height {
+			end = it.query.EndHeight
+		}
+
+		blocks, err := it.client.GetEventsForHeightRange(ctx, EventRangeQuery{
+			Type:        it.query.Type,
+			StartHeight: it.cursor.Height,
+			EndHeight:   end,
+		})
+		if errors.Is(err, ErrMessageTooLarge) && it.pageSize > 1 {
+			it.pageSize /= 2
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		it.blocks = blocks
+		it.queried = end
+		it.fetched = true
+		return nil
+	}
+}
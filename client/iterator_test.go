@@ -0,0 +1,184 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// eventNode is an Access API node whose block at height h has h%3 events. It
+// rejects queries over more than 100 blocks as too large, and fails queries that
+// include the fail height once.
+type eventNode struct {
+	mu      sync.Mutex
+	ranges  [][2]uint64
+	fail    uint64
+	latest  uint64
+	message *entities.Event
+}
+
+func (n *eventNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if method == client.MethodGetLatestBlockHeader {
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.latest}
+		return nil
+	}
+
+	req := args.(*access.GetEventsForHeightRangeRequest)
+	n.ranges = append(n.ranges, [2]uint64{req.StartHeight, req.EndHeight})
+
+	if req.EndHeight-req.StartHeight >= 100 {
+		return status.Error(codes.ResourceExhausted, "grpc: received message larger than max (5000000 vs. 4194304)")
+	}
+
+	if n.fail != 0 && req.StartHeight <= n.fail && n.fail <= req.EndHeight {
+		n.fail = 0
+		return status.Error(codes.Unavailable, "connection reset")
+	}
+
+	res := reply.(*access.EventsResponse)
+	for height := req.StartHeight; height <= req.EndHeight; height++ {
+		result := &access.EventsResponse_Result{BlockHeight: height, BlockTimestamp: ptypes.TimestampNow()}
+		for i := uint64(0); i < height%3; i++ {
+			event := *n.message
+			event.EventIndex = uint32(i)
+			result.Events = append(result.Events, &event)
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	return nil
+}
+
+func TestEventIterator(t *testing.T) {
+	ctx := context.Background()
+
+	message, err := convert.EventToMessage(test.EventGenerator().New())
+	require.NoError(t, err)
+
+	query := client.EventRangeQuery{Type: "A.0000000000000001.Test.Event", StartHeight: 10, EndHeight: 309}
+
+	// expected returns the number of events in the given heights.
+	expected := func(start, end uint64) int {
+		n := 0
+		for height := start; height <= end; height++ {
+			n += int(height % 3)
+		}
+		return n
+	}
+
+	t.Run("Pages", func(t *testing.T) {
+		node := &eventNode{message: message, fail: 150}
+		it := client.NewFromEndpoint(client.NewEndpoint("node", node)).EventIterator(query)
+
+		var (
+			count    int
+			failures int
+			last     client.BlockEvent
+		)
+		for {
+			event, err := it.Next(ctx)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				failures++
+				require.Equal(t, 1, failures)
+				continue
+			}
+
+			if count > 0 {
+				assert.True(t, event.Height > last.Height || event.EventIndex == last.EventIndex+1)
+			}
+			assert.Equal(t, client.EventCursor{Height: event.Height, Index: event.EventIndex + 1}, it.Cursor())
+
+			last = event
+			count++
+		}
+
+		assert.Equal(t, expected(10, 309), count)
+		assert.Equal(t, 1, failures)
+		assert.Equal(t, client.EventCursor{Height: 310}, it.Cursor())
+
+		_, err := it.Next(ctx)
+		assert.Equal(t, io.EOF, err)
+
+		// the first page was split until it fit
+		assert.Equal(t, [2]uint64{10, 259}, node.ranges[0])
+		assert.Equal(t, [2]uint64{10, 71}, node.ranges[2])
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		node := &eventNode{message: message, latest: 50}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		cursor, err := client.ParseEventCursor("20:1")
+		require.NoError(t, err)
+
+		it := c.EventIterator(client.EventRangeQuery{Type: query.Type, StartHeight: 10})
+		require.NoError(t, it.Seek(cursor))
+
+		event, err := it.Next(ctx)
+		require.NoError(t, err)
+		assert.EqualValues(t, 20, event.Height)
+		assert.Equal(t, 1, event.EventIndex)
+
+		count := 1
+		for {
+			_, err := it.Next(ctx)
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			count++
+		}
+		assert.Equal(t, expected(21, 50)+1, count)
+
+		assert.Error(t, it.Seek(client.EventCursor{Height: 5}))
+
+		for _, s := range []string{"", "20", "20:-1", "a:1", "20:1x"} {
+			_, err := client.ParseEventCursor(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrKeyNotFound is returned when an account has no usable key with a public key.
+var ErrKeyNotFound = errcode.New(errcode.KeyNotFound, "client: account key not found")
+
+// FindKeyIndex returns the index of the key of an account with the given public key,
+// for signers that only know their key material. See FindAccountKey.
+func (c *Client) FindKeyIndex(ctx context.Context, address flow.Address, publicKey crypto.PublicKey) (int, error) {
+	key, err := c.FindAccountKey(ctx, address, publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	return key.Index, nil
+}
+
+// FindAccountKey returns the key of an account with the given public key at the
+// latest sealed block, e.g. to sign with its index and hash algorithm.
+//
+// Revoked keys are ignored, as are keys with another signature algorithm or with a
+// hash algorithm that cannot be used with it. If the public key was added several
+// times, the key with the lowest index is returned. An error wrapping ErrKeyNotFound
+// is returned if the account has no usable key with the public key.
+func (c *Client) FindAccountKey(
+	ctx context.Context,
+	address flow.Address,
+	publicKey crypto.PublicKey,
+) (*flow.AccountKey, error) {
+	keys, err := c.sortedAccountKeys(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := publicKey.Encode()
+
+	for _, key := range keys {
+		if key.Revoked ||
+			key.SigAlgo != publicKey.Algorithm() ||
+			!crypto.CompatibleAlgorithms(key.SigAlgo, key.HashAlgo) ||
+			!bytes.Equal(key.PublicKey.Encode(), encoded) {
+			continue
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: account %s has no usable key %x", ErrKeyNotFound, address, encoded)
+}
+
+// GetAccountKeys gets at most limit keys of an account, starting at the given key
+// index, at the latest sealed block. Keys are returned in index order.
+//
+// The Access API does not support requests for a range of account keys, so the
+// account is fetched in full and the range is selected by the client. Paging through
+// keys with this function therefore fetches the account once per page; use
+// StreamAccountKeys to read all keys of an account with a single request.
+func (c *Client) GetAccountKeys(
+	ctx context.Context,
+	address flow.Address,
+	start int,
+	limit int,
+) ([]*flow.AccountKey, error) {
+	if start < 0 || limit <= 0 {
+		return nil, fmt.Errorf("client: invalid key range %d+%d", start, limit)
+	}
+
+	keys, err := c.sortedAccountKeys(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i].Index >= start
+	})
+
+	keys = keys[i:]
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	return keys, nil
+}
+
+// StreamAccountKeys streams the keys of an account at the latest sealed block in
+// pages of at most pageSize keys, in index order.
+//
+// All pages are read from the same block. The error channel receives a single
+// value, nil if all keys were streamed, and both channels are then closed.
+func (c *Client) StreamAccountKeys(
+	ctx context.Context,
+	address flow.Address,
+	pageSize int,
+) (<-chan []*flow.AccountKey, <-chan error) {
+	pages := make(chan []*flow.AccountKey)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(pages)
+		defer close(errs)
+
+		errs <- c.streamAccountKeys(ctx, address, pageSize, pages)
+	}()
+
+	return pages, errs
+}
+
+func (c *Client) streamAccountKeys(
+	ctx context.Context,
+	address flow.Address,
+	pageSize int,
+	pages chan<- []*flow.AccountKey,
+) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("client: invalid page size %d", pageSize)
+	}
+
+	keys, err := c.sortedAccountKeys(ctx, address)
+	if err != nil {
+		return err
+	}
+
+	for len(keys) > 0 {
+		n := pageSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+
+		select {
+		case pages <- keys[:n]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+func (c *Client) sortedAccountKeys(ctx context.Context, address flow.Address) ([]*flow.AccountKey, error) {
+	account, err := c.GetAccountAtLatestBlock(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := account.Keys
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Index < keys[j].Index
+	})
+
+	return keys, nil
+}
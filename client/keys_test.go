@@ -0,0 +1,202 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func keysClient(t *testing.T, count int) (*client.Client, flow.Address) {
+	account := test.AccountGenerator().New()
+	keys := test.AccountKeyGenerator()
+
+	account.Keys = nil
+	// keys are returned out of order
+	for i := count - 1; i >= 0; i-- {
+		key := keys.New()
+		key.Index = i
+		account.Keys = append(account.Keys, key)
+	}
+
+	invoker := client.InvokerFunc(func(
+		_ context.Context,
+		method string,
+		_ interface{},
+		reply interface{},
+		_ ...grpc.CallOption,
+	) error {
+		require.Equal(t, client.MethodGetAccountAtLatestBlock, method)
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(*account)
+		return nil
+	})
+
+	return client.NewFromEndpoint(client.NewEndpoint("node", invoker)), account.Address
+}
+
+func indexes(keys []*flow.AccountKey) []int {
+	result := make([]int, len(keys))
+	for i, key := range keys {
+		result[i] = key.Index
+	}
+	return result
+}
+
+func TestClient_GetAccountKeys(t *testing.T) {
+	ctx := context.Background()
+	c, address := keysClient(t, 7)
+
+	keys, err := c.GetAccountKeys(ctx, address, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, indexes(keys))
+
+	keys, err = c.GetAccountKeys(ctx, address, 5, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 6}, indexes(keys))
+
+	keys, err = c.GetAccountKeys(ctx, address, 10, 10)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = c.GetAccountKeys(ctx, address, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestClient_StreamAccountKeys(t *testing.T) {
+	ctx := context.Background()
+	c, address := keysClient(t, 7)
+
+	pages, errs := c.StreamAccountKeys(ctx, address, 3)
+
+	var result [][]int
+	for page := range pages {
+		result = append(result, indexes(page))
+	}
+
+	require.NoError(t, <-errs)
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6}}, result)
+
+	t.Run("Invalid page size", func(t *testing.T) {
+		pages, errs := c.StreamAccountKeys(ctx, address, 0)
+
+		_, ok := <-pages
+		assert.False(t, ok)
+		assert.Error(t, <-errs)
+	})
+}
+
+func TestClient_GetAccountKeys_ProposerOnly(t *testing.T) {
+	account := test.AccountGenerator().New()
+	generated := test.AccountKeyGenerator().New()
+
+	key := flow.NewProposerOnlyKey(generated.PublicKey, generated.HashAlgo)
+	key.Index = 1
+	key.SequenceNumber = 3
+	account.Keys = []*flow.AccountKey{key}
+
+	invoker := client.InvokerFunc(func(
+		_ context.Context,
+		_ string,
+		_ interface{},
+		reply interface{},
+		_ ...grpc.CallOption,
+	) error {
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(*account)
+		return nil
+	})
+
+	c := client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+
+	keys, err := c.GetAccountKeys(context.Background(), account.Address, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	assert.Equal(t, key, keys[0])
+	assert.True(t, keys[0].IsProposerOnly())
+	assert.NoError(t, keys[0].Validate())
+}
+
+func TestClient_FindKeyIndex(t *testing.T) {
+	ctx := context.Background()
+
+	generate := func(sigAlgo crypto.SignatureAlgorithm, seed byte) crypto.PrivateKey {
+		key, err := crypto.GeneratePrivateKey(sigAlgo, bytes.Repeat([]byte{seed}, crypto.MinSeedLength))
+		require.NoError(t, err)
+		return key
+	}
+
+	signer := generate(crypto.ECDSA_P256, 1)
+	other := generate(crypto.ECDSA_P256, 2)
+
+	newKey := func(index int, privateKey crypto.PrivateKey, hashAlgo crypto.HashAlgorithm) *flow.AccountKey {
+		key := flow.NewAccountKey().
+			FromPrivateKey(privateKey).
+			SetHashAlgo(hashAlgo).
+			SetWeight(flow.AccountKeyWeightThreshold)
+		key.Index = index
+		return key
+	}
+
+	revoked := newKey(0, signer, crypto.SHA3_256)
+	revoked.Revoked = true
+
+	account := &flow.Account{
+		Address: flow.HexToAddress("01"),
+		Keys: []*flow.AccountKey{
+			newKey(4, signer, crypto.SHA2_256),
+			newKey(3, signer, crypto.SHA3_256),
+			newKey(2, other, crypto.SHA3_256),
+			newKey(1, signer, crypto.HashAlgorithm(42)),
+			revoked,
+		},
+	}
+
+	invoker := client.InvokerFunc(func(_ context.Context, _ string, _, reply interface{}, _ ...grpc.CallOption) error {
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(*account)
+		return nil
+	})
+	c := client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+
+	index, err := c.FindKeyIndex(ctx, account.Address, signer.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, 3, index)
+
+	key, err := c.FindAccountKey(ctx, account.Address, other.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, 2, key.Index)
+	assert.Equal(t, crypto.SHA3_256, key.HashAlgo)
+
+	_, err = c.FindKeyIndex(ctx, account.Address, generate(crypto.ECDSA_secp256k1, 1).PublicKey())
+	assert.True(t, errors.Is(err, client.ErrKeyNotFound))
+	assert.Equal(t, errcode.KeyNotFound, errcode.Of(err))
+}
@@ -0,0 +1,132 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// latestMethods are the methods whose responses are cached by LatestBlockCache.
+var latestMethods = map[string]bool{
+	MethodGetLatestBlockHeader: true,
+	MethodGetLatestBlock:       true,
+}
+
+// LatestBlockCache returns a middleware that caches the responses of
+// GetLatestBlockHeader and GetLatestBlock for the given time, so that components
+// polling the latest block, e.g. to set the reference block of transactions, share
+// a single call per interval instead of each calling the Access API.
+//
+// The Access API has no conditional requests to revalidate a response with, so
+// cached responses are served until they expire: the latest block seen by callers is
+// at most ttl older than the one returned by the Access API. Sealed and unsealed
+// queries are cached separately. A ttl of zero or less disables the cache.
+func LatestBlockCache(ttl time.Duration) Middleware {
+	cache := &latestCache{
+		ttl:     ttl,
+		entries: make(map[string]latestCacheEntry),
+	}
+
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			req, isReq := args.(proto.Message)
+			res, isRes := reply.(proto.Message)
+			if ttl <= 0 || !latestMethods[method] || !isReq || !isRes || bypassLatestCache(ctx) {
+				return next.Invoke(ctx, method, args, reply, opts...)
+			}
+
+			key, err := proto.Marshal(req)
+			if err != nil {
+				return next.Invoke(ctx, method, args, reply, opts...)
+			}
+
+			if cache.get(method+string(key), res) {
+				return nil
+			}
+
+			if err := next.Invoke(ctx, method, args, reply, opts...); err != nil {
+				return err
+			}
+
+			cache.put(method+string(key), res)
+			return nil
+		})
+	}
+}
+
+type noLatestCacheKey struct{}
+
+// withoutLatestCache returns a context whose calls are not served from the cache.
+func withoutLatestCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noLatestCacheKey{}, true)
+}
+
+func bypassLatestCache(ctx context.Context) bool {
+	bypass, _ := ctx.Value(noLatestCacheKey{}).(bool)
+	return bypass
+}
+
+// latestCache holds the encoded responses of latest block calls.
+type latestCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]latestCacheEntry
+}
+
+type latestCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// get decodes the cached response for key into reply, and reports whether it was found.
+func (c *latestCache) get(key string, reply proto.Message) bool {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if !ok || time.Now().After(entry.expires) {
+		return false
+	}
+
+	return proto.Unmarshal(entry.value, reply) == nil
+}
+
+func (c *latestCache) put(key string, reply proto.Message) {
+	value, err := proto.Marshal(reply)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = latestCacheEntry{value: value, expires: time.Now().Add(c.ttl)}
+}
@@ -0,0 +1,76 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestLatestBlockCache(t *testing.T) {
+	ctx := context.Background()
+
+	calls := make(map[string]int)
+	var height uint64
+
+	node := client.InvokerFunc(func(_ context.Context, method string, _, reply interface{}, _ ...grpc.CallOption) error {
+		calls[method]++
+		height++
+
+		if res, ok := reply.(*access.BlockHeaderResponse); ok {
+			res.Block = &entities.BlockHeader{Height: height}
+		}
+		return nil
+	})
+
+	invoker := client.Chain(node, client.LatestBlockCache(50*time.Millisecond))
+
+	latest := func(isSealed bool) uint64 {
+		res := new(access.BlockHeaderResponse)
+		req := &access.GetLatestBlockHeaderRequest{IsSealed: isSealed}
+		require.NoError(t, invoker.Invoke(ctx, client.MethodGetLatestBlockHeader, req, res))
+		return res.Block.Height
+	}
+
+	assert.EqualValues(t, 1, latest(true))
+	assert.EqualValues(t, 1, latest(true))
+	assert.EqualValues(t, 2, latest(false))
+	assert.EqualValues(t, 2, latest(false))
+	assert.Equal(t, 2, calls[client.MethodGetLatestBlockHeader])
+
+	// other methods are not cached
+	for i := 0; i < 2; i++ {
+		res := new(access.BlockHeaderResponse)
+		req := &access.GetBlockHeaderByHeightRequest{Height: 1}
+		require.NoError(t, invoker.Invoke(ctx, client.MethodGetBlockHeaderByHeight, req, res))
+	}
+	assert.Equal(t, 2, calls[client.MethodGetBlockHeaderByHeight])
+
+	time.Sleep(60 * time.Millisecond)
+	assert.EqualValues(t, 5, latest(true))
+}
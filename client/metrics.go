@@ -0,0 +1,352 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CallMetrics describes an Access API call.
+type CallMetrics struct {
+	// Method is the full gRPC method name, e.g. MethodGetAccountAtLatestBlock.
+	Method   string
+	Duration time.Duration
+	// Code is the gRPC status code of the call, codes.OK if it succeeded.
+	Code codes.Code
+	// Retry is true if the call retries a failed call, e.g. with WithRetry or
+	// SubmitTransaction.
+	Retry bool
+}
+
+// A MetricsRecorder records the Access API calls of a client, e.g. to export them
+// to a monitoring system.
+type MetricsRecorder interface {
+	RecordCall(ctx context.Context, call CallMetrics)
+}
+
+// Names of the client-side queues reported with QueueMetrics.
+const (
+	// QueueRateLimiter is the queue of calls waiting for the rate limiter.
+	QueueRateLimiter = "rate_limiter"
+	// QueueConnPool is the queue of calls sent over the connections of a pool.
+	QueueConnPool = "conn_pool"
+)
+
+// QueueMetrics describe the calls held by a client-side queue, e.g. the calls
+// waiting for the rate limiter of a client.
+type QueueMetrics struct {
+	// Queue is the name of the queue, e.g. QueueRateLimiter.
+	Queue string
+	// Waiting is the number of calls waiting for their turn.
+	Waiting int
+	// InFlight is the number of calls sent and not completed yet.
+	InFlight int
+}
+
+// A QueueMetricsRecorder is a MetricsRecorder that also records the depth of the
+// client-side queues, to detect saturation before calls are rejected with
+// backpressure errors. Recorders implementing it are notified each time the depth
+// of a queue changes.
+type QueueMetricsRecorder interface {
+	MetricsRecorder
+	RecordQueue(ctx context.Context, queue QueueMetrics)
+}
+
+// recordQueue records the depth of a queue, if the recorder records queues.
+func recordQueue(ctx context.Context, recorder MetricsRecorder, queue QueueMetrics) {
+	if queues, ok := recorder.(QueueMetricsRecorder); ok {
+		queues.RecordQueue(ctx, queue)
+	}
+}
+
+// MetricsRecorderFunc is a function that implements the MetricsRecorder interface.
+type MetricsRecorderFunc func(ctx context.Context, call CallMetrics)
+
+// RecordCall calls f(ctx, call).
+func (f MetricsRecorderFunc) RecordCall(ctx context.Context, call CallMetrics) {
+	f(ctx, call)
+}
+
+// MetricsMiddleware returns a middleware that records each call with the recorder.
+//
+// Each attempt of a retried call is recorded separately.
+func MetricsMiddleware(recorder MetricsRecorder) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			start := time.Now()
+			err := next.Invoke(ctx, method, args, reply, opts...)
+
+			recorder.RecordCall(ctx, CallMetrics{
+				Method:   method,
+				Duration: time.Since(start),
+				Code:     status.Code(err),
+				Retry:    isRetry(ctx),
+			})
+
+			return err
+		})
+	}
+}
+
+type retryKey struct{}
+
+// withRetry returns a context marking the calls made with it as retries.
+func withRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// isRetry returns true if the context marks calls as retries.
+func isRetry(ctx context.Context) bool {
+	retry, _ := ctx.Value(retryKey{}).(bool)
+	return retry
+}
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram of Metrics.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Metrics is a MetricsRecorder that aggregates calls per method, and serves them in
+// the Prometheus text format:
+//
+//	metrics := client.NewMetrics()
+//	c, err := client.NewClient(addr, client.WithMetrics(metrics))
+//	...
+//	http.Handle("/metrics", metrics)
+//
+// The following metrics are exported, labeled with the method name, e.g.
+// GetAccountAtLatestBlock:
+//
+//	flow_client_calls_total{method, code}        counter of calls by gRPC status code
+//	flow_client_retries_total{method}            counter of retried calls
+//	flow_client_call_duration_seconds{method}    histogram of call latencies
+//
+// as well as the depth of the client-side queues, labeled with the queue name, e.g.
+// rate_limiter:
+//
+//	flow_client_queue_waiting{queue}             gauge of calls waiting in the queue
+//	flow_client_queue_in_flight{queue}           gauge of calls in flight
+//
+// Applications using the Prometheus client library can instead implement
+// MetricsRecorder with their own collectors.
+type Metrics struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*methodMetrics
+	queues  map[string]QueueMetrics
+}
+
+type methodMetrics struct {
+	calls   map[codes.Code]uint64
+	retries uint64
+	// latencies counts the calls in each bucket, and the last element those over
+	// the largest bound.
+	latencies []uint64
+	sum       time.Duration
+}
+
+var _ QueueMetricsRecorder = &Metrics{}
+
+// NewMetrics returns empty metrics with the given latency histogram bucket bounds,
+// in increasing order, or DefaultLatencyBuckets if none are given.
+func NewMetrics(buckets ...time.Duration) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	return &Metrics{
+		buckets: buckets,
+		methods: make(map[string]*methodMetrics),
+		queues:  make(map[string]QueueMetrics),
+	}
+}
+
+// RecordCall adds a call to the metrics.
+func (m *Metrics) RecordCall(_ context.Context, call CallMetrics) {
+	name := path.Base(call.Method)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	method, ok := m.methods[name]
+	if !ok {
+		method = &methodMetrics{
+			calls:     make(map[codes.Code]uint64),
+			latencies: make([]uint64, len(m.buckets)+1),
+		}
+		m.methods[name] = method
+	}
+
+	method.calls[call.Code]++
+	if call.Retry {
+		method.retries++
+	}
+
+	i := sort.Search(len(m.buckets), func(i int) bool {
+		return call.Duration <= m.buckets[i]
+	})
+	method.latencies[i]++
+	method.sum += call.Duration
+}
+
+// RecordQueue updates the depth of a queue.
+func (m *Metrics) RecordQueue(_ context.Context, queue QueueMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queues[queue.Queue] = queue
+}
+
+// WriteTo writes the metrics to w in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.methods))
+	for name := range m.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprintln(cw, "# HELP flow_client_calls_total Access API calls by method and gRPC status code.")
+	fmt.Fprintln(cw, "# TYPE flow_client_calls_total counter")
+	for _, name := range names {
+		method := m.methods[name]
+
+		callCodes := make([]codes.Code, 0, len(method.calls))
+		for code := range method.calls {
+			callCodes = append(callCodes, code)
+		}
+		sort.Slice(callCodes, func(i, j int) bool { return callCodes[i] < callCodes[j] })
+
+		for _, code := range callCodes {
+			fmt.Fprintf(cw, "flow_client_calls_total{method=%q,code=%q} %d\n", name, code.String(), method.calls[code])
+		}
+	}
+
+	fmt.Fprintln(cw, "# HELP flow_client_retries_total Retried Access API calls by method.")
+	fmt.Fprintln(cw, "# TYPE flow_client_retries_total counter")
+	for _, name := range names {
+		fmt.Fprintf(cw, "flow_client_retries_total{method=%q} %d\n", name, m.methods[name].retries)
+	}
+
+	fmt.Fprintln(cw, "# HELP flow_client_call_duration_seconds Access API call latencies by method.")
+	fmt.Fprintln(cw, "# TYPE flow_client_call_duration_seconds histogram")
+	for _, name := range names {
+		method := m.methods[name]
+
+		var count uint64
+		for i, bound := range m.buckets {
+			count += method.latencies[i]
+			fmt.Fprintf(cw, "flow_client_call_duration_seconds_bucket{method=%q,le=%q} %d\n", name, seconds(bound), count)
+		}
+		count += method.latencies[len(m.buckets)]
+
+		fmt.Fprintf(cw, "flow_client_call_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", name, count)
+		fmt.Fprintf(cw, "flow_client_call_duration_seconds_sum{method=%q} %s\n", name, seconds(method.sum))
+		fmt.Fprintf(cw, "flow_client_call_duration_seconds_count{method=%q} %d\n", name, count)
+	}
+
+	if len(m.queues) > 0 {
+		queues := make([]string, 0, len(m.queues))
+		for name := range m.queues {
+			queues = append(queues, name)
+		}
+		sort.Strings(queues)
+
+		fmt.Fprintln(cw, "# HELP flow_client_queue_waiting Calls waiting in client-side queues.")
+		fmt.Fprintln(cw, "# TYPE flow_client_queue_waiting gauge")
+		for _, name := range queues {
+			fmt.Fprintf(cw, "flow_client_queue_waiting{queue=%q} %d\n", name, m.queues[name].Waiting)
+		}
+
+		fmt.Fprintln(cw, "# HELP flow_client_queue_in_flight Calls in flight in client-side queues.")
+		fmt.Fprintln(cw, "# TYPE flow_client_queue_in_flight gauge")
+		for _, name := range queues {
+			fmt.Fprintf(cw, "flow_client_queue_in_flight{queue=%q} %d\n", name, m.queues[name].InFlight)
+		}
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+
+	return cw.n, cw.w.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written to a buffered writer, and keeps the
+// first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
@@ -0,0 +1,122 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	metrics := client.NewMetrics(10*time.Millisecond, time.Second)
+
+	var calls []client.CallMetrics
+	recorder := client.MetricsRecorderFunc(func(ctx context.Context, call client.CallMetrics) {
+		calls = append(calls, call)
+		metrics.RecordCall(ctx, call)
+	})
+
+	node := &scriptNode{failures: 2, err: status.Error(codes.Unavailable, "unavailable")}
+	invoker := client.Chain(node, client.MetricsMiddleware(recorder))
+	c := client.NewFromEndpoint(client.NewEndpoint("node", invoker)).WithRetry(3, time.Millisecond)
+
+	_, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+	require.NoError(t, err)
+
+	require.Len(t, calls, 3)
+	assert.Equal(t, client.MethodExecuteScriptAtLatestBlock, calls[0].Method)
+	assert.Equal(t, codes.Unavailable, calls[0].Code)
+	assert.False(t, calls[0].Retry)
+	assert.Equal(t, codes.Unavailable, calls[1].Code)
+	assert.True(t, calls[1].Retry)
+	assert.Equal(t, codes.OK, calls[2].Code)
+	assert.True(t, calls[2].Retry)
+
+	metrics.RecordCall(ctx, client.CallMetrics{
+		Method:   client.MethodPing,
+		Duration: 2 * time.Second,
+		Code:     codes.DeadlineExceeded,
+	})
+
+	metrics.RecordQueue(ctx, client.QueueMetrics{Queue: client.QueueRateLimiter, Waiting: 3})
+	metrics.RecordQueue(ctx, client.QueueMetrics{Queue: client.QueueConnPool, InFlight: 5})
+
+	var b bytes.Buffer
+	n, err := metrics.WriteTo(&b)
+	require.NoError(t, err)
+	assert.EqualValues(t, b.Len(), n)
+
+	output := b.String()
+	for _, line := range []string{
+		`flow_client_calls_total{method="ExecuteScriptAtLatestBlock",code="OK"} 1`,
+		`flow_client_calls_total{method="ExecuteScriptAtLatestBlock",code="Unavailable"} 2`,
+		`flow_client_calls_total{method="Ping",code="DeadlineExceeded"} 1`,
+		`flow_client_retries_total{method="ExecuteScriptAtLatestBlock"} 2`,
+		`flow_client_retries_total{method="Ping"} 0`,
+		`flow_client_call_duration_seconds_bucket{method="ExecuteScriptAtLatestBlock",le="0.01"} 3`,
+		`flow_client_call_duration_seconds_bucket{method="Ping",le="1"} 0`,
+		`flow_client_call_duration_seconds_bucket{method="Ping",le="+Inf"} 1`,
+		`flow_client_call_duration_seconds_sum{method="Ping"} 2`,
+		`flow_client_call_duration_seconds_count{method="Ping"} 1`,
+		`flow_client_queue_waiting{queue="rate_limiter"} 3`,
+		`flow_client_queue_in_flight{queue="conn_pool"} 5`,
+	} {
+		assert.Contains(t, output, line+"\n")
+	}
+
+	res := httptest.NewRecorder()
+	metrics.ServeHTTP(res, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, output, res.Body.String())
+}
+
+func TestNewClient_Metrics(t *testing.T) {
+	_, addr, stop := startPingServer(t)
+	defer stop()
+
+	metrics := client.NewMetrics()
+
+	c, err := client.NewClient(addr, client.WithInsecure(), client.WithMetrics(metrics))
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	require.NoError(t, c.Ping(ctx))
+
+	_, err = c.GetLatestBlockHeader(ctx, true)
+	require.Error(t, err)
+
+	var b bytes.Buffer
+	_, err = metrics.WriteTo(&b)
+	require.NoError(t, err)
+
+	assert.Contains(t, b.String(), `flow_client_calls_total{method="Ping",code="OK"} 1`)
+	assert.Contains(t, b.String(), `flow_client_calls_total{method="GetLatestBlockHeader",code="Unimplemented"} 1`)
+}
@@ -0,0 +1,92 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// A Middleware decorates the invoker that sends the Access API calls of a client,
+// e.g. to add authentication headers, log requests, record metrics or retry calls.
+//
+// The middleware calls next to continue the call:
+//
+//	func authenticate(token string) client.Middleware {
+//		return func(next client.Invoker) client.Invoker {
+//			return client.InvokerFunc(func(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+//				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+//				return next.Invoke(ctx, method, args, reply, opts...)
+//			})
+//		}
+//	}
+type Middleware func(next Invoker) Invoker
+
+// Chain returns an invoker that sends calls through the given middleware, in order,
+// before they reach the invoker. The first middleware is the outermost one.
+//
+// Chain decorates the invokers of endpoints and routers:
+//
+//	c := client.NewFromEndpoint(client.NewEndpoint(addr, client.Chain(conn, logging, metrics)))
+func Chain(invoker Invoker, middleware ...Middleware) Invoker {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		invoker = middleware[i](invoker)
+	}
+	return invoker
+}
+
+// WithMiddleware returns a dial option that sends the calls of a client created
+// with New through the given middleware, in order. The first middleware is the
+// outermost one.
+//
+// The middleware is installed as gRPC unary interceptors, which run after the
+// interceptors given with earlier dial options.
+func WithMiddleware(middleware ...Middleware) grpc.DialOption {
+	interceptors := make([]grpc.UnaryClientInterceptor, len(middleware))
+	for i, m := range middleware {
+		interceptors[i] = m.interceptor()
+	}
+	return grpc.WithChainUnaryInterceptor(interceptors...)
+}
+
+// interceptor returns a gRPC unary interceptor that runs this middleware.
+func (m Middleware) interceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req interface{},
+		reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		next := InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			return invoker(ctx, method, args, reply, cc, opts...)
+		})
+
+		return m(next).Invoke(ctx, method, req, reply, opts...)
+	}
+}
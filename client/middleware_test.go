@@ -0,0 +1,104 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// traceMiddleware records the methods it sees under its name, and adds its name
+// to the outgoing metadata.
+type traceMiddleware struct {
+	mu    sync.Mutex
+	trace []string
+}
+
+func (m *traceMiddleware) named(name string) client.Middleware {
+	return func(next client.Invoker) client.Invoker {
+		return client.InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			m.mu.Lock()
+			m.trace = append(m.trace, name+" "+method)
+			m.mu.Unlock()
+
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-middleware", name)
+			return next.Invoke(ctx, method, args, reply, opts...)
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Chain", func(t *testing.T) {
+		m := &traceMiddleware{}
+
+		var headers []string
+		node := client.InvokerFunc(func(
+			ctx context.Context,
+			_ string,
+			_ interface{},
+			_ interface{},
+			_ ...grpc.CallOption,
+		) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			headers = md.Get("x-middleware")
+			return nil
+		})
+
+		invoker := client.Chain(node, m.named("outer"), m.named("inner"))
+		c := client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+
+		require.NoError(t, c.Ping(ctx))
+
+		assert.Equal(t, []string{"outer " + client.MethodPing, "inner " + client.MethodPing}, m.trace)
+		assert.Equal(t, []string{"outer", "inner"}, headers)
+	})
+
+	t.Run("Dial option", func(t *testing.T) {
+		server, addr, stop := startPingServer(t)
+		defer stop()
+
+		m := &traceMiddleware{}
+
+		c, err := client.New(addr, grpc.WithInsecure(), client.WithMiddleware(m.named("outer"), m.named("inner")))
+		require.NoError(t, err)
+		defer c.Close()
+
+		require.NoError(t, c.Ping(ctx))
+
+		assert.Equal(t, []string{"outer " + client.MethodPing, "inner " + client.MethodPing}, m.trace)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&server.pings))
+	})
+}
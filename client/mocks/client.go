@@ -0,0 +1,710 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package mocks provides an in-memory implementation of flow.Client for unit tests.
+//
+// A mock client is seeded with the accounts, blocks, transactions and events that
+// the code under test reads, and records the transactions it sends:
+//
+//	c := mocks.New()
+//	c.AddAccount(account)
+//	c.AddNextBlock()
+//
+//	err := app.Transfer(ctx, c, account.Address, amount)
+//
+//	sent := c.SentTransactions()
+//
+// Entities that are not seeded are reported with the same errors as an Access node
+// that does not know them, i.e. client.RPCError values with a NotFound status.
+package mocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/onflow/cadence"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// ErrClosed is returned by the methods of a closed client.
+var ErrClosed = errors.New("mocks: client is closed")
+
+// ErrNoScriptHandler is returned when a script is executed by a client without a
+// script handler.
+var ErrNoScriptHandler = errors.New("mocks: no script handler")
+
+// A ScriptHandler executes a script at the given block height.
+type ScriptHandler func(ctx context.Context, height uint64, script []byte, arguments []cadence.Value) (cadence.Value, error)
+
+// Client is an in-memory flow.Client. It is safe for concurrent use.
+//
+// The zero value is not usable: clients are created with New.
+type Client struct {
+	mu sync.RWMutex
+
+	chainID      flow.ChainID
+	blocks       map[uint64]*flow.Block
+	blockIDs     map[flow.Identifier]uint64
+	latest       uint64
+	sealed       uint64
+	sealedSet    bool
+	collections  map[flow.Identifier]*flow.Collection
+	transactions map[flow.Identifier]*flow.Transaction
+	results      map[flow.Identifier]*flow.TransactionResult
+	accounts     map[flow.Address]*flow.Account
+	history      map[flow.Address][]accountVersion
+	events       map[uint64][]flow.Event
+	sent         []flow.Transaction
+	scripts      ScriptHandler
+	errs         map[string]error
+	closed       bool
+}
+
+var _ flow.Client = (*Client)(nil)
+
+// New returns an empty client for the emulator chain.
+func New() *Client {
+	return &Client{
+		chainID:      flow.Emulator,
+		blocks:       make(map[uint64]*flow.Block),
+		blockIDs:     make(map[flow.Identifier]uint64),
+		collections:  make(map[flow.Identifier]*flow.Collection),
+		transactions: make(map[flow.Identifier]*flow.Transaction),
+		results:      make(map[flow.Identifier]*flow.TransactionResult),
+		accounts:     make(map[flow.Address]*flow.Account),
+		history:      make(map[flow.Address][]accountVersion),
+		events:       make(map[uint64][]flow.Event),
+		errs:         make(map[string]error),
+	}
+}
+
+// SetChainID sets the chain ID returned by GetNetworkParameters.
+func (c *Client) SetChainID(chainID flow.ChainID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chainID = chainID
+}
+
+// AddAccount adds or replaces an account.
+func (c *Client) AddAccount(account *flow.Account) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accounts[account.Address] = account
+}
+
+// accountVersion is the state of an account from a block height on.
+type accountVersion struct {
+	height  uint64
+	account *flow.Account
+}
+
+// AddAccountAtHeight adds the state of an account from the given block height on,
+// returned by GetAccountAtBlockHeight for that height and the following ones, until
+// the height of a later state. The account is not found at heights before its first
+// state.
+//
+// The state with the greatest height is also the latest state of the account.
+// Accounts added only with AddAccount are returned at all heights.
+func (c *Client) AddAccountAtHeight(account *flow.Account, height uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	versions := c.history[account.Address]
+
+	i := len(versions)
+	for i > 0 && versions[i-1].height >= height {
+		i--
+	}
+
+	if i < len(versions) && versions[i].height == height {
+		versions[i].account = account
+	} else {
+		versions = append(versions, accountVersion{})
+		copy(versions[i+1:], versions[i:])
+		versions[i] = accountVersion{height: height, account: account}
+	}
+
+	c.history[account.Address] = versions
+	c.accounts[account.Address] = versions[len(versions)-1].account
+}
+
+// AddBlock adds or replaces a block. The block with the greatest height is the
+// latest block.
+func (c *Client) AddBlock(block *flow.Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addBlock(block)
+}
+
+func (c *Client) addBlock(block *flow.Block) {
+	if previous, ok := c.blocks[block.Height]; ok {
+		delete(c.blockIDs, previous.ID)
+	}
+
+	c.blocks[block.Height] = block
+	c.blockIDs[block.ID] = block.Height
+
+	if block.Height > c.latest || len(c.blocks) == 1 {
+		c.latest = block.Height
+	}
+}
+
+// AddNextBlock adds an empty block on top of the latest block, and returns it.
+//
+// The first block has height 0. Block IDs are derived from heights, and timestamps
+// are one second apart.
+func (c *Client) AddNextBlock() *flow.Block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	block := &flow.Block{}
+	if parent, ok := c.blocks[c.latest]; ok {
+		block.ParentID = parent.ID
+		block.Height = parent.Height + 1
+		block.Timestamp = parent.Timestamp.Add(time.Second)
+	} else {
+		block.Timestamp = time.Unix(1600000000, 0).UTC()
+	}
+
+	var height [8]byte
+	binary.BigEndian.PutUint64(height[:], block.Height)
+	block.ID = flow.Identifier(sha256.Sum256(height[:]))
+
+	c.addBlock(block)
+
+	return block
+}
+
+// SetSealedHeight sets the height of the latest sealed block. Blocks above it are
+// only returned by requests for unsealed blocks.
+//
+// By default, all blocks are sealed.
+func (c *Client) SetSealedHeight(height uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sealed = height
+	c.sealedSet = true
+}
+
+// AddCollection adds or replaces a collection.
+func (c *Client) AddCollection(collection *flow.Collection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.collections[collection.ID()] = collection
+}
+
+// AddTransaction adds or replaces a transaction with the given result.
+func (c *Client) AddTransaction(tx *flow.Transaction, result *flow.TransactionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transactions[tx.ID()] = tx
+	c.results[tx.ID()] = result
+}
+
+// SetTransactionResult sets the result of a transaction, e.g. to seal a sent
+// transaction.
+func (c *Client) SetTransactionResult(txID flow.Identifier, result *flow.TransactionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[txID] = result
+}
+
+// AddEvents adds events emitted in the block at the given height.
+func (c *Client) AddEvents(height uint64, events ...flow.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events[height] = append(c.events[height], events...)
+}
+
+// HandleScripts sets the handler that executes scripts. Scripts fail if no
+// handler is set.
+func (c *Client) HandleScripts(handler ScriptHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scripts = handler
+}
+
+// SetError makes calls to the given method, one of the client.Method constants,
+// fail with err. A nil error clears it.
+func (c *Client) SetError(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		delete(c.errs, method)
+		return
+	}
+
+	c.errs[method] = err
+}
+
+// SentTransactions returns the transactions sent with SendTransaction, in order.
+func (c *Client) SentTransactions() []flow.Transaction {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]flow.Transaction(nil), c.sent...)
+}
+
+func notFound(format string, args ...interface{}) error {
+	return client.RPCError{GRPCErr: status.Errorf(codes.NotFound, format, args...)}
+}
+
+// check returns the error of a call to method.
+func (c *Client) check(ctx context.Context, method string) error {
+	if c.closed {
+		return ErrClosed
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.errs[method]
+}
+
+func (c *Client) latestHeight(isSealed bool) uint64 {
+	if isSealed && c.sealedSet && c.sealed < c.latest {
+		return c.sealed
+	}
+
+	return c.latest
+}
+
+func (c *Client) block(height uint64) (*flow.Block, error) {
+	block, ok := c.blocks[height]
+	if !ok {
+		return nil, notFound("block at height %d not found", height)
+	}
+
+	return block, nil
+}
+
+func (c *Client) blockByID(blockID flow.Identifier) (*flow.Block, error) {
+	height, ok := c.blockIDs[blockID]
+	if !ok {
+		return nil, notFound("block %s not found", blockID)
+	}
+
+	return c.blocks[height], nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.check(ctx, client.MethodPing)
+}
+
+func (c *Client) GetLatestBlockHeader(ctx context.Context, isSealed bool) (*flow.BlockHeader, error) {
+	block, err := c.getLatestBlock(ctx, client.MethodGetLatestBlockHeader, isSealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block.BlockHeader, nil
+}
+
+func (c *Client) GetBlockHeaderByID(ctx context.Context, blockID flow.Identifier) (*flow.BlockHeader, error) {
+	block, err := c.getBlockByID(ctx, client.MethodGetBlockHeaderByID, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block.BlockHeader, nil
+}
+
+func (c *Client) GetBlockHeaderByHeight(ctx context.Context, height uint64) (*flow.BlockHeader, error) {
+	block, err := c.getBlockByHeight(ctx, client.MethodGetBlockHeaderByHeight, height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &block.BlockHeader, nil
+}
+
+func (c *Client) GetLatestBlock(ctx context.Context, isSealed bool) (*flow.Block, error) {
+	return c.getLatestBlock(ctx, client.MethodGetLatestBlock, isSealed)
+}
+
+func (c *Client) GetBlockByID(ctx context.Context, blockID flow.Identifier) (*flow.Block, error) {
+	return c.getBlockByID(ctx, client.MethodGetBlockByID, blockID)
+}
+
+func (c *Client) GetBlockByHeight(ctx context.Context, height uint64) (*flow.Block, error) {
+	return c.getBlockByHeight(ctx, client.MethodGetBlockByHeight, height)
+}
+
+func (c *Client) getLatestBlock(ctx context.Context, method string, isSealed bool) (*flow.Block, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return c.block(c.latestHeight(isSealed))
+}
+
+func (c *Client) getBlockByID(ctx context.Context, method string, blockID flow.Identifier) (*flow.Block, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return c.blockByID(blockID)
+}
+
+func (c *Client) getBlockByHeight(ctx context.Context, method string, height uint64) (*flow.Block, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return c.block(height)
+}
+
+func (c *Client) GetCollection(ctx context.Context, colID flow.Identifier) (*flow.Collection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetCollectionByID); err != nil {
+		return nil, err
+	}
+
+	collection, ok := c.collections[colID]
+	if !ok {
+		return nil, notFound("collection %s not found", colID)
+	}
+
+	return collection, nil
+}
+
+// GetFullCollection returns a collection with the transactions added with
+// AddTransaction or sent.
+func (c *Client) GetFullCollection(ctx context.Context, colID flow.Identifier) (*flow.FullCollection, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetCollectionByID); err != nil {
+		return nil, err
+	}
+
+	collection, ok := c.collections[colID]
+	if !ok {
+		return nil, notFound("collection %s not found", colID)
+	}
+
+	transactions := make([]*flow.Transaction, len(collection.TransactionIDs))
+	for i, txID := range collection.TransactionIDs {
+		if err := c.check(ctx, client.MethodGetTransaction); err != nil {
+			return nil, err
+		}
+
+		tx, ok := c.transactions[txID]
+		if !ok {
+			return nil, notFound("transaction %s not found", txID)
+		}
+		transactions[i] = tx
+	}
+
+	return &flow.FullCollection{Transactions: transactions}, nil
+}
+
+// SendTransaction records the transaction. Its result is pending until it is set
+// with SetTransactionResult.
+func (c *Client) SendTransaction(ctx context.Context, tx flow.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.check(ctx, client.MethodSendTransaction); err != nil {
+		return err
+	}
+
+	c.sent = append(c.sent, tx)
+
+	id := tx.ID()
+	c.transactions[id] = &tx
+	if _, ok := c.results[id]; !ok {
+		c.results[id] = &flow.TransactionResult{Status: flow.TransactionStatusPending}
+	}
+
+	return nil
+}
+
+func (c *Client) GetTransaction(ctx context.Context, txID flow.Identifier) (*flow.Transaction, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetTransaction); err != nil {
+		return nil, err
+	}
+
+	tx, ok := c.transactions[txID]
+	if !ok {
+		return nil, notFound("transaction %s not found", txID)
+	}
+
+	return tx, nil
+}
+
+func (c *Client) GetTransactionResult(ctx context.Context, txID flow.Identifier) (*flow.TransactionResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetTransactionResult); err != nil {
+		return nil, err
+	}
+
+	result, ok := c.results[txID]
+	if !ok {
+		return nil, notFound("transaction %s not found", txID)
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetAccount(ctx context.Context, address flow.Address) (*flow.Account, error) {
+	return c.getAccount(ctx, client.MethodGetAccount, address)
+}
+
+func (c *Client) GetAccountAtLatestBlock(ctx context.Context, address flow.Address) (*flow.Account, error) {
+	return c.getAccount(ctx, client.MethodGetAccountAtLatestBlock, address)
+}
+
+func (c *Client) GetAccountAtBlockHeight(
+	ctx context.Context,
+	address flow.Address,
+	height uint64,
+) (*flow.Account, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetAccountAtBlockHeight); err != nil {
+		return nil, err
+	}
+
+	versions := c.history[address]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].height <= height {
+			return versions[i].account, nil
+		}
+	}
+
+	if len(versions) > 0 {
+		return nil, notFound("account %s not found at height %d", address, height)
+	}
+
+	account, ok := c.accounts[address]
+	if !ok {
+		return nil, notFound("account %s not found", address)
+	}
+
+	return account, nil
+}
+
+func (c *Client) getAccount(ctx context.Context, method string, address flow.Address) (*flow.Account, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, method); err != nil {
+		return nil, err
+	}
+
+	account, ok := c.accounts[address]
+	if !ok {
+		return nil, notFound("account %s not found", address)
+	}
+
+	return account, nil
+}
+
+func (c *Client) ExecuteScriptAtLatestBlock(
+	ctx context.Context,
+	script []byte,
+	arguments []cadence.Value,
+) (cadence.Value, error) {
+	c.mu.RLock()
+	err := c.check(ctx, client.MethodExecuteScriptAtLatestBlock)
+	height := c.latestHeight(true)
+	c.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.executeScript(ctx, height, script, arguments)
+}
+
+func (c *Client) ExecuteScriptAtBlockID(
+	ctx context.Context,
+	blockID flow.Identifier,
+	script []byte,
+	arguments []cadence.Value,
+) (cadence.Value, error) {
+	c.mu.RLock()
+	err := c.check(ctx, client.MethodExecuteScriptAtBlockID)
+	var block *flow.Block
+	if err == nil {
+		block, err = c.blockByID(blockID)
+	}
+	c.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.executeScript(ctx, block.Height, script, arguments)
+}
+
+func (c *Client) ExecuteScriptAtBlockHeight(
+	ctx context.Context,
+	height uint64,
+	script []byte,
+	arguments []cadence.Value,
+) (cadence.Value, error) {
+	c.mu.RLock()
+	err := c.check(ctx, client.MethodExecuteScriptAtBlockHeight)
+	c.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.executeScript(ctx, height, script, arguments)
+}
+
+// executeScript calls the script handler without holding the lock, so that
+// handlers can seed the client.
+func (c *Client) executeScript(
+	ctx context.Context,
+	height uint64,
+	script []byte,
+	arguments []cadence.Value,
+) (cadence.Value, error) {
+	c.mu.RLock()
+	handler := c.scripts
+	c.mu.RUnlock()
+
+	if handler == nil {
+		return nil, ErrNoScriptHandler
+	}
+
+	return handler(ctx, height, script, arguments)
+}
+
+func (c *Client) GetEventsForHeightRange(ctx context.Context, query client.EventRangeQuery) ([]client.BlockEvents, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetEventsForHeightRange); err != nil {
+		return nil, err
+	}
+
+	if query.EndHeight < query.StartHeight {
+		return nil, client.RPCError{
+			GRPCErr: status.Errorf(codes.InvalidArgument, "start height %d is greater than end height %d", query.StartHeight, query.EndHeight),
+		}
+	}
+
+	if query.EndHeight > c.latestHeight(true) {
+		return nil, notFound("block at height %d is not sealed", query.EndHeight)
+	}
+
+	var results []client.BlockEvents
+	for height := query.StartHeight; height <= query.EndHeight; height++ {
+		block, err := c.block(height)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, c.blockEvents(block, query.Type))
+	}
+
+	return results, nil
+}
+
+func (c *Client) GetEventsForBlockIDs(
+	ctx context.Context,
+	eventType string,
+	blockIDs []flow.Identifier,
+) ([]client.BlockEvents, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetEventsForBlockIDs); err != nil {
+		return nil, err
+	}
+
+	results := make([]client.BlockEvents, 0, len(blockIDs))
+	for _, blockID := range blockIDs {
+		block, err := c.blockByID(blockID)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, c.blockEvents(block, eventType))
+	}
+
+	return results, nil
+}
+
+func (c *Client) blockEvents(block *flow.Block, eventType string) client.BlockEvents {
+	result := client.BlockEvents{
+		BlockID:        block.ID,
+		Height:         block.Height,
+		BlockTimestamp: block.Timestamp,
+	}
+
+	for _, event := range c.events[block.Height] {
+		if eventType == "" || event.Type == eventType {
+			result.Events = append(result.Events, event)
+		}
+	}
+
+	return result
+}
+
+func (c *Client) GetNetworkParameters(ctx context.Context) (flow.ChainID, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.check(ctx, client.MethodGetNetworkParameters); err != nil {
+		return "", err
+	}
+
+	return c.chainID, nil
+}
+
+// Close closes the client. Later calls fail with ErrClosed.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mocks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/mocks"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Blocks", func(t *testing.T) {
+		c := mocks.New()
+
+		first := c.AddNextBlock()
+		second := c.AddNextBlock()
+		c.AddNextBlock()
+		c.SetSealedHeight(1)
+
+		assert.Equal(t, first.ID, second.ParentID)
+
+		sealed, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), sealed.Height)
+
+		finalized, err := c.GetLatestBlock(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), finalized.Height)
+
+		block, err := c.GetBlockByID(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, second, block)
+
+		_, err = c.GetBlockByHeight(ctx, 3)
+		assert.Equal(t, errcode.RPCNotFound, errcode.Of(err))
+	})
+
+	t.Run("Accounts", func(t *testing.T) {
+		c := mocks.New()
+
+		account := test.AccountGenerator().New()
+		c.AddAccount(account)
+
+		result, err := c.GetAccount(ctx, account.Address)
+		require.NoError(t, err)
+		assert.Equal(t, account, result)
+
+		_, err = c.GetAccountAtLatestBlock(ctx, flow.HexToAddress("02"))
+		assert.Equal(t, errcode.RPCNotFound, errcode.Of(err))
+
+		result, err = c.GetAccountAtBlockHeight(ctx, account.Address, 7)
+		require.NoError(t, err)
+		assert.Equal(t, account, result)
+	})
+
+	t.Run("Account history", func(t *testing.T) {
+		c := mocks.New()
+
+		accounts := test.AccountGenerator()
+		before := accounts.New()
+		after := *before
+		after.Balance = before.Balance + 100
+
+		c.AddAccountAtHeight(&after, 20)
+		c.AddAccountAtHeight(before, 10)
+
+		_, err := c.GetAccountAtBlockHeight(ctx, before.Address, 9)
+		assert.Equal(t, errcode.RPCNotFound, errcode.Of(err))
+
+		for height, expected := range map[uint64]*flow.Account{10: before, 19: before, 20: &after, 100: &after} {
+			result, err := c.GetAccountAtBlockHeight(ctx, before.Address, height)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		}
+
+		latest, err := c.GetAccount(ctx, before.Address)
+		require.NoError(t, err)
+		assert.Equal(t, &after, latest)
+	})
+
+	t.Run("Transactions", func(t *testing.T) {
+		c := mocks.New()
+
+		tx := test.TransactionGenerator().New()
+		require.NoError(t, c.SendTransaction(ctx, *tx))
+		assert.Equal(t, []flow.Transaction{*tx}, c.SentTransactions())
+
+		result, err := c.GetTransactionResult(ctx, tx.ID())
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusPending, result.Status)
+
+		c.SetTransactionResult(tx.ID(), &flow.TransactionResult{Status: flow.TransactionStatusSealed})
+
+		result, err = c.GetTransactionResult(ctx, tx.ID())
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+	})
+
+	t.Run("Collections", func(t *testing.T) {
+		c := mocks.New()
+
+		transactions := test.TransactionGenerator()
+		first := transactions.New()
+		second := transactions.New()
+		c.AddTransaction(first, &flow.TransactionResult{Status: flow.TransactionStatusSealed})
+		c.AddTransaction(second, &flow.TransactionResult{Status: flow.TransactionStatusSealed})
+
+		collection := &flow.Collection{TransactionIDs: []flow.Identifier{second.ID(), first.ID()}}
+		c.AddCollection(collection)
+
+		full, err := c.GetFullCollection(ctx, collection.ID())
+		require.NoError(t, err)
+		assert.Equal(t, []*flow.Transaction{second, first}, full.Transactions)
+		assert.Equal(t, collection.ID(), full.ID())
+
+		missing := &flow.Collection{TransactionIDs: []flow.Identifier{test.IdentifierGenerator().New()}}
+		c.AddCollection(missing)
+
+		_, err = c.GetFullCollection(ctx, missing.ID())
+		assert.Equal(t, errcode.RPCNotFound, errcode.Of(err))
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		c := mocks.New()
+
+		events := test.EventGenerator()
+		first := events.New()
+		second := events.New()
+		second.Type = "A.0000000000000001.Test.Other"
+
+		c.AddNextBlock()
+		block := c.AddNextBlock()
+		c.AddEvents(block.Height, first, second)
+
+		results, err := c.GetEventsForHeightRange(ctx, client.EventRangeQuery{
+			Type:        first.Type,
+			StartHeight: 0,
+			EndHeight:   1,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, results, 2)
+		assert.Empty(t, results[0].Events)
+		assert.Equal(t, []flow.Event{first}, results[1].Events)
+
+		_, err = c.GetEventsForHeightRange(ctx, client.EventRangeQuery{StartHeight: 0, EndHeight: 2})
+		assert.Equal(t, errcode.RPCNotFound, errcode.Of(err))
+	})
+
+	t.Run("Scripts", func(t *testing.T) {
+		c := mocks.New()
+		c.AddNextBlock()
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		assert.Equal(t, mocks.ErrNoScriptHandler, err)
+
+		c.HandleScripts(func(_ context.Context, height uint64, _ []byte, _ []cadence.Value) (cadence.Value, error) {
+			return cadence.NewUInt64(height), nil
+		})
+
+		value, err := c.ExecuteScriptAtBlockHeight(ctx, 7, []byte("script"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewUInt64(7), value)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		c := mocks.New()
+
+		failure := errors.New("unavailable")
+		c.SetError(client.MethodPing, failure)
+		assert.Equal(t, failure, c.Ping(ctx))
+
+		c.SetError(client.MethodPing, nil)
+		assert.NoError(t, c.Ping(ctx))
+
+		require.NoError(t, c.Close())
+		assert.Equal(t, mocks.ErrClosed, c.Ping(ctx))
+	})
+}
@@ -0,0 +1,496 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// An Option configures a client created with NewClient.
+type Option func(*options)
+
+// options are the settings of a client created with NewClient.
+type options struct {
+	insecure    bool
+	tls         *tls.Config
+	timeout     time.Duration
+	connections int
+	maxInFlight int
+	reconnect   *ReconnectConfig
+	callTimeout time.Duration
+	limiter     *RateLimiter
+	breaker     *CircuitBreaker
+	metrics     MetricsRecorder
+	replay      *ReplayRegistry
+	latestTTL   time.Duration
+	warmUp      bool
+	chainID     flow.ChainID
+	callOptions []grpc.CallOption
+	dialOptions []grpc.DialOption
+	err         error
+}
+
+// tlsConfig returns the TLS configuration to update with a TLS option.
+func (o *options) tlsConfig() *tls.Config {
+	o.insecure = false
+	if o.tls == nil {
+		o.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return o.tls
+}
+
+// WithTLS connects to the Access API over TLS with the given configuration. A nil
+// configuration verifies the server certificate against the system roots.
+//
+// Options given after WithTLS, e.g. WithRootCAs, update a copy of the configuration.
+func WithTLS(config *tls.Config) Option {
+	return func(o *options) {
+		o.insecure = false
+		o.tls = nil
+		if config != nil {
+			o.tls = config.Clone()
+		}
+	}
+}
+
+// WithRootCAs connects to the Access API over TLS, and verifies the server
+// certificate against the given PEM-encoded CA certificates instead of the system
+// roots, e.g. for Access nodes with certificates issued by a private CA.
+func WithRootCAs(pemCerts ...[]byte) Option {
+	return func(o *options) {
+		pool := x509.NewCertPool()
+		for _, pemCert := range pemCerts {
+			if !pool.AppendCertsFromPEM(pemCert) {
+				o.err = errors.New("client: invalid root CA certificate")
+				return
+			}
+		}
+
+		o.tlsConfig().RootCAs = pool
+	}
+}
+
+// WithClientCertificate connects to the Access API over mutual TLS, and presents
+// the given PEM-encoded certificate chain and private key to the server.
+func WithClientCertificate(certPEM, keyPEM []byte) Option {
+	return func(o *options) {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			o.err = fmt.Errorf("client: invalid client certificate: %w", err)
+			return
+		}
+
+		config := o.tlsConfig()
+		config.Certificates = append(config.Certificates, cert)
+	}
+}
+
+// WithServerName connects to the Access API over TLS, and verifies that the server
+// certificate is valid for the given host name instead of the host of the address,
+// e.g. when Access nodes are dialed by IP address.
+func WithServerName(serverName string) Option {
+	return func(o *options) {
+		o.tlsConfig().ServerName = serverName
+	}
+}
+
+// WithInsecure connects to the Access API without transport security, e.g. to a
+// local emulator.
+func WithInsecure() Option {
+	return func(o *options) {
+		o.insecure = true
+		o.tls = nil
+	}
+}
+
+// WithTimeout makes NewClient wait until the connection to the Access API is
+// established, and fail if it is not established within the timeout.
+//
+// By default, NewClient returns immediately and connects in the background.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithCallTimeout bounds each call of the client to the given timeout, unless the
+// context of the call already has a deadline. The timeout includes the time a call
+// waits for the rate limit of the client. Calls retried with Client.WithRetry are
+// bounded separately.
+//
+// Unlike WithTimeout, which bounds the time to establish the connection, the call
+// timeout applies to every Access API call. See DefaultCallTimeout.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.callTimeout = timeout
+	}
+}
+
+// DefaultStartupTimeout is the time NewClient waits for the connection to be
+// established and verified when WithWarmUp is given without WithTimeout.
+const DefaultStartupTimeout = 30 * time.Second
+
+// ErrChainMismatch is returned by NewClient when the Access node given with
+// WithWarmUp serves another network than expected.
+var ErrChainMismatch = errcode.New(errcode.ChainMismatch, "client: access node serves another chain")
+
+// WithWarmUp makes NewClient establish the connection to the Access API and verify
+// it before returning, so that a misconfigured or unreachable node fails a service
+// at startup rather than on its first call. The node is pinged, and if chainID is
+// not empty, NewClient fails with an error wrapping ErrChainMismatch unless the node
+// serves that chain.
+//
+// The connection and the checks must complete within the timeout given with
+// WithTimeout, or DefaultStartupTimeout.
+func WithWarmUp(chainID flow.ChainID) Option {
+	return func(o *options) {
+		o.warmUp = true
+		o.chainID = chainID
+	}
+}
+
+// Maximum message sizes in bytes for WithMaxMessageSize.
+const (
+	// DefaultMaxMessageSize is the size limit of received messages if
+	// WithMaxMessageSize is not given.
+	DefaultMaxMessageSize = 4 << 20
+	// LargeMaxMessageSize is a size limit for event-heavy workloads, e.g. indexers
+	// querying events over hundreds of blocks, or scripts returning large results.
+	LargeMaxMessageSize = 64 << 20
+)
+
+// WithMaxMessageSize sets the maximum size in bytes of the messages sent to and
+// received from the Access API, e.g. to receive large script results or event
+// query responses.
+//
+// Calls that send or receive a larger message fail with ErrMessageTooLarge. The
+// limit is DefaultMaxMessageSize by default; LargeMaxMessageSize suits most
+// event-heavy workloads. Larger limits let a single response use more memory, so
+// prefer querying events over smaller height ranges to raising the limit further.
+func WithMaxMessageSize(bytes int) Option {
+	return func(o *options) {
+		o.callOptions = append(o.callOptions, grpc.MaxCallRecvMsgSize(bytes), grpc.MaxCallSendMsgSize(bytes))
+	}
+}
+
+// WithCompression compresses the requests sent to and the responses received from
+// the Access API with gzip, e.g. to reduce the bandwidth used by large script
+// results and event query responses over WAN links.
+//
+// Compression costs CPU time on both ends, and is rarely worth it for small
+// messages or over local networks.
+func WithCompression() Option {
+	return func(o *options) {
+		o.callOptions = append(o.callOptions, grpc.UseCompressor(gzip.Name))
+	}
+}
+
+// WithConnections makes NewClient maintain the given number of connections to the
+// Access API, and spread calls across them, as NewPooled.
+//
+// A single connection suffices for most applications. Services that make thousands
+// of concurrent calls, e.g. script executions, scale further with a few connections.
+func WithConnections(connections int) Option {
+	return func(o *options) {
+		o.connections = connections
+	}
+}
+
+// WithMaxInFlight caps the number of calls in flight on each connection of the
+// client, as ConnPool.SetMaxInFlight. Calls over the cap fail right away with a
+// BackpressureError instead of queuing on the connection, so that services can shed
+// load. WithMaxInFlight cannot be combined with WithReconnect.
+func WithMaxInFlight(perConnection int) Option {
+	return func(o *options) {
+		o.maxInFlight = perConnection
+	}
+}
+
+// WithReconnect makes the client re-dial its connection when calls keep failing
+// because the Access API is unavailable, e.g. after the Access node restarted, as
+// NewReconnecting. WithReconnect cannot be combined with WithConnections or
+// WithMaxInFlight.
+func WithReconnect(config ReconnectConfig) Option {
+	return func(o *options) {
+		o.reconnect = &config
+	}
+}
+
+// WithRateLimit throttles the calls of the client to rps calls per second on
+// average, with bursts of up to burst calls, e.g. for applications sharing a public
+// Access node. Calls over the limit wait for their turn, within the bounds set with
+// WithRateLimitQueue.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(o *options) {
+		if o.limiter == nil {
+			o.limiter = NewRateLimiter(rps, burst)
+			return
+		}
+		o.limiter.limit = newRateBucket(rps, burst)
+	}
+}
+
+// WithMethodRateLimit throttles the calls to a method to their own rate, instead of
+// the rate set with WithRateLimit, e.g. to give script executions a separate budget:
+//
+//	c, err := client.NewClient(
+//		addr,
+//		client.WithRateLimit(10, 20),
+//		client.WithMethodRateLimit(client.MethodExecuteScriptAtLatestBlock, 50, 100),
+//	)
+func WithMethodRateLimit(method string, rps float64, burst int) Option {
+	return func(o *options) {
+		if o.limiter == nil {
+			o.limiter = NewRateLimiter(0, 0)
+		}
+		o.limiter.SetMethodLimit(method, rps, burst)
+	}
+}
+
+// WithRateLimitQueue bounds the calls waiting for the rate limit of the client, as
+// RateLimiter.SetQueueLimit. Calls that would wait longer than maxWait, or while
+// maxWaiters calls are already waiting, fail right away with a BackpressureError:
+//
+//	c, err := client.NewClient(
+//		addr,
+//		client.WithRateLimit(10, 20),
+//		client.WithRateLimitQueue(2*time.Second, 100),
+//	)
+func WithRateLimitQueue(maxWait time.Duration, maxWaiters int) Option {
+	return func(o *options) {
+		if o.limiter == nil {
+			o.limiter = NewRateLimiter(0, 0)
+		}
+		o.limiter.SetQueueLimit(maxWait, maxWaiters)
+	}
+}
+
+// WithCircuitBreaker stops the calls of the client after consecutive failures, so
+// that an unreachable Access node fails calls right away instead of holding each of
+// them until it times out. See CircuitBreaker.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(o *options) {
+		o.breaker = NewCircuitBreaker(config)
+	}
+}
+
+// WithMetrics records the Access API calls of the client with the recorder, e.g.
+// Metrics. Calls are recorded after they pass the rate limit of the client, so
+// latencies only include the time spent on the Access API.
+func WithMetrics(recorder MetricsRecorder) Option {
+	return func(o *options) {
+		o.metrics = recorder
+	}
+}
+
+// WithLatestBlockCache caches the latest block and block header returned by the
+// Access API for the given time, to reduce the cost of polling them. See
+// LatestBlockCache.
+func WithLatestBlockCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.latestTTL = ttl
+	}
+}
+
+// WithReplayProtection refuses to send transactions that the registry recorded
+// for another environment, e.g. a mainnet transaction sent with a testnet client.
+//
+//	store := client.NewFileReplayStore("/var/lib/payouts/replay.json")
+//
+//	c, err := client.NewClient(
+//		addr,
+//		client.WithReplayProtection(client.NewReplayRegistry("mainnet", 0, store)),
+//	)
+func WithReplayProtection(registry *ReplayRegistry) Option {
+	return func(o *options) {
+		o.replay = registry
+	}
+}
+
+// WithUserAgent sets the user agent sent to the Access API, to identify an
+// application in the logs of Access nodes.
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, grpc.WithUserAgent(userAgent))
+	}
+}
+
+// WithDialOptions passes gRPC dial options to the connection, e.g. the options
+// returned by WithLoadBalancing, WithResolvers or WithMiddleware.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// NewClient initializes a Flow client for the Access API at the given address,
+// configured with options:
+//
+//	c, err := client.NewClient(
+//		"access.mainnet.nodes.onflow.org:9000",
+//		client.WithTimeout(10*time.Second),
+//		client.WithUserAgent("my-app/1.0"),
+//	)
+//
+// The connection uses TLS, verifying the server certificate against the system
+// roots, unless WithInsecure is given. Mutual TLS is enabled with
+// WithClientCertificate:
+//
+//	c, err := client.NewClient(
+//		"access.internal:9000",
+//		client.WithRootCAs(caPEM),
+//		client.WithClientCertificate(certPEM, keyPEM),
+//	)
+//
+// NewClient is equivalent to New with the corresponding dial options.
+func NewClient(addr string, opts ...Option) (*Client, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	if o.reconnect != nil && o.connections > 1 {
+		return nil, errors.New("client: WithReconnect cannot be combined with WithConnections")
+	}
+	if o.reconnect != nil && o.maxInFlight > 0 {
+		return nil, errors.New("client: WithReconnect cannot be combined with WithMaxInFlight")
+	}
+
+	transport := grpc.WithInsecure()
+	if !o.insecure {
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(o.tlsConfig()))
+	}
+
+	dialOptions := append([]grpc.DialOption{transport}, o.dialOptions...)
+	if o.latestTTL > 0 {
+		dialOptions = append(dialOptions, WithMiddleware(LatestBlockCache(o.latestTTL)))
+	}
+	if o.callTimeout > 0 {
+		dialOptions = append(dialOptions, WithMiddleware(DefaultCallTimeout(o.callTimeout)))
+	}
+	if o.limiter != nil {
+		o.limiter.SetMetrics(o.metrics)
+		dialOptions = append(dialOptions, WithMiddleware(o.limiter.Middleware()))
+	}
+	if o.replay != nil {
+		dialOptions = append(dialOptions, WithMiddleware(o.replay.Middleware()))
+	}
+	if o.breaker != nil {
+		dialOptions = append(dialOptions, WithMiddleware(o.breaker.Middleware()))
+	}
+	if o.metrics != nil {
+		dialOptions = append(dialOptions, WithMiddleware(MetricsMiddleware(o.metrics)))
+	}
+	if len(o.callOptions) > 0 {
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(o.callOptions...))
+	}
+
+	if o.warmUp && o.timeout <= 0 {
+		o.timeout = DefaultStartupTimeout
+	}
+
+	ctx := context.Background()
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+
+		dialOptions = append(dialOptions, grpc.WithBlock())
+	}
+
+	var c *Client
+
+	if o.connections > 1 || o.maxInFlight > 0 {
+		size := o.connections
+		if size < 1 {
+			size = 1
+		}
+
+		pool, err := dialPool(ctx, addr, size, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		pool.SetMaxInFlight(o.maxInFlight)
+		pool.SetMetrics(o.metrics)
+
+		c = newFromPool(pool)
+	} else if o.reconnect != nil {
+		conn, err := dialReconnecting(ctx, addr, *o.reconnect, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		c = newFromReconnecting(conn)
+	} else {
+		conn, err := grpc.DialContext(ctx, addr, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		c = newFromConn(addr, conn)
+	}
+
+	if o.warmUp {
+		if err := c.warmUp(ctx, o.chainID); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// warmUp pings the Access node, and verifies that it serves the given chain, if any.
+func (c *Client) warmUp(ctx context.Context, chainID flow.ChainID) error {
+	if err := c.Ping(ctx); err != nil {
+		return fmt.Errorf("client: failed to ping access node: %w", err)
+	}
+
+	if chainID == "" {
+		return nil
+	}
+
+	served, err := c.GetNetworkParameters(ctx)
+	if err != nil {
+		return err
+	}
+
+	if served != chainID {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChainMismatch, chainID, served)
+	}
+
+	return nil
+}
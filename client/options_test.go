@@ -0,0 +1,391 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Options", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		userAgents := make(chan []string, 1)
+		server := grpc.NewServer(grpc.UnaryInterceptor(func(
+			ctx context.Context,
+			req interface{},
+			info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			userAgents <- md.Get("user-agent")
+			return handler(ctx, req)
+		}))
+		access.RegisterAccessAPIServer(server, &pingServer{})
+
+		go func() { _ = server.Serve(lis) }()
+		defer server.Stop()
+
+		c, err := client.NewClient(
+			lis.Addr().String(),
+			client.WithInsecure(),
+			client.WithTimeout(5*time.Second),
+			client.WithUserAgent("test-app/1.0"),
+			client.WithMaxMessageSize(16<<20),
+		)
+		require.NoError(t, err)
+		defer c.Close()
+
+		require.NoError(t, c.Ping(ctx))
+
+		agents := <-userAgents
+		require.Len(t, agents, 1)
+		assert.True(t, strings.HasPrefix(agents[0], "test-app/1.0"))
+	})
+
+	t.Run("Max message size", func(t *testing.T) {
+		_, addr, stop := startPingServer(t)
+		defer stop()
+
+		tx := flow.NewTransaction().SetScript(make([]byte, 4096))
+
+		c, err := client.NewClient(addr, client.WithInsecure(), client.WithMaxMessageSize(1024))
+		require.NoError(t, err)
+		defer c.Close()
+
+		err = c.SendTransaction(ctx, *tx)
+		assert.True(t, errors.Is(err, client.ErrMessageTooLarge))
+		assert.False(t, errors.Is(err, client.ErrBackpressure))
+		assert.Equal(t, errcode.RPCMessageTooLarge, errcode.Of(err))
+
+		c, err = client.NewClient(addr, client.WithInsecure(), client.WithMaxMessageSize(client.LargeMaxMessageSize))
+		require.NoError(t, err)
+		defer c.Close()
+
+		err = c.SendTransaction(ctx, *tx)
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		assert.False(t, errors.Is(err, client.ErrMessageTooLarge))
+	})
+
+	t.Run("Compression", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		serverStats := &compressionRecorder{}
+		server := grpc.NewServer(grpc.StatsHandler(serverStats))
+		access.RegisterAccessAPIServer(server, &pingServer{})
+
+		go func() { _ = server.Serve(lis) }()
+		defer server.Stop()
+
+		clientStats := &compressionRecorder{}
+		c, err := client.NewClient(
+			lis.Addr().String(),
+			client.WithInsecure(),
+			client.WithCompression(),
+			client.WithDialOptions(grpc.WithStatsHandler(clientStats)),
+		)
+		require.NoError(t, err)
+		defer c.Close()
+
+		require.NoError(t, c.Ping(ctx))
+
+		// the request and the response are compressed
+		assert.Equal(t, "gzip", serverStats.compression())
+		assert.Equal(t, "gzip", clientStats.compression())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := lis.Addr().String()
+		require.NoError(t, lis.Close())
+
+		_, err = client.NewClient(addr, client.WithInsecure(), client.WithTimeout(50*time.Millisecond))
+		assert.Error(t, err)
+	})
+
+	t.Run("Warm-up", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		server := grpc.NewServer()
+		node := &networkServer{chainID: flow.Testnet}
+		access.RegisterAccessAPIServer(server, node)
+
+		go func() { _ = server.Serve(lis) }()
+		defer server.Stop()
+
+		addr := lis.Addr().String()
+
+		c, err := client.NewClient(addr, client.WithInsecure(), client.WithWarmUp(flow.Testnet))
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+		assert.EqualValues(t, 1, atomic.LoadInt32(&node.pings))
+
+		_, err = client.NewClient(addr, client.WithInsecure(), client.WithWarmUp(flow.Mainnet))
+		assert.True(t, errors.Is(err, client.ErrChainMismatch))
+		assert.Equal(t, errcode.ChainMismatch, errcode.Of(err))
+
+		// without a chain ID, the node is only pinged
+		c, err = client.NewClient(addr, client.WithInsecure(), client.WithWarmUp(""))
+		require.NoError(t, err)
+		require.NoError(t, c.Close())
+
+		server.Stop()
+
+		start := time.Now()
+		_, err = client.NewClient(
+			addr,
+			client.WithInsecure(),
+			client.WithWarmUp(flow.Testnet),
+			client.WithTimeout(50*time.Millisecond),
+		)
+		assert.Error(t, err)
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+}
+
+// networkServer is an Access API server that serves a chain.
+type networkServer struct {
+	pingServer
+	chainID flow.ChainID
+}
+
+func (s *networkServer) GetNetworkParameters(
+	context.Context,
+	*access.GetNetworkParametersRequest,
+) (*access.GetNetworkParametersResponse, error) {
+	return &access.GetNetworkParametersResponse{ChainId: string(s.chainID)}, nil
+}
+
+// compressionRecorder is a stats handler that records the compression of the
+// received messages.
+type compressionRecorder struct {
+	mu          sync.Mutex
+	compressors []string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		r.mu.Lock()
+		r.compressors = append(r.compressors, header.Compression)
+		r.mu.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+// compression returns the compression of the last received header.
+func (r *compressionRecorder) compression() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.compressors) == 0 {
+		return ""
+	}
+	return r.compressors[len(r.compressors)-1]
+}
+
+// testCert is a certificate and its private key, PEM-encoded.
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+	keyPair tls.Certificate
+}
+
+// newTestCert returns a certificate for the template, signed by the parent, or
+// self-signed if the parent is nil.
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key, certPEM: certPEM, keyPEM: keyPEM, keyPair: keyPair}
+}
+
+func TestNewClient_TLS(t *testing.T) {
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	serverCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "access.test"},
+		DNSNames:    []string{"access.test"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "test-app"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert.keyPair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})))
+	access.RegisterAccessAPIServer(server, &pingServer{})
+
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	addr := lis.Addr().String()
+
+	ping := func(opts ...client.Option) error {
+		c, err := client.NewClient(addr, opts...)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		return c.Ping(ctx)
+	}
+
+	t.Run("Mutual TLS", func(t *testing.T) {
+		err := ping(
+			client.WithRootCAs(ca.certPEM),
+			client.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			client.WithServerName("access.test"),
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("With TLS config", func(t *testing.T) {
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		err := ping(
+			client.WithTLS(&tls.Config{RootCAs: roots}),
+			client.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			client.WithServerName("access.test"),
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("No client certificate", func(t *testing.T) {
+		err := ping(
+			client.WithRootCAs(ca.certPEM),
+			client.WithServerName("access.test"),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("Wrong server name", func(t *testing.T) {
+		err := ping(
+			client.WithRootCAs(ca.certPEM),
+			client.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			client.WithServerName("other.test"),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown CA", func(t *testing.T) {
+		err := ping(
+			client.WithClientCertificate(clientCert.certPEM, clientCert.keyPEM),
+			client.WithServerName("access.test"),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid certificates", func(t *testing.T) {
+		_, err := client.NewClient(addr, client.WithRootCAs([]byte("not a certificate")))
+		assert.Error(t, err)
+
+		_, err = client.NewClient(addr, client.WithClientCertificate(clientCert.certPEM, ca.keyPEM))
+		assert.Error(t, err)
+	})
+}
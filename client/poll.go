@@ -0,0 +1,369 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// PollConfig configures the polling interval of subscriptions.
+//
+// Subscriptions poll the latest sealed block header, and schedule the next poll at
+// the time the next block is expected to be sealed. The expected time is derived
+// from the block production rate observed in block timestamps. When a poll finds no
+// new block, the interval is doubled on every consecutive empty poll.
+type PollConfig struct {
+	// MinInterval is the shortest time between two polls while caught up.
+	//
+	// Defaults to 250 milliseconds.
+	MinInterval time.Duration
+	// MaxInterval is the longest time between two polls.
+	//
+	// Defaults to 10 seconds.
+	MaxInterval time.Duration
+	// MaxRange is the maximum number of blocks requested in a single event query.
+	//
+	// Defaults to 250 blocks, the limit enforced by Access nodes.
+	MaxRange uint64
+}
+
+const (
+	defaultMinPollInterval = 250 * time.Millisecond
+	defaultMaxPollInterval = 10 * time.Second
+	defaultMaxPollRange    = 250
+
+	// blockIntervalSmoothing is the weight given to a new block interval sample.
+	blockIntervalSmoothing = 0.2
+)
+
+func (c PollConfig) withDefaults() PollConfig {
+	if c.MinInterval == 0 {
+		c.MinInterval = defaultMinPollInterval
+	}
+
+	if c.MaxInterval == 0 {
+		c.MaxInterval = defaultMaxPollInterval
+	}
+
+	if c.MaxInterval < c.MinInterval {
+		c.MaxInterval = c.MinInterval
+	}
+
+	if c.MaxRange == 0 {
+		c.MaxRange = defaultMaxPollRange
+	}
+
+	return c
+}
+
+// pollClock schedules polls from the observed block production rate.
+type pollClock struct {
+	config PollConfig
+
+	// blockInterval is the smoothed time between two consecutive blocks.
+	blockInterval time.Duration
+
+	lastHeight    uint64
+	lastTimestamp time.Time
+	// lastSeen is the local time at which the last new block was observed.
+	lastSeen time.Time
+	// misses is the number of consecutive polls that found no new block.
+	misses uint
+}
+
+func newPollClock(config PollConfig) *pollClock {
+	return &pollClock{config: config}
+}
+
+// observe records the latest sealed block header returned by a poll at the given time.
+func (p *pollClock) observe(header *flow.BlockHeader, now time.Time) {
+	if header.Height <= p.lastHeight && !p.lastSeen.IsZero() {
+		p.misses++
+		return
+	}
+
+	if !p.lastTimestamp.IsZero() && header.Height > p.lastHeight && header.Timestamp.After(p.lastTimestamp) {
+		sample := header.Timestamp.Sub(p.lastTimestamp) / time.Duration(header.Height-p.lastHeight)
+
+		if p.blockInterval == 0 {
+			p.blockInterval = sample
+		} else {
+			p.blockInterval = time.Duration(
+				(1-blockIntervalSmoothing)*float64(p.blockInterval) + blockIntervalSmoothing*float64(sample),
+			)
+		}
+	}
+
+	p.lastHeight = header.Height
+	p.lastTimestamp = header.Timestamp
+	p.lastSeen = now
+	p.misses = 0
+}
+
+// next returns the time to wait before the next poll.
+func (p *pollClock) next(now time.Time) time.Duration {
+	var wait time.Duration
+
+	switch {
+	case p.misses > 0:
+		// the expected block is late: back off exponentially
+		wait = p.config.MinInterval
+		for i := uint(1); i < p.misses && wait < p.config.MaxInterval; i++ {
+			wait *= 2
+		}
+	case p.blockInterval > 0:
+		wait = p.lastSeen.Add(p.blockInterval).Sub(now)
+	default:
+		wait = p.config.MinInterval
+	}
+
+	if wait < p.config.MinInterval {
+		wait = p.config.MinInterval
+	}
+
+	if wait > p.config.MaxInterval {
+		wait = p.config.MaxInterval
+	}
+
+	return wait
+}
+
+// SubscribeEvents polls for events of the given type in sealed blocks, starting at
+// startHeight, and sends the events of each block on the returned channel in
+// height order. Blocks without events of the given type are also sent, so that
+// consumers can record their progress.
+//
+// Polling stops when the context is cancelled or a request fails. In both cases,
+// the error is sent on the error channel and both channels are closed.
+func (c *Client) SubscribeEvents(
+	ctx context.Context,
+	eventType string,
+	startHeight uint64,
+	config PollConfig,
+) (<-chan BlockEvents, <-chan error) {
+	return SubscribeEvents(ctx, c, eventType, startHeight, config)
+}
+
+// SubscribeEvents polls any flow.Client for events, see Client.SubscribeEvents.
+func SubscribeEvents(
+	ctx context.Context,
+	c flow.Client,
+	eventType string,
+	startHeight uint64,
+	config PollConfig,
+) (<-chan BlockEvents, <-chan error) {
+	config = config.withDefaults()
+
+	blocks := make(chan BlockEvents)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(blocks)
+		defer close(errs)
+
+		errs <- pollEvents(ctx, c, eventType, startHeight, config, blocks)
+	}()
+
+	return blocks, errs
+}
+
+func pollEvents(
+	ctx context.Context,
+	c flow.Client,
+	eventType string,
+	next uint64,
+	config PollConfig,
+	blocks chan<- BlockEvents,
+) error {
+	clock := newPollClock(config)
+
+	for {
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		if err != nil {
+			return err
+		}
+
+		clock.observe(header, time.Now())
+
+		for next <= header.Height {
+			end := next + config.MaxRange - 1
+			if end > header.Height {
+				end = header.Height
+			}
+
+			results, err := c.GetEventsForHeightRange(ctx, EventRangeQuery{
+				Type:        eventType,
+				StartHeight: next,
+				EndHeight:   end,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, result := range results {
+				select {
+				case blocks <- result:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			next = end + 1
+		}
+
+		if err := sleep(ctx, clock.next(time.Now())); err != nil {
+			return err
+		}
+	}
+}
+
+// SubscribeBlocks polls for new sealed, or finalized if isSealed is false, block
+// headers and sends them on the returned channel in height order, starting with the
+// latest block header. Headers of blocks produced between two polls are requested
+// by height, so that no block is skipped.
+//
+// Polling stops when the context is cancelled or a request fails. In both cases,
+// the error is sent on the error channel and both channels are closed.
+func (c *Client) SubscribeBlocks(
+	ctx context.Context,
+	isSealed bool,
+	config PollConfig,
+) (<-chan *flow.BlockHeader, <-chan error) {
+	config = config.withDefaults()
+
+	headers := make(chan *flow.BlockHeader)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(headers)
+		defer close(errs)
+
+		errs <- c.pollBlocks(ctx, isSealed, config, headers)
+	}()
+
+	return headers, errs
+}
+
+func (c *Client) pollBlocks(
+	ctx context.Context,
+	isSealed bool,
+	config PollConfig,
+	headers chan<- *flow.BlockHeader,
+) error {
+	clock := newPollClock(config)
+
+	var next uint64
+	started := false
+
+	for {
+		latest, err := c.GetLatestBlockHeader(ctx, isSealed)
+		if err != nil {
+			return err
+		}
+
+		clock.observe(latest, time.Now())
+
+		if !started {
+			next = latest.Height
+			started = true
+		}
+
+		for ; next <= latest.Height; next++ {
+			header := latest
+			if next < latest.Height {
+				header, err = c.GetBlockHeaderByHeight(ctx, next)
+				if err != nil {
+					return err
+				}
+			}
+
+			select {
+			case headers <- header:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err := sleep(ctx, clock.next(time.Now())); err != nil {
+			return err
+		}
+	}
+}
+
+// SubscribeTransactionStatus polls for the result of a transaction and sends it on
+// the returned channel each time the transaction status changes, until the
+// transaction is sealed or expired. Statuses that change between two polls are not
+// sent, e.g. a transaction may go from pending to sealed.
+//
+// The interval between two polls starts at MinInterval, and is doubled up to
+// MaxInterval on every consecutive poll that finds no status change.
+//
+// Once the transaction is sealed or expired, both channels are closed. Polling stops
+// early when the context is cancelled or a request fails. In both cases, the error
+// is sent on the error channel and both channels are closed.
+func (c *Client) SubscribeTransactionStatus(
+	ctx context.Context,
+	txID flow.Identifier,
+	config PollConfig,
+) (<-chan *flow.TransactionResult, <-chan error) {
+	config = config.withDefaults()
+
+	results := make(chan *flow.TransactionResult)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(results)
+		defer close(errs)
+
+		if err := c.pollTransactionStatus(ctx, txID, config, results); err != nil {
+			errs <- err
+		}
+	}()
+
+	return results, errs
+}
+
+func (c *Client) pollTransactionStatus(
+	ctx context.Context,
+	txID flow.Identifier,
+	config PollConfig,
+	results chan<- *flow.TransactionResult,
+) error {
+	_, err := c.WaitForSeal(
+		ctx,
+		txID,
+		WithPollInterval(config.MinInterval),
+		WithPollBackoff(2, config.MaxInterval),
+		WithStatusCallback(func(result *flow.TransactionResult) {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	// the last result is not sent if the context was cancelled meanwhile
+	return ctx.Err()
+}
@@ -0,0 +1,85 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+func TestPollClock(t *testing.T) {
+	config := PollConfig{
+		MinInterval: 100 * time.Millisecond,
+		MaxInterval: 2 * time.Second,
+	}.withDefaults()
+
+	start := time.Unix(1600000000, 0)
+
+	header := func(height uint64, offset time.Duration) *flow.BlockHeader {
+		return &flow.BlockHeader{Height: height, Timestamp: start.Add(offset)}
+	}
+
+	t.Run("Minimum interval before block rate is known", func(t *testing.T) {
+		clock := newPollClock(config)
+		clock.observe(header(10, 0), start)
+
+		assert.Equal(t, config.MinInterval, clock.next(start))
+	})
+
+	t.Run("Waits for next expected block", func(t *testing.T) {
+		clock := newPollClock(config)
+		clock.observe(header(10, 0), start)
+		clock.observe(header(12, 2*time.Second), start.Add(2*time.Second))
+
+		assert.Equal(t, time.Second, clock.blockInterval)
+		assert.Equal(t, 700*time.Millisecond, clock.next(start.Add(2300*time.Millisecond)))
+	})
+
+	t.Run("Backs off while caught up", func(t *testing.T) {
+		clock := newPollClock(config)
+		clock.observe(header(10, 0), start)
+		clock.observe(header(11, time.Second), start.Add(time.Second))
+
+		now := start.Add(2 * time.Second)
+
+		var waits []time.Duration
+		for i := 0; i < 7; i++ {
+			clock.observe(header(11, time.Second), now)
+			waits = append(waits, clock.next(now))
+		}
+
+		assert.Equal(t, []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			1600 * time.Millisecond,
+			2 * time.Second,
+			2 * time.Second,
+		}, waits)
+
+		// a new block resets the backoff
+		clock.observe(header(12, 2*time.Second), now)
+		assert.Equal(t, time.Second, clock.next(now))
+	})
+}
@@ -0,0 +1,197 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// chainNode is an Access API node whose sealed height can be advanced by tests.
+type chainNode struct {
+	mu     sync.Mutex
+	height uint64
+	ranges [][2]uint64
+	heads  []uint64
+}
+
+func (n *chainNode) advance(height uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.height = height
+}
+
+func (n *chainNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.height}
+	case client.MethodGetBlockHeaderByHeight:
+		req := args.(*access.GetBlockHeaderByHeightRequest)
+		n.heads = append(n.heads, req.Height)
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: req.Height}
+	case client.MethodGetEventsForHeightRange:
+		req := args.(*access.GetEventsForHeightRangeRequest)
+		n.ranges = append(n.ranges, [2]uint64{req.StartHeight, req.EndHeight})
+
+		res := reply.(*access.EventsResponse)
+		for height := req.StartHeight; height <= req.EndHeight; height++ {
+			res.Results = append(res.Results, &access.EventsResponse_Result{
+				BlockHeight:    height,
+				BlockTimestamp: ptypes.TimestampNow(),
+			})
+		}
+	}
+
+	return nil
+}
+
+func TestClient_SubscribeEvents(t *testing.T) {
+	node := &chainNode{height: 12}
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocks, errs := c.SubscribeEvents(ctx, "A.0000000000000001.Test.Event", 5, client.PollConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+		MaxRange:    3,
+	})
+
+	var heights []uint64
+	for len(heights) < 10 {
+		select {
+		case block := <-blocks:
+			heights = append(heights, block.Height)
+			if block.Height == 12 {
+				node.advance(14)
+			}
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for blocks")
+		}
+	}
+
+	assert.Equal(t, []uint64{5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, heights)
+
+	cancel()
+
+	for range blocks {
+	}
+	assert.Equal(t, context.Canceled, <-errs)
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	assert.Equal(t, [][2]uint64{{5, 7}, {8, 10}, {11, 12}, {13, 14}}, node.ranges)
+}
+
+func TestClient_SubscribeBlocks(t *testing.T) {
+	node := &chainNode{height: 12}
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headers, errs := c.SubscribeBlocks(ctx, true, client.PollConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+	})
+
+	var heights []uint64
+	for len(heights) < 5 {
+		select {
+		case header := <-headers:
+			heights = append(heights, header.Height)
+			switch header.Height {
+			case 12:
+				node.advance(13)
+			case 13:
+				node.advance(16)
+			}
+		case err := <-errs:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for block headers")
+		}
+	}
+
+	assert.Equal(t, []uint64{12, 13, 14, 15, 16}, heights)
+
+	cancel()
+
+	for range headers {
+	}
+	assert.Equal(t, context.Canceled, <-errs)
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	assert.Equal(t, []uint64{14, 15}, node.heads)
+}
+
+func TestClient_SubscribeTransactionStatus(t *testing.T) {
+	node := &sequenceNode{
+		statuses: []entities.TransactionStatus{
+			entities.TransactionStatus_UNKNOWN,
+			entities.TransactionStatus_PENDING,
+			entities.TransactionStatus_PENDING,
+			entities.TransactionStatus_FINALIZED,
+			entities.TransactionStatus_SEALED,
+		},
+	}
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	results, errs := c.SubscribeTransactionStatus(context.Background(), flow.EmptyID, client.PollConfig{
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+	})
+
+	var statuses []flow.TransactionStatus
+	for result := range results {
+		statuses = append(statuses, result.Status)
+	}
+
+	assert.NoError(t, <-errs)
+	assert.Equal(t, []flow.TransactionStatus{
+		flow.TransactionStatusPending,
+		flow.TransactionStatusFinalized,
+		flow.TransactionStatusSealed,
+	}, statuses)
+}
@@ -0,0 +1,159 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"sync"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A Pool reuses the blocks, transactions and event slices returned by a client, to
+// reduce allocations and GC pressure when ingesting blocks in bulk.
+//
+// Values returned by a client using a pool belong to the caller until they are
+// released. Once released, a value and the slices it holds (collection guarantees,
+// arguments, signatures and events) must not be used again, as they are handed out
+// by later requests.
+//
+// A nil pool is valid and releases nothing.
+type Pool struct {
+	blocks       sync.Pool
+	transactions sync.Pool
+	events       sync.Pool
+}
+
+// NewPool returns an empty pool.
+func NewPool() *Pool {
+	return &Pool{}
+}
+
+// WithPool returns a client sharing the connection of c that takes the values
+// returned by GetLatestBlock, GetBlockByID, GetBlockByHeight, GetTransaction,
+// GetEventsForHeightRange and GetEventsForBlockIDs from the given pool.
+//
+// Callers release these values to the pool once they are processed:
+//
+//	for height := start; height <= end; height++ {
+//		block, err := c.GetBlockByHeight(ctx, height)
+//		if err != nil {
+//			return err
+//		}
+//
+//		index(block)
+//		pool.ReleaseBlock(block)
+//	}
+//
+// Values that are not released are reclaimed by the garbage collector.
+func (c *Client) WithPool(pool *Pool) *Client {
+	pooled := *c
+	pooled.pool = pool
+	return &pooled
+}
+
+// ReleaseBlock returns a block to the pool.
+func (p *Pool) ReleaseBlock(block *flow.Block) {
+	if p == nil || block == nil {
+		return
+	}
+
+	for i := range block.Seals {
+		block.Seals[i] = nil
+	}
+
+	p.blocks.Put(block)
+}
+
+// ReleaseTransaction returns a transaction to the pool.
+func (p *Pool) ReleaseTransaction(tx *flow.Transaction) {
+	if p == nil || tx == nil {
+		return
+	}
+
+	for i := range tx.Arguments {
+		tx.Arguments[i] = nil
+	}
+
+	for i := range tx.PayloadSignatures {
+		tx.PayloadSignatures[i] = flow.TransactionSignature{}
+	}
+
+	for i := range tx.EnvelopeSignatures {
+		tx.EnvelopeSignatures[i] = flow.TransactionSignature{}
+	}
+
+	tx.Script = nil
+
+	p.transactions.Put(tx)
+}
+
+// ReleaseEvents returns the event slices of the given blocks to the pool.
+func (p *Pool) ReleaseEvents(blocks ...BlockEvents) {
+	if p == nil {
+		return
+	}
+
+	for _, block := range blocks {
+		if cap(block.Events) == 0 {
+			continue
+		}
+
+		events := block.Events[:cap(block.Events)]
+		for i := range events {
+			events[i] = flow.Event{}
+		}
+
+		events = events[:0]
+		p.events.Put(&events)
+	}
+}
+
+// block returns a block from the pool, or a new block if the pool is empty or nil.
+func (p *Pool) block() *flow.Block {
+	if p != nil {
+		if block, ok := p.blocks.Get().(*flow.Block); ok {
+			return block
+		}
+	}
+
+	return &flow.Block{}
+}
+
+// transaction returns a transaction from the pool, or a new transaction if the pool
+// is empty or nil.
+func (p *Pool) transaction() *flow.Transaction {
+	if p != nil {
+		if tx, ok := p.transactions.Get().(*flow.Transaction); ok {
+			return tx
+		}
+	}
+
+	return &flow.Transaction{}
+}
+
+// eventSlice returns an empty event slice with capacity for at least n events.
+func (p *Pool) eventSlice(n int) []flow.Event {
+	if p != nil {
+		if events, ok := p.events.Get().(*[]flow.Event); ok && cap(*events) >= n {
+			return (*events)[:0]
+		}
+	}
+
+	return make([]flow.Event, 0, n)
+}
@@ -0,0 +1,205 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestPool_Blocks(t *testing.T) {
+	blocks := test.BlockGenerator()
+	guarantees := test.CollectionGuaranteeGenerator()
+
+	t.Run("Reused", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		pool := client.NewPool()
+		c = c.WithPool(pool)
+
+		blockA := blocks.New()
+		blockB := blocks.New()
+		blockB.CollectionGuarantees = []*flow.CollectionGuarantee{guarantees.New()}
+
+		msgA, err := convert.BlockToMessage(*blockA)
+		require.NoError(t, err)
+		msgB, err := convert.BlockToMessage(*blockB)
+		require.NoError(t, err)
+
+		rpc.On("GetBlockByHeight", ctx, &access.GetBlockByHeightRequest{Height: 1}).
+			Return(&access.BlockResponse{Block: msgA}, nil)
+		rpc.On("GetBlockByHeight", ctx, &access.GetBlockByHeightRequest{Height: 2}).
+			Return(&access.BlockResponse{Block: msgB}, nil)
+
+		block, err := c.GetBlockByHeight(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, blockA, block)
+
+		pool.ReleaseBlock(block)
+
+		block, err = c.GetBlockByHeight(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, blockB, block)
+	}))
+
+	t.Run("Nil pool", func(t *testing.T) {
+		var pool *client.Pool
+
+		pool.ReleaseBlock(blocks.New())
+		pool.ReleaseTransaction(test.TransactionGenerator().New())
+		pool.ReleaseEvents(client.BlockEvents{Events: []flow.Event{test.EventGenerator().New()}})
+	})
+}
+
+func TestPool_Transactions(t *testing.T) {
+	txs := test.TransactionGenerator()
+	ids := test.IdentifierGenerator()
+
+	clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		pool := client.NewPool()
+		c = c.WithPool(pool)
+
+		txA := txs.New()
+		txB := txs.NewUnsigned()
+		txB.Arguments = nil
+
+		msgA, err := convert.TransactionToMessage(*txA)
+		require.NoError(t, err)
+		msgB, err := convert.TransactionToMessage(*txB)
+		require.NoError(t, err)
+
+		idA, idB := ids.New(), ids.New()
+
+		rpc.On("GetTransaction", ctx, &access.GetTransactionRequest{Id: idA.Bytes()}).
+			Return(&access.TransactionResponse{Transaction: msgA}, nil)
+		rpc.On("GetTransaction", ctx, &access.GetTransactionRequest{Id: idB.Bytes()}).
+			Return(&access.TransactionResponse{Transaction: msgB}, nil)
+
+		tx, err := c.GetTransaction(ctx, idA)
+		require.NoError(t, err)
+		assert.Equal(t, txA, tx)
+
+		pool.ReleaseTransaction(tx)
+
+		tx, err = c.GetTransaction(ctx, idB)
+		require.NoError(t, err)
+		assert.Equal(t, txB.ID(), tx.ID())
+		assert.Empty(t, tx.Arguments)
+		assert.Empty(t, tx.PayloadSignatures)
+		assert.Empty(t, tx.EnvelopeSignatures)
+	})(t)
+}
+
+func TestPool_Events(t *testing.T) {
+	events := test.EventGenerator()
+	ids := test.IdentifierGenerator()
+
+	clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		pool := client.NewPool()
+		c = c.WithPool(pool)
+
+		eventA, eventB, eventC := events.New(), events.New(), events.New()
+
+		response := func(events ...flow.Event) *access.EventsResponse {
+			messages := make([]*entities.Event, len(events))
+			for i, event := range events {
+				messages[i], _ = convert.EventToMessage(event)
+			}
+
+			return &access.EventsResponse{
+				Results: []*access.EventsResponse_Result{
+					{
+						BlockId:        ids.New().Bytes(),
+						BlockHeight:    1,
+						BlockTimestamp: ptypes.TimestampNow(),
+						Events:         messages,
+					},
+				},
+			}
+		}
+
+		rpc.On("GetEventsForHeightRange", ctx, mock.Anything).Return(response(eventA, eventB), nil).Once()
+		rpc.On("GetEventsForHeightRange", ctx, mock.Anything).Return(response(eventC), nil).Once()
+
+		query := client.EventRangeQuery{Type: "foo", StartHeight: 1, EndHeight: 1}
+
+		blocks, err := c.GetEventsForHeightRange(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Event{eventA, eventB}, blocks[0].Events)
+
+		pool.ReleaseEvents(blocks...)
+
+		blocks, err = c.GetEventsForHeightRange(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Event{eventC}, blocks[0].Events)
+	})(t)
+}
+
+// blockRPCClient is an RPC client that returns the same block for every height.
+type blockRPCClient struct {
+	client.RPCClient
+	res *access.BlockResponse
+}
+
+func (c blockRPCClient) GetBlockByHeight(
+	context.Context,
+	*access.GetBlockByHeightRequest,
+	...grpc.CallOption,
+) (*access.BlockResponse, error) {
+	return c.res, nil
+}
+
+func BenchmarkPool_GetBlockByHeight(b *testing.B) {
+	block := test.BlockGenerator().New()
+
+	msg, err := convert.BlockToMessage(*block)
+	require.NoError(b, err)
+
+	ctx := context.Background()
+	rpc := blockRPCClient{res: &access.BlockResponse{Block: msg}}
+
+	run := func(b *testing.B, pool *client.Pool) {
+		c := client.NewFromRPCClient(rpc).WithPool(pool)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			block, err := c.GetBlockByHeight(ctx, 1)
+			if err != nil {
+				b.Fatal(err)
+			}
+			pool.ReleaseBlock(block)
+		}
+	}
+
+	b.Run("Unpooled", func(b *testing.B) { run(b, nil) })
+	b.Run("Pooled", func(b *testing.B) { run(b, client.NewPool()) })
+}
@@ -0,0 +1,549 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/templates"
+)
+
+// DefaultLeaseTimeout is the time after which a proposer key lease that was neither
+// committed nor released is reclaimed. Past this time, the transaction that used the
+// lease, if any, is sealed or expired.
+const DefaultLeaseTimeout = 10 * time.Minute
+
+// proposerKeysGasLimit is the gas limit of transactions that add proposer keys.
+const proposerKeysGasLimit = 9999
+
+// ProposerKeyPoolConfig configures a ProposerKeyPool.
+type ProposerKeyPoolConfig struct {
+	// Address is the proposer account.
+	Address flow.Address
+	// PublicKey selects the keys of the account that are pooled: all keys with this
+	// public key that are not revoked.
+	PublicKey crypto.PublicKey
+	// Signer signs with the private key of PublicKey.
+	Signer crypto.Signer
+	// HashAlgo is the hash algorithm of the keys added by AddKeys.
+	HashAlgo crypto.HashAlgorithm
+	// Weight is the weight of the keys added to the pool. The default weight of 0
+	// makes proposer-only keys, that cannot authorize transactions or pay for them.
+	Weight int
+	// AuthorizerKeyIndex is the index of the account key that proposes, authorizes
+	// and pays for the transactions that add keys. If it has the public key of the
+	// pool, it is leased from the pool to propose them.
+	AuthorizerKeyIndex int
+	// AuthorizerSigner signs with the key at AuthorizerKeyIndex.
+	AuthorizerSigner crypto.Signer
+	// LeaseTimeout is the time after which a lease is reclaimed.
+	//
+	// Defaults to DefaultLeaseTimeout.
+	LeaseTimeout time.Duration
+	// Submit configures how transactions that add keys are sent.
+	Submit SubmitConfig
+	// Replenish configures the automatic addition of keys to the pool.
+	Replenish ReplenishConfig
+	// OnEvent, if set, is called with the audit events of the pool, in order, from a
+	// single goroutine. A slow handler delays the delivery of later events.
+	OnEvent func(PoolEvent)
+}
+
+// A ProposerKeyPool leases the keys of a proposer account to concurrent senders.
+//
+// A key can only propose one transaction at a time, as each proposal must use the
+// next sequence number of the key. The pool gives each sender a distinct key
+// with its next sequence number, so that a single account sends many transactions
+// at once, and tracks sequence numbers as leases are returned.
+//
+// A lease must be returned with Commit if its proposal was used, with Release if it
+// was not, or with Discard if this is unknown. Leases that are not returned are
+// reclaimed after the lease timeout.
+//
+// A pool that adds keys automatically or has an OnEvent handler must be closed with
+// Close once it is no longer used.
+type ProposerKeyPool struct {
+	client *Client
+	config ProposerKeyPoolConfig
+
+	mu   sync.Mutex
+	keys map[int]*pooledKey
+	free []*pooledKey
+	// released is closed and replaced when a key becomes free.
+	released chan struct{}
+
+	addMu sync.Mutex
+
+	// replenishing is true while keys are added automatically.
+	replenishing bool
+	// lastReplenish is the time keys were last added automatically.
+	lastReplenish time.Time
+
+	// ctx is cancelled when the pool is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// replenishers tracks the goroutines that add keys.
+	replenishers sync.WaitGroup
+	closeOnce    sync.Once
+
+	eventMu sync.Mutex
+	// events are the events waiting to be delivered to OnEvent.
+	events []PoolEvent
+	// eventsClosed means that no more events are queued.
+	eventsClosed bool
+	// eventReady is signalled when events are queued.
+	eventReady chan struct{}
+	// eventsDone is closed once the queued events are delivered after the pool is
+	// closed.
+	eventsDone chan struct{}
+}
+
+type pooledKey struct {
+	index          int
+	sequenceNumber uint64
+	// stale means that the sequence number must be fetched before the key is leased.
+	stale bool
+	// revoked means that the key is removed from the pool when it is returned.
+	revoked bool
+	lease   *ProposerKeyLease
+}
+
+// NewProposerKeyPool returns a pool of the keys of the configured account.
+//
+// The pool may be empty, in which case keys are added with AddKeys.
+func NewProposerKeyPool(
+	ctx context.Context,
+	c *Client,
+	config ProposerKeyPoolConfig,
+) (*ProposerKeyPool, error) {
+	if config.LeaseTimeout <= 0 {
+		config.LeaseTimeout = DefaultLeaseTimeout
+	}
+
+	config.Replenish = config.Replenish.withDefaults()
+
+	p := &ProposerKeyPool{
+		client:     c,
+		config:     config,
+		keys:       make(map[int]*pooledKey),
+		released:   make(chan struct{}),
+		eventReady: make(chan struct{}, 1),
+		eventsDone: make(chan struct{}),
+	}
+
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	if err := p.sync(ctx); err != nil {
+		p.cancel()
+		return nil, err
+	}
+
+	if config.OnEvent != nil {
+		go p.deliverEvents()
+	} else {
+		close(p.eventsDone)
+	}
+
+	return p, nil
+}
+
+// Size returns the number of keys in the pool.
+func (p *ProposerKeyPool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.keys)
+}
+
+// Available returns the number of keys that are not leased.
+func (p *ProposerKeyPool) Available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.free)
+}
+
+// A ProposerKeyLease is the exclusive use of a proposer key for one transaction.
+type ProposerKeyLease struct {
+	Address        flow.Address
+	KeyIndex       int
+	SequenceNumber uint64
+	// Signer signs with the leased key.
+	Signer crypto.Signer
+
+	pool  *ProposerKeyPool
+	key   *pooledKey
+	timer *time.Timer
+}
+
+// Lease leases a key of the pool, waiting until one is free or ctx is done.
+func (p *ProposerKeyPool) Lease(ctx context.Context) (*ProposerKeyLease, error) {
+	for {
+		p.mu.Lock()
+
+		if len(p.free) > 0 {
+			key := p.free[0]
+			p.free = p.free[1:]
+
+			lease, err := p.acquire(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			if lease == nil {
+				continue
+			}
+
+			return lease, nil
+		}
+
+		released := p.released
+		p.replenish(ReplenishExhausted)
+		p.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// leaseKey leases the pooled key with the given index, waiting until it is free or
+// ctx is done. It returns a nil lease if the key is not in the pool.
+func (p *ProposerKeyPool) leaseKey(ctx context.Context, index int) (*ProposerKeyLease, error) {
+	for {
+		p.mu.Lock()
+
+		key, ok := p.keys[index]
+		if !ok || key.revoked {
+			p.mu.Unlock()
+			return nil, nil
+		}
+
+		if key.lease == nil {
+			for i, free := range p.free {
+				if free == key {
+					p.free = append(p.free[:i], p.free[i+1:]...)
+					break
+				}
+			}
+
+			lease, err := p.acquire(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			if lease == nil {
+				continue
+			}
+
+			return lease, nil
+		}
+
+		released := p.released
+		p.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// acquire leases a key that the caller removed from the free list, with p.mu held,
+// and unlocks p.mu. It returns a nil lease if the key was revoked meanwhile.
+func (p *ProposerKeyPool) acquire(ctx context.Context, key *pooledKey) (*ProposerKeyLease, error) {
+	lease := &ProposerKeyLease{
+		Address:  p.config.Address,
+		KeyIndex: key.index,
+		Signer:   p.config.Signer,
+		pool:     p,
+		key:      key,
+	}
+	key.lease = lease
+	stale := key.stale
+
+	p.mu.Unlock()
+
+	if stale {
+		if err := p.sync(ctx); err != nil {
+			lease.Release()
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	if key.revoked {
+		p.mu.Unlock()
+		lease.Release()
+		return nil, nil
+	}
+	lease.SequenceNumber = key.sequenceNumber
+	lease.timer = time.AfterFunc(p.config.LeaseTimeout, lease.reclaim)
+	p.mu.Unlock()
+
+	return lease, nil
+}
+
+// SetProposalKey sets the leased key as the proposal key of a transaction.
+func (l *ProposerKeyLease) SetProposalKey(tx *flow.Transaction) *flow.Transaction {
+	return tx.SetProposalKey(l.Address, l.KeyIndex, l.SequenceNumber)
+}
+
+// Commit returns the key to the pool after its proposal was used by a transaction
+// that was sent, and increments its sequence number.
+//
+// A transaction that is not sealed because it expired does not increment the
+// sequence number of its proposer, so leases should be committed once the
+// transaction is executed, and released if it expired.
+func (l *ProposerKeyLease) Commit() {
+	l.pool.put(l, func(key *pooledKey) {
+		key.sequenceNumber = l.SequenceNumber + 1
+	})
+}
+
+// Release returns the key to the pool without using its proposal.
+func (l *ProposerKeyLease) Release() {
+	l.pool.put(l, func(*pooledKey) {})
+}
+
+// Discard returns the key to the pool when it is unknown whether its proposal was
+// used. The sequence number of the key is fetched before the key is leased again.
+func (l *ProposerKeyLease) Discard() {
+	l.pool.put(l, markStale)
+}
+
+func markStale(key *pooledKey) {
+	key.stale = true
+}
+
+// put returns a leased key to the pool, and returns false if the lease was already
+// returned or reclaimed, in which case it has no effect.
+func (p *ProposerKeyPool) put(lease *ProposerKeyLease, update func(key *pooledKey)) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := lease.key
+	if key.lease != lease {
+		return false
+	}
+
+	if lease.timer != nil {
+		lease.timer.Stop()
+	}
+
+	key.lease = nil
+	update(key)
+
+	if key.revoked {
+		delete(p.keys, key.index)
+		return true
+	}
+
+	p.free = append(p.free, key)
+
+	close(p.released)
+	p.released = make(chan struct{})
+
+	return true
+}
+
+// AddKeys adds n keys with the public key of the pool to the account, and adds them
+// to the pool once the transaction is sealed.
+func (p *ProposerKeyPool) AddKeys(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("client: invalid number of keys %d", n)
+	}
+
+	_, err := p.addKeys(ctx, n)
+	return err
+}
+
+// addKeys adds n keys to the account and the pool, and returns the ID of the
+// transaction that added them, if it was sent.
+func (p *ProposerKeyPool) addKeys(ctx context.Context, n int) (flow.Identifier, error) {
+	p.addMu.Lock()
+	defer p.addMu.Unlock()
+
+	account, err := p.client.GetAccountAtLatestBlock(ctx, p.config.Address)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	var authorizer *flow.AccountKey
+	for _, key := range account.Keys {
+		if key.Index == p.config.AuthorizerKeyIndex {
+			authorizer = key
+		}
+	}
+
+	if authorizer == nil {
+		return flow.EmptyID, fmt.Errorf("client: account %s has no key %d", p.config.Address, p.config.AuthorizerKeyIndex)
+	}
+
+	if authorizer.Weight < flow.AccountKeyWeightThreshold {
+		return flow.EmptyID, fmt.Errorf("client: key %d cannot authorize transactions of account %s alone", authorizer.Index, p.config.Address)
+	}
+
+	header, err := p.client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	keys := make([]*flow.AccountKey, n)
+	for i := range keys {
+		keys[i] = flow.NewAccountKey().
+			SetPublicKey(p.config.PublicKey).
+			SetHashAlgo(p.config.HashAlgo).
+			SetWeight(p.config.Weight)
+	}
+
+	if err := keys[0].Validate(); err != nil {
+		return flow.EmptyID, fmt.Errorf("client: invalid proposer key: %w", err)
+	}
+
+	// the authorizer key may have the public key of the pool, in which case its
+	// sequence number is leased so that it does not propose two transactions at once
+	sequenceNumber := authorizer.SequenceNumber
+
+	lease, err := p.leaseKey(ctx, authorizer.Index)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	if lease != nil {
+		sequenceNumber = lease.SequenceNumber
+	}
+
+	tx := templates.AddAccountKeys(p.config.Address, keys).
+		SetReferenceBlockID(header.ID).
+		SetGasLimit(proposerKeysGasLimit).
+		SetProposalKey(p.config.Address, authorizer.Index, sequenceNumber).
+		SetPayer(p.config.Address)
+
+	err = tx.SignEnvelope(p.config.Address, authorizer.Index, p.config.AuthorizerSigner)
+	if err != nil {
+		if lease != nil {
+			lease.Release()
+		}
+		return flow.EmptyID, err
+	}
+
+	res, err := p.client.SubmitTransaction(ctx, *tx, p.config.Submit)
+	if lease != nil {
+		switch {
+		case err != nil:
+			lease.Discard()
+		case res.Result.Status == flow.TransactionStatusExpired:
+			lease.Release()
+		default:
+			lease.Commit()
+		}
+	}
+	if err != nil {
+		return tx.ID(), err
+	}
+
+	if res.Result.Status == flow.TransactionStatusExpired {
+		return tx.ID(), fmt.Errorf("client: transaction %s that adds proposer keys expired", tx.ID())
+	}
+
+	if res.Result.Error != nil {
+		return tx.ID(), fmt.Errorf("client: failed to add proposer keys: %w", res.Result.Error)
+	}
+
+	return tx.ID(), p.sync(ctx)
+}
+
+// sync fetches the keys of the account, adds new keys to the pool, removes revoked
+// keys, and updates the sequence numbers of stale keys that are not leased.
+func (p *ProposerKeyPool) sync(ctx context.Context) error {
+	account, err := p.client.GetAccountAtLatestBlock(ctx, p.config.Address)
+	if err != nil {
+		return err
+	}
+
+	publicKey := p.config.PublicKey.Encode()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	added := false
+
+	for _, accountKey := range account.Keys {
+		if !bytes.Equal(accountKey.PublicKey.Encode(), publicKey) {
+			continue
+		}
+
+		key, ok := p.keys[accountKey.Index]
+		if !ok {
+			if accountKey.Revoked {
+				continue
+			}
+
+			key = &pooledKey{
+				index:          accountKey.Index,
+				sequenceNumber: accountKey.SequenceNumber,
+			}
+			p.keys[key.index] = key
+			p.free = append(p.free, key)
+			added = true
+			continue
+		}
+
+		if accountKey.Revoked {
+			key.revoked = true
+		}
+
+		// stale keys are free or about to be leased, never in use
+		if key.stale {
+			key.sequenceNumber = accountKey.SequenceNumber
+			key.stale = false
+		}
+	}
+
+	if p.removeRevoked() || added {
+		close(p.released)
+		p.released = make(chan struct{})
+	}
+
+	return nil
+}
+
+// removeRevoked removes the revoked keys that are not leased, and returns true if
+// the free list changed.
+func (p *ProposerKeyPool) removeRevoked() bool {
+	free := p.free[:0]
+	for _, key := range p.free {
+		if key.revoked {
+			delete(p.keys, key.index)
+			continue
+		}
+		free = append(free, key)
+	}
+
+	changed := len(free) != len(p.free)
+	p.free = free
+	return changed
+}
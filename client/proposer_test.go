@@ -0,0 +1,439 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// accountNode is an Access API node that serves a single account, and executes
+// transactions that add keys to it.
+type accountNode struct {
+	mu      sync.Mutex
+	account flow.Account
+	// proposals are the proposal keys of the transactions sent to the node.
+	proposals []flow.ProposalKey
+}
+
+func (n *accountNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch method {
+	case client.MethodGetAccountAtLatestBlock:
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(n.account)
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Id: make([]byte, 32)}
+	case client.MethodGetTransactionResult:
+		reply.(*access.TransactionResultResponse).Status = entities.TransactionStatus_SEALED
+	case client.MethodSendTransaction:
+		tx, err := convert.MessageToTransaction(args.(*access.SendTransactionRequest).Transaction)
+		if err != nil {
+			return err
+		}
+
+		n.proposals = append(n.proposals, tx.ProposalKey)
+
+		value, err := jsoncdc.Decode(tx.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		for _, encoded := range value.(cadence.Array).Values {
+			b := make([]byte, len(encoded.(cadence.Array).Values))
+			for i, v := range encoded.(cadence.Array).Values {
+				b[i] = byte(v.(cadence.UInt8))
+			}
+
+			key, err := flow.DecodeAccountKey(b)
+			if err != nil {
+				return err
+			}
+
+			key.Index = len(n.account.Keys)
+			n.account.Keys = append(n.account.Keys, key)
+		}
+
+		reply.(*access.SendTransactionResponse).Id = make([]byte, 32)
+	}
+
+	return nil
+}
+
+func (n *accountNode) setSequenceNumber(index int, sequenceNumber uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.account.Keys[index].SequenceNumber = sequenceNumber
+}
+
+func TestProposerKeyPool(t *testing.T) {
+	ctx := context.Background()
+
+	newKey := func(b byte) crypto.PrivateKey {
+		privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{b}, crypto.MinSeedLength))
+		require.NoError(t, err)
+		return privateKey
+	}
+
+	adminKey := newKey(1)
+	proposerKey := newKey(2)
+
+	accountKey := func(privateKey crypto.PrivateKey, index int, sequenceNumber uint64) *flow.AccountKey {
+		key := flow.NewAccountKey().
+			FromPrivateKey(privateKey).
+			SetHashAlgo(crypto.SHA3_256).
+			SetWeight(flow.AccountKeyWeightThreshold)
+		key.Index = index
+		key.SequenceNumber = sequenceNumber
+		return key
+	}
+
+	address := flow.HexToAddress("01")
+
+	newPool := func(t *testing.T, config client.ProposerKeyPoolConfig) (*client.ProposerKeyPool, *accountNode) {
+		node := &accountNode{
+			account: flow.Account{
+				Address: address,
+				Keys: []*flow.AccountKey{
+					accountKey(adminKey, 0, 0),
+					accountKey(proposerKey, 1, 5),
+					accountKey(proposerKey, 2, 7),
+				},
+			},
+		}
+
+		config.Address = address
+		config.PublicKey = proposerKey.PublicKey()
+		config.Signer = crypto.NewInMemorySigner(proposerKey, crypto.SHA3_256)
+		config.HashAlgo = crypto.SHA3_256
+		config.AuthorizerSigner = crypto.NewInMemorySigner(adminKey, crypto.SHA3_256)
+		config.Submit.Consistency.PollInterval = time.Millisecond
+
+		pool, err := client.NewProposerKeyPool(ctx, client.NewFromEndpoint(client.NewEndpoint("node", node)), config)
+		require.NoError(t, err)
+
+		return pool, node
+	}
+
+	t.Run("Leases distinct keys", func(t *testing.T) {
+		pool, _ := newPool(t, client.ProposerKeyPoolConfig{})
+		assert.Equal(t, 2, pool.Size())
+
+		first, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		second, err := pool.Lease(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, first.KeyIndex)
+		assert.Equal(t, uint64(5), first.SequenceNumber)
+		assert.Equal(t, 2, second.KeyIndex)
+		assert.Equal(t, uint64(7), second.SequenceNumber)
+		assert.Equal(t, 0, pool.Available())
+
+		tx := first.SetProposalKey(flow.NewTransaction())
+		assert.Equal(t, flow.ProposalKey{Address: address, KeyIndex: 1, SequenceNumber: 5}, tx.ProposalKey)
+
+		timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err = pool.Lease(timeout)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		first.Commit()
+		second.Release()
+
+		// returning a lease twice has no effect
+		first.Commit()
+
+		third, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, third.KeyIndex)
+		assert.Equal(t, uint64(6), third.SequenceNumber)
+
+		fourth, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fourth.KeyIndex)
+		assert.Equal(t, uint64(7), fourth.SequenceNumber)
+	})
+
+	t.Run("Waits for a released key", func(t *testing.T) {
+		pool, _ := newPool(t, client.ProposerKeyPoolConfig{})
+
+		first, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		_, err = pool.Lease(ctx)
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			first.Commit()
+		}()
+
+		lease, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, first.KeyIndex, lease.KeyIndex)
+	})
+
+	t.Run("Discarded keys are synced", func(t *testing.T) {
+		pool, node := newPool(t, client.ProposerKeyPoolConfig{})
+
+		lease, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		_, err = pool.Lease(ctx)
+		require.NoError(t, err)
+
+		node.setSequenceNumber(lease.KeyIndex, 42)
+		lease.Discard()
+
+		lease, err = pool.Lease(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), lease.SequenceNumber)
+	})
+
+	t.Run("Reclaims expired leases", func(t *testing.T) {
+		pool, node := newPool(t, client.ProposerKeyPoolConfig{LeaseTimeout: 10 * time.Millisecond})
+
+		lease, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		_, err = pool.Lease(ctx)
+		require.NoError(t, err)
+
+		node.setSequenceNumber(lease.KeyIndex, 6)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 2, pool.Available())
+
+		first, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		second, err := pool.Lease(ctx)
+		require.NoError(t, err)
+
+		reclaimed := first
+		if second.KeyIndex == lease.KeyIndex {
+			reclaimed = second
+		}
+		assert.Equal(t, uint64(6), reclaimed.SequenceNumber)
+
+		// the expired lease no longer returns the key
+		lease.Commit()
+		assert.Equal(t, 0, pool.Available())
+	})
+
+	t.Run("Adds keys", func(t *testing.T) {
+		pool, node := newPool(t, client.ProposerKeyPoolConfig{})
+
+		require.NoError(t, pool.AddKeys(ctx, 3))
+		assert.Equal(t, 5, pool.Size())
+		assert.Equal(t, 5, pool.Available())
+		assert.Len(t, node.account.Keys, 6)
+	})
+
+	t.Run("Leases a pooled authorizer key", func(t *testing.T) {
+		pool, node := newPool(t, client.ProposerKeyPoolConfig{
+			AuthorizerKeyIndex: 1,
+			AuthorizerSigner:   crypto.NewInMemorySigner(proposerKey, crypto.SHA3_256),
+		})
+
+		first, err := pool.Lease(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, first.KeyIndex)
+
+		added := make(chan error, 1)
+		go func() {
+			added <- pool.AddKeys(ctx, 1)
+		}()
+
+		// keys are not added while the authorizer key proposes another transaction
+		select {
+		case err := <-added:
+			t.Fatalf("keys added while the authorizer key is leased: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		first.Commit()
+		require.NoError(t, <-added)
+
+		node.mu.Lock()
+		assert.Equal(t, []flow.ProposalKey{{Address: address, KeyIndex: 1, SequenceNumber: 6}}, node.proposals)
+		node.mu.Unlock()
+
+		assert.Equal(t, 3, pool.Size())
+
+		for i := 0; i < 3; i++ {
+			lease, err := pool.Lease(ctx)
+			require.NoError(t, err)
+
+			if lease.KeyIndex == 1 {
+				assert.Equal(t, uint64(7), lease.SequenceNumber)
+			}
+		}
+	})
+
+	t.Run("Replenishes exhausted pool", func(t *testing.T) {
+		events := make(chan client.PoolEvent, 10)
+
+		pool, node := newPool(t, client.ProposerKeyPoolConfig{
+			Replenish: client.ReplenishConfig{
+				MaxKeys:     4,
+				BatchSize:   2,
+				MinInterval: time.Millisecond,
+			},
+			OnEvent: func(event client.PoolEvent) {
+				events <- event
+			},
+		})
+
+		for i := 0; i < 4; i++ {
+			_, err := pool.Lease(ctx)
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 4, pool.Size())
+		assert.Equal(t, 0, node.account.Keys[3].Weight)
+
+		replenishing := <-events
+		assert.Equal(t, client.PoolEventReplenishing, replenishing.Kind)
+		assert.Equal(t, client.ReplenishExhausted, replenishing.Reason)
+		assert.Equal(t, 2, replenishing.Keys)
+
+		replenished := <-events
+		assert.Equal(t, client.PoolEventReplenished, replenished.Kind)
+		assert.Equal(t, 4, replenished.Size)
+		assert.NotEqual(t, flow.EmptyID, replenished.TransactionID)
+
+		time.Sleep(10 * time.Millisecond)
+
+		timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err := pool.Lease(timeout)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		capReached := <-events
+		assert.Equal(t, client.PoolEventCapReached, capReached.Kind)
+		assert.Equal(t, address, capReached.Address)
+	})
+
+	t.Run("Replenishes after lock-up", func(t *testing.T) {
+		events := make(chan client.PoolEvent, 10)
+
+		pool, _ := newPool(t, client.ProposerKeyPoolConfig{
+			LeaseTimeout: 10 * time.Millisecond,
+			Replenish: client.ReplenishConfig{
+				MaxKeys: 3,
+			},
+			OnEvent: func(event client.PoolEvent) {
+				events <- event
+			},
+		})
+
+		lease, err := pool.Lease(ctx)
+		require.NoError(t, err)
+
+		var kinds []client.PoolEventKind
+		for i := 0; i < 3; i++ {
+			event := <-events
+			kinds = append(kinds, event.Kind)
+
+			if event.Kind == client.PoolEventLeaseReclaimed {
+				assert.Equal(t, lease.KeyIndex, event.KeyIndex)
+			} else {
+				assert.Equal(t, client.ReplenishLockUp, event.Reason)
+				assert.Equal(t, 1, event.Keys)
+			}
+		}
+
+		// events are delivered in order
+		assert.Equal(t, []client.PoolEventKind{
+			client.PoolEventLeaseReclaimed,
+			client.PoolEventReplenishing,
+			client.PoolEventReplenished,
+		}, kinds)
+		assert.Equal(t, 3, pool.Size())
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		var events []client.PoolEvent
+
+		pool, _ := newPool(t, client.ProposerKeyPoolConfig{
+			Replenish: client.ReplenishConfig{
+				MaxKeys:     4,
+				BatchSize:   2,
+				MinInterval: time.Millisecond,
+			},
+			OnEvent: func(event client.PoolEvent) {
+				events = append(events, event)
+			},
+		})
+
+		timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		for {
+			if _, err := pool.Lease(timeout); err != nil {
+				break
+			}
+		}
+
+		require.NoError(t, pool.Close())
+		require.NoError(t, pool.Close())
+
+		// the addition in progress returned and its events were delivered
+		require.True(t, len(events) >= 2)
+		assert.Equal(t, client.PoolEventReplenishing, events[0].Kind)
+		assert.Contains(t, []client.PoolEventKind{
+			client.PoolEventReplenished,
+			client.PoolEventReplenishFailed,
+		}, events[1].Kind)
+
+		// keys are no longer added
+		delivered := len(events)
+
+		timeout, cancel = context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err := pool.Lease(timeout)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Len(t, events, delivered)
+	})
+}
@@ -0,0 +1,216 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// A RateLimiter throttles the calls of a client to a sustained rate, e.g. to stay
+// within the limits of a shared public Access node instead of being rejected with
+// backpressure errors.
+//
+// Calls over the limit wait for their turn rather than fail, unless their context
+// is done first. The wait can be bounded with SetQueueLimit, past which calls are
+// rejected with a BackpressureError so that callers can shed load.
+type RateLimiter struct {
+	limit   *rateBucket
+	methods map[string]*rateBucket
+
+	maxWait    time.Duration
+	maxWaiters int32
+	metrics    MetricsRecorder
+	waiting    int32
+}
+
+// NewRateLimiter returns a limiter that allows rps calls per second on average,
+// and bursts of up to burst calls. A rate of zero or less does not limit calls.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		limit:   newRateBucket(rps, burst),
+		methods: make(map[string]*rateBucket),
+	}
+}
+
+// SetMethodLimit limits the calls to a method, e.g. MethodExecuteScriptAtLatestBlock,
+// to their own rate, instead of the rate of the limiter.
+//
+// Method limits must be set before the limiter is used.
+func (l *RateLimiter) SetMethodLimit(method string, rps float64, burst int) {
+	l.methods[method] = newRateBucket(rps, burst)
+}
+
+// SetQueueLimit bounds the calls waiting for the limiter. Calls that would wait
+// longer than maxWait, or while maxWaiters calls are already waiting, fail right
+// away with a BackpressureError that suggests retrying after the wait they would
+// have had. A bound of zero does not limit calls.
+//
+// The queue limit must be set before the limiter is used.
+func (l *RateLimiter) SetQueueLimit(maxWait time.Duration, maxWaiters int) {
+	l.maxWait = maxWait
+	l.maxWaiters = int32(maxWaiters)
+}
+
+// SetMetrics reports the number of calls waiting for the limiter to the recorder,
+// if it is a QueueMetricsRecorder, under the name QueueRateLimiter.
+//
+// The recorder must be set before the limiter is used.
+func (l *RateLimiter) SetMetrics(recorder MetricsRecorder) {
+	l.metrics = recorder
+}
+
+// Wait blocks until a call to the method is allowed, or the context is done.
+//
+// If the call would exceed the queue limit of the limiter, Wait returns a
+// BackpressureError instead.
+func (l *RateLimiter) Wait(ctx context.Context, method string) error {
+	bucket, ok := l.methods[method]
+	if !ok {
+		bucket = l.limit
+	}
+
+	if bucket == nil {
+		return nil
+	}
+
+	delay := bucket.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	if l.maxWait > 0 && delay > l.maxWait {
+		bucket.cancel()
+		return &BackpressureError{
+			Message: fmt.Sprintf("rate limit would delay the call for over %s", l.maxWait),
+			Delay:   delay,
+		}
+	}
+
+	waiting := atomic.AddInt32(&l.waiting, 1)
+	if l.maxWaiters > 0 && waiting > l.maxWaiters {
+		atomic.AddInt32(&l.waiting, -1)
+		bucket.cancel()
+		return &BackpressureError{
+			Message: fmt.Sprintf("%d calls already wait for the rate limit", l.maxWaiters),
+			Delay:   delay,
+		}
+	}
+
+	l.recordWaiting(ctx, waiting)
+	defer func() {
+		l.recordWaiting(ctx, atomic.AddInt32(&l.waiting, -1))
+	}()
+
+	if err := sleep(ctx, delay); err != nil {
+		bucket.cancel()
+		return err
+	}
+
+	return nil
+}
+
+func (l *RateLimiter) recordWaiting(ctx context.Context, waiting int32) {
+	if l.metrics != nil {
+		recordQueue(ctx, l.metrics, QueueMetrics{Queue: QueueRateLimiter, Waiting: int(waiting)})
+	}
+}
+
+// Middleware returns a middleware that waits for the limiter before each call.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			if err := l.Wait(ctx, method); err != nil {
+				return err
+			}
+			return next.Invoke(ctx, method, args, reply, opts...)
+		})
+	}
+}
+
+// A rateBucket is a token bucket whose tokens can be reserved in advance.
+type rateBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateBucket returns a bucket that refills at rps tokens per second up to
+// burst tokens, or nil if rps is not positive.
+func newRateBucket(rps float64, burst int) *rateBucket {
+	if rps <= 0 {
+		return nil
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateBucket{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes a token from the bucket, and returns the time until the token is
+// available. Tokens reserved ahead of time make later reservations wait longer, so
+// waiting calls are served in order.
+func (b *rateBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was not used.
+func (b *rateBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+}
@@ -0,0 +1,177 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	noop := client.InvokerFunc(func(context.Context, string, interface{}, interface{}, ...grpc.CallOption) error {
+		return nil
+	})
+
+	limiter := client.NewRateLimiter(100, 2)
+	limiter.SetMethodLimit(client.MethodExecuteScriptAtLatestBlock, 0, 0)
+
+	invoker := client.Chain(noop, limiter.Middleware())
+
+	t.Run("Throttles calls", func(t *testing.T) {
+		start := time.Now()
+
+		// the burst is served at once, and the next 4 calls wait 10ms each
+		for i := 0; i < 6; i++ {
+			require.NoError(t, invoker.Invoke(ctx, client.MethodPing, nil, nil))
+		}
+
+		assert.True(t, time.Since(start) >= 35*time.Millisecond)
+	})
+
+	t.Run("Method limit", func(t *testing.T) {
+		start := time.Now()
+
+		for i := 0; i < 100; i++ {
+			require.NoError(t, invoker.Invoke(ctx, client.MethodExecuteScriptAtLatestBlock, nil, nil))
+		}
+
+		assert.True(t, time.Since(start) < 30*time.Millisecond)
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		limiter := client.NewRateLimiter(1, 1)
+		require.NoError(t, limiter.Wait(ctx, client.MethodPing))
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		assert.Equal(t, context.DeadlineExceeded, limiter.Wait(ctx, client.MethodPing))
+	})
+
+	t.Run("Max wait", func(t *testing.T) {
+		limiter := client.NewRateLimiter(10, 1)
+		limiter.SetQueueLimit(50*time.Millisecond, 0)
+		require.NoError(t, limiter.Wait(ctx, client.MethodPing))
+
+		// the next token is available in 100ms
+		err := limiter.Wait(ctx, client.MethodPing)
+		assert.True(t, errors.Is(err, client.ErrBackpressure))
+
+		retryAfter, ok := client.RetryAfter(err)
+		require.True(t, ok)
+		assert.True(t, retryAfter > 50*time.Millisecond && retryAfter <= 100*time.Millisecond, retryAfter)
+
+		time.Sleep(retryAfter)
+		assert.NoError(t, limiter.Wait(ctx, client.MethodPing))
+	})
+
+	t.Run("Max waiters", func(t *testing.T) {
+		metrics := client.NewMetrics()
+
+		limiter := client.NewRateLimiter(20, 1)
+		limiter.SetQueueLimit(0, 1)
+		limiter.SetMetrics(metrics)
+		require.NoError(t, limiter.Wait(ctx, client.MethodPing))
+
+		done := make(chan error)
+		go func() { done <- limiter.Wait(ctx, client.MethodPing) }()
+
+		waiting := `flow_client_queue_waiting{queue="rate_limiter"} 1`
+		require.Eventually(t, func() bool {
+			return strings.Contains(metricsOutput(t, metrics), waiting)
+		}, time.Second, time.Millisecond)
+
+		err := limiter.Wait(ctx, client.MethodPing)
+		assert.True(t, errors.Is(err, client.ErrBackpressure))
+
+		retryAfter, ok := client.RetryAfter(err)
+		assert.True(t, ok)
+		assert.True(t, retryAfter > 0)
+
+		require.NoError(t, <-done)
+		assert.Contains(t, metricsOutput(t, metrics), `flow_client_queue_waiting{queue="rate_limiter"} 0`)
+	})
+}
+
+func TestNewClient_RateLimit(t *testing.T) {
+	ping, addr, stop := startPingServer(t)
+	defer stop()
+
+	c, err := client.NewClient(
+		addr,
+		client.WithInsecure(),
+		client.WithRateLimit(1, 1),
+		client.WithMethodRateLimit(client.MethodGetLatestBlockHeader, 1000, 1),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	require.NoError(t, c.Ping(ctx))
+
+	// the second ping waits for about a second
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, c.Ping(ctx))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&ping.pings))
+
+	// other methods have their own limit, and reach the server
+	_, err = c.GetLatestBlockHeader(context.Background(), true)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	t.Run("Queue limit", func(t *testing.T) {
+		c, err := client.NewClient(
+			addr,
+			client.WithInsecure(),
+			client.WithRateLimit(1, 1),
+			client.WithRateLimitQueue(100*time.Millisecond, 0),
+		)
+		require.NoError(t, err)
+		defer c.Close()
+
+		ctx := context.Background()
+		require.NoError(t, c.Ping(ctx))
+
+		// the second ping would wait for about a second
+		err = c.Ping(ctx)
+		assert.True(t, errors.Is(err, client.ErrBackpressure))
+
+		_, err = c.GetLatestBlockHeader(ctx, true)
+		assert.True(t, errors.Is(err, client.ErrBackpressure))
+
+		retryAfter, ok := client.RetryAfter(err)
+		assert.True(t, ok)
+		assert.True(t, retryAfter > 100*time.Millisecond)
+	})
+}
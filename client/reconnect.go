@@ -0,0 +1,329 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of ReconnectConfig.
+const (
+	// DefaultReconnectFailures is the number of consecutive unavailable calls after
+	// which the connection is re-dialed.
+	DefaultReconnectFailures = 3
+	// DefaultReconnectBackoff is the minimum time between two re-dials.
+	DefaultReconnectBackoff = time.Second
+	// DefaultMaxReconnectBackoff is the maximum time between two re-dials.
+	DefaultMaxReconnectBackoff = time.Minute
+	// DefaultReconnectDrainTimeout is the maximum time a replaced connection is kept
+	// open for the calls in flight on it.
+	DefaultReconnectDrainTimeout = 30 * time.Second
+)
+
+// ReconnectConfig configures the re-dialing of a connection that the Access API
+// stopped serving, e.g. after the Access node restarted or moved to another address.
+type ReconnectConfig struct {
+	// Failures is the number of consecutive calls failing because the Access API is
+	// unavailable after which the connection is re-dialed. See DefaultReconnectFailures.
+	Failures int
+	// Backoff is the minimum time between two re-dials. It doubles after each re-dial
+	// until a call succeeds, up to MaxBackoff. See DefaultReconnectBackoff.
+	Backoff time.Duration
+	// MaxBackoff is the maximum time between two re-dials. See DefaultMaxReconnectBackoff.
+	MaxBackoff time.Duration
+	// DrainTimeout is the maximum time a replaced connection is kept open, so that
+	// the calls in flight on it can complete. Calls still in flight after it fail as
+	// canceled. See DefaultReconnectDrainTimeout.
+	DrainTimeout time.Duration
+	// OnReconnect, if set, is called after the connection is re-dialed, with the number
+	// of re-dials since the last successful call.
+	OnReconnect func(attempts int)
+}
+
+// withDefaults returns the config with defaults for unset fields.
+func (c ReconnectConfig) withDefaults() ReconnectConfig {
+	if c.Failures <= 0 {
+		c.Failures = DefaultReconnectFailures
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultReconnectBackoff
+	}
+	if c.MaxBackoff < c.Backoff {
+		c.MaxBackoff = DefaultMaxReconnectBackoff
+		if c.MaxBackoff < c.Backoff {
+			c.MaxBackoff = c.Backoff
+		}
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = DefaultReconnectDrainTimeout
+	}
+	return c
+}
+
+// trackedConn is a connection with the number of calls in flight on it, so that it
+// is only closed once they completed after it was replaced.
+type trackedConn struct {
+	*grpc.ClientConn
+
+	inFlight int32
+	retired  int32
+	idleOnce sync.Once
+	idle     chan struct{}
+}
+
+func newTrackedConn(conn *grpc.ClientConn) *trackedConn {
+	return &trackedConn{
+		ClientConn: conn,
+		idle:       make(chan struct{}),
+	}
+}
+
+func (c *trackedConn) acquire() {
+	atomic.AddInt32(&c.inFlight, 1)
+}
+
+func (c *trackedConn) release() {
+	if atomic.AddInt32(&c.inFlight, -1) == 0 && atomic.LoadInt32(&c.retired) == 1 {
+		c.idleOnce.Do(func() { close(c.idle) })
+	}
+}
+
+// retire closes the connection once no call is in flight on it, after at most the
+// drain timeout, or right away when ctx is done.
+func (c *trackedConn) retire(ctx context.Context, drainTimeout time.Duration) {
+	atomic.StoreInt32(&c.retired, 1)
+	if atomic.LoadInt32(&c.inFlight) == 0 {
+		c.idleOnce.Do(func() { close(c.idle) })
+	}
+
+	timer := time.NewTimer(drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.idle:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	_ = c.Close()
+}
+
+// reconnectingConn is a connection to an Access API node that is replaced by a new
+// connection when calls keep failing because the node is unavailable.
+//
+// gRPC connections reconnect on their own, but keep the resolved addresses and an
+// ever growing backoff, and may never recover from some failures. A new connection
+// resolves the target again and connects right away.
+type reconnectingConn struct {
+	addr   string
+	opts   []grpc.DialOption
+	config ReconnectConfig
+	// ctx is canceled when the connection is closed, to abandon re-dials.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.RWMutex
+	conn     *trackedConn
+	failures int
+	attempts int
+	backoff  time.Duration
+	nextDial time.Time
+	dialing  bool
+	closed   bool
+}
+
+var _ Invoker = &reconnectingConn{}
+
+func dialReconnecting(
+	ctx context.Context,
+	addr string,
+	config ReconnectConfig,
+	opts ...grpc.DialOption,
+) (*reconnectingConn, error) {
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	config = config.withDefaults()
+	dialCtx, cancel := context.WithCancel(context.Background())
+
+	return &reconnectingConn{
+		addr:    addr,
+		opts:    opts,
+		config:  config,
+		ctx:     dialCtx,
+		cancel:  cancel,
+		conn:    newTrackedConn(conn),
+		backoff: config.Backoff,
+	}, nil
+}
+
+// NewReconnecting initializes a Flow client for the Access API at the given address
+// that re-dials the connection when calls keep failing because the Access API is
+// unavailable, instead of failing until the process restarts.
+func NewReconnecting(addr string, config ReconnectConfig, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := dialReconnecting(context.Background(), addr, config, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFromReconnecting(conn), nil
+}
+
+// newFromReconnecting returns a client that sends all calls over the given
+// connection, and closes it when the client is closed.
+func newFromReconnecting(conn *reconnectingConn) *Client {
+	return &Client{
+		rpcClient: NewRPCClient(conn),
+		invoker:   conn,
+		close:     conn.Close,
+		endpoint:  conn.addr,
+	}
+}
+
+// Invoke sends a call over the current connection, and re-dials it if too many
+// consecutive calls failed because the Access API is unavailable.
+func (r *reconnectingConn) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	r.mu.RLock()
+	conn := r.conn
+	conn.acquire()
+	r.mu.RUnlock()
+
+	defer conn.release()
+
+	err := conn.Invoke(ctx, method, args, reply, opts...)
+
+	switch {
+	case err == nil:
+		r.succeeded(conn)
+	case status.Code(err) == codes.Unavailable && ctx.Err() == nil:
+		r.failed(conn)
+	}
+
+	return err
+}
+
+// succeeded resets the failures and the backoff after a call over conn succeeded.
+func (r *reconnectingConn) succeeded(conn *trackedConn) {
+	r.mu.RLock()
+	reset := conn == r.conn && (r.failures > 0 || r.attempts > 0)
+	r.mu.RUnlock()
+
+	if !reset {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if conn == r.conn {
+		r.failures = 0
+		r.attempts = 0
+		r.backoff = r.config.Backoff
+	}
+}
+
+// failed counts a call over conn that failed because the Access API is unavailable,
+// and re-dials the connection in the background once there are too many.
+func (r *reconnectingConn) failed(conn *trackedConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if conn != r.conn || r.closed {
+		return
+	}
+
+	r.failures++
+	if r.failures < r.config.Failures || r.dialing || time.Now().Before(r.nextDial) {
+		return
+	}
+
+	r.dialing = true
+	go r.redial()
+}
+
+// redial replaces the current connection with a new one. The old connection is
+// closed once the calls in flight on it completed, or after the drain timeout.
+//
+// Dials that block until the connection is established, e.g. with grpc.WithBlock,
+// are abandoned after the maximum backoff.
+func (r *reconnectingConn) redial() {
+	ctx, cancel := context.WithTimeout(r.ctx, r.config.MaxBackoff)
+	conn, err := grpc.DialContext(ctx, r.addr, r.opts...)
+	cancel()
+
+	r.mu.Lock()
+
+	r.dialing = false
+	r.nextDial = time.Now().Add(r.backoff)
+	r.backoff *= 2
+	if r.backoff > r.config.MaxBackoff {
+		r.backoff = r.config.MaxBackoff
+	}
+
+	if err != nil {
+		r.mu.Unlock()
+		return
+	}
+
+	if r.closed {
+		r.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+
+	old := r.conn
+	r.conn = newTrackedConn(conn)
+	r.failures = 0
+	r.attempts++
+	attempts := r.attempts
+
+	r.mu.Unlock()
+
+	go old.retire(r.ctx, r.config.DrainTimeout)
+
+	if r.config.OnReconnect != nil {
+		r.config.OnReconnect(attempts)
+	}
+}
+
+// Close closes the current connection, and the replaced connections that are still
+// draining.
+func (r *reconnectingConn) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	r.cancel()
+	return r.conn.Close()
+}
@@ -0,0 +1,198 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestNewReconnecting(t *testing.T) {
+	ping, addr, stop := startPingServer(t)
+
+	var reconnects int32
+
+	c, err := client.NewReconnecting(
+		addr,
+		client.ReconnectConfig{
+			Failures: 1,
+			Backoff:  10 * time.Millisecond,
+			OnReconnect: func(attempts int) {
+				atomic.AddInt32(&reconnects, 1)
+			},
+		},
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, c.Ping(ctx))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ping.pings))
+
+	stop()
+
+	err = c.Ping(ctx)
+	require.Error(t, err)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&reconnects) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the node comes back at the same address
+	lis, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	restarted := &pingServer{}
+	access.RegisterAccessAPIServer(server, restarted)
+
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	assert.Eventually(t, func() bool {
+		return c.Ping(ctx) == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Greater(t, atomic.LoadInt32(&restarted.pings), int32(0))
+}
+
+// drainServer is an Access API server whose pings block until released, and whose
+// latest block header requests fail as unavailable, to trigger re-dials while pings
+// are in flight.
+type drainServer struct {
+	access.UnimplementedAccessAPIServer
+	received chan struct{}
+	release  chan struct{}
+}
+
+func (s *drainServer) Ping(ctx context.Context, _ *access.PingRequest) (*access.PingResponse, error) {
+	s.received <- struct{}{}
+	select {
+	case <-s.release:
+	case <-ctx.Done():
+	}
+	return &access.PingResponse{}, nil
+}
+
+func (s *drainServer) GetLatestBlockHeader(
+	context.Context,
+	*access.GetLatestBlockHeaderRequest,
+) (*access.BlockHeaderResponse, error) {
+	return nil, status.Error(codes.Unavailable, "unavailable")
+}
+
+func TestNewReconnecting_Drain(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	node := &drainServer{
+		received: make(chan struct{}, 1),
+		release:  make(chan struct{}),
+	}
+	access.RegisterAccessAPIServer(server, node)
+
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	ctx := context.Background()
+
+	// reconnect starts a ping, and makes another call re-dial the connection while
+	// the ping is in flight
+	reconnect := func(t *testing.T, drainTimeout time.Duration) (*client.Client, chan error) {
+		var reconnects int32
+
+		c, err := client.NewReconnecting(
+			lis.Addr().String(),
+			client.ReconnectConfig{
+				Failures:     1,
+				Backoff:      10 * time.Millisecond,
+				DrainTimeout: drainTimeout,
+				OnReconnect: func(int) {
+					atomic.AddInt32(&reconnects, 1)
+				},
+			},
+			grpc.WithInsecure(),
+		)
+		require.NoError(t, err)
+
+		errs := make(chan error, 1)
+		go func() { errs <- c.Ping(ctx) }()
+		<-node.received
+
+		_, err = c.GetLatestBlockHeader(ctx, true)
+		require.Error(t, err)
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&reconnects) > 0
+		}, 5*time.Second, 5*time.Millisecond)
+
+		return c, errs
+	}
+
+	t.Run("Calls in flight complete", func(t *testing.T) {
+		c, errs := reconnect(t, time.Minute)
+		defer c.Close()
+
+		node.release <- struct{}{}
+		assert.NoError(t, <-errs)
+
+		// new calls use the new connection
+		go func() { errs <- c.Ping(ctx) }()
+		<-node.received
+		node.release <- struct{}{}
+		assert.NoError(t, <-errs)
+	})
+
+	t.Run("Calls in flight fail after the drain timeout", func(t *testing.T) {
+		c, errs := reconnect(t, 20*time.Millisecond)
+		defer c.Close()
+
+		select {
+		case err := <-errs:
+			assert.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("call still in flight after the drain timeout")
+		}
+	})
+}
+
+func TestNewClient_Reconnect(t *testing.T) {
+	_, err := client.NewClient(
+		"127.0.0.1:0",
+		client.WithInsecure(),
+		client.WithConnections(2),
+		client.WithReconnect(client.ReconnectConfig{}),
+	)
+	assert.Error(t, err)
+}
@@ -0,0 +1,330 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// TransactionExpiry is the number of blocks after its reference block within which
+// a transaction must be included, or expire.
+const TransactionExpiry = 600
+
+// DefaultReplayTTL is the time a ReplayRegistry remembers a submitted transaction:
+// the expiry window of TransactionExpiry blocks, at one block per second. Past this
+// time, the transaction cannot be executed in any environment.
+const DefaultReplayTTL = TransactionExpiry * time.Second
+
+// ErrReplay matches the errors of transactions refused because they were already
+// submitted to another environment.
+var ErrReplay = errcode.New(errcode.ReplayDetected, "client: transaction replayed across environments")
+
+// A ReplayError is returned when a transaction is submitted to an environment after
+// it was submitted to another one.
+//
+// Use errors.Is(err, ErrReplay) to test for it.
+type ReplayError struct {
+	// Environment is the environment the transaction was refused for.
+	Environment string
+	// Previous is the record of the earlier submission.
+	Previous ReplayEntry
+}
+
+func (e *ReplayError) Error() string {
+	return errorMessage(
+		"transaction %s was already submitted to %s, refusing to submit it to %s",
+		e.Previous.TransactionID,
+		e.Previous.Environment,
+		e.Environment,
+	)
+}
+
+func (e *ReplayError) Unwrap() error {
+	return ErrReplay
+}
+
+// ErrorCode returns the code of ErrReplay.
+func (e *ReplayError) ErrorCode() errcode.Code {
+	return errcode.ReplayDetected
+}
+
+// A ReplayEntry records the submission of a transaction to an environment.
+type ReplayEntry struct {
+	TransactionID flow.Identifier `json:"transactionID"`
+	Environment   string          `json:"environment"`
+	ExpiresAt     time.Time       `json:"expiresAt"`
+}
+
+// A ReplayStore records the transactions submitted to all environments.
+//
+// A store is shared by the registries of all environments, e.g. as a file or a
+// database table, so that it detects a transaction sent to the wrong one.
+type ReplayStore interface {
+	// Record adds the entry, unless an entry of the same transaction that has not
+	// expired at now exists for another environment, in which case that entry is
+	// returned with false. Entries that expired at now may be discarded.
+	Record(entry ReplayEntry, now time.Time) (ReplayEntry, bool, error)
+}
+
+// replayEntries are the entries of a store, by transaction ID.
+type replayEntries map[flow.Identifier]ReplayEntry
+
+// record adds the entry to the entries as described by ReplayStore, and returns
+// true if the entries changed.
+func (e replayEntries) record(entry ReplayEntry, now time.Time) (ReplayEntry, bool, bool) {
+	changed := false
+
+	for id, recorded := range e {
+		if !recorded.ExpiresAt.After(now) {
+			delete(e, id)
+			changed = true
+		}
+	}
+
+	recorded, ok := e[entry.TransactionID]
+	if ok && recorded.Environment != entry.Environment {
+		return recorded, false, changed
+	}
+
+	if ok && !entry.ExpiresAt.After(recorded.ExpiresAt) {
+		return entry, true, changed
+	}
+
+	e[entry.TransactionID] = entry
+	return entry, true, true
+}
+
+// MemoryReplayStore is a ReplayStore that keeps entries in memory, e.g. for the
+// clients of several environments in a single process.
+type MemoryReplayStore struct {
+	mu      sync.Mutex
+	entries replayEntries
+}
+
+var _ ReplayStore = &MemoryReplayStore{}
+
+// NewMemoryReplayStore returns an empty in-memory replay store.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{
+		entries: make(replayEntries),
+	}
+}
+
+func (s *MemoryReplayStore) Record(entry ReplayEntry, now time.Time) (ReplayEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded, ok, _ := s.entries.record(entry, now)
+	return recorded, ok, nil
+}
+
+// FileReplayStore is a ReplayStore that persists entries to a JSON file, so that
+// they survive restarts and can be shared by the processes of a host.
+//
+// The file is replaced atomically by renaming a temporary file. Processes sharing the
+// file must not record entries concurrently.
+type FileReplayStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+var _ ReplayStore = &FileReplayStore{}
+
+// NewFileReplayStore returns a store that persists entries to the file at the given
+// path, which is created on the first submission.
+func NewFileReplayStore(path string) *FileReplayStore {
+	return &FileReplayStore{path: path}
+}
+
+func (s *FileReplayStore) Record(entry ReplayEntry, now time.Time) (ReplayEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return ReplayEntry{}, false, err
+	}
+
+	recorded, ok, changed := entries.record(entry, now)
+	if changed {
+		if err := s.save(entries); err != nil {
+			return ReplayEntry{}, false, err
+		}
+	}
+
+	return recorded, ok, nil
+}
+
+func (s *FileReplayStore) load() (replayEntries, error) {
+	entries := make(replayEntries)
+
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []ReplayEntry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("client: invalid replay store %s: %w", s.path, err)
+	}
+
+	for _, entry := range list {
+		entries[entry.TransactionID] = entry
+	}
+
+	return entries, nil
+}
+
+func (s *FileReplayStore) save(entries replayEntries) error {
+	list := make([]ReplayEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// A ReplayRegistry protects an environment, e.g. mainnet or a staging network,
+// from transactions that were already submitted to another environment, e.g. when
+// the configurations of two deployments get mixed up.
+//
+// Each transaction submitted through the registry is recorded in a store shared with
+// the registries of other environments, for the time the transaction may still be
+// executed. Submitting the same transaction again to the same environment is
+// allowed.
+type ReplayRegistry struct {
+	environment string
+	ttl         time.Duration
+	store       ReplayStore
+}
+
+// NewReplayRegistry returns a registry for the named environment that records
+// transactions in the store for the given time, or DefaultReplayTTL if ttl is zero
+// or less.
+//
+// If store is nil, transactions are recorded in memory.
+func NewReplayRegistry(environment string, ttl time.Duration, store ReplayStore) *ReplayRegistry {
+	if ttl <= 0 {
+		ttl = DefaultReplayTTL
+	}
+	if store == nil {
+		store = NewMemoryReplayStore()
+	}
+
+	return &ReplayRegistry{
+		environment: environment,
+		ttl:         ttl,
+		store:       store,
+	}
+}
+
+// Record records the submission of a transaction to the environment of the
+// registry, or returns a *ReplayError if it was submitted to another environment
+// within the TTL.
+func (r *ReplayRegistry) Record(txID flow.Identifier) error {
+	now := time.Now()
+
+	recorded, ok, err := r.store.Record(ReplayEntry{
+		TransactionID: txID,
+		Environment:   r.environment,
+		ExpiresAt:     now.Add(r.ttl),
+	}, now)
+	if err != nil {
+		return fmt.Errorf("client: failed to write replay store: %w", err)
+	}
+
+	if !ok {
+		return &ReplayError{Environment: r.environment, Previous: recorded}
+	}
+
+	return nil
+}
+
+// Middleware returns a middleware that records each transaction sent with
+// SendTransaction before it is sent, and refuses to send transactions replayed from
+// another environment.
+//
+// A transaction is recorded even if sending it fails, as it may still have reached
+// the network.
+func (r *ReplayRegistry) Middleware() Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			if req, ok := args.(*access.SendTransactionRequest); ok && method == MethodSendTransaction {
+				tx, err := convert.MessageToTransaction(req.GetTransaction())
+				if err != nil {
+					return newMessageToEntityError(entityTransaction, err)
+				}
+
+				if err := r.Record(tx.ID()); err != nil {
+					return err
+				}
+			}
+
+			return next.Invoke(ctx, method, args, reply, opts...)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+func TestReplayRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	stores := map[string]func() client.ReplayStore{
+		"Memory": func() client.ReplayStore {
+			return client.NewMemoryReplayStore()
+		},
+		"File": func() client.ReplayStore {
+			return client.NewFileReplayStore(filepath.Join(dir, "replay.json"))
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+
+			mainnet := client.NewReplayRegistry("mainnet", time.Hour, store)
+			testnet := client.NewReplayRegistry("testnet", time.Hour, store)
+
+			txID := flow.HexToID("01")
+
+			require.NoError(t, mainnet.Record(txID))
+			require.NoError(t, mainnet.Record(txID))
+
+			err := testnet.Record(txID)
+			assert.True(t, errors.Is(err, client.ErrReplay))
+			assert.Equal(t, errcode.ReplayDetected, errcode.Of(err))
+
+			var replayErr *client.ReplayError
+			require.True(t, errors.As(err, &replayErr))
+			assert.Equal(t, "testnet", replayErr.Environment)
+			assert.Equal(t, "mainnet", replayErr.Previous.Environment)
+			assert.Equal(t, txID, replayErr.Previous.TransactionID)
+
+			assert.NoError(t, testnet.Record(flow.HexToID("02")))
+
+			// entries expire after the TTL
+			short := client.NewReplayRegistry("staging", time.Millisecond, store)
+			require.NoError(t, short.Record(flow.HexToID("03")))
+			time.Sleep(5 * time.Millisecond)
+			assert.NoError(t, testnet.Record(flow.HexToID("03")))
+		})
+	}
+
+	t.Run("File persists entries", func(t *testing.T) {
+		path := filepath.Join(dir, "persisted.json")
+
+		require.NoError(t, client.NewReplayRegistry("mainnet", 0, client.NewFileReplayStore(path)).Record(flow.HexToID("01")))
+
+		err := client.NewReplayRegistry("testnet", 0, client.NewFileReplayStore(path)).Record(flow.HexToID("01"))
+		assert.True(t, errors.Is(err, client.ErrReplay))
+
+		require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+		err = client.NewReplayRegistry("testnet", 0, client.NewFileReplayStore(path)).Record(flow.HexToID("01"))
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, client.ErrReplay))
+	})
+}
+
+func TestReplayRegistry_Middleware(t *testing.T) {
+	ctx := context.Background()
+	store := client.NewMemoryReplayStore()
+
+	var sent int
+	node := client.InvokerFunc(func(_ context.Context, method string, _, _ interface{}, _ ...grpc.CallOption) error {
+		if method == client.MethodSendTransaction {
+			sent++
+		}
+		return nil
+	})
+
+	newClient := func(environment string) *client.Client {
+		registry := client.NewReplayRegistry(environment, 0, store)
+		return client.NewFromEndpoint(client.NewEndpoint(environment, client.Chain(node, registry.Middleware())))
+	}
+
+	mainnet := newClient("mainnet")
+	testnet := newClient("testnet")
+
+	tx := flow.NewTransaction().
+		SetScript([]byte("transaction {}")).
+		SetProposalKey(flow.HexToAddress("01"), 0, 1).
+		SetPayer(flow.HexToAddress("01"))
+
+	require.NoError(t, mainnet.SendTransaction(ctx, *tx))
+	require.NoError(t, mainnet.SendTransaction(ctx, *tx))
+
+	err := testnet.SendTransaction(ctx, *tx)
+	assert.True(t, errors.Is(err, client.ErrReplay))
+	assert.Equal(t, errcode.ReplayDetected, errcode.Of(err))
+	assert.Equal(t, 2, sent)
+}
@@ -0,0 +1,276 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// Default values of a ReplenishConfig.
+const (
+	DefaultReplenishBatchSize = 10
+	DefaultReplenishInterval  = time.Minute
+)
+
+// ReplenishConfig configures the automatic addition of keys to a ProposerKeyPool.
+//
+// Keys are added when a lease waits because all keys are leased, or when a lease is
+// reclaimed because it was never returned, as a key locked up this way is unusable
+// until the lease timeout. Keys are added in batches, in a single transaction that
+// the authorizer key of the pool proposes, authorizes and pays for.
+type ReplenishConfig struct {
+	// MaxKeys is the maximum number of keys of the pool. Keys are not added
+	// automatically if MaxKeys is zero.
+	MaxKeys int
+	// BatchSize is the number of keys added at once.
+	//
+	// Defaults to DefaultReplenishBatchSize.
+	BatchSize int
+	// MinInterval is the minimum time between the end of an automatic addition of
+	// keys and the start of the next one.
+	//
+	// Defaults to DefaultReplenishInterval.
+	MinInterval time.Duration
+}
+
+func (c ReplenishConfig) withDefaults() ReplenishConfig {
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultReplenishBatchSize
+	}
+	if c.MinInterval <= 0 {
+		c.MinInterval = DefaultReplenishInterval
+	}
+	return c
+}
+
+// A ReplenishReason is the reason keys are added to a pool automatically.
+type ReplenishReason string
+
+const (
+	// ReplenishExhausted means that all keys of the pool were leased.
+	ReplenishExhausted ReplenishReason = "exhausted"
+	// ReplenishLockUp means that a lease was not returned before the lease timeout.
+	ReplenishLockUp ReplenishReason = "lock-up"
+)
+
+// A PoolEventKind is the kind of a PoolEvent.
+type PoolEventKind string
+
+const (
+	// PoolEventLeaseReclaimed means that a lease was reclaimed after the lease timeout.
+	PoolEventLeaseReclaimed PoolEventKind = "lease-reclaimed"
+	// PoolEventReplenishing means that a transaction that adds keys is sent.
+	PoolEventReplenishing PoolEventKind = "replenishing"
+	// PoolEventReplenished means that keys were added to the pool.
+	PoolEventReplenished PoolEventKind = "replenished"
+	// PoolEventReplenishFailed means that keys could not be added to the pool.
+	PoolEventReplenishFailed PoolEventKind = "replenish-failed"
+	// PoolEventCapReached means that keys are needed but the pool has MaxKeys keys.
+	PoolEventCapReached PoolEventKind = "cap-reached"
+)
+
+// A PoolEvent is an audit event of a ProposerKeyPool.
+type PoolEvent struct {
+	Kind    PoolEventKind
+	Time    time.Time
+	Address flow.Address
+	// Size is the number of keys of the pool.
+	Size int
+	// KeyIndex is the index of the key of a reclaimed lease.
+	KeyIndex int
+	// Reason is the reason keys are added.
+	Reason ReplenishReason
+	// Keys is the number of keys added.
+	Keys int
+	// TransactionID is the ID of the transaction that adds keys, once it is sent.
+	TransactionID flow.Identifier
+	// Err is the reason keys could not be added.
+	Err error
+}
+
+// reclaim returns the key of a lease that reached the lease timeout to the pool.
+func (l *ProposerKeyLease) reclaim() {
+	p := l.pool
+	if !p.put(l, markStale) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.emit(PoolEvent{
+		Kind:     PoolEventLeaseReclaimed,
+		Size:     len(p.keys),
+		KeyIndex: l.KeyIndex,
+	})
+
+	p.replenish(ReplenishLockUp)
+}
+
+// replenish adds keys to the pool in the background, unless keys are already being
+// added, keys were added less than MinInterval ago, the pool is full or closed.
+//
+// It must be called with p.mu held.
+func (p *ProposerKeyPool) replenish(reason ReplenishReason) {
+	config := p.config.Replenish
+	if config.MaxKeys == 0 || p.replenishing || p.ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+	if !p.lastReplenish.IsZero() && now.Sub(p.lastReplenish) < config.MinInterval {
+		return
+	}
+
+	p.lastReplenish = now
+	size := len(p.keys)
+
+	n := config.MaxKeys - size
+	if n > config.BatchSize {
+		n = config.BatchSize
+	}
+
+	if n <= 0 {
+		p.emit(PoolEvent{
+			Kind:   PoolEventCapReached,
+			Size:   size,
+			Reason: reason,
+		})
+		return
+	}
+
+	p.replenishing = true
+
+	p.emit(PoolEvent{
+		Kind:   PoolEventReplenishing,
+		Size:   size,
+		Reason: reason,
+		Keys:   n,
+	})
+
+	p.replenishers.Add(1)
+
+	go func() {
+		defer p.replenishers.Done()
+
+		// the transaction that adds keys is sealed or expired within the lease timeout
+		ctx, cancel := context.WithTimeout(p.ctx, p.config.LeaseTimeout)
+		defer cancel()
+
+		txID, err := p.addKeys(ctx, n)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.replenishing = false
+		p.lastReplenish = time.Now()
+
+		event := PoolEvent{
+			Kind:          PoolEventReplenished,
+			Size:          len(p.keys),
+			Reason:        reason,
+			Keys:          n,
+			TransactionID: txID,
+		}
+
+		if err != nil {
+			event.Kind = PoolEventReplenishFailed
+			event.Err = err
+		}
+
+		p.emit(event)
+	}()
+}
+
+// Close stops the automatic addition of keys, cancels an addition in progress and
+// waits for it to return, and delivers the remaining events to OnEvent.
+//
+// Leases can still be taken and returned after the pool is closed. Close must not be
+// called from OnEvent.
+func (p *ProposerKeyPool) Close() error {
+	p.closeOnce.Do(func() {
+		// replenish checks the context with p.mu held
+		p.mu.Lock()
+		p.cancel()
+		p.mu.Unlock()
+
+		p.replenishers.Wait()
+
+		p.eventMu.Lock()
+		p.eventsClosed = true
+		p.eventMu.Unlock()
+
+		select {
+		case p.eventReady <- struct{}{}:
+		default:
+		}
+	})
+
+	<-p.eventsDone
+	return nil
+}
+
+// emit queues an event for delivery to OnEvent. Events are delivered in the order
+// they are queued, and dropped once the pool is closed.
+func (p *ProposerKeyPool) emit(event PoolEvent) {
+	if p.config.OnEvent == nil {
+		return
+	}
+
+	p.eventMu.Lock()
+	defer p.eventMu.Unlock()
+
+	if p.eventsClosed {
+		return
+	}
+
+	event.Time = time.Now()
+	event.Address = p.config.Address
+
+	p.events = append(p.events, event)
+
+	select {
+	case p.eventReady <- struct{}{}:
+	default:
+	}
+}
+
+// deliverEvents passes the queued events to OnEvent until the pool is closed and
+// its last events are delivered.
+func (p *ProposerKeyPool) deliverEvents() {
+	defer close(p.eventsDone)
+
+	for range p.eventReady {
+		p.eventMu.Lock()
+		events, closed := p.events, p.eventsClosed
+		p.events = nil
+		p.eventMu.Unlock()
+
+		for _, event := range events {
+			p.config.OnEvent(event)
+		}
+
+		if closed {
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestRequestID(t *testing.T) {
+	var received []string
+
+	invoker := client.InvokerFunc(func(
+		ctx context.Context,
+		_ string,
+		_ interface{},
+		_ interface{},
+		_ ...grpc.CallOption,
+	) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		received = md.Get(client.RequestIDMetadataKey)
+		return errInternal
+	})
+
+	c := client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+
+	id := client.NewRequestID()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, client.NewRequestID())
+
+	ctx := client.WithRequestID(context.Background(), id)
+	assert.Equal(t, id, client.RequestID(ctx))
+
+	_, err := c.GetLatestBlockHeader(ctx, true)
+	require.Error(t, err)
+
+	assert.Equal(t, []string{id}, received)
+	assert.Contains(t, err.Error(), "(request ID "+id+")")
+
+	var rpcErr client.RPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, id, rpcErr.RequestID)
+
+	t.Run("Without request ID", func(t *testing.T) {
+		_, err := c.GetLatestBlockHeader(context.Background(), true)
+		require.Error(t, err)
+
+		assert.Empty(t, received)
+		assert.Equal(t, "", client.RequestID(context.Background()))
+		assert.NotContains(t, err.Error(), "request ID")
+	})
+}
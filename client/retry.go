@@ -0,0 +1,346 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"google.golang.org/grpc"
+)
+
+// WithRetry returns a client sharing the connection of c that retries calls that
+// fail because the Access API is unavailable, overloaded or timed out, until a call
+// succeeds, fails otherwise, or maxAttempts calls were made.
+//
+// The delay before the first retry is drawn at random between backoff/2 and backoff,
+// and the upper bound doubles before each further retry, up to DefaultMaxBackoff. A
+// longer delay suggested by the Access API with a backpressure error is respected.
+// Retries stop when the context of the call is done.
+//
+// Sending a transaction again is safe, as the network executes a transaction at most
+// once. Note that SubmitTransaction retries on its own, within its retry budget, on
+// top of the retries of the client.
+func (c *Client) WithRetry(maxAttempts int, backoff time.Duration) *Client {
+	return c.WithRetryBudget(RetryBudget{
+		MaxAttempts: maxAttempts,
+		Backoff:     backoff,
+	})
+}
+
+// WithRetryBudget is WithRetry with the attempts, delays and elapsed time of the
+// retries limited by a budget. No call is retried past the MaxElapsed of the budget.
+func (c *Client) WithRetryBudget(budget RetryBudget) *Client {
+	retrying := *c
+	retrying.rpcClient = &retryRPCClient{
+		rpcClient: c.rpcClient,
+		budget:    budget.withDefaults(),
+	}
+	return &retrying
+}
+
+// retryRPCClient is an RPC client that retries failed calls of another.
+type retryRPCClient struct {
+	rpcClient RPCClient
+	budget    RetryBudget
+}
+
+var _ RPCClient = &retryRPCClient{}
+
+// do calls call until it succeeds, fails with an error that is not retryable, or
+// the attempts are exhausted, and returns the last error.
+//
+// Retries are called with a context that marks them for metrics.
+func (c *retryRPCClient) do(ctx context.Context, call func(ctx context.Context) error) error {
+	budget := c.budget
+	backoff := budget.Backoff
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		callCtx := ctx
+		if attempt > 1 {
+			callCtx = withRetry(ctx)
+		}
+
+		err := call(callCtx)
+		if err == nil || attempt >= budget.MaxAttempts || !isRetryable(ctx, err) {
+			return err
+		}
+
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if retryAfter, ok := RetryAfter(err); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+
+		if budget.MaxElapsed > 0 && time.Since(start)+delay > budget.MaxElapsed {
+			return err
+		}
+
+		if sleep(ctx, delay) != nil {
+			return err
+		}
+
+		backoff *= 2
+		if backoff > budget.MaxBackoff {
+			backoff = budget.MaxBackoff
+		}
+	}
+}
+
+func (c *retryRPCClient) Ping(
+	ctx context.Context,
+	in *access.PingRequest,
+	opts ...grpc.CallOption,
+) (res *access.PingResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.Ping(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetLatestBlockHeader(
+	ctx context.Context,
+	in *access.GetLatestBlockHeaderRequest,
+	opts ...grpc.CallOption,
+) (res *access.BlockHeaderResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetLatestBlockHeader(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetBlockHeaderByID(
+	ctx context.Context,
+	in *access.GetBlockHeaderByIDRequest,
+	opts ...grpc.CallOption,
+) (res *access.BlockHeaderResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetBlockHeaderByID(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetBlockHeaderByHeight(
+	ctx context.Context,
+	in *access.GetBlockHeaderByHeightRequest,
+	opts ...grpc.CallOption,
+) (res *access.BlockHeaderResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetBlockHeaderByHeight(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetLatestBlock(
+	ctx context.Context,
+	in *access.GetLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (res *access.BlockResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetLatestBlock(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetBlockByID(
+	ctx context.Context,
+	in *access.GetBlockByIDRequest,
+	opts ...grpc.CallOption,
+) (res *access.BlockResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetBlockByID(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetBlockByHeight(
+	ctx context.Context,
+	in *access.GetBlockByHeightRequest,
+	opts ...grpc.CallOption,
+) (res *access.BlockResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetBlockByHeight(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetCollectionByID(
+	ctx context.Context,
+	in *access.GetCollectionByIDRequest,
+	opts ...grpc.CallOption,
+) (res *access.CollectionResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetCollectionByID(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) SendTransaction(
+	ctx context.Context,
+	in *access.SendTransactionRequest,
+	opts ...grpc.CallOption,
+) (res *access.SendTransactionResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.SendTransaction(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetTransaction(
+	ctx context.Context,
+	in *access.GetTransactionRequest,
+	opts ...grpc.CallOption,
+) (res *access.TransactionResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetTransaction(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetTransactionResult(
+	ctx context.Context,
+	in *access.GetTransactionRequest,
+	opts ...grpc.CallOption,
+) (res *access.TransactionResultResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetTransactionResult(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetAccount(
+	ctx context.Context,
+	in *access.GetAccountRequest,
+	opts ...grpc.CallOption,
+) (res *access.GetAccountResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetAccount(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetAccountAtLatestBlock(
+	ctx context.Context,
+	in *access.GetAccountAtLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (res *access.AccountResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetAccountAtLatestBlock(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetAccountAtBlockHeight(
+	ctx context.Context,
+	in *access.GetAccountAtBlockHeightRequest,
+	opts ...grpc.CallOption,
+) (res *access.AccountResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetAccountAtBlockHeight(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) ExecuteScriptAtLatestBlock(
+	ctx context.Context,
+	in *access.ExecuteScriptAtLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (res *access.ExecuteScriptResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.ExecuteScriptAtLatestBlock(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) ExecuteScriptAtBlockID(
+	ctx context.Context,
+	in *access.ExecuteScriptAtBlockIDRequest,
+	opts ...grpc.CallOption,
+) (res *access.ExecuteScriptResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.ExecuteScriptAtBlockID(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) ExecuteScriptAtBlockHeight(
+	ctx context.Context,
+	in *access.ExecuteScriptAtBlockHeightRequest,
+	opts ...grpc.CallOption,
+) (res *access.ExecuteScriptResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.ExecuteScriptAtBlockHeight(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetEventsForHeightRange(
+	ctx context.Context,
+	in *access.GetEventsForHeightRangeRequest,
+	opts ...grpc.CallOption,
+) (res *access.EventsResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetEventsForHeightRange(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetEventsForBlockIDs(
+	ctx context.Context,
+	in *access.GetEventsForBlockIDsRequest,
+	opts ...grpc.CallOption,
+) (res *access.EventsResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetEventsForBlockIDs(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryRPCClient) GetNetworkParameters(
+	ctx context.Context,
+	in *access.GetNetworkParametersRequest,
+	opts ...grpc.CallOption,
+) (res *access.GetNetworkParametersResponse, err error) {
+	err = c.do(ctx, func(ctx context.Context) error {
+		res, err = c.rpcClient.GetNetworkParameters(ctx, in, opts...)
+		return err
+	})
+	return res, err
+}
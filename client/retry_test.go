@@ -0,0 +1,164 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// scriptNode is an Access API node that fails the first script executions with the
+// given error.
+type scriptNode struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (n *scriptNode) Invoke(
+	_ context.Context,
+	_ string,
+	_ interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	n.calls++
+	if n.calls <= n.failures {
+		return n.err
+	}
+
+	reply.(*access.ExecuteScriptResponse).Value = jsoncdc.MustEncode(cadence.NewInt(42))
+	return nil
+}
+
+func TestClient_WithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Retries transient errors", func(t *testing.T) {
+		node := &scriptNode{failures: 2, err: status.Error(codes.ResourceExhausted, "rate limited")}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetry(3, time.Millisecond)
+
+		value, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewInt(42), value)
+		assert.Equal(t, 3, node.calls)
+	})
+
+	t.Run("Stops after max attempts", func(t *testing.T) {
+		node := &scriptNode{failures: 5, err: status.Error(codes.Unavailable, "down")}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetry(3, time.Millisecond)
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 3, node.calls)
+	})
+
+	t.Run("Does not retry rejected calls", func(t *testing.T) {
+		node := &scriptNode{failures: 1, err: status.Error(codes.InvalidArgument, "invalid script")}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetry(3, time.Millisecond)
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, 1, node.calls)
+	})
+
+	t.Run("Respects retry-after", func(t *testing.T) {
+		backpressure, err := status.New(codes.ResourceExhausted, "overloaded").
+			WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(30 * time.Millisecond)})
+		require.NoError(t, err)
+
+		node := &scriptNode{failures: 1, err: backpressure.Err()}
+
+		_, err = client.NewFromEndpoint(client.NewEndpoint("node", node)).
+			ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		assert.True(t, errors.Is(err, client.ErrBackpressure))
+		assert.Equal(t, errcode.RPCBackpressure, errcode.Of(err))
+
+		retryAfter, ok := client.RetryAfter(err)
+		assert.True(t, ok)
+		assert.Equal(t, 30*time.Millisecond, retryAfter)
+
+		node.calls = 0
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetry(2, time.Millisecond)
+
+		start := time.Now()
+		_, err = c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(30*time.Millisecond))
+	})
+
+	t.Run("Caps the backoff", func(t *testing.T) {
+		node := &scriptNode{failures: 15, err: status.Error(codes.Unavailable, "down")}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetryBudget(client.RetryBudget{
+			MaxAttempts: 16,
+			Backoff:     time.Millisecond,
+			MaxBackoff:  time.Millisecond,
+		})
+
+		// uncapped, the delays would add up to more than a minute
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 16, node.calls)
+	})
+
+	t.Run("Stops past the elapsed budget", func(t *testing.T) {
+		node := &scriptNode{failures: 5, err: status.Error(codes.Unavailable, "down")}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetryBudget(client.RetryBudget{
+			MaxAttempts: 5,
+			Backoff:     time.Hour,
+			MaxElapsed:  time.Second,
+		})
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, []byte("script"), nil)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, node.calls)
+	})
+
+	t.Run("Stops when the context is done", func(t *testing.T) {
+		node := newFlakyNode(5, status.Error(codes.Unavailable, "down"))
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node)).WithRetry(5, time.Hour)
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		err := c.SendTransaction(ctx, *flow.NewTransaction())
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 1, node.sends)
+	})
+}
@@ -0,0 +1,274 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+)
+
+// ScoringConfig configures a ScoringRouter.
+type ScoringConfig struct {
+	// ProbeInterval is the time between two measurements of each endpoint.
+	//
+	// Defaults to 10 seconds.
+	ProbeInterval time.Duration
+	// ProbeTimeout is the maximum duration of a single measurement.
+	//
+	// Defaults to 5 seconds.
+	ProbeTimeout time.Duration
+	// MaxHeightLag is the number of blocks an endpoint may lag behind the highest
+	// observed sealed height and still be eligible to serve reads.
+	MaxHeightLag uint64
+}
+
+const (
+	defaultProbeInterval = 10 * time.Second
+	defaultProbeTimeout  = 5 * time.Second
+
+	// latencySmoothing is the weight given to a new latency sample.
+	latencySmoothing = 0.3
+)
+
+// An EndpointScore is the latest measurement of an endpoint.
+type EndpointScore struct {
+	Address string
+	// Latency is the smoothed round-trip latency of the endpoint.
+	Latency time.Duration
+	// Height is the latest sealed block height reported by the endpoint.
+	Height uint64
+	// Healthy is false if the latest measurement of the endpoint failed.
+	Healthy bool
+	// LastProbe is the time of the latest measurement.
+	LastProbe time.Time
+}
+
+// A ScoringRouter periodically measures the latency and latest sealed height of each
+// endpoint, routes reads to the best endpoint and pins writes to a single endpoint.
+//
+// The best endpoint is the one with the lowest latency among the healthy endpoints
+// that are at most MaxHeightLag blocks behind the highest observed sealed height.
+//
+// Writes stay pinned to the same endpoint for as long as it is healthy, so that
+// transactions submitted in sequence are received by the same node.
+type ScoringRouter struct {
+	endpoints []*Endpoint
+	config    ScoringConfig
+
+	mu     sync.RWMutex
+	scores []EndpointScore
+	read   int
+	write  int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ Router = &ScoringRouter{}
+
+// NewScoringRouter returns a router over the given endpoints and starts measuring
+// them in the background.
+//
+// The first endpoint serves all calls until the first measurement completes.
+func NewScoringRouter(endpoints []*Endpoint, config ScoringConfig) *ScoringRouter {
+	if config.ProbeInterval == 0 {
+		config.ProbeInterval = defaultProbeInterval
+	}
+
+	if config.ProbeTimeout == 0 {
+		config.ProbeTimeout = defaultProbeTimeout
+	}
+
+	scores := make([]EndpointScore, len(endpoints))
+	for i, endpoint := range endpoints {
+		scores[i] = EndpointScore{
+			Address: endpoint.Address,
+			Healthy: true,
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := &ScoringRouter{
+		endpoints: endpoints,
+		config:    config,
+		scores:    scores,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	go r.run(ctx)
+
+	return r
+}
+
+func (r *ScoringRouter) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		r.Probe(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Route returns the pinned endpoint for writes and the best endpoint for reads.
+func (r *ScoringRouter) Route(_ context.Context, method string) (*Endpoint, error) {
+	if len(r.endpoints) == 0 {
+		return nil, ErrNoEndpoint
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if IsWriteMethod(method) {
+		return r.endpoints[r.write], nil
+	}
+
+	return r.endpoints[r.read], nil
+}
+
+// Probe measures all endpoints once and updates the routing decisions.
+func (r *ScoringRouter) Probe(ctx context.Context) {
+	type measurement struct {
+		latency time.Duration
+		height  uint64
+		err     error
+	}
+
+	measurements := make([]measurement, len(r.endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range r.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *Endpoint) {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, r.config.ProbeTimeout)
+			defer cancel()
+
+			req := &access.GetLatestBlockHeaderRequest{IsSealed: true}
+			res := new(access.BlockHeaderResponse)
+
+			start := time.Now()
+			err := endpoint.Invoker.Invoke(probeCtx, MethodGetLatestBlockHeader, req, res)
+
+			measurements[i] = measurement{
+				latency: time.Since(start),
+				height:  res.GetBlock().GetHeight(),
+				err:     err,
+			}
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, m := range measurements {
+		score := &r.scores[i]
+		score.LastProbe = now
+
+		if m.err != nil {
+			score.Healthy = false
+			continue
+		}
+
+		score.Healthy = true
+		score.Height = m.height
+
+		if score.Latency == 0 {
+			score.Latency = m.latency
+		} else {
+			score.Latency = time.Duration(
+				(1-latencySmoothing)*float64(score.Latency) + latencySmoothing*float64(m.latency),
+			)
+		}
+	}
+
+	r.updateRoutes()
+}
+
+// updateRoutes selects the read and write endpoints from the current scores.
+//
+// The caller must hold the write lock.
+func (r *ScoringRouter) updateRoutes() {
+	var maxHeight uint64
+	for _, score := range r.scores {
+		if score.Healthy && score.Height > maxHeight {
+			maxHeight = score.Height
+		}
+	}
+
+	best := -1
+	for i, score := range r.scores {
+		if !score.Healthy || score.Height+r.config.MaxHeightLag < maxHeight {
+			continue
+		}
+
+		if best == -1 || score.Latency < r.scores[best].Latency {
+			best = i
+		}
+	}
+
+	// keep the previous routes if no endpoint is currently eligible
+	if best == -1 {
+		return
+	}
+
+	r.read = best
+
+	if !r.scores[r.write].Healthy {
+		r.write = best
+	}
+}
+
+// Scores returns the latest measurement of each endpoint.
+func (r *ScoringRouter) Scores() []EndpointScore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := make([]EndpointScore, len(r.scores))
+	copy(scores, r.scores)
+
+	return scores
+}
+
+// Close stops measuring endpoints and closes their connections.
+func (r *ScoringRouter) Close() error {
+	r.cancel()
+	<-r.done
+	return closeEndpoints(r.endpoints)
+}
@@ -0,0 +1,163 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// fakeNode is an Access API node that reports a fixed sealed height.
+type fakeNode struct {
+	height  uint64
+	delay   time.Duration
+	healthy int32
+	calls   int32
+}
+
+func newFakeNode(height uint64, delay time.Duration) *fakeNode {
+	return &fakeNode{height: height, delay: delay, healthy: 1}
+}
+
+func (n *fakeNode) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	atomic.StoreInt32(&n.healthy, v)
+}
+
+func (n *fakeNode) Invoke(
+	_ context.Context,
+	method string,
+	_ interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	atomic.AddInt32(&n.calls, 1)
+
+	time.Sleep(n.delay)
+
+	if atomic.LoadInt32(&n.healthy) == 0 {
+		return errInternal
+	}
+
+	if method == client.MethodGetLatestBlockHeader {
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: n.height}
+	}
+
+	return nil
+}
+
+func TestScoringRouter(t *testing.T) {
+	ctx := context.Background()
+
+	route := func(t *testing.T, r client.Router, method string) string {
+		endpoint, err := r.Route(ctx, method)
+		require.NoError(t, err)
+		return endpoint.Address
+	}
+
+	t.Run("Reads use fastest node, writes stay pinned", func(t *testing.T) {
+		slow := newFakeNode(100, 30*time.Millisecond)
+		fast := newFakeNode(100, 0)
+
+		r := client.NewScoringRouter(
+			[]*client.Endpoint{
+				client.NewEndpoint("slow", slow),
+				client.NewEndpoint("fast", fast),
+			},
+			client.ScoringConfig{ProbeInterval: time.Hour},
+		)
+		defer r.Close()
+
+		r.Probe(ctx)
+
+		assert.Equal(t, "fast", route(t, r, client.MethodGetAccountAtLatestBlock))
+		assert.Equal(t, "slow", route(t, r, client.MethodSendTransaction))
+
+		slow.setHealthy(false)
+		r.Probe(ctx)
+
+		assert.Equal(t, "fast", route(t, r, client.MethodSendTransaction))
+
+		slow.setHealthy(true)
+		r.Probe(ctx)
+
+		// writes remain pinned to the new node
+		assert.Equal(t, "fast", route(t, r, client.MethodSendTransaction))
+	})
+
+	t.Run("Lagging nodes are skipped", func(t *testing.T) {
+		lagging := newFakeNode(90, 0)
+		current := newFakeNode(100, 20*time.Millisecond)
+
+		r := client.NewScoringRouter(
+			[]*client.Endpoint{
+				client.NewEndpoint("lagging", lagging),
+				client.NewEndpoint("current", current),
+			},
+			client.ScoringConfig{ProbeInterval: time.Hour, MaxHeightLag: 5},
+		)
+		defer r.Close()
+
+		r.Probe(ctx)
+
+		assert.Equal(t, "current", route(t, r, client.MethodExecuteScriptAtLatestBlock))
+
+		scores := r.Scores()
+		require.Len(t, scores, 2)
+		assert.Equal(t, uint64(90), scores[0].Height)
+		assert.Equal(t, uint64(100), scores[1].Height)
+	})
+
+	t.Run("Client sends calls through router", func(t *testing.T) {
+		node := newFakeNode(42, 0)
+
+		r := client.NewScoringRouter(
+			[]*client.Endpoint{client.NewEndpoint("node", node)},
+			client.ScoringConfig{ProbeInterval: time.Hour},
+		)
+
+		c := client.NewFromRouter(r)
+		defer c.Close()
+
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), header.Height)
+	})
+
+	t.Run("No endpoints", func(t *testing.T) {
+		r := client.NewScoringRouter(nil, client.ScoringConfig{})
+		defer r.Close()
+
+		_, err := r.Route(ctx, client.MethodPing)
+		assert.Equal(t, client.ErrNoEndpoint, err)
+	})
+}
@@ -0,0 +1,154 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// A ScriptError is returned when the Cadence runtime fails to execute a script, e.g.
+// because of a type error, a failed precondition or a panic.
+//
+// Unlike the RPCError of a transport failure, a script error is a property of the
+// script, its arguments and the state it reads: executing the script again at the
+// same block fails again, so it should be surfaced rather than retried:
+//
+//	var scriptErr client.ScriptError
+//	if errors.As(err, &scriptErr) {
+//		log.Printf("script failed at %s: %s", scriptErr.Position(), scriptErr.Message)
+//	}
+//
+// A script error unwraps to the RPCError of the call.
+type ScriptError struct {
+	// Message is the Cadence error message, e.g. "cannot find variable in this
+	// scope: `x`", without the Access API and execution node prefixes.
+	Message string
+	// Location is the location of the failing code, e.g. the script ID or the
+	// contract of an import, and is empty if the error has no location.
+	Location string
+	// Line and Column are the position of the error in Location, starting at 1.
+	Line   int
+	Column int
+	// Err is the error of the call.
+	Err RPCError
+}
+
+// scriptErrorMarkers mark the Access API errors that report Cadence runtime
+// failures, rather than failures to reach an execution node.
+var scriptErrorMarkers = []string{
+	"Execution failed:",
+	"cadence runtime error",
+}
+
+var scriptLocationPattern = regexp.MustCompile(`-->\s*(\S+):(\d+):(\d+)`)
+
+// newScriptError returns a ScriptError if err reports a Cadence runtime failure,
+// and an RPCError otherwise.
+func newScriptError(ctx context.Context, err error) error {
+	rpcErr := newRPCError(ctx, err)
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.ResourceExhausted:
+		return rpcErr
+	}
+
+	message := status.Convert(err).Message()
+
+	start := -1
+	for _, marker := range scriptErrorMarkers {
+		if i := strings.LastIndex(message, marker); i >= 0 && i+len(marker) > start {
+			start = i + len(marker)
+		}
+	}
+
+	if start < 0 {
+		return rpcErr
+	}
+
+	return parseScriptError(message[start:], rpcErr)
+}
+
+// parseScriptError parses the Cadence part of an Access API error message.
+func parseScriptError(message string, err RPCError) ScriptError {
+	scriptErr := ScriptError{Err: err}
+
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+
+		if scriptErr.Message == "" && strings.HasPrefix(line, "error: ") {
+			scriptErr.Message = strings.TrimPrefix(line, "error: ")
+			continue
+		}
+
+		if match := scriptLocationPattern.FindStringSubmatch(line); match != nil && scriptErr.Location == "" {
+			scriptErr.Location = match[1]
+			scriptErr.Line, _ = strconv.Atoi(match[2])
+			scriptErr.Column, _ = strconv.Atoi(match[3])
+		}
+	}
+
+	if scriptErr.Message == "" {
+		scriptErr.Message = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), ":"))
+	}
+
+	return scriptErr
+}
+
+// Position returns the position of the error as "location:line:column", or an
+// empty string if the error has no location.
+func (e ScriptError) Position() string {
+	if e.Location == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", e.Location, e.Line, e.Column)
+}
+
+func (e ScriptError) Error() string {
+	message := "script execution failed: " + e.Message
+	if position := e.Position(); position != "" {
+		message += " (at " + position + ")"
+	}
+	if e.Err.RequestID != "" {
+		message += " (request ID " + e.Err.RequestID + ")"
+	}
+	return errorMessage("%s", message)
+}
+
+func (e ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns errcode.ScriptFailed.
+func (e ScriptError) ErrorCode() errcode.Code {
+	return errcode.ScriptFailed
+}
+
+// GRPCStatus returns the gRPC status for this error.
+func (e ScriptError) GRPCStatus() *status.Status {
+	return e.Err.GRPCStatus()
+}
@@ -0,0 +1,95 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+func TestScriptError(t *testing.T) {
+	script := []byte("pub fun main(): Int { return x }")
+
+	t.Run("Runtime failure", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		rpc.On("ExecuteScriptAtLatestBlock", ctx, mock.Anything).Return(nil, status.Error(
+			codes.Internal,
+			"failed to execute the script on the execution node: rpc error: code = Internal desc = "+
+				"failed to execute script: failed to execute script at block (abcd): Execution failed:\n"+
+				"error: cannot find variable in this scope: `x`\n"+
+				" --> 9d7a2b1e:1:29\n"+
+				"  |\n"+
+				"1 | pub fun main(): Int { return x }\n"+
+				"  |                              ^ not found in this scope\n",
+		))
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, script, nil)
+
+		var scriptErr client.ScriptError
+		require.True(t, errors.As(err, &scriptErr))
+		assert.Equal(t, "cannot find variable in this scope: `x`", scriptErr.Message)
+		assert.Equal(t, "9d7a2b1e", scriptErr.Location)
+		assert.Equal(t, 1, scriptErr.Line)
+		assert.Equal(t, 29, scriptErr.Column)
+		assert.Equal(t, "9d7a2b1e:1:29", scriptErr.Position())
+
+		assert.Equal(t, errcode.ScriptFailed, errcode.Of(err))
+		assert.Equal(t, codes.Internal, status.Code(err))
+
+		var rpcErr client.RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+	}))
+
+	t.Run("Message with verbs", func(t *testing.T) {
+		err := client.ScriptError{Message: "pre-condition failed: fee must be under 5% of %d"}
+		assert.Equal(t, "client: script execution failed: pre-condition failed: fee must be under 5% of %d", err.Error())
+	})
+
+	t.Run("Transport failure", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		rpc.On("ExecuteScriptAtBlockHeight", ctx, mock.Anything).Return(nil, status.Error(
+			codes.Unavailable,
+			"failed to execute the script on the execution node: connection refused",
+		))
+
+		_, err := c.ExecuteScriptAtBlockHeight(ctx, 7, script, nil)
+
+		var scriptErr client.ScriptError
+		assert.False(t, errors.As(err, &scriptErr))
+		assert.Equal(t, errcode.RPCUnavailable, errcode.Of(err))
+	}))
+
+	t.Run("Internal error", clientTest(func(t *testing.T, ctx context.Context, rpc *MockRPCClient, c *client.Client) {
+		rpc.On("ExecuteScriptAtLatestBlock", ctx, mock.Anything).Return(nil, errInternal)
+
+		_, err := c.ExecuteScriptAtLatestBlock(ctx, script, nil)
+
+		var scriptErr client.ScriptError
+		assert.False(t, errors.As(err, &scriptErr))
+		assert.Equal(t, errcode.RPC, errcode.Of(err))
+	}))
+}
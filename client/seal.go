@@ -0,0 +1,264 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrSealTimeout is returned by WaitForSeal when a transaction is not sealed within
+// the maximum wait.
+var ErrSealTimeout = errcode.New(errcode.SealTimeout, "client: transaction not sealed in time")
+
+// A WaitOption configures WaitForSeal.
+type WaitOption func(*waitOptions)
+
+// waitOptions are the settings of WaitForSeal.
+type waitOptions struct {
+	interval    time.Duration
+	backoff     float64
+	maxInterval time.Duration
+	maxWait     time.Duration
+	onStatus    func(result *flow.TransactionResult)
+	consistent  bool
+	readClient  *Client
+}
+
+// WithPollInterval sets the time between two status requests of WaitForSeal, or the
+// first interval if WithPollBackoff is given. Defaults to 1 second.
+func WithPollInterval(interval time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.interval = interval
+	}
+}
+
+// WithPollBackoff multiplies the time between two status requests of WaitForSeal by
+// factor after each request that finds no status change, up to maxInterval. The
+// interval is reset when the status changes.
+func WithPollBackoff(factor float64, maxInterval time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.backoff = factor
+		o.maxInterval = maxInterval
+	}
+}
+
+// WithMaxWait makes WaitForSeal fail with an error wrapping ErrSealTimeout if the
+// transaction is not sealed within the given time.
+func WithMaxWait(maxWait time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.maxWait = maxWait
+	}
+}
+
+// WithStatusCallback calls onStatus with the result of the transaction each time
+// WaitForSeal observes a new status, including the final one. Statuses that change
+// between two requests are not observed, e.g. a transaction may go from pending to
+// sealed.
+func WithStatusCallback(onStatus func(result *flow.TransactionResult)) WaitOption {
+	return func(o *waitOptions) {
+		o.onStatus = onStatus
+	}
+}
+
+// WithReadConsistency makes WaitForSeal, once the transaction is sealed, also wait
+// until readClient reports a latest sealed block height greater than or equal to
+// the height at which the transaction was observed to be sealed, see
+// WaitForSealConsistent. A nil read client waits for the client itself.
+func WithReadConsistency(readClient *Client) WaitOption {
+	return func(o *waitOptions) {
+		o.consistent = true
+		o.readClient = readClient
+	}
+}
+
+// WaitForSeal polls the result of a transaction until it is sealed, and returns it:
+//
+//	result, err := c.WaitForSeal(
+//		ctx,
+//		tx.ID(),
+//		client.WithPollBackoff(1.5, 10*time.Second),
+//		client.WithMaxWait(2*time.Minute),
+//	)
+//
+// The result is also returned if the transaction expires. Execution errors are
+// reported in the Error field of the result. Polling stops when the context is done,
+// the maximum wait is exceeded or a request fails.
+func (c *Client) WaitForSeal(
+	ctx context.Context,
+	txID flow.Identifier,
+	opts ...WaitOption,
+) (*flow.TransactionResult, error) {
+	o := &waitOptions{
+		interval: defaultPollInterval,
+		backoff:  1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.interval <= 0 {
+		o.interval = defaultPollInterval
+	}
+	if o.maxInterval < o.interval {
+		o.maxInterval = o.interval
+	}
+
+	pollCtx := ctx
+	if o.maxWait > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(ctx, o.maxWait)
+		defer cancel()
+	}
+
+	status := flow.TransactionStatusUnknown
+	wait := o.interval
+
+	for {
+		result, err := c.GetTransactionResult(pollCtx, txID)
+		if err != nil {
+			return nil, sealWaitError(ctx, pollCtx, txID, status, o.maxWait, err)
+		}
+
+		if result.Status != status {
+			status = result.Status
+			wait = o.interval
+
+			if o.onStatus != nil {
+				o.onStatus(result)
+			}
+		} else if o.backoff > 1 {
+			wait = time.Duration(float64(wait) * o.backoff)
+			if wait > o.maxInterval {
+				wait = o.maxInterval
+			}
+		}
+
+		if status == flow.TransactionStatusSealed && o.consistent {
+			if err := c.waitForReadConsistency(pollCtx, o); err != nil {
+				return nil, sealWaitError(ctx, pollCtx, txID, status, o.maxWait, err)
+			}
+		}
+
+		if status == flow.TransactionStatusSealed || status == flow.TransactionStatusExpired {
+			return result, nil
+		}
+
+		if err := sleep(pollCtx, wait); err != nil {
+			return nil, sealWaitError(ctx, pollCtx, txID, status, o.maxWait, err)
+		}
+	}
+}
+
+// waitForReadConsistency waits until the read client of the options has caught up
+// with the latest sealed block of c.
+func (c *Client) waitForReadConsistency(ctx context.Context, o *waitOptions) error {
+	header, err := c.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	readClient := o.readClient
+	if readClient == nil {
+		readClient = c
+	}
+
+	_, err = readClient.WaitForSealedHeight(ctx, header.Height, o.interval)
+	return err
+}
+
+// sealWaitError returns an error wrapping ErrSealTimeout if err was caused by the
+// maximum wait of WaitForSeal rather than by the context of the caller.
+func sealWaitError(
+	ctx context.Context,
+	pollCtx context.Context,
+	txID flow.Identifier,
+	status flow.TransactionStatus,
+	maxWait time.Duration,
+	err error,
+) error {
+	if ctx.Err() == nil && pollCtx.Err() != nil {
+		return fmt.Errorf("%w: transaction %s still %s after %s", ErrSealTimeout, txID, status, maxWait)
+	}
+	return err
+}
+
+// ErrTransactionExpired is returned by SendTransactionAndWait when a transaction
+// expires before it is sealed.
+var ErrTransactionExpired = errcode.New(errcode.TransactionExpired, "client: transaction expired")
+
+// An ExecutionError is returned by SendTransactionAndWait when a sealed transaction
+// failed to execute. It unwraps to the error of the result.
+type ExecutionError struct {
+	Result *flow.TransactionResult
+}
+
+func (e ExecutionError) Error() string {
+	return errorMessage("transaction execution failed: %s", e.Result.Error.Error())
+}
+
+func (e ExecutionError) Unwrap() error {
+	return e.Result.Error
+}
+
+// ErrorCode returns errcode.TransactionFailed.
+func (e ExecutionError) ErrorCode() errcode.Code {
+	return errcode.TransactionFailed
+}
+
+// SendTransactionAndWait sends a transaction and waits for it to be sealed, as
+// WaitForSeal with the given options:
+//
+//	result, err := c.SendTransactionAndWait(ctx, tx, client.WithMaxWait(time.Minute))
+//	var execErr client.ExecutionError
+//	if errors.As(err, &execErr) {
+//		// the transaction was sealed, but reverted
+//	}
+//
+// The result of the sealed transaction is returned. If the transaction failed to
+// execute, the result is returned with an ExecutionError. If it expired, the result
+// is returned with an error wrapping ErrTransactionExpired.
+func (c *Client) SendTransactionAndWait(
+	ctx context.Context,
+	tx flow.Transaction,
+	opts ...WaitOption,
+) (*flow.TransactionResult, error) {
+	if err := c.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	result, err := c.WaitForSeal(ctx, tx.ID(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status == flow.TransactionStatusExpired {
+		return result, fmt.Errorf("%w: %s", ErrTransactionExpired, tx.ID())
+	}
+
+	if result.Error != nil {
+		return result, ExecutionError{Result: result}
+	}
+
+	return result, nil
+}
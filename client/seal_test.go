@@ -0,0 +1,223 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestClient_WaitForSeal(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reports each status until sealed", func(t *testing.T) {
+		node := &sequenceNode{
+			statuses: []entities.TransactionStatus{
+				entities.TransactionStatus_PENDING,
+				entities.TransactionStatus_PENDING,
+				entities.TransactionStatus_FINALIZED,
+				entities.TransactionStatus_EXECUTED,
+				entities.TransactionStatus_SEALED,
+			},
+		}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		var statuses []flow.TransactionStatus
+
+		result, err := c.WaitForSeal(
+			ctx,
+			flow.EmptyID,
+			client.WithPollInterval(time.Millisecond),
+			client.WithPollBackoff(2, 4*time.Millisecond),
+			client.WithStatusCallback(func(result *flow.TransactionResult) {
+				statuses = append(statuses, result.Status)
+			}),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		assert.Equal(t, []flow.TransactionStatus{
+			flow.TransactionStatusPending,
+			flow.TransactionStatusFinalized,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		}, statuses)
+	})
+
+	t.Run("Returns expired transactions", func(t *testing.T) {
+		node := &sequenceNode{
+			statuses: []entities.TransactionStatus{entities.TransactionStatus_EXPIRED},
+		}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		result, err := c.WaitForSeal(ctx, flow.EmptyID)
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusExpired, result.Status)
+	})
+
+	t.Run("Waits for read consistency", func(t *testing.T) {
+		writer := &sequenceNode{
+			statuses: []entities.TransactionStatus{entities.TransactionStatus_SEALED},
+			heights:  []uint64{10},
+		}
+		reader := &sequenceNode{heights: []uint64{9}}
+
+		c := client.NewFromEndpoint(client.NewEndpoint("writer", writer))
+		readClient := client.NewFromEndpoint(client.NewEndpoint("reader", reader))
+
+		// the read client never catches up
+		_, err := c.WaitForSeal(
+			ctx,
+			flow.EmptyID,
+			client.WithPollInterval(time.Millisecond),
+			client.WithReadConsistency(readClient),
+			client.WithMaxWait(20*time.Millisecond),
+		)
+		assert.True(t, errors.Is(err, client.ErrSealTimeout))
+
+		reader.heights = []uint64{9, 10}
+
+		result, err := c.WaitForSeal(
+			ctx,
+			flow.EmptyID,
+			client.WithPollInterval(time.Millisecond),
+			client.WithReadConsistency(readClient),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		assert.Equal(t, []uint64{10}, reader.heights)
+	})
+
+	t.Run("Fails after the maximum wait", func(t *testing.T) {
+		node := &sequenceNode{
+			statuses: []entities.TransactionStatus{entities.TransactionStatus_PENDING},
+		}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		_, err := c.WaitForSeal(
+			ctx,
+			flow.EmptyID,
+			client.WithPollInterval(5*time.Millisecond),
+			client.WithMaxWait(30*time.Millisecond),
+		)
+		assert.True(t, errors.Is(err, client.ErrSealTimeout))
+	})
+
+	t.Run("Stops when the context is cancelled", func(t *testing.T) {
+		node := &sequenceNode{
+			statuses: []entities.TransactionStatus{entities.TransactionStatus_PENDING},
+		}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		cancelCtx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+		defer cancel()
+
+		_, err := c.WaitForSeal(
+			cancelCtx,
+			flow.EmptyID,
+			client.WithPollInterval(5*time.Millisecond),
+			client.WithMaxWait(time.Minute),
+		)
+		assert.False(t, errors.Is(err, client.ErrSealTimeout))
+		assert.Error(t, err)
+	})
+}
+
+// resultNode is an Access API node that accepts transactions, and reports them
+// with the given status and error message.
+type resultNode struct {
+	sent         int
+	status       entities.TransactionStatus
+	errorMessage string
+}
+
+func (n *resultNode) Invoke(
+	_ context.Context,
+	method string,
+	_ interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	switch method {
+	case client.MethodSendTransaction:
+		n.sent++
+	case client.MethodGetTransactionResult:
+		res := reply.(*access.TransactionResultResponse)
+		res.Status = n.status
+		if n.errorMessage != "" {
+			res.StatusCode = 1
+			res.ErrorMessage = n.errorMessage
+		}
+	}
+
+	return nil
+}
+
+func TestClient_SendTransactionAndWait(t *testing.T) {
+	ctx := context.Background()
+	tx := test.TransactionGenerator().New()
+
+	t.Run("Returns the sealed result", func(t *testing.T) {
+		node := &resultNode{status: entities.TransactionStatus_SEALED}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		result, err := c.SendTransactionAndWait(ctx, *tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, node.sent)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+	})
+
+	t.Run("Returns execution errors", func(t *testing.T) {
+		node := &resultNode{status: entities.TransactionStatus_SEALED, errorMessage: "panic: boom"}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		result, err := c.SendTransactionAndWait(ctx, *tx)
+		require.Error(t, err)
+		require.NotNil(t, result)
+
+		var execErr client.ExecutionError
+		require.True(t, errors.As(err, &execErr))
+		assert.Equal(t, result, execErr.Result)
+		assert.Contains(t, err.Error(), "panic: boom")
+		assert.Equal(t, errcode.TransactionFailed, errcode.Of(err))
+	})
+
+	t.Run("Returns expired transactions", func(t *testing.T) {
+		node := &resultNode{status: entities.TransactionStatus_EXPIRED}
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		result, err := c.SendTransactionAndWait(ctx, *tx)
+		assert.True(t, errors.Is(err, client.ErrTransactionExpired))
+		assert.Equal(t, flow.TransactionStatusExpired, result.Status)
+	})
+}
@@ -0,0 +1,93 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+)
+
+// A ScriptRequest is a script to execute, with its arguments.
+type ScriptRequest struct {
+	Script    []byte
+	Arguments []cadence.Value
+}
+
+// A Snapshot executes scripts against the execution state of a single block, so that
+// the results of several scripts are consistent with each other.
+//
+// Scripts executed at the latest block one after the other may each see a different
+// block, e.g. a balance read before a transfer is sealed and a second balance read
+// after it. A snapshot pins the height once:
+//
+//	snapshot, err := c.LatestSnapshot(ctx)
+//	...
+//	balances, err := snapshot.ExecuteScripts(ctx, []client.ScriptRequest{
+//		{Script: balanceScript, Arguments: []cadence.Value{alice}},
+//		{Script: balanceScript, Arguments: []cadence.Value{bob}},
+//	})
+type Snapshot struct {
+	client *Client
+	height uint64
+}
+
+// LatestSnapshot returns a snapshot of the latest sealed block.
+func (c *Client) LatestSnapshot(ctx context.Context) (*Snapshot, error) {
+	header, err := c.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.SnapshotAtHeight(header.Height), nil
+}
+
+// SnapshotAtHeight returns a snapshot of the block at the given height.
+func (c *Client) SnapshotAtHeight(height uint64) *Snapshot {
+	return &Snapshot{client: c, height: height}
+}
+
+// Height returns the height of the block of the snapshot.
+func (s *Snapshot) Height() uint64 {
+	return s.height
+}
+
+// ExecuteScript executes a script against the execution state of the snapshot.
+func (s *Snapshot) ExecuteScript(ctx context.Context, script []byte, arguments []cadence.Value) (cadence.Value, error) {
+	return s.client.ExecuteScriptAtBlockHeight(ctx, s.height, script, arguments)
+}
+
+// ExecuteScripts executes scripts in order against the execution state of the
+// snapshot, and returns their results in the same order. It stops at the first
+// script that fails, and returns its error.
+func (s *Snapshot) ExecuteScripts(ctx context.Context, requests []ScriptRequest) ([]cadence.Value, error) {
+	values := make([]cadence.Value, len(requests))
+
+	for i, req := range requests {
+		value, err := s.ExecuteScript(ctx, req.Script, req.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("client: script %d failed at height %d: %w", i, s.height, err)
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// tipNode is an Access API node whose sealed tip advances with every call, and whose
+// scripts return the height they are executed at.
+type tipNode struct {
+	height uint64
+}
+
+func (n *tipNode) Invoke(_ context.Context, method string, args, reply interface{}, _ ...grpc.CallOption) error {
+	n.height++
+
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{
+			Height:    n.height,
+			Timestamp: ptypes.TimestampNow(),
+		}
+	case client.MethodExecuteScriptAtBlockHeight:
+		req := args.(*access.ExecuteScriptAtBlockHeightRequest)
+		if string(req.Script) == "fail" {
+			return status.Error(codes.InvalidArgument, "script failed")
+		}
+		reply.(*access.ExecuteScriptResponse).Value = jsoncdc.MustEncode(cadence.NewUInt64(req.BlockHeight))
+	default:
+		return status.Error(codes.Unimplemented, method)
+	}
+
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	node := &tipNode{height: 100}
+	c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+	snapshot, err := c.LatestSnapshot(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 101, snapshot.Height())
+
+	values, err := snapshot.ExecuteScripts(ctx, []client.ScriptRequest{
+		{Script: []byte("a")},
+		{Script: []byte("b")},
+		{Script: []byte("c")},
+	})
+	require.NoError(t, err)
+
+	// all scripts see the pinned height, while the tip advances
+	assert.Equal(t, []cadence.Value{
+		cadence.NewUInt64(101),
+		cadence.NewUInt64(101),
+		cadence.NewUInt64(101),
+	}, values)
+
+	value, err := c.SnapshotAtHeight(7).ExecuteScript(ctx, []byte("a"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, cadence.NewUInt64(7), value)
+
+	_, err = snapshot.ExecuteScripts(ctx, []client.ScriptRequest{
+		{Script: []byte("a")},
+		{Script: []byte("fail")},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(errors.Unwrap(err)))
+	assert.Contains(t, err.Error(), "script 1")
+}
@@ -0,0 +1,255 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// A Spork is a segment of the Flow history served by its own Access nodes.
+type Spork struct {
+	// Name identifies the spork, e.g. "mainnet-5".
+	Name string
+	// RootHeight is the height of the first block of the spork.
+	RootHeight uint64
+	// Endpoint serves the history of the spork.
+	Endpoint *Endpoint
+}
+
+// A SporkRouter is an invoker that routes each call to the spork that holds the
+// requested data, so that all history can be read through a single client.
+//
+// Calls for a block height are sent to the spork that contains the height, and event
+// queries over height ranges that span several sporks are split across them. Calls
+// for an entity ID are sent to each spork, newest first, until one of them knows
+// the entity. All other calls, including transactions, are sent to the latest spork.
+type SporkRouter struct {
+	sporks []Spork
+}
+
+var _ Invoker = &SporkRouter{}
+
+// NewSporkRouter returns a router over the given sporks.
+//
+// An error is returned if no spork is given or if two sporks have the same root height.
+func NewSporkRouter(sporks []Spork) (*SporkRouter, error) {
+	if len(sporks) == 0 {
+		return nil, ErrNoEndpoint
+	}
+
+	sorted := make([]Spork, len(sporks))
+	copy(sorted, sporks)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].RootHeight < sorted[j].RootHeight
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].RootHeight == sorted[i-1].RootHeight {
+			return nil, fmt.Errorf(
+				"client: sporks %s and %s have the same root height %d",
+				sorted[i-1].Name,
+				sorted[i].Name,
+				sorted[i].RootHeight,
+			)
+		}
+	}
+
+	return &SporkRouter{sporks: sorted}, nil
+}
+
+// NewFromInvoker initializes a Flow client that sends all calls through the given invoker.
+//
+// If the invoker implements io.Closer, it is closed when the client is closed.
+func NewFromInvoker(invoker Invoker) *Client {
+	closeFunc := func() error { return nil }
+	if closer, ok := invoker.(io.Closer); ok {
+		closeFunc = closer.Close
+	}
+
+	return &Client{
+		rpcClient: NewRPCClient(invoker),
+		invoker:   invoker,
+		close:     closeFunc,
+	}
+}
+
+// Sporks returns the sporks of this router, ordered by root height.
+func (r *SporkRouter) Sporks() []Spork {
+	sporks := make([]Spork, len(r.sporks))
+	copy(sporks, r.sporks)
+	return sporks
+}
+
+// SporkAt returns the spork that contains the block at the given height.
+func (r *SporkRouter) SporkAt(height uint64) (Spork, error) {
+	i := r.index(height)
+	if i < 0 {
+		return Spork{}, fmt.Errorf("client: no spork contains height %d", height)
+	}
+	return r.sporks[i], nil
+}
+
+// index returns the index of the spork that contains the height, or -1.
+func (r *SporkRouter) index(height uint64) int {
+	return sort.Search(len(r.sporks), func(i int) bool {
+		return r.sporks[i].RootHeight > height
+	}) - 1
+}
+
+func (r *SporkRouter) latest() Spork {
+	return r.sporks[len(r.sporks)-1]
+}
+
+// Invoke sends a call to the spork that holds the requested data.
+func (r *SporkRouter) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	switch req := args.(type) {
+	case *access.GetEventsForHeightRangeRequest:
+		return r.invokeEventRange(ctx, req, reply.(*access.EventsResponse), opts...)
+	case *access.GetBlockHeaderByHeightRequest:
+		return r.invokeAtHeight(ctx, req.GetHeight(), method, args, reply, opts...)
+	case *access.GetBlockByHeightRequest:
+		return r.invokeAtHeight(ctx, req.GetHeight(), method, args, reply, opts...)
+	case *access.GetAccountAtBlockHeightRequest:
+		return r.invokeAtHeight(ctx, req.GetBlockHeight(), method, args, reply, opts...)
+	case *access.ExecuteScriptAtBlockHeightRequest:
+		return r.invokeAtHeight(ctx, req.GetBlockHeight(), method, args, reply, opts...)
+	}
+
+	switch method {
+	case MethodGetBlockHeaderByID,
+		MethodGetBlockByID,
+		MethodGetCollectionByID,
+		MethodGetTransaction,
+		MethodGetTransactionResult,
+		MethodExecuteScriptAtBlockID,
+		MethodGetEventsForBlockIDs:
+		return r.invokeByID(ctx, method, args, reply, opts...)
+	}
+
+	return r.latest().Endpoint.Invoker.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (r *SporkRouter) invokeAtHeight(
+	ctx context.Context,
+	height uint64,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	spork, err := r.SporkAt(height)
+	if err != nil {
+		return status.Error(codes.OutOfRange, err.Error())
+	}
+
+	return spork.Endpoint.Invoker.Invoke(ctx, method, args, reply, opts...)
+}
+
+// invokeByID sends a call to each spork, newest first, until one of them does not
+// respond with a not found error, as mapped to ErrNotFound. Some Access nodes report
+// unknown IDs with an Internal or Unknown status.
+func (r *SporkRouter) invokeByID(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	var err error
+
+	for i := len(r.sporks) - 1; i >= 0; i-- {
+		if m, ok := reply.(proto.Message); ok {
+			m.Reset()
+		}
+
+		err = r.sporks[i].Endpoint.Invoker.Invoke(ctx, method, args, reply, opts...)
+		if err == nil || !errors.Is(newRPCError(ctx, err), ErrNotFound) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// invokeEventRange splits an event query at spork boundaries and merges the results.
+func (r *SporkRouter) invokeEventRange(
+	ctx context.Context,
+	req *access.GetEventsForHeightRangeRequest,
+	reply *access.EventsResponse,
+	opts ...grpc.CallOption,
+) error {
+	start, end := req.GetStartHeight(), req.GetEndHeight()
+
+	i := r.index(start)
+	if i < 0 {
+		return status.Error(codes.OutOfRange, fmt.Sprintf("client: no spork contains height %d", start))
+	}
+
+	for ; i < len(r.sporks) && start <= end; i++ {
+		segmentEnd := end
+		if i+1 < len(r.sporks) && r.sporks[i+1].RootHeight-1 < segmentEnd {
+			segmentEnd = r.sporks[i+1].RootHeight - 1
+		}
+
+		segment := &access.GetEventsForHeightRangeRequest{
+			Type:        req.GetType(),
+			StartHeight: start,
+			EndHeight:   segmentEnd,
+		}
+
+		res := new(access.EventsResponse)
+
+		err := r.sporks[i].Endpoint.Invoker.Invoke(ctx, MethodGetEventsForHeightRange, segment, res, opts...)
+		if err != nil {
+			return err
+		}
+
+		reply.Results = append(reply.Results, res.GetResults()...)
+
+		start = segmentEnd + 1
+	}
+
+	return nil
+}
+
+// Close closes the endpoints of all sporks.
+func (r *SporkRouter) Close() error {
+	endpoints := make([]*Endpoint, len(r.sporks))
+	for i, spork := range r.sporks {
+		endpoints[i] = spork.Endpoint
+	}
+	return closeEndpoints(endpoints)
+}
@@ -0,0 +1,192 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// sporkNode is a fake Access node that serves a single spork.
+type sporkNode struct {
+	name    string
+	tx      *flow.Transaction
+	methods []string
+	ranges  [][2]uint64
+	// missing is the error returned for unknown transactions, by default a
+	// NotFound status.
+	missing error
+}
+
+func (n *sporkNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	n.methods = append(n.methods, method)
+
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: 1000}
+	case client.MethodGetBlockHeaderByHeight:
+		height := args.(*access.GetBlockHeaderByHeightRequest).Height
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: height}
+	case client.MethodGetEventsForHeightRange:
+		req := args.(*access.GetEventsForHeightRangeRequest)
+		n.ranges = append(n.ranges, [2]uint64{req.StartHeight, req.EndHeight})
+
+		res := reply.(*access.EventsResponse)
+		for height := req.StartHeight; height <= req.EndHeight; height++ {
+			res.Results = append(res.Results, &access.EventsResponse_Result{
+				BlockHeight:    height,
+				BlockTimestamp: ptypes.TimestampNow(),
+			})
+		}
+	case client.MethodGetTransaction:
+		if n.tx == nil {
+			if n.missing != nil {
+				return n.missing
+			}
+			return status.Error(codes.NotFound, "not found")
+		}
+
+		msg, err := convert.TransactionToMessage(*n.tx)
+		if err != nil {
+			return err
+		}
+		reply.(*access.TransactionResponse).Transaction = msg
+	}
+
+	return nil
+}
+
+func TestSporkRouter(t *testing.T) {
+	ctx := context.Background()
+
+	tx := test.TransactionGenerator().New()
+
+	old := &sporkNode{name: "old", tx: tx}
+	current := &sporkNode{name: "current"}
+
+	router, err := client.NewSporkRouter([]client.Spork{
+		{Name: "current", RootHeight: 100, Endpoint: client.NewEndpoint("current", current)},
+		{Name: "old", RootHeight: 10, Endpoint: client.NewEndpoint("old", old)},
+	})
+	require.NoError(t, err)
+
+	c := client.NewFromInvoker(router)
+	defer c.Close()
+
+	t.Run("Heights", func(t *testing.T) {
+		spork, err := router.SporkAt(99)
+		require.NoError(t, err)
+		assert.Equal(t, "old", spork.Name)
+
+		spork, err = router.SporkAt(100)
+		require.NoError(t, err)
+		assert.Equal(t, "current", spork.Name)
+
+		_, err = router.SporkAt(9)
+		assert.Error(t, err)
+
+		header, err := c.GetBlockHeaderByHeight(ctx, 50)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(50), header.Height)
+		assert.Equal(t, client.MethodGetBlockHeaderByHeight, old.methods[len(old.methods)-1])
+
+		_, err = c.GetBlockHeaderByHeight(ctx, 5)
+		assert.Equal(t, codes.OutOfRange, status.Code(err))
+	})
+
+	t.Run("Event ranges are split", func(t *testing.T) {
+		results, err := c.GetEventsForHeightRange(ctx, client.EventRangeQuery{
+			Type:        "A.0x1.Token.Deposit",
+			StartHeight: 90,
+			EndHeight:   110,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, results, 21)
+		for i, result := range results {
+			assert.Equal(t, uint64(90+i), result.Height)
+		}
+
+		assert.Equal(t, [][2]uint64{{90, 99}}, old.ranges)
+		assert.Equal(t, [][2]uint64{{100, 110}}, current.ranges)
+	})
+
+	t.Run("IDs are looked up in all sporks", func(t *testing.T) {
+		result, err := c.GetTransaction(ctx, tx.ID())
+		require.NoError(t, err)
+		assert.Equal(t, tx.ID(), result.ID())
+
+		assert.Contains(t, current.methods, client.MethodGetTransaction)
+	})
+
+	t.Run("IDs reported missing with other codes", func(t *testing.T) {
+		current.missing = status.Error(codes.Internal, "failed to retrieve transaction: key not found")
+		defer func() { current.missing = nil }()
+
+		result, err := c.GetTransaction(ctx, tx.ID())
+		require.NoError(t, err)
+		assert.Equal(t, tx.ID(), result.ID())
+
+		// other internal errors are not looked up in older sporks
+		current.missing = status.Error(codes.Internal, "storage failure")
+		old.methods = nil
+
+		_, err = c.GetTransaction(ctx, tx.ID())
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.NotContains(t, old.methods, client.MethodGetTransaction)
+	})
+
+	t.Run("Latest calls use the latest spork", func(t *testing.T) {
+		header, err := c.GetLatestBlockHeader(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1000), header.Height)
+		assert.NotContains(t, old.methods, client.MethodGetLatestBlockHeader)
+	})
+
+	t.Run("Invalid sporks", func(t *testing.T) {
+		_, err := client.NewSporkRouter(nil)
+		assert.Equal(t, client.ErrNoEndpoint, err)
+
+		_, err = client.NewSporkRouter([]client.Spork{
+			{Name: "a", RootHeight: 1, Endpoint: client.NewEndpoint("a", old)},
+			{Name: "b", RootHeight: 1, Endpoint: client.NewEndpoint("b", current)},
+		})
+		assert.Error(t, err)
+	})
+}
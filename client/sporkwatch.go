@@ -0,0 +1,187 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrSporkBoundary is returned when the network served by an Access node changes,
+// which happens when the network is upgraded with a spork.
+var ErrSporkBoundary = errcode.New(errcode.SporkBoundary, "client: spork boundary detected")
+
+// A SporkBoundaryError describes the change that signaled a spork.
+//
+// Use errors.Is(err, ErrSporkBoundary) to test for spork boundaries.
+type SporkBoundaryError struct {
+	// Reason describes the observed discontinuity.
+	Reason string
+	// PreviousChainID and ChainID are the chain IDs before and after the boundary,
+	// if the node reports them.
+	PreviousChainID flow.ChainID
+	ChainID         flow.ChainID
+	// PreviousHeight is the last sealed height observed before the boundary, and
+	// Height the first sealed height observed after it.
+	PreviousHeight uint64
+	Height         uint64
+}
+
+func (e *SporkBoundaryError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSporkBoundary, e.Reason)
+}
+
+func (e *SporkBoundaryError) Unwrap() error {
+	return ErrSporkBoundary
+}
+
+// ErrorCode returns errcode.SporkBoundary.
+func (e *SporkBoundaryError) ErrorCode() errcode.Code {
+	return errcode.SporkBoundary
+}
+
+// SporkWatchConfig configures a SporkWatcher.
+type SporkWatchConfig struct {
+	// Interval is the time between two checks.
+	//
+	// Defaults to 30 seconds.
+	Interval time.Duration
+	// OnBoundary is called when a spork boundary is detected, before Run returns.
+	OnBoundary func(err *SporkBoundaryError)
+}
+
+const defaultSporkWatchInterval = 30 * time.Second
+
+// A SporkWatcher detects that the network served by an Access node has changed.
+//
+// A spork is detected when the chain ID reported by the node changes, when the
+// latest sealed height decreases, or when the block at the last observed height is
+// no longer known or has a different ID.
+type SporkWatcher struct {
+	client *Client
+	config SporkWatchConfig
+
+	observed bool
+	chainID  flow.ChainID
+	header   *flow.BlockHeader
+}
+
+// NewSporkWatcher returns a watcher for the network served by the client.
+func NewSporkWatcher(c *Client, config SporkWatchConfig) *SporkWatcher {
+	if config.Interval == 0 {
+		config.Interval = defaultSporkWatchInterval
+	}
+
+	return &SporkWatcher{client: c, config: config}
+}
+
+// Check observes the network once, and returns a *SporkBoundaryError if it changed
+// since the previous observation. Other errors are returned if the node cannot be
+// queried.
+//
+// The first observation is recorded as the reference. The reference is not updated
+// when a boundary is detected, so Check keeps reporting it until the watcher is
+// replaced.
+func (w *SporkWatcher) Check(ctx context.Context) error {
+	chainID, err := w.client.GetNetworkParameters(ctx)
+	if status.Code(err) == codes.Unimplemented {
+		// the emulator and older nodes do not report network parameters
+		chainID, err = "", nil
+	}
+	if err != nil {
+		return err
+	}
+
+	header, err := w.client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if !w.observed {
+		w.observed = true
+		w.chainID = chainID
+		w.header = header
+		return nil
+	}
+
+	boundary := &SporkBoundaryError{
+		PreviousChainID: w.chainID,
+		ChainID:         chainID,
+		PreviousHeight:  w.header.Height,
+		Height:          header.Height,
+	}
+
+	switch {
+	case chainID != w.chainID:
+		boundary.Reason = fmt.Sprintf("chain ID changed from %q to %q", w.chainID, chainID)
+	case header.Height < w.header.Height:
+		boundary.Reason = fmt.Sprintf("sealed height decreased from %d to %d", w.header.Height, header.Height)
+	default:
+		previous, err := w.client.GetBlockHeaderByHeight(ctx, w.header.Height)
+		if errors.Is(err, ErrNotFound) {
+			boundary.Reason = fmt.Sprintf("block at height %d is no longer known", w.header.Height)
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if previous.ID != w.header.ID {
+			boundary.Reason = fmt.Sprintf("block at height %d changed", w.header.Height)
+			break
+		}
+
+		w.header = header
+		return nil
+	}
+
+	return boundary
+}
+
+// Run checks the network at the configured interval until a spork boundary is
+// detected or the context is canceled.
+//
+// When a boundary is detected, the OnBoundary callback is called and the
+// *SporkBoundaryError is returned. Errors from the node are ignored, since nodes
+// are commonly unavailable during a spork, and the check is retried at the next
+// interval.
+func (w *SporkWatcher) Run(ctx context.Context) error {
+	for {
+		err := w.Check(ctx)
+
+		if boundary, ok := err.(*SporkBoundaryError); ok {
+			if w.config.OnBoundary != nil {
+				w.config.OnBoundary(boundary)
+			}
+			return boundary
+		}
+
+		if err := sleep(ctx, w.config.Interval); err != nil {
+			return err
+		}
+	}
+}
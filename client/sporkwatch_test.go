@@ -0,0 +1,162 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// networkNode is a fake Access node whose network can be changed.
+type networkNode struct {
+	mu      sync.Mutex
+	chainID string
+	height  uint64
+	// fork is added to block IDs, to simulate a different history
+	fork byte
+}
+
+func (n *networkNode) set(f func(n *networkNode)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f(n)
+}
+
+func (n *networkNode) header(height uint64) *entities.BlockHeader {
+	id := make([]byte, 32)
+	id[0] = byte(height)
+	id[1] = n.fork
+	return &entities.BlockHeader{Id: id, Height: height}
+}
+
+func (n *networkNode) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch method {
+	case client.MethodGetNetworkParameters:
+		if n.chainID == "" {
+			return status.Error(codes.Unimplemented, "unimplemented")
+		}
+		reply.(*access.GetNetworkParametersResponse).ChainId = n.chainID
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = n.header(n.height)
+	case client.MethodGetBlockHeaderByHeight:
+		height := args.(*access.GetBlockHeaderByHeightRequest).Height
+		if height > n.height {
+			return status.Error(codes.NotFound, "not found")
+		}
+		reply.(*access.BlockHeaderResponse).Block = n.header(height)
+	}
+
+	return nil
+}
+
+func TestSporkWatcher(t *testing.T) {
+	ctx := context.Background()
+
+	check := func(t *testing.T, node *networkNode, change func(n *networkNode)) error {
+		w := client.NewSporkWatcher(client.NewFromEndpoint(client.NewEndpoint("node", node)), client.SporkWatchConfig{})
+
+		require.NoError(t, w.Check(ctx))
+
+		node.set(func(n *networkNode) { n.height++ })
+		require.NoError(t, w.Check(ctx))
+
+		node.set(change)
+		return w.Check(ctx)
+	}
+
+	t.Run("Chain ID change", func(t *testing.T) {
+		err := check(t, &networkNode{chainID: "flow-mainnet", height: 10}, func(n *networkNode) {
+			n.chainID = "flow-testnet"
+		})
+
+		var boundary *client.SporkBoundaryError
+		require.True(t, errors.As(err, &boundary))
+		assert.True(t, errors.Is(err, client.ErrSporkBoundary))
+		assert.Equal(t, errcode.SporkBoundary, errcode.Of(err))
+		assert.Equal(t, "flow-testnet", string(boundary.ChainID))
+	})
+
+	t.Run("Height decrease", func(t *testing.T) {
+		err := check(t, &networkNode{height: 10}, func(n *networkNode) {
+			n.height = 5
+		})
+
+		var boundary *client.SporkBoundaryError
+		require.True(t, errors.As(err, &boundary))
+		assert.Equal(t, uint64(11), boundary.PreviousHeight)
+		assert.Equal(t, uint64(5), boundary.Height)
+	})
+
+	t.Run("Different history", func(t *testing.T) {
+		err := check(t, &networkNode{height: 10}, func(n *networkNode) {
+			n.height = 20
+			n.fork = 1
+		})
+		assert.True(t, errors.Is(err, client.ErrSporkBoundary))
+	})
+
+	t.Run("Continuous history", func(t *testing.T) {
+		err := check(t, &networkNode{chainID: "flow-mainnet", height: 10}, func(n *networkNode) {
+			n.height = 20
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Run calls back", func(t *testing.T) {
+		node := &networkNode{height: 10}
+
+		var called *client.SporkBoundaryError
+		w := client.NewSporkWatcher(client.NewFromEndpoint(client.NewEndpoint("node", node)), client.SporkWatchConfig{
+			Interval:   time.Millisecond,
+			OnBoundary: func(err *client.SporkBoundaryError) { called = err },
+		})
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			node.set(func(n *networkNode) { n.height = 1 })
+		}()
+
+		err := w.Run(ctx)
+		assert.True(t, errors.Is(err, client.ErrSporkBoundary))
+		assert.Equal(t, err, called)
+	})
+}
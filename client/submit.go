@@ -0,0 +1,255 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// Default values of a RetryBudget.
+const (
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = 500 * time.Millisecond
+	DefaultMaxBackoff  = 30 * time.Second
+)
+
+// A RetryBudget limits how many times SubmitTransaction sends a transaction, or a
+// client created with WithRetryBudget makes a call, when the Access API cannot be
+// reached.
+//
+// Sending a transaction more than once is safe: the network executes a transaction
+// at most once, whatever the number of nodes that received it.
+type RetryBudget struct {
+	// MaxAttempts is the maximum number of times the transaction is sent.
+	//
+	// Defaults to DefaultMaxAttempts.
+	MaxAttempts int
+	// MaxElapsed is the time after the first attempt past which the transaction is
+	// not sent again. Zero means no limit.
+	MaxElapsed time.Duration
+	// Backoff is the delay before the first retry, doubled before each further retry
+	// up to MaxBackoff.
+	//
+	// Defaults to DefaultBackoff.
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts.
+	//
+	// Defaults to DefaultMaxBackoff, or to Backoff if it is longer.
+	MaxBackoff time.Duration
+}
+
+func (b RetryBudget) withDefaults() RetryBudget {
+	if b.MaxAttempts <= 0 {
+		b.MaxAttempts = DefaultMaxAttempts
+	}
+	if b.Backoff <= 0 {
+		b.Backoff = DefaultBackoff
+	}
+	if b.MaxBackoff < b.Backoff {
+		b.MaxBackoff = DefaultMaxBackoff
+		if b.MaxBackoff < b.Backoff {
+			b.MaxBackoff = b.Backoff
+		}
+	}
+	return b
+}
+
+// SubmitConfig configures SubmitTransaction.
+type SubmitConfig struct {
+	// Retry limits the attempts to send the transaction.
+	Retry RetryBudget
+	// Consistency configures how the client waits for the transaction to be sealed.
+	Consistency ConsistencyConfig
+}
+
+// A Submission describes how a transaction was delivered to the network.
+//
+// Submissions let monitoring tell failures of the network path, such as retries
+// or an unreachable node, apart from failures of the transaction on chain.
+type Submission struct {
+	// Attempts is the number of times the transaction was sent.
+	Attempts int
+	// Latency is the time from the first attempt until the result was received, or
+	// until the submission failed.
+	Latency time.Duration
+	// Endpoint is the address of the Access node that accepted the transaction, or
+	// that rejected the last attempt. It is empty if the address is unknown, e.g. for
+	// clients created with NewFromRPCClient.
+	Endpoint string
+}
+
+// A SubmitResult is the result of a transaction sent by SubmitTransaction.
+type SubmitResult struct {
+	// Result is the result of the sealed or expired transaction. Execution errors
+	// are reported in its Error field.
+	Result *flow.TransactionResult
+	// Submission describes how the transaction was delivered.
+	Submission Submission
+}
+
+// A SubmissionError is returned by SubmitTransaction when a transaction could not
+// be sent, or its result could not be retrieved.
+type SubmissionError struct {
+	Submission Submission
+	Err        error
+}
+
+func (e SubmissionError) Error() string {
+	return errorMessage("transaction submission failed after %d attempt(s): %s", e.Submission.Attempts, e.Err.Error())
+}
+
+func (e SubmissionError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus returns the gRPC status of the wrapped error.
+func (e SubmissionError) GRPCStatus() *status.Status {
+	s, _ := status.FromError(e.Err)
+	return s
+}
+
+// SubmitTransaction sends a transaction, retrying within the given budget while
+// the Access API is unavailable, and waits for the transaction to be sealed or to
+// expire.
+//
+// The result is returned with the metadata of its submission. If the submission
+// fails, a SubmissionError that wraps the last error is returned.
+func (c *Client) SubmitTransaction(
+	ctx context.Context,
+	tx flow.Transaction,
+	config SubmitConfig,
+) (*SubmitResult, error) {
+	budget := config.Retry.withDefaults()
+
+	var (
+		recorder   endpointRecorder
+		submission Submission
+	)
+
+	ctx = context.WithValue(ctx, endpointRecorderKey{}, &recorder)
+	start := time.Now()
+	backoff := budget.Backoff
+
+	fail := func(err error) (*SubmitResult, error) {
+		submission.Latency = time.Since(start)
+		if submission.Endpoint == "" {
+			submission.Endpoint = recorder.endpoint(c)
+		}
+		return nil, SubmissionError{Submission: submission, Err: err}
+	}
+
+	for {
+		submission.Attempts++
+
+		sendCtx := ctx
+		if submission.Attempts > 1 {
+			sendCtx = withRetry(ctx)
+		}
+
+		err := c.SendTransaction(sendCtx, tx)
+		if err == nil {
+			break
+		}
+
+		if !isRetryable(ctx, err) || submission.Attempts >= budget.MaxAttempts {
+			return fail(err)
+		}
+
+		if budget.MaxElapsed > 0 && time.Since(start)+backoff > budget.MaxElapsed {
+			return fail(err)
+		}
+
+		if err := sleep(ctx, backoff); err != nil {
+			return fail(err)
+		}
+
+		backoff *= 2
+		if backoff > budget.MaxBackoff {
+			backoff = budget.MaxBackoff
+		}
+	}
+
+	submission.Endpoint = recorder.endpoint(c)
+
+	result, err := c.WaitForSeal(ctx, tx.ID(), config.Consistency.waitOptions()...)
+	if err != nil {
+		return fail(err)
+	}
+
+	submission.Latency = time.Since(start)
+
+	return &SubmitResult{
+		Result:     result,
+		Submission: submission,
+	}, nil
+}
+
+// isRetryable returns true if a call failed because the Access API could not serve
+// it, rather than because the request was rejected.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	case codes.ResourceExhausted:
+		// a message over the size limit is rejected again on retry
+		return !isMessageTooLarge(err)
+	}
+
+	return false
+}
+
+type endpointRecorderKey struct{}
+
+// An endpointRecorder records the endpoint selected by a router for the calls made
+// with a context.
+type endpointRecorder struct {
+	mu      sync.Mutex
+	address string
+}
+
+// recordEndpoint records the address of the endpoint serving a call made with ctx.
+func recordEndpoint(ctx context.Context, address string) {
+	if r, ok := ctx.Value(endpointRecorderKey{}).(*endpointRecorder); ok {
+		r.mu.Lock()
+		r.address = address
+		r.mu.Unlock()
+	}
+}
+
+// endpoint returns the last recorded endpoint, or the endpoint of the client.
+func (r *endpointRecorder) endpoint(c *Client) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.address != "" {
+		return r.address
+	}
+	return c.endpoint
+}
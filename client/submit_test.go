@@ -0,0 +1,166 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// flakyNode is a sequence node that fails the first sends of transactions with the
+// given error.
+type flakyNode struct {
+	sequenceNode
+	failures int
+	err      error
+	sends    int
+}
+
+func (n *flakyNode) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	opts ...grpc.CallOption,
+) error {
+	if method == client.MethodSendTransaction {
+		n.sends++
+		if n.sends <= n.failures {
+			return n.err
+		}
+		return nil
+	}
+
+	return n.sequenceNode.Invoke(ctx, method, args, reply, opts...)
+}
+
+// rotatingRouter routes each call to the next of its endpoints.
+type rotatingRouter struct {
+	endpoints []*client.Endpoint
+	next      int
+}
+
+func (r *rotatingRouter) Route(context.Context, string) (*client.Endpoint, error) {
+	endpoint := r.endpoints[r.next%len(r.endpoints)]
+	r.next++
+	return endpoint, nil
+}
+
+func newFlakyNode(failures int, err error) *flakyNode {
+	return &flakyNode{
+		sequenceNode: sequenceNode{
+			statuses: []entities.TransactionStatus{entities.TransactionStatus_SEALED},
+			heights:  []uint64{10},
+		},
+		failures: failures,
+		err:      err,
+	}
+}
+
+func TestClient_SubmitTransaction(t *testing.T) {
+	ctx := context.Background()
+	tx := test.TransactionGenerator().New()
+
+	config := client.SubmitConfig{
+		Retry:       client.RetryBudget{Backoff: time.Millisecond},
+		Consistency: client.ConsistencyConfig{PollInterval: time.Millisecond},
+	}
+
+	t.Run("Retries unavailable node", func(t *testing.T) {
+		node := newFlakyNode(2, status.Error(codes.Unavailable, "unavailable"))
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		res, err := c.SubmitTransaction(ctx, *tx, config)
+		require.NoError(t, err)
+
+		assert.Equal(t, flow.TransactionStatusSealed, res.Result.Status)
+		assert.Equal(t, 3, res.Submission.Attempts)
+		assert.Equal(t, "node", res.Submission.Endpoint)
+		assert.True(t, res.Submission.Latency > 0)
+	})
+
+	t.Run("Budget exhausted", func(t *testing.T) {
+		node := newFlakyNode(5, status.Error(codes.Unavailable, "unavailable"))
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		_, err := c.SubmitTransaction(ctx, *tx, config)
+
+		var submissionErr client.SubmissionError
+		require.True(t, errors.As(err, &submissionErr))
+		assert.Equal(t, client.DefaultMaxAttempts, submissionErr.Submission.Attempts)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, client.DefaultMaxAttempts, node.sends)
+	})
+
+	t.Run("Rejected transaction is not retried", func(t *testing.T) {
+		node := newFlakyNode(1, status.Error(codes.InvalidArgument, "invalid"))
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		_, err := c.SubmitTransaction(ctx, *tx, config)
+
+		var submissionErr client.SubmissionError
+		require.True(t, errors.As(err, &submissionErr))
+		assert.Equal(t, 1, submissionErr.Submission.Attempts)
+		assert.Equal(t, 1, node.sends)
+	})
+
+	t.Run("Elapsed budget", func(t *testing.T) {
+		node := newFlakyNode(5, status.Error(codes.Unavailable, "unavailable"))
+		c := client.NewFromEndpoint(client.NewEndpoint("node", node))
+
+		_, err := c.SubmitTransaction(ctx, *tx, client.SubmitConfig{
+			Retry: client.RetryBudget{MaxAttempts: 10, Backoff: time.Hour, MaxElapsed: time.Minute},
+		})
+
+		var submissionErr client.SubmissionError
+		require.True(t, errors.As(err, &submissionErr))
+		assert.Equal(t, 1, submissionErr.Submission.Attempts)
+	})
+
+	t.Run("Endpoint selected by router", func(t *testing.T) {
+		down := newFlakyNode(1, status.Error(codes.Unavailable, "unavailable"))
+		up := newFlakyNode(0, nil)
+
+		c := client.NewFromRouter(&rotatingRouter{
+			endpoints: []*client.Endpoint{
+				client.NewEndpoint("down", down),
+				client.NewEndpoint("up", up),
+			},
+		})
+
+		res, err := c.SubmitTransaction(ctx, *tx, config)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, res.Submission.Attempts)
+		assert.Equal(t, "up", res.Submission.Endpoint)
+	})
+}
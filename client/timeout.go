@@ -0,0 +1,54 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// DefaultCallTimeout returns a middleware that bounds each call whose context has
+// no deadline to the given timeout, so that a call to an Access node that stopped
+// responding fails instead of hanging, e.g. while waiting for a transaction result.
+//
+// Calls whose context has a deadline keep it, even if it is later than the default,
+// so callers override the default by setting their own deadline. A timeout of zero
+// or less does not bound calls.
+func DefaultCallTimeout(timeout time.Duration) Middleware {
+	return func(next Invoker) Invoker {
+		return InvokerFunc(func(
+			ctx context.Context,
+			method string,
+			args interface{},
+			reply interface{},
+			opts ...grpc.CallOption,
+		) error {
+			if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+				return next.Invoke(ctx, method, args, reply, opts...)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			return next.Invoke(ctx, method, args, reply, opts...)
+		})
+	}
+}
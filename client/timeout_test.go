@@ -0,0 +1,99 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func TestDefaultCallTimeout(t *testing.T) {
+	var deadlines []time.Duration
+
+	// hang records the time left to the deadline of each call, and blocks until
+	// the call is done
+	hang := client.InvokerFunc(func(ctx context.Context, _ string, _, _ interface{}, _ ...grpc.CallOption) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadlines = append(deadlines, 0)
+			return nil
+		}
+
+		deadlines = append(deadlines, time.Until(deadline))
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	invoker := client.Chain(hang, client.DefaultCallTimeout(20*time.Millisecond))
+
+	err := invoker.Invoke(context.Background(), client.MethodGetTransactionResult, nil, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the deadline of the caller overrides the default
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	go cancel()
+	err = invoker.Invoke(ctx, client.MethodGetTransactionResult, nil, nil)
+	assert.Equal(t, context.Canceled, err)
+
+	err = client.Chain(hang, client.DefaultCallTimeout(0)).Invoke(context.Background(), client.MethodPing, nil, nil)
+	assert.NoError(t, err)
+
+	require.Len(t, deadlines, 3)
+	assert.True(t, deadlines[0] <= 20*time.Millisecond)
+	assert.True(t, deadlines[1] > time.Minute)
+	assert.Zero(t, deadlines[2])
+}
+
+func TestNewClient_CallTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	ping := &blockingServer{
+		peers:   make(chan string, 10),
+		release: make(chan struct{}),
+	}
+	access.RegisterAccessAPIServer(server, ping)
+
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+	defer close(ping.release)
+
+	c, err := client.NewClient(lis.Addr().String(), client.WithInsecure(), client.WithCallTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	defer c.Close()
+
+	start := time.Now()
+	err = c.Ping(context.Background())
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
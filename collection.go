@@ -47,3 +47,23 @@ func (c Collection) Encode() []byte {
 type CollectionGuarantee struct {
 	CollectionID Identifier
 }
+
+// A FullCollection is a collection with the bodies of its transactions.
+type FullCollection struct {
+	Transactions []*Transaction
+}
+
+// Light returns the collection of the transaction IDs of this full collection.
+func (c FullCollection) Light() Collection {
+	transactionIDs := make([]Identifier, len(c.Transactions))
+	for i, tx := range c.Transactions {
+		transactionIDs[i] = tx.ID()
+	}
+
+	return Collection{TransactionIDs: transactionIDs}
+}
+
+// ID returns the canonical SHA3-256 hash of this collection.
+func (c FullCollection) ID() Identifier {
+	return c.Light().ID()
+}
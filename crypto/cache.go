@@ -0,0 +1,157 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// domainTagLength is the length of the domain tag that prefixes signed messages.
+const domainTagLength = 32
+
+// A SignatureCache caches signatures by signing key, domain tag and message hash
+// for a fixed time.
+//
+// A cache can be shared by the CachingSigners of several keys. It is safe for
+// concurrent use.
+type SignatureCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[signatureKey]cachedSignature
+	lastSweep time.Time
+}
+
+type signatureKey struct {
+	key         string
+	tag         [domainTagLength]byte
+	messageHash [sha256.Size]byte
+}
+
+type cachedSignature struct {
+	sig     []byte
+	expires time.Time
+}
+
+// NewSignatureCache returns a cache that keeps signatures for the given time.
+func NewSignatureCache(ttl time.Duration) *SignatureCache {
+	return &SignatureCache{
+		ttl:       ttl,
+		entries:   make(map[signatureKey]cachedSignature),
+		lastSweep: time.Now(),
+	}
+}
+
+// Len returns the number of signatures in the cache, including expired signatures
+// that are not removed yet.
+func (c *SignatureCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+func (c *SignatureCache) get(k signatureKey, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[k]
+	if !ok || now.After(entry.expires) {
+		return nil, false
+	}
+
+	return append([]byte(nil), entry.sig...), true
+}
+
+func (c *SignatureCache) put(k signatureKey, sig []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// expired signatures are removed at most once per TTL
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.entries[k] = cachedSignature{
+		sig:     append([]byte(nil), sig...),
+		expires: now.Add(c.ttl),
+	}
+}
+
+// A CachingSigner is a signer that reuses the signatures of identical messages,
+// e.g. the account proofs signed for each health check of a service, to avoid
+// the cost and latency of remote signers such as a KMS.
+//
+// Messages are identified by their domain tag, the first 32 bytes of a Flow signed
+// message, and the hash of the message. Key identifies the signing key in the
+// cache, e.g. an account address and key index, and must be distinct for each key
+// that shares the cache.
+//
+// Caching is opt-in: a cached signature is returned for as long as it is in the
+// cache, even if the signer would produce a new one, e.g. with ECDSA. Failed
+// signatures are not cached.
+type CachingSigner struct {
+	Signer Signer
+	Key    string
+	Cache  *SignatureCache
+}
+
+var _ Signer = CachingSigner{}
+
+// NewCachingSigner returns a signer that caches the signatures of signer in cache,
+// under the given key.
+func NewCachingSigner(signer Signer, key string, cache *SignatureCache) CachingSigner {
+	return CachingSigner{
+		Signer: signer,
+		Key:    key,
+		Cache:  cache,
+	}
+}
+
+// Sign returns the cached signature of message, or signs it with the wrapped signer
+// and caches the signature.
+func (s CachingSigner) Sign(message []byte) ([]byte, error) {
+	k := signatureKey{
+		key:         s.Key,
+		messageHash: sha256.Sum256(message),
+	}
+	if len(message) >= domainTagLength {
+		copy(k.tag[:], message[:domainTagLength])
+	}
+
+	if sig, ok := s.Cache.get(k, time.Now()); ok {
+		return sig, nil
+	}
+
+	sig, err := s.Signer.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Cache.put(k, sig, time.Now())
+
+	return sig, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+func TestCachingSigner(t *testing.T) {
+	message := append(bytes.Repeat([]byte{'t'}, 32), []byte("account proof")...)
+
+	t.Run("Cached", func(t *testing.T) {
+		stub := &stubSigner{sig: []byte("sig")}
+		cache := crypto.NewSignatureCache(time.Minute)
+		signer := crypto.NewCachingSigner(stub, "0x01/0", cache)
+
+		for i := 0; i < 3; i++ {
+			sig, err := signer.Sign(message)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("sig"), sig)
+		}
+		assert.EqualValues(t, 1, atomic.LoadInt32(&stub.calls))
+
+		_, err := signer.Sign([]byte("other message"))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&stub.calls))
+
+		// another key sharing the cache signs on its own
+		_, err = crypto.NewCachingSigner(stub, "0x01/1", cache).Sign(message)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, atomic.LoadInt32(&stub.calls))
+		assert.Equal(t, 3, cache.Len())
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		stub := &stubSigner{sig: []byte("sig")}
+		signer := crypto.NewCachingSigner(stub, "key", crypto.NewSignatureCache(10*time.Millisecond))
+
+		_, err := signer.Sign(message)
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = signer.Sign(message)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&stub.calls))
+		assert.Equal(t, 1, signer.Cache.Len())
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		stub := &stubSigner{sig: []byte("sig"), fail: 1}
+		signer := crypto.NewCachingSigner(stub, "key", crypto.NewSignatureCache(time.Minute))
+
+		_, err := signer.Sign(message)
+		assert.Error(t, err)
+
+		stub.setFailing(false)
+
+		sig, err := signer.Sign(message)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("sig"), sig)
+		assert.EqualValues(t, 2, atomic.LoadInt32(&stub.calls))
+	})
+}
@@ -60,7 +60,8 @@ func (k Key) ResourceID() string {
 // Client is a client for interacting with the Google Cloud KMS API
 // using types native to the Flow Go SDK.
 type Client struct {
-	client *kms.KeyManagementClient
+	client  *kms.KeyManagementClient
+	limiter *crypto.SignLimiter
 }
 
 // NewClient creates a new KMS client.
@@ -75,6 +76,25 @@ func NewClient(ctx context.Context) (*Client, error) {
 	}, nil
 }
 
+// WithSignLimiter returns a copy of the client whose signers sign within the limits
+// of limiter.
+//
+// KMS quotas apply to a whole project, so all the signers of the project should
+// share one limiter:
+//
+//	limiter := crypto.NewSignLimiter(crypto.SignLimits{
+//		MaxConcurrent: 10,
+//		MaxPerMinute:  3000,
+//		MaxWait:       30 * time.Second,
+//	})
+//
+//	kmsClient = kmsClient.WithSignLimiter(limiter)
+func (c *Client) WithSignLimiter(limiter *crypto.SignLimiter) *Client {
+	limited := *c
+	limited.limiter = limiter
+	return &limited
+}
+
 // GetPublicKey fetches the public key portion of a KMS asymmetric signing key version.
 //
 // ECDSA_P256 is currently the only Flow signature algorithm supported by Google Cloud KMS.
@@ -39,6 +39,7 @@ type Signer struct {
 	key      Key
 	hashAlgo crypto.HashAlgorithm
 	hasher   crypto.Hasher
+	limiter  *crypto.SignLimiter
 }
 
 // SignerForKey returns a new Google Cloud KMS signer for an asymmetric key version.
@@ -64,13 +65,24 @@ func (c *Client) SignerForKey(
 		key:      key,
 		hashAlgo: hashAlgo,
 		hasher:   hasher,
+		limiter:  c.limiter,
 	}, nil
 }
 
 // Sign signs the given message using the KMS signing key for this signer.
 //
+// If the client has a sign limiter, the request waits for its turn first.
+//
 // Reference: https://cloud.google.com/kms/docs/create-validate-signatures
 func (s *Signer) Sign(message []byte) ([]byte, error) {
+	if s.limiter != nil {
+		release, err := s.limiter.Acquire()
+		if err != nil {
+			return nil, fmt.Errorf("cloudkms: failed to sign: %w", err)
+		}
+		defer release()
+	}
+
 	digest := s.hasher.ComputeHash(message)
 
 	digestMessage, err := makeDigest(s.hashAlgo, digest)
@@ -24,6 +24,7 @@ import (
 	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"io"
 
 	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto"
 	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto/hash"
@@ -43,8 +44,19 @@ const (
 )
 
 // String returns the string representation of this signature algorithm.
+//
+// Algorithms added with RegisterSignatureAlgorithm are represented by their name.
 func (f SignatureAlgorithm) String() string {
-	return [...]string{"UNKNOWN", "BLS_BLS12381", "ECDSA_P256", "ECDSA_secp256k1"}[f]
+	names := [...]string{"UNKNOWN", "BLS_BLS12381", "ECDSA_P256", "ECDSA_secp256k1"}
+	if f >= 0 && int(f) < len(names) {
+		return names[f]
+	}
+
+	if registered, ok := lookupSignatureAlgorithm(f); ok {
+		return registered.name
+	}
+
+	return fmt.Sprintf("SignatureAlgorithm(%d)", int(f))
 }
 
 // StringToSignatureAlgorithm converts a string to a SignatureAlgorithm.
@@ -57,7 +69,7 @@ func StringToSignatureAlgorithm(s string) SignatureAlgorithm {
 	case ECDSA_secp256k1.String():
 		return ECDSA_secp256k1
 	default:
-		return UnknownSignatureAlgorithm
+		return signatureAlgorithmByName(s)
 	}
 }
 
@@ -73,8 +85,19 @@ const (
 )
 
 // String returns the string representation of this hash algorithm.
+//
+// Algorithms added with RegisterHashAlgorithm are represented by their name.
 func (f HashAlgorithm) String() string {
-	return [...]string{"UNKNOWN", "SHA2_256", "SHA2_384", "SHA3_256", "SHA3_384"}[f]
+	names := [...]string{"UNKNOWN", "SHA2_256", "SHA2_384", "SHA3_256", "SHA3_384"}
+	if f >= 0 && int(f) < len(names) {
+		return names[f]
+	}
+
+	if registered, ok := lookupHashAlgorithm(f); ok {
+		return registered.name
+	}
+
+	return fmt.Sprintf("HashAlgorithm(%d)", int(f))
 }
 
 // StringToHashAlgorithm converts a string to a HashAlgorithm.
@@ -89,7 +112,7 @@ func StringToHashAlgorithm(s string) HashAlgorithm {
 	case SHA3_384.String():
 		return SHA3_384
 	default:
-		return UnknownHashAlgorithm
+		return hashAlgorithmByName(s)
 	}
 }
 
@@ -106,6 +129,11 @@ func CompatibleAlgorithms(sigAlgo SignatureAlgorithm, hashAlgo HashAlgorithm) bo
 			return true
 		}
 	}
+
+	if registered, ok := lookupSignatureAlgorithm(sigAlgo); ok {
+		return registered.scheme.CompatibleHash(hashAlgo)
+	}
+
 	return false
 }
 
@@ -172,6 +200,10 @@ type Signer interface {
 type InMemorySigner struct {
 	PrivateKey PrivateKey
 	Hasher     Hasher
+	// Random is the source of randomness of signatures.
+	//
+	// Defaults to crypto/rand.
+	Random io.Reader
 }
 
 // NewInMemorySigner initializes and returns a new in-memory signer with the provided private key
@@ -186,7 +218,11 @@ func NewInMemorySigner(privateKey PrivateKey, hashAlgo HashAlgorithm) InMemorySi
 }
 
 func (s InMemorySigner) Sign(message []byte) ([]byte, error) {
-	return s.PrivateKey.Sign(message, s.Hasher)
+	if s.Random == nil {
+		return s.PrivateKey.Sign(message, s.Hasher)
+	}
+
+	return s.PrivateKey.SignWithRandom(message, s.Hasher, s.Random)
 }
 
 // NaiveSigner is an alias for InMemorySigner.
@@ -204,6 +240,17 @@ func NewNaiveSigner(privateKey PrivateKey, hashAlgo HashAlgorithm) NaiveSigner {
 // should be expanded before being passed to the key generation process.
 const MinSeedLength = crypto.MinSeedLen
 
+// SetFixedWidthVerification enables or disables the fixed-width arithmetic used to
+// verify ECDSA_secp256k1 signatures.
+//
+// Fixed-width verification is disabled by default, and signatures are verified by
+// crypto/ecdsa with math/big arithmetic. When enabled, verification does not
+// allocate and keeps precomputed multiples of each public key after its first
+// verification, which suits high-throughput verifiers.
+func SetFixedWidthVerification(enabled bool) {
+	crypto.SetFixedWidthVerification(enabled)
+}
+
 func keyGenerationKMACTag(sigAlgo SignatureAlgorithm) []byte {
 	return []byte(fmt.Sprintf("%s Key Generation", sigAlgo))
 }
@@ -220,6 +267,18 @@ func GeneratePrivateKey(sigAlgo SignatureAlgorithm, seed []byte) (PrivateKey, er
 		)
 	}
 
+	// registered schemes expand the seed on their own
+	if registered, ok := lookupSignatureAlgorithm(sigAlgo); ok {
+		privKey, err := registered.scheme.GeneratePrivateKey(seed)
+		if err != nil {
+			return PrivateKey{}, err
+		}
+
+		return PrivateKey{
+			privateKey: schemePrivateKey{algo: sigAlgo, key: privKey},
+		}, nil
+	}
+
 	// expand the seed and uniformize its entropy
 	var seedLen int
 	switch sigAlgo {
@@ -256,6 +315,17 @@ func GeneratePrivateKey(sigAlgo SignatureAlgorithm, seed []byte) (PrivateKey, er
 
 // DecodePrivateKey decodes a raw byte encoded private key with the given signature algorithm.
 func DecodePrivateKey(sigAlgo SignatureAlgorithm, b []byte) (PrivateKey, error) {
+	if registered, ok := lookupSignatureAlgorithm(sigAlgo); ok {
+		privKey, err := registered.scheme.DecodePrivateKey(b)
+		if err != nil {
+			return PrivateKey{}, err
+		}
+
+		return PrivateKey{
+			privateKey: schemePrivateKey{algo: sigAlgo, key: privKey},
+		}, nil
+	}
+
 	privKey, err := crypto.DecodePrivateKey(crypto.SigningAlgorithm(sigAlgo), b)
 	if err != nil {
 		return PrivateKey{}, err
@@ -278,6 +348,17 @@ func DecodePrivateKeyHex(sigAlgo SignatureAlgorithm, s string) (PrivateKey, erro
 
 // DecodePublicKey decodes a raw byte encoded public key with the given signature algorithm.
 func DecodePublicKey(sigAlgo SignatureAlgorithm, b []byte) (PublicKey, error) {
+	if registered, ok := lookupSignatureAlgorithm(sigAlgo); ok {
+		pubKey, err := registered.scheme.DecodePublicKey(b)
+		if err != nil {
+			return PublicKey{}, err
+		}
+
+		return PublicKey{
+			publicKey: schemePublicKey{algo: sigAlgo, key: pubKey},
+		}, nil
+	}
+
 	pubKey, err := crypto.DecodePublicKey(crypto.SigningAlgorithm(sigAlgo), b)
 	if err != nil {
 		return PublicKey{}, err
@@ -0,0 +1,250 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrSignerTimeout is returned by a timeout signer when the wrapped signer does not
+// return before the timeout.
+var ErrSignerTimeout = errcode.New(errcode.SignerTimeout, "crypto: signer timed out")
+
+// ErrNoSignerAvailable is returned by a fallback signer when the circuits of all of its
+// signers are open.
+var ErrNoSignerAvailable = errcode.New(errcode.NoSignerAvailable, "crypto: no signer available")
+
+// A TimeoutSigner is a signer that bounds the duration of each signature.
+//
+// If the wrapped signer does not return before the timeout, Sign returns
+// ErrSignerTimeout. The pending signature is abandoned and its result discarded,
+// so a hung remote signer never blocks the caller.
+type TimeoutSigner struct {
+	Signer  Signer
+	Timeout time.Duration
+}
+
+// NewTimeoutSigner returns a signer that fails with ErrSignerTimeout if the given
+// signer takes longer than timeout to sign a message.
+func NewTimeoutSigner(signer Signer, timeout time.Duration) TimeoutSigner {
+	return TimeoutSigner{
+		Signer:  signer,
+		Timeout: timeout,
+	}
+}
+
+func (s TimeoutSigner) Sign(message []byte) ([]byte, error) {
+	type result struct {
+		sig []byte
+		err error
+	}
+
+	// buffered so that an abandoned signature does not leak a blocked goroutine
+	done := make(chan result, 1)
+
+	go func() {
+		sig, err := s.Signer.Sign(message)
+		done <- result{sig, err}
+	}()
+
+	timer := time.NewTimer(s.Timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.sig, r.err
+	case <-timer.C:
+		return nil, ErrSignerTimeout
+	}
+}
+
+// FallbackConfig configures a FallbackSigner.
+type FallbackConfig struct {
+	// Timeout is the maximum duration of a signature from a single signer.
+	// If zero, signatures are not bounded.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failures after which the
+	// circuit of a signer opens and the signer is skipped.
+	//
+	// Defaults to 3.
+	FailureThreshold int
+	// Cooldown is the time a circuit stays open before the signer is tried again.
+	//
+	// Defaults to 30 seconds.
+	Cooldown time.Duration
+}
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = 30 * time.Second
+)
+
+// A FallbackSigner signs with the first available signer of a chain, for example
+// a KMS signer backed by a local key.
+//
+// Each signer has a circuit breaker: after FailureThreshold consecutive failures,
+// the signer is skipped for Cooldown, so that an outage of a remote signer costs
+// at most one timeout per cooldown period instead of one per signature. After the
+// cooldown, the circuit is half-open: a single signature probes the signer while
+// concurrent signatures keep skipping it. A successful probe closes the circuit,
+// and a failed one opens it for another cooldown.
+//
+// Sign can only be used if all signers produce signatures for the same public key,
+// such as replicas of a key in several regions. If the signers hold different
+// account keys, use SignIndexed and attach the signature with the key index of the
+// signer that produced it.
+type FallbackSigner struct {
+	signers []Signer
+	config  FallbackConfig
+
+	mu       sync.Mutex
+	circuits []circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+	// probing means that the circuit is half-open and a probe is in flight.
+	probing bool
+}
+
+var _ Signer = &FallbackSigner{}
+
+// NewFallbackSigner returns a signer that tries the given signers in order.
+func NewFallbackSigner(config FallbackConfig, signers ...Signer) *FallbackSigner {
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = defaultFailureThreshold
+	}
+
+	if config.Cooldown == 0 {
+		config.Cooldown = defaultCooldown
+	}
+
+	if config.Timeout > 0 {
+		wrapped := make([]Signer, len(signers))
+		for i, signer := range signers {
+			wrapped[i] = NewTimeoutSigner(signer, config.Timeout)
+		}
+		signers = wrapped
+	}
+
+	return &FallbackSigner{
+		signers:  signers,
+		config:   config,
+		circuits: make([]circuit, len(signers)),
+	}
+}
+
+// Sign signs the message with the first signer that succeeds.
+func (s *FallbackSigner) Sign(message []byte) ([]byte, error) {
+	sig, _, err := s.SignIndexed(message)
+	return sig, err
+}
+
+// SignIndexed signs the message with the first signer that succeeds, and returns
+// the index of that signer in the chain.
+//
+// If all signers fail, the returned error lists the error of each signer.
+func (s *FallbackSigner) SignIndexed(message []byte) ([]byte, int, error) {
+	var errs []string
+
+	for i, signer := range s.signers {
+		ok, probe := s.available(i)
+		if !ok {
+			continue
+		}
+
+		sig, err := signer.Sign(message)
+		s.record(i, probe, err)
+
+		if err == nil {
+			return sig, i, nil
+		}
+
+		errs = append(errs, fmt.Sprintf("signer %d: %s", i, err))
+	}
+
+	if len(errs) == 0 {
+		return nil, -1, ErrNoSignerAvailable
+	}
+
+	return nil, -1, fmt.Errorf("crypto: all signers failed: %s", strings.Join(errs, "; "))
+}
+
+// available returns true if the signer at index i may be used, that is if its
+// circuit is closed, or if its cooldown has elapsed and no probe is in flight. It
+// also returns true if the signature is the probe of a half-open circuit.
+func (s *FallbackSigner) available(i int) (ok bool, probe bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &s.circuits[i]
+
+	switch {
+	case c.openUntil.IsZero():
+		return true, false
+	case c.probing || time.Now().Before(c.openUntil):
+		return false, false
+	}
+
+	c.probing = true
+	return true, true
+}
+
+func (s *FallbackSigner) record(i int, probe bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &s.circuits[i]
+
+	if err == nil {
+		*c = circuit{}
+		return
+	}
+
+	if probe {
+		c.probing = false
+	}
+
+	c.failures++
+	if probe || c.failures >= s.config.FailureThreshold {
+		c.openUntil = time.Now().Add(s.config.Cooldown)
+	}
+}
+
+// OpenCircuits returns the indexes of the signers that are currently skipped.
+func (s *FallbackSigner) OpenCircuits() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var open []int
+	now := time.Now()
+	for i, c := range s.circuits {
+		if c.probing || now.Before(c.openUntil) {
+			open = append(open, i)
+		}
+	}
+
+	return open
+}
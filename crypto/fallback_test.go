@@ -0,0 +1,188 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// stubSigner returns a fixed signature after an optional delay, or fails.
+type stubSigner struct {
+	sig   []byte
+	delay time.Duration
+	fail  int32
+	calls int32
+}
+
+func (s *stubSigner) setFailing(fail bool) {
+	var v int32
+	if fail {
+		v = 1
+	}
+	atomic.StoreInt32(&s.fail, v)
+}
+
+func (s *stubSigner) Sign([]byte) ([]byte, error) {
+	atomic.AddInt32(&s.calls, 1)
+	time.Sleep(s.delay)
+
+	if atomic.LoadInt32(&s.fail) == 1 {
+		return nil, errors.New("unavailable")
+	}
+
+	return s.sig, nil
+}
+
+func TestTimeoutSigner(t *testing.T) {
+	slow := &stubSigner{sig: []byte{1}, delay: time.Second}
+
+	start := time.Now()
+	_, err := crypto.NewTimeoutSigner(slow, 10*time.Millisecond).Sign(nil)
+
+	assert.Equal(t, crypto.ErrSignerTimeout, err)
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+
+	fast := &stubSigner{sig: []byte{2}}
+
+	sig, err := crypto.NewTimeoutSigner(fast, time.Second).Sign(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2}, sig)
+}
+
+func TestFallbackSigner(t *testing.T) {
+	t.Run("Falls back to next signer", func(t *testing.T) {
+		remote := &stubSigner{sig: []byte{1}}
+		local := &stubSigner{sig: []byte{2}}
+
+		s := crypto.NewFallbackSigner(crypto.FallbackConfig{}, remote, local)
+
+		sig, i, err := s.SignIndexed(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, sig)
+		assert.Equal(t, 0, i)
+
+		remote.setFailing(true)
+
+		sig, i, err = s.SignIndexed(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, sig)
+		assert.Equal(t, 1, i)
+	})
+
+	t.Run("Times out hung signers", func(t *testing.T) {
+		remote := &stubSigner{sig: []byte{1}, delay: time.Second}
+		local := &stubSigner{sig: []byte{2}}
+
+		s := crypto.NewFallbackSigner(crypto.FallbackConfig{Timeout: 10 * time.Millisecond}, remote, local)
+
+		sig, err := s.Sign(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{2}, sig)
+	})
+
+	t.Run("Opens and closes circuits", func(t *testing.T) {
+		remote := &stubSigner{sig: []byte{1}}
+		local := &stubSigner{sig: []byte{2}}
+
+		s := crypto.NewFallbackSigner(
+			crypto.FallbackConfig{FailureThreshold: 2, Cooldown: 50 * time.Millisecond},
+			remote,
+			local,
+		)
+
+		remote.setFailing(true)
+
+		for i := 0; i < 4; i++ {
+			_, err := s.Sign(nil)
+			require.NoError(t, err)
+		}
+
+		// the remote signer is skipped once its circuit opens
+		assert.Equal(t, int32(2), atomic.LoadInt32(&remote.calls))
+		assert.Equal(t, []int{0}, s.OpenCircuits())
+
+		remote.setFailing(false)
+		time.Sleep(60 * time.Millisecond)
+
+		sig, err := s.Sign(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1}, sig)
+		assert.Empty(t, s.OpenCircuits())
+	})
+
+	t.Run("Probes half-open circuits once", func(t *testing.T) {
+		remote := &stubSigner{sig: []byte{1}}
+		local := &stubSigner{sig: []byte{2}}
+
+		s := crypto.NewFallbackSigner(
+			crypto.FallbackConfig{FailureThreshold: 1, Cooldown: 20 * time.Millisecond},
+			remote,
+			local,
+		)
+
+		remote.setFailing(true)
+
+		_, err := s.Sign(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int{0}, s.OpenCircuits())
+
+		time.Sleep(30 * time.Millisecond)
+		remote.delay = 50 * time.Millisecond
+
+		// a single signature probes the remote signer, the others skip it
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.Sign(nil)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&remote.calls))
+		assert.Equal(t, int32(6), atomic.LoadInt32(&local.calls))
+
+		// the failed probe opened the circuit for another cooldown
+		assert.Equal(t, []int{0}, s.OpenCircuits())
+	})
+
+	t.Run("All signers failing", func(t *testing.T) {
+		remote := &stubSigner{}
+		remote.setFailing(true)
+
+		s := crypto.NewFallbackSigner(crypto.FallbackConfig{FailureThreshold: 1}, remote)
+
+		_, err := s.Sign(nil)
+		assert.EqualError(t, err, "crypto: all signers failed: signer 0: unavailable")
+
+		_, err = s.Sign(nil)
+		assert.Equal(t, crypto.ErrNoSignerAvailable, err)
+	})
+}
@@ -19,7 +19,9 @@
 package crypto
 
 import (
+	"context"
 	"fmt"
+	"io"
 
 	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto/hash"
 )
@@ -29,7 +31,8 @@ type Hash = hash.Hash
 
 // NewHasher initializes and returns a new hasher with the given hash algorithm.
 //
-// This function returns an error if the hash algorithm is invalid.
+// Hash algorithms added with RegisterHashAlgorithm are supported as well. This
+// function returns an error if the hash algorithm is invalid.
 func NewHasher(algo HashAlgorithm) (Hasher, error) {
 	switch algo {
 	case SHA2_256:
@@ -40,9 +43,13 @@ func NewHasher(algo HashAlgorithm) (Hasher, error) {
 		return NewSHA3_256(), nil
 	case SHA3_384:
 		return NewSHA3_384(), nil
-	default:
-		return nil, fmt.Errorf("invalid hash algorithm %s", algo)
 	}
+
+	if registered, ok := lookupHashAlgorithm(algo); ok {
+		return &stdHasher{algo: algo, hash: registered.newHash()}, nil
+	}
+
+	return nil, fmt.Errorf("invalid hash algorithm %s", algo)
 }
 
 // NewSHA2_256 returns a new instance of SHA2-256 hasher.
@@ -64,3 +71,52 @@ func NewSHA3_256() Hasher {
 func NewSHA3_384() Hasher {
 	return hash.NewSHA3_384()
 }
+
+// hashReaderChunkSize is the number of bytes HashReader reads at a time.
+const hashReaderChunkSize = 1 << 20
+
+// HashReader hashes everything read from r with the hasher, e.g. a contract bundle
+// or a media file too large to hold in memory, and returns the hash.
+//
+// The context is checked between chunks of 1 MiB, so that hashing stops soon after
+// the context is done. If onProgress is not nil, it is called after each chunk with
+// the total number of bytes hashed so far.
+//
+// The state of the hasher is reset before and after hashing.
+func HashReader(
+	ctx context.Context,
+	hasher Hasher,
+	r io.Reader,
+	onProgress func(hashed int64),
+) (Hash, error) {
+	hasher.Reset()
+
+	buf := make([]byte, hashReaderChunkSize)
+	var hashed int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			hasher.Reset()
+			return nil, err
+		}
+
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			_, _ = hasher.Write(buf[:n])
+			hashed += int64(n)
+
+			if onProgress != nil {
+				onProgress(hashed)
+			}
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return hasher.SumHash(), nil
+		}
+
+		if err != nil {
+			hasher.Reset()
+			return nil, fmt.Errorf("crypto: failed to read data to hash: %w", err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+func TestHashReader(t *testing.T) {
+	data := bytes.Repeat([]byte("flow"), 1<<19) // 2 MiB
+
+	t.Run("Hashes everything read", func(t *testing.T) {
+		var progress []int64
+
+		hash, err := crypto.HashReader(
+			context.Background(),
+			crypto.NewSHA3_256(),
+			bytes.NewReader(data),
+			func(hashed int64) {
+				progress = append(progress, hashed)
+			},
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, crypto.NewSHA3_256().ComputeHash(data), hash)
+		assert.Equal(t, []int64{1 << 20, 2 << 20}, progress)
+	})
+
+	t.Run("Hashes empty readers", func(t *testing.T) {
+		hash, err := crypto.HashReader(context.Background(), crypto.NewSHA2_256(), bytes.NewReader(nil), nil)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.NewSHA2_256().ComputeHash(nil), hash)
+	})
+
+	t.Run("Stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		_, err := crypto.HashReader(ctx, crypto.NewSHA3_256(), bytes.NewReader(data), func(int64) {
+			cancel()
+		})
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("Returns read errors", func(t *testing.T) {
+		broken := io.MultiReader(bytes.NewReader(data[:10]), errReader{})
+
+		_, err := crypto.HashReader(context.Background(), crypto.NewSHA3_256(), broken, nil)
+		assert.True(t, errors.Is(err, errBrokenReader))
+	})
+}
+
+var errBrokenReader = errors.New("broken")
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errBrokenReader
+}
@@ -31,6 +31,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"sync"
 
@@ -100,6 +101,79 @@ func (sk *PrKeyECDSA) signHash(h hash.Hash) (Signature, error) {
 	return signature, nil
 }
 
+// signHashWithRandom returns the signature of the hash using the private key and a
+// nonce drawn from the given reader.
+// crypto/ecdsa is not used as it does not draw nonces from the reader it is given
+// since Go 1.26, which prevents reproducible signatures.
+func (sk *PrKeyECDSA) signHashWithRandom(h hash.Hash, random io.Reader) (Signature, error) {
+	curve := sk.alg.curve
+	N := curve.Params().N
+	Nlen := bitsToBytes(N.BitLen())
+	e := hashToInt(h, N)
+
+	// use extra 128 bits to reduce the modular reduction bias
+	buf := make([]byte, Nlen+(securityBits/8))
+	n := new(big.Int).Sub(N, one)
+
+	for {
+		if _, err := io.ReadFull(random, buf); err != nil {
+			return nil, fmt.Errorf("ECDSA Sign has failed: %w", err)
+		}
+
+		k := new(big.Int).SetBytes(buf)
+		k.Mod(k, n)
+		k.Add(k, one)
+
+		x, _ := curve.ScalarBaseMult(k.Bytes())
+		r := x.Mod(x, N)
+		if r.Sign() == 0 {
+			continue
+		}
+
+		s := new(big.Int).Mul(r, sk.goPrKey.D)
+		s.Add(s, e)
+		s.Mul(s, k.ModInverse(k, N))
+		s.Mod(s, N)
+		if s.Sign() == 0 {
+			continue
+		}
+
+		rBytes := r.Bytes()
+		sBytes := s.Bytes()
+		signature := make([]byte, 2*Nlen)
+		// pad the signature with zeroes
+		copy(signature[Nlen-len(rBytes):], rBytes)
+		copy(signature[2*Nlen-len(sBytes):], sBytes)
+		return signature, nil
+	}
+}
+
+// hashToInt converts a hash to an integer, keeping its leftmost bits up to the
+// bit length of the curve order, as specified in FIPS 186-4.
+func hashToInt(h hash.Hash, N *big.Int) *big.Int {
+	orderBits := N.BitLen()
+	orderBytes := bitsToBytes(orderBits)
+	if len(h) > orderBytes {
+		h = h[:orderBytes]
+	}
+
+	e := new(big.Int).SetBytes(h)
+	if excess := len(h)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// SignWithRandom signs an array of bytes like Sign, and draws the nonce of the
+// signature from the given reader.
+func (sk *PrKeyECDSA) SignWithRandom(data []byte, alg hash.Hasher, random io.Reader) (Signature, error) {
+	if alg == nil {
+		return nil, errors.New("Sign requires a Hasher")
+	}
+	h := alg.ComputeHash(data)
+	return sk.signHashWithRandom(h, random)
+}
+
 // Sign signs an array of bytes
 // It only reads the private key without modifiying it while hashers sha2 and sha3 are
 // modified temporarily.
@@ -115,12 +189,20 @@ func (sk *PrKeyECDSA) Sign(data []byte, alg hash.Hasher) (Signature, error) {
 
 // verifyHash implements ECDSA signature verification
 func (pk *PubKeyECDSA) verifyHash(sig Signature, h hash.Hash) (bool, error) {
+	if pk.alg.algo == ECDSASecp256k1 && fixedWidthVerification() {
+		return pk.verifyHashFixed(sig, h), nil
+	}
+	return pk.verifyHashBig(sig, h), nil
+}
+
+// verifyHashBig implements ECDSA signature verification with crypto/ecdsa
+func (pk *PubKeyECDSA) verifyHashBig(sig Signature, h hash.Hash) bool {
 	var r big.Int
 	var s big.Int
 	Nlen := bitsToBytes((pk.alg.curve.Params().N).BitLen())
 	r.SetBytes(sig[:Nlen])
 	s.SetBytes(sig[Nlen:])
-	return goecdsa.Verify(pk.goPubKey, h, &r, &s), nil
+	return goecdsa.Verify(pk.goPubKey, h, &r, &s)
 }
 
 // Verify verifies a signature of a byte array
@@ -198,7 +280,7 @@ func (a *ecdsaAlgo) rawDecodePublicKey(der []byte) (PublicKey, error) {
 		X:     &x,
 		Y:     &y,
 	}
-	return &PubKeyECDSA{a, &pk}, nil
+	return &PubKeyECDSA{alg: a, goPubKey: &pk}, nil
 }
 
 func (a *ecdsaAlgo) decodePublicKey(der []byte) (PublicKey, error) {
@@ -273,6 +355,10 @@ type PubKeyECDSA struct {
 	alg *ecdsaAlgo
 	// public key data
 	goPubKey *goecdsa.PublicKey
+
+	// multiples of the public key for fixed-width verification, computed on first use
+	fixedOnce sync.Once
+	fixed     *secp256k1Tables
 }
 
 // Algorithm returns the the algo related to the private key
@@ -21,6 +21,7 @@ package hash
 import (
 	"bytes"
 	"encoding/hex"
+	"fmt"
 	"io"
 )
 
@@ -39,7 +40,11 @@ const (
 
 // String returns the string representation of this hashing algorithm.
 func (f HashingAlgorithm) String() string {
-	return [...]string{"UNKNOWN", "SHA2_256", "SHA2_384", "SHA3_256", "SHA3_384", "KMAC128"}[f]
+	names := [...]string{"UNKNOWN", "SHA2_256", "SHA2_384", "SHA3_256", "SHA3_384", "KMAC128"}
+	if f < 0 || int(f) >= len(names) {
+		return fmt.Sprintf("HashingAlgorithm(%d)", int(f))
+	}
+	return names[f]
 }
 
 const (
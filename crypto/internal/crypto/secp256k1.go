@@ -0,0 +1,948 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+// Fixed-width arithmetic for ECDSA verification on the secp256k1 curve.
+
+// Field elements and scalars are 256-bit integers stored in four 64-bit limbs, and
+// all operations run on values on the stack, without math/big allocations.
+// Field multiplications use the special form of the secp256k1 prime, and scalar
+// multiplications the Montgomery representation.
+//
+// Points are represented in Jacobian coordinates. u1*G + u2*Q is computed in a
+// single pass (Strauss-Shamir) after splitting both scalars in halves of about 128
+// bits with the curve endomorphism (GLV), using width-5 NAF digits and tables of
+// odd multiples in affine coordinates.
+
+// Verification only handles public data, so this implementation is not constant time.
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+
+	"github.com/btcsuite/btcd/btcec"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto/hash"
+)
+
+// fixedWidth is 1 when secp256k1 signatures are verified with fixed-width arithmetic.
+var fixedWidth int32
+
+// SetFixedWidthVerification enables or disables fixed-width arithmetic for the
+// verification of ECDSA secp256k1 signatures. It is disabled by default, and
+// signatures are then verified by crypto/ecdsa with math/big arithmetic.
+func SetFixedWidthVerification(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&fixedWidth, v)
+}
+
+func fixedWidthVerification() bool {
+	return atomic.LoadInt32(&fixedWidth) == 1
+}
+
+// u256 is a 256-bit unsigned integer in little-endian 64-bit limbs.
+type u256 [4]uint64
+
+// u256FromBytes returns the integer of a big-endian byte slice of at most 32 bytes.
+func u256FromBytes(b []byte) u256 {
+	var padded [32]byte
+	copy(padded[32-len(b):], b)
+
+	var x u256
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			x[3-i] = x[3-i]<<8 | uint64(padded[8*i+j])
+		}
+	}
+	return x
+}
+
+func (x *u256) isZero() bool {
+	return x[0]|x[1]|x[2]|x[3] == 0
+}
+
+// sub returns x - y and the borrow.
+func (x *u256) sub(y *u256) (u256, uint64) {
+	var z u256
+	var borrow uint64
+	z[0], borrow = bits.Sub64(x[0], y[0], 0)
+	z[1], borrow = bits.Sub64(x[1], y[1], borrow)
+	z[2], borrow = bits.Sub64(x[2], y[2], borrow)
+	z[3], borrow = bits.Sub64(x[3], y[3], borrow)
+	return z, borrow
+}
+
+// add returns x + y and the carry.
+func (x *u256) add(y *u256) (u256, uint64) {
+	var z u256
+	var carry uint64
+	z[0], carry = bits.Add64(x[0], y[0], 0)
+	z[1], carry = bits.Add64(x[1], y[1], carry)
+	z[2], carry = bits.Add64(x[2], y[2], carry)
+	z[3], carry = bits.Add64(x[3], y[3], carry)
+	return z, carry
+}
+
+func (x *u256) less(y *u256) bool {
+	_, borrow := x.sub(y)
+	return borrow == 1
+}
+
+// rsh1 returns x >> 1.
+func (x *u256) rsh1() u256 {
+	return u256{
+		x[0]>>1 | x[1]<<63,
+		x[1]>>1 | x[2]<<63,
+		x[2]>>1 | x[3]<<63,
+		x[3] >> 1,
+	}
+}
+
+func u256FromBig(x *big.Int) u256 {
+	return u256FromBytes(x.Bytes())
+}
+
+// A modulus is an odd 256-bit modulus m larger than 2^255, with the constants of
+// the Montgomery representation x*R mod m, where R = 2^256.
+type modulus struct {
+	m u256
+	// mInv is -m^-1 mod 2^64.
+	mInv uint64
+	// one is R mod m, the representation of 1.
+	one u256
+	// r2 is R^2 mod m, used to convert values to the Montgomery representation.
+	r2 u256
+	// exp is m - 2, the inversion exponent.
+	exp u256
+}
+
+func newModulus(m *big.Int) *modulus {
+	mod := &modulus{m: u256FromBig(m)}
+
+	// Newton iteration for the inverse of m modulo 2^64
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - mod.m[0]*inv
+	}
+	mod.mInv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), 256)
+	mod.one = u256FromBig(new(big.Int).Mod(r, m))
+	mod.r2 = u256FromBig(new(big.Int).Mod(new(big.Int).Mul(r, r), m))
+	mod.exp = u256FromBig(new(big.Int).Sub(m, big.NewInt(2)))
+
+	return mod
+}
+
+// mul returns x*y*R^-1 mod m (CIOS Montgomery multiplication).
+//
+// The product of x and y must be less than m*R, which holds if either is reduced.
+func (mod *modulus) mul(x, y *u256) u256 {
+	var t0, t1, t2, t3, t4, t5 uint64
+
+	for i := 0; i < 4; i++ {
+		yi := y[i]
+
+		// t += x*yi
+		var c, hi, lo, cc uint64
+		hi, lo = bits.Mul64(x[0], yi)
+		t0, cc = bits.Add64(t0, lo, 0)
+		c = hi + cc
+
+		hi, lo = bits.Mul64(x[1], yi)
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t1, cc = bits.Add64(t1, lo, 0)
+		c = hi + cc
+
+		hi, lo = bits.Mul64(x[2], yi)
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t2, cc = bits.Add64(t2, lo, 0)
+		c = hi + cc
+
+		hi, lo = bits.Mul64(x[3], yi)
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t3, cc = bits.Add64(t3, lo, 0)
+		c = hi + cc
+
+		t4, cc = bits.Add64(t4, c, 0)
+		t5 = cc
+
+		// t = (t + q*m) / 2^64, with q chosen so that the low limb cancels
+		q := t0 * mod.mInv
+
+		hi, lo = bits.Mul64(q, mod.m[0])
+		_, cc = bits.Add64(t0, lo, 0)
+		c = hi + cc
+
+		hi, lo = bits.Mul64(q, mod.m[1])
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t0, cc = bits.Add64(t1, lo, 0)
+		c = hi + cc
+
+		hi, lo = bits.Mul64(q, mod.m[2])
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t1, cc = bits.Add64(t2, lo, 0)
+		c = hi + cc
+
+		hi, lo = bits.Mul64(q, mod.m[3])
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		t2, cc = bits.Add64(t3, lo, 0)
+		c = hi + cc
+
+		t3, cc = bits.Add64(t4, c, 0)
+		t4 = t5 + cc
+	}
+
+	z := u256{t0, t1, t2, t3}
+	if t4 != 0 || !z.less(&mod.m) {
+		z, _ = z.sub(&mod.m)
+	}
+	return z
+}
+
+func (mod *modulus) add(x, y *u256) u256 {
+	z, carry := x.add(y)
+	t, borrow := z.sub(&mod.m)
+	if carry == 1 || borrow == 0 {
+		return t
+	}
+	return z
+}
+
+func (mod *modulus) sub(x, y *u256) u256 {
+	z, borrow := x.sub(y)
+	if borrow == 1 {
+		z, _ = z.add(&mod.m)
+	}
+	return z
+}
+
+// toMont returns the Montgomery representation of x.
+func (mod *modulus) toMont(x *u256) u256 {
+	return mod.mul(x, &mod.r2)
+}
+
+// fromMont returns the integer of a Montgomery representation.
+func (mod *modulus) fromMont(x *u256) u256 {
+	return mod.mul(x, &u256{1})
+}
+
+// inv returns the inverse of x by Fermat's little theorem, in the Montgomery
+// representation.
+func (mod *modulus) inv(x *u256) u256 {
+	z := mod.one
+	for i := 255; i >= 0; i-- {
+		z = mod.mul(&z, &z)
+		if mod.exp[i/64]>>(uint(i)%64)&1 == 1 {
+			z = mod.mul(&z, x)
+		}
+	}
+	return z
+}
+
+// mulWide returns the 512-bit product of x and y.
+func mulWide(x, y *u256) [8]uint64 {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			carry, t[i+j] = madd(x[i], y[j], t[i+j], carry)
+		}
+		t[i+4] = carry
+	}
+	return t
+}
+
+// madd returns the high and low limbs of a*b + c + d.
+func madd(a, b, c, d uint64) (hi, lo uint64) {
+	hi, lo = bits.Mul64(a, b)
+	var cc uint64
+	lo, cc = bits.Add64(lo, c, 0)
+	hi += cc
+	lo, cc = bits.Add64(lo, d, 0)
+	hi += cc
+	return hi, lo
+}
+
+// primeField is the base field of secp256k1, whose prime p = 2^256 - fieldC allows
+// fast reduction: 2^256 = fieldC (mod p).
+type primeField struct {
+	p u256
+	// exp is p - 2, the inversion exponent.
+	exp u256
+	// one is the element 1.
+	one u256
+}
+
+const fieldC = 0x1000003d1
+
+func newPrimeField(p *big.Int) *primeField {
+	return &primeField{
+		p:   u256FromBig(p),
+		exp: u256FromBig(new(big.Int).Sub(p, big.NewInt(2))),
+		one: u256{1},
+	}
+}
+
+// mul returns x*y mod p.
+func (f *primeField) mul(x, y *u256) u256 {
+	var t0, t1, t2, t3, t4, t5, t6, t7, c uint64
+
+	c, t0 = madd(x[0], y[0], 0, 0)
+	c, t1 = madd(x[0], y[1], 0, c)
+	c, t2 = madd(x[0], y[2], 0, c)
+	t4, t3 = madd(x[0], y[3], 0, c)
+
+	c, t1 = madd(x[1], y[0], t1, 0)
+	c, t2 = madd(x[1], y[1], t2, c)
+	c, t3 = madd(x[1], y[2], t3, c)
+	t5, t4 = madd(x[1], y[3], t4, c)
+
+	c, t2 = madd(x[2], y[0], t2, 0)
+	c, t3 = madd(x[2], y[1], t3, c)
+	c, t4 = madd(x[2], y[2], t4, c)
+	t6, t5 = madd(x[2], y[3], t5, c)
+
+	c, t3 = madd(x[3], y[0], t3, 0)
+	c, t4 = madd(x[3], y[1], t4, c)
+	c, t5 = madd(x[3], y[2], t5, c)
+	t7, t6 = madd(x[3], y[3], t6, c)
+
+	// fold the high limbs: t = t_lo + t_hi*fieldC
+	c, t0 = madd(t4, fieldC, t0, 0)
+	c, t1 = madd(t5, fieldC, t1, c)
+	c, t2 = madd(t6, fieldC, t2, c)
+	c, t3 = madd(t7, fieldC, t3, c)
+
+	// fold the remaining limb
+	hi, lo := bits.Mul64(c, fieldC)
+	var cc uint64
+	t0, cc = bits.Add64(t0, lo, 0)
+	t1, cc = bits.Add64(t1, hi, cc)
+	t2, cc = bits.Add64(t2, 0, cc)
+	t3, cc = bits.Add64(t3, 0, cc)
+
+	z := u256{t0, t1, t2, t3}
+	if cc == 1 {
+		// z is small after wrapping around 2^256, the last fold cannot overflow
+		z, _ = z.add(&u256{fieldC})
+	}
+
+	if !z.less(&f.p) {
+		z, _ = z.sub(&f.p)
+	}
+	return z
+}
+
+func (f *primeField) add(x, y *u256) u256 {
+	z, carry := x.add(y)
+	t, borrow := z.sub(&f.p)
+	if carry == 1 || borrow == 0 {
+		return t
+	}
+	return z
+}
+
+func (f *primeField) sub(x, y *u256) u256 {
+	z, borrow := x.sub(y)
+	if borrow == 1 {
+		z, _ = z.add(&f.p)
+	}
+	return z
+}
+
+// inv returns the inverse of x by Fermat's little theorem.
+func (f *primeField) inv(x *u256) u256 {
+	z := f.one
+	for i := 255; i >= 0; i-- {
+		z = f.mul(&z, &z)
+		if f.exp[i/64]>>(uint(i)%64)&1 == 1 {
+			z = f.mul(&z, x)
+		}
+	}
+	return z
+}
+
+// jacobian is a point (x/z^2, y/z^3) of the curve; z is zero for the point at
+// infinity.
+type jacobian struct {
+	x, y, z u256
+}
+
+// affine is a point (x, y) of the curve.
+type affine struct {
+	x, y u256
+}
+
+// nafWidth is the window width of NAF digits, which are odd and lie in (-2^4, 2^4).
+const nafWidth = 5
+
+// oddMultiples are the multiples 1*P, 3*P, ..., 15*P of a point.
+type oddMultiples [1 << (nafWidth - 2)]affine
+
+// secp256k1Tables are the precomputed multiples of a point P and of lambda*P.
+type secp256k1Tables struct {
+	p, lambdaP oddMultiples
+}
+
+// secp256k1Params are the fixed-width parameters of the secp256k1 curve.
+type secp256k1Params struct {
+	p *primeField
+	n *modulus
+	// pMinusN is p - n.
+	pMinusN u256
+	// halfN is (n-1)/2: scalars above it are negated after decomposition.
+	halfN u256
+
+	// beta is the cube root of unity modulo p such that lambda*(x, y) = (beta*x, y).
+	beta u256
+	// lambda is the cube root of unity modulo n, in the Montgomery representation.
+	lambda u256
+	// g1 and g2 are the rounded 2^384*b2/n and 2^384*-b1/n of the decomposition.
+	g1, g2 u256
+	// minusB1 and minusB2 are -b1 and -b2 modulo n, in the Montgomery representation.
+	minusB1, minusB2 u256
+
+	// g holds the multiples of the base point.
+	g *secp256k1Tables
+}
+
+var (
+	secp256k1FixedOnce sync.Once
+	secp256k1Fixed     *secp256k1Params
+)
+
+func secp256k1FixedParams() *secp256k1Params {
+	secp256k1FixedOnce.Do(func() {
+		secp256k1Fixed = newSecp256k1Params()
+	})
+	return secp256k1Fixed
+}
+
+// newSecp256k1Params derives the endomorphism and decomposition constants from the
+// curve parameters.
+func newSecp256k1Params() *secp256k1Params {
+	curve := btcec.S256()
+	params := curve.Params()
+	p, n := params.P, params.N
+
+	c := &secp256k1Params{
+		p:       newPrimeField(p),
+		n:       newModulus(n),
+		pMinusN: u256FromBig(new(big.Int).Sub(p, n)),
+		halfN:   u256FromBig(new(big.Int).Rsh(n, 1)),
+	}
+
+	beta := cubeRootOfUnity(p)
+
+	// of the two roots modulo n, the smaller one has a decomposition basis whose
+	// vectors fit in 128 bits
+	lambda := cubeRootOfUnity(n)
+	if lambdaSquared := new(big.Int).Mod(new(big.Int).Mul(lambda, lambda), n); lambdaSquared.Cmp(lambda) < 0 {
+		lambda = lambdaSquared
+	}
+
+	// the roots come in pairs: pick the beta that matches lambda on the base point
+	lambdaGx, _ := curve.ScalarBaseMult(lambda.Bytes())
+	if new(big.Int).Mod(new(big.Int).Mul(beta, params.Gx), p).Cmp(lambdaGx) != 0 {
+		beta.Mul(beta, beta).Mod(beta, p)
+	}
+
+	betaInt := u256FromBig(beta)
+	lambdaInt := u256FromBig(lambda)
+	c.beta = betaInt
+	c.lambda = c.n.toMont(&lambdaInt)
+
+	_, b1, _, b2 := latticeBasis(n, lambda)
+	if b1.Sign() >= 0 || b2.Sign() <= 0 {
+		panic("crypto: unexpected secp256k1 decomposition basis")
+	}
+
+	g1 := roundedDiv(new(big.Int).Lsh(b2, 384), n)
+	g2 := roundedDiv(new(big.Int).Lsh(new(big.Int).Neg(b1), 384), n)
+	if g1.BitLen() > 256 || g2.BitLen() > 256 {
+		panic("crypto: unexpected secp256k1 decomposition basis")
+	}
+	c.g1 = u256FromBig(g1)
+	c.g2 = u256FromBig(g2)
+
+	minusB1 := u256FromBig(new(big.Int).Mod(new(big.Int).Neg(b1), n))
+	minusB2 := u256FromBig(new(big.Int).Mod(new(big.Int).Neg(b2), n))
+	c.minusB1 = c.n.toMont(&minusB1)
+	c.minusB2 = c.n.toMont(&minusB2)
+
+	c.g = c.tables(params.Gx, params.Gy)
+
+	return c
+}
+
+// cubeRootOfUnity returns a non-trivial cube root of unity modulo the prime m.
+func cubeRootOfUnity(m *big.Int) *big.Int {
+	exp := new(big.Int).Sub(m, big.NewInt(1))
+	exp.Div(exp, big.NewInt(3))
+
+	one := big.NewInt(1)
+	for g := int64(2); ; g++ {
+		root := new(big.Int).Exp(big.NewInt(g), exp, m)
+		if root.Cmp(one) != 0 {
+			return root
+		}
+	}
+}
+
+// latticeBasis returns short vectors (a1, b1), (a2, b2) such that a + b*lambda = 0
+// modulo n, found with the extended Euclidean algorithm (Guide to Elliptic Curve
+// Cryptography, algorithm 3.74).
+func latticeBasis(n, lambda *big.Int) (a1, b1, a2, b2 *big.Int) {
+	sqrtN := new(big.Int).Sqrt(n)
+
+	// r[i] = s[i]*n + t[i]*lambda
+	rPrev, r := new(big.Int).Set(n), new(big.Int).Set(lambda)
+	tPrev, t := big.NewInt(0), big.NewInt(1)
+
+	for r.Cmp(sqrtN) >= 0 {
+		q := new(big.Int).Div(rPrev, r)
+		rPrev, r = r, new(big.Int).Sub(rPrev, new(big.Int).Mul(q, r))
+		tPrev, t = t, new(big.Int).Sub(tPrev, new(big.Int).Mul(q, t))
+	}
+
+	// r is the first remainder below sqrt(n), rPrev the last one above
+	a1, b1 = r, new(big.Int).Neg(t)
+
+	q := new(big.Int).Div(rPrev, r)
+	rNext := new(big.Int).Sub(rPrev, new(big.Int).Mul(q, r))
+	tNext := new(big.Int).Sub(tPrev, new(big.Int).Mul(q, t))
+
+	norm := func(a, b *big.Int) *big.Int {
+		return new(big.Int).Add(new(big.Int).Mul(a, a), new(big.Int).Mul(b, b))
+	}
+
+	if norm(rPrev, tPrev).Cmp(norm(rNext, tNext)) <= 0 {
+		a2, b2 = rPrev, new(big.Int).Neg(tPrev)
+	} else {
+		a2, b2 = rNext, new(big.Int).Neg(tNext)
+	}
+
+	return a1, b1, a2, b2
+}
+
+// roundedDiv returns x/y rounded to the nearest integer, for positive x and y.
+func roundedDiv(x, y *big.Int) *big.Int {
+	q := new(big.Int).Lsh(x, 1)
+	q.Add(q, y)
+	return q.Div(q, new(big.Int).Lsh(y, 1))
+}
+
+// tables returns the odd multiples of an affine point and of its endomorphism image.
+func (c *secp256k1Params) tables(x, y *big.Int) *secp256k1Tables {
+	point := jacobian{
+		x: u256FromBig(x),
+		y: u256FromBig(y),
+		z: c.p.one,
+	}
+
+	var multiples [len(oddMultiples{})]jacobian
+	multiples[0] = point
+	twice := c.double(&point)
+	for i := 1; i < len(multiples); i++ {
+		multiples[i] = c.addPoints(&multiples[i-1], &twice)
+	}
+
+	t := &secp256k1Tables{}
+	c.normalize(multiples[:], t.p[:])
+
+	for i, m := range t.p {
+		t.lambdaP[i] = affine{x: c.p.mul(&m.x, &c.beta), y: m.y}
+	}
+
+	return t
+}
+
+// normalize converts points to affine coordinates with a single inversion
+// (Montgomery's trick). Points must not be at infinity.
+func (c *secp256k1Params) normalize(points []jacobian, out []affine) {
+	f := c.p
+
+	// prefix[i] is the product of the z coordinates of points 0 to i
+	prefix := make([]u256, len(points))
+	prefix[0] = points[0].z
+	for i := 1; i < len(points); i++ {
+		prefix[i] = f.mul(&prefix[i-1], &points[i].z)
+	}
+
+	inv := f.inv(&prefix[len(points)-1])
+	for i := len(points) - 1; i >= 0; i-- {
+		zInv := inv
+		if i > 0 {
+			zInv = f.mul(&inv, &prefix[i-1])
+			inv = f.mul(&inv, &points[i].z)
+		}
+
+		zInv2 := f.mul(&zInv, &zInv)
+		zInv3 := f.mul(&zInv2, &zInv)
+		out[i] = affine{
+			x: f.mul(&points[i].x, &zInv2),
+			y: f.mul(&points[i].y, &zInv3),
+		}
+	}
+}
+
+// double returns 2*p (dbl-2009-l, a = 0).
+func (c *secp256k1Params) double(p *jacobian) jacobian {
+	if p.z.isZero() {
+		return *p
+	}
+
+	f := c.p
+	a := f.mul(&p.x, &p.x)
+	b := f.mul(&p.y, &p.y)
+	cc := f.mul(&b, &b)
+
+	d := f.add(&p.x, &b)
+	d = f.mul(&d, &d)
+	d = f.sub(&d, &a)
+	d = f.sub(&d, &cc)
+	d = f.add(&d, &d)
+
+	e := f.add(&a, &a)
+	e = f.add(&e, &a)
+
+	var r jacobian
+	r.x = f.mul(&e, &e)
+	d2 := f.add(&d, &d)
+	r.x = f.sub(&r.x, &d2)
+
+	c8 := f.add(&cc, &cc)
+	c8 = f.add(&c8, &c8)
+	c8 = f.add(&c8, &c8)
+	r.y = f.sub(&d, &r.x)
+	r.y = f.mul(&e, &r.y)
+	r.y = f.sub(&r.y, &c8)
+
+	r.z = f.mul(&p.y, &p.z)
+	r.z = f.add(&r.z, &r.z)
+
+	return r
+}
+
+// addPoints returns p + q (add-2007-bl).
+func (c *secp256k1Params) addPoints(p, q *jacobian) jacobian {
+	if p.z.isZero() {
+		return *q
+	}
+	if q.z.isZero() {
+		return *p
+	}
+
+	f := c.p
+	z1z1 := f.mul(&p.z, &p.z)
+	z2z2 := f.mul(&q.z, &q.z)
+	u1 := f.mul(&p.x, &z2z2)
+	u2 := f.mul(&q.x, &z1z1)
+	s1 := f.mul(&p.y, &q.z)
+	s1 = f.mul(&s1, &z2z2)
+	s2 := f.mul(&q.y, &p.z)
+	s2 = f.mul(&s2, &z1z1)
+
+	h := f.sub(&u2, &u1)
+	rr := f.sub(&s2, &s1)
+
+	if h.isZero() {
+		if rr.isZero() {
+			return c.double(p)
+		}
+		return jacobian{}
+	}
+
+	i := f.add(&h, &h)
+	i = f.mul(&i, &i)
+	j := f.mul(&h, &i)
+	rr = f.add(&rr, &rr)
+	v := f.mul(&u1, &i)
+
+	var r jacobian
+	r.x = f.mul(&rr, &rr)
+	r.x = f.sub(&r.x, &j)
+	v2 := f.add(&v, &v)
+	r.x = f.sub(&r.x, &v2)
+
+	s1j := f.mul(&s1, &j)
+	s1j = f.add(&s1j, &s1j)
+	r.y = f.sub(&v, &r.x)
+	r.y = f.mul(&rr, &r.y)
+	r.y = f.sub(&r.y, &s1j)
+
+	r.z = f.add(&p.z, &q.z)
+	r.z = f.mul(&r.z, &r.z)
+	r.z = f.sub(&r.z, &z1z1)
+	r.z = f.sub(&r.z, &z2z2)
+	r.z = f.mul(&r.z, &h)
+
+	return r
+}
+
+// addAffine returns p + q for an affine q (madd-2007-bl).
+func (c *secp256k1Params) addAffine(p *jacobian, q *affine) jacobian {
+	f := c.p
+
+	if p.z.isZero() {
+		return jacobian{x: q.x, y: q.y, z: f.one}
+	}
+
+	z1z1 := f.mul(&p.z, &p.z)
+	u2 := f.mul(&q.x, &z1z1)
+	s2 := f.mul(&q.y, &p.z)
+	s2 = f.mul(&s2, &z1z1)
+
+	h := f.sub(&u2, &p.x)
+	rr := f.sub(&s2, &p.y)
+
+	if h.isZero() {
+		if rr.isZero() {
+			return c.double(p)
+		}
+		return jacobian{}
+	}
+
+	hh := f.mul(&h, &h)
+	i := f.add(&hh, &hh)
+	i = f.add(&i, &i)
+	j := f.mul(&h, &i)
+	rr = f.add(&rr, &rr)
+	v := f.mul(&p.x, &i)
+
+	var r jacobian
+	r.x = f.mul(&rr, &rr)
+	r.x = f.sub(&r.x, &j)
+	v2 := f.add(&v, &v)
+	r.x = f.sub(&r.x, &v2)
+
+	y1j := f.mul(&p.y, &j)
+	y1j = f.add(&y1j, &y1j)
+	r.y = f.sub(&v, &r.x)
+	r.y = f.mul(&rr, &r.y)
+	r.y = f.sub(&r.y, &y1j)
+
+	r.z = f.add(&p.z, &h)
+	r.z = f.mul(&r.z, &r.z)
+	r.z = f.sub(&r.z, &z1z1)
+	r.z = f.sub(&r.z, &hh)
+
+	return r
+}
+
+// decompose splits a scalar k into k1 + k2*lambda modulo n, where k1 and k2 are
+// returned as absolute values of about 128 bits with their signs.
+func (c *secp256k1Params) decompose(k *u256) (k1, k2 u256, neg1, neg2 bool) {
+	c1 := roundedShift384(mulWide(k, &c.g1))
+	c2 := roundedShift384(mulWide(k, &c.g2))
+
+	// k2 = c1*-b1 + c2*-b2, k1 = k - k2*lambda
+	t1 := c.n.mul(&c1, &c.minusB1)
+	t2 := c.n.mul(&c2, &c.minusB2)
+	k2 = c.n.add(&t1, &t2)
+
+	k2Lambda := c.n.mul(&k2, &c.lambda)
+	k1 = c.n.sub(k, &k2Lambda)
+
+	k1, neg1 = c.abs(&k1)
+	k2, neg2 = c.abs(&k2)
+	return k1, k2, neg1, neg2
+}
+
+// abs returns the absolute value of a scalar in (-n/2, n/2], and whether it is negative.
+func (c *secp256k1Params) abs(k *u256) (u256, bool) {
+	if c.halfN.less(k) {
+		negated, _ := c.n.m.sub(k)
+		return negated, true
+	}
+	return *k, false
+}
+
+// roundedShift384 returns t >> 384, rounded to the nearest integer.
+func roundedShift384(t [8]uint64) u256 {
+	x := u256{t[6], t[7]}
+	if t[5]>>63 == 1 {
+		x, _ = x.add(&u256{1})
+	}
+	return x
+}
+
+// nafDigits are the width-5 NAF digits of a scalar, least significant first.
+type nafDigits [257]int8
+
+// naf returns the width-5 NAF digits of k and their count.
+func naf(k u256) (nafDigits, int) {
+	var digits nafDigits
+	n := 0
+
+	for i := 0; !k.isZero(); i++ {
+		if k[0]&1 == 1 {
+			d := int64(k[0] & (1<<nafWidth - 1))
+			if d >= 1<<(nafWidth-1) {
+				d -= 1 << nafWidth
+			}
+			digits[i] = int8(d)
+
+			if d > 0 {
+				k, _ = k.sub(&u256{uint64(d)})
+			} else {
+				k, _ = k.add(&u256{uint64(-d)})
+			}
+		}
+
+		k = k.rsh1()
+		n = i + 1
+	}
+
+	return digits, n
+}
+
+// term is a scalar multiplication k*P in a multi-scalar multiplication.
+type term struct {
+	digits nafDigits
+	length int
+	table  *oddMultiples
+	neg    bool
+}
+
+func newTerm(k u256, neg bool, table *oddMultiples) term {
+	digits, length := naf(k)
+	return term{digits: digits, length: length, table: table, neg: neg}
+}
+
+// multiply returns the sum of the terms.
+func (c *secp256k1Params) multiply(terms []term) jacobian {
+	length := 0
+	for i := range terms {
+		if terms[i].length > length {
+			length = terms[i].length
+		}
+	}
+
+	var point jacobian
+	for i := length - 1; i >= 0; i-- {
+		point = c.double(&point)
+
+		for j := range terms {
+			d := terms[j].digits[i]
+			if d == 0 {
+				continue
+			}
+
+			neg := terms[j].neg
+			if d < 0 {
+				d, neg = -d, !neg
+			}
+
+			q := terms[j].table[d/2]
+			if neg {
+				q.y = c.p.sub(&u256{}, &q.y)
+			}
+
+			point = c.addAffine(&point, &q)
+		}
+	}
+
+	return point
+}
+
+// verify returns true if (r, s) is a valid signature of the hash h for the public
+// key whose multiples are in q.
+func (c *secp256k1Params) verify(q *secp256k1Tables, sig Signature, h hash.Hash) bool {
+	if len(sig) != 64 {
+		return false
+	}
+
+	r := u256FromBytes(sig[:32])
+	s := u256FromBytes(sig[32:])
+	if r.isZero() || s.isZero() || !r.less(&c.n.m) || !s.less(&c.n.m) {
+		return false
+	}
+
+	// the hash is truncated to the bit length of the curve order
+	digest := []byte(h)
+	if len(digest) > 32 {
+		digest = digest[:32]
+	}
+	e := u256FromBytes(digest)
+
+	// w = s^-1 in the Montgomery representation, so that multiplying it with a plain
+	// integer yields a plain integer
+	w := c.n.toMont(&s)
+	w = c.n.inv(&w)
+	u1 := c.n.mul(&e, &w)
+	u2 := c.n.mul(&r, &w)
+
+	u1a, u1b, neg1a, neg1b := c.decompose(&u1)
+	u2a, u2b, neg2a, neg2b := c.decompose(&u2)
+
+	point := c.multiply([]term{
+		newTerm(u1a, neg1a, &c.g.p),
+		newTerm(u1b, neg1b, &c.g.lambdaP),
+		newTerm(u2a, neg2a, &q.p),
+		newTerm(u2b, neg2b, &q.lambdaP),
+	})
+
+	if point.z.isZero() {
+		return false
+	}
+
+	// the x coordinate x/z^2 reduced modulo n equals r if x = r*z^2, or, when
+	// r < p - n, if x = (r+n)*z^2
+	zz := c.p.mul(&point.z, &point.z)
+
+	candidate := c.p.mul(&r, &zz)
+	if candidate == point.x {
+		return true
+	}
+
+	if !r.less(&c.pMinusN) {
+		return false
+	}
+
+	rn, _ := r.add(&c.n.m)
+	candidate = c.p.mul(&rn, &zz)
+	return candidate == point.x
+}
+
+// fixedTables returns the multiples of the public key, computed on first use.
+func (pk *PubKeyECDSA) fixedTables(c *secp256k1Params) *secp256k1Tables {
+	pk.fixedOnce.Do(func() {
+		pk.fixed = c.tables(pk.goPubKey.X, pk.goPubKey.Y)
+	})
+	return pk.fixed
+}
+
+// verifyHashFixed verifies a secp256k1 signature with fixed-width arithmetic.
+func (pk *PubKeyECDSA) verifyHashFixed(sig Signature, h hash.Hash) bool {
+	c := secp256k1FixedParams()
+	return c.verify(pk.fixedTables(c), sig, h)
+}
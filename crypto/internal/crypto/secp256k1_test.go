@@ -0,0 +1,400 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	goecdsa "crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto/hash"
+)
+
+func (x u256) big() *big.Int {
+	var b [32]byte
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			b[31-8*i-j] = byte(x[i] >> (8 * uint(j)))
+		}
+	}
+	return new(big.Int).SetBytes(b[:])
+}
+
+func randomBelow(t *testing.T, m *big.Int) *big.Int {
+	v, err := rand.Int(rand.Reader, m)
+	require.NoError(t, err)
+	return v
+}
+
+func TestModulusArithmetic(t *testing.T) {
+	curve := btcec.S256().Params()
+
+	for _, m := range []*big.Int{curve.N, curve.P} {
+		mod := newModulus(m)
+		assert.Equal(t, 0, mod.m.big().Cmp(m))
+		assert.Equal(t, uint64(0), mod.m[0]*mod.mInv+1)
+
+		edge := new(big.Int).Sub(m, big.NewInt(1))
+		for i := 0; i < 200; i++ {
+			a, b := randomBelow(t, m), randomBelow(t, m)
+			if i == 0 {
+				a, b = edge, edge
+			}
+			x, y := u256FromBytes(a.Bytes()), u256FromBytes(b.Bytes())
+			xm, ym := mod.toMont(&x), mod.toMont(&y)
+
+			expected := new(big.Int).Mul(a, b)
+			expected.Mod(expected, m)
+			product := mod.mul(&xm, &ym)
+			assert.Equal(t, 0, mod.fromMont(&product).big().Cmp(expected))
+
+			expected = new(big.Int).Add(a, b)
+			expected.Mod(expected, m)
+			sum := mod.add(&x, &y)
+			assert.Equal(t, 0, sum.big().Cmp(expected))
+
+			expected = new(big.Int).Sub(a, b)
+			expected.Mod(expected, m)
+			difference := mod.sub(&x, &y)
+			assert.Equal(t, 0, difference.big().Cmp(expected))
+
+			if a.Sign() != 0 {
+				expected = new(big.Int).ModInverse(a, m)
+				inverse := mod.inv(&xm)
+				assert.Equal(t, 0, mod.fromMont(&inverse).big().Cmp(expected))
+			}
+		}
+	}
+}
+
+func TestPrimeFieldArithmetic(t *testing.T) {
+	p := btcec.S256().Params().P
+	f := newPrimeField(p)
+
+	edge := new(big.Int).Sub(p, big.NewInt(1))
+	for i := 0; i < 200; i++ {
+		a, b := randomBelow(t, p), randomBelow(t, p)
+		if i == 0 {
+			a, b = edge, edge
+		}
+		x, y := u256FromBytes(a.Bytes()), u256FromBytes(b.Bytes())
+
+		expected := new(big.Int).Mul(a, b)
+		expected.Mod(expected, p)
+		product := f.mul(&x, &y)
+		assert.Equal(t, 0, product.big().Cmp(expected))
+
+		expected = new(big.Int).Add(a, b)
+		expected.Mod(expected, p)
+		sum := f.add(&x, &y)
+		assert.Equal(t, 0, sum.big().Cmp(expected))
+
+		expected = new(big.Int).Sub(a, b)
+		expected.Mod(expected, p)
+		difference := f.sub(&x, &y)
+		assert.Equal(t, 0, difference.big().Cmp(expected))
+
+		if a.Sign() != 0 {
+			expected = new(big.Int).ModInverse(a, p)
+			inverse := f.inv(&x)
+			assert.Equal(t, 0, inverse.big().Cmp(expected))
+		}
+	}
+}
+
+func TestSecp256k1Decomposition(t *testing.T) {
+	c := secp256k1FixedParams()
+	n := btcec.S256().Params().N
+	lambda := c.n.fromMont(&c.lambda)
+	limit := new(big.Int).Lsh(big.NewInt(1), 129)
+
+	for i := 0; i < 200; i++ {
+		k := u256FromBytes(randomBelow(t, n).Bytes())
+
+		k1, k2, neg1, neg2 := c.decompose(&k)
+		assert.True(t, k1.big().Cmp(limit) < 0)
+		assert.True(t, k2.big().Cmp(limit) < 0)
+
+		signed := func(x u256, neg bool) *big.Int {
+			if neg {
+				return new(big.Int).Neg(x.big())
+			}
+			return x.big()
+		}
+
+		// k = k1 + k2*lambda (mod n)
+		sum := new(big.Int).Mul(signed(k2, neg2), lambda.big())
+		sum.Add(sum, signed(k1, neg1))
+		sum.Mod(sum, n)
+		assert.Equal(t, 0, sum.Cmp(k.big()))
+	}
+}
+
+func TestSecp256k1FixedWidthVerification(t *testing.T) {
+	halg := hash.NewSHA3_256()
+
+	for i := 0; i < 20; i++ {
+		seed := make([]byte, KeyGenSeedMinLenECDSASecp256k1)
+		_, err := rand.Read(seed)
+		require.NoError(t, err)
+
+		sk, err := GeneratePrivateKey(ECDSASecp256k1, seed)
+		require.NoError(t, err)
+		pk := sk.PublicKey().(*PubKeyECDSA)
+
+		message := seed[:i+1]
+		sig, err := sk.Sign(message, halg)
+		require.NoError(t, err)
+		h := halg.ComputeHash(message)
+
+		assert.True(t, pk.verifyHashFixed(sig, h))
+
+		// a modified signature must be rejected by both implementations
+		tampered := make(Signature, len(sig))
+		copy(tampered, sig)
+		tampered[i] ^= 1
+
+		expected := pk.verifyHashBig(tampered, h)
+		assert.False(t, expected)
+		assert.Equal(t, expected, pk.verifyHashFixed(tampered, h))
+
+		// the signature of another message must be rejected
+		assert.False(t, pk.verifyHashFixed(sig, halg.ComputeHash(append(message, 0))))
+	}
+
+	t.Run("Out of range", func(t *testing.T) {
+		sk, err := GeneratePrivateKey(ECDSASecp256k1, make([]byte, KeyGenSeedMinLenECDSASecp256k1))
+		require.NoError(t, err)
+		pk := sk.PublicKey().(*PubKeyECDSA)
+		h := halg.ComputeHash([]byte("message"))
+
+		n := btcec.S256().N.Bytes()
+		assert.False(t, pk.verifyHashFixed(append(make([]byte, 32), n...), h))
+		assert.False(t, pk.verifyHashFixed(append(n, make([]byte, 32)...), h))
+		assert.False(t, pk.verifyHashFixed(make([]byte, 64), h))
+		assert.False(t, pk.verifyHashFixed(make([]byte, 63), h))
+	})
+}
+
+// newSecp256k1PublicKey returns the public key of a point of the curve.
+func newSecp256k1PublicKey(x, y *big.Int) *PubKeyECDSA {
+	return &PubKeyECDSA{
+		alg:      newECDSASecp256k1(),
+		goPubKey: &goecdsa.PublicKey{Curve: btcec.S256(), X: x, Y: y},
+	}
+}
+
+// encodeSignature returns the signature of r and s, each padded to 32 bytes.
+func encodeSignature(r, s *big.Int) Signature {
+	sig := make(Signature, 64)
+	copy(sig[32-len(r.Bytes()):32], r.Bytes())
+	copy(sig[64-len(s.Bytes()):], s.Bytes())
+	return sig
+}
+
+// signWithNonce returns the signature of the integer e with the private key d and
+// the nonce k, without normalizing s.
+func signWithNonce(d, k, e *big.Int) (r, s *big.Int) {
+	curve := btcec.S256()
+	n := curve.N
+
+	x, _ := curve.ScalarBaseMult(k.Bytes())
+	r = new(big.Int).Mod(x, n)
+
+	s = new(big.Int).Mul(r, d)
+	s.Add(s, e)
+	s.Mul(s, new(big.Int).ModInverse(k, n))
+	s.Mod(s, n)
+
+	return r, s
+}
+
+// hashOf returns the 32-byte hash encoding the integer e.
+func hashOf(e *big.Int) hash.Hash {
+	h := make(hash.Hash, 32)
+	copy(h[32-len(e.Bytes()):], e.Bytes())
+	return h
+}
+
+// TestSecp256k1FixedWidthDifferential checks that fixed-width verification agrees
+// with crypto/ecdsa on random and edge-case signatures.
+func TestSecp256k1FixedWidthDifferential(t *testing.T) {
+	curve := btcec.S256()
+	n := curve.N
+	one := big.NewInt(1)
+
+	verified := 0
+	check := func(pk *PubKeyECDSA, sig Signature, h hash.Hash) bool {
+		expected := pk.verifyHashBig(sig, h)
+		require.Equal(
+			t,
+			expected,
+			pk.verifyHashFixed(sig, h),
+			"key (%x, %x), signature %x, hash %x",
+			pk.goPubKey.X, pk.goPubKey.Y, []byte(sig), []byte(h),
+		)
+		verified++
+		return expected
+	}
+
+	keyOf := func(d *big.Int) *PubKeyECDSA {
+		x, y := curve.ScalarBaseMult(d.Bytes())
+		return newSecp256k1PublicKey(x, y)
+	}
+
+	t.Run("Random", func(t *testing.T) {
+		for i := 0; i < 2000; i++ {
+			d := new(big.Int).Add(randomBelow(t, new(big.Int).Sub(n, one)), one)
+			k := new(big.Int).Add(randomBelow(t, new(big.Int).Sub(n, one)), one)
+			e := randomBelow(t, new(big.Int).Lsh(one, 256))
+			pk := keyOf(d)
+			h := hashOf(e)
+
+			r, s := signWithNonce(d, k, e)
+			assert.True(t, check(pk, encodeSignature(r, s), h))
+
+			// high s values are accepted
+			assert.True(t, check(pk, encodeSignature(r, new(big.Int).Sub(n, s)), h))
+
+			assert.False(t, check(pk, encodeSignature(new(big.Int).Add(r, one), s), h))
+			assert.False(t, check(pk, encodeSignature(r, new(big.Int).Add(s, one)), h))
+			assert.False(t, check(pk, encodeSignature(r, s), hashOf(new(big.Int).Xor(e, one))))
+		}
+	})
+
+	t.Run("Edge keys and hashes", func(t *testing.T) {
+		lambda := cubeRootOfUnity(n)
+		maxHash := new(big.Int).Sub(new(big.Int).Lsh(one, 256), one)
+
+		keys := []*big.Int{
+			big.NewInt(1),
+			big.NewInt(2),
+			new(big.Int).Sub(n, one),
+			new(big.Int).Sub(n, big.NewInt(2)),
+			lambda,
+			new(big.Int).Sub(n, lambda),
+		}
+
+		for _, d := range keys {
+			pk := keyOf(d)
+
+			for i := 0; i < 20; i++ {
+				k := new(big.Int).Add(randomBelow(t, new(big.Int).Sub(n, one)), one)
+				r, _ := signWithNonce(d, k, new(big.Int))
+
+				rd := new(big.Int).Mul(r, d)
+				rd.Mod(rd, n)
+
+				hashes := []*big.Int{
+					new(big.Int),
+					one,
+					new(big.Int).Sub(n, one),
+					new(big.Int).Set(n),
+					maxHash,
+					// u1*G = u2*Q, so that both halves of the sum are equal
+					rd,
+				}
+
+				for _, e := range hashes {
+					_, s := signWithNonce(d, k, e)
+					if s.Sign() == 0 {
+						continue
+					}
+					assert.True(t, check(pk, encodeSignature(r, s), hashOf(e)))
+				}
+
+				// u1*G = -u2*Q, so that the sum is the point at infinity
+				e := new(big.Int).Sub(n, rd)
+				e.Mod(e, n)
+				s := randomBelow(t, new(big.Int).Sub(n, one))
+				s.Add(s, one)
+				assert.False(t, check(pk, encodeSignature(r, s), hashOf(e)))
+
+				// hashes longer than the curve order are truncated
+				long := append(hashOf(e), 0xff, 0xff)
+				check(pk, encodeSignature(r, s), long)
+			}
+
+			h := hashOf(big.NewInt(42))
+			for _, sig := range []Signature{
+				encodeSignature(one, one),
+				encodeSignature(one, new(big.Int).Sub(n, one)),
+				encodeSignature(new(big.Int).Sub(n, one), one),
+				encodeSignature(new(big.Int).Sub(n, one), new(big.Int).Sub(n, one)),
+				encodeSignature(n, one),
+				encodeSignature(one, n),
+				encodeSignature(maxHash, maxHash),
+				encodeSignature(new(big.Int), one),
+			} {
+				assert.False(t, check(pk, sig, h))
+			}
+		}
+	})
+
+	t.Run("Large x coordinate", func(t *testing.T) {
+		// points R whose x coordinate is above n, so that r = x - n
+		points := 0
+		for x := new(big.Int).Add(n, one); points < 5 && x.Cmp(curve.P) < 0; x.Add(x, one) {
+			y2 := new(big.Int).Exp(x, big.NewInt(3), curve.P)
+			y2.Add(y2, curve.B)
+			y2.Mod(y2, curve.P)
+
+			y := new(big.Int).ModSqrt(y2, curve.P)
+			if y == nil {
+				continue
+			}
+			points++
+
+			r := new(big.Int).Sub(x, n)
+			for i := 0; i < 20; i++ {
+				s := new(big.Int).Add(randomBelow(t, new(big.Int).Sub(n, one)), one)
+				e := randomBelow(t, n)
+
+				// the key Q = r^-1 * (s*R - e*G), for which (r, s) is a signature of e
+				sx, sy := curve.ScalarMult(x, y, s.Bytes())
+				ex, ey := curve.ScalarBaseMult(new(big.Int).Sub(n, e).Bytes())
+				qx, qy := curve.Add(sx, sy, ex, ey)
+				qx, qy = curve.ScalarMult(qx, qy, new(big.Int).ModInverse(r, n).Bytes())
+				pk := newSecp256k1PublicKey(qx, qy)
+
+				assert.True(t, check(pk, encodeSignature(r, s), hashOf(e)))
+				assert.True(t, check(pk, encodeSignature(r, new(big.Int).Sub(n, s)), hashOf(e)))
+
+				// the unreduced x coordinate is out of range
+				assert.False(t, check(pk, encodeSignature(x, s), hashOf(e)))
+			}
+		}
+		require.Equal(t, 5, points)
+	})
+
+	t.Logf("%d signatures verified by both implementations", verified)
+}
+
+func BenchmarkECDSASecp256k1VerifyFixedWidth(b *testing.B) {
+	SetFixedWidthVerification(true)
+	defer SetFixedWidthVerification(false)
+
+	halg := hash.NewSHA3_256()
+	benchVerify(b, ECDSASecp256k1, halg)
+}
@@ -20,6 +20,7 @@ package crypto
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto/hash"
 )
@@ -55,6 +56,16 @@ func GeneratePrivateKey(algo SigningAlgorithm, seed []byte) (PrivateKey, error)
 	return signer.generatePrivateKey(seed)
 }
 
+// SignWithRandom signs data with the private key, and draws the randomness of the
+// signature from the given reader.
+func SignWithRandom(sk PrivateKey, data []byte, alg hash.Hasher, random io.Reader) (Signature, error) {
+	ecdsaKey, ok := sk.(*PrKeyECDSA)
+	if !ok {
+		return nil, fmt.Errorf("the signature scheme %s does not support a random source", sk.Algorithm())
+	}
+	return ecdsaKey.SignWithRandom(data, alg, random)
+}
+
 // DecodePrivateKey decodes an array of bytes into a private key of the given algorithm
 func DecodePrivateKey(algo SigningAlgorithm, data []byte) (PrivateKey, error) {
 	signer, err := newSigner(algo)
@@ -18,6 +18,8 @@
 
 package crypto
 
+import "fmt"
+
 // SigningAlgorithm is an identifier for a signing algorithm
 // (and parameters if applicable)
 type SigningAlgorithm int
@@ -36,7 +38,11 @@ const (
 
 // String returns the string representation of this signing algorithm.
 func (f SigningAlgorithm) String() string {
-	return [...]string{"UNKNOWN", "BLS_BLS12381", "ECDSA_P256", "ECDSA_secp256k1"}[f]
+	names := [...]string{"UNKNOWN", "BLS_BLS12381", "ECDSA_P256", "ECDSA_secp256k1"}
+	if f < 0 || int(f) >= len(names) {
+		return fmt.Sprintf("SigningAlgorithm(%d)", int(f))
+	}
+	return names[f]
 }
 
 const (
@@ -0,0 +1,239 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrSignerQueueFull is returned by a limited signer when too many signatures are
+// already waiting for their turn.
+var ErrSignerQueueFull = errcode.New(errcode.SignerQueueFull, "crypto: signer queue is full")
+
+// budgetWindow is the window of the signature budget of a SignLimiter.
+const budgetWindow = time.Minute
+
+// SignLimits configures a SignLimiter. Zero values do not limit signatures.
+type SignLimits struct {
+	// MaxConcurrent is the maximum number of signatures in progress at once.
+	MaxConcurrent int
+	// MaxPerMinute is the maximum number of signatures started in any minute, e.g.
+	// the signing quota of a KMS provider.
+	MaxPerMinute int
+	// MaxQueue is the maximum number of signatures waiting for their turn. Further
+	// signatures fail with ErrSignerQueueFull.
+	MaxQueue int
+	// MaxWait is the maximum time a signature waits for its turn. Signatures that
+	// wait longer fail with an error wrapping ErrSignerTimeout.
+	MaxWait time.Duration
+	// OnWait, if set, is called with the time each signature waited for its turn,
+	// e.g. to record it in a histogram. It must not block.
+	OnWait func(wait time.Duration)
+}
+
+// SignLimiterStats are the counters of a SignLimiter.
+type SignLimiterStats struct {
+	// InFlight is the number of signatures in progress.
+	InFlight int
+	// Waiting is the number of signatures waiting for their turn.
+	Waiting int
+	// Started is the number of signatures that got their turn.
+	Started uint64
+	// Rejected is the number of signatures that failed because the queue was full
+	// or they waited longer than MaxWait.
+	Rejected uint64
+	// TotalWait is the total time signatures waited for their turn.
+	TotalWait time.Duration
+}
+
+// A SignLimiter bounds the concurrency and rate of the signatures of one or more
+// signers, e.g. all the KMS signers of a project, so that bursts of signatures queue
+// up instead of exceeding the quotas of the provider.
+//
+// Signatures wait in order for a free slot and for room in the budget of the last
+// minute. A signature that gives up after MaxWait still counts against the budget
+// if it was already scheduled.
+type SignLimiter struct {
+	limits SignLimits
+	slots  chan struct{}
+
+	mu sync.Mutex
+	// starts holds the start times of the last MaxPerMinute signatures, as a ring.
+	starts []time.Time
+	next   int
+	stats  SignLimiterStats
+}
+
+// NewSignLimiter returns a limiter with the given limits.
+func NewSignLimiter(limits SignLimits) *SignLimiter {
+	l := &SignLimiter{limits: limits}
+
+	if limits.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+
+	if limits.MaxPerMinute > 0 {
+		l.starts = make([]time.Time, limits.MaxPerMinute)
+	}
+
+	return l
+}
+
+// Wrap returns a signer that signs with signer within the limits of l.
+func (l *SignLimiter) Wrap(signer Signer) LimitedSigner {
+	return LimitedSigner{
+		Signer:  signer,
+		Limiter: l,
+	}
+}
+
+// Stats returns the counters of the limiter.
+func (l *SignLimiter) Stats() SignLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// Acquire waits for the turn of a signature, and returns a function that must be
+// called once the signature is done.
+func (l *SignLimiter) Acquire() (release func(), err error) {
+	start := time.Now()
+
+	l.mu.Lock()
+	if l.limits.MaxQueue > 0 && l.stats.Waiting >= l.limits.MaxQueue {
+		l.stats.Rejected++
+		l.mu.Unlock()
+		return nil, ErrSignerQueueFull
+	}
+	l.stats.Waiting++
+	l.mu.Unlock()
+
+	var deadline <-chan time.Time
+	if l.limits.MaxWait > 0 {
+		timer := time.NewTimer(l.limits.MaxWait)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	reject := func() error {
+		l.mu.Lock()
+		l.stats.Waiting--
+		l.stats.Rejected++
+		l.mu.Unlock()
+		return fmt.Errorf("%w: waited %s for a signing slot", ErrSignerTimeout, time.Since(start))
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-deadline:
+			return nil, reject()
+		}
+	}
+
+	if delay := l.reserve(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-deadline:
+			l.releaseSlot()
+			return nil, reject()
+		}
+	}
+
+	wait := time.Since(start)
+
+	l.mu.Lock()
+	l.stats.Waiting--
+	l.stats.InFlight++
+	l.stats.Started++
+	l.stats.TotalWait += wait
+	l.mu.Unlock()
+
+	if l.limits.OnWait != nil {
+		l.limits.OnWait(wait)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.stats.InFlight--
+			l.mu.Unlock()
+
+			l.releaseSlot()
+		})
+	}, nil
+}
+
+func (l *SignLimiter) releaseSlot() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// reserve schedules the start of a signature within the budget, and returns the
+// time until it may start.
+func (l *SignLimiter) reserve() time.Duration {
+	if l.starts == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	// the oldest of the last MaxPerMinute starts must be a minute old
+	at := now
+	if oldest := l.starts[l.next]; !oldest.IsZero() && oldest.Add(budgetWindow).After(at) {
+		at = oldest.Add(budgetWindow)
+	}
+
+	l.starts[l.next] = at
+	l.next = (l.next + 1) % len(l.starts)
+
+	return at.Sub(now)
+}
+
+// A LimitedSigner is a signer whose signatures wait for their turn in a SignLimiter.
+type LimitedSigner struct {
+	Signer  Signer
+	Limiter *SignLimiter
+}
+
+var _ Signer = LimitedSigner{}
+
+// Sign signs the message with the wrapped signer once the limiter allows it.
+func (s LimitedSigner) Sign(message []byte) ([]byte, error) {
+	release, err := s.Limiter.Acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.Signer.Sign(message)
+}
@@ -0,0 +1,153 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// concurrencySigner records the maximum number of concurrent signatures.
+type concurrencySigner struct {
+	current int32
+	max     int32
+}
+
+func (s *concurrencySigner) Sign([]byte) ([]byte, error) {
+	current := atomic.AddInt32(&s.current, 1)
+	defer atomic.AddInt32(&s.current, -1)
+
+	for {
+		max := atomic.LoadInt32(&s.max)
+		if current <= max || atomic.CompareAndSwapInt32(&s.max, max, current) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	return []byte{1}, nil
+}
+
+func TestLimitedSigner(t *testing.T) {
+	t.Run("Limits concurrent signatures", func(t *testing.T) {
+		var waits int32
+
+		signer := &concurrencySigner{}
+		limiter := crypto.NewSignLimiter(crypto.SignLimits{
+			MaxConcurrent: 2,
+			OnWait: func(time.Duration) {
+				atomic.AddInt32(&waits, 1)
+			},
+		})
+		limited := limiter.Wrap(signer)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := limited.Sign(nil)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(2), signer.max)
+		assert.Equal(t, int32(8), waits)
+
+		stats := limiter.Stats()
+		assert.Equal(t, uint64(8), stats.Started)
+		assert.Equal(t, 0, stats.InFlight)
+		assert.Equal(t, 0, stats.Waiting)
+		assert.Greater(t, int64(stats.TotalWait), int64(0))
+	})
+
+	t.Run("Rejects signatures when the queue is full", func(t *testing.T) {
+		slow := &stubSigner{sig: []byte{1}, delay: 100 * time.Millisecond}
+		limiter := crypto.NewSignLimiter(crypto.SignLimits{
+			MaxConcurrent: 1,
+			MaxQueue:      1,
+		})
+		limited := limiter.Wrap(slow)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := limited.Sign(nil)
+				assert.NoError(t, err)
+			}()
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		_, err := limited.Sign(nil)
+		assert.Equal(t, crypto.ErrSignerQueueFull, err)
+
+		wg.Wait()
+		assert.Equal(t, uint64(1), limiter.Stats().Rejected)
+	})
+
+	t.Run("Gives up after the maximum wait", func(t *testing.T) {
+		slow := &stubSigner{sig: []byte{1}, delay: 200 * time.Millisecond}
+		limited := crypto.NewSignLimiter(crypto.SignLimits{
+			MaxConcurrent: 1,
+			MaxWait:       20 * time.Millisecond,
+		}).Wrap(slow)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = limited.Sign(nil)
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		_, err := limited.Sign(nil)
+		assert.True(t, errors.Is(err, crypto.ErrSignerTimeout))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&slow.calls))
+
+		<-done
+	})
+
+	t.Run("Enforces the per-minute budget", func(t *testing.T) {
+		fast := &stubSigner{sig: []byte{1}}
+		limited := crypto.NewSignLimiter(crypto.SignLimits{
+			MaxPerMinute: 2,
+			MaxWait:      20 * time.Millisecond,
+		}).Wrap(fast)
+
+		for i := 0; i < 2; i++ {
+			sig, err := limited.Sign(nil)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{1}, sig)
+		}
+
+		_, err := limited.Sign(nil)
+		assert.True(t, errors.Is(err, crypto.ErrSignerTimeout))
+		assert.Equal(t, int32(2), fast.calls)
+	})
+}
@@ -0,0 +1,178 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SignatureAlgorithmFromString returns the signature algorithm with the given name,
+// e.g. "ECDSA_secp256k1", ignoring case. Unlike StringToSignatureAlgorithm, it
+// returns an error for unknown names, and for UNKNOWN.
+//
+// Algorithms added with RegisterSignatureAlgorithm are found by their name.
+func SignatureAlgorithmFromString(s string) (SignatureAlgorithm, error) {
+	if algo := StringToSignatureAlgorithm(s); algo != UnknownSignatureAlgorithm {
+		return algo, nil
+	}
+
+	for algo := BLS_BLS12381; algo <= ECDSA_secp256k1; algo++ {
+		if strings.EqualFold(algo.String(), s) {
+			return algo, nil
+		}
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for algo, registered := range registry.sigAlgos {
+		if strings.EqualFold(registered.name, s) {
+			return algo, nil
+		}
+	}
+
+	return UnknownSignatureAlgorithm, fmt.Errorf("crypto: unknown signature algorithm %q", s)
+}
+
+// HashAlgorithmFromString returns the hash algorithm with the given name, e.g.
+// "SHA3_256", ignoring case. Unlike StringToHashAlgorithm, it returns an error for
+// unknown names, and for UNKNOWN.
+//
+// Algorithms added with RegisterHashAlgorithm are found by their name.
+func HashAlgorithmFromString(s string) (HashAlgorithm, error) {
+	if algo := StringToHashAlgorithm(s); algo != UnknownHashAlgorithm {
+		return algo, nil
+	}
+
+	for algo := SHA2_256; algo <= SHA3_384; algo++ {
+		if strings.EqualFold(algo.String(), s) {
+			return algo, nil
+		}
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for algo, registered := range registry.hashAlgos {
+		if strings.EqualFold(registered.name, s) {
+			return algo, nil
+		}
+	}
+
+	return UnknownHashAlgorithm, fmt.Errorf("crypto: unknown hash algorithm %q", s)
+}
+
+// MarshalJSON encodes the signature algorithm as its name.
+func (f SignatureAlgorithm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON decodes a signature algorithm from its name, as parsed by
+// SignatureAlgorithmFromString, or from its numeric identifier. UNKNOWN and 0 decode to
+// UnknownSignatureAlgorithm, so that unset algorithms round-trip.
+func (f *SignatureAlgorithm) UnmarshalJSON(data []byte) error {
+	name, id, err := unmarshalAlgorithm(data)
+	if err != nil {
+		return fmt.Errorf("crypto: invalid signature algorithm %s", data)
+	}
+
+	if name == UnknownSignatureAlgorithm.String() || (name == "" && id == 0) {
+		*f = UnknownSignatureAlgorithm
+		return nil
+	}
+
+	if name != "" {
+		algo, err := SignatureAlgorithmFromString(name)
+		if err != nil {
+			return err
+		}
+		*f = algo
+		return nil
+	}
+
+	algo := SignatureAlgorithm(id)
+	if _, registered := lookupSignatureAlgorithm(algo); algo < UnknownSignatureAlgorithm ||
+		(algo > ECDSA_secp256k1 && !registered) {
+		return fmt.Errorf("crypto: unknown signature algorithm %d", id)
+	}
+
+	*f = algo
+	return nil
+}
+
+// MarshalJSON encodes the hash algorithm as its name.
+func (f HashAlgorithm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON decodes a hash algorithm from its name, as parsed by
+// HashAlgorithmFromString, or from its numeric identifier. UNKNOWN and 0 decode to
+// UnknownHashAlgorithm, so that unset algorithms round-trip.
+func (f *HashAlgorithm) UnmarshalJSON(data []byte) error {
+	name, id, err := unmarshalAlgorithm(data)
+	if err != nil {
+		return fmt.Errorf("crypto: invalid hash algorithm %s", data)
+	}
+
+	if name == UnknownHashAlgorithm.String() || (name == "" && id == 0) {
+		*f = UnknownHashAlgorithm
+		return nil
+	}
+
+	if name != "" {
+		algo, err := HashAlgorithmFromString(name)
+		if err != nil {
+			return err
+		}
+		*f = algo
+		return nil
+	}
+
+	algo := HashAlgorithm(id)
+	if _, registered := lookupHashAlgorithm(algo); algo < UnknownHashAlgorithm ||
+		(algo > SHA3_384 && !registered) {
+		return fmt.Errorf("crypto: unknown hash algorithm %d", id)
+	}
+
+	*f = algo
+	return nil
+}
+
+// unmarshalAlgorithm decodes the JSON encoding of an algorithm, either a non-empty
+// string or an integer.
+func unmarshalAlgorithm(data []byte) (name string, id int, err error) {
+	data = bytes.TrimSpace(data)
+
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &name); err != nil {
+			return "", 0, err
+		}
+		if name == "" {
+			return "", 0, fmt.Errorf("empty algorithm name")
+		}
+		return name, 0, nil
+	}
+
+	id, err = strconv.Atoi(string(data))
+	return "", id, err
+}
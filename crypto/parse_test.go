@@ -0,0 +1,114 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+func TestSignatureAlgorithmFromString(t *testing.T) {
+	for _, algo := range []crypto.SignatureAlgorithm{
+		crypto.BLS_BLS12381,
+		crypto.ECDSA_P256,
+		crypto.ECDSA_secp256k1,
+	} {
+		parsed, err := crypto.SignatureAlgorithmFromString(algo.String())
+		require.NoError(t, err)
+		assert.Equal(t, algo, parsed)
+	}
+
+	parsed, err := crypto.SignatureAlgorithmFromString("ecdsa_p256")
+	require.NoError(t, err)
+	assert.Equal(t, crypto.ECDSA_P256, parsed)
+
+	_, err = crypto.SignatureAlgorithmFromString("UNKNOWN")
+	assert.Error(t, err)
+
+	_, err = crypto.SignatureAlgorithmFromString("RSA")
+	assert.Error(t, err)
+}
+
+func TestHashAlgorithmFromString(t *testing.T) {
+	for _, algo := range []crypto.HashAlgorithm{
+		crypto.SHA2_256,
+		crypto.SHA2_384,
+		crypto.SHA3_256,
+		crypto.SHA3_384,
+	} {
+		parsed, err := crypto.HashAlgorithmFromString(algo.String())
+		require.NoError(t, err)
+		assert.Equal(t, algo, parsed)
+	}
+
+	parsed, err := crypto.HashAlgorithmFromString("sha3_256")
+	require.NoError(t, err)
+	assert.Equal(t, crypto.SHA3_256, parsed)
+
+	_, err = crypto.HashAlgorithmFromString("MD5")
+	assert.Error(t, err)
+}
+
+func TestAlgorithmJSON(t *testing.T) {
+	type config struct {
+		SigAlgo  crypto.SignatureAlgorithm `json:"sigAlgo"`
+		HashAlgo crypto.HashAlgorithm      `json:"hashAlgo"`
+	}
+
+	t.Run("Round-trips names", func(t *testing.T) {
+		data, err := json.Marshal(config{SigAlgo: crypto.ECDSA_secp256k1, HashAlgo: crypto.SHA3_256})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"sigAlgo": "ECDSA_secp256k1", "hashAlgo": "SHA3_256"}`, string(data))
+
+		var decoded config
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, crypto.ECDSA_secp256k1, decoded.SigAlgo)
+		assert.Equal(t, crypto.SHA3_256, decoded.HashAlgo)
+	})
+
+	t.Run("Round-trips unset algorithms", func(t *testing.T) {
+		data, err := json.Marshal(config{})
+		require.NoError(t, err)
+
+		var decoded config
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, config{}, decoded)
+	})
+
+	t.Run("Decodes numeric identifiers", func(t *testing.T) {
+		var decoded config
+		require.NoError(t, json.Unmarshal([]byte(`{"sigAlgo": 2, "hashAlgo": 3}`), &decoded))
+		assert.Equal(t, crypto.ECDSA_P256, decoded.SigAlgo)
+		assert.Equal(t, crypto.SHA3_256, decoded.HashAlgo)
+	})
+
+	t.Run("Rejects unknown algorithms", func(t *testing.T) {
+		var decoded config
+		assert.Error(t, json.Unmarshal([]byte(`{"sigAlgo": "RSA"}`), &decoded))
+		assert.Error(t, json.Unmarshal([]byte(`{"sigAlgo": 42}`), &decoded))
+		assert.Error(t, json.Unmarshal([]byte(`{"hashAlgo": "MD5"}`), &decoded))
+		assert.Error(t, json.Unmarshal([]byte(`{"hashAlgo": -1}`), &decoded))
+		assert.Error(t, json.Unmarshal([]byte(`{"hashAlgo": true}`), &decoded))
+	})
+}
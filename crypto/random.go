@@ -0,0 +1,104 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto"
+)
+
+// GenerateRandomPrivateKey generates a private key with the specified signature
+// algorithm from a seed of MinSeedLength bytes drawn from crypto/rand.
+func GenerateRandomPrivateKey(sigAlgo SignatureAlgorithm) (PrivateKey, error) {
+	return GeneratePrivateKeyWithRandom(sigAlgo, rand.Reader)
+}
+
+// GeneratePrivateKeyWithRandom generates a private key with the specified signature
+// algorithm from a seed of MinSeedLength bytes drawn from the given reader.
+//
+// With a deterministic reader, such as NewDeterministicRandomSource, keys are
+// reproducible, which allows golden tests. Such a reader must never be used
+// outside of tests.
+func GeneratePrivateKeyWithRandom(sigAlgo SignatureAlgorithm, random io.Reader) (PrivateKey, error) {
+	seed := make([]byte, MinSeedLength)
+
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return PrivateKey{}, fmt.Errorf("crypto: failed to read seed: %w", err)
+	}
+
+	return GeneratePrivateKey(sigAlgo, seed)
+}
+
+// SignWithRandom signs the given message with this private key and the provided
+// hasher, and draws the randomness of the signature from the given reader.
+func (sk PrivateKey) SignWithRandom(message []byte, hasher Hasher, random io.Reader) ([]byte, error) {
+	if schemeKey, ok := sk.privateKey.(schemePrivateKey); ok {
+		return schemeKey.signWithRandom(message, hasher, random)
+	}
+
+	return crypto.SignWithRandom(sk.privateKey, message, hasher, random)
+}
+
+// deterministicRandomSource is a reproducible stream of bytes derived from a seed.
+type deterministicRandomSource struct {
+	mu      sync.Mutex
+	seed    []byte
+	counter uint64
+	block   []byte
+}
+
+// NewDeterministicRandomSource returns a reader that produces the same stream of
+// bytes for the same seed, for use with InMemorySigner.Random,
+// GeneratePrivateKeyWithRandom or SplitKeyWithRandom in tests. It is safe for concurrent use, but the bytes each reader receives then
+// depend on the order of reads.
+//
+// The stream is the concatenation of SHA-256(seed || counter) for counter = 0, 1, ...
+// It is not suitable for production use.
+func NewDeterministicRandomSource(seed []byte) io.Reader {
+	return &deterministicRandomSource{seed: append([]byte(nil), seed...)}
+}
+
+func (d *deterministicRandomSource) Read(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if len(d.block) == 0 {
+			var counter [8]byte
+			binary.BigEndian.PutUint64(counter[:], d.counter)
+			d.counter++
+
+			block := sha256.Sum256(append(d.seed, counter[:]...))
+			d.block = block[:]
+		}
+
+		copied := copy(p[n:], d.block)
+		d.block = d.block[copied:]
+		n += copied
+	}
+
+	return n, nil
+}
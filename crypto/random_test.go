@@ -0,0 +1,114 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+func TestNewDeterministicRandomSource(t *testing.T) {
+	read := func(r io.Reader, sizes ...int) []byte {
+		var out []byte
+		for _, size := range sizes {
+			b := make([]byte, size)
+			_, err := io.ReadFull(r, b)
+			require.NoError(t, err)
+			out = append(out, b...)
+		}
+		return out
+	}
+
+	a := read(crypto.NewDeterministicRandomSource([]byte("seed")), 100)
+	b := read(crypto.NewDeterministicRandomSource([]byte("seed")), 7, 50, 43)
+	c := read(crypto.NewDeterministicRandomSource([]byte("other")), 100)
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestInMemorySigner_Random(t *testing.T) {
+	message := []byte("golden")
+
+	for _, sigAlgo := range []crypto.SignatureAlgorithm{crypto.ECDSA_P256, crypto.ECDSA_secp256k1} {
+		sigAlgo := sigAlgo
+
+		t.Run(sigAlgo.String(), func(t *testing.T) {
+			sk, err := crypto.GeneratePrivateKey(sigAlgo, makeSeed(crypto.MinSeedLength))
+			require.NoError(t, err)
+
+			hasher, err := crypto.NewHasher(crypto.SHA3_256)
+			require.NoError(t, err)
+
+			sign := func(random io.Reader) []byte {
+				signer := crypto.NewInMemorySigner(sk, crypto.SHA3_256)
+				signer.Random = random
+
+				sig, err := signer.Sign(message)
+				require.NoError(t, err)
+
+				valid, err := sk.PublicKey().Verify(sig, message, hasher)
+				require.NoError(t, err)
+				assert.True(t, valid)
+
+				return sig
+			}
+
+			t.Run("Per signer", func(t *testing.T) {
+				a := sign(crypto.NewDeterministicRandomSource([]byte("golden")))
+				b := sign(crypto.NewDeterministicRandomSource([]byte("golden")))
+				c := sign(crypto.NewDeterministicRandomSource([]byte("other")))
+
+				assert.Equal(t, a, b)
+				assert.NotEqual(t, a, c)
+			})
+
+			t.Run("Exhausted source", func(t *testing.T) {
+				signer := crypto.NewInMemorySigner(sk, crypto.SHA3_256)
+				signer.Random = bytes.NewReader(nil)
+
+				_, err := signer.Sign(message)
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestGeneratePrivateKeyWithRandom(t *testing.T) {
+	a, err := crypto.GeneratePrivateKeyWithRandom(crypto.ECDSA_P256, crypto.NewDeterministicRandomSource([]byte("golden")))
+	require.NoError(t, err)
+
+	b, err := crypto.GeneratePrivateKeyWithRandom(crypto.ECDSA_P256, crypto.NewDeterministicRandomSource([]byte("golden")))
+	require.NoError(t, err)
+
+	c, err := crypto.GenerateRandomPrivateKey(crypto.ECDSA_P256)
+	require.NoError(t, err)
+
+	assert.Equal(t, a.Encode(), b.Encode())
+	assert.NotEqual(t, a.Encode(), c.Encode())
+
+	_, err = crypto.GeneratePrivateKeyWithRandom(crypto.ECDSA_P256, bytes.NewReader(nil))
+	assert.Error(t, err)
+}
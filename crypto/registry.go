@@ -0,0 +1,331 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	stdhash "hash"
+	"io"
+	"sync"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto"
+	"github.com/portto/blocto-flow-go-sdk/crypto/internal/crypto/hash"
+)
+
+// A SignatureScheme implements a signature algorithm registered with
+// RegisterSignatureAlgorithm, e.g. a curve variant of a KMS provider.
+type SignatureScheme interface {
+	// GeneratePrivateKey generates a private key from a seed of at least
+	// MinSeedLength bytes.
+	GeneratePrivateKey(seed []byte) (SchemePrivateKey, error)
+	// DecodePrivateKey decodes a raw byte encoded private key.
+	DecodePrivateKey(b []byte) (SchemePrivateKey, error)
+	// DecodePublicKey decodes a raw byte encoded public key.
+	DecodePublicKey(b []byte) (SchemePublicKey, error)
+	// CompatibleHash returns true if signatures of the scheme can use the hash algorithm.
+	CompatibleHash(hashAlgo HashAlgorithm) bool
+}
+
+// A SchemePrivateKey is a private key of a registered signature scheme.
+//
+// A key that also implements SignWithRandom(message []byte, hasher Hasher, random io.Reader)
+// draws the randomness of its signatures from the random source of the SDK, like
+// built-in keys. Other keys use their own randomness.
+type SchemePrivateKey interface {
+	// Sign signs the message with the provided hasher.
+	Sign(message []byte, hasher Hasher) ([]byte, error)
+	// PublicKey returns the public key for this private key.
+	PublicKey() SchemePublicKey
+	// Encode returns the raw byte encoding of this private key.
+	Encode() []byte
+}
+
+// A SchemePublicKey is a public key of a registered signature scheme.
+type SchemePublicKey interface {
+	// Verify verifies the signature of a message with the provided hasher.
+	Verify(sig, message []byte, hasher Hasher) (bool, error)
+	// Encode returns the raw byte encoding of this public key.
+	Encode() []byte
+}
+
+// registeredSignatureAlgorithm is a signature algorithm added with RegisterSignatureAlgorithm.
+type registeredSignatureAlgorithm struct {
+	name   string
+	scheme SignatureScheme
+}
+
+// registeredHashAlgorithm is a hash algorithm added with RegisterHashAlgorithm.
+type registeredHashAlgorithm struct {
+	name    string
+	newHash func() stdhash.Hash
+}
+
+// registry holds the algorithms registered by applications.
+var registry = struct {
+	sync.RWMutex
+	sigAlgos  map[SignatureAlgorithm]registeredSignatureAlgorithm
+	hashAlgos map[HashAlgorithm]registeredHashAlgorithm
+}{
+	sigAlgos:  map[SignatureAlgorithm]registeredSignatureAlgorithm{},
+	hashAlgos: map[HashAlgorithm]registeredHashAlgorithm{},
+}
+
+// RegisterSignatureAlgorithm adds a signature algorithm implemented by scheme, so
+// that keys of the algorithm are generated, decoded and used like built-in keys,
+// e.g. by GeneratePrivateKey, DecodePublicKey and InMemorySigner.
+//
+// The identifier is the value of the algorithm in account keys, and name is its
+// string representation. An error is returned if either is already taken, by a
+// built-in or registered algorithm. Algorithms are usually registered in an init
+// function:
+//
+//	const ECDSA_P256_KMS crypto.SignatureAlgorithm = 100
+//
+//	func init() {
+//		err := crypto.RegisterSignatureAlgorithm(ECDSA_P256_KMS, "ECDSA_P256_KMS", kmsScheme{})
+//		if err != nil {
+//			panic(err)
+//		}
+//	}
+func RegisterSignatureAlgorithm(algo SignatureAlgorithm, name string, scheme SignatureScheme) error {
+	if algo <= ECDSA_secp256k1 {
+		return fmt.Errorf("crypto: signature algorithm %d is reserved", int(algo))
+	}
+	if name == "" || scheme == nil {
+		return fmt.Errorf("crypto: signature algorithm %d needs a name and a scheme", int(algo))
+	}
+	registry.Lock()
+	defer registry.Unlock()
+
+	taken := false
+	for builtin := UnknownSignatureAlgorithm; builtin <= ECDSA_secp256k1; builtin++ {
+		taken = taken || builtin.String() == name
+	}
+	for _, registered := range registry.sigAlgos {
+		taken = taken || registered.name == name
+	}
+	if taken {
+		return fmt.Errorf("crypto: signature algorithm %s is already registered", name)
+	}
+
+	if existing, ok := registry.sigAlgos[algo]; ok {
+		return fmt.Errorf("crypto: signature algorithm %d is already registered as %s", int(algo), existing.name)
+	}
+
+	registry.sigAlgos[algo] = registeredSignatureAlgorithm{name: name, scheme: scheme}
+	return nil
+}
+
+// RegisterHashAlgorithm adds a hash algorithm implemented by the hashes returned by
+// newHash, so that NewHasher returns hashers of the algorithm.
+//
+// The identifier is the value of the algorithm in account keys, and name is its
+// string representation. An error is returned if either is already taken, by a
+// built-in or registered algorithm.
+func RegisterHashAlgorithm(algo HashAlgorithm, name string, newHash func() stdhash.Hash) error {
+	if algo <= SHA3_384 {
+		return fmt.Errorf("crypto: hash algorithm %d is reserved", int(algo))
+	}
+	if name == "" || newHash == nil {
+		return fmt.Errorf("crypto: hash algorithm %d needs a name and a constructor", int(algo))
+	}
+	registry.Lock()
+	defer registry.Unlock()
+
+	taken := false
+	for builtin := UnknownHashAlgorithm; builtin <= SHA3_384; builtin++ {
+		taken = taken || builtin.String() == name
+	}
+	for _, registered := range registry.hashAlgos {
+		taken = taken || registered.name == name
+	}
+	if taken {
+		return fmt.Errorf("crypto: hash algorithm %s is already registered", name)
+	}
+
+	if existing, ok := registry.hashAlgos[algo]; ok {
+		return fmt.Errorf("crypto: hash algorithm %d is already registered as %s", int(algo), existing.name)
+	}
+
+	registry.hashAlgos[algo] = registeredHashAlgorithm{name: name, newHash: newHash}
+	return nil
+}
+
+// lookupSignatureAlgorithm returns the registered signature algorithm with the given identifier.
+func lookupSignatureAlgorithm(algo SignatureAlgorithm) (registeredSignatureAlgorithm, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	registered, ok := registry.sigAlgos[algo]
+	return registered, ok
+}
+
+// lookupHashAlgorithm returns the registered hash algorithm with the given identifier.
+func lookupHashAlgorithm(algo HashAlgorithm) (registeredHashAlgorithm, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	registered, ok := registry.hashAlgos[algo]
+	return registered, ok
+}
+
+// signatureAlgorithmByName returns the registered signature algorithm with the given name.
+func signatureAlgorithmByName(name string) SignatureAlgorithm {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for algo, registered := range registry.sigAlgos {
+		if registered.name == name {
+			return algo
+		}
+	}
+	return UnknownSignatureAlgorithm
+}
+
+// hashAlgorithmByName returns the registered hash algorithm with the given name.
+func hashAlgorithmByName(name string) HashAlgorithm {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for algo, registered := range registry.hashAlgos {
+		if registered.name == name {
+			return algo
+		}
+	}
+	return UnknownHashAlgorithm
+}
+
+// schemePrivateKey adapts a private key of a registered scheme to the keys of the
+// internal crypto package.
+type schemePrivateKey struct {
+	algo SignatureAlgorithm
+	key  SchemePrivateKey
+}
+
+var _ crypto.PrivateKey = schemePrivateKey{}
+
+func (sk schemePrivateKey) Algorithm() crypto.SigningAlgorithm {
+	return crypto.SigningAlgorithm(sk.algo)
+}
+
+func (sk schemePrivateKey) Size() int {
+	return len(sk.key.Encode())
+}
+
+func (sk schemePrivateKey) String() string {
+	return "0x" + hex.EncodeToString(sk.key.Encode())
+}
+
+func (sk schemePrivateKey) Sign(message []byte, hasher hash.Hasher) (crypto.Signature, error) {
+	return sk.key.Sign(message, hasher)
+}
+
+func (sk schemePrivateKey) signWithRandom(message []byte, hasher Hasher, random io.Reader) ([]byte, error) {
+	if randomKey, ok := sk.key.(interface {
+		SignWithRandom(message []byte, hasher Hasher, random io.Reader) ([]byte, error)
+	}); ok {
+		return randomKey.SignWithRandom(message, hasher, random)
+	}
+	return sk.key.Sign(message, hasher)
+}
+
+func (sk schemePrivateKey) PublicKey() crypto.PublicKey {
+	return schemePublicKey{algo: sk.algo, key: sk.key.PublicKey()}
+}
+
+func (sk schemePrivateKey) Encode() []byte {
+	return sk.key.Encode()
+}
+
+func (sk schemePrivateKey) Equals(other crypto.PrivateKey) bool {
+	return other.Algorithm() == sk.Algorithm() && bytes.Equal(other.Encode(), sk.Encode())
+}
+
+// schemePublicKey adapts a public key of a registered scheme to the keys of the
+// internal crypto package.
+type schemePublicKey struct {
+	algo SignatureAlgorithm
+	key  SchemePublicKey
+}
+
+var _ crypto.PublicKey = schemePublicKey{}
+
+func (pk schemePublicKey) Algorithm() crypto.SigningAlgorithm {
+	return crypto.SigningAlgorithm(pk.algo)
+}
+
+func (pk schemePublicKey) Size() int {
+	return len(pk.key.Encode())
+}
+
+func (pk schemePublicKey) String() string {
+	return "0x" + hex.EncodeToString(pk.key.Encode())
+}
+
+func (pk schemePublicKey) Verify(sig crypto.Signature, message []byte, hasher hash.Hasher) (bool, error) {
+	return pk.key.Verify(sig, message, hasher)
+}
+
+func (pk schemePublicKey) Encode() []byte {
+	return pk.key.Encode()
+}
+
+func (pk schemePublicKey) Equals(other crypto.PublicKey) bool {
+	return other.Algorithm() == pk.Algorithm() && bytes.Equal(other.Encode(), pk.Encode())
+}
+
+// stdHasher adapts a hash of the standard library to a Hasher.
+type stdHasher struct {
+	algo HashAlgorithm
+	hash stdhash.Hash
+}
+
+var _ Hasher = &stdHasher{}
+
+func (h *stdHasher) Algorithm() hash.HashingAlgorithm {
+	return hash.HashingAlgorithm(h.algo)
+}
+
+func (h *stdHasher) Size() int {
+	return h.hash.Size()
+}
+
+func (h *stdHasher) ComputeHash(data []byte) Hash {
+	h.hash.Reset()
+	_, _ = h.hash.Write(data)
+	sum := h.hash.Sum(nil)
+	h.hash.Reset()
+	return sum
+}
+
+func (h *stdHasher) Write(p []byte) (int, error) {
+	return h.hash.Write(p)
+}
+
+func (h *stdHasher) SumHash() Hash {
+	sum := h.hash.Sum(nil)
+	h.hash.Reset()
+	return sum
+}
+
+func (h *stdHasher) Reset() {
+	h.hash.Reset()
+}
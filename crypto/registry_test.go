@@ -0,0 +1,157 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+const (
+	testEd25519 crypto.SignatureAlgorithm = 100
+	testSHA2512 crypto.HashAlgorithm      = 100
+)
+
+// ed25519Scheme is a signature scheme registered by the tests.
+type ed25519Scheme struct{}
+
+type ed25519PrivateKey ed25519.PrivateKey
+
+type ed25519PublicKey ed25519.PublicKey
+
+func (ed25519Scheme) GeneratePrivateKey(seed []byte) (crypto.SchemePrivateKey, error) {
+	return ed25519PrivateKey(ed25519.NewKeyFromSeed(seed[:ed25519.SeedSize])), nil
+}
+
+func (ed25519Scheme) DecodePrivateKey(b []byte) (crypto.SchemePrivateKey, error) {
+	if len(b) != ed25519.SeedSize {
+		return nil, errors.New("invalid private key")
+	}
+	return ed25519PrivateKey(ed25519.NewKeyFromSeed(b)), nil
+}
+
+func (ed25519Scheme) DecodePublicKey(b []byte) (crypto.SchemePublicKey, error) {
+	if len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	return ed25519PublicKey(b), nil
+}
+
+func (ed25519Scheme) CompatibleHash(hashAlgo crypto.HashAlgorithm) bool {
+	return hashAlgo == testSHA2512
+}
+
+func (sk ed25519PrivateKey) Sign(message []byte, hasher crypto.Hasher) ([]byte, error) {
+	return ed25519.Sign(ed25519.PrivateKey(sk), hasher.ComputeHash(message)), nil
+}
+
+func (sk ed25519PrivateKey) PublicKey() crypto.SchemePublicKey {
+	return ed25519PublicKey(ed25519.PrivateKey(sk).Public().(ed25519.PublicKey))
+}
+
+func (sk ed25519PrivateKey) Encode() []byte {
+	return ed25519.PrivateKey(sk).Seed()
+}
+
+func (pk ed25519PublicKey) Verify(sig, message []byte, hasher crypto.Hasher) (bool, error) {
+	return ed25519.Verify(ed25519.PublicKey(pk), hasher.ComputeHash(message), sig), nil
+}
+
+func (pk ed25519PublicKey) Encode() []byte {
+	return pk
+}
+
+func init() {
+	if err := crypto.RegisterSignatureAlgorithm(testEd25519, "ED25519_TEST", ed25519Scheme{}); err != nil {
+		panic(err)
+	}
+	if err := crypto.RegisterHashAlgorithm(testSHA2512, "SHA2_512_TEST", sha512.New); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterSignatureAlgorithm(t *testing.T) {
+	t.Run("Names", func(t *testing.T) {
+		assert.Equal(t, "ED25519_TEST", testEd25519.String())
+		assert.Equal(t, testEd25519, crypto.StringToSignatureAlgorithm("ED25519_TEST"))
+		assert.Equal(t, "SignatureAlgorithm(101)", crypto.SignatureAlgorithm(101).String())
+
+		assert.True(t, crypto.CompatibleAlgorithms(testEd25519, testSHA2512))
+		assert.False(t, crypto.CompatibleAlgorithms(testEd25519, crypto.SHA3_256))
+	})
+
+	t.Run("Signs and verifies", func(t *testing.T) {
+		seed := make([]byte, crypto.MinSeedLength)
+		privateKey, err := crypto.GeneratePrivateKey(testEd25519, seed)
+		require.NoError(t, err)
+		assert.Equal(t, testEd25519, privateKey.Algorithm())
+
+		signer := crypto.NewInMemorySigner(privateKey, testSHA2512)
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+
+		publicKey, err := crypto.DecodePublicKey(testEd25519, privateKey.PublicKey().Encode())
+		require.NoError(t, err)
+		assert.Equal(t, testEd25519, publicKey.Algorithm())
+
+		hasher, err := crypto.NewHasher(testSHA2512)
+		require.NoError(t, err)
+
+		valid, err := publicKey.Verify(sig, []byte("message"), hasher)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		decoded, err := crypto.DecodePrivateKey(testEd25519, privateKey.Encode())
+		require.NoError(t, err)
+		assert.Equal(t, privateKey.PublicKey().Encode(), decoded.PublicKey().Encode())
+	})
+
+	t.Run("Rejects taken identifiers and names", func(t *testing.T) {
+		assert.Error(t, crypto.RegisterSignatureAlgorithm(crypto.ECDSA_P256, "OTHER", ed25519Scheme{}))
+		assert.Error(t, crypto.RegisterSignatureAlgorithm(testEd25519, "OTHER", ed25519Scheme{}))
+		assert.Error(t, crypto.RegisterSignatureAlgorithm(101, "ECDSA_P256", ed25519Scheme{}))
+		assert.Error(t, crypto.RegisterSignatureAlgorithm(101, "ED25519_TEST", ed25519Scheme{}))
+	})
+}
+
+func TestRegisterHashAlgorithm(t *testing.T) {
+	assert.Equal(t, "SHA2_512_TEST", testSHA2512.String())
+	assert.Equal(t, testSHA2512, crypto.StringToHashAlgorithm("SHA2_512_TEST"))
+
+	hasher, err := crypto.NewHasher(testSHA2512)
+	require.NoError(t, err)
+
+	expected := sha512.Sum512([]byte("message"))
+	assert.Equal(t, expected[:], []byte(hasher.ComputeHash([]byte("message"))))
+	assert.Equal(t, sha512.Size, hasher.Size())
+
+	_, _ = hasher.Write([]byte("mess"))
+	_, _ = hasher.Write([]byte("age"))
+	assert.Equal(t, expected[:], []byte(hasher.SumHash()))
+
+	assert.Error(t, crypto.RegisterHashAlgorithm(crypto.SHA3_256, "OTHER", sha512.New))
+	assert.Error(t, crypto.RegisterHashAlgorithm(101, "SHA3_256", sha512.New))
+}
@@ -0,0 +1,254 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrInvalidKeyShare is returned by CombineKey when a share is malformed or corrupted,
+// or when the shares were not split from the same key.
+var ErrInvalidKeyShare = errcode.New(errcode.InvalidKeyShare, "crypto: invalid key share")
+
+// ErrInsufficientShares is returned by CombineKey when fewer shares than the threshold
+// of the split are provided.
+var ErrInsufficientShares = errcode.New(errcode.InsufficientShares, "crypto: insufficient key shares")
+
+// MaxKeyShares is the maximum number of shares a key can be split into.
+const MaxKeyShares = 255
+
+const (
+	keyShareVersion = 1
+
+	// version | signature algorithm | threshold | x | split ID
+	keyShareHeaderLength   = 4 + keyShareSplitIDLength
+	keyShareSplitIDLength  = 8
+	keyShareChecksumLength = 4
+	keyDigestLength        = 4
+)
+
+// SplitKey splits a private key into n shares with Shamir's secret sharing scheme,
+// so that any k of the shares recover the key with CombineKey, and fewer reveal
+// nothing about it.
+//
+// Each share records the signature algorithm of the key, the threshold k and a
+// checksum, so that corrupted shares and shares of different splits are detected
+// when combined. The random coefficients are drawn from crypto/rand.
+func SplitKey(privKey PrivateKey, n, k int) ([][]byte, error) {
+	return SplitKeyWithRandom(privKey, n, k, rand.Reader)
+}
+
+// SplitKeyWithRandom splits a private key as SplitKey, and draws the random
+// coefficients from the given reader.
+func SplitKeyWithRandom(privKey PrivateKey, n, k int, random io.Reader) ([][]byte, error) {
+	if k < 2 || k > n || n > MaxKeyShares {
+		return nil, fmt.Errorf("crypto: invalid key split %d of %d, must be 2 <= k <= n <= %d", k, n, MaxKeyShares)
+	}
+
+	if privKey.privateKey == nil {
+		return nil, fmt.Errorf("crypto: private key is empty")
+	}
+
+	encoded := privKey.Encode()
+	digest := sha256.Sum256(encoded)
+	secret := append(encoded, digest[:keyDigestLength]...)
+
+	splitID := make([]byte, keyShareSplitIDLength)
+	if _, err := io.ReadFull(random, splitID); err != nil {
+		return nil, fmt.Errorf("crypto: failed to read randomness: %w", err)
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		share := make([]byte, keyShareHeaderLength, keyShareHeaderLength+len(secret)+keyShareChecksumLength)
+		share[0] = keyShareVersion
+		share[1] = byte(privKey.Algorithm())
+		share[2] = byte(k)
+		share[3] = byte(i + 1)
+		copy(share[4:], splitID)
+		shares[i] = share
+	}
+
+	// each byte of the secret is the constant term of its own polynomial of degree k-1
+	coefficients := make([]byte, k-1)
+	for _, b := range secret {
+		if _, err := io.ReadFull(random, coefficients); err != nil {
+			return nil, fmt.Errorf("crypto: failed to read randomness: %w", err)
+		}
+
+		for i, share := range shares {
+			shares[i] = append(share, gfEvaluate(b, coefficients, share[3]))
+		}
+	}
+
+	for i, share := range shares {
+		checksum := sha256.Sum256(share)
+		shares[i] = append(share, checksum[:keyShareChecksumLength]...)
+	}
+
+	return shares, nil
+}
+
+// CombineKey recovers a private key from shares returned by SplitKey.
+//
+// ErrInsufficientShares is returned if fewer shares than the threshold are provided,
+// and ErrInvalidKeyShare if a share is corrupted or the shares are from different
+// splits.
+func CombineKey(shares [][]byte) (PrivateKey, error) {
+	if len(shares) == 0 {
+		return PrivateKey{}, ErrInsufficientShares
+	}
+
+	for _, share := range shares {
+		if err := checkKeyShare(share); err != nil {
+			return PrivateKey{}, err
+		}
+	}
+
+	first := shares[0]
+	threshold := int(first[2])
+
+	// the same share may be provided more than once
+	unique := make(map[byte][]byte, len(shares))
+
+	for _, share := range shares {
+		sameSplit := len(share) == len(first) &&
+			bytes.Equal(share[:3], first[:3]) &&
+			bytes.Equal(share[4:keyShareHeaderLength], first[4:keyShareHeaderLength])
+		if !sameSplit {
+			return PrivateKey{}, ErrInvalidKeyShare
+		}
+
+		x := share[3]
+		if other, ok := unique[x]; ok && !bytes.Equal(other, share) {
+			return PrivateKey{}, ErrInvalidKeyShare
+		}
+		unique[x] = share
+	}
+
+	if len(unique) < threshold {
+		return PrivateKey{}, ErrInsufficientShares
+	}
+
+	points := make([][]byte, 0, len(unique))
+	xs := make([]byte, 0, len(unique))
+	for x, share := range unique {
+		points = append(points, share)
+		xs = append(xs, x)
+	}
+
+	secretLength := len(first) - keyShareHeaderLength - keyShareChecksumLength
+	secret := make([]byte, secretLength)
+	ys := make([]byte, len(points))
+
+	for j := range secret {
+		for i, share := range points {
+			ys[i] = share[keyShareHeaderLength+j]
+		}
+
+		secret[j] = gfInterpolate(xs, ys)
+	}
+
+	encoded := secret[:secretLength-keyDigestLength]
+	digest := sha256.Sum256(encoded)
+	if !bytes.Equal(digest[:keyDigestLength], secret[secretLength-keyDigestLength:]) {
+		return PrivateKey{}, ErrInvalidKeyShare
+	}
+
+	privKey, err := DecodePrivateKey(SignatureAlgorithm(first[1]), encoded)
+	if err != nil {
+		return PrivateKey{}, fmt.Errorf("crypto: invalid combined key: %w", err)
+	}
+
+	return privKey, nil
+}
+
+// checkKeyShare checks the format and checksum of a share.
+func checkKeyShare(share []byte) error {
+	if len(share) <= keyShareHeaderLength+keyDigestLength+keyShareChecksumLength {
+		return ErrInvalidKeyShare
+	}
+
+	if share[0] != keyShareVersion || share[2] < 2 || share[3] == 0 {
+		return ErrInvalidKeyShare
+	}
+
+	body := share[:len(share)-keyShareChecksumLength]
+	checksum := sha256.Sum256(body)
+	if !bytes.Equal(checksum[:keyShareChecksumLength], share[len(body):]) {
+		return ErrInvalidKeyShare
+	}
+
+	return nil
+}
+
+// gfEvaluate evaluates at x the polynomial over GF(2^8) with the given constant term
+// and coefficients of increasing degree.
+func gfEvaluate(constant byte, coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return gfMul(y, x) ^ constant
+}
+
+// gfInterpolate returns the value at 0 of the polynomial over GF(2^8) that goes
+// through the given points, with Lagrange interpolation. The xs must be distinct.
+func gfInterpolate(xs, ys []byte) byte {
+	var result byte
+	for i := range xs {
+		numerator, denominator := byte(1), byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			numerator = gfMul(numerator, xs[j])
+			denominator = gfMul(denominator, xs[i]^xs[j])
+		}
+		result ^= gfMul(ys[i], gfMul(numerator, gfInverse(denominator)))
+	}
+	return result
+}
+
+// gfMul multiplies in GF(2^8) with the AES polynomial, without data-dependent branches
+// or table lookups.
+func gfMul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		product ^= -(b & 1) & a
+		b >>= 1
+		a = (a << 1) ^ (-(a >> 7) & 0x1b)
+	}
+	return product
+}
+
+// gfInverse returns the multiplicative inverse of a non-zero element of GF(2^8), a^254.
+func gfInverse(a byte) byte {
+	result := a
+	for i := 0; i < 6; i++ {
+		result = gfMul(gfMul(result, result), a)
+	}
+	return gfMul(result, result)
+}
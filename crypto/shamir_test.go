@@ -0,0 +1,108 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crypto_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+func TestSplitKey(t *testing.T) {
+	for _, sigAlgo := range []crypto.SignatureAlgorithm{crypto.ECDSA_P256, crypto.ECDSA_secp256k1} {
+		t.Run(sigAlgo.String(), func(t *testing.T) {
+			seed := bytes.Repeat([]byte{7}, crypto.MinSeedLength)
+			privKey, err := crypto.GeneratePrivateKey(sigAlgo, seed)
+			require.NoError(t, err)
+
+			shares, err := crypto.SplitKey(privKey, 5, 3)
+			require.NoError(t, err)
+			require.Len(t, shares, 5)
+
+			for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+				var selected [][]byte
+				for _, i := range subset {
+					selected = append(selected, shares[i])
+				}
+
+				combined, err := crypto.CombineKey(selected)
+				require.NoError(t, err)
+				assert.Equal(t, sigAlgo, combined.Algorithm())
+				assert.Equal(t, privKey.Encode(), combined.Encode())
+			}
+
+			a, err := crypto.SplitKeyWithRandom(privKey, 3, 2, crypto.NewDeterministicRandomSource([]byte("golden")))
+			require.NoError(t, err)
+			b, err := crypto.SplitKeyWithRandom(privKey, 3, 2, crypto.NewDeterministicRandomSource([]byte("golden")))
+			require.NoError(t, err)
+			assert.Equal(t, a, b)
+		})
+	}
+}
+
+func TestCombineKey_Errors(t *testing.T) {
+	seed := bytes.Repeat([]byte{8}, crypto.MinSeedLength)
+	privKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed)
+	require.NoError(t, err)
+
+	shares, err := crypto.SplitKey(privKey, 3, 2)
+	require.NoError(t, err)
+
+	t.Run("Invalid split", func(t *testing.T) {
+		_, err := crypto.SplitKey(privKey, 3, 1)
+		assert.Error(t, err)
+
+		_, err = crypto.SplitKey(privKey, 2, 3)
+		assert.Error(t, err)
+
+		_, err = crypto.SplitKey(privKey, 256, 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("Insufficient shares", func(t *testing.T) {
+		_, err := crypto.CombineKey(shares[:1])
+		assert.Equal(t, crypto.ErrInsufficientShares, err)
+
+		_, err = crypto.CombineKey([][]byte{shares[0], shares[0]})
+		assert.Equal(t, crypto.ErrInsufficientShares, err)
+	})
+
+	t.Run("Corrupted share", func(t *testing.T) {
+		corrupted := append([]byte(nil), shares[1]...)
+		corrupted[20] ^= 1
+
+		_, err := crypto.CombineKey([][]byte{shares[0], corrupted})
+		assert.Equal(t, crypto.ErrInvalidKeyShare, err)
+
+		_, err = crypto.CombineKey([][]byte{shares[0], shares[1][:10]})
+		assert.Equal(t, crypto.ErrInvalidKeyShare, err)
+	})
+
+	t.Run("Shares of different splits", func(t *testing.T) {
+		other, err := crypto.SplitKey(privKey, 3, 2)
+		require.NoError(t, err)
+
+		_, err = crypto.CombineKey([][]byte{shares[0], other[1]})
+		assert.Equal(t, crypto.ErrInvalidKeyShare, err)
+	})
+}
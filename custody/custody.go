@@ -0,0 +1,273 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package custody segregates the funds of a service between hot wallets, whose keys
+// sign online, and cold wallets, whose keys are kept on offline devices.
+//
+// A Policy orders wallet tiers from the hottest to the coldest, each with a limit
+// on the amount it pays in a transaction. Withdrawals are routed to the first tier
+// that may pay them, so that large amounts require approval on a cold device:
+//
+//	policy := custody.Policy{
+//		Tiers: []custody.WalletTier{
+//			{Name: "hot", Address: hot, Signer: hotSigner, MaxAmount: 1000_00000000},
+//			{Name: "cold", Address: cold, PublicKey: coldKey, HashAlgo: crypto.SHA3_256},
+//		},
+//	}
+//
+//	tier, err := policy.Route(amount)
+//	...
+//	tx, err := transfer.TransferTokens(token, amount, recipient, tier.Address)
+//	...
+//	request, err := policy.Authorize(tx, time.Now().Add(time.Hour))
+//
+// Hot tiers sign the transaction at once. For cold tiers, Authorize returns a
+// deeplink signing request that is exported to the offline device, e.g. as a QR
+// code, and the signature it returns is imported with Import.
+package custody
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/deeplink"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+var (
+	// ErrNoWalletTier is returned when no tier of a policy may pay an amount, or
+	// sign a transaction.
+	ErrNoWalletTier = errcode.New(errcode.NoWalletTier, "custody: no wallet tier")
+	// ErrTierLimitExceeded is returned when a hot tier is asked to sign a transaction
+	// that withdraws more than its limit, or that is not built from an allowed
+	// transfer template.
+	ErrTierLimitExceeded = errcode.New(errcode.TierLimitExceeded, "custody: tier limit exceeded")
+	// ErrInvalidSignature is returned when an imported signature does not verify
+	// with the key of its tier.
+	ErrInvalidSignature = errcode.New(errcode.ImportedSignature, "custody: invalid signature")
+)
+
+// A WalletTier is a wallet account of a custody policy, and the key that signs its
+// withdrawals.
+type WalletTier struct {
+	Name     string
+	Address  flow.Address
+	KeyIndex int
+	// Signer signs the transactions of a hot tier. It is nil for cold tiers, whose
+	// key is kept offline.
+	Signer crypto.Signer
+	// PublicKey and HashAlgo verify the signatures imported for a cold tier.
+	PublicKey crypto.PublicKey
+	HashAlgo  crypto.HashAlgorithm
+	// MaxAmount is the largest amount the tier pays in a transaction. The zero
+	// amount does not limit the tier.
+	MaxAmount cadence.UFix64
+}
+
+// Cold returns true if the tier signs offline.
+func (t WalletTier) Cold() bool {
+	return t.Signer == nil
+}
+
+// allows returns true if the tier may pay the amount.
+func (t WalletTier) allows(amount cadence.UFix64) bool {
+	return t.MaxAmount == 0 || amount <= t.MaxAmount
+}
+
+// A Policy routes withdrawals to wallet tiers, ordered from the hottest to the
+// coldest.
+type Policy struct {
+	Tiers []WalletTier
+	// Templates are the transfer templates hot tiers sign. If nil, hot tiers only
+	// sign FLOW token transfers built by transfer.TransferTokens.
+	Templates *transfer.TemplateSet
+}
+
+func (p Policy) templates() *transfer.TemplateSet {
+	if p.Templates != nil {
+		return p.Templates
+	}
+	return transfer.DefaultTemplates()
+}
+
+// Validate returns an error if the policy has no tiers, tiers share a name or an
+// account, or a cold tier has no public key to verify its signatures.
+func (p Policy) Validate() error {
+	if len(p.Tiers) == 0 {
+		return fmt.Errorf("custody: policy has no tiers")
+	}
+
+	names := make(map[string]bool)
+	addresses := make(map[flow.Address]bool)
+
+	for _, tier := range p.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("custody: tier of %s has no name", tier.Address)
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("custody: duplicate tier %s", tier.Name)
+		}
+		if addresses[tier.Address] {
+			return fmt.Errorf("custody: tiers share account %s", tier.Address)
+		}
+		if tier.Cold() && tier.PublicKey == (crypto.PublicKey{}) {
+			return fmt.Errorf("custody: cold tier %s has no public key", tier.Name)
+		}
+
+		names[tier.Name] = true
+		addresses[tier.Address] = true
+	}
+
+	return nil
+}
+
+// Tier returns the tier with the given name, and false if the policy has none.
+func (p Policy) Tier(name string) (WalletTier, bool) {
+	for _, tier := range p.Tiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+	return WalletTier{}, false
+}
+
+// Route returns the first tier that may pay the amount, or an error wrapping
+// ErrNoWalletTier.
+func (p Policy) Route(amount cadence.UFix64) (WalletTier, error) {
+	for _, tier := range p.Tiers {
+		if tier.allows(amount) {
+			return tier, nil
+		}
+	}
+
+	return WalletTier{}, fmt.Errorf("%w for amount %s", ErrNoWalletTier, formatAmount(amount))
+}
+
+// Authorize signs a transaction with the tier that authorizes or pays for it. The
+// tier signs the envelope if it pays for the transaction, and the payload otherwise.
+//
+// A hot tier signs the transaction at once, and a nil request is returned. It only
+// signs fungible token transfers built from the templates of the policy, withdrawing
+// from the tier within its limit: other transactions fail with an error wrapping
+// ErrTierLimitExceeded.
+//
+// For a cold tier, the returned request is signed on the offline device, and the
+// signature is added with Import. The request expires at expiresAt, or never for the
+// zero time.
+func (p Policy) Authorize(tx *flow.Transaction, expiresAt time.Time) (*deeplink.Request, error) {
+	tier, err := p.signer(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if tier.Cold() {
+		return &deeplink.Request{
+			Kind:        deeplink.KindTransaction,
+			Signer:      tier.Address,
+			KeyIndex:    tier.KeyIndex,
+			Transaction: tx,
+			ExpiresAt:   expiresAt,
+		}, nil
+	}
+
+	intent, err := p.templates().Analyze(tx)
+	if err != nil || intent.Kind != transfer.FungibleTransfer {
+		return nil, fmt.Errorf("%w: tier %s only signs allowed token transfers", ErrTierLimitExceeded, tier.Name)
+	}
+
+	if intent.Sender != tier.Address {
+		return nil, fmt.Errorf("%w: tier %s does not sign withdrawals from %s", ErrTierLimitExceeded, tier.Name, intent.Sender)
+	}
+
+	if !tier.allows(intent.Amount) {
+		return nil, fmt.Errorf("%w: tier %s pays at most %s, not %s", ErrTierLimitExceeded, tier.Name, formatAmount(tier.MaxAmount), formatAmount(intent.Amount))
+	}
+
+	if tx.Payer == tier.Address {
+		return nil, tx.SignEnvelope(tier.Address, tier.KeyIndex, tier.Signer)
+	}
+
+	return nil, tx.SignPayload(tier.Address, tier.KeyIndex, tier.Signer)
+}
+
+// Import adds the signature returned by the offline device of a cold tier to the
+// transaction, after verifying it with the public key of the tier.
+func (p Policy) Import(tx *flow.Transaction, signature []byte) error {
+	tier, err := p.signer(tx)
+	if err != nil {
+		return err
+	}
+
+	if !tier.Cold() {
+		return fmt.Errorf("custody: tier %s is not a cold tier", tier.Name)
+	}
+	if tier.PublicKey == (crypto.PublicKey{}) {
+		return fmt.Errorf("custody: cold tier %s has no public key", tier.Name)
+	}
+
+	hasher, err := crypto.NewHasher(tier.HashAlgo)
+	if err != nil {
+		return err
+	}
+
+	message := tx.PayloadMessage()
+	if tx.Payer == tier.Address {
+		message = tx.EnvelopeMessage()
+	}
+
+	valid, err := tier.PublicKey.Verify(signature, message, hasher)
+	if err != nil || !valid {
+		return fmt.Errorf("%w of tier %s", ErrInvalidSignature, tier.Name)
+	}
+
+	if tx.Payer == tier.Address {
+		tx.AddEnvelopeSignature(tier.Address, tier.KeyIndex, signature)
+	} else {
+		tx.AddPayloadSignature(tier.Address, tier.KeyIndex, signature)
+	}
+
+	return nil
+}
+
+// signer returns the tier that authorizes the transaction, or else pays for it.
+func (p Policy) signer(tx *flow.Transaction) (WalletTier, error) {
+	for _, authorizer := range tx.Authorizers {
+		for _, tier := range p.Tiers {
+			if tier.Address == authorizer {
+				return tier, nil
+			}
+		}
+	}
+
+	for _, tier := range p.Tiers {
+		if tier.Address == tx.Payer {
+			return tier, nil
+		}
+	}
+
+	return WalletTier{}, fmt.Errorf("%w signs transaction %s", ErrNoWalletTier, tx.ID())
+}
+
+func formatAmount(amount cadence.UFix64) string {
+	return transfer.FormatTokenAmount(amount, transfer.MaxDecimals)
+}
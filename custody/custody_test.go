@@ -0,0 +1,234 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package custody_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/custody"
+	"github.com/portto/blocto-flow-go-sdk/deeplink"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+var (
+	hot       = flow.HexToAddress("01")
+	cold      = flow.HexToAddress("02")
+	recipient = flow.HexToAddress("03")
+)
+
+func generateKey(t *testing.T, seed byte) crypto.PrivateKey {
+	key, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{seed}, crypto.MinSeedLength))
+	require.NoError(t, err)
+	return key
+}
+
+func amount(t *testing.T, s string) cadence.UFix64 {
+	value, err := transfer.ParseTokenAmount(s, transfer.MaxDecimals)
+	require.NoError(t, err)
+	return value
+}
+
+func TestPolicy(t *testing.T) {
+	hotKey := generateKey(t, 1)
+	coldKey := generateKey(t, 2)
+
+	policy := custody.Policy{
+		Tiers: []custody.WalletTier{
+			{
+				Name:      "hot",
+				Address:   hot,
+				Signer:    crypto.NewInMemorySigner(hotKey, crypto.SHA3_256),
+				MaxAmount: amount(t, "1000"),
+			},
+			{
+				Name:      "cold",
+				Address:   cold,
+				KeyIndex:  2,
+				PublicKey: coldKey.PublicKey(),
+				HashAlgo:  crypto.SHA3_256,
+			},
+		},
+	}
+	require.NoError(t, policy.Validate())
+
+	token, err := transfer.FlowToken(flow.Emulator)
+	require.NoError(t, err)
+
+	withdraw := func(value string) *flow.Transaction {
+		tier, err := policy.Route(amount(t, value))
+		require.NoError(t, err)
+
+		tx, err := transfer.TransferTokens(token, amount(t, value), recipient, tier.Address)
+		require.NoError(t, err)
+
+		return tx.SetProposalKey(tier.Address, tier.KeyIndex, 0).SetPayer(tier.Address)
+	}
+
+	t.Run("Hot", func(t *testing.T) {
+		tx := withdraw("1000")
+		assert.Equal(t, hot, tx.Payer)
+
+		request, err := policy.Authorize(tx, time.Time{})
+		require.NoError(t, err)
+		assert.Nil(t, request)
+		require.Len(t, tx.EnvelopeSignatures, 1)
+
+		valid, err := hotKey.PublicKey().Verify(
+			tx.EnvelopeSignatures[0].Signature,
+			tx.EnvelopeMessage(),
+			crypto.NewSHA3_256(),
+		)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("Cold", func(t *testing.T) {
+		tx := withdraw("1000.00000001")
+		assert.Equal(t, cold, tx.Payer)
+
+		request, err := policy.Authorize(tx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, request)
+		assert.Equal(t, cold, request.Signer)
+		assert.Equal(t, 2, request.KeyIndex)
+		assert.Empty(t, tx.EnvelopeSignatures)
+
+		// export to the offline device, and sign there
+		payload, err := request.Encode()
+		require.NoError(t, err)
+
+		offline, err := deeplink.Decode(payload, time.Now())
+		require.NoError(t, err)
+
+		signature, err := offline.Sign(crypto.NewInMemorySigner(coldKey, crypto.SHA3_256))
+		require.NoError(t, err)
+
+		err = policy.Import(tx, signature[1:])
+		assert.True(t, errors.Is(err, custody.ErrInvalidSignature))
+		assert.Equal(t, errcode.ImportedSignature, errcode.Of(err))
+
+		require.NoError(t, policy.Import(tx, signature))
+		require.Len(t, tx.EnvelopeSignatures, 1)
+		assert.Equal(t, 2, tx.EnvelopeSignatures[0].KeyIndex)
+	})
+
+	t.Run("Hot limit", func(t *testing.T) {
+		tx, err := transfer.TransferTokens(token, amount(t, "5000"), recipient, hot)
+		require.NoError(t, err)
+		tx.SetPayer(hot)
+
+		_, err = policy.Authorize(tx, time.Time{})
+		assert.True(t, errors.Is(err, custody.ErrTierLimitExceeded))
+		assert.Equal(t, errcode.TierLimitExceeded, errcode.Of(err))
+		assert.Empty(t, tx.EnvelopeSignatures)
+
+		other := flow.NewTransaction().
+			SetScript([]byte("transaction { prepare(signer: AuthAccount) {} }")).
+			AddAuthorizer(hot).
+			SetPayer(hot)
+
+		_, err = policy.Authorize(other, time.Time{})
+		assert.True(t, errors.Is(err, custody.ErrTierLimitExceeded))
+
+		err = policy.Import(tx, []byte{1})
+		assert.Error(t, err)
+	})
+
+	t.Run("Allowed templates", func(t *testing.T) {
+		// a script with the parameters of the transfer template that ignores them
+		spoofed := flow.NewTransaction().
+			SetScript([]byte(`
+				import FungibleToken from 0xee82856bf20e2aa6
+				import FlowToken from 0x0ae53cb6e3f42a79
+
+				transaction(amount: UFix64, to: Address) {
+				  prepare(signer: AuthAccount) {
+				    let vault = signer.borrow<&FlowToken.Vault>(from: /storage/flowTokenVault)!
+				    getAccount(0x04).getCapability(/public/flowTokenReceiver)
+				      .borrow<&{FungibleToken.Receiver}>()!
+				      .deposit(from: <-vault.withdraw(amount: 1000000.0))
+				  }
+				}`)).
+			AddAuthorizer(hot).
+			SetPayer(hot)
+		require.NoError(t, spoofed.AddArgument(amount(t, "1")))
+		require.NoError(t, spoofed.AddArgument(cadence.NewAddress(recipient)))
+
+		_, err := policy.Authorize(spoofed, time.Time{})
+		assert.True(t, errors.Is(err, custody.ErrTierLimitExceeded))
+		assert.Empty(t, spoofed.EnvelopeSignatures)
+
+		// the FLOW token template is not in an empty allow-list
+		restricted := policy
+		restricted.Templates = transfer.NewTemplateSet()
+
+		tx := withdraw("1")
+		_, err = restricted.Authorize(tx, time.Time{})
+		assert.True(t, errors.Is(err, custody.ErrTierLimitExceeded))
+		assert.Empty(t, tx.EnvelopeSignatures)
+
+		template, err := transfer.TokenTemplate(token)
+		require.NoError(t, err)
+		require.NoError(t, restricted.Templates.Add(template))
+
+		_, err = restricted.Authorize(tx, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, tx.EnvelopeSignatures, 1)
+	})
+
+	t.Run("No tier", func(t *testing.T) {
+		limited := custody.Policy{Tiers: policy.Tiers[:1]}
+
+		_, err := limited.Route(amount(t, "1000.1"))
+		assert.True(t, errors.Is(err, custody.ErrNoWalletTier))
+		assert.Equal(t, errcode.NoWalletTier, errcode.Of(err))
+
+		tx := flow.NewTransaction().AddAuthorizer(recipient).SetPayer(recipient)
+		_, err = policy.Authorize(tx, time.Time{})
+		assert.True(t, errors.Is(err, custody.ErrNoWalletTier))
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		invalid := []custody.Policy{
+			{},
+			{Tiers: []custody.WalletTier{{Address: hot}}},
+			{Tiers: []custody.WalletTier{{Name: "cold", Address: cold}}},
+			{Tiers: []custody.WalletTier{policy.Tiers[0], policy.Tiers[0]}},
+			{Tiers: []custody.WalletTier{policy.Tiers[0], {Name: "other", Address: hot, Signer: policy.Tiers[0].Signer}}},
+		}
+
+		for _, p := range invalid {
+			assert.Error(t, p.Validate())
+		}
+
+		tier, ok := policy.Tier("cold")
+		assert.True(t, ok)
+		assert.True(t, tier.Cold())
+	})
+}
@@ -0,0 +1,313 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package deeplink serializes signing requests into compact payloads for QR codes
+// and deep links, so that a transaction or user message can be approved on a mobile
+// or air-gapped device.
+//
+// The requesting side encodes a request into a link:
+//
+//	link, err := deeplink.Request{
+//		Kind:        deeplink.KindTransaction,
+//		Signer:      address,
+//		KeyIndex:    0,
+//		Transaction: tx,
+//		ExpiresAt:   time.Now().Add(5 * time.Minute),
+//	}.Link("myapp://sign")
+//
+// and the approving side parses it, checks the request with the user, and signs it:
+//
+//	req, err := deeplink.ParseLink(link, time.Now())
+//	...
+//	signature, err := req.Sign(signer)
+//
+// Payloads carry a checksum, so that a corrupted scan is rejected rather than
+// signed, and an expiry, so that a stale request is not approved later.
+package deeplink
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// Version is the version of the payload format.
+const Version = 1
+
+// QueryParameter is the query parameter of a link that holds the payload.
+const QueryParameter = "r"
+
+var (
+	// ErrInvalidChecksum is returned when a payload does not match its checksum.
+	ErrInvalidChecksum = errcode.New(errcode.InvalidChecksum, "deeplink: invalid checksum")
+	// ErrExpired is returned when a request is parsed after its expiry.
+	ErrExpired = errcode.New(errcode.RequestExpired, "deeplink: request expired")
+)
+
+// A Kind is the kind of message a request asks to sign.
+type Kind uint8
+
+const (
+	// KindTransaction requests the signature of a transaction.
+	KindTransaction Kind = 1
+	// KindUserMessage requests the signature of a message in the user domain.
+	KindUserMessage Kind = 2
+)
+
+// String returns the name of this kind.
+func (k Kind) String() string {
+	switch k {
+	case KindTransaction:
+		return "transaction"
+	case KindUserMessage:
+		return "user message"
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// A Request asks an account key to sign a transaction or a user message.
+type Request struct {
+	Kind Kind
+	// Signer is the address of the account that must sign.
+	Signer flow.Address
+	// KeyIndex is the index of the account key that must sign.
+	KeyIndex int
+	// Transaction is the transaction to sign, for KindTransaction requests.
+	Transaction *flow.Transaction
+	// Message is the message to sign, for KindUserMessage requests.
+	Message []byte
+	// ExpiresAt is the time after which the request must not be signed. The zero
+	// time means that the request does not expire. It is encoded with a precision of
+	// one second.
+	ExpiresAt time.Time
+}
+
+const (
+	flagDeflate = 1 << 0
+
+	checksumLength = 4
+	// version, kind, flags, expiry and signer address
+	headerLength = 3 + 8 + flow.AddressLength
+)
+
+// Encode returns the payload of the request, encoded in unpadded base64url so that it
+// can be used in URLs and in the byte mode of QR codes.
+//
+// The body of the request is compressed if that makes the payload shorter.
+func (r Request) Encode() (string, error) {
+	body, err := r.body()
+	if err != nil {
+		return "", err
+	}
+
+	var flags byte
+	if compressed := deflate(body); len(compressed) < len(body) {
+		body = compressed
+		flags |= flagDeflate
+	}
+
+	var expiry uint64
+	if !r.ExpiresAt.IsZero() {
+		expiry = uint64(r.ExpiresAt.Unix())
+	}
+
+	data := make([]byte, headerLength, headerLength+binary.MaxVarintLen64+len(body)+checksumLength)
+	data[0] = Version
+	data[1] = byte(r.Kind)
+	data[2] = flags
+	binary.BigEndian.PutUint64(data[3:11], expiry)
+	copy(data[11:headerLength], r.Signer.Bytes())
+
+	var keyIndex [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(keyIndex[:], uint64(r.KeyIndex))
+	data = append(data, keyIndex[:n]...)
+	data = append(data, body...)
+	data = append(data, checksum(data)...)
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Link returns a deep link to the given base URL, e.g. "myapp://sign", that carries
+// the payload of the request in its QueryParameter.
+func (r Request) Link(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("deeplink: invalid base URL: %w", err)
+	}
+
+	payload, err := r.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set(QueryParameter, payload)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Decode parses a payload returned by Encode.
+//
+// ErrInvalidChecksum is returned if the payload is corrupted, and ErrExpired if the
+// request expired before now.
+func Decode(payload string, now time.Time) (*Request, error) {
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("deeplink: invalid payload encoding: %w", err)
+	}
+
+	if len(data) < headerLength+1+checksumLength {
+		return nil, fmt.Errorf("deeplink: payload is too short")
+	}
+
+	content, sum := data[:len(data)-checksumLength], data[len(data)-checksumLength:]
+	if !bytes.Equal(checksum(content), sum) {
+		return nil, ErrInvalidChecksum
+	}
+
+	if content[0] != Version {
+		return nil, fmt.Errorf("deeplink: unsupported payload version %d", content[0])
+	}
+
+	r := &Request{
+		Kind:   Kind(content[1]),
+		Signer: flow.BytesToAddress(content[11:headerLength]),
+	}
+
+	if expiry := binary.BigEndian.Uint64(content[3:11]); expiry != 0 {
+		r.ExpiresAt = time.Unix(int64(expiry), 0).UTC()
+		if now.After(r.ExpiresAt) {
+			return nil, ErrExpired
+		}
+	}
+
+	keyIndex, n := binary.Uvarint(content[headerLength:])
+	if n <= 0 {
+		return nil, fmt.Errorf("deeplink: invalid key index")
+	}
+	r.KeyIndex = int(keyIndex)
+
+	body := content[headerLength+n:]
+	if content[2]&flagDeflate != 0 {
+		body, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(body)))
+		if err != nil {
+			return nil, fmt.Errorf("deeplink: invalid compressed body: %w", err)
+		}
+	}
+
+	switch r.Kind {
+	case KindTransaction:
+		var tx flow.Transaction
+		if err := tx.DecodeFromBytes(body); err != nil {
+			return nil, fmt.Errorf("deeplink: invalid transaction: %w", err)
+		}
+		r.Transaction = &tx
+	case KindUserMessage:
+		r.Message = body
+	default:
+		return nil, fmt.Errorf("deeplink: unknown request kind %d", r.Kind)
+	}
+
+	return r, nil
+}
+
+// ParseLink parses a link returned by Link.
+func ParseLink(link string, now time.Time) (*Request, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("deeplink: invalid link: %w", err)
+	}
+
+	payload := u.Query().Get(QueryParameter)
+	if payload == "" {
+		return nil, fmt.Errorf("deeplink: link has no %s parameter", QueryParameter)
+	}
+
+	return Decode(payload, now)
+}
+
+// SigningMessage returns the message signed for the request: the payload of the
+// transaction, or its envelope if the signer is the payer, or the user message
+// prefixed with flow.UserDomainTag.
+func (r Request) SigningMessage() ([]byte, error) {
+	switch r.Kind {
+	case KindTransaction:
+		if r.Transaction == nil {
+			return nil, fmt.Errorf("deeplink: transaction request has no transaction")
+		}
+		if r.Transaction.Payer == r.Signer {
+			return r.Transaction.EnvelopeMessage(), nil
+		}
+		return r.Transaction.PayloadMessage(), nil
+	case KindUserMessage:
+		return append(flow.UserDomainTag[:], r.Message...), nil
+	}
+
+	return nil, fmt.Errorf("deeplink: unknown request kind %d", r.Kind)
+}
+
+// Sign signs the request with the given signer.
+func (r Request) Sign(signer crypto.Signer) ([]byte, error) {
+	message, err := r.SigningMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.Sign(message)
+}
+
+func (r Request) body() ([]byte, error) {
+	switch r.Kind {
+	case KindTransaction:
+		if r.Transaction == nil {
+			return nil, fmt.Errorf("deeplink: transaction request has no transaction")
+		}
+		return r.Transaction.Encode(), nil
+	case KindUserMessage:
+		return r.Message, nil
+	}
+
+	return nil, fmt.Errorf("deeplink: unknown request kind %d", r.Kind)
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	_, _ = w.Write(data)
+	_ = w.Close()
+
+	return buf.Bytes()
+}
+
+// checksum returns the first bytes of the SHA-256 hash of data.
+func checksum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:checksumLength]
+}
@@ -0,0 +1,146 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deeplink_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/deeplink"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestRequest_Link(t *testing.T) {
+	now := time.Date(2020, 6, 4, 15, 0, 0, 0, time.UTC)
+	tx := test.TransactionGenerator().NewUnsigned()
+
+	t.Run("Transaction", func(t *testing.T) {
+		req := deeplink.Request{
+			Kind:        deeplink.KindTransaction,
+			Signer:      tx.Authorizers[0],
+			KeyIndex:    300,
+			Transaction: tx,
+			ExpiresAt:   now.Add(5 * time.Minute),
+		}
+
+		link, err := req.Link("myapp://sign?v=1")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(link, "myapp://sign?r="))
+
+		parsed, err := deeplink.ParseLink(link, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, req.Kind, parsed.Kind)
+		assert.Equal(t, req.Signer, parsed.Signer)
+		assert.Equal(t, req.KeyIndex, parsed.KeyIndex)
+		assert.Equal(t, req.ExpiresAt, parsed.ExpiresAt)
+		assert.Equal(t, tx.ID(), parsed.Transaction.ID())
+
+		message, err := parsed.SigningMessage()
+		require.NoError(t, err)
+		assert.Equal(t, tx.PayloadMessage(), message)
+	})
+
+	t.Run("User message", func(t *testing.T) {
+		req := deeplink.Request{
+			Kind:    deeplink.KindUserMessage,
+			Signer:  tx.Payer,
+			Message: []byte(strings.Repeat("approve login ", 20)),
+		}
+
+		payload, err := req.Encode()
+		require.NoError(t, err)
+
+		// compressed below the size of the message
+		assert.True(t, len(payload) < len(req.Message))
+
+		parsed, err := deeplink.Decode(payload, now.Add(24*time.Hour))
+		require.NoError(t, err)
+
+		assert.Equal(t, req.Message, parsed.Message)
+		assert.True(t, parsed.ExpiresAt.IsZero())
+
+		message, err := parsed.SigningMessage()
+		require.NoError(t, err)
+		assert.Equal(t, append(flow.UserDomainTag[:], req.Message...), message)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		payload, err := deeplink.Request{
+			Kind:      deeplink.KindUserMessage,
+			Message:   []byte("hello"),
+			ExpiresAt: now,
+		}.Encode()
+		require.NoError(t, err)
+
+		_, err = deeplink.Decode(payload, now)
+		assert.NoError(t, err)
+
+		_, err = deeplink.Decode(payload, now.Add(time.Second))
+		assert.True(t, errors.Is(err, deeplink.ErrExpired))
+		assert.Equal(t, errcode.RequestExpired, errcode.Of(err))
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		payload, err := deeplink.Request{Kind: deeplink.KindUserMessage, Message: []byte("hello")}.Encode()
+		require.NoError(t, err)
+
+		data, err := base64.RawURLEncoding.DecodeString(payload)
+		require.NoError(t, err)
+		data[len(data)/2] ^= 1
+
+		_, err = deeplink.Decode(base64.RawURLEncoding.EncodeToString(data), now)
+		assert.True(t, errors.Is(err, deeplink.ErrInvalidChecksum))
+	})
+
+	t.Run("Missing payload", func(t *testing.T) {
+		_, err := deeplink.ParseLink("myapp://sign", now)
+		assert.Error(t, err)
+	})
+}
+
+func TestRequest_Sign(t *testing.T) {
+	key, signer := test.AccountKeyGenerator().NewWithSigner()
+	tx := test.TransactionGenerator().NewUnsigned()
+
+	req := deeplink.Request{
+		Kind:        deeplink.KindTransaction,
+		Signer:      tx.Payer,
+		Transaction: tx,
+	}
+
+	signature, err := req.Sign(signer)
+	require.NoError(t, err)
+
+	hasher, err := crypto.NewHasher(key.HashAlgo)
+	require.NoError(t, err)
+
+	valid, err := key.PublicKey.Verify(signature, tx.EnvelopeMessage(), hasher)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
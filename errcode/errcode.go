@@ -0,0 +1,311 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package errcode defines stable numeric codes for the errors returned by the SDK.
+//
+// Codes let applications map SDK failures to their own, e.g. localized, messages
+// without parsing error strings. The code of an error is found with Of, and a
+// user-facing message with Message and a Catalog:
+//
+//	catalog := errcode.MapCatalog{
+//		errcode.NoReceiver: "Le destinataire ne peut pas recevoir ce jeton.",
+//	}
+//
+//	fmt.Println(errcode.Message(err, catalog))
+//
+// Codes are grouped by package and are never reused or renumbered.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A Code identifies a kind of SDK error.
+type Code int
+
+const (
+	// Unknown is the code of errors that do not have a code.
+	Unknown Code = 0
+
+	// RPC is the code of Access API errors not covered by a more specific code.
+	RPC Code = 1000
+	// RPCUnavailable means that the Access API could not be reached.
+	RPCUnavailable Code = 1001
+	// RPCDeadlineExceeded means that an Access API call timed out.
+	RPCDeadlineExceeded Code = 1002
+	// RPCNotFound means that the Access API does not know the requested entity.
+	RPCNotFound Code = 1003
+	// RPCInvalidArgument means that the Access API rejected a request.
+	RPCInvalidArgument Code = 1004
+	// RPCBackpressure means that the Access API rejected a call because it is overloaded.
+	RPCBackpressure Code = 1005
+	// RPCMessageTooLarge means that an Access API message exceeded the maximum message size.
+	RPCMessageTooLarge Code = 1006
+	// EntityToMessage means that an entity could not be converted to a protobuf message.
+	EntityToMessage Code = 1010
+	// MessageToEntity means that a protobuf message could not be converted to an entity.
+	MessageToEntity Code = 1011
+	// EmptyMessage means that a protobuf message is empty.
+	EmptyMessage Code = 1012
+	// NoEndpoint means that no Access API endpoint is available.
+	NoEndpoint Code = 1020
+	// TransactionFailed means that the execution of a transaction failed.
+	TransactionFailed Code = 1030
+	// ScriptFailed means that the Cadence runtime failed to execute a script.
+	ScriptFailed Code = 1031
+	// SealTimeout means that a transaction was not sealed within the maximum wait.
+	SealTimeout Code = 1032
+	// SporkBoundary means that the network served by an Access node changed.
+	SporkBoundary Code = 1040
+	// ChainMismatch means that an Access node serves another network than expected.
+	ChainMismatch Code = 1041
+	// NodeUnhealthy means that an Access node failed a health check.
+	NodeUnhealthy Code = 1042
+	// CircuitOpen means that calls to an Access node are suspended after repeated failures.
+	CircuitOpen Code = 1043
+	// ReplayDetected means that a transaction was already submitted to another environment.
+	ReplayDetected Code = 1050
+	// KeyNotFound means that an account has no usable key with a public key.
+	KeyNotFound Code = 1060
+
+	// SignerTimeout means that a signer did not respond in time.
+	SignerTimeout Code = 2000
+	// NoSignerAvailable means that all signers of a fallback signer are unavailable.
+	NoSignerAvailable Code = 2001
+	// InvalidKeyShare means that a key share is malformed, corrupted or from another key.
+	InvalidKeyShare Code = 2002
+	// InsufficientShares means that fewer key shares than the threshold were combined.
+	InsufficientShares Code = 2003
+	// SignerQueueFull means that too many signatures are waiting for a rate-limited signer.
+	SignerQueueFull Code = 2004
+
+	// KeyEncrypted means that a private key must be decrypted before it is used.
+	KeyEncrypted Code = 3000
+	// WrongPassphrase means that a private key could not be decrypted with a passphrase.
+	WrongPassphrase Code = 3001
+	// BackupCorrupted means that a key backup does not match its checksum.
+	BackupCorrupted Code = 3002
+
+	// NoReceiver means that a recipient cannot receive a token.
+	NoReceiver Code = 4000
+	// InsufficientStorage means that a recipient does not have enough available storage.
+	InsufficientStorage Code = 4001
+	// UnrecognizedTransfer means that a transaction is not a recognized token transfer.
+	UnrecognizedTransfer Code = 4002
+	// DuplicatePayment means that a transaction was already submitted for a payment.
+	DuplicatePayment Code = 4003
+	// InvalidAmount means that a token amount could not be parsed.
+	InvalidAmount Code = 4004
+	// TransferFailed means that a transaction of a transfer operation failed.
+	TransferFailed Code = 4005
+
+	// NameNotFound means that an account name could not be resolved.
+	NameNotFound Code = 5000
+
+	// NoSigner means that a command requires a signer and none is configured.
+	NoSigner Code = 6000
+
+	// ExportRow means that a row could not be exported.
+	ExportRow Code = 7000
+	// ChecksumMismatch means that an exported file does not match its manifest.
+	ChecksumMismatch Code = 7001
+
+	// MessageMismatch means that the message of an FCL signable does not encode its voucher.
+	MessageMismatch Code = 8000
+	// SignerMismatch means that an FCL signable requests a signature from another account key.
+	SignerMismatch Code = 8001
+	// Declined means that an FCL authorization service declined to sign.
+	Declined Code = 8002
+
+	// InvalidChecksum means that a signing request payload is corrupted.
+	InvalidChecksum Code = 9000
+	// RequestExpired means that a signing request is past its expiry.
+	RequestExpired Code = 9001
+
+	// SchemaDrift means that an event does not match its declared schema.
+	SchemaDrift Code = 10000
+	// NoSchema means that no schema is declared for an event type.
+	NoSchema Code = 10001
+
+	// DeadlinePassed means that a signature was collected after the deadline of its signing policy.
+	DeadlinePassed Code = 11000
+	// UnexpectedSigner means that a signing policy does not expect a signature from a key.
+	UnexpectedSigner Code = 11001
+	// SignatureOrder means that a signature was collected before the roles its policy orders first.
+	SignatureOrder Code = 11002
+	// InvalidSignature means that a collected signature does not verify with its account key.
+	InvalidSignature Code = 11003
+
+	// NoWalletTier means that no wallet tier may pay an amount.
+	NoWalletTier Code = 12000
+	// TierLimitExceeded means that a transaction withdraws more than its wallet tier allows.
+	TierLimitExceeded Code = 12001
+	// ImportedSignature means that a signature imported for a cold wallet tier does not verify with its key.
+	ImportedSignature Code = 12002
+
+	// TransactionExpired means that a pre-signed transaction is past its expiry.
+	TransactionExpired Code = 13000
+
+	// ProvenanceGap means that the ownership history of an NFT is not continuous.
+	ProvenanceGap Code = 14000
+)
+
+var names = map[Code]string{
+	Unknown:              "Unknown",
+	RPC:                  "RPC",
+	RPCUnavailable:       "RPCUnavailable",
+	RPCDeadlineExceeded:  "RPCDeadlineExceeded",
+	RPCNotFound:          "RPCNotFound",
+	RPCInvalidArgument:   "RPCInvalidArgument",
+	RPCBackpressure:      "RPCBackpressure",
+	RPCMessageTooLarge:   "RPCMessageTooLarge",
+	EntityToMessage:      "EntityToMessage",
+	MessageToEntity:      "MessageToEntity",
+	EmptyMessage:         "EmptyMessage",
+	NoEndpoint:           "NoEndpoint",
+	TransactionFailed:    "TransactionFailed",
+	ScriptFailed:         "ScriptFailed",
+	SealTimeout:          "SealTimeout",
+	SporkBoundary:        "SporkBoundary",
+	ChainMismatch:        "ChainMismatch",
+	NodeUnhealthy:        "NodeUnhealthy",
+	CircuitOpen:          "CircuitOpen",
+	ReplayDetected:       "ReplayDetected",
+	KeyNotFound:          "KeyNotFound",
+	SignerTimeout:        "SignerTimeout",
+	NoSignerAvailable:    "NoSignerAvailable",
+	InvalidKeyShare:      "InvalidKeyShare",
+	InsufficientShares:   "InsufficientShares",
+	SignerQueueFull:      "SignerQueueFull",
+	KeyEncrypted:         "KeyEncrypted",
+	WrongPassphrase:      "WrongPassphrase",
+	BackupCorrupted:      "BackupCorrupted",
+	NoReceiver:           "NoReceiver",
+	InsufficientStorage:  "InsufficientStorage",
+	UnrecognizedTransfer: "UnrecognizedTransfer",
+	DuplicatePayment:     "DuplicatePayment",
+	InvalidAmount:        "InvalidAmount",
+	TransferFailed:       "TransferFailed",
+	NameNotFound:         "NameNotFound",
+	NoSigner:             "NoSigner",
+	ExportRow:            "ExportRow",
+	ChecksumMismatch:     "ChecksumMismatch",
+	MessageMismatch:      "MessageMismatch",
+	SignerMismatch:       "SignerMismatch",
+	Declined:             "Declined",
+	InvalidChecksum:      "InvalidChecksum",
+	RequestExpired:       "RequestExpired",
+	SchemaDrift:          "SchemaDrift",
+	NoSchema:             "NoSchema",
+	DeadlinePassed:       "DeadlinePassed",
+	UnexpectedSigner:     "UnexpectedSigner",
+	SignatureOrder:       "SignatureOrder",
+	InvalidSignature:     "InvalidSignature",
+	NoWalletTier:         "NoWalletTier",
+	TierLimitExceeded:    "TierLimitExceeded",
+	ImportedSignature:    "ImportedSignature",
+	TransactionExpired:   "TransactionExpired",
+	ProvenanceGap:        "ProvenanceGap",
+}
+
+// String returns the name of this code.
+func (c Code) String() string {
+	if name, ok := names[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Code(%d)", int(c))
+}
+
+// A Coder is an error that has a code.
+type Coder interface {
+	error
+	ErrorCode() Code
+}
+
+type codedError struct {
+	code Code
+	text string
+}
+
+// New returns an error with the given code and text.
+//
+// Each call returns a distinct error, so New can be used to declare sentinel errors
+// that are compared with errors.Is.
+func New(code Code, text string) error {
+	return &codedError{code: code, text: text}
+}
+
+func (e *codedError) Error() string {
+	return e.text
+}
+
+func (e *codedError) ErrorCode() Code {
+	return e.code
+}
+
+// Of returns the code of the first error in the chain of err that has one.
+//
+// Unknown is returned if err is nil or no error in its chain has a code.
+func Of(err error) Code {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	return Unknown
+}
+
+// A Catalog provides user-facing messages for errors.
+type Catalog interface {
+	// Message returns the message for an error with the given code, and false if
+	// the catalog has no message for it.
+	Message(code Code, err error) (string, bool)
+}
+
+// CatalogFunc is a function that implements the Catalog interface.
+type CatalogFunc func(code Code, err error) (string, bool)
+
+// Message calls f(code, err).
+func (f CatalogFunc) Message(code Code, err error) (string, bool) {
+	return f(code, err)
+}
+
+// A MapCatalog is a catalog of fixed messages.
+type MapCatalog map[Code]string
+
+// Message returns the message for the code.
+func (c MapCatalog) Message(code Code, _ error) (string, bool) {
+	message, ok := c[code]
+	return message, ok
+}
+
+// Message returns the catalog message for err, or the error string if the catalog
+// has no message for its code.
+func Message(err error, catalog Catalog) string {
+	if err == nil {
+		return ""
+	}
+
+	if catalog != nil {
+		if message, ok := catalog.Message(Of(err), err); ok {
+			return message
+		}
+	}
+
+	return err.Error()
+}
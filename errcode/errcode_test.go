@@ -0,0 +1,87 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package errcode_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, errcode.Unknown, errcode.Of(nil))
+	assert.Equal(t, errcode.Unknown, errcode.Of(errors.New("plain")))
+
+	wrapped := fmt.Errorf("context: %w", client.ErrNoEndpoint)
+	assert.Equal(t, errcode.NoEndpoint, errcode.Of(wrapped))
+	assert.True(t, errors.Is(wrapped, client.ErrNoEndpoint))
+
+	precondition := &transfer.PreconditionError{
+		Reason:    transfer.ErrInsufficientStorage,
+		Recipient: flow.HexToAddress("01"),
+	}
+	assert.Equal(t, errcode.InsufficientStorage, errcode.Of(precondition))
+
+	rpcErr, ok := errcodeOfRPC(codes.Unavailable)
+	assert.True(t, ok)
+	assert.Equal(t, errcode.RPCUnavailable, rpcErr)
+
+	rpcErr, _ = errcodeOfRPC(codes.Internal)
+	assert.Equal(t, errcode.RPC, rpcErr)
+}
+
+func errcodeOfRPC(code codes.Code) (errcode.Code, bool) {
+	err := client.RPCError{GRPCErr: status.Error(code, "failure")}
+	var coder errcode.Coder
+	ok := errors.As(err, &coder)
+	return errcode.Of(err), ok
+}
+
+func TestMessage(t *testing.T) {
+	catalog := errcode.MapCatalog{
+		errcode.NoReceiver: "Le destinataire ne peut pas recevoir ce jeton.",
+	}
+
+	err := fmt.Errorf("transfer failed: %w", transfer.ErrNoReceiver)
+	assert.Equal(t, "Le destinataire ne peut pas recevoir ce jeton.", errcode.Message(err, catalog))
+
+	// errors without a catalog entry keep their message
+	assert.Equal(t, client.ErrNoEndpoint.Error(), errcode.Message(client.ErrNoEndpoint, catalog))
+	assert.Equal(t, "", errcode.Message(nil, catalog))
+
+	detailed := errcode.CatalogFunc(func(code errcode.Code, err error) (string, bool) {
+		var precondition *transfer.PreconditionError
+		if errors.As(err, &precondition) {
+			return fmt.Sprintf("%s: %d bytes free", code, precondition.StorageCapacity-precondition.StorageUsed), true
+		}
+		return "", false
+	})
+
+	err = &transfer.PreconditionError{Reason: transfer.ErrInsufficientStorage, StorageUsed: 90, StorageCapacity: 100}
+	assert.Equal(t, "InsufficientStorage: 10 bytes free", errcode.Message(err, detailed))
+}
@@ -19,15 +19,24 @@
 package flow
 
 import (
+	"encoding/json"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 )
 
 // List of built-in account event types.
 const (
-	EventAccountCreated string = "flow.AccountCreated"
-	EventAccountUpdated string = "flow.AccountUpdated"
+	EventAccountCreated         string = "flow.AccountCreated"
+	EventAccountUpdated         string = "flow.AccountUpdated"
+	EventAccountKeyAdded        string = "flow.AccountKeyAdded"
+	EventAccountKeyRemoved      string = "flow.AccountKeyRemoved"
+	EventAccountContractAdded   string = "flow.AccountContractAdded"
+	EventAccountContractUpdated string = "flow.AccountContractUpdated"
 )
 
 type Event struct {
@@ -39,8 +48,127 @@ type Event struct {
 	TransactionIndex int
 	// EventIndex is the index of the event within the transaction it was emitted from.
 	EventIndex int
-	// Value contains the event data.
+	// Value contains the event data. It is empty for lazy events until decoded: use
+	// DecodeValue or Field to read them.
 	Value cadence.Event
+	// Payload is the JSON-CDC encoding of the event data. It is only set for lazy events.
+	Payload []byte
+
+	lazy *lazyEventValue
+}
+
+// lazyEventValue caches the decoded value of a lazy event, and is shared by all
+// copies of the event.
+type lazyEventValue struct {
+	once  sync.Once
+	done  uint32
+	value cadence.Event
+	err   error
+}
+
+// NewLazyEvent returns an event whose data is decoded from its JSON-CDC payload on
+// first access, and cached. The payload is retained, not copied.
+func NewLazyEvent(
+	eventType string,
+	transactionID Identifier,
+	transactionIndex int,
+	eventIndex int,
+	payload []byte,
+) Event {
+	return Event{
+		Type:             eventType,
+		TransactionID:    transactionID,
+		TransactionIndex: transactionIndex,
+		EventIndex:       eventIndex,
+		Payload:          payload,
+		lazy:             &lazyEventValue{},
+	}
+}
+
+// DecodeValue returns the event data, decoding it from the payload on first access
+// for lazy events.
+func (e Event) DecodeValue() (cadence.Event, error) {
+	if e.lazy == nil {
+		return e.Value, nil
+	}
+
+	e.lazy.once.Do(func() {
+		defer atomic.StoreUint32(&e.lazy.done, 1)
+
+		value, err := jsoncdc.Decode(e.Payload)
+		if err != nil {
+			e.lazy.err = fmt.Errorf("flow: invalid event payload: %w", err)
+			return
+		}
+
+		eventValue, ok := value.(cadence.Event)
+		if !ok {
+			e.lazy.err = fmt.Errorf("flow: expected Event value, got %s", value.Type().ID())
+			return
+		}
+
+		e.lazy.value = eventValue
+	})
+
+	return e.lazy.value, e.lazy.err
+}
+
+// jsonEventPayload is the JSON-CDC encoding of an event, with undecoded field values.
+type jsonEventPayload struct {
+	Value struct {
+		Fields []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"fields"`
+	} `json:"value"`
+}
+
+// Field returns the value of the event field with the given name, and false if the
+// event has no such field.
+//
+// For lazy events that are not decoded yet, only the requested field is decoded from
+// the payload.
+func (e Event) Field(name string) (cadence.Value, bool, error) {
+	if e.lazy != nil && !e.lazy.decoded() {
+		var payload jsonEventPayload
+		if err := json.Unmarshal(e.Payload, &payload); err != nil {
+			return nil, false, fmt.Errorf("flow: invalid event payload: %w", err)
+		}
+
+		for _, field := range payload.Value.Fields {
+			if field.Name == name {
+				value, err := jsoncdc.Decode(field.Value)
+				if err != nil {
+					return nil, false, fmt.Errorf("flow: invalid event field %s: %w", name, err)
+				}
+				return value, true, nil
+			}
+		}
+
+		return nil, false, nil
+	}
+
+	value, err := e.DecodeValue()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if value.EventType == nil {
+		return nil, false, nil
+	}
+
+	for i, field := range value.EventType.Fields {
+		if field.Identifier == name && i < len(value.Fields) {
+			return value.Fields[i], true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// decoded returns true if the value was decoded.
+func (l *lazyEventValue) decoded() bool {
+	return atomic.LoadUint32(&l.done) == 1
 }
 
 // String returns the string representation of this event.
@@ -65,6 +193,24 @@ func (e Event) Encode() []byte {
 	return mustRLPEncode(&temp)
 }
 
+// EventRangeQuery defines a query for Flow events.
+type EventRangeQuery struct {
+	// The event type to search for. If empty, no filtering by type is done.
+	Type string
+	// The block height to begin looking for events
+	StartHeight uint64
+	// The block height to end looking for events (inclusive)
+	EndHeight uint64
+}
+
+// BlockEvents are the events that occurred in a specific block.
+type BlockEvents struct {
+	BlockID        Identifier
+	Height         uint64
+	BlockTimestamp time.Time
+	Events         []Event
+}
+
 // An AccountCreatedEvent is emitted when a transaction creates a new Flow account.
 //
 // This event contains the following fields:
@@ -73,5 +219,6 @@ type AccountCreatedEvent Event
 
 // Address returns the address of the newly-created account.
 func (evt AccountCreatedEvent) Address() Address {
-	return BytesToAddress(evt.Value.Fields[0].(cadence.Address).Bytes())
+	value, _ := Event(evt).DecodeValue()
+	return BytesToAddress(value.Fields[0].(cadence.Address).Bytes())
 }
@@ -0,0 +1,145 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flow_test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func newLazyEvent(t testing.TB) (flow.Event, flow.Event) {
+	event := test.EventGenerator().New()
+
+	payload, err := jsoncdc.Encode(event.Value)
+	require.NoError(t, err)
+
+	lazy := flow.NewLazyEvent(event.Type, event.TransactionID, event.TransactionIndex, event.EventIndex, payload)
+
+	return event, lazy
+}
+
+func TestEvent_DecodeValue(t *testing.T) {
+	t.Run("Eager", func(t *testing.T) {
+		event, _ := newLazyEvent(t)
+
+		value, err := event.DecodeValue()
+		require.NoError(t, err)
+		assert.Equal(t, event.Value, value)
+	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		event, lazy := newLazyEvent(t)
+
+		assert.Nil(t, lazy.Value.Fields)
+
+		value, err := lazy.DecodeValue()
+		require.NoError(t, err)
+		assert.Equal(t, event.Value.Fields, value.Fields)
+		assert.Equal(t, event.Type, value.EventType.ID())
+	})
+
+	t.Run("Shared by copies", func(t *testing.T) {
+		_, lazy := newLazyEvent(t)
+		events := []flow.Event{lazy}
+
+		first, err := events[0].DecodeValue()
+		require.NoError(t, err)
+
+		second, err := lazy.DecodeValue()
+		require.NoError(t, err)
+		assert.True(t, first.EventType == second.EventType)
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		lazy := flow.NewLazyEvent("test.FooEvent", flow.EmptyID, 0, 0, []byte("{"))
+
+		_, err := lazy.DecodeValue()
+		assert.Error(t, err)
+
+		_, _, err = lazy.Field("a")
+		assert.Error(t, err)
+	})
+}
+
+func TestEvent_Field(t *testing.T) {
+	event, lazy := newLazyEvent(t)
+
+	for name, e := range map[string]flow.Event{"Eager": event, "Lazy": lazy} {
+		e := e
+
+		t.Run(name, func(t *testing.T) {
+			value, ok, err := e.Field("a")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, cadence.NewInt(1), value)
+
+			value, ok, err = e.Field("b")
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, cadence.NewString("foo"), value)
+
+			_, ok, err = e.Field("c")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("Lazy after decoding", func(t *testing.T) {
+		_, err := lazy.DecodeValue()
+		require.NoError(t, err)
+
+		value, ok, err := lazy.Field("b")
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, cadence.NewString("foo"), value)
+	})
+}
+
+func BenchmarkEvent_Field(b *testing.B) {
+	event, lazy := newLazyEvent(b)
+	payload := lazy.Payload
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		lazy := flow.NewLazyEvent(event.Type, event.TransactionID, event.TransactionIndex, event.EventIndex, payload)
+		_, _, _ = lazy.Field("a")
+	}
+}
+
+func BenchmarkEvent_DecodeValue(b *testing.B) {
+	event, lazy := newLazyEvent(b)
+	payload := lazy.Payload
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		lazy := flow.NewLazyEvent(event.Type, event.TransactionID, event.TransactionIndex, event.EventIndex, payload)
+		_, _ = lazy.DecodeValue()
+	}
+}
@@ -0,0 +1,385 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package eventschema validates events against the schemas that applications
+// expect them to have.
+//
+// Contract upgrades may add, remove or change the fields of the events that an
+// indexer consumes. Code that reads a field that was removed or changed type
+// silently produces zero values. A Registry holds the declared schema of each
+// event type, and reports events that drifted from it instead:
+//
+//	registry := eventschema.NewRegistry()
+//	err := registry.Register(eventschema.Schema{
+//		EventType: "A.1654653399040a61.FlowToken.TokensDeposited",
+//		Fields: []eventschema.Field{
+//			{Name: "amount", Type: "UFix64"},
+//			{Name: "to", Type: "Address?"},
+//		},
+//	})
+//
+//	fields, err := registry.Decode(event)
+//	if errors.Is(err, eventschema.ErrSchemaDrift) {
+//		// alert, and stop indexing until the schema is updated
+//	}
+package eventschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+var (
+	// ErrSchemaDrift means that an event does not match the schema of its type.
+	ErrSchemaDrift = errcode.New(errcode.SchemaDrift, "eventschema: event does not match its schema")
+	// ErrNoSchema means that no schema is registered for the type of an event.
+	ErrNoSchema = errcode.New(errcode.NoSchema, "eventschema: no schema for event type")
+)
+
+// A Field is a field of an event.
+type Field struct {
+	// Name is the name of the field.
+	Name string
+	// Type is the Cadence type of the field, e.g. "UFix64", "Address?", "[String]",
+	// "{String: UInt64}", or the qualified identifier of a composite type.
+	//
+	// A field with an empty type, "AnyStruct" or "AnyResource" may have any value.
+	Type string
+}
+
+// A Schema is the expected set of fields of an event type.
+type Schema struct {
+	// EventType is the qualified type of the event, e.g.
+	// "A.1654653399040a61.FlowToken.TokensDeposited".
+	EventType string
+	// Fields are the fields of the event.
+	Fields []Field
+	// AllowAddedFields accepts events with fields that the schema does not declare,
+	// e.g. for consumers that are not affected by new fields. Fields that are
+	// removed or change type are still reported.
+	AllowAddedFields bool
+}
+
+// A FieldChange is a field whose value does not have the declared type.
+type FieldChange struct {
+	Name string
+	// Expected is the declared type of the field.
+	Expected string
+	// Actual is the type of the value of the field.
+	Actual string
+}
+
+// A DriftError is returned for an event that does not match the schema of its type.
+//
+// Use errors.Is(err, ErrSchemaDrift) to test for it.
+type DriftError struct {
+	EventType string
+	// Added are the fields of the event that the schema does not declare.
+	Added []string
+	// Removed are the fields of the schema that the event does not have.
+	Removed []string
+	// Changed are the fields of the event whose values do not have the declared type.
+	Changed []FieldChange
+}
+
+func (e *DriftError) Error() string {
+	var parts []string
+
+	if len(e.Added) > 0 {
+		parts = append(parts, "added fields "+strings.Join(e.Added, ", "))
+	}
+
+	if len(e.Removed) > 0 {
+		parts = append(parts, "removed fields "+strings.Join(e.Removed, ", "))
+	}
+
+	for _, change := range e.Changed {
+		parts = append(parts, fmt.Sprintf("field %s is %s, expected %s", change.Name, change.Actual, change.Expected))
+	}
+
+	return fmt.Sprintf("eventschema: event %s does not match its schema: %s", e.EventType, strings.Join(parts, "; "))
+}
+
+func (e *DriftError) Unwrap() error {
+	return ErrSchemaDrift
+}
+
+// ErrorCode returns the code of ErrSchemaDrift.
+func (e *DriftError) ErrorCode() errcode.Code {
+	return errcode.SchemaDrift
+}
+
+// A Registry holds the schemas of event types.
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]Schema
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: make(map[string]Schema),
+	}
+}
+
+// Register declares the schemas of event types, replacing the previously
+// registered schemas of the same types.
+func (r *Registry) Register(schemas ...Schema) error {
+	for _, schema := range schemas {
+		if schema.EventType == "" {
+			return errors.New("eventschema: schema has no event type")
+		}
+
+		names := make(map[string]bool, len(schema.Fields))
+		for _, field := range schema.Fields {
+			if field.Name == "" {
+				return fmt.Errorf("eventschema: schema of %s has a field without a name", schema.EventType)
+			}
+			if names[field.Name] {
+				return fmt.Errorf("eventschema: schema of %s declares field %s twice", schema.EventType, field.Name)
+			}
+			names[field.Name] = true
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, schema := range schemas {
+		schema.Fields = append([]Field(nil), schema.Fields...)
+		r.schemas[schema.EventType] = schema
+	}
+
+	return nil
+}
+
+// Lookup returns the schema of an event type, and false if none is registered.
+func (r *Registry) Lookup(eventType string) (Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.schemas[eventType]
+	return schema, ok
+}
+
+// Validate returns a DriftError if the event does not match the schema of its
+// type. An error wrapping ErrNoSchema is returned if no schema is registered for
+// its type.
+func (r *Registry) Validate(event flow.Event) error {
+	_, err := r.Decode(event)
+	return err
+}
+
+// Decode returns the fields of the event by name, after validating the event
+// against the schema of its type as Validate.
+//
+// The returned fields include all declared fields, and the fields added to the
+// event if its schema allows added fields.
+func (r *Registry) Decode(event flow.Event) (map[string]cadence.Value, error) {
+	schema, ok := r.Lookup(event.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNoSchema, event.Type)
+	}
+
+	value, err := event.DecodeValue()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]cadence.Value, len(value.Fields))
+	var names []string
+
+	if value.EventType != nil {
+		for i, field := range value.EventType.Fields {
+			if i < len(value.Fields) {
+				fields[field.Identifier] = value.Fields[i]
+				names = append(names, field.Identifier)
+			}
+		}
+	}
+
+	drift := &DriftError{EventType: event.Type}
+	declared := make(map[string]bool, len(schema.Fields))
+
+	for _, field := range schema.Fields {
+		declared[field.Name] = true
+
+		fieldValue, ok := fields[field.Name]
+		if !ok {
+			drift.Removed = append(drift.Removed, field.Name)
+			continue
+		}
+
+		if !matches(fieldValue, field.Type) {
+			drift.Changed = append(drift.Changed, FieldChange{
+				Name:     field.Name,
+				Expected: field.Type,
+				Actual:   describe(fieldValue),
+			})
+		}
+	}
+
+	if !schema.AllowAddedFields {
+		for _, name := range names {
+			if !declared[name] {
+				drift.Added = append(drift.Added, name)
+			}
+		}
+	}
+
+	if len(drift.Added) > 0 || len(drift.Removed) > 0 || len(drift.Changed) > 0 {
+		return nil, drift
+	}
+
+	return fields, nil
+}
+
+// matches returns true if the value has the given type.
+//
+// Values decoded from JSON-CDC do not carry the type of their container, so arrays
+// and dictionaries are matched element by element, and values without a type match
+// any type.
+func matches(value cadence.Value, typ string) bool {
+	typ = strings.TrimSpace(typ)
+
+	if typ == "" || typ == "AnyStruct" || typ == "AnyResource" {
+		return true
+	}
+
+	optional, isOptional := value.(cadence.Optional)
+
+	if strings.HasSuffix(typ, "?") {
+		inner := strings.TrimSuffix(typ, "?")
+		if isOptional {
+			return optional.Value == nil || matches(optional.Value, inner)
+		}
+		return matches(value, inner)
+	}
+
+	if isOptional {
+		return false
+	}
+
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		array, ok := value.(cadence.Array)
+		if !ok {
+			return false
+		}
+
+		element := typ[1 : len(typ)-1]
+		// constant-sized arrays, e.g. [UInt8; 32]
+		if i := topLevelIndex(element, ';'); i >= 0 {
+			element = element[:i]
+		}
+
+		for _, v := range array.Values {
+			if !matches(v, element) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if strings.HasPrefix(typ, "{") && strings.HasSuffix(typ, "}") {
+		dictionary, ok := value.(cadence.Dictionary)
+		if !ok {
+			return false
+		}
+
+		inner := typ[1 : len(typ)-1]
+		i := topLevelIndex(inner, ':')
+		if i < 0 {
+			return false
+		}
+
+		keyType, valueType := inner[:i], inner[i+1:]
+		for _, pair := range dictionary.Pairs {
+			if !matches(pair.Key, keyType) || !matches(pair.Value, valueType) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch value.(type) {
+	case cadence.Array, cadence.Dictionary:
+		return false
+	}
+
+	valueType := value.Type()
+	if valueType == nil {
+		return true
+	}
+
+	return valueType.ID() == typ
+}
+
+// topLevelIndex returns the index of the first occurrence of c in s that is not
+// nested in brackets or braces, or -1.
+func topLevelIndex(s string, c byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// describe returns the type of a value, for drift reports.
+func describe(value cadence.Value) string {
+	switch v := value.(type) {
+	case cadence.Optional:
+		if v.Value == nil {
+			return "nil"
+		}
+		return describe(v.Value) + "?"
+	case cadence.Array:
+		if len(v.Values) == 0 {
+			return "[]"
+		}
+		return "[" + describe(v.Values[0]) + "]"
+	case cadence.Dictionary:
+		if len(v.Pairs) == 0 {
+			return "{}"
+		}
+		return "{" + describe(v.Pairs[0].Key) + ": " + describe(v.Pairs[0].Value) + "}"
+	}
+
+	if valueType := value.Type(); valueType != nil {
+		return valueType.ID()
+	}
+
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,184 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eventschema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/eventschema"
+)
+
+const depositedType = "A.0ae53cb6e3f42a79.FlowToken.TokensDeposited"
+
+// newEvent returns a lazy event with the given fields, as received from the
+// Access API.
+func newEvent(t *testing.T, fields []cadence.Field, values ...cadence.Value) flow.Event {
+	value := cadence.NewEvent(values).WithType(&cadence.EventType{
+		TypeID:     depositedType,
+		Identifier: "TokensDeposited",
+		Fields:     fields,
+	})
+
+	payload, err := jsoncdc.Encode(value)
+	require.NoError(t, err)
+
+	return flow.NewLazyEvent(depositedType, flow.Identifier{}, 0, 0, payload)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := eventschema.NewRegistry()
+	require.NoError(t, registry.Register(eventschema.Schema{
+		EventType: depositedType,
+		Fields: []eventschema.Field{
+			{Name: "amount", Type: "UFix64"},
+			{Name: "to", Type: "Address?"},
+		},
+	}))
+
+	amountField := cadence.Field{Identifier: "amount", Type: cadence.UFix64Type{}}
+	toField := cadence.Field{Identifier: "to", Type: cadence.OptionalType{Type: cadence.AddressType{}}}
+	to := cadence.NewOptional(cadence.NewAddress(flow.HexToAddress("01")))
+
+	t.Run("Valid", func(t *testing.T) {
+		event := newEvent(t, []cadence.Field{amountField, toField}, cadence.UFix64(100), to)
+
+		fields, err := registry.Decode(event)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.UFix64(100), fields["amount"])
+		assert.Equal(t, to, fields["to"])
+
+		event = newEvent(t, []cadence.Field{amountField, toField}, cadence.UFix64(100), cadence.NewOptional(nil))
+		assert.NoError(t, registry.Validate(event))
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		event := newEvent(
+			t,
+			[]cadence.Field{
+				{Identifier: "amount", Type: cadence.StringType{}},
+				{Identifier: "memo", Type: cadence.StringType{}},
+			},
+			cadence.NewString("100.0"),
+			cadence.NewString("payout"),
+		)
+
+		_, err := registry.Decode(event)
+		assert.True(t, errors.Is(err, eventschema.ErrSchemaDrift))
+		assert.Equal(t, errcode.SchemaDrift, errcode.Of(err))
+
+		var drift *eventschema.DriftError
+		require.True(t, errors.As(err, &drift))
+		assert.Equal(t, depositedType, drift.EventType)
+		assert.Equal(t, []string{"memo"}, drift.Added)
+		assert.Equal(t, []string{"to"}, drift.Removed)
+		assert.Equal(t, []eventschema.FieldChange{
+			{Name: "amount", Expected: "UFix64", Actual: "String"},
+		}, drift.Changed)
+	})
+
+	t.Run("Allow added fields", func(t *testing.T) {
+		require.NoError(t, registry.Register(eventschema.Schema{
+			EventType:        "A.0ae53cb6e3f42a79.FlowToken.TokensWithdrawn",
+			Fields:           []eventschema.Field{{Name: "amount", Type: "UFix64"}},
+			AllowAddedFields: true,
+		}))
+
+		value := cadence.NewEvent([]cadence.Value{cadence.UFix64(5), cadence.NewString("memo")}).
+			WithType(&cadence.EventType{
+				TypeID: "A.0ae53cb6e3f42a79.FlowToken.TokensWithdrawn",
+				Fields: []cadence.Field{amountField, {Identifier: "memo", Type: cadence.StringType{}}},
+			})
+
+		fields, err := registry.Decode(flow.Event{Type: "A.0ae53cb6e3f42a79.FlowToken.TokensWithdrawn", Value: value})
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewString("memo"), fields["memo"])
+	})
+
+	t.Run("No schema", func(t *testing.T) {
+		err := registry.Validate(flow.Event{Type: "A.0000000000000001.Other.Event"})
+		assert.True(t, errors.Is(err, eventschema.ErrNoSchema))
+	})
+
+	t.Run("Invalid schema", func(t *testing.T) {
+		assert.Error(t, registry.Register(eventschema.Schema{}))
+		assert.Error(t, registry.Register(eventschema.Schema{
+			EventType: depositedType,
+			Fields:    []eventschema.Field{{Name: "amount"}, {Name: "amount"}},
+		}))
+	})
+}
+
+func TestRegistry_ContainerTypes(t *testing.T) {
+	const eventType = "A.0000000000000001.Market.Listed"
+
+	registry := eventschema.NewRegistry()
+	require.NoError(t, registry.Register(eventschema.Schema{
+		EventType: eventType,
+		Fields: []eventschema.Field{
+			{Name: "ids", Type: "[UInt64]"},
+			{Name: "prices", Type: "{String: UFix64}"},
+			{Name: "hash", Type: "[UInt8; 2]"},
+			{Name: "metadata"},
+		},
+	}))
+
+	event := func(ids, prices cadence.Value) flow.Event {
+		value := cadence.NewEvent([]cadence.Value{
+			ids,
+			prices,
+			cadence.NewArray([]cadence.Value{cadence.NewUInt8(1), cadence.NewUInt8(2)}),
+			cadence.NewString("anything"),
+		}).WithType(&cadence.EventType{
+			TypeID: eventType,
+			Fields: []cadence.Field{
+				{Identifier: "ids"},
+				{Identifier: "prices"},
+				{Identifier: "hash"},
+				{Identifier: "metadata"},
+			},
+		})
+
+		return flow.Event{Type: eventType, Value: value}
+	}
+
+	ids := cadence.NewArray([]cadence.Value{cadence.NewUInt64(1), cadence.NewUInt64(2)})
+	prices := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: cadence.NewString("1"), Value: cadence.UFix64(100)},
+	})
+
+	assert.NoError(t, registry.Validate(event(ids, prices)))
+
+	err := registry.Validate(event(cadence.NewArray([]cadence.Value{cadence.NewString("1")}), prices))
+	var drift *eventschema.DriftError
+	require.True(t, errors.As(err, &drift))
+	assert.Equal(t, []eventschema.FieldChange{
+		{Name: "ids", Expected: "[UInt64]", Actual: "[String]"},
+	}, drift.Changed)
+
+	err = registry.Validate(event(ids, cadence.NewOptional(nil)))
+	assert.True(t, errors.Is(err, eventschema.ErrSchemaDrift))
+}
@@ -21,15 +21,14 @@ package examples
 import (
 	"context"
 	"crypto/rand"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"time"
 
 	"github.com/portto/blocto-flow-go-sdk"
 	"github.com/portto/blocto-flow-go-sdk/client"
 	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/keystore"
 	"github.com/portto/blocto-flow-go-sdk/templates"
 )
 
@@ -42,57 +41,28 @@ func ReadFile(path string) []byte {
 	return contents
 }
 
-const configPath = "./flow.json"
+const keysPath = "./keys.json"
 
-var (
-	servicePrivateKeyHex     string
-	servicePrivateKeySigAlgo crypto.SignatureAlgorithm
-)
-
-type config struct {
-	Accounts struct {
-		Service struct {
-			Address    string `json:"address"`
-			PrivateKey string `json:"privateKey"`
-			SigAlgo    string `json:"sigAlgorithm"`
-			HashAlgo   string `json:"hashAlgorithm"`
-		}
-	}
-}
+var serviceKey *keystore.Key
 
-func readConfig() config {
-	f, err := os.Open(configPath)
+func init() {
+	file, err := keystore.Load(keysPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			fmt.Println("Emulator examples must be run from the flow-go-sdk/examples directory. Please see flow-go-sdk/examples/README.md for more details.")
 		} else {
-			fmt.Printf("Failed to load config from %s: %s\n", configPath, err.Error())
+			fmt.Printf("Failed to load keys from %s: %s\n", keysPath, err.Error())
 		}
 
 		os.Exit(1)
 	}
 
-	d := json.NewDecoder(f)
-
-	var conf config
-
-	err = d.Decode(&conf)
+	_, serviceKey, err = file.Key("service", keystore.FlowCLILabel)
 	Handle(err)
-
-	return conf
-}
-
-func init() {
-	conf := readConfig()
-	servicePrivateKeyHex = conf.Accounts.Service.PrivateKey
-	servicePrivateKeySigAlgo = crypto.StringToSignatureAlgorithm(conf.Accounts.Service.SigAlgo)
 }
 
 func ServiceAccount(flowClient *client.Client) (flow.Address, *flow.AccountKey, crypto.Signer) {
 
-	privateKey, err := crypto.DecodePrivateKeyHex(servicePrivateKeySigAlgo, servicePrivateKeyHex)
-	Handle(err)
-
 	addr := flow.ServiceAddress(flow.Emulator)
 
 	acc, err := flowClient.GetAccount(context.Background(), addr)
@@ -100,7 +70,8 @@ func ServiceAccount(flowClient *client.Client) (flow.Address, *flow.AccountKey,
 
 	accountKey := acc.Keys[0]
 
-	signer := crypto.NewInMemorySigner(privateKey, accountKey.HashAlgo)
+	signer, err := serviceKey.Signer()
+	Handle(err)
 
 	return addr, accountKey, signer
 }
@@ -136,7 +107,8 @@ func RandomAccount(flowClient *client.Client) (flow.Address, *flow.AccountKey, c
 		nil,
 	)
 
-	signer := crypto.NewInMemorySigner(privateKey, accountKey.HashAlgo)
+	signer, err := serviceKey.Signer()
+	Handle(err)
 
 	return account.Address, account.Keys[0], signer
 }
@@ -184,20 +156,12 @@ func Handle(err error) {
 }
 
 func WaitForSeal(ctx context.Context, c *client.Client, id flow.Identifier) *flow.TransactionResult {
-	result, err := c.GetTransactionResult(ctx, id)
-	Handle(err)
-
 	fmt.Printf("Waiting for transaction %s to be sealed...\n", id)
 
-	for result.Status != flow.TransactionStatusSealed {
-		time.Sleep(time.Second)
-		fmt.Print(".")
-		result, err = c.GetTransactionResult(ctx, id)
-		Handle(err)
-	}
-
-	fmt.Println()
-	fmt.Printf("Transaction %s sealed\n", id)
+	result, err := c.WaitForSeal(ctx, id, client.WithStatusCallback(func(result *flow.TransactionResult) {
+		fmt.Printf("Transaction %s %s\n", id, result.Status)
+	}))
+	Handle(err)
 
 	return result
 }
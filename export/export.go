@@ -0,0 +1,238 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// An Exporter writes blocks, transactions and events to separate files.
+type Exporter struct {
+	blocks       *writer
+	transactions *writer
+	events       *writer
+}
+
+// NewExporter returns an exporter that writes each entity stream in the given format.
+//
+// A nil writer disables the export of the corresponding entity.
+func NewExporter(format Format, blocks, transactions, events io.Writer) (*Exporter, error) {
+	e := &Exporter{}
+
+	var err error
+
+	e.blocks, err = newWriter(format, blocks, BlockSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	e.transactions, err = newWriter(format, transactions, TransactionSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	e.events, err = newWriter(format, events, EventSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// WriteBlock writes a block.
+func (e *Exporter) WriteBlock(block flow.Block) error {
+	return e.blocks.write(
+		block.Height,
+		block.ID.Hex(),
+		block.ParentID.Hex(),
+		block.Height,
+		block.Timestamp,
+		int64(len(block.CollectionGuarantees)),
+	)
+}
+
+// WriteTransaction writes a transaction included in the block at the given height.
+//
+// The result is optional; if it is nil, the status and error columns are empty.
+func (e *Exporter) WriteTransaction(height uint64, tx flow.Transaction, result *flow.TransactionResult) error {
+	args := make([]string, len(tx.Arguments))
+	for i, arg := range tx.Arguments {
+		args[i] = string(bytes.TrimSpace(arg))
+	}
+
+	authorizers := make([]string, len(tx.Authorizers))
+	for i, authorizer := range tx.Authorizers {
+		authorizers[i] = authorizer.Hex()
+	}
+
+	var status, errorMessage string
+	if result != nil {
+		status = result.Status.String()
+		if result.Error != nil {
+			errorMessage = result.Error.Error()
+		}
+	}
+
+	return e.transactions.write(
+		height,
+		height,
+		tx.ID().Hex(),
+		tx.ReferenceBlockID.Hex(),
+		string(tx.Script),
+		"["+strings.Join(args, ",")+"]",
+		tx.GasLimit,
+		tx.ProposalKey.Address.Hex(),
+		int64(tx.ProposalKey.KeyIndex),
+		tx.ProposalKey.SequenceNumber,
+		tx.Payer.Hex(),
+		strings.Join(authorizers, ","),
+		status,
+		errorMessage,
+	)
+}
+
+// WriteEvents writes the events of a block.
+//
+// The height of the block is covered by the events file of the manifest even if
+// the block has no events.
+func (e *Exporter) WriteEvents(block client.BlockEvents) error {
+	e.events.cover(block.Height)
+
+	for _, event := range block.Events {
+		payload := event.Payload
+		if payload == nil {
+			var err error
+			payload, err = jsoncdc.Encode(event.Value)
+			if err != nil {
+				return fmt.Errorf("export: failed to encode event payload: %w", err)
+			}
+		}
+
+		err := e.events.write(
+			block.Height,
+			block.BlockID.Hex(),
+			block.Height,
+			block.BlockTimestamp,
+			event.TransactionID.Hex(),
+			int64(event.TransactionIndex),
+			int64(event.EventIndex),
+			event.Type,
+			string(bytes.TrimSpace(payload)),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes all row writers and returns the first error encountered.
+func (e *Exporter) Close() error {
+	var result error
+	for _, w := range []*writer{e.blocks, e.transactions, e.events} {
+		if err := w.close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// writer checks rows against a schema before writing them, and records the
+// manifest of the written file.
+//
+// A nil writer discards all rows.
+type writer struct {
+	schema  Schema
+	rows    RowWriter
+	file    *checksumWriter
+	count   int
+	heights bool
+	start   uint64
+	end     uint64
+}
+
+func newWriter(format Format, w io.Writer, schema Schema) (*writer, error) {
+	if w == nil {
+		return nil, nil
+	}
+
+	file := newChecksumWriter(w)
+
+	rows, err := format.NewRowWriter(file, schema)
+	if err != nil {
+		return nil, fmt.Errorf("export: failed to create %s writer: %w", schema.Name, err)
+	}
+
+	return &writer{schema: schema, rows: rows, file: file}, nil
+}
+
+// write writes a row of an entity at the given block height.
+func (w *writer) write(height uint64, values ...interface{}) error {
+	if w == nil {
+		return nil
+	}
+
+	row := append(Row{int64(w.schema.Version)}, values...)
+
+	if err := w.schema.checkRow(row); err != nil {
+		return err
+	}
+
+	if err := w.rows.WriteRow(row); err != nil {
+		return err
+	}
+
+	w.count++
+	w.cover(height)
+
+	return nil
+}
+
+// cover records that the file covers the given block height.
+func (w *writer) cover(height uint64) {
+	if w == nil {
+		return
+	}
+
+	if !w.heights || height < w.start {
+		w.start = height
+	}
+
+	if !w.heights || height > w.end {
+		w.end = height
+	}
+
+	w.heights = true
+}
+
+func (w *writer) close() error {
+	if w == nil {
+		return nil
+	}
+	return w.rows.Close()
+}
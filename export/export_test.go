@@ -0,0 +1,188 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/export"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func readCSV(t *testing.T, r io.Reader) [][]string {
+	records, err := csv.NewReader(r).ReadAll()
+	require.NoError(t, err)
+	return records
+}
+
+func TestExporter_CSV(t *testing.T) {
+	var blocks, transactions, events bytes.Buffer
+
+	e, err := export.NewExporter(export.CSV, &blocks, &transactions, &events)
+	require.NoError(t, err)
+
+	block := test.BlockGenerator().New()
+	tx := test.TransactionGenerator().New()
+	result := test.TransactionResultGenerator().New()
+	event := test.EventGenerator().New()
+
+	require.NoError(t, e.WriteBlock(*block))
+	require.NoError(t, e.WriteTransaction(block.Height, *tx, &result))
+	require.NoError(t, e.WriteEvents(client.BlockEvents{
+		BlockID:        block.ID,
+		Height:         block.Height,
+		BlockTimestamp: block.Timestamp,
+		Events:         []flow.Event{event},
+	}))
+	require.NoError(t, e.Close())
+
+	t.Run("Blocks", func(t *testing.T) {
+		records := readCSV(t, &blocks)
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{"schema_version", "id", "parent_id", "height", "timestamp", "collection_count"}, records[0])
+		assert.Equal(t, "1", records[1][0])
+		assert.Equal(t, block.ID.Hex(), records[1][1])
+	})
+
+	t.Run("Transactions", func(t *testing.T) {
+		records := readCSV(t, &transactions)
+		require.Len(t, records, 2)
+		assert.Len(t, records[1], len(export.TransactionSchema.Columns))
+		assert.Equal(t, tx.ID().Hex(), records[1][2])
+		assert.Equal(t, result.Status.String(), records[1][12])
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		records := readCSV(t, &events)
+		require.Len(t, records, 2)
+		assert.Equal(t, event.Type, records[1][7])
+		assert.True(t, strings.HasPrefix(records[1][8], `{"type":"Event"`))
+	})
+}
+
+// recordingFormat is a pluggable format that records rows in memory.
+type recordingFormat struct {
+	schemas []export.Schema
+	rows    []export.Row
+}
+
+func (f *recordingFormat) NewRowWriter(_ io.Writer, schema export.Schema) (export.RowWriter, error) {
+	f.schemas = append(f.schemas, schema)
+	return f, nil
+}
+
+func (f *recordingFormat) WriteRow(row export.Row) error {
+	f.rows = append(f.rows, row)
+	return nil
+}
+
+func (f *recordingFormat) Close() error { return nil }
+
+func TestExporter_PluggableFormat(t *testing.T) {
+	format := &recordingFormat{}
+
+	e, err := export.NewExporter(format, &bytes.Buffer{}, nil, nil)
+	require.NoError(t, err)
+
+	// transactions are not exported
+	require.NoError(t, e.WriteTransaction(1, *test.TransactionGenerator().New(), nil))
+
+	block := test.BlockGenerator().New()
+	require.NoError(t, e.WriteBlock(*block))
+
+	require.Len(t, format.schemas, 1)
+	assert.Equal(t, "blocks", format.schemas[0].Name)
+	assert.Equal(t, export.SchemaVersion, format.schemas[0].Version)
+
+	require.Len(t, format.rows, 1)
+	assert.Equal(t, export.Row{
+		int64(export.SchemaVersion),
+		block.ID.Hex(),
+		block.ParentID.Hex(),
+		block.Height,
+		block.Timestamp,
+		int64(len(block.CollectionGuarantees)),
+	}, format.rows[0])
+}
+
+func TestExporter_Manifest(t *testing.T) {
+	var blocks, events bytes.Buffer
+
+	e, err := export.NewExporter(export.CSV, &blocks, nil, &events)
+	require.NoError(t, err)
+
+	generator := test.BlockGenerator()
+	first := generator.New()
+	second := generator.New()
+
+	require.NoError(t, e.WriteBlock(*first))
+	require.NoError(t, e.WriteBlock(*second))
+	require.NoError(t, e.WriteEvents(client.BlockEvents{
+		BlockID: first.ID,
+		Height:  first.Height,
+		Events:  []flow.Event{test.EventGenerator().New()},
+	}))
+	require.NoError(t, e.WriteEvents(client.BlockEvents{BlockID: second.ID, Height: second.Height}))
+	require.NoError(t, e.Close())
+
+	manifest := e.Manifest()
+	assert.Equal(t, export.ManifestVersion, manifest.Version)
+	assert.True(t, manifest.HasHeights)
+	assert.Equal(t, first.Height, manifest.StartHeight)
+	assert.Equal(t, second.Height, manifest.EndHeight)
+	require.Len(t, manifest.Files, 2)
+
+	blocksFile, ok := manifest.File("blocks")
+	require.True(t, ok)
+	assert.Equal(t, 2, blocksFile.Rows)
+	assert.Equal(t, int64(blocks.Len()), blocksFile.Size)
+
+	eventsFile, ok := manifest.File("events")
+	require.True(t, ok)
+	assert.Equal(t, 1, eventsFile.Rows)
+	assert.Equal(t, second.Height, eventsFile.EndHeight)
+
+	err = manifest.Verify(map[string]io.Reader{
+		"blocks": bytes.NewReader(blocks.Bytes()),
+		"events": bytes.NewReader(events.Bytes()),
+	})
+	assert.NoError(t, err)
+
+	tampered := append([]byte{}, events.Bytes()...)
+	tampered[len(tampered)-2] ^= 1
+
+	err = manifest.Verify(map[string]io.Reader{
+		"blocks": bytes.NewReader(blocks.Bytes()),
+		"events": bytes.NewReader(tampered),
+	})
+	assert.True(t, errors.Is(err, export.ErrChecksumMismatch))
+
+	err = manifest.Verify(map[string]io.Reader{"blocks": bytes.NewReader(blocks.Bytes())})
+	assert.True(t, errors.Is(err, export.ErrChecksumMismatch))
+}
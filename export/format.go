@@ -0,0 +1,119 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// A RowError is returned when a row does not match its schema.
+type RowError struct {
+	Schema string
+	// Column is the index of the mismatched column, or -1 if the row has the wrong length.
+	Column int
+	Value  interface{}
+}
+
+func (e *RowError) Error() string {
+	if e.Column < 0 {
+		return fmt.Sprintf("export: %s row has %v values", e.Schema, e.Value)
+	}
+	return fmt.Sprintf("export: invalid %s value at column %d: %T", e.Schema, e.Column, e.Value)
+}
+
+// ErrorCode returns errcode.ExportRow.
+func (e *RowError) ErrorCode() errcode.Code {
+	return errcode.ExportRow
+}
+
+// A RowWriter writes rows of a single schema to a file.
+type RowWriter interface {
+	// WriteRow writes a single row. The row has already been checked against the schema.
+	WriteRow(row Row) error
+	// Close flushes buffered rows and finalizes the file. It does not close the
+	// underlying writer.
+	Close() error
+}
+
+// A Format creates row writers for a file format.
+//
+// Columnar formats such as Parquet are supported by implementing this interface,
+// mapping each column type to the corresponding physical type of the format and
+// storing the schema name and version in the file metadata.
+type Format interface {
+	NewRowWriter(w io.Writer, schema Schema) (RowWriter, error)
+}
+
+// CSV is the CSV format.
+//
+// The first line of each file is a header with the column names. Timestamps are
+// written in RFC 3339 format in UTC.
+var CSV Format = csvFormat{}
+
+type csvFormat struct{}
+
+func (csvFormat) NewRowWriter(w io.Writer, schema Schema) (RowWriter, error) {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(schema.Columns))
+	for i, column := range schema.Columns {
+		header[i] = column.Name
+	}
+
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &csvRowWriter{w: cw, record: make([]string, len(schema.Columns))}, nil
+}
+
+type csvRowWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (w *csvRowWriter) WriteRow(row Row) error {
+	for i, value := range row {
+		switch v := value.(type) {
+		case string:
+			w.record[i] = v
+		case uint64:
+			w.record[i] = strconv.FormatUint(v, 10)
+		case int64:
+			w.record[i] = strconv.FormatInt(v, 10)
+		case bool:
+			w.record[i] = strconv.FormatBool(v)
+		case time.Time:
+			w.record[i] = v.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	return w.w.Write(w.record)
+}
+
+func (w *csvRowWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
@@ -0,0 +1,188 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ManifestVersion is the version of the manifests written by this package.
+const ManifestVersion = 1
+
+// ErrChecksumMismatch is returned when a file does not match its manifest.
+var ErrChecksumMismatch = errcode.New(errcode.ChecksumMismatch, "export: file does not match its manifest")
+
+// A Manifest describes the files written by an exporter, so that archived datasets
+// can be verified before they are loaded. Manifests are encoded as JSON next to the
+// files they describe.
+type Manifest struct {
+	Version int `json:"version"`
+	// StartHeight and EndHeight are the lowest and highest block heights covered
+	// by the files. They are only meaningful if HasHeights is true.
+	StartHeight uint64         `json:"startHeight"`
+	EndHeight   uint64         `json:"endHeight"`
+	HasHeights  bool           `json:"hasHeights"`
+	Files       []FileManifest `json:"files"`
+}
+
+// A FileManifest describes a single exported file.
+type FileManifest struct {
+	// Schema is the name of the schema of the rows in the file, e.g. "blocks".
+	Schema        string `json:"schema"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Rows          int    `json:"rows"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded SHA-256 digest of the file.
+	SHA256 string `json:"sha256"`
+	// StartHeight and EndHeight are the lowest and highest block heights covered by
+	// the file. They are only meaningful if HasHeights is true.
+	StartHeight uint64 `json:"startHeight"`
+	EndHeight   uint64 `json:"endHeight"`
+	HasHeights  bool   `json:"hasHeights"`
+}
+
+// Manifest returns the manifest of the files written by this exporter.
+//
+// Formats may buffer rows, so the manifest is only complete once the exporter
+// is closed.
+func (e *Exporter) Manifest() Manifest {
+	manifest := Manifest{Version: ManifestVersion}
+
+	for _, w := range []*writer{e.blocks, e.transactions, e.events} {
+		if w == nil {
+			continue
+		}
+
+		file := w.manifest()
+		manifest.Files = append(manifest.Files, file)
+
+		if !file.HasHeights {
+			continue
+		}
+
+		if !manifest.HasHeights || file.StartHeight < manifest.StartHeight {
+			manifest.StartHeight = file.StartHeight
+		}
+
+		if !manifest.HasHeights || file.EndHeight > manifest.EndHeight {
+			manifest.EndHeight = file.EndHeight
+		}
+
+		manifest.HasHeights = true
+	}
+
+	return manifest
+}
+
+// File returns the manifest of the file with the given schema name, and false if
+// the manifest has no such file.
+func (m Manifest) File(schema string) (FileManifest, bool) {
+	for _, file := range m.Files {
+		if file.Schema == schema {
+			return file, true
+		}
+	}
+
+	return FileManifest{}, false
+}
+
+// Verify reads every file of the manifest from files, keyed by schema name, and
+// returns an error wrapping ErrChecksumMismatch if a file is missing, or its size
+// or checksum do not match the manifest.
+func (m Manifest) Verify(files map[string]io.Reader) error {
+	for _, file := range m.Files {
+		r, ok := files[file.Schema]
+		if !ok {
+			return fmt.Errorf("%w: missing %s file", ErrChecksumMismatch, file.Schema)
+		}
+
+		if err := file.Verify(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Verify reads the file from r and returns an error wrapping ErrChecksumMismatch if
+// its size or checksum do not match this manifest.
+func (f FileManifest) Verify(r io.Reader) error {
+	w := newChecksumWriter(nil)
+
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("export: failed to read %s file: %w", f.Schema, err)
+	}
+
+	if w.size != f.Size {
+		return fmt.Errorf("%w: %s file has %d bytes, expected %d", ErrChecksumMismatch, f.Schema, w.size, f.Size)
+	}
+
+	if sum := w.sum(); sum != f.SHA256 {
+		return fmt.Errorf("%w: %s file has checksum %s, expected %s", ErrChecksumMismatch, f.Schema, sum, f.SHA256)
+	}
+
+	return nil
+}
+
+func (w *writer) manifest() FileManifest {
+	return FileManifest{
+		Schema:        w.schema.Name,
+		SchemaVersion: w.schema.Version,
+		Rows:          w.count,
+		Size:          w.file.size,
+		SHA256:        w.file.sum(),
+		StartHeight:   w.start,
+		EndHeight:     w.end,
+		HasHeights:    w.heights,
+	}
+}
+
+// checksumWriter computes the size and SHA-256 digest of the data written to an
+// underlying writer, if any.
+type checksumWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	size int64
+}
+
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	return &checksumWriter{w: w, hash: sha256.New()}
+}
+
+func (c *checksumWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if c.w != nil {
+		n, err = c.w.Write(p)
+	}
+
+	c.hash.Write(p[:n])
+	c.size += int64(n)
+	return n, err
+}
+
+func (c *checksumWriter) sum() string {
+	return hex.EncodeToString(c.hash.Sum(nil))
+}
@@ -0,0 +1,174 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package export writes Flow blocks, transactions and events to tabular files for
+// bulk loading into data warehouses.
+//
+// Each entity stream is written as rows of a versioned Schema through a Format.
+// CSV is built in; columnar formats such as Parquet can be plugged in by
+// implementing Format on top of a Parquet library.
+//
+// Every row starts with a schema_version column, so that files written by
+// different versions of this package can be loaded into the same table and
+// migrated when the schema changes.
+//
+// The Manifest of an exporter records the size, SHA-256 checksum, row count and
+// block heights of each file, so that archived datasets can be verified with
+// Manifest.Verify before downstream pipelines trust them.
+package export
+
+import (
+	"time"
+)
+
+// SchemaVersion is the version of the schemas written by this package.
+//
+// The version is incremented whenever a column is added, removed or changes meaning.
+const SchemaVersion = 1
+
+// A ColumnType is the type of the values of a column.
+type ColumnType int
+
+const (
+	// ColumnString holds string values.
+	ColumnString ColumnType = iota
+	// ColumnUint64 holds uint64 values.
+	ColumnUint64
+	// ColumnInt64 holds int64 values.
+	ColumnInt64
+	// ColumnBool holds bool values.
+	ColumnBool
+	// ColumnTimestamp holds time.Time values.
+	ColumnTimestamp
+)
+
+// String returns the string representation of this column type.
+func (t ColumnType) String() string {
+	switch t {
+	case ColumnString:
+		return "string"
+	case ColumnUint64:
+		return "uint64"
+	case ColumnInt64:
+		return "int64"
+	case ColumnBool:
+		return "bool"
+	case ColumnTimestamp:
+		return "timestamp"
+	}
+	return "unknown"
+}
+
+// A Column is a named, typed column of a schema.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// A Schema describes the rows of an exported entity stream.
+type Schema struct {
+	// Name is the name of the entity, e.g. "blocks".
+	Name    string
+	Version int
+	Columns []Column
+}
+
+// A Row is a list of values, one per schema column, in column order.
+//
+// Values must have the Go type that corresponds to their column type: string,
+// uint64, int64, bool or time.Time.
+type Row []interface{}
+
+func newSchema(name string, columns ...Column) Schema {
+	return Schema{
+		Name:    name,
+		Version: SchemaVersion,
+		Columns: append([]Column{{"schema_version", ColumnInt64}}, columns...),
+	}
+}
+
+// BlockSchema is the schema of exported blocks.
+var BlockSchema = newSchema(
+	"blocks",
+	Column{"id", ColumnString},
+	Column{"parent_id", ColumnString},
+	Column{"height", ColumnUint64},
+	Column{"timestamp", ColumnTimestamp},
+	Column{"collection_count", ColumnInt64},
+)
+
+// TransactionSchema is the schema of exported transactions.
+var TransactionSchema = newSchema(
+	"transactions",
+	Column{"block_height", ColumnUint64},
+	Column{"id", ColumnString},
+	Column{"reference_block_id", ColumnString},
+	Column{"script", ColumnString},
+	Column{"arguments", ColumnString},
+	Column{"gas_limit", ColumnUint64},
+	Column{"proposer", ColumnString},
+	Column{"proposer_key_index", ColumnInt64},
+	Column{"proposer_sequence_number", ColumnUint64},
+	Column{"payer", ColumnString},
+	Column{"authorizers", ColumnString},
+	Column{"status", ColumnString},
+	Column{"error", ColumnString},
+)
+
+// EventSchema is the schema of exported events.
+var EventSchema = newSchema(
+	"events",
+	Column{"block_id", ColumnString},
+	Column{"block_height", ColumnUint64},
+	Column{"block_timestamp", ColumnTimestamp},
+	Column{"transaction_id", ColumnString},
+	Column{"transaction_index", ColumnInt64},
+	Column{"event_index", ColumnInt64},
+	Column{"type", ColumnString},
+	Column{"payload", ColumnString},
+)
+
+// checkRow returns an error if the row does not match the schema.
+func (s Schema) checkRow(row Row) error {
+	if len(row) != len(s.Columns) {
+		return &RowError{Schema: s.Name, Column: -1, Value: len(row)}
+	}
+
+	for i, column := range s.Columns {
+		var ok bool
+
+		switch row[i].(type) {
+		case string:
+			ok = column.Type == ColumnString
+		case uint64:
+			ok = column.Type == ColumnUint64
+		case int64:
+			ok = column.Type == ColumnInt64
+		case bool:
+			ok = column.Type == ColumnBool
+		case time.Time:
+			ok = column.Type == ColumnTimestamp
+		}
+
+		if !ok {
+			return &RowError{Schema: s.Name, Column: i, Value: row[i]}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package faucet provides a client for the faucet of the Flow test network, to
+// provision funded accounts in integration tests.
+//
+// The faucet creates and funds accounts with transactions that it sends on behalf
+// of the caller. When the faucet responds with the ID of such a transaction, the
+// client waits for it to be sealed with a Flow client before returning.
+package faucet
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// TestnetURL is the URL of the faucet of the Flow test network.
+const TestnetURL = "https://testnet-faucet.onflow.org"
+
+// DefaultPollInterval is the default time between two requests for the result of a
+// faucet transaction.
+const DefaultPollInterval = time.Second
+
+// A Client is a client for a faucet API.
+type Client struct {
+	// URL is the base URL of the faucet API.
+	URL string
+	// Flow is the client used to wait for faucet transactions.
+	Flow flow.Client
+	// HTTPClient sends the requests to the faucet API.
+	//
+	// Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Token, if set, is sent with each request to authenticate with the faucet.
+	Token string
+	// PollInterval is the time between two requests for the result of a faucet
+	// transaction.
+	//
+	// Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// New returns a client for the faucet at the given URL that waits for faucet
+// transactions with flowClient.
+func New(url string, flowClient flow.Client) *Client {
+	return &Client{
+		URL:  url,
+		Flow: flowClient,
+	}
+}
+
+// An APIError is an error response of the faucet API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("faucet: request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+type createAccountRequest struct {
+	PublicKey          string `json:"publicKey"`
+	SignatureAlgorithm string `json:"signatureAlgorithm"`
+	HashAlgorithm      string `json:"hashAlgorithm"`
+	Token              string `json:"token,omitempty"`
+}
+
+type fundAccountRequest struct {
+	Address string `json:"address"`
+	Token   string `json:"token,omitempty"`
+}
+
+type response struct {
+	Address       string `json:"address"`
+	TransactionID string `json:"transactionId"`
+	Amount        string `json:"amount"`
+	Error         string `json:"error"`
+}
+
+// CreateAccount creates a funded account with a single key of full weight, with the
+// given public key and hash algorithm, and returns its address once it exists.
+func (c *Client) CreateAccount(
+	ctx context.Context,
+	publicKey crypto.PublicKey,
+	hashAlgo crypto.HashAlgorithm,
+) (flow.Address, error) {
+	res, err := c.post(ctx, "/api/account", createAccountRequest{
+		PublicKey:          hex.EncodeToString(publicKey.Encode()),
+		SignatureAlgorithm: publicKey.Algorithm().String(),
+		HashAlgorithm:      hashAlgo.String(),
+		Token:              c.Token,
+	})
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	var result *flow.TransactionResult
+	if res.TransactionID != "" {
+		result, err = c.wait(ctx, res.TransactionID)
+		if err != nil {
+			return flow.EmptyAddress, err
+		}
+	}
+
+	if res.Address != "" {
+		return flow.HexToAddress(res.Address), nil
+	}
+
+	if result != nil {
+		for _, event := range result.Events {
+			if event.Type == flow.EventAccountCreated {
+				return flow.AccountCreatedEvent(event).Address(), nil
+			}
+		}
+	}
+
+	return flow.EmptyAddress, fmt.Errorf("faucet: response has no account address")
+}
+
+// FundAccount funds an existing account, and returns the amount of tokens it
+// received once the funding transaction is sealed. The amount is zero if the faucet
+// does not report it.
+func (c *Client) FundAccount(ctx context.Context, address flow.Address) (cadence.UFix64, error) {
+	res, err := c.post(ctx, "/api/fund", fundAccountRequest{
+		Address: "0x" + address.Hex(),
+		Token:   c.Token,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if res.TransactionID != "" {
+		if _, err := c.wait(ctx, res.TransactionID); err != nil {
+			return 0, err
+		}
+	}
+
+	if res.Amount == "" {
+		return 0, nil
+	}
+
+	amount, err := cadence.NewUFix64(res.Amount)
+	if err != nil {
+		return 0, fmt.Errorf("faucet: invalid amount %q: %w", res.Amount, err)
+	}
+
+	return amount, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, body interface{}) (*response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.URL, "/")+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	httpRes, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	b, err = ioutil.ReadAll(httpRes.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var res response
+	decodeErr := json.Unmarshal(b, &res)
+
+	if httpRes.StatusCode < 200 || httpRes.StatusCode >= 300 {
+		message := res.Error
+		if decodeErr != nil || message == "" {
+			message = strings.TrimSpace(string(b))
+		}
+		return nil, &APIError{StatusCode: httpRes.StatusCode, Message: message}
+	}
+
+	if decodeErr != nil {
+		return nil, fmt.Errorf("faucet: invalid response: %w", decodeErr)
+	}
+
+	return &res, nil
+}
+
+// wait waits for a faucet transaction to be sealed.
+func (c *Client) wait(ctx context.Context, id string) (*flow.TransactionResult, error) {
+	txID := flow.HexToID(strings.TrimPrefix(id, "0x"))
+
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	for {
+		result, err := c.Flow.GetTransactionResult(ctx, txID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case result.Error != nil:
+			return nil, fmt.Errorf("faucet: transaction %s failed: %w", txID, result.Error)
+		case result.Status == flow.TransactionStatusExpired:
+			return nil, fmt.Errorf("faucet: transaction %s expired", txID)
+		case result.Status == flow.TransactionStatusSealed:
+			return result, nil
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faucet_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/faucet"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// resultClient is a flow.Client that reports transactions as pending, then sealed
+// with the given events.
+type resultClient struct {
+	flow.Client
+	polls  int
+	events []flow.Event
+}
+
+func (c *resultClient) GetTransactionResult(context.Context, flow.Identifier) (*flow.TransactionResult, error) {
+	c.polls++
+	if c.polls < 2 {
+		return &flow.TransactionResult{Status: flow.TransactionStatusPending}, nil
+	}
+	return &flow.TransactionResult{Status: flow.TransactionStatusSealed, Events: c.events}, nil
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	address := flow.HexToAddress("01cf0e2f2f715450")
+	key := test.AccountKeyGenerator().New()
+
+	var requests []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		switch r.URL.Path {
+		case "/api/account":
+			_ = json.NewEncoder(w).Encode(map[string]string{"transactionId": "0x01"})
+		case "/api/fund":
+			_ = json.NewEncoder(w).Encode(map[string]string{"transactionId": "02", "amount": "1000.0"})
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "slow down"})
+		}
+	}))
+	defer server.Close()
+
+	newClient := func() (*faucet.Client, *resultClient) {
+		flowClient := &resultClient{
+			events: []flow.Event{{
+				Type:  flow.EventAccountCreated,
+				Value: cadence.NewEvent([]cadence.Value{cadence.NewAddress(address)}),
+			}},
+		}
+
+		c := faucet.New(server.URL, flowClient)
+		c.Token = "token"
+		c.PollInterval = time.Millisecond
+
+		return c, flowClient
+	}
+
+	t.Run("Create account", func(t *testing.T) {
+		c, flowClient := newClient()
+
+		created, err := c.CreateAccount(ctx, key.PublicKey, key.HashAlgo)
+		require.NoError(t, err)
+		assert.Equal(t, address, created)
+		assert.Equal(t, 2, flowClient.polls)
+
+		request := requests[len(requests)-1]
+		assert.Equal(t, "ECDSA_P256", request["signatureAlgorithm"])
+		assert.Equal(t, "SHA3_256", request["hashAlgorithm"])
+		assert.Equal(t, "token", request["token"])
+		assert.Len(t, request["publicKey"], 128)
+	})
+
+	t.Run("Fund account", func(t *testing.T) {
+		c, _ := newClient()
+
+		amount, err := c.FundAccount(ctx, address)
+		require.NoError(t, err)
+		expected, err := cadence.NewUFix64("1000.0")
+		require.NoError(t, err)
+		assert.Equal(t, expected, amount)
+		assert.Equal(t, "0x01cf0e2f2f715450", requests[len(requests)-1]["address"])
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		c, _ := newClient()
+		c.URL += "/other"
+
+		_, err := c.FundAccount(ctx, address)
+
+		apiErr, ok := err.(*faucet.APIError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+		assert.Equal(t, "slow down", apiErr.Message)
+	})
+}
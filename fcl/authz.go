@@ -0,0 +1,175 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fcl
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrSignerMismatch is returned when a signable requests a signature from an account
+// key other than the key of the authorizer.
+var ErrSignerMismatch = errcode.New(errcode.SignerMismatch, "fcl: signable requests another account key")
+
+// ErrDeclined is returned by an approval function to decline a signable without a
+// specific reason.
+var ErrDeclined = errcode.New(errcode.Declined, "fcl: signature declined")
+
+// Versions of the FCL objects produced by this package.
+const (
+	CompositeSignatureVersion = "1.0.0"
+	PollingResponseVersion    = "1.0.0"
+)
+
+// Statuses of a polling response.
+const (
+	StatusApproved = "APPROVED"
+	StatusDeclined = "DECLINED"
+	StatusPending  = "PENDING"
+)
+
+// A CompositeSignature is a signature with the account key that produced it.
+type CompositeSignature struct {
+	FType     string `json:"f_type"`
+	FVsn      string `json:"f_vsn"`
+	Addr      string `json:"addr"`
+	KeyID     int    `json:"keyId"`
+	Signature string `json:"signature"`
+}
+
+// NewCompositeSignature returns the composite signature of an account key.
+func NewCompositeSignature(address flow.Address, keyIndex int, signature []byte) CompositeSignature {
+	return CompositeSignature{
+		FType:     "CompositeSignature",
+		FVsn:      CompositeSignatureVersion,
+		Addr:      withPrefix(address),
+		KeyID:     keyIndex,
+		Signature: hex.EncodeToString(signature),
+	}
+}
+
+// A PollingResponse is the response of an authorization service.
+type PollingResponse struct {
+	FType  string              `json:"f_type"`
+	FVsn   string              `json:"f_vsn"`
+	Status string              `json:"status"`
+	Reason *string             `json:"reason"`
+	Data   *CompositeSignature `json:"data,omitempty"`
+}
+
+// Approve returns a response that approves a signable with the given signature.
+func Approve(signature CompositeSignature) PollingResponse {
+	return PollingResponse{
+		FType:  "PollingResponse",
+		FVsn:   PollingResponseVersion,
+		Status: StatusApproved,
+		Data:   &signature,
+	}
+}
+
+// Decline returns a response that declines a signable for the given reason.
+func Decline(reason string) PollingResponse {
+	return PollingResponse{
+		FType:  "PollingResponse",
+		FVsn:   PollingResponseVersion,
+		Status: StatusDeclined,
+		Reason: &reason,
+	}
+}
+
+// An Authorizer signs the signables sent to an authorization service with an account key.
+type Authorizer struct {
+	Address  flow.Address
+	KeyIndex int
+	Signer   crypto.Signer
+	// Approve decides whether a signable is signed, e.g. by checking its script against
+	// an allowlist. A signable is declined if Approve returns an error.
+	//
+	// All signables with a valid message are signed if Approve is nil.
+	Approve func(ctx context.Context, signable *Signable) error
+}
+
+// Sign verifies and signs a signable, and returns the composite signature.
+func (a Authorizer) Sign(ctx context.Context, signable *Signable) (CompositeSignature, error) {
+	if signable.Address() != a.Address || signable.KeyID != a.KeyIndex {
+		return CompositeSignature{}, ErrSignerMismatch
+	}
+
+	message, err := signable.VerifiedMessage()
+	if err != nil {
+		return CompositeSignature{}, err
+	}
+
+	if a.Approve != nil {
+		if err := a.Approve(ctx, signable); err != nil {
+			return CompositeSignature{}, err
+		}
+	}
+
+	signature, err := a.Signer.Sign(message)
+	if err != nil {
+		return CompositeSignature{}, fmt.Errorf("fcl: failed to sign message: %w", err)
+	}
+
+	return NewCompositeSignature(a.Address, a.KeyIndex, signature), nil
+}
+
+// Handler returns an HTTP handler that reads a signable from the request body and
+// responds with a polling response.
+//
+// Signables that are declined, or that cannot be signed, get a DECLINED response
+// with the error as the reason.
+func (a Authorizer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var response PollingResponse
+
+		signable, err := ParseSignable(body)
+		if err == nil {
+			var signature CompositeSignature
+			signature, err = a.Sign(r.Context(), signable)
+			response = Approve(signature)
+		}
+		if err != nil {
+			response = Decline(err.Error())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
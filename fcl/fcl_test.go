@@ -0,0 +1,225 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fcl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/fcl"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+type account struct {
+	key    *flow.AccountKey
+	signer crypto.Signer
+	fcl.Authorizer
+}
+
+func newAccount(address flow.Address, keys *test.AccountKeys) account {
+	key, signer := keys.NewWithSigner()
+	return account{
+		key:        key,
+		signer:     signer,
+		Authorizer: fcl.Authorizer{Address: address, KeyIndex: key.Index, Signer: signer},
+	}
+}
+
+// signable returns the signable of tx for the given account key, as built by fcl-js.
+func signable(t *testing.T, tx *flow.Transaction, address flow.Address, keyIndex int) *fcl.Signable {
+	payer := address == tx.Payer
+
+	message := tx.PayloadMessage()
+	if payer {
+		message = tx.EnvelopeMessage()
+	}
+
+	voucher, err := fcl.NewVoucher(*tx)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(fcl.Signable{
+		FType:   "Signable",
+		FVsn:    "1.0.1",
+		Message: hex.EncodeToString(message),
+		Addr:    address.Hex(),
+		KeyID:   keyIndex,
+		Roles:   fcl.Roles{Authorizer: !payer, Payer: payer},
+		Voucher: voucher,
+	})
+	require.NoError(t, err)
+
+	s, err := fcl.ParseSignable(data)
+	require.NoError(t, err)
+
+	return s
+}
+
+func verify(t *testing.T, a account, signature fcl.CompositeSignature, message []byte) {
+	sig, err := hex.DecodeString(signature.Signature)
+	require.NoError(t, err)
+
+	hasher, err := crypto.NewHasher(a.key.HashAlgo)
+	require.NoError(t, err)
+
+	valid, err := a.key.PublicKey.Verify(sig, message, hasher)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestAuthorizer_Sign(t *testing.T) {
+	ctx := context.Background()
+	addresses := test.AddressGenerator()
+	keys := test.AccountKeyGenerator()
+
+	authorizer := newAccount(addresses.New(), keys)
+	payer := newAccount(addresses.New(), keys)
+
+	newTx := func() *flow.Transaction {
+		tx := test.TransactionGenerator().NewUnsigned().
+			SetProposalKey(authorizer.Address, authorizer.KeyIndex, 7).
+			SetPayer(payer.Address)
+		tx.Authorizers = []flow.Address{authorizer.Address}
+
+		// fcl-js encodes arguments without a trailing newline
+		for i, arg := range tx.Arguments {
+			tx.Arguments[i] = bytes.TrimSpace(arg)
+		}
+
+		return tx
+	}
+
+	t.Run("Authorizer and payer", func(t *testing.T) {
+		tx := newTx()
+
+		signature, err := authorizer.Sign(ctx, signable(t, tx, authorizer.Address, authorizer.KeyIndex))
+		require.NoError(t, err)
+
+		assert.Equal(t, "CompositeSignature", signature.FType)
+		assert.Equal(t, "0x"+authorizer.Address.Hex(), signature.Addr)
+		assert.Equal(t, authorizer.KeyIndex, signature.KeyID)
+		verify(t, authorizer, signature, tx.PayloadMessage())
+
+		sig, err := hex.DecodeString(signature.Signature)
+		require.NoError(t, err)
+		tx.AddPayloadSignature(authorizer.Address, authorizer.KeyIndex, sig)
+
+		signature, err = payer.Sign(ctx, signable(t, tx, payer.Address, payer.KeyIndex))
+		require.NoError(t, err)
+		verify(t, payer, signature, tx.EnvelopeMessage())
+	})
+
+	t.Run("Tampered voucher", func(t *testing.T) {
+		s := signable(t, newTx(), authorizer.Address, authorizer.KeyIndex)
+		s.Voucher.Cadence = "transaction { execute { panic(\"\") } }"
+
+		_, err := authorizer.Sign(ctx, s)
+		assert.True(t, errors.Is(err, fcl.ErrMessageMismatch))
+		assert.Equal(t, errcode.MessageMismatch, errcode.Of(err))
+	})
+
+	t.Run("Tagged message", func(t *testing.T) {
+		tx := newTx()
+		s := signable(t, tx, authorizer.Address, authorizer.KeyIndex)
+		s.Message = hex.EncodeToString(append(flow.TransactionDomainTag[:], tx.PayloadMessage()...))
+
+		_, err := authorizer.Sign(ctx, s)
+		assert.True(t, errors.Is(err, fcl.ErrMessageMismatch))
+	})
+
+	t.Run("Claimed roles", func(t *testing.T) {
+		tx := newTx()
+
+		// the authorizer does not pay, whatever the signable claims
+		s := signable(t, tx, authorizer.Address, authorizer.KeyIndex)
+		s.Roles = fcl.Roles{Payer: true}
+		s.Message = hex.EncodeToString(tx.EnvelopeMessage())
+
+		_, err := authorizer.Sign(ctx, s)
+		assert.True(t, errors.Is(err, fcl.ErrMessageMismatch))
+
+		// an account without a role in the voucher is refused
+		outsider := newAccount(addresses.New(), keys)
+
+		s = signable(t, tx, outsider.Address, outsider.KeyIndex)
+		s.Message = hex.EncodeToString(tx.PayloadMessage())
+
+		_, err = outsider.Sign(ctx, s)
+		assert.True(t, errors.Is(err, fcl.ErrMessageMismatch))
+	})
+
+	t.Run("Other key", func(t *testing.T) {
+		s := signable(t, newTx(), payer.Address, payer.KeyIndex)
+
+		_, err := authorizer.Sign(ctx, s)
+		assert.True(t, errors.Is(err, fcl.ErrSignerMismatch))
+	})
+
+	t.Run("Declined", func(t *testing.T) {
+		a := authorizer
+		a.Approve = func(context.Context, *fcl.Signable) error {
+			return fcl.ErrDeclined
+		}
+
+		_, err := a.Sign(ctx, signable(t, newTx(), a.Address, a.KeyIndex))
+		assert.True(t, errors.Is(err, fcl.ErrDeclined))
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		tx := newTx()
+		server := httptest.NewServer(authorizer.Handler())
+		defer server.Close()
+
+		post := func(body interface{}) fcl.PollingResponse {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			res, err := http.Post(server.URL, "application/json", bytes.NewReader(data))
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			var response fcl.PollingResponse
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+			return response
+		}
+
+		response := post(signable(t, tx, authorizer.Address, authorizer.KeyIndex))
+		assert.Equal(t, fcl.StatusApproved, response.Status)
+		require.NotNil(t, response.Data)
+		verify(t, authorizer, *response.Data, tx.PayloadMessage())
+
+		response = post(signable(t, tx, payer.Address, payer.KeyIndex))
+		assert.Equal(t, fcl.StatusDeclined, response.Status)
+		require.NotNil(t, response.Reason)
+		assert.True(t, strings.Contains(*response.Reason, "another account key"))
+		assert.Nil(t, response.Data)
+	})
+}
@@ -0,0 +1,133 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fcl implements the server side of the Flow Client Library (FCL) wallet
+// protocol, so that Go backends can act as custodial authorization services.
+//
+// An FCL authorization service receives a signable, a JSON document describing the
+// transaction to sign, and responds with a composite signature:
+//
+//	authorizer := fcl.Authorizer{Address: address, KeyIndex: 0, Signer: signer}
+//	http.Handle("/authz", authorizer.Handler())
+//
+// The message of a signable is checked against the transaction it describes before
+// it is signed, so that a client cannot obtain a signature over a transaction other
+// than the one presented for approval.
+package fcl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrMessageMismatch is returned when the message of a signable does not encode
+// the transaction described by its voucher.
+var ErrMessageMismatch = errcode.New(errcode.MessageMismatch, "fcl: signable message does not match voucher")
+
+// A Signable is a request to sign a transaction, sent by FCL to an authorization service.
+type Signable struct {
+	FType string `json:"f_type"`
+	FVsn  string `json:"f_vsn"`
+	// Message is the hex encoded message to sign: the transaction payload, or the
+	// transaction envelope if the signer is the payer, without a domain tag.
+	Message string `json:"message"`
+	// Addr is the address of the account that must sign.
+	Addr string `json:"addr"`
+	// KeyID is the index of the account key that must sign.
+	KeyID int `json:"keyId"`
+	// Roles are the roles of the account in the transaction, as claimed by the
+	// client. They are not used to verify the message.
+	Roles Roles `json:"roles"`
+	// Voucher is the transaction to sign.
+	Voucher Voucher `json:"voucher"`
+}
+
+// Roles are the roles of an account in a transaction.
+type Roles struct {
+	Proposer   bool `json:"proposer"`
+	Authorizer bool `json:"authorizer"`
+	Payer      bool `json:"payer"`
+	Param      bool `json:"param"`
+}
+
+// ParseSignable parses the JSON encoding of a signable.
+func ParseSignable(data []byte) (*Signable, error) {
+	var signable Signable
+	if err := json.Unmarshal(data, &signable); err != nil {
+		return nil, fmt.Errorf("fcl: invalid signable: %w", err)
+	}
+
+	return &signable, nil
+}
+
+// Address returns the address of the account that must sign.
+func (s *Signable) Address() flow.Address {
+	return flow.HexToAddress(s.Addr)
+}
+
+// VerifiedMessage returns the message to sign, after checking that it encodes the
+// transaction described by the voucher.
+//
+// The roles of the signer are read from the voucher, not from Roles: the message
+// is the envelope of the transaction if the signer is its payer, and its payload if
+// the signer proposes or authorizes it. ErrMessageMismatch is returned if the signer
+// has no role in the transaction, or if the message encodes anything else, including
+// a message prefixed with flow.TransactionDomainTag.
+func (s *Signable) VerifiedMessage() ([]byte, error) {
+	message, err := hex.DecodeString(strings.TrimPrefix(s.Message, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("fcl: invalid signable message: %w", err)
+	}
+
+	tx, err := s.Voucher.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	var expected []byte
+
+	switch address := s.Address(); {
+	case tx.Payer == address:
+		expected = tx.EnvelopeMessage()
+	case tx.ProposalKey.Address == address || authorizes(tx, address):
+		expected = tx.PayloadMessage()
+	default:
+		return nil, fmt.Errorf("%w: %s has no role in the transaction", ErrMessageMismatch, address)
+	}
+
+	if !bytes.Equal(message, expected) {
+		return nil, ErrMessageMismatch
+	}
+
+	return message, nil
+}
+
+func authorizes(tx *flow.Transaction, address flow.Address) bool {
+	for _, authorizer := range tx.Authorizers {
+		if authorizer == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fcl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A Voucher is the FCL representation of a transaction.
+//
+// Vouchers produced by NewVoucher marshal to the same JSON as vouchers produced by
+// fcl-js, and a transaction converted from a voucher encodes to the same payload
+// and envelope messages as fcl-js.
+type Voucher struct {
+	Cadence      string            `json:"cadence"`
+	RefBlock     string            `json:"refBlock"`
+	ComputeLimit uint64            `json:"computeLimit"`
+	Arguments    []json.RawMessage `json:"arguments"`
+	ProposalKey  ProposalKey       `json:"proposalKey"`
+	Payer        string            `json:"payer"`
+	Authorizers  []string          `json:"authorizers"`
+	PayloadSigs  []Signature       `json:"payloadSigs"`
+	EnvelopeSigs []Signature       `json:"envelopeSigs"`
+}
+
+// A ProposalKey is the proposal key of a voucher.
+type ProposalKey struct {
+	Address     string `json:"address"`
+	KeyID       int    `json:"keyId"`
+	SequenceNum uint64 `json:"sequenceNum"`
+}
+
+// A Signature is a payload or envelope signature of a voucher. Sig is empty for
+// signatures that are not collected yet, and is encoded as null.
+type Signature struct {
+	Address string `json:"address"`
+	KeyID   int    `json:"keyId"`
+	Sig     string `json:"sig"`
+}
+
+// MarshalJSON encodes an empty signature as null, like fcl-js.
+func (s Signature) MarshalJSON() ([]byte, error) {
+	var sig *string
+	if s.Sig != "" {
+		sig = &s.Sig
+	}
+
+	return json.Marshal(struct {
+		Address string  `json:"address"`
+		KeyID   int     `json:"keyId"`
+		Sig     *string `json:"sig"`
+	}{s.Address, s.KeyID, sig})
+}
+
+// NewVoucher returns the voucher of a transaction.
+//
+// Arguments are compacted, as fcl-js encodes them with JSON.stringify.
+func NewVoucher(tx flow.Transaction) (Voucher, error) {
+	arguments := make([]json.RawMessage, len(tx.Arguments))
+	for i, arg := range tx.Arguments {
+		compact, err := compactArgument(i, arg)
+		if err != nil {
+			return Voucher{}, err
+		}
+		arguments[i] = compact
+	}
+
+	authorizers := make([]string, len(tx.Authorizers))
+	for i, authorizer := range tx.Authorizers {
+		authorizers[i] = withPrefix(authorizer)
+	}
+
+	return Voucher{
+		Cadence:      string(tx.Script),
+		RefBlock:     tx.ReferenceBlockID.Hex(),
+		ComputeLimit: tx.GasLimit,
+		Arguments:    arguments,
+		ProposalKey: ProposalKey{
+			Address:     withPrefix(tx.ProposalKey.Address),
+			KeyID:       tx.ProposalKey.KeyIndex,
+			SequenceNum: tx.ProposalKey.SequenceNumber,
+		},
+		Payer:        withPrefix(tx.Payer),
+		Authorizers:  authorizers,
+		PayloadSigs:  voucherSignatures(tx.PayloadSignatures),
+		EnvelopeSigs: voucherSignatures(tx.EnvelopeSignatures),
+	}, nil
+}
+
+// Transaction returns the transaction described by the voucher.
+//
+// Signatures that are not collected yet are omitted.
+func (v Voucher) Transaction() (*flow.Transaction, error) {
+	refBlock, err := hex.DecodeString(strings.TrimPrefix(v.RefBlock, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("fcl: invalid voucher reference block: %w", err)
+	}
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(v.Cadence)).
+		SetReferenceBlockID(flow.BytesToID(refBlock)).
+		SetGasLimit(v.ComputeLimit).
+		SetProposalKey(flow.HexToAddress(v.ProposalKey.Address), v.ProposalKey.KeyID, v.ProposalKey.SequenceNum).
+		SetPayer(flow.HexToAddress(v.Payer))
+
+	for i, arg := range v.Arguments {
+		compact, err := compactArgument(i, arg)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddRawArgument(compact)
+	}
+
+	for _, authorizer := range v.Authorizers {
+		tx.AddAuthorizer(flow.HexToAddress(authorizer))
+	}
+
+	for _, sig := range v.PayloadSigs {
+		if sig.Sig == "" {
+			continue
+		}
+
+		signature, err := hex.DecodeString(strings.TrimPrefix(sig.Sig, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("fcl: invalid voucher payload signature: %w", err)
+		}
+
+		tx.AddPayloadSignature(flow.HexToAddress(sig.Address), sig.KeyID, signature)
+	}
+
+	for _, sig := range v.EnvelopeSigs {
+		if sig.Sig == "" {
+			continue
+		}
+
+		signature, err := hex.DecodeString(strings.TrimPrefix(sig.Sig, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("fcl: invalid voucher envelope signature: %w", err)
+		}
+
+		tx.AddEnvelopeSignature(flow.HexToAddress(sig.Address), sig.KeyID, signature)
+	}
+
+	return tx, nil
+}
+
+// compactArgument returns a JSON-CDC argument without whitespace, as encoded by
+// JSON.stringify.
+func compactArgument(i int, arg []byte) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, arg); err != nil {
+		return nil, fmt.Errorf("fcl: invalid argument %d: %w", i, err)
+	}
+	return compact.Bytes(), nil
+}
+
+func voucherSignatures(signatures []flow.TransactionSignature) []Signature {
+	result := make([]Signature, len(signatures))
+	for i, sig := range signatures {
+		result[i] = Signature{
+			Address: withPrefix(sig.Address),
+			KeyID:   sig.KeyIndex,
+			Sig:     hex.EncodeToString(sig.Signature),
+		}
+	}
+	return result
+}
+
+func withPrefix(address flow.Address) string {
+	return "0x" + address.Hex()
+}
@@ -0,0 +1,109 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fcl_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/fcl"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+// fclVoucher is a voucher as built by fcl-js, with the signature of the authorizer
+// collected and the signature of the payer pending.
+const fclVoucher = `{"cadence":"transaction(greeting: String, amount: UFix64) { prepare(acct: AuthAccount) {} }",` +
+	`"refBlock":"be7c8d9fd3b6dcd0d0e1f5e5c6e1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3","computeLimit":9999,` +
+	`"arguments":[{"type":"String","value":"Hello"},{"type":"UFix64","value":"1.5"}],` +
+	`"proposalKey":{"address":"0x01cf0e2f2f715450","keyId":1,"sequenceNum":12},` +
+	`"payer":"0x179b6b1cb6755e31","authorizers":["0x01cf0e2f2f715450"],` +
+	`"payloadSigs":[{"address":"0x01cf0e2f2f715450","keyId":1,"sig":"0a0b0c"}],` +
+	`"envelopeSigs":[{"address":"0x179b6b1cb6755e31","keyId":0,"sig":null}]}`
+
+func TestVoucher_JSON(t *testing.T) {
+	var voucher fcl.Voucher
+	require.NoError(t, json.Unmarshal([]byte(fclVoucher), &voucher))
+
+	assert.Equal(t, "", voucher.EnvelopeSigs[0].Sig)
+
+	data, err := json.Marshal(voucher)
+	require.NoError(t, err)
+	assert.Equal(t, fclVoucher, string(data))
+}
+
+func TestVoucher_Transaction(t *testing.T) {
+	var voucher fcl.Voucher
+	require.NoError(t, json.Unmarshal([]byte(fclVoucher), &voucher))
+
+	tx, err := voucher.Transaction()
+	require.NoError(t, err)
+
+	authorizer := flow.HexToAddress("01cf0e2f2f715450")
+	payer := flow.HexToAddress("179b6b1cb6755e31")
+
+	assert.Equal(t, voucher.Cadence, string(tx.Script))
+	assert.Equal(t, voucher.RefBlock, tx.ReferenceBlockID.Hex())
+	assert.Equal(t, uint64(9999), tx.GasLimit)
+	assert.Equal(t, [][]byte{
+		[]byte(`{"type":"String","value":"Hello"}`),
+		[]byte(`{"type":"UFix64","value":"1.5"}`),
+	}, tx.Arguments)
+	assert.Equal(t, flow.ProposalKey{Address: authorizer, KeyIndex: 1, SequenceNumber: 12}, tx.ProposalKey)
+	assert.Equal(t, payer, tx.Payer)
+	assert.Equal(t, []flow.Address{authorizer}, tx.Authorizers)
+
+	require.Len(t, tx.PayloadSignatures, 1)
+	assert.Equal(t, authorizer, tx.PayloadSignatures[0].Address)
+	assert.Equal(t, 0, tx.PayloadSignatures[0].SignerIndex)
+	assert.Equal(t, []byte{0x0a, 0x0b, 0x0c}, tx.PayloadSignatures[0].Signature)
+	assert.Empty(t, tx.EnvelopeSignatures)
+
+	back, err := fcl.NewVoucher(*tx)
+	require.NoError(t, err)
+
+	voucher.EnvelopeSigs = []fcl.Signature{}
+	assert.Equal(t, voucher, back)
+}
+
+func TestNewVoucher(t *testing.T) {
+	tx := test.TransactionGenerator().New()
+
+	voucher, err := fcl.NewVoucher(*tx)
+	require.NoError(t, err)
+
+	for i, arg := range voucher.Arguments {
+		assert.Equal(t, bytes.TrimSpace(tx.Arguments[i]), []byte(arg))
+	}
+
+	back, err := voucher.Transaction()
+	require.NoError(t, err)
+
+	for i := range tx.Arguments {
+		tx.Arguments[i] = bytes.TrimSpace(tx.Arguments[i])
+	}
+
+	assert.Equal(t, tx.PayloadMessage(), back.PayloadMessage())
+	assert.Equal(t, tx.EnvelopeMessage(), back.EnvelopeMessage())
+	assert.Equal(t, tx.Encode(), back.Encode())
+}
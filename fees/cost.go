@@ -0,0 +1,149 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fees
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A TransactionCost is what a transaction cost its payer.
+type TransactionCost struct {
+	TransactionID flow.Identifier `json:"transactionID"`
+	// Payer is the account that paid for the transaction.
+	Payer flow.Address `json:"payer"`
+	// Fees is the transaction fee deducted from the payer.
+	Fees cadence.UFix64 `json:"fees"`
+	// InclusionEffort is the inclusion effort the fee was computed from.
+	InclusionEffort cadence.UFix64 `json:"inclusionEffort"`
+	// ExecutionEffort is the execution effort the fee was computed from.
+	ExecutionEffort cadence.UFix64 `json:"executionEffort"`
+	// Deposits is the total amount deposited to the fee vault by the transaction,
+	// which includes the transaction fee and account creation fees.
+	Deposits cadence.UFix64 `json:"deposits"`
+	// AccountsCreated is the number of accounts created by the transaction.
+	AccountsCreated int `json:"accountsCreated"`
+}
+
+// Summarize returns the cost of a transaction from the events of its result.
+func (s SystemContracts) Summarize(tx *flow.Transaction, result *flow.TransactionResult) (TransactionCost, error) {
+	cost := TransactionCost{
+		TransactionID: tx.ID(),
+		Payer:         tx.Payer,
+	}
+
+	for _, event := range result.Events {
+		if event.Type == flow.EventAccountCreated {
+			cost.AccountsCreated++
+			continue
+		}
+
+		decoded, ok, err := s.Decode(event)
+		if err != nil {
+			return TransactionCost{}, err
+		}
+		if !ok {
+			continue
+		}
+
+		switch e := decoded.(type) {
+		case FeesDeductedEvent:
+			cost.Fees = e.Amount
+			cost.InclusionEffort = e.InclusionEffort
+			cost.ExecutionEffort = e.ExecutionEffort
+		case FeesDepositedEvent:
+			if err := add(&cost.Deposits, e.Amount); err != nil {
+				return TransactionCost{}, err
+			}
+		}
+	}
+
+	return cost, nil
+}
+
+// GetTransactionCost returns the cost of a transaction.
+func GetTransactionCost(
+	ctx context.Context,
+	c flow.Client,
+	contracts SystemContracts,
+	txID flow.Identifier,
+) (TransactionCost, error) {
+	tx, err := c.GetTransaction(ctx, txID)
+	if err != nil {
+		return TransactionCost{}, err
+	}
+
+	result, err := c.GetTransactionResult(ctx, txID)
+	if err != nil {
+		return TransactionCost{}, err
+	}
+
+	return contracts.Summarize(tx, result)
+}
+
+// An AccountCost is the total cost of the transactions paid for by an account.
+type AccountCost struct {
+	Transactions    int            `json:"transactions"`
+	Fees            cadence.UFix64 `json:"fees"`
+	Deposits        cadence.UFix64 `json:"deposits"`
+	AccountsCreated int            `json:"accountsCreated"`
+}
+
+// A Report is the cost of transactions by payer.
+type Report map[flow.Address]*AccountCost
+
+// Add adds the cost of a transaction to its payer.
+func (r Report) Add(cost TransactionCost) error {
+	total, ok := r[cost.Payer]
+	if !ok {
+		total = &AccountCost{}
+	}
+
+	fees, deposits := total.Fees, total.Deposits
+
+	if err := add(&fees, cost.Fees); err != nil {
+		return err
+	}
+
+	if err := add(&deposits, cost.Deposits); err != nil {
+		return err
+	}
+
+	total.Transactions++
+	total.Fees = fees
+	total.Deposits = deposits
+	total.AccountsCreated += cost.AccountsCreated
+	r[cost.Payer] = total
+
+	return nil
+}
+
+// add adds an amount to a total, failing on overflow.
+func add(total *cadence.UFix64, amount cadence.UFix64) error {
+	sum := *total + amount
+	if sum < *total {
+		return fmt.Errorf("fees: amount overflow")
+	}
+	*total = sum
+	return nil
+}
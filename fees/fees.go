@@ -0,0 +1,233 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fees decodes the events emitted by the fee and storage system contracts,
+// and summarizes the cost of transactions for attribution to the accounts that pay
+// for them.
+package fees
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// SystemContracts are the addresses of the system contracts that emit fee and storage
+// events on a chain.
+type SystemContracts struct {
+	// FlowFees is the address of the FlowFees contract.
+	FlowFees flow.Address
+	// FlowStorageFees is the address of the FlowStorageFees contract.
+	FlowStorageFees flow.Address
+	// FlowServiceAccount is the address of the FlowServiceAccount contract.
+	FlowServiceAccount flow.Address
+}
+
+// SystemContractsFor returns the system contracts of the given chain.
+func SystemContractsFor(chain flow.ChainID) (SystemContracts, error) {
+	definition, ok := flow.LookupChain(chain)
+	if !ok {
+		return SystemContracts{}, fmt.Errorf("fees: unknown chain %s", chain)
+	}
+
+	var contracts SystemContracts
+	for _, contract := range []struct {
+		name    string
+		address *flow.Address
+	}{
+		{flow.ContractFlowFees, &contracts.FlowFees},
+		{flow.ContractFlowStorageFees, &contracts.FlowStorageFees},
+		{flow.ContractFlowServiceAccount, &contracts.FlowServiceAccount},
+	} {
+		if *contract.address, ok = definition.Contract(contract.name); !ok {
+			return SystemContracts{}, fmt.Errorf("fees: no %s contract on chain %s", contract.name, chain)
+		}
+	}
+
+	return contracts, nil
+}
+
+// FeesDeductedType returns the type of the event emitted when the fees of a
+// transaction are deducted from its payer.
+func (s SystemContracts) FeesDeductedType() string {
+	return eventType(s.FlowFees, "FlowFees", "FeesDeducted")
+}
+
+// FeesDepositedType returns the type of the event emitted when tokens are deposited
+// to the fee vault, including account creation fees.
+func (s SystemContracts) FeesDepositedType() string {
+	return eventType(s.FlowFees, "FlowFees", "TokensDeposited")
+}
+
+// StorageMegaBytesPerReservedFLOWChangedType returns the type of the event emitted
+// when the storage capacity bought by reserved FLOW changes.
+func (s SystemContracts) StorageMegaBytesPerReservedFLOWChangedType() string {
+	return eventType(s.FlowStorageFees, "FlowStorageFees", "StorageMegaBytesPerReservedFLOWChanged")
+}
+
+// MinimumStorageReservationChangedType returns the type of the event emitted when
+// the minimum storage reservation of accounts changes.
+func (s SystemContracts) MinimumStorageReservationChangedType() string {
+	return eventType(s.FlowStorageFees, "FlowStorageFees", "MinimumStorageReservationChanged")
+}
+
+// AccountCreationFeeUpdatedType returns the type of the event emitted when the
+// account creation fee changes.
+func (s SystemContracts) AccountCreationFeeUpdatedType() string {
+	return eventType(s.FlowServiceAccount, "FlowServiceAccount", "AccountCreationFeeUpdated")
+}
+
+func eventType(address flow.Address, contract, name string) string {
+	return fmt.Sprintf("A.%s.%s.%s", address.Hex(), contract, name)
+}
+
+// A FeesDeductedEvent is emitted when the fees of a transaction are deducted from
+// its payer.
+type FeesDeductedEvent struct {
+	Amount          cadence.UFix64 `json:"amount"`
+	InclusionEffort cadence.UFix64 `json:"inclusionEffort"`
+	ExecutionEffort cadence.UFix64 `json:"executionEffort"`
+}
+
+// A FeesDepositedEvent is emitted when tokens are deposited to the fee vault.
+type FeesDepositedEvent struct {
+	Amount cadence.UFix64 `json:"amount"`
+}
+
+// A StorageParameterChangedEvent is emitted when a storage fee parameter changes.
+type StorageParameterChangedEvent struct {
+	// Parameter is the name of the event, e.g. "MinimumStorageReservationChanged".
+	Parameter string         `json:"parameter"`
+	Value     cadence.UFix64 `json:"value"`
+}
+
+// An AccountCreationFeeUpdatedEvent is emitted when the account creation fee changes.
+type AccountCreationFeeUpdatedEvent struct {
+	Fee cadence.UFix64 `json:"fee"`
+}
+
+// Decode returns the typed value of a fee or storage event: a FeesDeductedEvent,
+// FeesDepositedEvent, StorageParameterChangedEvent or AccountCreationFeeUpdatedEvent.
+//
+// Decode returns false if the event is not emitted by one of the system contracts.
+func (s SystemContracts) Decode(event flow.Event) (interface{}, bool, error) {
+	var (
+		decoded interface{}
+		value   cadence.Event
+		err     error
+	)
+
+	switch event.Type {
+	case s.FeesDeductedType(), s.FeesDepositedType(), s.StorageMegaBytesPerReservedFLOWChangedType(),
+		s.MinimumStorageReservationChangedType(), s.AccountCreationFeeUpdatedType():
+		value, err = event.DecodeValue()
+		if err != nil {
+			return nil, true, fmt.Errorf("fees: invalid %s event: %w", event.Type, err)
+		}
+	default:
+		return nil, false, nil
+	}
+
+	values := fields(value)
+
+	switch event.Type {
+	case s.FeesDeductedType():
+		var e FeesDeductedEvent
+		err = firstError(
+			ufix64(values, "amount", &e.Amount),
+			ufix64(values, "inclusionEffort", &e.InclusionEffort),
+			ufix64(values, "executionEffort", &e.ExecutionEffort),
+		)
+		decoded = e
+	case s.FeesDepositedType():
+		var e FeesDepositedEvent
+		err = ufix64(values, "amount", &e.Amount)
+		decoded = e
+	case s.StorageMegaBytesPerReservedFLOWChangedType(), s.MinimumStorageReservationChangedType():
+		e := StorageParameterChangedEvent{Parameter: value.EventType.Identifier}
+		err = firstValue(values, &e.Value)
+		decoded = e
+	case s.AccountCreationFeeUpdatedType():
+		var e AccountCreationFeeUpdatedEvent
+		err = firstValue(values, &e.Fee)
+		decoded = e
+	default:
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, true, fmt.Errorf("fees: invalid %s event: %w", event.Type, err)
+	}
+
+	return decoded, true, nil
+}
+
+// fields returns the fields of an event by name, in declaration order.
+func fields(event cadence.Event) []namedValue {
+	if event.EventType == nil {
+		return nil
+	}
+
+	values := make([]namedValue, 0, len(event.Fields))
+	for i, field := range event.EventType.Fields {
+		if i < len(event.Fields) {
+			values = append(values, namedValue{name: field.Identifier, value: event.Fields[i]})
+		}
+	}
+
+	return values
+}
+
+type namedValue struct {
+	name  string
+	value cadence.Value
+}
+
+func ufix64(values []namedValue, name string, target *cadence.UFix64) error {
+	for _, v := range values {
+		if v.name == name {
+			u, ok := v.value.(cadence.UFix64)
+			if !ok {
+				return fmt.Errorf("field %s is not a UFix64", name)
+			}
+			*target = u
+			return nil
+		}
+	}
+	return fmt.Errorf("missing field %s", name)
+}
+
+// firstValue decodes the single UFix64 field of a parameter event, whose name varies
+// between contract versions.
+func firstValue(values []namedValue, target *cadence.UFix64) error {
+	if len(values) == 0 {
+		return fmt.Errorf("missing value")
+	}
+	return ufix64(values, values[0].name, target)
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
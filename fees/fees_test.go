@@ -0,0 +1,124 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fees_test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/fees"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func ufix(s string) cadence.UFix64 {
+	v, err := cadence.NewUFix64(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func newEvent(typeID string, names []string, values ...cadence.Value) flow.Event {
+	eventType := &cadence.EventType{TypeID: typeID, Identifier: typeID}
+	for _, name := range names {
+		eventType.Fields = append(eventType.Fields, cadence.Field{Identifier: name, Type: cadence.UFix64Type{}})
+	}
+
+	return flow.Event{
+		Type:  typeID,
+		Value: cadence.NewEvent(values).WithType(eventType),
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	contracts, err := fees.SystemContractsFor(flow.Emulator)
+	require.NoError(t, err)
+
+	tx := test.TransactionGenerator().New()
+
+	result := &flow.TransactionResult{
+		Status: flow.TransactionStatusSealed,
+		Events: []flow.Event{
+			{Type: flow.EventAccountCreated},
+			newEvent(contracts.FeesDepositedType(), []string{"amount"}, ufix("0.001")),
+			newEvent(contracts.FeesDepositedType(), []string{"amount"}, ufix("0.0001")),
+			newEvent(
+				contracts.FeesDeductedType(),
+				[]string{"amount", "inclusionEffort", "executionEffort"},
+				ufix("0.0001"), ufix("1.0"), ufix("0.5"),
+			),
+			newEvent("A.0000000000000001.Other.Event", []string{"amount"}, ufix("5.0")),
+		},
+	}
+
+	cost, err := contracts.Summarize(tx, result)
+	require.NoError(t, err)
+
+	assert.Equal(t, fees.TransactionCost{
+		TransactionID:   tx.ID(),
+		Payer:           tx.Payer,
+		Fees:            ufix("0.0001"),
+		InclusionEffort: ufix("1.0"),
+		ExecutionEffort: ufix("0.5"),
+		Deposits:        ufix("0.0011"),
+		AccountsCreated: 1,
+	}, cost)
+
+	report := fees.Report{}
+	require.NoError(t, report.Add(cost))
+	require.NoError(t, report.Add(cost))
+	assert.Equal(t, &fees.AccountCost{
+		Transactions:    2,
+		Fees:            ufix("0.0002"),
+		Deposits:        ufix("0.0022"),
+		AccountsCreated: 2,
+	}, report[tx.Payer])
+
+	t.Run("Invalid event", func(t *testing.T) {
+		result := &flow.TransactionResult{
+			Events: []flow.Event{newEvent(contracts.FeesDeductedType(), []string{"amount"}, ufix("1.0"))},
+		}
+		_, err := contracts.Summarize(tx, result)
+		assert.EqualError(t, err, "fees: invalid "+contracts.FeesDeductedType()+" event: missing field inclusionEffort")
+	})
+}
+
+func TestDecode(t *testing.T) {
+	contracts, err := fees.SystemContractsFor(flow.Mainnet)
+	require.NoError(t, err)
+
+	event := newEvent(contracts.MinimumStorageReservationChangedType(), []string{"newValue"}, ufix("0.001"))
+	event.Value.EventType.Identifier = "MinimumStorageReservationChanged"
+
+	decoded, ok, err := contracts.Decode(event)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, fees.StorageParameterChangedEvent{
+		Parameter: "MinimumStorageReservationChanged",
+		Value:     ufix("0.001"),
+	}, decoded)
+
+	_, ok, err = contracts.Decode(flow.Event{Type: flow.EventAccountCreated})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
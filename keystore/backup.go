@@ -0,0 +1,174 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// BackupFormat identifies key backups.
+const BackupFormat = "flow-key-backup"
+
+// BackupVersion is the version of the key backup format written by this package.
+const BackupVersion = 1
+
+// ErrBackupCorrupted is returned when a key backup was modified or truncated.
+var ErrBackupCorrupted = errcode.New(errcode.BackupCorrupted, "keystore: backup is corrupted")
+
+// A Backup is an encrypted copy of the keys of a file, for recovery.
+//
+// The encrypted content is the keys.json encoding of the file, with all private keys
+// in plaintext, so that the address of each account and the index and algorithms of
+// each key are recovered with the keys. A checksum lets corruption be told apart
+// from a wrong passphrase.
+type Backup struct {
+	Format    string       `json:"format"`
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Encrypted EncryptedKey `json:"encrypted"`
+	// Checksum is the hex encoded SHA-256 hash of the header and encrypted content.
+	Checksum string `json:"checksum"`
+}
+
+// ExportBackup returns a backup of all keys of this file, encrypted with the given
+// passphrase.
+//
+// ErrEncrypted is returned if a key has not been decrypted.
+func (f *File) ExportBackup(passphrase string) ([]byte, error) {
+	plain := New()
+
+	for name, account := range f.Accounts {
+		keys := make([]*Key, len(account.Keys))
+		for i, key := range account.Keys {
+			if key.PrivateKey == nil {
+				return nil, ErrEncrypted
+			}
+
+			plainKey := *key
+			plainKey.Encrypted = nil
+			keys[i] = &plainKey
+		}
+
+		plain.Accounts[name] = &Account{
+			Address: account.Address,
+			Keys:    keys,
+		}
+	}
+
+	plaintext, err := plain.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	backup := Backup{
+		Format:    BackupFormat,
+		Version:   BackupVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	encrypted, err := seal(passphrase, plaintext, backup.additionalData())
+	if err != nil {
+		return nil, err
+	}
+
+	backup.Encrypted = *encrypted
+
+	backup.Checksum, err = backup.checksum()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("keystore: failed to encode backup: %w", err)
+	}
+
+	return append(b, '\n'), nil
+}
+
+// ImportBackup decrypts a backup returned by ExportBackup, and returns the file it
+// contains, with all private keys decrypted.
+//
+// ErrBackupCorrupted is returned if the backup was modified, and ErrWrongPassphrase
+// if the passphrase is incorrect.
+func ImportBackup(data []byte, passphrase string) (*File, error) {
+	var backup Backup
+	if err := json.Unmarshal(data, &backup); err != nil {
+		return nil, fmt.Errorf("keystore: failed to decode backup: %w", err)
+	}
+
+	if backup.Format != BackupFormat {
+		return nil, fmt.Errorf("keystore: unknown backup format %q", backup.Format)
+	}
+
+	if backup.Version > BackupVersion {
+		return nil, fmt.Errorf("keystore: unsupported backup version %d", backup.Version)
+	}
+
+	checksum, err := backup.checksum()
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != backup.Checksum {
+		return nil, ErrBackupCorrupted
+	}
+
+	plaintext, err := backup.Encrypted.open(passphrase, backup.additionalData())
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := Decode(bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range file.Accounts {
+		for _, key := range account.Keys {
+			if key.PrivateKey == nil {
+				return nil, fmt.Errorf("keystore: backup key %q has no private key", key.Label)
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// additionalData binds the ciphertext to the header of the backup.
+func (b *Backup) additionalData() []byte {
+	return []byte(fmt.Sprintf("%s/%d/%d", b.Format, b.Version, b.CreatedAt.Unix()))
+}
+
+func (b *Backup) checksum() (string, error) {
+	encrypted, err := json.Marshal(b.Encrypted)
+	if err != nil {
+		return "", fmt.Errorf("keystore: failed to encode backup: %w", err)
+	}
+
+	sum := sha256.Sum256(append(b.additionalData(), encrypted...))
+	return hex.EncodeToString(sum[:]), nil
+}
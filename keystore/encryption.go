@@ -0,0 +1,252 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrWrongPassphrase is returned when a key cannot be decrypted with the given passphrase.
+var ErrWrongPassphrase = errcode.New(errcode.WrongPassphrase, "keystore: wrong passphrase")
+
+// An EncryptedKey is a private key encrypted with AES-256-GCM under a key derived
+// from a passphrase with scrypt.
+type EncryptedKey struct {
+	// Version is the version of the encryption format. Keys encrypted before
+	// versioning have version 0, and are not bound to the address of their account.
+	Version    int    `json:"version,omitempty"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+const (
+	kdfScrypt = "scrypt"
+
+	// scrypt parameters recommended for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	// upper bounds of the scrypt parameters of decrypted keys, so that a crafted
+	// file cannot make key derivation use unbounded memory or time
+	maxScryptN      = 1 << 20
+	maxScryptR      = 32
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30
+
+	saltLength = 16
+	keyLength  = 32
+
+	// encryptionVersion is the version of the encryption format written by Encrypt.
+	encryptionVersion = 2
+)
+
+// Encrypt encrypts the private key of the given account with the given passphrase.
+//
+// The decrypted private key remains available until the file is written.
+func (k *Key) Encrypt(address flow.Address, passphrase string) error {
+	if k.PrivateKey == nil {
+		return ErrEncrypted
+	}
+
+	encrypted, err := seal(passphrase, k.PrivateKey.Encode(), k.additionalData(address, encryptionVersion))
+	if err != nil {
+		return err
+	}
+
+	encrypted.Version = encryptionVersion
+	k.Encrypted = encrypted
+
+	return nil
+}
+
+// Decrypt decrypts the private key of the given account with the given passphrase.
+//
+// ErrWrongPassphrase is returned if the passphrase is incorrect, or the key or its
+// account address were modified. Keys encrypted in the format of version 0 are
+// not bound to their address, and should be encrypted again once decrypted.
+func (k *Key) Decrypt(address flow.Address, passphrase string) error {
+	if k.Encrypted == nil {
+		return nil
+	}
+
+	if k.Encrypted.Version != 0 && k.Encrypted.Version != encryptionVersion {
+		return fmt.Errorf("keystore: unsupported encryption version %d", k.Encrypted.Version)
+	}
+
+	plaintext, err := k.Encrypted.open(passphrase, k.additionalData(address, k.Encrypted.Version))
+	if err != nil {
+		return err
+	}
+
+	privateKey, err := crypto.DecodePrivateKey(k.SigAlgo, plaintext)
+	if err != nil {
+		return fmt.Errorf("keystore: invalid private key: %w", err)
+	}
+
+	k.PrivateKey = &privateKey
+
+	return nil
+}
+
+// seal encrypts plaintext under a key derived from the passphrase with a new salt.
+func seal(passphrase string, plaintext, additionalData []byte) (*EncryptedKey, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	encrypted := &EncryptedKey{
+		KDF:  kdfScrypt,
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+		Salt: hex.EncodeToString(salt),
+	}
+
+	aead, err := encrypted.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	encrypted.Nonce = hex.EncodeToString(nonce)
+	encrypted.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, plaintext, additionalData))
+
+	return encrypted, nil
+}
+
+// open decrypts the ciphertext with the given passphrase.
+//
+// ErrWrongPassphrase is returned if the passphrase is incorrect or the ciphertext or
+// additional data were modified.
+func (e *EncryptedKey) open(passphrase string, additionalData []byte) ([]byte, error) {
+	aead, err := e.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hex.DecodeString(e.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid nonce: %w", err)
+	}
+
+	ciphertext, err := hex.DecodeString(e.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid ciphertext: %w", err)
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("keystore: invalid nonce length %d", len(nonce))
+	}
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return plaintext, nil
+}
+
+// additionalData binds the ciphertext to the key metadata and account address, so
+// that the algorithms or index of an encrypted key cannot be changed, nor the key
+// moved to another account, without detection. The address is not bound by the
+// format of version 0.
+func (k *Key) additionalData(address flow.Address, version int) []byte {
+	data := fmt.Sprintf("%s/%d/%s/%s", k.Label, k.Index, k.SigAlgo, k.HashAlgo)
+	if version == 0 {
+		return []byte(data)
+	}
+
+	return []byte(fmt.Sprintf("%d/%s/%s", version, address.Hex(), data))
+}
+
+func (e *EncryptedKey) aead(passphrase string) (cipher.AEAD, error) {
+	if e.KDF != kdfScrypt {
+		return nil, fmt.Errorf("keystore: unsupported key derivation function %q", e.KDF)
+	}
+
+	if err := e.validateScrypt(); err != nil {
+		return nil, err
+	}
+
+	salt, err := hex.DecodeString(e.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: invalid salt: %w", err)
+	}
+
+	derived, err := scrypt.Key([]byte(passphrase), salt, e.N, e.R, e.P, keyLength)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// validateScrypt returns an error if the scrypt parameters are out of bounds: N
+// must be a power of two up to maxScryptN, R and P must be positive and at most
+// maxScryptR and maxScryptP, and key derivation must use at most maxScryptMemory.
+func (e *EncryptedKey) validateScrypt() error {
+	if e.N < 2 || e.N > maxScryptN || e.N&(e.N-1) != 0 {
+		return fmt.Errorf("keystore: invalid scrypt parameter N=%d", e.N)
+	}
+	if e.R < 1 || e.R > maxScryptR {
+		return fmt.Errorf("keystore: invalid scrypt parameter r=%d", e.R)
+	}
+	if e.P < 1 || e.P > maxScryptP {
+		return fmt.Errorf("keystore: invalid scrypt parameter p=%d", e.P)
+	}
+	if 128*int64(e.N)*int64(e.R) > maxScryptMemory {
+		return fmt.Errorf("keystore: scrypt parameters N=%d r=%d use too much memory", e.N, e.R)
+	}
+	return nil
+}
+
+func encodeHex(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func trimHexPrefix(s string) string {
+	return strings.TrimPrefix(s, "0x")
+}
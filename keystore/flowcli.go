@@ -0,0 +1,341 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// FlowCLILabel is the label of keys imported from a flow.json file, which holds a
+// single key per account.
+const FlowCLILabel = "default"
+
+// flowAccount is an account in a flow.json file.
+//
+// The Flow CLI accepts a key either as a single string, as a key object, or in the
+// legacy format with the key fields inlined in the account.
+type flowAccount struct {
+	Address string          `json:"address"`
+	Key     json.RawMessage `json:"key,omitempty"`
+
+	// legacy format
+	PrivateKey string `json:"privateKey,omitempty"`
+	SigAlgo    string `json:"sigAlgorithm,omitempty"`
+	HashAlgo   string `json:"hashAlgorithm,omitempty"`
+	KeyIndex   int    `json:"keyIndex,omitempty"`
+}
+
+type flowKey struct {
+	Type       string `json:"type"`
+	Index      int    `json:"index"`
+	SigAlgo    string `json:"signatureAlgorithm"`
+	HashAlgo   string `json:"hashAlgorithm"`
+	PrivateKey string `json:"privateKey,omitempty"`
+	Location   string `json:"location,omitempty"`
+}
+
+const (
+	flowKeyTypeHex  = "hex"
+	flowKeyTypeFile = "file"
+)
+
+// ImportFlowJSON reads the accounts of a flow.json file produced by the Flow CLI.
+//
+// Private keys may be given inline as hex, as references to environment variables
+// ("$NAME" or "${NAME}"), or as "file" keys that point to a key file. Relative key
+// file locations are resolved from the directory of the flow.json file.
+//
+// Each account is imported with a single key labeled FlowCLILabel.
+func ImportFlowJSON(path string) (*File, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Accounts map[string]flowAccount `json:"accounts"`
+	}
+
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("keystore: failed to decode flow.json: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	file := New()
+
+	for name, account := range config.Accounts {
+		key, err := account.key(dir)
+		if err != nil {
+			return nil, fmt.Errorf("keystore: account %s: %w", name, err)
+		}
+
+		err = file.AddKey(name, flow.HexToAddress(account.Address), key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+func (a flowAccount) key(dir string) (*Key, error) {
+	k := flowKey{
+		Type:       flowKeyTypeHex,
+		Index:      a.KeyIndex,
+		SigAlgo:    a.SigAlgo,
+		HashAlgo:   a.HashAlgo,
+		PrivateKey: a.PrivateKey,
+	}
+
+	if len(a.Key) > 0 {
+		var s string
+		if err := json.Unmarshal(a.Key, &s); err == nil {
+			k = flowKey{Type: flowKeyTypeHex, PrivateKey: s}
+		} else if err := json.Unmarshal(a.Key, &k); err != nil {
+			return nil, fmt.Errorf("invalid key: %w", err)
+		}
+	}
+
+	sigAlgo := crypto.ECDSA_P256
+	if k.SigAlgo != "" {
+		sigAlgo = crypto.StringToSignatureAlgorithm(k.SigAlgo)
+		if sigAlgo == crypto.UnknownSignatureAlgorithm {
+			return nil, fmt.Errorf("unknown signature algorithm %q", k.SigAlgo)
+		}
+	}
+
+	hashAlgo := crypto.SHA3_256
+	if k.HashAlgo != "" {
+		hashAlgo = crypto.StringToHashAlgorithm(k.HashAlgo)
+		if hashAlgo == crypto.UnknownHashAlgorithm {
+			return nil, fmt.Errorf("unknown hash algorithm %q", k.HashAlgo)
+		}
+	}
+
+	var privateKey crypto.PrivateKey
+	var err error
+
+	switch k.Type {
+	case flowKeyTypeHex, "":
+		var s string
+		s, err = resolveEnv(k.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err = crypto.DecodePrivateKeyHex(sigAlgo, trimHexPrefix(s))
+	case flowKeyTypeFile:
+		location := k.Location
+		if !filepath.IsAbs(location) {
+			location = filepath.Join(dir, location)
+		}
+		privateKey, err = ReadKeyFile(location, sigAlgo)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		Label:      FlowCLILabel,
+		Index:      k.Index,
+		SigAlgo:    sigAlgo,
+		HashAlgo:   hashAlgo,
+		PrivateKey: &privateKey,
+	}, nil
+}
+
+// resolveEnv returns the value of the referenced environment variable if s is a
+// reference of the form "$NAME" or "${NAME}", and s otherwise.
+func resolveEnv(s string) (string, error) {
+	if !strings.HasPrefix(s, "$") {
+		return s, nil
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(s, "$"), "{"), "}")
+
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return value, nil
+}
+
+// ExportFlowJSON writes the accounts of this file to a flow.json file in the format
+// of the Flow CLI.
+//
+// If the flow.json file exists, the accounts of this file are merged into its
+// accounts section, and all other accounts and sections are preserved. An existing
+// account whose key resolves to the exported key is left as is, so that
+// environment variable and key file references are not replaced by inline keys; so
+// is an account whose key reference cannot be resolved, e.g. because its
+// environment variable is not set.
+//
+// Other accounts are written with their key labeled FlowCLILabel, or their first
+// key if there is none, as inline hex. Encrypted keys must be decrypted before they
+// are exported.
+func (f *File) ExportFlowJSON(path string) error {
+	config := make(map[string]json.RawMessage)
+
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(b, &config); err != nil {
+			return fmt.Errorf("keystore: failed to decode flow.json: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	accounts := make(map[string]json.RawMessage, len(f.Accounts))
+
+	if section, ok := config["accounts"]; ok {
+		if err := json.Unmarshal(section, &accounts); err != nil {
+			return fmt.Errorf("keystore: failed to decode flow.json accounts: %w", err)
+		}
+	}
+
+	dir := filepath.Dir(path)
+
+	for name, account := range f.Accounts {
+		if len(account.Keys) == 0 {
+			continue
+		}
+
+		key := account.Key(FlowCLILabel)
+		if key == nil {
+			key = account.Keys[0]
+		}
+
+		if key.PrivateKey == nil {
+			return fmt.Errorf("keystore: account %s: %w", name, ErrEncrypted)
+		}
+
+		if existing, ok := accounts[name]; ok && keeps(existing, dir, account.Address, key) {
+			continue
+		}
+
+		k, err := json.Marshal(flowKey{
+			Type:       flowKeyTypeHex,
+			Index:      key.Index,
+			SigAlgo:    key.SigAlgo.String(),
+			HashAlgo:   key.HashAlgo.String(),
+			PrivateKey: encodeHex(key.PrivateKey.Encode()),
+		})
+		if err != nil {
+			return err
+		}
+
+		accounts[name], err = json.Marshal(flowAccount{
+			Address: account.Address.Hex(),
+			Key:     k,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	config["accounts"], err = json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+
+	b, err = json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// keeps returns true if an account of a flow.json file is left as is when the key
+// of the address is exported: if it resolves to the same key, or if its key is a
+// reference that cannot be resolved.
+func keeps(existing json.RawMessage, dir string, address flow.Address, key *Key) bool {
+	var account flowAccount
+	if err := json.Unmarshal(existing, &account); err != nil {
+		return false
+	}
+
+	if flow.HexToAddress(account.Address) != address {
+		return false
+	}
+
+	resolved, err := account.key(dir)
+	if err != nil {
+		return account.reference()
+	}
+
+	return resolved.Index == key.Index &&
+		resolved.SigAlgo == key.SigAlgo &&
+		resolved.HashAlgo == key.HashAlgo &&
+		bytes.Equal(resolved.PrivateKey.Encode(), key.PrivateKey.Encode())
+}
+
+// reference returns true if the key of the account is read from an environment
+// variable or a key file.
+func (a flowAccount) reference() bool {
+	if strings.HasPrefix(a.PrivateKey, "$") {
+		return true
+	}
+
+	if len(a.Key) == 0 {
+		return false
+	}
+
+	var s string
+	if err := json.Unmarshal(a.Key, &s); err == nil {
+		return strings.HasPrefix(s, "$")
+	}
+
+	var k flowKey
+	if err := json.Unmarshal(a.Key, &k); err != nil {
+		return false
+	}
+
+	return k.Type == flowKeyTypeFile || strings.HasPrefix(k.PrivateKey, "$")
+}
+
+// ReadKeyFile reads a hex-encoded private key from a key file, as written by the
+// Flow CLI. Surrounding whitespace and a "0x" prefix are ignored.
+func ReadKeyFile(path string, sigAlgo crypto.SignatureAlgorithm) (crypto.PrivateKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return crypto.PrivateKey{}, err
+	}
+
+	return crypto.DecodePrivateKeyHex(sigAlgo, trimHexPrefix(strings.TrimSpace(string(b))))
+}
+
+// WriteKeyFile writes a hex-encoded private key to a key file readable only by the
+// current user.
+func WriteKeyFile(path string, privateKey crypto.PrivateKey) error {
+	return ioutil.WriteFile(path, []byte(encodeHex(privateKey.Encode())), 0600)
+}
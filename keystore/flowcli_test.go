@@ -0,0 +1,162 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package keystore_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/keystore"
+)
+
+func TestFlowJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flowjson")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	legacyKey := newPrivateKey(t, 1)
+	envKey := newPrivateKey(t, 2)
+	fileKey := newPrivateKey(t, 3)
+
+	require.NoError(t, keystore.WriteKeyFile(filepath.Join(dir, "deployer.pkey"), fileKey))
+
+	os.Setenv("KEYSTORE_TEST_KEY", hex.EncodeToString(envKey.Encode()))
+	defer os.Unsetenv("KEYSTORE_TEST_KEY")
+
+	path := filepath.Join(dir, "flow.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{
+		"contracts": {"Token": "./Token.cdc"},
+		"accounts": {
+			"service": {
+				"address": "f8d6e0586b0a20c7",
+				"privateKey": "`+hex.EncodeToString(legacyKey.Encode())+`",
+				"sigAlgorithm": "ECDSA_P256",
+				"hashAlgorithm": "SHA3_256"
+			},
+			"ci": {
+				"address": "0x01cf0e2f2f715450",
+				"key": "$KEYSTORE_TEST_KEY"
+			},
+			"deployer": {
+				"address": "179b6b1cb6755e31",
+				"key": {
+					"type": "file",
+					"index": 2,
+					"signatureAlgorithm": "ECDSA_P256",
+					"hashAlgorithm": "SHA2_256",
+					"location": "deployer.pkey"
+				}
+			}
+		}
+	}`), 0600))
+
+	file, err := keystore.ImportFlowJSON(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ci", "deployer", "service"}, file.AccountNames())
+
+	check := func(t *testing.T, file *keystore.File, name string, address string, index int, hashAlgo crypto.HashAlgorithm, privateKey crypto.PrivateKey) {
+		account, key, err := file.Key(name, keystore.FlowCLILabel)
+		require.NoError(t, err)
+
+		assert.Equal(t, flow.HexToAddress(address), account.Address)
+		assert.Equal(t, index, key.Index)
+		assert.Equal(t, hashAlgo, key.HashAlgo)
+		assert.Equal(t, privateKey.Encode(), key.PrivateKey.Encode())
+	}
+
+	check(t, file, "service", "f8d6e0586b0a20c7", 0, crypto.SHA3_256, legacyKey)
+	check(t, file, "ci", "01cf0e2f2f715450", 0, crypto.SHA3_256, envKey)
+	check(t, file, "deployer", "179b6b1cb6755e31", 2, crypto.SHA2_256, fileKey)
+
+	t.Run("Export preserves other sections", func(t *testing.T) {
+		require.NoError(t, file.ExportFlowJSON(path))
+
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		var config map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(b, &config))
+		assert.JSONEq(t, `{"Token": "./Token.cdc"}`, string(config["contracts"]))
+
+		exported, err := keystore.ImportFlowJSON(path)
+		require.NoError(t, err)
+
+		check(t, exported, "deployer", "179b6b1cb6755e31", 2, crypto.SHA2_256, fileKey)
+		check(t, exported, "ci", "01cf0e2f2f715450", 0, crypto.SHA3_256, envKey)
+
+		// references are not replaced by inline keys
+		var accounts map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(config["accounts"], &accounts))
+		assert.Equal(t, "$KEYSTORE_TEST_KEY", accounts["ci"]["key"])
+		assert.Equal(t, "deployer.pkey", accounts["deployer"]["key"].(map[string]interface{})["location"])
+	})
+
+	t.Run("Export merges accounts", func(t *testing.T) {
+		path := filepath.Join(dir, "merge.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{
+			"accounts": {
+				"ci": {"address": "01cf0e2f2f715450", "key": "$KEYSTORE_TEST_KEY"},
+				"staging": {"address": "02", "key": "${KEYSTORE_TEST_MISSING}"},
+				"emulator": {"address": "f8d6e0586b0a20c7", "key": "$KEYSTORE_TEST_KEY"}
+			}
+		}`), 0600))
+
+		rotated := newPrivateKey(t, 4)
+
+		file := keystore.New()
+		require.NoError(t, file.AddKey("ci", flow.HexToAddress("01cf0e2f2f715450"), keystore.NewKey(keystore.FlowCLILabel, 0, rotated, crypto.SHA3_256)))
+		require.NoError(t, file.AddKey("staging", flow.HexToAddress("02"), keystore.NewKey(keystore.FlowCLILabel, 0, rotated, crypto.SHA3_256)))
+		require.NoError(t, file.ExportFlowJSON(path))
+
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		var config struct {
+			Accounts map[string]map[string]interface{} `json:"accounts"`
+		}
+		require.NoError(t, json.Unmarshal(b, &config))
+
+		// the rotated key replaces the reference, and the unresolved reference is kept
+		assert.Equal(t, hex.EncodeToString(rotated.Encode()), config.Accounts["ci"]["key"].(map[string]interface{})["privateKey"])
+		assert.Equal(t, "${KEYSTORE_TEST_MISSING}", config.Accounts["staging"]["key"])
+
+		// accounts of the flow.json file that are not exported are kept
+		assert.Equal(t, "$KEYSTORE_TEST_KEY", config.Accounts["emulator"]["key"])
+	})
+
+	t.Run("Missing environment variable", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{
+			"accounts": {"ci": {"address": "01", "key": "${KEYSTORE_TEST_MISSING}"}}
+		}`), 0600))
+
+		_, err := keystore.ImportFlowJSON(path)
+		assert.EqualError(t, err, "keystore: account ci: environment variable KEYSTORE_TEST_MISSING is not set")
+	})
+}
@@ -0,0 +1,326 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package keystore reads and writes keys.json files, which store the private keys
+// of one or more accounts.
+//
+// A keys.json file maps account names to an address and a list of labeled keys:
+//
+//	{
+//	  "version": 1,
+//	  "accounts": {
+//	    "treasury": {
+//	      "address": "f8d6e0586b0a20c7",
+//	      "keys": [
+//	        {
+//	          "label": "hot",
+//	          "index": 0,
+//	          "sigAlgorithm": "ECDSA_P256",
+//	          "hashAlgorithm": "SHA3_256",
+//	          "privateKey": "c47d..."
+//	        }
+//	      ]
+//	    }
+//	  }
+//	}
+//
+// Private keys can be encrypted with a passphrase, in which case the key holds an
+// "encrypted" object instead of a "privateKey" string. Encrypted keys are bound to
+// the address of their account, and cannot be moved to another account.
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// Version is the version of the keys.json format written by this package.
+const Version = 1
+
+// ErrEncrypted is returned when a private key is used before it is decrypted.
+var ErrEncrypted = errcode.New(errcode.KeyEncrypted, "keystore: private key is encrypted")
+
+// A File is the content of a keys.json file.
+type File struct {
+	Version  int                 `json:"version"`
+	Accounts map[string]*Account `json:"accounts"`
+}
+
+// An Account is an account address with its keys.
+type Account struct {
+	Address flow.Address `json:"address"`
+	Keys    []*Key       `json:"keys"`
+}
+
+// A Key is a labeled account key with its private key.
+type Key struct {
+	// Label identifies the key within its account.
+	Label string
+	// Index is the index of the key on the account.
+	Index    int
+	SigAlgo  crypto.SignatureAlgorithm
+	HashAlgo crypto.HashAlgorithm
+	// PrivateKey is the decrypted private key. It is nil if the key is encrypted.
+	PrivateKey *crypto.PrivateKey
+	// Encrypted is the encrypted private key. It is nil if the key is stored in plaintext.
+	Encrypted *EncryptedKey
+}
+
+// New returns an empty file.
+func New() *File {
+	return &File{
+		Version:  Version,
+		Accounts: make(map[string]*Account),
+	}
+}
+
+// Load reads a keys.json file.
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Decode(f)
+}
+
+// Decode reads a keys.json file from r.
+func Decode(r io.Reader) (*File, error) {
+	var file File
+
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("keystore: failed to decode keys file: %w", err)
+	}
+
+	if file.Version > Version {
+		return nil, fmt.Errorf("keystore: unsupported keys file version %d", file.Version)
+	}
+
+	if file.Accounts == nil {
+		file.Accounts = make(map[string]*Account)
+	}
+
+	return &file, nil
+}
+
+// Save writes this file to the given path, readable only by the current user.
+func (f *File) Save(path string) error {
+	b, err := f.marshal()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, b, 0600)
+}
+
+// Encode writes this file to w.
+func (f *File) Encode(w io.Writer) error {
+	b, err := f.marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func (f *File) marshal() ([]byte, error) {
+	f.Version = Version
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("keystore: failed to encode keys file: %w", err)
+	}
+
+	return append(b, '\n'), nil
+}
+
+// AccountNames returns the names of all accounts in sorted order.
+func (f *File) AccountNames() []string {
+	names := make([]string, 0, len(f.Accounts))
+	for name := range f.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddKey adds a key to the named account, creating the account if needed.
+//
+// An error is returned if the account exists with a different address or already
+// has a key with the same label.
+func (f *File) AddKey(name string, address flow.Address, key *Key) error {
+	account, ok := f.Accounts[name]
+	if !ok {
+		account = &Account{Address: address}
+		f.Accounts[name] = account
+	}
+
+	if account.Address != address {
+		return fmt.Errorf("keystore: account %s has address %s, not %s", name, account.Address, address)
+	}
+
+	if account.Key(key.Label) != nil {
+		return fmt.Errorf("keystore: account %s already has a key labeled %q", name, key.Label)
+	}
+
+	account.Keys = append(account.Keys, key)
+
+	return nil
+}
+
+// Key returns the key with the given label in the named account.
+func (f *File) Key(name, label string) (*Account, *Key, error) {
+	account, ok := f.Accounts[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("keystore: account %s not found", name)
+	}
+
+	key := account.Key(label)
+	if key == nil {
+		return nil, nil, fmt.Errorf("keystore: account %s has no key labeled %q", name, label)
+	}
+
+	return account, key, nil
+}
+
+// Key returns the key with the given label, or nil if there is none.
+func (a *Account) Key(label string) *Key {
+	for _, key := range a.Keys {
+		if key.Label == label {
+			return key
+		}
+	}
+	return nil
+}
+
+// NewKey returns a plaintext key for the given private key.
+func NewKey(label string, index int, privateKey crypto.PrivateKey, hashAlgo crypto.HashAlgorithm) *Key {
+	return &Key{
+		Label:      label,
+		Index:      index,
+		SigAlgo:    privateKey.Algorithm(),
+		HashAlgo:   hashAlgo,
+		PrivateKey: &privateKey,
+	}
+}
+
+// Signer returns a signer for the private key.
+//
+// ErrEncrypted is returned if the key has not been decrypted.
+func (k *Key) Signer() (crypto.Signer, error) {
+	if k.PrivateKey == nil {
+		return nil, ErrEncrypted
+	}
+
+	return crypto.NewInMemorySigner(*k.PrivateKey, k.HashAlgo), nil
+}
+
+// AccountKey returns the public account key of this key.
+//
+// ErrEncrypted is returned if the key has not been decrypted.
+func (k *Key) AccountKey() (*flow.AccountKey, error) {
+	if k.PrivateKey == nil {
+		return nil, ErrEncrypted
+	}
+
+	key := flow.NewAccountKey().
+		FromPrivateKey(*k.PrivateKey).
+		SetHashAlgo(k.HashAlgo).
+		SetWeight(flow.AccountKeyWeightThreshold)
+	key.Index = k.Index
+
+	return key, nil
+}
+
+type keyJSON struct {
+	Label      string        `json:"label"`
+	Index      int           `json:"index"`
+	SigAlgo    string        `json:"sigAlgorithm"`
+	HashAlgo   string        `json:"hashAlgorithm"`
+	PrivateKey string        `json:"privateKey,omitempty"`
+	Encrypted  *EncryptedKey `json:"encrypted,omitempty"`
+}
+
+// MarshalJSON encodes the key. Decrypted keys that have an encrypted form are
+// written encrypted only.
+func (k Key) MarshalJSON() ([]byte, error) {
+	v := keyJSON{
+		Label:     k.Label,
+		Index:     k.Index,
+		SigAlgo:   k.SigAlgo.String(),
+		HashAlgo:  k.HashAlgo.String(),
+		Encrypted: k.Encrypted,
+	}
+
+	if k.Encrypted == nil {
+		if k.PrivateKey == nil {
+			return nil, fmt.Errorf("keystore: key %q has no private key", k.Label)
+		}
+		v.PrivateKey = encodeHex(k.PrivateKey.Encode())
+	}
+
+	return json.Marshal(v)
+}
+
+func (k *Key) UnmarshalJSON(data []byte) error {
+	var v keyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	sigAlgo := crypto.StringToSignatureAlgorithm(v.SigAlgo)
+	if sigAlgo == crypto.UnknownSignatureAlgorithm {
+		return fmt.Errorf("keystore: key %q has unknown signature algorithm %q", v.Label, v.SigAlgo)
+	}
+
+	hashAlgo := crypto.StringToHashAlgorithm(v.HashAlgo)
+	if hashAlgo == crypto.UnknownHashAlgorithm {
+		return fmt.Errorf("keystore: key %q has unknown hash algorithm %q", v.Label, v.HashAlgo)
+	}
+
+	*k = Key{
+		Label:     v.Label,
+		Index:     v.Index,
+		SigAlgo:   sigAlgo,
+		HashAlgo:  hashAlgo,
+		Encrypted: v.Encrypted,
+	}
+
+	switch {
+	case v.PrivateKey != "":
+		privateKey, err := crypto.DecodePrivateKeyHex(sigAlgo, trimHexPrefix(v.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("keystore: key %q has invalid private key: %w", v.Label, err)
+		}
+		k.PrivateKey = &privateKey
+	case v.Encrypted == nil:
+		return fmt.Errorf("keystore: key %q has no private key", v.Label)
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package keystore_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/keystore"
+)
+
+func newPrivateKey(t *testing.T, b byte) crypto.PrivateKey {
+	seed := bytes.Repeat([]byte{b}, crypto.MinSeedLength)
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func TestFile_SaveLoad(t *testing.T) {
+	address := flow.HexToAddress("f8d6e0586b0a20c7")
+
+	hot := newPrivateKey(t, 1)
+	cold := newPrivateKey(t, 2)
+
+	file := keystore.New()
+	require.NoError(t, file.AddKey("treasury", address, keystore.NewKey("hot", 0, hot, crypto.SHA3_256)))
+	require.NoError(t, file.AddKey("treasury", address, keystore.NewKey("cold", 1, cold, crypto.SHA2_256)))
+
+	err := file.AddKey("treasury", address, keystore.NewKey("hot", 2, hot, crypto.SHA3_256))
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "keys.json")
+	require.NoError(t, file.Save(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := keystore.Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"treasury"}, loaded.AccountNames())
+
+	account, key, err := loaded.Key("treasury", "cold")
+	require.NoError(t, err)
+	assert.Equal(t, address, account.Address)
+	assert.Equal(t, 1, key.Index)
+	assert.Equal(t, crypto.ECDSA_P256, key.SigAlgo)
+	assert.Equal(t, crypto.SHA2_256, key.HashAlgo)
+	assert.Equal(t, cold.Encode(), key.PrivateKey.Encode())
+
+	_, _, err = loaded.Key("treasury", "warm")
+	assert.EqualError(t, err, `keystore: account treasury has no key labeled "warm"`)
+}
+
+// legacyKeysJSON holds a key encrypted in the format of version 0, which does not
+// bind the account address.
+const legacyKeysJSON = `{
+  "version": 1,
+  "accounts": {
+    "user": {
+      "address": "0000000000000001",
+      "keys": [
+        {
+          "label": "hot",
+          "index": 0,
+          "sigAlgorithm": "ECDSA_P256",
+          "hashAlgorithm": "SHA3_256",
+          "encrypted": {
+            "kdf": "scrypt",
+            "n": 1024,
+            "r": 8,
+            "p": 1,
+            "salt": "01010101010101010101010101010101",
+            "nonce": "020202020202020202020202",
+            "ciphertext": "904d1e6e2890b752250e57bf29d442c5beca5487a0c84ce6857d1b118620045892c512a53d0b03e5feb0e4d61f50704b"
+          }
+        }
+      ]
+    }
+  }
+}`
+
+func TestKey_Encryption(t *testing.T) {
+	privateKey := newPrivateKey(t, 3)
+
+	address := flow.HexToAddress("01")
+
+	key := keystore.NewKey("hot", 0, privateKey, crypto.SHA3_256)
+	require.NoError(t, key.Encrypt(address, "correct horse"))
+
+	file := keystore.New()
+	require.NoError(t, file.AddKey("user", address, key))
+
+	var buf bytes.Buffer
+	require.NoError(t, file.Encode(&buf))
+
+	assert.NotContains(t, buf.String(), "privateKey")
+	assert.Contains(t, buf.String(), `"kdf": "scrypt"`)
+
+	t.Run("Decrypt", func(t *testing.T) {
+		loaded, err := keystore.Decode(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+
+		_, key, err := loaded.Key("user", "hot")
+		require.NoError(t, err)
+
+		_, err = key.Signer()
+		assert.Equal(t, keystore.ErrEncrypted, err)
+
+		assert.Equal(t, keystore.ErrWrongPassphrase, key.Decrypt(address, "wrong"))
+
+		require.NoError(t, key.Decrypt(address, "correct horse"))
+		assert.Equal(t, privateKey.Encode(), key.PrivateKey.Encode())
+
+		signer, err := key.Signer()
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, sig)
+	})
+
+	t.Run("Metadata is authenticated", func(t *testing.T) {
+		tampered := strings.Replace(buf.String(), `"index": 0`, `"index": 5`, 1)
+
+		loaded, err := keystore.Decode(strings.NewReader(tampered))
+		require.NoError(t, err)
+
+		_, key, err := loaded.Key("user", "hot")
+		require.NoError(t, err)
+
+		assert.Equal(t, keystore.ErrWrongPassphrase, key.Decrypt(address, "correct horse"))
+	})
+
+	t.Run("Address is authenticated", func(t *testing.T) {
+		loaded, err := keystore.Decode(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+
+		_, key, err := loaded.Key("user", "hot")
+		require.NoError(t, err)
+
+		// the key is moved to another account
+		assert.Equal(t, keystore.ErrWrongPassphrase, key.Decrypt(flow.HexToAddress("02"), "correct horse"))
+	})
+
+	t.Run("Scrypt parameters are bounded", func(t *testing.T) {
+		for _, n := range []string{`"n": 1000`, `"n": 2097152`, `"n": 0`} {
+			tampered := strings.Replace(buf.String(), `"n": 32768`, n, 1)
+
+			loaded, err := keystore.Decode(strings.NewReader(tampered))
+			require.NoError(t, err)
+
+			_, key, err := loaded.Key("user", "hot")
+			require.NoError(t, err)
+
+			err = key.Decrypt(address, "correct horse")
+			assert.Error(t, err, n)
+			assert.NotEqual(t, keystore.ErrWrongPassphrase, err, n)
+		}
+
+		tampered := strings.Replace(buf.String(), `"r": 8`, `"r": 1024`, 1)
+
+		loaded, err := keystore.Decode(strings.NewReader(tampered))
+		require.NoError(t, err)
+
+		_, key, err := loaded.Key("user", "hot")
+		require.NoError(t, err)
+		assert.Error(t, key.Decrypt(address, "correct horse"))
+	})
+
+	t.Run("Legacy format", func(t *testing.T) {
+		legacy, err := keystore.Decode(strings.NewReader(legacyKeysJSON))
+		require.NoError(t, err)
+
+		_, key, err := legacy.Key("user", "hot")
+		require.NoError(t, err)
+
+		require.NoError(t, key.Decrypt(address, "correct horse"))
+		assert.Equal(t, newPrivateKey(t, 6).Encode(), key.PrivateKey.Encode())
+
+		// encrypted again, the key is bound to its address
+		require.NoError(t, key.Encrypt(address, "correct horse"))
+		assert.Equal(t, 2, key.Encrypted.Version)
+	})
+}
+
+func TestFile_Backup(t *testing.T) {
+	address := flow.HexToAddress("f8d6e0586b0a20c7")
+	hot := newPrivateKey(t, 4)
+
+	file := keystore.New()
+	require.NoError(t, file.AddKey("treasury", address, keystore.NewKey("hot", 3, hot, crypto.SHA2_256)))
+
+	backup, err := file.ExportBackup("correct horse")
+	require.NoError(t, err)
+	assert.NotContains(t, string(backup), "privateKey")
+
+	tamper := func(t *testing.T, f func(backup map[string]interface{})) []byte {
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(backup, &fields))
+		f(fields)
+		b, err := json.Marshal(fields)
+		require.NoError(t, err)
+		return b
+	}
+
+	t.Run("Import", func(t *testing.T) {
+		imported, err := keystore.ImportBackup(backup, "correct horse")
+		require.NoError(t, err)
+
+		account, key, err := imported.Key("treasury", "hot")
+		require.NoError(t, err)
+		assert.Equal(t, address, account.Address)
+		assert.Equal(t, 3, key.Index)
+		assert.Equal(t, crypto.ECDSA_P256, key.SigAlgo)
+		assert.Equal(t, crypto.SHA2_256, key.HashAlgo)
+		assert.Equal(t, hot.Encode(), key.PrivateKey.Encode())
+
+		_, err = keystore.ImportBackup(backup, "wrong")
+		assert.Equal(t, keystore.ErrWrongPassphrase, err)
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		corrupted := tamper(t, func(backup map[string]interface{}) {
+			encrypted := backup["encrypted"].(map[string]interface{})
+			ciphertext := encrypted["ciphertext"].(string)
+			encrypted["ciphertext"] = "00" + ciphertext[2:]
+		})
+
+		_, err := keystore.ImportBackup(corrupted, "correct horse")
+		assert.Equal(t, keystore.ErrBackupCorrupted, err)
+
+		redated := tamper(t, func(backup map[string]interface{}) {
+			backup["createdAt"] = "2020-01-01T00:00:00Z"
+		})
+
+		_, err = keystore.ImportBackup(redated, "correct horse")
+		assert.Equal(t, keystore.ErrBackupCorrupted, err)
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		future := tamper(t, func(backup map[string]interface{}) {
+			backup["version"] = keystore.BackupVersion + 1
+		})
+
+		_, err := keystore.ImportBackup(future, "correct horse")
+		assert.EqualError(t, err, "keystore: unsupported backup version 2")
+	})
+
+	t.Run("Encrypted key", func(t *testing.T) {
+		key := keystore.NewKey("cold", 0, newPrivateKey(t, 5), crypto.SHA3_256)
+		require.NoError(t, key.Encrypt(address, "key passphrase"))
+
+		file := keystore.New()
+		require.NoError(t, file.AddKey("user", address, key))
+
+		var buf bytes.Buffer
+		require.NoError(t, file.Encode(&buf))
+
+		loaded, err := keystore.Decode(&buf)
+		require.NoError(t, err)
+
+		_, err = loaded.ExportBackup("correct horse")
+		assert.Equal(t, keystore.ErrEncrypted, err)
+	})
+}
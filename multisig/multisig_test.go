@@ -0,0 +1,192 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multisig_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/multisig"
+)
+
+var (
+	payer    = flow.HexToAddress("01")
+	treasury = flow.HexToAddress("02")
+
+	alice  = multisig.KeyRef{Address: treasury, KeyIndex: 0}
+	bob    = multisig.KeyRef{Address: treasury, KeyIndex: 1}
+	carol  = multisig.KeyRef{Address: treasury, KeyIndex: 2}
+	ops    = multisig.KeyRef{Address: payer, KeyIndex: 0}
+	backup = multisig.KeyRef{Address: payer, KeyIndex: 1}
+)
+
+func newPolicy(deadline time.Time) multisig.SigningPolicy {
+	return multisig.SigningPolicy{
+		Roles: []multisig.Role{
+			{Name: "finance", Keys: []multisig.KeyRef{alice, bob, carol}, Required: 2},
+			{Name: "operations", Keys: []multisig.KeyRef{ops, backup}},
+		},
+		Order:    []string{"finance", "operations"},
+		Deadline: deadline,
+	}
+}
+
+func newTransaction() *flow.Transaction {
+	return flow.NewTransaction().
+		SetScript([]byte("transaction {}")).
+		SetProposalKey(treasury, 0, 1).
+		SetPayer(payer).
+		AddAuthorizer(treasury)
+}
+
+func TestSigningPolicy(t *testing.T) {
+	deadline := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	policy := newPolicy(deadline)
+	require.NoError(t, policy.Validate())
+
+	b, err := policy.Encode()
+	require.NoError(t, err)
+
+	decoded, err := multisig.DecodePolicy(b)
+	require.NoError(t, err)
+	assert.Equal(t, policy, decoded)
+
+	invalid := []multisig.SigningPolicy{
+		{},
+		{Roles: []multisig.Role{{Name: "finance", Keys: []multisig.KeyRef{alice}, Required: 2}}},
+		{Roles: []multisig.Role{{Name: "finance", Keys: []multisig.KeyRef{alice}}, {Name: "finance"}}},
+		{Roles: []multisig.Role{{Keys: []multisig.KeyRef{alice}}}},
+		{Roles: []multisig.Role{{Name: "finance", Keys: []multisig.KeyRef{alice}}}, Order: []string{"legal"}},
+	}
+
+	for _, p := range invalid {
+		assert.Error(t, p.Validate())
+	}
+
+	_, err = multisig.DecodePolicy([]byte(`{"roles": []}`))
+	assert.Error(t, err)
+}
+
+func TestPendingTransaction(t *testing.T) {
+	now := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+
+	pending, err := multisig.NewPendingTransaction(newTransaction(), newPolicy(now.Add(time.Hour)))
+	require.NoError(t, err)
+
+	sign := func(key multisig.KeyRef) error {
+		return pending.AddSignature(multisig.Signature{Key: key, Signature: []byte{byte(key.KeyIndex)}}, now)
+	}
+
+	assert.Equal(t, []multisig.KeyRef{alice, bob, carol}, pending.Eligible())
+
+	// operations approves after finance
+	err = sign(ops)
+	assert.True(t, errors.Is(err, multisig.ErrSignatureOrder))
+	assert.Equal(t, errcode.SignatureOrder, errcode.Of(err))
+
+	err = sign(multisig.KeyRef{Address: treasury, KeyIndex: 7})
+	assert.True(t, errors.Is(err, multisig.ErrUnexpectedSigner))
+
+	require.NoError(t, sign(alice))
+	assert.Error(t, sign(alice))
+	assert.False(t, pending.Approved("finance"))
+
+	require.NoError(t, sign(carol))
+	assert.True(t, pending.Approved("finance"))
+	assert.False(t, pending.Satisfied())
+	assert.Equal(t, []multisig.KeyRef{ops, backup}, pending.Eligible())
+
+	assert.Equal(t, pending.Transaction.EnvelopeMessage(), pending.Message(ops))
+	assert.Equal(t, pending.Transaction.PayloadMessage(), pending.Message(bob))
+
+	require.NoError(t, sign(ops))
+	assert.True(t, pending.Satisfied())
+	assert.Empty(t, pending.Eligible())
+
+	// payload signatures would invalidate the envelope signature
+	err = sign(bob)
+	assert.True(t, errors.Is(err, multisig.ErrSignatureOrder))
+
+	assert.Len(t, pending.Transaction.PayloadSignatures, 2)
+	assert.Len(t, pending.Transaction.EnvelopeSignatures, 1)
+
+	t.Run("JSON", func(t *testing.T) {
+		b, err := json.Marshal(pending)
+		require.NoError(t, err)
+
+		var decoded multisig.PendingTransaction
+		require.NoError(t, json.Unmarshal(b, &decoded))
+
+		assert.Equal(t, pending.Transaction.ID(), decoded.Transaction.ID())
+		assert.Equal(t, pending.Transaction.EnvelopeSignatures, decoded.Transaction.EnvelopeSignatures)
+		assert.Equal(t, pending.Policy, decoded.Policy)
+		assert.Equal(t, pending.Signatures, decoded.Signatures)
+		assert.True(t, decoded.Satisfied())
+	})
+
+	t.Run("Unordered role", func(t *testing.T) {
+		auditor := multisig.KeyRef{Address: treasury, KeyIndex: 3}
+
+		policy := newPolicy(now.Add(time.Hour))
+		policy.Roles = append(policy.Roles, multisig.Role{Name: "audit", Keys: []multisig.KeyRef{auditor}})
+		require.NoError(t, policy.Validate())
+
+		pending, err := multisig.NewPendingTransaction(newTransaction(), policy)
+		require.NoError(t, err)
+
+		assert.Equal(t, []multisig.KeyRef{alice, bob, carol, auditor}, pending.Eligible())
+
+		// a role that is not ordered signs before the ordered roles
+		require.NoError(t, pending.AddSignature(multisig.Signature{Key: auditor, Signature: []byte{3}}, now))
+		assert.True(t, pending.Approved("audit"))
+
+		err = pending.AddSignature(multisig.Signature{Key: ops, Signature: []byte{0}}, now)
+		assert.True(t, errors.Is(err, multisig.ErrSignatureOrder))
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		pending, err := multisig.NewPendingTransaction(newTransaction(), newPolicy(now.Add(time.Hour)))
+		require.NoError(t, err)
+
+		assert.False(t, pending.Expired(now))
+		assert.True(t, pending.Expired(now.Add(2*time.Hour)))
+
+		err = pending.AddSignature(multisig.Signature{Key: alice}, now.Add(2*time.Hour))
+		assert.True(t, errors.Is(err, multisig.ErrDeadlinePassed))
+	})
+
+	t.Run("Required keys", func(t *testing.T) {
+		pending, err := multisig.NewPendingTransaction(newTransaction(), multisig.SigningPolicy{
+			RequiredKeys: []multisig.KeyRef{alice, ops},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, pending.AddSignature(multisig.Signature{Key: alice}, now))
+		assert.False(t, pending.Satisfied())
+		require.NoError(t, pending.AddSignature(multisig.Signature{Key: ops}, now))
+		assert.True(t, pending.Satisfied())
+	})
+}
@@ -0,0 +1,283 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multisig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+var (
+	// ErrDeadlinePassed means that a signature was added after the deadline of the policy.
+	ErrDeadlinePassed = errcode.New(errcode.DeadlinePassed, "multisig: signing deadline passed")
+	// ErrUnexpectedSigner means that the policy does not expect a signature from a key.
+	ErrUnexpectedSigner = errcode.New(errcode.UnexpectedSigner, "multisig: unexpected signer")
+	// ErrSignatureOrder means that a signature was added before the signatures that
+	// must precede it.
+	ErrSignatureOrder = errcode.New(errcode.SignatureOrder, "multisig: signature out of order")
+)
+
+// A Signature is a signature collected for a pending transaction.
+type Signature struct {
+	Key       KeyRef    `json:"key"`
+	Signature []byte    `json:"signature"`
+	SignedAt  time.Time `json:"signedAt"`
+}
+
+// A PendingTransaction is a transaction that collects signatures under a signing
+// policy.
+//
+// Keys of the payer sign the envelope of the transaction, and other keys sign its
+// payload. Since the envelope covers the payload signatures, payload signatures
+// are no longer accepted once the envelope is signed.
+type PendingTransaction struct {
+	Transaction *flow.Transaction
+	Policy      SigningPolicy
+	// Signatures are the collected signatures, in the order they were added.
+	Signatures []Signature
+}
+
+// NewPendingTransaction attaches a policy to a transaction.
+func NewPendingTransaction(tx *flow.Transaction, policy SigningPolicy) (*PendingTransaction, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &PendingTransaction{
+		Transaction: tx,
+		Policy:      policy,
+	}, nil
+}
+
+// Message returns the message that the key signs: the envelope message for keys
+// of the payer, and the payload message for other keys.
+func (p *PendingTransaction) Message(key KeyRef) []byte {
+	if key.Address == p.Transaction.Payer {
+		return p.Transaction.EnvelopeMessage()
+	}
+	return p.Transaction.PayloadMessage()
+}
+
+// AddSignature adds a signature of the message returned by Message to the
+// transaction, if the policy allows it at the given time.
+//
+// An error wrapping ErrDeadlinePassed, ErrUnexpectedSigner or ErrSignatureOrder is
+// returned if the policy does not allow the signature. Signatures are not verified.
+func (p *PendingTransaction) AddSignature(sig Signature, now time.Time) error {
+	if p.Policy.expired(now) {
+		return fmt.Errorf("%w at %s", ErrDeadlinePassed, p.Policy.Deadline.Format(time.RFC3339))
+	}
+
+	if !p.Policy.allows(sig.Key) {
+		return fmt.Errorf("%w %s", ErrUnexpectedSigner, sig.Key)
+	}
+
+	if p.signed(sig.Key) {
+		return fmt.Errorf("multisig: key %s already signed", sig.Key)
+	}
+
+	if !p.open(sig.Key) {
+		return fmt.Errorf("%w: key %s signs after the roles ordered before its role", ErrSignatureOrder, sig.Key)
+	}
+
+	envelope := sig.Key.Address == p.Transaction.Payer
+	if !envelope && len(p.Transaction.EnvelopeSignatures) > 0 {
+		return fmt.Errorf("%w: key %s signs the payload after the envelope was signed", ErrSignatureOrder, sig.Key)
+	}
+
+	if sig.SignedAt.IsZero() {
+		sig.SignedAt = now
+	}
+
+	if envelope {
+		p.Transaction.AddEnvelopeSignature(sig.Key.Address, sig.Key.KeyIndex, sig.Signature)
+	} else {
+		p.Transaction.AddPayloadSignature(sig.Key.Address, sig.Key.KeyIndex, sig.Signature)
+	}
+
+	p.Signatures = append(p.Signatures, sig)
+
+	return nil
+}
+
+// Approved returns true if enough keys of the role with the given name signed.
+func (p *PendingTransaction) Approved(role string) bool {
+	r, ok := p.Policy.Role(role)
+	if !ok {
+		return false
+	}
+
+	var signed int
+	for _, key := range r.Keys {
+		if p.signed(key) {
+			signed++
+		}
+	}
+
+	return signed >= r.required()
+}
+
+// Satisfied returns true if all roles approved and all required keys signed.
+func (p *PendingTransaction) Satisfied() bool {
+	for _, role := range p.Policy.Roles {
+		if !p.Approved(role.Name) {
+			return false
+		}
+	}
+
+	for _, key := range p.Policy.RequiredKeys {
+		if !p.signed(key) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Expired returns true if the deadline of the policy passed at the given time.
+func (p *PendingTransaction) Expired(now time.Time) bool {
+	return p.Policy.expired(now)
+}
+
+// Eligible returns the keys that the policy allows to sign next: the keys that did
+// not sign yet, of the required keys and of the roles that did not approve and are
+// not waiting for roles ordered before them.
+func (p *PendingTransaction) Eligible() []KeyRef {
+	var keys []KeyRef
+	seen := make(map[KeyRef]bool)
+
+	add := func(key KeyRef) {
+		if seen[key] || p.signed(key) {
+			return
+		}
+		if key.Address != p.Transaction.Payer && len(p.Transaction.EnvelopeSignatures) > 0 {
+			return
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	for _, key := range p.Policy.RequiredKeys {
+		add(key)
+	}
+
+	for _, role := range p.Policy.Roles {
+		if p.Approved(role.Name) || !p.roleOpen(role.Name) {
+			continue
+		}
+		for _, key := range role.Keys {
+			add(key)
+		}
+	}
+
+	return keys
+}
+
+func (p *PendingTransaction) signed(key KeyRef) bool {
+	for _, sig := range p.Signatures {
+		if sig.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// open returns true if the key is a required key, or a key of a role that may
+// sign under the ordering of the policy.
+func (p *PendingTransaction) open(key KeyRef) bool {
+	for _, k := range p.Policy.RequiredKeys {
+		if k == key {
+			return true
+		}
+	}
+
+	for _, role := range p.Policy.Roles {
+		if role.has(key) && p.roleOpen(role.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// roleOpen returns true if the role is not ordered, or if all roles ordered before
+// it approved.
+func (p *PendingTransaction) roleOpen(name string) bool {
+	for i, ordered := range p.Policy.Order {
+		if ordered != name {
+			continue
+		}
+
+		for _, before := range p.Policy.Order[:i] {
+			if !p.Approved(before) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+type pendingTransactionJSON struct {
+	Transaction []byte        `json:"transaction"`
+	Policy      SigningPolicy `json:"policy"`
+	Signatures  []Signature   `json:"signatures,omitempty"`
+}
+
+// MarshalJSON returns the JSON encoding of the pending transaction, with the
+// transaction in its canonical encoding.
+func (p *PendingTransaction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pendingTransactionJSON{
+		Transaction: p.Transaction.Encode(),
+		Policy:      p.Policy,
+		Signatures:  p.Signatures,
+	})
+}
+
+// UnmarshalJSON decodes a pending transaction encoded with MarshalJSON.
+func (p *PendingTransaction) UnmarshalJSON(data []byte) error {
+	var v pendingTransactionJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if len(v.Transaction) == 0 {
+		return errors.New("multisig: pending transaction has no transaction")
+	}
+
+	var tx flow.Transaction
+	if err := tx.DecodeFromBytes(v.Transaction); err != nil {
+		return fmt.Errorf("multisig: invalid transaction: %w", err)
+	}
+
+	if err := v.Policy.Validate(); err != nil {
+		return err
+	}
+
+	p.Transaction = &tx
+	p.Policy = v.Policy
+	p.Signatures = v.Signatures
+
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package multisig coordinates the collection of signatures for transactions that
+// require the approval of several parties.
+//
+// A SigningPolicy formalizes who must approve a transaction: roles of which a number
+// of keys must sign, specific keys that must sign, the order in which roles approve,
+// and a deadline. A PendingTransaction attaches a policy to a transaction, and only
+// accepts the signatures that the policy allows:
+//
+//	policy := multisig.SigningPolicy{
+//		Roles: []multisig.Role{
+//			{Name: "finance", Keys: financeKeys, Required: 2},
+//			{Name: "security", Keys: securityKeys},
+//		},
+//		Order:    []string{"finance", "security"},
+//		Deadline: time.Now().Add(24 * time.Hour),
+//	}
+//
+//	pending, err := multisig.NewPendingTransaction(tx, policy)
+//	...
+//	err = pending.AddSignature(multisig.Signature{Key: key, Signature: sig}, time.Now())
+//	...
+//	if pending.Satisfied() {
+//		err = c.SendTransaction(ctx, *pending.Transaction)
+//	}
+//
+// Policies and pending transactions can be marshaled to JSON and persisted while
+// signatures are collected.
+//
+// A Workflow automates the collection: it notifies the keys that may sign next with
+// pluggable notifiers, verifies the signatures they return, and sends the
+// transaction once its policy is satisfied:
+//
+//	workflow := multisig.NewWorkflow(c, nil, multisig.WebhookNotifier{URL: hookURL})
+//
+//	_, err := workflow.Start(ctx, "payout-42", pending)
+//	...
+//	approval, err := workflow.Sign(ctx, "payout-42", key, sig)
+package multisig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A KeyRef identifies an account key.
+type KeyRef struct {
+	Address  flow.Address `json:"address"`
+	KeyIndex int          `json:"keyIndex"`
+}
+
+// String returns the string representation of this key reference.
+func (k KeyRef) String() string {
+	return fmt.Sprintf("%s/%d", k.Address.Hex(), k.KeyIndex)
+}
+
+// A Role is a group of account keys that approve transactions together, e.g. the
+// keys of the members of a team.
+type Role struct {
+	Name string   `json:"name"`
+	Keys []KeyRef `json:"keys"`
+	// Required is the number of keys of the role that must sign.
+	//
+	// Defaults to 1.
+	Required int `json:"required,omitempty"`
+}
+
+func (r Role) required() int {
+	if r.Required <= 0 {
+		return 1
+	}
+	return r.Required
+}
+
+func (r Role) has(key KeyRef) bool {
+	for _, k := range r.Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// A SigningPolicy is the set of approvals a transaction requires before it is sent.
+type SigningPolicy struct {
+	// Roles must all approve the transaction.
+	Roles []Role `json:"roles,omitempty"`
+	// RequiredKeys must all sign the transaction, in addition to the keys of roles.
+	RequiredKeys []KeyRef `json:"requiredKeys,omitempty"`
+	// Order lists role names in the order in which the roles approve: the keys of a
+	// role may only sign once all roles listed before it approved. Roles not listed
+	// may approve at any time.
+	Order []string `json:"order,omitempty"`
+	// Deadline is the time after which signatures are no longer accepted. The policy
+	// has no deadline if Deadline is zero.
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+// Validate returns an error if the policy is inconsistent, e.g. if a role requires
+// more signatures than it has keys.
+func (p SigningPolicy) Validate() error {
+	if len(p.Roles) == 0 && len(p.RequiredKeys) == 0 {
+		return errors.New("multisig: policy requires no signatures")
+	}
+
+	roles := make(map[string]bool, len(p.Roles))
+	for _, role := range p.Roles {
+		if role.Name == "" {
+			return errors.New("multisig: role has no name")
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("multisig: role %s is declared twice", role.Name)
+		}
+		if role.required() > len(role.Keys) {
+			return fmt.Errorf(
+				"multisig: role %s requires %d signatures but has %d keys",
+				role.Name,
+				role.required(),
+				len(role.Keys),
+			)
+		}
+		roles[role.Name] = true
+	}
+
+	ordered := make(map[string]bool, len(p.Order))
+	for _, name := range p.Order {
+		if !roles[name] {
+			return fmt.Errorf("multisig: order lists unknown role %s", name)
+		}
+		if ordered[name] {
+			return fmt.Errorf("multisig: order lists role %s twice", name)
+		}
+		ordered[name] = true
+	}
+
+	return nil
+}
+
+// Role returns the role with the given name, and false if the policy has no such role.
+func (p SigningPolicy) Role(name string) (Role, bool) {
+	for _, role := range p.Roles {
+		if role.Name == name {
+			return role, true
+		}
+	}
+	return Role{}, false
+}
+
+// allows returns true if the policy expects a signature from the key.
+func (p SigningPolicy) allows(key KeyRef) bool {
+	for _, k := range p.RequiredKeys {
+		if k == key {
+			return true
+		}
+	}
+
+	for _, role := range p.Roles {
+		if role.has(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expired returns true if the deadline of the policy passed at the given time.
+func (p SigningPolicy) expired(now time.Time) bool {
+	return !p.Deadline.IsZero() && now.After(p.Deadline)
+}
+
+// Encode returns the JSON encoding of the policy.
+func (p SigningPolicy) Encode() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// DecodePolicy decodes and validates a policy encoded with Encode.
+func DecodePolicy(b []byte) (SigningPolicy, error) {
+	var policy SigningPolicy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return SigningPolicy{}, fmt.Errorf("multisig: invalid policy: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return SigningPolicy{}, err
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,528 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multisig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/deeplink"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+var (
+	// ErrInvalidSignature means that a signature does not verify with the account key
+	// that supposedly produced it.
+	ErrInvalidSignature = errcode.New(errcode.InvalidSignature, "multisig: invalid signature")
+	// ErrApprovalNotFound is returned for unknown approval IDs.
+	ErrApprovalNotFound = errors.New("multisig: approval not found")
+)
+
+// A Notification asks an account key to sign a pending transaction.
+type Notification struct {
+	// ApprovalID is the ID of the approval in the workflow.
+	ApprovalID string
+	Key        KeyRef
+	// Request is the signing request of the key, which can be encoded as a deep link
+	// or QR code for the signing device.
+	Request deeplink.Request
+}
+
+// A Notifier tells signers that a pending transaction awaits their signature, e.g.
+// by email, chat message or webhook.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NotifierFunc is a function that implements the Notifier interface.
+type NotifierFunc func(ctx context.Context, notification Notification) error
+
+// Notify calls f(ctx, notification).
+func (f NotifierFunc) Notify(ctx context.Context, notification Notification) error {
+	return f(ctx, notification)
+}
+
+// A WebhookNotifier posts notifications to a URL as JSON objects:
+//
+//	{
+//		"approvalID": "payout-42",
+//		"address": "0xf8d6e0586b0a20c7",
+//		"keyIndex": 1,
+//		"request": "AQEA...",
+//		"expiresAt": "2020-10-01T12:00:00Z"
+//	}
+//
+// where request is the payload of the deeplink signing request.
+type WebhookNotifier struct {
+	URL string
+	// HTTPClient sends the notifications.
+	//
+	// Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+var _ Notifier = WebhookNotifier{}
+
+type webhookNotification struct {
+	ApprovalID string       `json:"approvalID"`
+	Address    flow.Address `json:"address"`
+	KeyIndex   int          `json:"keyIndex"`
+	Request    string       `json:"request"`
+	ExpiresAt  *time.Time   `json:"expiresAt,omitempty"`
+}
+
+// Notify posts the notification to the URL of the webhook.
+func (n WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	payload, err := notification.Request.Encode()
+	if err != nil {
+		return err
+	}
+
+	body := webhookNotification{
+		ApprovalID: notification.ApprovalID,
+		Address:    notification.Key.Address,
+		KeyIndex:   notification.Key.KeyIndex,
+		Request:    payload,
+	}
+	if !notification.Request.ExpiresAt.IsZero() {
+		body.ExpiresAt = &notification.Request.ExpiresAt
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("multisig: webhook returned %d: %s", res.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// An Approval is the state of a pending transaction in a workflow.
+type Approval struct {
+	ID      string              `json:"id"`
+	Pending *PendingTransaction `json:"pending"`
+	// Notified are the keys that were notified.
+	Notified []KeyRef `json:"notified,omitempty"`
+	// Submitted is true once the transaction was sent to the network.
+	Submitted bool `json:"submitted"`
+}
+
+func (a *Approval) notified(key KeyRef) bool {
+	for _, k := range a.Notified {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// An ApprovalStore persists the approvals of a workflow.
+type ApprovalStore interface {
+	// Save creates or replaces an approval.
+	Save(approval *Approval) error
+	// Load returns the approval with the given ID, or an error wrapping
+	// ErrApprovalNotFound.
+	Load(id string) (*Approval, error)
+}
+
+// MemoryApprovalStore is an ApprovalStore that keeps approvals in memory.
+type MemoryApprovalStore struct {
+	mu        sync.Mutex
+	approvals map[string][]byte
+}
+
+var _ ApprovalStore = &MemoryApprovalStore{}
+
+// NewMemoryApprovalStore returns an empty in-memory approval store.
+func NewMemoryApprovalStore() *MemoryApprovalStore {
+	return &MemoryApprovalStore{
+		approvals: make(map[string][]byte),
+	}
+}
+
+// Save stores a copy of the approval.
+func (s *MemoryApprovalStore) Save(approval *Approval) error {
+	b, err := json.Marshal(approval)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.approvals[approval.ID] = b
+	return nil
+}
+
+// Load returns a copy of the stored approval.
+func (s *MemoryApprovalStore) Load(id string) (*Approval, error) {
+	s.mu.Lock()
+	b, ok := s.approvals[id]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	}
+
+	var approval Approval
+	if err := json.Unmarshal(b, &approval); err != nil {
+		return nil, err
+	}
+
+	return &approval, nil
+}
+
+// A Workflow collects the signatures of pending transactions, and sends each
+// transaction once its signing policy is satisfied.
+//
+// When an approval starts, and after each signature, the keys that the policy
+// allows to sign next are notified. Keys of the payer are only notified once no
+// other key may sign, since the envelope they sign covers the payload signatures.
+// Signatures are returned by signers with Sign, and verified with the keys of
+// their accounts before they are added.
+//
+// If notifying keys or sending the transaction fails, the approval keeps its
+// signatures and Retry resumes it.
+//
+// The proposal key of a transaction must be part of its policy, as any other key
+// that signs it.
+type Workflow struct {
+	client    flow.Client
+	notifiers []Notifier
+	store     ApprovalStore
+
+	// mu serializes the updates of approvals in the store. It is not held across
+	// network calls.
+	mu sync.Mutex
+	// advancing holds the IDs of the approvals being advanced, and whether they
+	// changed since, in which case they are advanced again.
+	advancing map[string]bool
+}
+
+// NewWorkflow returns a workflow that verifies signatures and sends transactions
+// with c, notifies signers with the given notifiers, and keeps approvals in the
+// store.
+//
+// If store is nil, approvals are kept in memory.
+func NewWorkflow(c flow.Client, store ApprovalStore, notifiers ...Notifier) *Workflow {
+	if store == nil {
+		store = NewMemoryApprovalStore()
+	}
+
+	return &Workflow{
+		client:    c,
+		notifiers: notifiers,
+		store:     store,
+		advancing: make(map[string]bool),
+	}
+}
+
+// Start records a pending transaction under the given ID, and notifies the keys
+// that may sign first.
+func (w *Workflow) Start(ctx context.Context, id string, pending *PendingTransaction) (*Approval, error) {
+	w.mu.Lock()
+
+	if _, err := w.store.Load(id); err == nil {
+		w.mu.Unlock()
+		return nil, fmt.Errorf("multisig: approval %s already exists", id)
+	} else if !errors.Is(err, ErrApprovalNotFound) {
+		w.mu.Unlock()
+		return nil, err
+	}
+
+	approval := &Approval{ID: id, Pending: pending}
+	err := w.store.Save(approval)
+
+	w.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return w.advance(ctx, approval)
+}
+
+// Approval returns the approval with the given ID.
+func (w *Workflow) Approval(id string) (*Approval, error) {
+	return w.store.Load(id)
+}
+
+// Sign adds the signature of a key to the approval with the given ID, after
+// verifying it with the account key, and notifies the keys that may sign next. The
+// transaction is sent once its policy is satisfied.
+//
+// An error wrapping ErrInvalidSignature is returned for signatures that do not
+// verify, and the errors of PendingTransaction.AddSignature for signatures that the
+// policy does not allow.
+func (w *Workflow) Sign(ctx context.Context, id string, key KeyRef, signature []byte) (*Approval, error) {
+	approval, err := w.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if approval.Submitted {
+		return approval, fmt.Errorf("multisig: approval %s was already submitted", id)
+	}
+
+	accountKey, err := w.accountKey(ctx, key)
+	if err != nil {
+		return approval, err
+	}
+
+	approval, err = w.update(id, func(approval *Approval) error {
+		if approval.Submitted {
+			return fmt.Errorf("multisig: approval %s was already submitted", id)
+		}
+
+		// the message of payer keys changes with each payload signature, so the
+		// signature is verified with the approval it is added to
+		if err := verify(accountKey, approval.Pending, key, signature); err != nil {
+			return err
+		}
+
+		return approval.Pending.AddSignature(Signature{Key: key, Signature: signature}, time.Now())
+	})
+	if err != nil {
+		return approval, err
+	}
+
+	return w.advance(ctx, approval)
+}
+
+// Retry resumes an approval whose last step failed: it sends the transaction of an
+// approval whose policy is satisfied but that was not submitted, or else notifies
+// the keys that may sign but were not notified.
+//
+// Retry has no effect on submitted approvals.
+func (w *Workflow) Retry(ctx context.Context, id string) (*Approval, error) {
+	approval, err := w.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.advance(ctx, approval)
+}
+
+// update applies fn to the stored approval with the given ID, and saves it unless
+// fn returns an error.
+func (w *Workflow) update(id string, fn func(approval *Approval) error) (*Approval, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	approval, err := w.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(approval); err != nil {
+		return approval, err
+	}
+
+	return approval, w.store.Save(approval)
+}
+
+// advance runs the next step of an approval until it no longer changes. If the
+// approval is being advanced by another call, that call runs again, and the
+// approval is returned as is.
+func (w *Workflow) advance(ctx context.Context, approval *Approval) (*Approval, error) {
+	id := approval.ID
+
+	w.mu.Lock()
+	if _, ok := w.advancing[id]; ok {
+		w.advancing[id] = true
+		w.mu.Unlock()
+		return approval, nil
+	}
+	w.advancing[id] = false
+	w.mu.Unlock()
+
+	for {
+		var err error
+		approval, err = w.step(ctx, approval)
+
+		w.mu.Lock()
+		changed := w.advancing[id]
+		if err != nil || !changed {
+			delete(w.advancing, id)
+			w.mu.Unlock()
+			return approval, err
+		}
+		w.advancing[id] = false
+		w.mu.Unlock()
+
+		if approval, err = w.store.Load(id); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// step sends the transaction of an approval if its policy is satisfied, and
+// notifies the keys that may sign next otherwise.
+func (w *Workflow) step(ctx context.Context, approval *Approval) (*Approval, error) {
+	if approval.Submitted {
+		return approval, nil
+	}
+
+	pending := approval.Pending
+
+	if pending.Satisfied() {
+		if err := w.client.SendTransaction(ctx, *pending.Transaction); err != nil {
+			return approval, err
+		}
+
+		return w.update(approval.ID, func(approval *Approval) error {
+			approval.Submitted = true
+			return nil
+		})
+	}
+
+	eligible := pending.Eligible()
+
+	var payload []KeyRef
+	for _, key := range eligible {
+		if key.Address != pending.Transaction.Payer {
+			payload = append(payload, key)
+		}
+	}
+	if len(payload) > 0 {
+		eligible = payload
+	}
+
+	var (
+		notified []KeyRef
+		err      error
+	)
+
+	for _, key := range eligible {
+		if approval.notified(key) {
+			continue
+		}
+
+		notification := Notification{
+			ApprovalID: approval.ID,
+			Key:        key,
+			Request: deeplink.Request{
+				Kind:        deeplink.KindTransaction,
+				Signer:      key.Address,
+				KeyIndex:    key.KeyIndex,
+				Transaction: pending.Transaction,
+				ExpiresAt:   pending.Policy.Deadline,
+			},
+		}
+
+		if err = w.notify(ctx, notification); err != nil {
+			break
+		}
+
+		notified = append(notified, key)
+	}
+
+	if len(notified) == 0 {
+		return approval, err
+	}
+
+	approval, saveErr := w.update(approval.ID, func(approval *Approval) error {
+		for _, key := range notified {
+			if !approval.notified(key) {
+				approval.Notified = append(approval.Notified, key)
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = saveErr
+	}
+
+	return approval, err
+}
+
+// notify sends a notification with all notifiers.
+func (w *Workflow) notify(ctx context.Context, notification Notification) error {
+	for _, notifier := range w.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			return fmt.Errorf("multisig: failed to notify key %s: %w", notification.Key, err)
+		}
+	}
+	return nil
+}
+
+// accountKey returns the account key that a key reference designates, or an error
+// wrapping ErrInvalidSignature if the account has no such key or it is revoked.
+func (w *Workflow) accountKey(ctx context.Context, key KeyRef) (*flow.AccountKey, error) {
+	account, err := w.client.GetAccount(ctx, key.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range account.Keys {
+		if k.Index == key.KeyIndex && !k.Revoked {
+			return k, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: account has no key %s", ErrInvalidSignature, key)
+}
+
+// verify returns an error if the signature was not produced by the account key
+// over the message the key signs.
+func verify(accountKey *flow.AccountKey, pending *PendingTransaction, key KeyRef, signature []byte) error {
+	hasher, err := crypto.NewHasher(accountKey.HashAlgo)
+	if err != nil {
+		return err
+	}
+
+	valid, err := accountKey.PublicKey.Verify(signature, pending.Message(key), hasher)
+	if err != nil || !valid {
+		return fmt.Errorf("%w of key %s", ErrInvalidSignature, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package multisig_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/mocks"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/deeplink"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/multisig"
+)
+
+func TestWorkflow(t *testing.T) {
+	ctx := context.Background()
+
+	signers := make(map[multisig.KeyRef]crypto.Signer)
+	accounts := make(map[flow.Address]*flow.Account)
+
+	for i, key := range []multisig.KeyRef{alice, bob, carol, ops, backup} {
+		privateKey, err := crypto.GeneratePrivateKey(
+			crypto.ECDSA_P256,
+			bytes.Repeat([]byte{byte(i + 1)}, crypto.MinSeedLength),
+		)
+		require.NoError(t, err)
+
+		accountKey := flow.NewAccountKey().
+			FromPrivateKey(privateKey).
+			SetHashAlgo(crypto.SHA3_256).
+			SetWeight(flow.AccountKeyWeightThreshold)
+		accountKey.Index = key.KeyIndex
+
+		account, ok := accounts[key.Address]
+		if !ok {
+			account = &flow.Account{Address: key.Address}
+			accounts[key.Address] = account
+		}
+		account.Keys = append(account.Keys, accountKey)
+
+		signers[key] = crypto.NewInMemorySigner(privateKey, crypto.SHA3_256)
+	}
+
+	c := mocks.New()
+	for _, account := range accounts {
+		c.AddAccount(account)
+	}
+
+	var notified []multisig.KeyRef
+	notifier := multisig.NotifierFunc(func(ctx context.Context, n multisig.Notification) error {
+		assert.Equal(t, "payout-1", n.ApprovalID)
+		assert.Equal(t, n.Key.Address, n.Request.Signer)
+		assert.Equal(t, n.Key.KeyIndex, n.Request.KeyIndex)
+		notified = append(notified, n.Key)
+		return nil
+	})
+
+	workflow := multisig.NewWorkflow(c, nil, notifier)
+
+	pending, err := multisig.NewPendingTransaction(newTransaction(), newPolicy(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	_, err = workflow.Start(ctx, "payout-1", pending)
+	require.NoError(t, err)
+	assert.Equal(t, []multisig.KeyRef{alice, bob, carol}, notified)
+
+	_, err = workflow.Start(ctx, "payout-1", pending)
+	assert.Error(t, err)
+
+	sign := func(key multisig.KeyRef) (*multisig.Approval, error) {
+		approval, err := workflow.Approval("payout-1")
+		require.NoError(t, err)
+
+		signature, err := signers[key].Sign(approval.Pending.Message(key))
+		require.NoError(t, err)
+
+		return workflow.Sign(ctx, "payout-1", key, signature)
+	}
+
+	// signature of another key
+	approval, err := workflow.Approval("payout-1")
+	require.NoError(t, err)
+	signature, err := signers[bob].Sign(approval.Pending.Message(alice))
+	require.NoError(t, err)
+	_, err = workflow.Sign(ctx, "payout-1", alice, signature)
+	assert.True(t, errors.Is(err, multisig.ErrInvalidSignature))
+	assert.Equal(t, errcode.InvalidSignature, errcode.Of(err))
+
+	_, err = sign(ops)
+	assert.True(t, errors.Is(err, multisig.ErrSignatureOrder))
+
+	_, err = sign(alice)
+	require.NoError(t, err)
+	assert.Len(t, notified, 3)
+
+	approval, err = sign(carol)
+	require.NoError(t, err)
+	assert.False(t, approval.Submitted)
+	assert.Equal(t, []multisig.KeyRef{alice, bob, carol, ops, backup}, notified)
+	assert.Empty(t, c.SentTransactions())
+
+	approval, err = sign(backup)
+	require.NoError(t, err)
+	assert.True(t, approval.Submitted)
+
+	sent := c.SentTransactions()
+	require.Len(t, sent, 1)
+	assert.Len(t, sent[0].PayloadSignatures, 2)
+	assert.Len(t, sent[0].EnvelopeSignatures, 1)
+
+	_, err = sign(ops)
+	assert.Error(t, err)
+
+	_, err = workflow.Approval("payout-2")
+	assert.True(t, errors.Is(err, multisig.ErrApprovalNotFound))
+
+	t.Run("Retry", func(t *testing.T) {
+		retrying := multisig.NewWorkflow(c, nil)
+
+		pending, err := multisig.NewPendingTransaction(newTransaction().SetGasLimit(42), newPolicy(time.Now().Add(time.Hour)))
+		require.NoError(t, err)
+
+		_, err = retrying.Start(ctx, "payout-3", pending)
+		require.NoError(t, err)
+
+		sign := func(key multisig.KeyRef) (*multisig.Approval, error) {
+			approval, err := retrying.Approval("payout-3")
+			require.NoError(t, err)
+
+			signature, err := signers[key].Sign(approval.Pending.Message(key))
+			require.NoError(t, err)
+
+			return retrying.Sign(ctx, "payout-3", key, signature)
+		}
+
+		for _, key := range []multisig.KeyRef{alice, bob} {
+			_, err := sign(key)
+			require.NoError(t, err)
+		}
+
+		unavailable := errors.New("unavailable")
+		c.SetError(client.MethodSendTransaction, unavailable)
+
+		// the policy is satisfied, but the transaction is not sent
+		approval, err := sign(ops)
+		assert.True(t, errors.Is(err, unavailable))
+		assert.True(t, approval.Pending.Satisfied())
+		assert.False(t, approval.Submitted)
+
+		_, err = retrying.Retry(ctx, "payout-3")
+		assert.True(t, errors.Is(err, unavailable))
+
+		c.SetError(client.MethodSendTransaction, nil)
+
+		approval, err = retrying.Retry(ctx, "payout-3")
+		require.NoError(t, err)
+		assert.True(t, approval.Submitted)
+
+		stored, err := retrying.Approval("payout-3")
+		require.NoError(t, err)
+		assert.True(t, stored.Submitted)
+		assert.Len(t, c.SentTransactions(), 2)
+
+		_, err = retrying.Retry(ctx, "payout-4")
+		assert.True(t, errors.Is(err, multisig.ErrApprovalNotFound))
+	})
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	pending, err := multisig.NewPendingTransaction(newTransaction(), newPolicy(time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+
+	notification := multisig.Notification{
+		ApprovalID: "payout-1",
+		Key:        bob,
+		Request: deeplink.Request{
+			Kind:        deeplink.KindTransaction,
+			Signer:      bob.Address,
+			KeyIndex:    bob.KeyIndex,
+			Transaction: pending.Transaction,
+			ExpiresAt:   pending.Policy.Deadline,
+		},
+	}
+
+	err = multisig.WebhookNotifier{URL: server.URL + "/notify"}.Notify(context.Background(), notification)
+	require.NoError(t, err)
+
+	payload, err := notification.Request.Encode()
+	require.NoError(t, err)
+
+	assert.Equal(t, "payout-1", received["approvalID"])
+	assert.Equal(t, float64(1), received["keyIndex"])
+	assert.Equal(t, payload, received["request"])
+	assert.NotEmpty(t, received["expiresAt"])
+
+	err = multisig.WebhookNotifier{URL: server.URL + "/fail"}.Notify(context.Background(), notification)
+	assert.Error(t, err)
+}
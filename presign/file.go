@@ -0,0 +1,202 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package presign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A FileStore is a store that saves each entry to a JSON file in a directory.
+//
+// Files are replaced atomically by renaming a temporary file.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+var _ Store = &FileStore{}
+
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+const fileSuffix = ".json"
+
+// fileEntry is the JSON representation of an entry. Transactions are saved with
+// their canonical encoding, which includes their signatures.
+type fileEntry struct {
+	Key             string    `json:"key"`
+	Transaction     []byte    `json:"transaction"`
+	ReferenceHeight uint64    `json:"referenceHeight"`
+	AddedAt         time.Time `json:"addedAt"`
+}
+
+// NewFileStore returns a store that saves entries in the given directory, which is
+// created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	if !validKey.MatchString(key) || key == "." || key == ".." {
+		return "", fmt.Errorf("presign: invalid key %q", key)
+	}
+	return filepath.Join(s.dir, key+fileSuffix), nil
+}
+
+// Put adds or replaces an entry.
+func (s *FileStore) Put(_ context.Context, entry Entry) error {
+	path, err := s.path(entry.Key)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fileEntry{
+		Key:             entry.Key,
+		Transaction:     entry.Transaction.Encode(),
+		ReferenceHeight: entry.ReferenceHeight,
+		AddedAt:         entry.AddedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := ioutil.TempFile(s.dir, entry.Key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the entry with the given key.
+func (s *FileStore) Get(_ context.Context, key string) (Entry, bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	entry, err := readEntry(path)
+	if os.IsNotExist(err) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	return entry, true, nil
+}
+
+// List returns all entries.
+func (s *FileStore) List(_ context.Context) ([]Entry, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), fileSuffix) {
+			continue
+		}
+
+		entry, err := readEntry(filepath.Join(s.dir, file.Name()))
+		if os.IsNotExist(err) {
+			// deleted since the directory was read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Delete removes the entry with the given key.
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func readEntry(path string) (Entry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var saved fileEntry
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return Entry{}, fmt.Errorf("presign: invalid entry %s: %w", path, err)
+	}
+
+	var tx flow.Transaction
+	if err := tx.DecodeFromBytes(saved.Transaction); err != nil {
+		return Entry{}, fmt.Errorf("presign: invalid transaction in %s: %w", path, err)
+	}
+
+	return Entry{
+		Key:             saved.Key,
+		Transaction:     tx,
+		ReferenceHeight: saved.ReferenceHeight,
+		AddedAt:         saved.AddedAt,
+	}, nil
+}
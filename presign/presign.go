@@ -0,0 +1,334 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package presign holds fully signed transactions until they are submitted, e.g.
+// payouts that are signed in advance and sent in batches.
+//
+// A transaction must be sealed within client.TransactionExpiry blocks of its
+// reference block. A Queue records the height of the reference block of each
+// transaction it holds, and flags the transactions that expire within a margin, so
+// that they are signed again with a recent reference block before they are sent:
+//
+//	q := presign.NewQueue(c, presign.NewMemoryStore(), 0)
+//
+//	if _, err := q.Add(ctx, "payout-42", tx); err != nil {
+//		return err
+//	}
+//
+//	statuses, err := q.NeedsResign(ctx)
+package presign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// DefaultResignMargin is the default number of blocks before its expiry from which
+// a transaction must be signed again.
+const DefaultResignMargin = 100
+
+var (
+	// ErrExpired is returned when an expired transaction is submitted.
+	ErrExpired = errcode.New(errcode.TransactionExpired, "presign: transaction expired")
+	// ErrNotFound is returned for unknown transaction keys.
+	ErrNotFound = errors.New("presign: transaction not found")
+	// ErrNotSigned is returned when a transaction without an envelope signature is added.
+	ErrNotSigned = errors.New("presign: transaction is not signed")
+)
+
+// An Entry is a signed transaction held by a queue.
+type Entry struct {
+	// Key identifies the transaction in the queue, e.g. the ID of a payout.
+	Key string
+	// Transaction is the signed transaction.
+	Transaction flow.Transaction
+	// ReferenceHeight is the height of the reference block of the transaction.
+	ReferenceHeight uint64
+	// AddedAt is the time the transaction was added to the queue.
+	AddedAt time.Time
+}
+
+// ExpiryHeight returns the last block height at which the transaction is valid.
+func (e Entry) ExpiryHeight() uint64 {
+	return e.ReferenceHeight + client.TransactionExpiry
+}
+
+// A Status is an entry with its expiry at the latest block.
+type Status struct {
+	Entry
+	// Remaining is the number of blocks before the transaction expires, and zero if
+	// it expired.
+	Remaining uint64
+	// Expired is true if the transaction is past its expiry height.
+	Expired bool
+	// NeedsResign is true if the transaction expired or expires within the resign
+	// margin of the queue, and must be signed again with a recent reference block.
+	NeedsResign bool
+}
+
+// A Store saves the entries of a queue by key.
+//
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Put adds or replaces an entry.
+	Put(ctx context.Context, entry Entry) error
+	// Get returns the entry with the given key, and false if there is none.
+	Get(ctx context.Context, key string) (Entry, bool, error)
+	// List returns all entries, in any order.
+	List(ctx context.Context) ([]Entry, error)
+	// Delete removes the entry with the given key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// A MemoryStore is a store that keeps entries in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]Entry
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns an empty memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+// Put adds or replaces an entry.
+func (s *MemoryStore) Put(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[entry.Key] = entry
+	return nil
+}
+
+// Get returns the entry with the given key.
+func (s *MemoryStore) Get(_ context.Context, key string) (Entry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[key]
+	return entry, ok, nil
+}
+
+// List returns all entries.
+func (s *MemoryStore) List(_ context.Context) ([]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Delete removes the entry with the given key.
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// A Queue holds signed transactions until they are submitted, and tracks their expiry.
+type Queue struct {
+	client flow.Client
+	store  Store
+	margin uint64
+}
+
+// NewQueue returns a queue that saves its transactions to store, and flags them for
+// re-signing resignMargin blocks before their expiry. A margin of zero defaults to
+// DefaultResignMargin.
+func NewQueue(c flow.Client, store Store, resignMargin uint64) *Queue {
+	if resignMargin == 0 {
+		resignMargin = DefaultResignMargin
+	}
+
+	return &Queue{
+		client: c,
+		store:  store,
+		margin: resignMargin,
+	}
+}
+
+// Add adds a signed transaction to the queue, or replaces the transaction with the
+// same key, e.g. after it was signed again.
+//
+// The reference block of the transaction is fetched to record its height. An error
+// wrapping ErrNotSigned is returned if the transaction has no envelope signature.
+func (q *Queue) Add(ctx context.Context, key string, tx *flow.Transaction) (Entry, error) {
+	if len(tx.EnvelopeSignatures) == 0 {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotSigned, key)
+	}
+
+	header, err := q.client.GetBlockHeaderByID(ctx, tx.ReferenceBlockID)
+	if err != nil {
+		return Entry{}, fmt.Errorf("presign: failed to get reference block of %s: %w", key, err)
+	}
+
+	entry := Entry{
+		Key:             key,
+		Transaction:     *tx,
+		ReferenceHeight: header.Height,
+		AddedAt:         time.Now(),
+	}
+
+	if err := q.store.Put(ctx, entry); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+// List returns the status of all transactions of the queue at the latest finalized
+// block, ordered by expiry height.
+func (q *Queue) List(ctx context.Context) ([]Status, error) {
+	entries, err := q.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := q.latestHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(entries))
+	for i, entry := range entries {
+		statuses[i] = q.status(entry, height)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].ReferenceHeight != statuses[j].ReferenceHeight {
+			return statuses[i].ReferenceHeight < statuses[j].ReferenceHeight
+		}
+		return statuses[i].Key < statuses[j].Key
+	})
+
+	return statuses, nil
+}
+
+// NeedsResign returns the status of the transactions that must be signed again,
+// ordered by expiry height.
+func (q *Queue) NeedsResign(ctx context.Context) ([]Status, error) {
+	statuses, err := q.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var flagged []Status
+	for _, status := range statuses {
+		if status.NeedsResign {
+			flagged = append(flagged, status)
+		}
+	}
+
+	return flagged, nil
+}
+
+// Submit sends the transaction with the given key and removes it from the queue.
+//
+// An error wrapping ErrExpired is returned, and the transaction is kept, if it is
+// past its expiry at the latest finalized block. Transactions within the resign
+// margin are still sent.
+func (q *Queue) Submit(ctx context.Context, key string) (flow.Identifier, error) {
+	entry, ok, err := q.store.Get(ctx, key)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	if !ok {
+		return flow.EmptyID, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	height, err := q.latestHeight(ctx)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	if status := q.status(entry, height); status.Expired {
+		return flow.EmptyID, fmt.Errorf("%w: %s expired at height %d", ErrExpired, key, entry.ExpiryHeight())
+	}
+
+	if err := q.client.SendTransaction(ctx, entry.Transaction); err != nil {
+		return flow.EmptyID, err
+	}
+
+	if err := q.store.Delete(ctx, key); err != nil {
+		return flow.EmptyID, err
+	}
+
+	return entry.Transaction.ID(), nil
+}
+
+// Purge removes the expired transactions from the queue and returns them.
+func (q *Queue) Purge(ctx context.Context) ([]Entry, error) {
+	statuses, err := q.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []Entry
+	for _, status := range statuses {
+		if !status.Expired {
+			continue
+		}
+
+		if err := q.store.Delete(ctx, status.Key); err != nil {
+			return purged, err
+		}
+		purged = append(purged, status.Entry)
+	}
+
+	return purged, nil
+}
+
+// latestHeight returns the height of the latest finalized block, against which
+// collection nodes check the expiry of transactions.
+func (q *Queue) latestHeight(ctx context.Context) (uint64, error) {
+	header, err := q.client.GetLatestBlockHeader(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	return header.Height, nil
+}
+
+func (q *Queue) status(entry Entry, height uint64) Status {
+	status := Status{Entry: entry}
+
+	expiry := entry.ExpiryHeight()
+	if height > expiry {
+		status.Expired = true
+	} else {
+		status.Remaining = expiry - height
+	}
+
+	status.NeedsResign = status.Expired || status.Remaining <= q.margin
+
+	return status
+}
@@ -0,0 +1,123 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package presign_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client/mocks"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/presign"
+	"github.com/portto/blocto-flow-go-sdk/test"
+)
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "presign")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileStore, err := presign.NewFileStore(dir)
+	require.NoError(t, err)
+
+	for name, store := range map[string]presign.Store{
+		"Memory": presign.NewMemoryStore(),
+		"File":   fileStore,
+	} {
+		store := store
+
+		t.Run(name, func(t *testing.T) {
+			c := mocks.New()
+
+			blocks := make([]*flow.Block, 0, 700)
+			advance := func(height uint64) {
+				for uint64(len(blocks)) <= height {
+					blocks = append(blocks, c.AddNextBlock())
+				}
+			}
+
+			advance(200)
+
+			transactions := test.TransactionGenerator()
+			early := transactions.New().SetReferenceBlockID(blocks[0].ID)
+			late := transactions.New().SetReferenceBlockID(blocks[200].ID)
+
+			q := presign.NewQueue(c, store, 0)
+
+			_, err := q.Add(ctx, "early", early)
+			require.NoError(t, err)
+			entry, err := q.Add(ctx, "late", late)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(800), entry.ExpiryHeight())
+
+			unsigned := flow.NewTransaction().SetReferenceBlockID(blocks[0].ID)
+			_, err = q.Add(ctx, "unsigned", unsigned)
+			assert.True(t, errors.Is(err, presign.ErrNotSigned))
+
+			advance(550)
+
+			statuses, err := q.List(ctx)
+			require.NoError(t, err)
+			require.Len(t, statuses, 2)
+			assert.Equal(t, "early", statuses[0].Key)
+			assert.Equal(t, early.ID(), statuses[0].Transaction.ID())
+			assert.Equal(t, uint64(50), statuses[0].Remaining)
+			assert.True(t, statuses[0].NeedsResign)
+			assert.Equal(t, uint64(250), statuses[1].Remaining)
+			assert.False(t, statuses[1].NeedsResign)
+
+			flagged, err := q.NeedsResign(ctx)
+			require.NoError(t, err)
+			require.Len(t, flagged, 1)
+			assert.Equal(t, "early", flagged[0].Key)
+
+			advance(650)
+
+			_, err = q.Submit(ctx, "early")
+			assert.Equal(t, errcode.TransactionExpired, errcode.Of(err))
+
+			txID, err := q.Submit(ctx, "late")
+			require.NoError(t, err)
+			assert.Equal(t, late.ID(), txID)
+			require.Len(t, c.SentTransactions(), 1)
+			assert.Equal(t, late.ID(), c.SentTransactions()[0].ID())
+
+			_, err = q.Submit(ctx, "late")
+			assert.True(t, errors.Is(err, presign.ErrNotFound))
+
+			purged, err := q.Purge(ctx)
+			require.NoError(t, err)
+			require.Len(t, purged, 1)
+			assert.Equal(t, "early", purged[0].Key)
+
+			statuses, err = q.List(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, statuses)
+		})
+	}
+}
@@ -0,0 +1,390 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package preview renders transactions as human-readable previews for approval
+// interfaces.
+//
+// A Preview summarizes what a transaction will do before it is signed or
+// submitted: the script that runs, its decoded arguments, the accounts that
+// sign it and in which role, and an estimate of its fees. Previews can be
+// rendered as plain text or marshaled to JSON.
+package preview
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// A Preview is the structured summary of a transaction.
+type Preview struct {
+	ID          string     `json:"id"`
+	Script      Script     `json:"script"`
+	Arguments   []Argument `json:"arguments"`
+	Signers     []Signer   `json:"signers"`
+	GasLimit    uint64     `json:"gasLimit"`
+	FeeEstimate string     `json:"feeEstimate,omitempty"`
+}
+
+// Script summarizes the Cadence script of a transaction.
+type Script struct {
+	// Hash is the hex-encoded SHA3-256 hash of the script source.
+	Hash string `json:"hash"`
+	// Name is the name of the script, if it matches a known script.
+	Name string `json:"name,omitempty"`
+	// Imports are the imported contracts, as "Name from 0xAddress".
+	Imports []string `json:"imports"`
+	// Lines is the number of lines of the script.
+	Lines int `json:"lines"`
+	// Source is the full script source.
+	Source string `json:"source"`
+}
+
+// An Argument is a decoded transaction argument.
+type Argument struct {
+	// Name is the parameter name, if the script declares it.
+	Name string `json:"name,omitempty"`
+	// Type is the Cadence type of the argument.
+	Type string `json:"type"`
+	// Value is the formatted argument value.
+	Value string `json:"value"`
+	// TokenAmount is true if the value is a fixed-point number, which is how
+	// fungible token amounts are represented.
+	TokenAmount bool `json:"tokenAmount,omitempty"`
+}
+
+// Signer roles.
+const (
+	RoleProposer   = "proposer"
+	RolePayer      = "payer"
+	RoleAuthorizer = "authorizer"
+)
+
+// A Signer is an account that must sign the transaction.
+type Signer struct {
+	Address string   `json:"address"`
+	Roles   []string `json:"roles"`
+	// Signed is true if the transaction already contains a signature from this account.
+	Signed bool `json:"signed"`
+}
+
+// A FeeEstimator estimates the fees paid by the payer of a transaction.
+type FeeEstimator interface {
+	EstimateFee(tx flow.Transaction) (cadence.UFix64, error)
+}
+
+// FlatFee is a FeeEstimator that charges the same fee for every transaction.
+type FlatFee cadence.UFix64
+
+// EstimateFee returns the flat fee.
+func (f FlatFee) EstimateFee(flow.Transaction) (cadence.UFix64, error) {
+	return cadence.UFix64(f), nil
+}
+
+// Options configures the rendering of previews.
+type Options struct {
+	// KnownScripts maps script hashes, as returned in Script.Hash, to script names.
+	KnownScripts map[string]string
+	// Fees estimates the transaction fees. If nil, no estimate is included.
+	Fees FeeEstimator
+}
+
+// New returns the preview of a transaction.
+//
+// An error is returned if an argument cannot be decoded or the fees cannot be estimated.
+// Scripts that cannot be parsed are still previewed, without parameter names or imports.
+func New(tx flow.Transaction, opts Options) (*Preview, error) {
+	p := &Preview{
+		ID:       tx.ID().Hex(),
+		Script:   newScript(tx.Script, opts.KnownScripts),
+		GasLimit: tx.GasLimit,
+		Signers:  signers(tx),
+	}
+
+	names := parameterNames(tx.Script)
+
+	p.Arguments = make([]Argument, len(tx.Arguments))
+	for i := range tx.Arguments {
+		value, err := tx.Argument(i)
+		if err != nil {
+			return nil, fmt.Errorf("preview: failed to decode argument %d: %w", i, err)
+		}
+
+		p.Arguments[i] = newArgument(value)
+		if i < len(names) {
+			p.Arguments[i].Name = names[i]
+		}
+	}
+
+	if opts.Fees != nil {
+		fee, err := opts.Fees.EstimateFee(tx)
+		if err != nil {
+			return nil, fmt.Errorf("preview: failed to estimate fees: %w", err)
+		}
+		p.FeeEstimate = formatUFix64(uint64(fee))
+	}
+
+	return p, nil
+}
+
+func newScript(source []byte, known map[string]string) Script {
+	hasher, _ := crypto.NewHasher(crypto.SHA3_256)
+	hash := hex.EncodeToString(hasher.ComputeHash(source))
+
+	script := Script{
+		Hash:    hash,
+		Name:    known[hash],
+		Imports: []string{},
+		Lines:   strings.Count(strings.TrimRight(string(source), "\n"), "\n") + 1,
+		Source:  string(source),
+	}
+
+	program, err := parser2.ParseProgram(string(source))
+	if err != nil {
+		return script
+	}
+
+	for _, declaration := range program.ImportDeclarations() {
+		location := formatLocation(declaration.Location)
+
+		if len(declaration.Identifiers) == 0 {
+			script.Imports = append(script.Imports, location)
+			continue
+		}
+
+		for _, identifier := range declaration.Identifiers {
+			script.Imports = append(script.Imports, fmt.Sprintf("%s from %s", identifier.Identifier, location))
+		}
+	}
+
+	return script
+}
+
+func formatLocation(location ast.Location) string {
+	if address, ok := location.(ast.AddressLocation); ok {
+		return "0x" + address.ToAddress().Hex()
+	}
+	return fmt.Sprint(location)
+}
+
+// parameterNames returns the parameter names of the transaction declared in the script.
+func parameterNames(source []byte) []string {
+	program, err := parser2.ParseProgram(string(source))
+	if err != nil {
+		return nil
+	}
+
+	declarations := program.TransactionDeclarations()
+	if len(declarations) != 1 || declarations[0].ParameterList == nil {
+		return nil
+	}
+
+	parameters := declarations[0].ParameterList.Parameters
+
+	names := make([]string, len(parameters))
+	for i, parameter := range parameters {
+		names[i] = parameter.Identifier.Identifier
+	}
+
+	return names
+}
+
+func signers(tx flow.Transaction) []Signer {
+	roles := make(map[flow.Address][]string)
+	var order []flow.Address
+
+	add := func(address flow.Address, role string) {
+		if _, ok := roles[address]; !ok {
+			order = append(order, address)
+		}
+		for _, r := range roles[address] {
+			if r == role {
+				return
+			}
+		}
+		roles[address] = append(roles[address], role)
+	}
+
+	add(tx.ProposalKey.Address, RoleProposer)
+	add(tx.Payer, RolePayer)
+	for _, authorizer := range tx.Authorizers {
+		add(authorizer, RoleAuthorizer)
+	}
+
+	signed := make(map[flow.Address]bool)
+	for _, sig := range append(tx.PayloadSignatures, tx.EnvelopeSignatures...) {
+		signed[sig.Address] = true
+	}
+
+	result := make([]Signer, len(order))
+	for i, address := range order {
+		result[i] = Signer{
+			Address: "0x" + address.Hex(),
+			Roles:   roles[address],
+			Signed:  signed[address],
+		}
+	}
+
+	return result
+}
+
+func newArgument(value cadence.Value) Argument {
+	argument := Argument{
+		Type:  typeName(value),
+		Value: FormatValue(value),
+	}
+
+	switch value.(type) {
+	case cadence.UFix64, cadence.Fix64:
+		argument.TokenAmount = true
+	}
+
+	return argument
+}
+
+func typeName(value cadence.Value) string {
+	if value.Type() == nil {
+		return fmt.Sprintf("%T", value)
+	}
+	return value.Type().ID()
+}
+
+// FormatValue formats a Cadence value for display.
+//
+// Fixed-point numbers are formatted as decimals without trailing zeros, addresses
+// are prefixed with "0x" and strings are quoted.
+func FormatValue(value cadence.Value) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case cadence.Optional:
+		if v.Value == nil {
+			return "nil"
+		}
+		return FormatValue(v.Value)
+	case cadence.UFix64:
+		return formatUFix64(uint64(v))
+	case cadence.Fix64:
+		if v < 0 {
+			return "-" + formatUFix64(uint64(-int64(v)))
+		}
+		return formatUFix64(uint64(v))
+	case cadence.Address:
+		return "0x" + v.Hex()
+	case cadence.String:
+		return strconv.Quote(string(v))
+	case cadence.Array:
+		values := make([]string, len(v.Values))
+		for i, elem := range v.Values {
+			values[i] = FormatValue(elem)
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case cadence.Dictionary:
+		pairs := make([]string, len(v.Pairs))
+		for i, pair := range v.Pairs {
+			pairs[i] = FormatValue(pair.Key) + ": " + FormatValue(pair.Value)
+		}
+		sort.Strings(pairs)
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+
+	switch v := value.ToGoValue().(type) {
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fix64Scale is the number of decimal places of Cadence fixed-point numbers.
+const fix64Scale = 8
+
+func formatUFix64(v uint64) string {
+	s := strconv.FormatUint(v, 10)
+	if len(s) <= fix64Scale {
+		s = strings.Repeat("0", fix64Scale-len(s)+1) + s
+	}
+
+	integer, fraction := s[:len(s)-fix64Scale], strings.TrimRight(s[len(s)-fix64Scale:], "0")
+	if fraction == "" {
+		fraction = "0"
+	}
+
+	return integer + "." + fraction
+}
+
+// JSON returns the JSON encoding of this preview.
+func (p Preview) JSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// String renders this preview as plain text.
+func (p Preview) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Transaction %s\n", p.ID)
+
+	name := p.Script.Name
+	if name == "" {
+		name = "unknown script"
+	}
+	fmt.Fprintf(&sb, "\nScript: %s (%d lines, hash %s)\n", name, p.Script.Lines, p.Script.Hash)
+	for _, imp := range p.Script.Imports {
+		fmt.Fprintf(&sb, "  imports %s\n", imp)
+	}
+
+	if len(p.Arguments) > 0 {
+		sb.WriteString("\nArguments:\n")
+		for i, arg := range p.Arguments {
+			label := arg.Name
+			if label == "" {
+				label = fmt.Sprintf("#%d", i)
+			}
+			fmt.Fprintf(&sb, "  %s (%s): %s\n", label, arg.Type, arg.Value)
+		}
+	}
+
+	sb.WriteString("\nSigners:\n")
+	for _, signer := range p.Signers {
+		status := "not signed"
+		if signer.Signed {
+			status = "signed"
+		}
+		fmt.Fprintf(&sb, "  %s [%s] %s\n", signer.Address, strings.Join(signer.Roles, ", "), status)
+	}
+
+	fmt.Fprintf(&sb, "\nGas limit: %d\n", p.GasLimit)
+	if p.FeeEstimate != "" {
+		fmt.Fprintf(&sb, "Estimated fee: %s\n", p.FeeEstimate)
+	}
+
+	return sb.String()
+}
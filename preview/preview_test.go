@@ -0,0 +1,129 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package preview_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/preview"
+)
+
+const transferScript = `import FungibleToken from 0xee82856bf20e2aa6
+import FlowToken from 0x0ae53cb6e3f42a79
+
+transaction(amount: UFix64, to: Address) {
+  prepare(signer: AuthAccount) {}
+}
+`
+
+func newTransfer(t *testing.T) *flow.Transaction {
+	payer := flow.HexToAddress("01")
+	sender := flow.HexToAddress("02")
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(transferScript)).
+		SetGasLimit(100).
+		SetProposalKey(sender, 0, 1).
+		SetPayer(payer).
+		AddAuthorizer(sender)
+
+	amount, err := cadence.NewUFix64("12.5")
+	require.NoError(t, err)
+
+	require.NoError(t, tx.AddArgument(amount))
+	require.NoError(t, tx.AddArgument(cadence.NewAddress(flow.HexToAddress("03"))))
+
+	tx.AddPayloadSignature(sender, 0, []byte{1})
+
+	return tx
+}
+
+func TestNew(t *testing.T) {
+	tx := newTransfer(t)
+
+	p, err := preview.New(*tx, preview.Options{Fees: preview.FlatFee(1000)})
+	require.NoError(t, err)
+
+	assert.Equal(t, tx.ID().Hex(), p.ID)
+	assert.Equal(t, 6, p.Script.Lines)
+	assert.Equal(t, []string{
+		"FungibleToken from 0xee82856bf20e2aa6",
+		"FlowToken from 0x0ae53cb6e3f42a79",
+	}, p.Script.Imports)
+
+	assert.Equal(t, []preview.Argument{
+		{Name: "amount", Type: "UFix64", Value: "12.5", TokenAmount: true},
+		{Name: "to", Type: "Address", Value: "0x0000000000000003"},
+	}, p.Arguments)
+
+	assert.Equal(t, []preview.Signer{
+		{
+			Address: "0x0000000000000002",
+			Roles:   []string{preview.RoleProposer, preview.RoleAuthorizer},
+			Signed:  true,
+		},
+		{
+			Address: "0x0000000000000001",
+			Roles:   []string{preview.RolePayer},
+		},
+	}, p.Signers)
+
+	assert.Equal(t, "0.00001", p.FeeEstimate)
+}
+
+func TestPreview_Render(t *testing.T) {
+	tx := newTransfer(t)
+
+	p, err := preview.New(*tx, preview.Options{})
+	require.NoError(t, err)
+
+	known, err := preview.New(*tx, preview.Options{
+		KnownScripts: map[string]string{p.Script.Hash: "Transfer FLOW"},
+	})
+	require.NoError(t, err)
+
+	text := known.String()
+	assert.Contains(t, text, "Script: Transfer FLOW (6 lines")
+	assert.Contains(t, text, "  amount (UFix64): 12.5\n")
+	assert.Contains(t, text, "  0x0000000000000001 [payer] not signed\n")
+	assert.NotContains(t, text, "Estimated fee")
+
+	b, err := known.JSON()
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "Transfer FLOW", decoded["script"].(map[string]interface{})["name"])
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "-0.5", preview.FormatValue(cadence.Fix64(-50000000)))
+	assert.Equal(t, "0.0", preview.FormatValue(cadence.UFix64(0)))
+	assert.Equal(t, "1.00000001", preview.FormatValue(cadence.UFix64(100000001)))
+	assert.Equal(t, `"hi"`, preview.FormatValue(cadence.NewString("hi")))
+	assert.Equal(t, "42", preview.FormatValue(cadence.NewUInt64(42)))
+	assert.Equal(t, "[1, 2]", preview.FormatValue(cadence.NewArray([]cadence.Value{cadence.NewInt(1), cadence.NewInt(2)})))
+	assert.Equal(t, "nil", preview.FormatValue(cadence.NewOptional(nil)))
+}
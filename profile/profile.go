@@ -0,0 +1,426 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package profile measures the computation used by Cadence scripts and transactions,
+// broken down by source line.
+//
+// Scripts are executed in-process by the same Cadence interpreter that is embedded in
+// the Flow Emulator and execution nodes. Computation is metered with the same units
+// the runtime uses to enforce computation limits: one unit per executed statement,
+// loop iteration and function invocation.
+//
+// The profiler executes code against an empty environment: imports are not
+// available, and the authorizers of transactions are accounts with empty storage,
+// so it is intended for optimizing hot Cadence logic before it is deployed or
+// submitted.
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+	"github.com/onflow/cadence/runtime/trampoline"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// LineUsage is the computation attributed to a single source line.
+type LineUsage struct {
+	// Line is the 1-based line number in the script source.
+	Line int
+	// Source is the source code of the line, with surrounding whitespace removed.
+	Source string
+	// Statements is the number of statements executed on this line.
+	Statements uint64
+	// LoopIterations is the number of loop iterations started on this line.
+	LoopIterations uint64
+	// FunctionInvocations is the number of function invocations on this line.
+	FunctionInvocations uint64
+}
+
+// Computation returns the total computation used by this line.
+func (u LineUsage) Computation() uint64 {
+	return u.Statements + u.LoopIterations + u.FunctionInvocations
+}
+
+// A Report is the computation profile of a single script execution.
+type Report struct {
+	// Total is the total computation used by the script.
+	Total uint64
+	// Lines contains the usage of every line that used computation, ordered by line number.
+	Lines []LineUsage
+}
+
+// Hottest returns the n lines that used the most computation, in descending order.
+func (r Report) Hottest(n int) []LineUsage {
+	lines := make([]LineUsage, len(r.Lines))
+	copy(lines, r.Lines)
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Computation() > lines[j].Computation()
+	})
+
+	if n < len(lines) {
+		lines = lines[:n]
+	}
+
+	return lines
+}
+
+// String returns a human-readable table of this report.
+func (r Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "total computation: %d\n", r.Total)
+	fmt.Fprintf(&sb, "%6s %10s %10s %10s %10s  %s\n", "line", "total", "stmts", "loops", "calls", "source")
+
+	for _, line := range r.Lines {
+		fmt.Fprintf(
+			&sb,
+			"%6d %10d %10d %10d %10d  %s\n",
+			line.Line,
+			line.Computation(),
+			line.Statements,
+			line.LoopIterations,
+			line.FunctionInvocations,
+			line.Source,
+		)
+	}
+
+	return sb.String()
+}
+
+var location = runtime.ScriptLocation("profile")
+
+var valueDeclarations = append(
+	stdlib.FlowBuiltInFunctions(stdlib.FlowBuiltinImpls{}),
+	stdlib.BuiltinFunctions...,
+)
+
+var typeDeclarations = append(
+	stdlib.FlowBuiltInTypes,
+	stdlib.BuiltinTypes...,
+).ToTypeDeclarations()
+
+// Script executes the main function of a Cadence script with the given arguments
+// and returns its computation profile.
+//
+// An error is returned if the script cannot be parsed or checked, or if its
+// execution fails. The report is still returned for execution failures, and
+// contains the computation used up to the point of failure.
+func Script(code []byte, arguments []cadence.Value) (*Report, error) {
+	checker, err := check(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return execute(code, checker, arguments, nil, func(inter *interpreter.Interpreter, args []interpreter.Value) error {
+		_, err := inter.Invoke("main", args...)
+		return err
+	})
+}
+
+// Transaction executes a Cadence transaction with the given arguments, authorized
+// by the given accounts, and returns its computation profile.
+//
+// The authorizers start with empty storage, and what the transaction saves or links
+// is discarded once it completes. Account code and keys cannot be changed.
+//
+// An error is returned if the transaction cannot be parsed or checked, if it does
+// not declare a single transaction with one prepare parameter per authorizer, or if
+// its execution fails. As for scripts, the report is still returned for execution
+// failures.
+func Transaction(code []byte, arguments []cadence.Value, authorizers []flow.Address) (*Report, error) {
+	checker, err := check(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checker.TransactionTypes) != 1 {
+		return nil, fmt.Errorf("profile: script must declare a single transaction")
+	}
+
+	if prepare := len(checker.TransactionTypes[0].PrepareParameters); prepare != len(authorizers) {
+		return nil, fmt.Errorf("profile: transaction requires %d authorizers, got %d", prepare, len(authorizers))
+	}
+
+	storage := make(map[storageKey]interpreter.OptionalValue)
+
+	options := []interpreter.Option{
+		interpreter.WithStorageExistenceHandler(func(_ *interpreter.Interpreter, address common.Address, key string) bool {
+			_, ok := storage[storageKey{address, key}]
+			return ok
+		}),
+		interpreter.WithStorageReadHandler(
+			func(_ *interpreter.Interpreter, address common.Address, key string, _ bool) interpreter.OptionalValue {
+				value, ok := storage[storageKey{address, key}]
+				if !ok {
+					return interpreter.NilValue{}
+				}
+				return value
+			},
+		),
+		interpreter.WithStorageWriteHandler(
+			func(_ *interpreter.Interpreter, address common.Address, key string, value interpreter.OptionalValue) {
+				if _, ok := value.(interpreter.NilValue); ok {
+					delete(storage, storageKey{address, key})
+					return
+				}
+				storage[storageKey{address, key}] = value
+			},
+		),
+		interpreter.WithStorageKeyHandler(func(_ *interpreter.Interpreter, _ common.Address, indexingType sema.Type) string {
+			return string(indexingType.ID())
+		}),
+	}
+
+	return execute(code, checker, arguments, options, func(inter *interpreter.Interpreter, args []interpreter.Value) error {
+		for _, address := range authorizers {
+			args = append(args, interpreter.NewAuthAccountValue(
+				interpreter.NewAddressValueFromBytes(address.Bytes()),
+				unsupported,
+				unsupported,
+				unsupported,
+				unsupported,
+			))
+		}
+
+		return inter.InvokeTransaction(0, args...)
+	})
+}
+
+// storageKey is the key of a value stored in an account.
+type storageKey struct {
+	address common.Address
+	key     string
+}
+
+// unsupported is the implementation of the account functions that change the code
+// or keys of an authorizer.
+var unsupported = interpreter.NewHostFunctionValue(func(interpreter.Invocation) trampoline.Trampoline {
+	panic(fmt.Errorf("profile: account code and keys cannot be changed"))
+})
+
+// check parses and checks a script or transaction.
+func check(code []byte) (*sema.Checker, error) {
+	program, err := parser2.ParseProgram(string(code))
+	if err != nil {
+		return nil, fmt.Errorf("profile: failed to parse script: %w", err)
+	}
+
+	if len(program.ImportDeclarations()) > 0 {
+		return nil, fmt.Errorf("profile: scripts with imports are not supported")
+	}
+
+	checker, err := sema.NewChecker(
+		program,
+		location,
+		sema.WithPredeclaredValues(valueDeclarations.ToValueDeclarations()),
+		sema.WithPredeclaredTypes(typeDeclarations),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("profile: failed to create checker: %w", err)
+	}
+
+	err = checker.Check()
+	if err != nil {
+		return nil, fmt.Errorf("profile: failed to check script: %w", err)
+	}
+
+	return checker, nil
+}
+
+// execute interprets a checked program, calls invoke with the imported arguments and
+// returns the computation profile of the execution.
+func execute(
+	code []byte,
+	checker *sema.Checker,
+	arguments []cadence.Value,
+	options []interpreter.Option,
+	invoke func(inter *interpreter.Interpreter, args []interpreter.Value) error,
+) (*Report, error) {
+	args := make([]interpreter.Value, len(arguments))
+	for i, arg := range arguments {
+		var err error
+		args[i], err = importValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("profile: invalid argument at index %d: %w", i, err)
+		}
+	}
+
+	usage := make(map[int]*LineUsage)
+	lineUsage := func(line int) *LineUsage {
+		u, ok := usage[line]
+		if !ok {
+			u = &LineUsage{Line: line}
+			usage[line] = u
+		}
+		return u
+	}
+
+	var uuid uint64
+
+	options = append([]interpreter.Option{
+		interpreter.WithPredefinedValues(valueDeclarations.ToValues()),
+		interpreter.WithUUIDHandler(func() uint64 {
+			defer func() { uuid++ }()
+			return uuid
+		}),
+		interpreter.WithOnStatementHandler(func(statement *interpreter.Statement) {
+			lineUsage(statement.Line).Statements++
+		}),
+		interpreter.WithOnLoopIterationHandler(func(_ *interpreter.Interpreter, line int) {
+			lineUsage(line).LoopIterations++
+		}),
+		interpreter.WithOnFunctionInvocationHandler(func(_ *interpreter.Interpreter, line int) {
+			lineUsage(line).FunctionInvocations++
+		}),
+	}, options...)
+
+	inter, err := interpreter.NewInterpreter(checker, options...)
+	if err != nil {
+		return nil, fmt.Errorf("profile: failed to create interpreter: %w", err)
+	}
+
+	execErr := inter.Interpret()
+	if execErr == nil {
+		execErr = invoke(inter, args)
+	}
+
+	report := newReport(code, usage)
+
+	if execErr != nil {
+		return report, fmt.Errorf("profile: execution failed: %w", execErr)
+	}
+
+	return report, nil
+}
+
+func newReport(code []byte, usage map[int]*LineUsage) *Report {
+	sourceLines := strings.Split(string(code), "\n")
+
+	report := &Report{
+		Lines: make([]LineUsage, 0, len(usage)),
+	}
+
+	for line, u := range usage {
+		if line > 0 && line <= len(sourceLines) {
+			u.Source = strings.TrimSpace(sourceLines[line-1])
+		}
+
+		report.Total += u.Computation()
+		report.Lines = append(report.Lines, *u)
+	}
+
+	sort.Slice(report.Lines, func(i, j int) bool {
+		return report.Lines[i].Line < report.Lines[j].Line
+	})
+
+	return report
+}
+
+// importValue converts a Cadence argument to an interpreter value.
+//
+// Only the value kinds that can be passed as script arguments are supported.
+func importValue(value cadence.Value) (interpreter.Value, error) {
+	switch v := value.(type) {
+	case cadence.Void:
+		return interpreter.VoidValue{}, nil
+	case cadence.Optional:
+		if v.Value == nil {
+			return interpreter.NilValue{}, nil
+		}
+		inner, err := importValue(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		return interpreter.NewSomeValueOwningNonCopying(inner), nil
+	case cadence.Bool:
+		return interpreter.BoolValue(v), nil
+	case cadence.String:
+		return interpreter.NewStringValue(string(v)), nil
+	case cadence.Address:
+		return interpreter.NewAddressValueFromBytes(v.Bytes()), nil
+	case cadence.Int:
+		return interpreter.NewIntValueFromBigInt(v.Big()), nil
+	case cadence.Int8:
+		return interpreter.Int8Value(v), nil
+	case cadence.Int16:
+		return interpreter.Int16Value(v), nil
+	case cadence.Int32:
+		return interpreter.Int32Value(v), nil
+	case cadence.Int64:
+		return interpreter.Int64Value(v), nil
+	case cadence.UInt:
+		return interpreter.NewUIntValueFromBigInt(v.Big()), nil
+	case cadence.UInt8:
+		return interpreter.UInt8Value(v), nil
+	case cadence.UInt16:
+		return interpreter.UInt16Value(v), nil
+	case cadence.UInt32:
+		return interpreter.UInt32Value(v), nil
+	case cadence.UInt64:
+		return interpreter.UInt64Value(v), nil
+	case cadence.Word8:
+		return interpreter.Word8Value(v), nil
+	case cadence.Word16:
+		return interpreter.Word16Value(v), nil
+	case cadence.Word32:
+		return interpreter.Word32Value(v), nil
+	case cadence.Word64:
+		return interpreter.Word64Value(v), nil
+	case cadence.Fix64:
+		return interpreter.Fix64Value(v), nil
+	case cadence.UFix64:
+		return interpreter.UFix64Value(v), nil
+	case cadence.Array:
+		values := make([]interpreter.Value, len(v.Values))
+		for i, elem := range v.Values {
+			value, err := importValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return interpreter.NewArrayValueUnownedNonCopying(values...), nil
+	case cadence.Dictionary:
+		keysAndValues := make([]interpreter.Value, 0, len(v.Pairs)*2)
+		for _, pair := range v.Pairs {
+			key, err := importValue(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := importValue(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			keysAndValues = append(keysAndValues, key, value)
+		}
+		return interpreter.NewDictionaryValueUnownedNonCopying(keysAndValues...), nil
+	}
+
+	return nil, fmt.Errorf("unsupported argument type %T", value)
+}
@@ -0,0 +1,146 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/profile"
+)
+
+const sumScript = `
+pub fun main(n: Int): Int {
+  var sum = 0
+  var i = 0
+  while i < n {
+    sum = sum + i
+    i = i + 1
+  }
+  return sum
+}
+`
+
+const counterTransaction = `
+transaction(n: Int) {
+  prepare(signer: AuthAccount) {
+    var i = 0
+    while i < n {
+      signer.save(i, to: /storage/counter)
+      i = signer.load<Int>(from: /storage/counter)! + 1
+    }
+  }
+}
+`
+
+func TestScript(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		report, err := profile.Script([]byte(sumScript), []cadence.Value{cadence.NewInt(10)})
+		require.NoError(t, err)
+
+		lines := make(map[int]profile.LineUsage)
+		var total uint64
+		for _, line := range report.Lines {
+			lines[line.Line] = line
+			total += line.Computation()
+		}
+
+		assert.Equal(t, total, report.Total)
+		assert.Equal(t, uint64(10), lines[5].LoopIterations)
+		assert.Equal(t, uint64(10), lines[6].Statements)
+		assert.Equal(t, uint64(10), lines[7].Statements)
+		assert.Equal(t, "sum = sum + i", lines[6].Source)
+
+		hottest := report.Hottest(1)
+		require.Len(t, hottest, 1)
+		assert.Equal(t, 5, hottest[0].Line)
+		assert.Equal(t, uint64(11), hottest[0].Computation())
+	})
+
+	t.Run("Scales with input", func(t *testing.T) {
+		small, err := profile.Script([]byte(sumScript), []cadence.Value{cadence.NewInt(10)})
+		require.NoError(t, err)
+
+		large, err := profile.Script([]byte(sumScript), []cadence.Value{cadence.NewInt(100)})
+		require.NoError(t, err)
+
+		assert.Equal(t, small.Total+90*3, large.Total)
+	})
+
+	t.Run("Execution failure", func(t *testing.T) {
+		report, err := profile.Script([]byte(`pub fun main() { panic("boom") }`), nil)
+		assert.Error(t, err)
+		require.NotNil(t, report)
+	})
+
+	t.Run("Invalid script", func(t *testing.T) {
+		_, err := profile.Script([]byte(`pub fun main() { let x: Int = "" }`), nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Imports not supported", func(t *testing.T) {
+		_, err := profile.Script([]byte("import Foo from 0x01\npub fun main() {}"), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	authorizers := []flow.Address{flow.HexToAddress("01")}
+
+	t.Run("Success", func(t *testing.T) {
+		report, err := profile.Transaction([]byte(counterTransaction), []cadence.Value{cadence.NewInt(10)}, authorizers)
+		require.NoError(t, err)
+
+		lines := make(map[int]profile.LineUsage)
+		for _, line := range report.Lines {
+			lines[line.Line] = line
+		}
+
+		assert.Equal(t, uint64(10), lines[5].LoopIterations)
+		assert.Equal(t, uint64(10), lines[6].Statements)
+		assert.Equal(t, uint64(10), lines[7].Statements)
+		assert.Equal(t, "signer.save(i, to: /storage/counter)", lines[6].Source)
+	})
+
+	t.Run("Authorizer count", func(t *testing.T) {
+		_, err := profile.Transaction([]byte(counterTransaction), []cadence.Value{cadence.NewInt(1)}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Not a transaction", func(t *testing.T) {
+		_, err := profile.Transaction([]byte(sumScript), []cadence.Value{cadence.NewInt(1)}, authorizers)
+		assert.Error(t, err)
+	})
+
+	t.Run("Account keys", func(t *testing.T) {
+		report, err := profile.Transaction([]byte(`
+transaction {
+  prepare(signer: AuthAccount) {
+    signer.removePublicKey(0)
+  }
+}`), nil, authorizers)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be changed")
+		require.NotNil(t, report)
+	})
+}
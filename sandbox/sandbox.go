@@ -0,0 +1,349 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sandbox bootstraps a development environment on the Flow emulator: the
+// contracts an application depends on, and funded test accounts.
+//
+//	env, err := sandbox.Bootstrap(ctx, flowClient, sandbox.Config{
+//		ServiceKey: serviceKey,
+//		Contracts: append(sandbox.EmulatorContracts(),
+//			sandbox.Contract{Name: "NonFungibleToken", Code: nftCode},
+//			sandbox.Contract{Name: "MetadataViews", Code: metadataViewsCode},
+//		),
+//		Accounts: 2,
+//		Funding:  funding,
+//	})
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/templates"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+const (
+	// gasLimit is the gas limit of bootstrap transactions.
+	gasLimit = 9999
+	// pollInterval is the default time between two status requests of clients other
+	// than a *client.Client waiting for a transaction to be sealed.
+	pollInterval = time.Second
+)
+
+// A Contract is a contract that the environment depends on.
+type Contract struct {
+	// Name is the name of the contract.
+	Name string
+	// Address is the address the contract may already be deployed at, e.g. for the
+	// contracts the emulator deploys on startup.
+	Address flow.Address
+	// Code is the code of the contract, deployed to a new account if the contract
+	// is not found at Address.
+	//
+	// Imports of contracts listed before this one are replaced with their address,
+	// whatever their location in the code, e.g. "./FungibleToken.cdc".
+	Code []byte
+}
+
+// EmulatorContracts returns the standard contracts that the emulator deploys on
+// startup: FungibleToken and FlowToken.
+func EmulatorContracts() []Contract {
+	token, _ := transfer.FlowToken(flow.Emulator)
+
+	return []Contract{
+		{Name: "FungibleToken", Address: token.FungibleTokenAddress},
+		{Name: token.Contract, Address: token.Address},
+	}
+}
+
+// Config configures Bootstrap.
+type Config struct {
+	// ServiceKey is the private key of the service account of the emulator.
+	ServiceKey crypto.PrivateKey
+	// ServiceHashAlgo is the hash algorithm of the service account key.
+	//
+	// Defaults to SHA3_256.
+	ServiceHashAlgo crypto.HashAlgorithm
+	// Contracts are the contracts to deploy if they are absent, in order.
+	Contracts []Contract
+	// Accounts is the number of test accounts to create.
+	Accounts int
+	// Funding is the amount of FLOW the service account sends to each test account.
+	Funding cadence.UFix64
+	// Submit configures how bootstrap transactions are sent.
+	//
+	// Transactions are sent with SubmitTransaction if the client is a *client.Client.
+	// Other clients send them once, and only use the poll interval of Consistency.
+	Submit client.SubmitConfig
+}
+
+// An Account is a test account and its key.
+type Account struct {
+	Address    flow.Address
+	Key        *flow.AccountKey
+	PrivateKey crypto.PrivateKey
+	Signer     crypto.Signer
+}
+
+// An Environment is a bootstrapped emulator environment.
+type Environment struct {
+	Client flow.Client
+	// Service is the service account of the emulator.
+	Service Account
+	// Contracts are the addresses of the configured contracts, by name.
+	Contracts map[string]flow.Address
+	// Accounts are the test accounts.
+	Accounts []Account
+}
+
+// Bootstrap deploys the configured contracts that are absent from the emulator,
+// creates the test accounts, each with a new key of full weight, and funds them.
+func Bootstrap(ctx context.Context, c flow.Client, config Config) (*Environment, error) {
+	if config.ServiceHashAlgo == crypto.UnknownHashAlgorithm {
+		config.ServiceHashAlgo = crypto.SHA3_256
+	}
+
+	env := &Environment{
+		Client: c,
+		Service: Account{
+			Address:    flow.ServiceAddress(flow.Emulator),
+			PrivateKey: config.ServiceKey,
+			Signer:     crypto.NewInMemorySigner(config.ServiceKey, config.ServiceHashAlgo),
+		},
+		Contracts: make(map[string]flow.Address, len(config.Contracts)),
+	}
+
+	b := &bootstrapper{env: env, config: config}
+
+	for _, contract := range config.Contracts {
+		address, err := b.deploy(ctx, contract)
+		if err != nil {
+			return nil, err
+		}
+		env.Contracts[contract.Name] = address
+	}
+
+	for i := 0; i < config.Accounts; i++ {
+		account, err := b.createAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		env.Accounts = append(env.Accounts, account)
+	}
+
+	return env, nil
+}
+
+type bootstrapper struct {
+	env    *Environment
+	config Config
+}
+
+// deploy deploys a contract to a new account, unless it is found at its address.
+func (b *bootstrapper) deploy(ctx context.Context, contract Contract) (flow.Address, error) {
+	if contract.Address != flow.EmptyAddress {
+		account, err := b.env.Client.GetAccountAtLatestBlock(ctx, contract.Address)
+		if err == nil && declaresContract(account.Code, contract.Name) {
+			return contract.Address, nil
+		}
+	}
+
+	if len(contract.Code) == 0 {
+		return flow.EmptyAddress, fmt.Errorf("sandbox: contract %s is absent and has no code", contract.Name)
+	}
+
+	code := contract.Code
+	for name, address := range b.env.Contracts {
+		code = replaceImport(code, name, address)
+	}
+
+	address, err := b.createAccountWith(ctx, nil, code)
+	if err != nil {
+		return flow.EmptyAddress, fmt.Errorf("sandbox: failed to deploy contract %s: %w", contract.Name, err)
+	}
+
+	return address, nil
+}
+
+// createAccount creates and funds a test account.
+func (b *bootstrapper) createAccount(ctx context.Context) (Account, error) {
+	privateKey, err := crypto.GenerateRandomPrivateKey(crypto.ECDSA_P256)
+	if err != nil {
+		return Account{}, err
+	}
+
+	key := flow.NewAccountKey().
+		FromPrivateKey(privateKey).
+		SetHashAlgo(crypto.SHA3_256).
+		SetWeight(flow.AccountKeyWeightThreshold)
+
+	address, err := b.createAccountWith(ctx, []*flow.AccountKey{key}, nil)
+	if err != nil {
+		return Account{}, fmt.Errorf("sandbox: failed to create account: %w", err)
+	}
+
+	if b.config.Funding > 0 {
+		token, err := transfer.FlowToken(flow.Emulator)
+		if err != nil {
+			return Account{}, err
+		}
+
+		tx, err := transfer.TransferTokens(token, b.config.Funding, address, b.env.Service.Address)
+		if err != nil {
+			return Account{}, err
+		}
+
+		if _, err := b.send(ctx, tx); err != nil {
+			return Account{}, fmt.Errorf("sandbox: failed to fund account %s: %w", address, err)
+		}
+	}
+
+	return Account{
+		Address:    address,
+		Key:        key,
+		PrivateKey: privateKey,
+		Signer:     crypto.NewInMemorySigner(privateKey, key.HashAlgo),
+	}, nil
+}
+
+func (b *bootstrapper) createAccountWith(ctx context.Context, keys []*flow.AccountKey, code []byte) (flow.Address, error) {
+	result, err := b.send(ctx, templates.CreateAccount(keys, code, b.env.Service.Address))
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	for _, event := range result.Events {
+		if event.Type == flow.EventAccountCreated {
+			return flow.AccountCreatedEvent(event).Address(), nil
+		}
+	}
+
+	return flow.EmptyAddress, fmt.Errorf("sandbox: no %s event", flow.EventAccountCreated)
+}
+
+// send proposes, pays for and authorizes a transaction with the service account,
+// and waits for it to be sealed.
+func (b *bootstrapper) send(ctx context.Context, tx *flow.Transaction) (*flow.TransactionResult, error) {
+	c := b.env.Client
+	service := &b.env.Service
+
+	account, err := c.GetAccountAtLatestBlock(ctx, service.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := service.PrivateKey.PublicKey().Encode()
+	for _, key := range account.Keys {
+		if bytes.Equal(key.PublicKey.Encode(), publicKey) && !key.Revoked {
+			service.Key = key
+		}
+	}
+
+	if service.Key == nil {
+		return nil, fmt.Errorf("sandbox: service account has no key for the service private key")
+	}
+
+	header, err := c.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.SetReferenceBlockID(header.ID).
+		SetGasLimit(gasLimit).
+		SetProposalKey(service.Address, service.Key.Index, service.Key.SequenceNumber).
+		SetPayer(service.Address)
+
+	if err := tx.SignEnvelope(service.Address, service.Key.Index, service.Signer); err != nil {
+		return nil, err
+	}
+
+	result, err := b.submit(ctx, *tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status == flow.TransactionStatusExpired {
+		return nil, fmt.Errorf("sandbox: transaction %s expired", tx.ID())
+	}
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return result, nil
+}
+
+// submit sends a transaction and waits for it to be sealed or to expire.
+func (b *bootstrapper) submit(ctx context.Context, tx flow.Transaction) (*flow.TransactionResult, error) {
+	if c, ok := b.env.Client.(*client.Client); ok {
+		res, err := c.SubmitTransaction(ctx, tx, b.config.Submit)
+		if err != nil {
+			return nil, err
+		}
+		return res.Result, nil
+	}
+
+	if err := b.env.Client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	interval := b.config.Submit.Consistency.PollInterval
+	if interval <= 0 {
+		interval = pollInterval
+	}
+
+	for {
+		result, err := b.env.Client.GetTransactionResult(ctx, tx.ID())
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Status == flow.TransactionStatusSealed || result.Status == flow.TransactionStatusExpired {
+			return result, nil
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// declaresContract returns true if the code declares the contract or contract
+// interface with the given name.
+func declaresContract(code []byte, name string) bool {
+	pattern := regexp.MustCompile(`\bcontract\s+(interface\s+)?` + regexp.QuoteMeta(name) + `\b`)
+	return pattern.Match(code)
+}
+
+// replaceImport replaces the location of the imports of the named contract with
+// its address.
+func replaceImport(code []byte, name string, address flow.Address) []byte {
+	pattern := regexp.MustCompile(`\bimport\s+` + regexp.QuoteMeta(name) + `\s+from\s+("[^"]*"|0x[0-9a-fA-F]+)`)
+	return pattern.ReplaceAll(code, []byte("import "+name+" from 0x"+address.Hex()))
+}
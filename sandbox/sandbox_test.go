@@ -0,0 +1,249 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sandbox_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/sandbox"
+)
+
+// emulator is an Access API node that executes account creations and records
+// other transactions.
+type emulator struct {
+	t        *testing.T
+	accounts map[flow.Address]*flow.Account
+	results  map[flow.Identifier]flow.TransactionResult
+	sent     []flow.Transaction
+}
+
+func newEmulator(t *testing.T, serviceKey crypto.PrivateKey) *emulator {
+	e := &emulator{
+		t:        t,
+		accounts: make(map[flow.Address]*flow.Account),
+		results:  make(map[flow.Identifier]flow.TransactionResult),
+	}
+
+	service := flow.ServiceAddress(flow.Emulator)
+	e.accounts[service] = &flow.Account{
+		Address: service,
+		Keys: []*flow.AccountKey{
+			flow.NewAccountKey().
+				FromPrivateKey(serviceKey).
+				SetHashAlgo(crypto.SHA3_256).
+				SetWeight(flow.AccountKeyWeightThreshold),
+		},
+	}
+
+	for _, contract := range sandbox.EmulatorContracts() {
+		e.accounts[contract.Address] = &flow.Account{
+			Address: contract.Address,
+			Code:    []byte(fmt.Sprintf("pub contract %s {}", contract.Name)),
+		}
+	}
+
+	return e
+}
+
+func (e *emulator) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	switch method {
+	case client.MethodGetAccountAtLatestBlock:
+		address := flow.BytesToAddress(args.(*access.GetAccountAtLatestBlockRequest).Address)
+		account, ok := e.accounts[address]
+		if !ok {
+			return fmt.Errorf("account %s not found", address)
+		}
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(*account)
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Id: make([]byte, 32), Height: 1}
+	case client.MethodSendTransaction:
+		tx, err := convert.MessageToTransaction(args.(*access.SendTransactionRequest).Transaction)
+		require.NoError(e.t, err)
+		e.execute(tx)
+		reply.(*access.SendTransactionResponse).Id = tx.ID().Bytes()
+	case client.MethodGetTransactionResult:
+		id := flow.HashToID(args.(*access.GetTransactionRequest).Id)
+		res, err := convert.TransactionResultToMessage(e.results[id])
+		require.NoError(e.t, err)
+		*reply.(*access.TransactionResultResponse) = *res
+	}
+
+	return nil
+}
+
+func (e *emulator) execute(tx flow.Transaction) {
+	e.sent = append(e.sent, tx)
+	e.accounts[tx.Payer].Keys[0].SequenceNumber++
+
+	result := flow.TransactionResult{Status: flow.TransactionStatusSealed}
+
+	if bytes.Contains(tx.Script, []byte("AuthAccount(payer")) {
+		account := &flow.Account{
+			Address: flow.HexToAddress(fmt.Sprintf("%x", 0x100+len(e.accounts))),
+			Code:    decodeBytes(e.t, tx.Arguments[1]),
+		}
+
+		value, err := jsoncdc.Decode(tx.Arguments[0])
+		require.NoError(e.t, err)
+
+		for _, encoded := range value.(cadence.Array).Values {
+			key, err := flow.DecodeAccountKey(decodeBytes(e.t, jsoncdc.MustEncode(encoded)))
+			require.NoError(e.t, err)
+			account.Keys = append(account.Keys, key)
+		}
+
+		e.accounts[account.Address] = account
+
+		eventType := &cadence.EventType{
+			TypeID:     flow.EventAccountCreated,
+			Identifier: flow.EventAccountCreated,
+			Fields:     []cadence.Field{{Identifier: "address", Type: cadence.AddressType{}}},
+		}
+
+		result.Events = []flow.Event{{
+			Type:          flow.EventAccountCreated,
+			TransactionID: tx.ID(),
+			Value:         cadence.NewEvent([]cadence.Value{cadence.NewAddress(account.Address)}).WithType(eventType),
+		}}
+	}
+
+	e.results[tx.ID()] = result
+}
+
+func decodeBytes(t *testing.T, arg []byte) []byte {
+	value, err := jsoncdc.Decode(arg)
+	require.NoError(t, err)
+
+	values := value.(cadence.Array).Values
+	b := make([]byte, len(values))
+	for i, v := range values {
+		b[i] = byte(v.(cadence.UInt8))
+	}
+	return b
+}
+
+func TestBootstrap(t *testing.T) {
+	serviceKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{1}, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	node := newEmulator(t, serviceKey)
+	c := client.NewFromEndpoint(client.NewEndpoint("emulator", node))
+
+	funding, err := cadence.NewUFix64("10.0")
+	require.NoError(t, err)
+
+	env, err := sandbox.Bootstrap(context.Background(), c, sandbox.Config{
+		ServiceKey: serviceKey,
+		Contracts: append(sandbox.EmulatorContracts(),
+			sandbox.Contract{
+				Name: "NonFungibleToken",
+				Code: []byte("pub contract interface NonFungibleToken {}"),
+			},
+			sandbox.Contract{
+				Name: "MetadataViews",
+				Code: []byte(`import FungibleToken from "./FungibleToken.cdc"
+import NonFungibleToken from 0x01
+
+pub contract MetadataViews {}`),
+			},
+		),
+		Accounts: 2,
+		Funding:  funding,
+		Submit: client.SubmitConfig{
+			Consistency: client.ConsistencyConfig{PollInterval: time.Millisecond},
+		},
+	})
+	require.NoError(t, err)
+
+	emulatorContracts := sandbox.EmulatorContracts()
+	assert.Equal(t, emulatorContracts[0].Address, env.Contracts["FungibleToken"])
+	assert.Equal(t, emulatorContracts[1].Address, env.Contracts["FlowToken"])
+
+	nft := env.Contracts["NonFungibleToken"]
+	require.Contains(t, node.accounts, nft)
+
+	metadataViews := node.accounts[env.Contracts["MetadataViews"]]
+	require.NotNil(t, metadataViews)
+	assert.Equal(t, fmt.Sprintf(`import FungibleToken from 0x%s
+import NonFungibleToken from 0x%s
+
+pub contract MetadataViews {}`, emulatorContracts[0].Address.Hex(), nft.Hex()), string(metadataViews.Code))
+
+	require.Len(t, env.Accounts, 2)
+	for _, account := range env.Accounts {
+		created := node.accounts[account.Address]
+		require.NotNil(t, created)
+		assert.Equal(t, account.PrivateKey.PublicKey().Encode(), created.Keys[0].PublicKey.Encode())
+	}
+
+	// 2 contracts deployed, 2 accounts created and funded
+	require.Len(t, node.sent, 6)
+	assert.True(t, strings.Contains(string(node.sent[3].Script), "FlowToken"))
+	assert.Equal(t, uint64(6), node.accounts[env.Service.Address].Keys[0].SequenceNumber)
+}
+
+// wrappedClient hides the concrete type of a client.
+type wrappedClient struct {
+	flow.Client
+}
+
+func TestBootstrap_Client(t *testing.T) {
+	serviceKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, bytes.Repeat([]byte{1}, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	node := newEmulator(t, serviceKey)
+	c := wrappedClient{client.NewFromEndpoint(client.NewEndpoint("emulator", node))}
+
+	env, err := sandbox.Bootstrap(context.Background(), c, sandbox.Config{
+		ServiceKey: serviceKey,
+		Contracts:  sandbox.EmulatorContracts(),
+		Accounts:   1,
+		Submit: client.SubmitConfig{
+			Consistency: client.ConsistencyConfig{PollInterval: time.Millisecond},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, env.Accounts, 1)
+	assert.NotNil(t, node.accounts[env.Accounts[0].Address])
+	assert.Len(t, node.sent, 1)
+}
@@ -0,0 +1,82 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// A TokenBucket is a limiter that allows a sustained rate of calls with bursts.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+var _ Limiter = &TokenBucket{}
+
+// NewTokenBucket returns a limiter that allows rate calls per second on average and
+// up to burst calls at once.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow takes a token from the bucket, if one is available.
+func (b *TokenBucket) Allow(context.Context, string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// RetryAfter returns the time until a token is available.
+func (b *TokenBucket) RetryAfter(context.Context, string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tokens := b.tokens + time.Since(b.last).Seconds()*b.rate
+	if tokens >= 1 || b.rate <= 0 {
+		return 0
+	}
+
+	return time.Duration((1 - tokens) / b.rate * float64(time.Second))
+}
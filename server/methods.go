@@ -0,0 +1,187 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+func (p *Proxy) Ping(ctx context.Context, req *access.PingRequest) (*access.PingResponse, error) {
+	res := new(access.PingResponse)
+	if err := p.call(ctx, client.MethodPing, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetLatestBlockHeader(ctx context.Context, req *access.GetLatestBlockHeaderRequest) (*access.BlockHeaderResponse, error) {
+	res := new(access.BlockHeaderResponse)
+	if err := p.call(ctx, client.MethodGetLatestBlockHeader, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetBlockHeaderByID(ctx context.Context, req *access.GetBlockHeaderByIDRequest) (*access.BlockHeaderResponse, error) {
+	res := new(access.BlockHeaderResponse)
+	if err := p.call(ctx, client.MethodGetBlockHeaderByID, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetBlockHeaderByHeight(ctx context.Context, req *access.GetBlockHeaderByHeightRequest) (*access.BlockHeaderResponse, error) {
+	res := new(access.BlockHeaderResponse)
+	if err := p.call(ctx, client.MethodGetBlockHeaderByHeight, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetLatestBlock(ctx context.Context, req *access.GetLatestBlockRequest) (*access.BlockResponse, error) {
+	res := new(access.BlockResponse)
+	if err := p.call(ctx, client.MethodGetLatestBlock, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetBlockByID(ctx context.Context, req *access.GetBlockByIDRequest) (*access.BlockResponse, error) {
+	res := new(access.BlockResponse)
+	if err := p.call(ctx, client.MethodGetBlockByID, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetBlockByHeight(ctx context.Context, req *access.GetBlockByHeightRequest) (*access.BlockResponse, error) {
+	res := new(access.BlockResponse)
+	if err := p.call(ctx, client.MethodGetBlockByHeight, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetCollectionByID(ctx context.Context, req *access.GetCollectionByIDRequest) (*access.CollectionResponse, error) {
+	res := new(access.CollectionResponse)
+	if err := p.call(ctx, client.MethodGetCollectionByID, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) SendTransaction(ctx context.Context, req *access.SendTransactionRequest) (*access.SendTransactionResponse, error) {
+	res := new(access.SendTransactionResponse)
+	if err := p.call(ctx, client.MethodSendTransaction, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetTransaction(ctx context.Context, req *access.GetTransactionRequest) (*access.TransactionResponse, error) {
+	res := new(access.TransactionResponse)
+	if err := p.call(ctx, client.MethodGetTransaction, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetTransactionResult(ctx context.Context, req *access.GetTransactionRequest) (*access.TransactionResultResponse, error) {
+	res := new(access.TransactionResultResponse)
+	if err := p.call(ctx, client.MethodGetTransactionResult, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetAccount(ctx context.Context, req *access.GetAccountRequest) (*access.GetAccountResponse, error) {
+	res := new(access.GetAccountResponse)
+	if err := p.call(ctx, client.MethodGetAccount, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetAccountAtLatestBlock(ctx context.Context, req *access.GetAccountAtLatestBlockRequest) (*access.AccountResponse, error) {
+	res := new(access.AccountResponse)
+	if err := p.call(ctx, client.MethodGetAccountAtLatestBlock, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetAccountAtBlockHeight(ctx context.Context, req *access.GetAccountAtBlockHeightRequest) (*access.AccountResponse, error) {
+	res := new(access.AccountResponse)
+	if err := p.call(ctx, client.MethodGetAccountAtBlockHeight, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) ExecuteScriptAtLatestBlock(ctx context.Context, req *access.ExecuteScriptAtLatestBlockRequest) (*access.ExecuteScriptResponse, error) {
+	res := new(access.ExecuteScriptResponse)
+	if err := p.call(ctx, client.MethodExecuteScriptAtLatestBlock, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) ExecuteScriptAtBlockID(ctx context.Context, req *access.ExecuteScriptAtBlockIDRequest) (*access.ExecuteScriptResponse, error) {
+	res := new(access.ExecuteScriptResponse)
+	if err := p.call(ctx, client.MethodExecuteScriptAtBlockID, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) ExecuteScriptAtBlockHeight(ctx context.Context, req *access.ExecuteScriptAtBlockHeightRequest) (*access.ExecuteScriptResponse, error) {
+	res := new(access.ExecuteScriptResponse)
+	if err := p.call(ctx, client.MethodExecuteScriptAtBlockHeight, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetEventsForHeightRange(ctx context.Context, req *access.GetEventsForHeightRangeRequest) (*access.EventsResponse, error) {
+	res := new(access.EventsResponse)
+	if err := p.call(ctx, client.MethodGetEventsForHeightRange, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetEventsForBlockIDs(ctx context.Context, req *access.GetEventsForBlockIDsRequest) (*access.EventsResponse, error) {
+	res := new(access.EventsResponse)
+	if err := p.call(ctx, client.MethodGetEventsForBlockIDs, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (p *Proxy) GetNetworkParameters(ctx context.Context, req *access.GetNetworkParametersRequest) (*access.GetNetworkParametersResponse, error) {
+	res := new(access.GetNetworkParametersResponse)
+	if err := p.call(ctx, client.MethodGetNetworkParameters, req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,282 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package server provides an Access API gRPC server that proxies calls to upstream
+// Access nodes, caching immutable responses, rate limiting calls and reporting
+// metrics.
+//
+// A caching gateway over a set of Access nodes can be built entirely from this SDK:
+//
+//	endpoints, err := client.DialEndpoints(addrs, grpc.WithInsecure())
+//	router := client.NewScoringRouter(endpoints, client.ScoringConfig{})
+//
+//	proxy := server.NewProxy(client.NewRouterInvoker(router), server.Config{
+//		Limiter: server.NewTokenBucket(100, 200),
+//	})
+//
+//	s := grpc.NewServer()
+//	proxy.Register(s)
+//	err = s.Serve(lis)
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+)
+
+// Config configures a Proxy.
+type Config struct {
+	// CacheSize is the maximum number of cached responses.
+	//
+	// Defaults to 10000. A negative size disables caching.
+	CacheSize int
+	// CacheTTL is the time a response is cached.
+	//
+	// Defaults to 10 minutes.
+	CacheTTL time.Duration
+	// Limiter limits the calls served by the proxy. If nil, calls are not limited.
+	Limiter Limiter
+	// Metrics observes the calls served by the proxy. If nil, calls are not observed.
+	Metrics Metrics
+	// MaxInFlight is the maximum number of calls forwarded upstream at once. Calls
+	// over the limit are rejected rather than queued, so that clients can back off.
+	//
+	// If zero, the number of calls in flight is not limited.
+	MaxInFlight int
+}
+
+const (
+	defaultCacheSize = 10000
+	defaultCacheTTL  = 10 * time.Minute
+
+	// inFlightRetryAfter is the delay suggested to clients rejected because too many
+	// calls are in flight.
+	inFlightRetryAfter = 100 * time.Millisecond
+)
+
+// A Limiter decides whether a call may be served.
+type Limiter interface {
+	// Allow reports whether a call to the given method may be served now.
+	Allow(ctx context.Context, method string) bool
+}
+
+// A RetryAfterLimiter is a limiter that suggests when rejected calls can be retried.
+//
+// The suggested delay is sent to clients with the rejection, see client.RetryAfter.
+type RetryAfterLimiter interface {
+	Limiter
+	// RetryAfter returns the time after which a call to the given method is
+	// expected to be allowed.
+	RetryAfter(ctx context.Context, method string) time.Duration
+}
+
+// Metrics observes the calls served by a proxy.
+type Metrics interface {
+	// ObserveCall is called once for each call, after it is served or rejected.
+	ObserveCall(method string, duration time.Duration, cached bool, err error)
+}
+
+// InFlightMetrics are metrics that also observe the number of calls forwarded
+// upstream at once. Metrics implementing it are notified each time the number
+// changes.
+type InFlightMetrics interface {
+	Metrics
+	ObserveInFlight(inFlight int)
+}
+
+// cacheable reports whether the response to a call can be cached.
+//
+// Only responses that can never change are cached: entities requested by ID, and
+// state at a given height.
+var cacheable = map[string]func(reply proto.Message) bool{
+	client.MethodGetBlockHeaderByID:         always,
+	client.MethodGetBlockHeaderByHeight:     always,
+	client.MethodGetBlockByID:               always,
+	client.MethodGetBlockByHeight:           always,
+	client.MethodGetCollectionByID:          always,
+	client.MethodGetTransaction:             always,
+	client.MethodGetAccountAtBlockHeight:    always,
+	client.MethodExecuteScriptAtBlockID:     always,
+	client.MethodExecuteScriptAtBlockHeight: always,
+	client.MethodGetEventsForBlockIDs:       always,
+	client.MethodGetTransactionResult: func(reply proto.Message) bool {
+		switch reply.(*access.TransactionResultResponse).GetStatus() {
+		case entities.TransactionStatus_SEALED, entities.TransactionStatus_EXPIRED:
+			return true
+		}
+		return false
+	},
+}
+
+func always(proto.Message) bool { return true }
+
+// A Proxy is an Access API server that forwards calls to an upstream invoker.
+type Proxy struct {
+	upstream client.Invoker
+	config   Config
+	cache    *cache
+	inFlight int32
+}
+
+var _ access.AccessAPIServer = &Proxy{}
+
+// NewProxy returns a proxy that forwards calls to the given upstream invoker, e.g. a
+// connection to an Access node or a router over several nodes.
+func NewProxy(upstream client.Invoker, config Config) *Proxy {
+	if config.CacheSize == 0 {
+		config.CacheSize = defaultCacheSize
+	}
+
+	if config.CacheTTL == 0 {
+		config.CacheTTL = defaultCacheTTL
+	}
+
+	return &Proxy{
+		upstream: upstream,
+		config:   config,
+		cache:    newCache(config.CacheSize, config.CacheTTL),
+	}
+}
+
+// Register registers the proxy as the Access API service of a gRPC server.
+func (p *Proxy) Register(s *grpc.Server) {
+	access.RegisterAccessAPIServer(s, p)
+}
+
+// call serves a single call, from the cache if possible.
+func (p *Proxy) call(ctx context.Context, method string, req, reply proto.Message) (err error) {
+	start := time.Now()
+	cached := false
+
+	if p.config.Metrics != nil {
+		defer func() {
+			p.config.Metrics.ObserveCall(method, time.Since(start), cached, err)
+		}()
+	}
+
+	if p.config.Limiter != nil && !p.config.Limiter.Allow(ctx, method) {
+		var retryAfter time.Duration
+		if limiter, ok := p.config.Limiter.(RetryAfterLimiter); ok {
+			retryAfter = limiter.RetryAfter(ctx, method)
+		}
+
+		return backpressureError("server: rate limit exceeded", retryAfter)
+	}
+
+	isCacheable, ok := cacheable[method]
+	ok = ok && p.cache != nil
+
+	var key string
+	if ok {
+		b, err := proto.Marshal(req)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		key = method + "\x00" + string(b)
+
+		if p.cache.get(key, reply) {
+			cached = true
+			return nil
+		}
+	}
+
+	if !p.acquire() {
+		return backpressureError("server: too many calls in flight", inFlightRetryAfter)
+	}
+
+	err = p.upstream.Invoke(upstreamContext(ctx), method, req, reply)
+	p.release()
+	if err != nil {
+		return err
+	}
+
+	if ok && isCacheable(reply) {
+		p.cache.put(key, reply)
+	}
+
+	return nil
+}
+
+// acquire reserves a slot for a call forwarded upstream, and returns false if all
+// slots are taken.
+func (p *Proxy) acquire() bool {
+	inFlight := atomic.AddInt32(&p.inFlight, 1)
+
+	if p.config.MaxInFlight > 0 && int(inFlight) > p.config.MaxInFlight {
+		atomic.AddInt32(&p.inFlight, -1)
+		return false
+	}
+
+	p.observeInFlight(inFlight)
+	return true
+}
+
+// release frees the slot of a call forwarded upstream.
+func (p *Proxy) release() {
+	p.observeInFlight(atomic.AddInt32(&p.inFlight, -1))
+}
+
+func (p *Proxy) observeInFlight(inFlight int32) {
+	if metrics, ok := p.config.Metrics.(InFlightMetrics); ok {
+		metrics.ObserveInFlight(int(inFlight))
+	}
+}
+
+// backpressureError returns a ResourceExhausted error that suggests a delay before
+// the call is retried.
+func backpressureError(message string, retryAfter time.Duration) error {
+	s := status.New(codes.ResourceExhausted, message)
+	if retryAfter <= 0 {
+		return s.Err()
+	}
+
+	detailed, err := s.WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retryAfter)})
+	if err != nil {
+		return s.Err()
+	}
+
+	return detailed.Err()
+}
+
+// upstreamContext forwards the request ID of an incoming call to the upstream call.
+func upstreamContext(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if ids := md.Get(client.RequestIDMetadataKey); len(ids) > 0 {
+		return client.WithRequestID(ctx, ids[0])
+	}
+
+	return ctx
+}
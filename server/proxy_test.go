@@ -0,0 +1,269 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/server"
+)
+
+// upstream is a fake Access node that counts the calls it serves.
+type upstream struct {
+	mu         sync.Mutex
+	calls      map[string]int
+	status     entities.TransactionStatus
+	requestIDs []string
+}
+
+func newUpstream() *upstream {
+	return &upstream{calls: make(map[string]int)}
+}
+
+func (u *upstream) Invoke(
+	ctx context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.calls[method]++
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	u.requestIDs = append(u.requestIDs, md.Get(client.RequestIDMetadataKey)...)
+
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Height: uint64(u.calls[method])}
+	case client.MethodGetBlockHeaderByID:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{
+			Id:     args.(*access.GetBlockHeaderByIDRequest).Id,
+			Height: 42,
+		}
+	case client.MethodGetTransactionResult:
+		reply.(*access.TransactionResultResponse).Status = u.status
+	}
+
+	return nil
+}
+
+func (u *upstream) count(method string) int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.calls[method]
+}
+
+type observation struct {
+	method string
+	cached bool
+	err    error
+}
+
+type recorder struct {
+	observations []observation
+}
+
+func (r *recorder) ObserveCall(method string, _ time.Duration, cached bool, err error) {
+	r.observations = append(r.observations, observation{method, cached, err})
+}
+
+func TestProxy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Caches immutable responses", func(t *testing.T) {
+		u := newUpstream()
+		metrics := &recorder{}
+		p := server.NewProxy(u, server.Config{Metrics: metrics})
+
+		for i := 0; i < 3; i++ {
+			res, err := p.GetBlockHeaderByID(ctx, &access.GetBlockHeaderByIDRequest{Id: []byte{1}})
+			require.NoError(t, err)
+			assert.Equal(t, uint64(42), res.Block.Height)
+
+			latest, err := p.GetLatestBlockHeader(ctx, &access.GetLatestBlockHeaderRequest{IsSealed: true})
+			require.NoError(t, err)
+			assert.Equal(t, uint64(i+1), latest.Block.Height)
+		}
+
+		_, err := p.GetBlockHeaderByID(ctx, &access.GetBlockHeaderByIDRequest{Id: []byte{2}})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, u.count(client.MethodGetBlockHeaderByID))
+		assert.Equal(t, 3, u.count(client.MethodGetLatestBlockHeader))
+
+		require.Len(t, metrics.observations, 7)
+		assert.Equal(t, observation{client.MethodGetBlockHeaderByID, false, nil}, metrics.observations[0])
+		assert.Equal(t, observation{client.MethodGetBlockHeaderByID, true, nil}, metrics.observations[2])
+	})
+
+	t.Run("Caches final transaction results only", func(t *testing.T) {
+		u := newUpstream()
+		p := server.NewProxy(u, server.Config{})
+
+		req := &access.GetTransactionRequest{Id: []byte{1}}
+
+		u.status = entities.TransactionStatus_EXECUTED
+		for i := 0; i < 2; i++ {
+			_, err := p.GetTransactionResult(ctx, req)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, u.count(client.MethodGetTransactionResult))
+
+		u.status = entities.TransactionStatus_SEALED
+		for i := 0; i < 2; i++ {
+			res, err := p.GetTransactionResult(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, entities.TransactionStatus_SEALED, res.Status)
+		}
+		assert.Equal(t, 3, u.count(client.MethodGetTransactionResult))
+	})
+
+	t.Run("Cache can be disabled", func(t *testing.T) {
+		u := newUpstream()
+		p := server.NewProxy(u, server.Config{CacheSize: -1})
+
+		for i := 0; i < 2; i++ {
+			_, err := p.GetBlockHeaderByID(ctx, &access.GetBlockHeaderByIDRequest{Id: []byte{1}})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, u.count(client.MethodGetBlockHeaderByID))
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		u := newUpstream()
+		p := server.NewProxy(u, server.Config{Limiter: server.NewTokenBucket(0.001, 2)})
+
+		for i := 0; i < 2; i++ {
+			_, err := p.Ping(ctx, &access.PingRequest{})
+			require.NoError(t, err)
+		}
+
+		_, err := p.Ping(ctx, &access.PingRequest{})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 2, u.count(client.MethodPing))
+
+		retryAfter, ok := client.RetryAfter(err)
+		assert.True(t, ok)
+		assert.Greater(t, int64(retryAfter), int64(time.Minute))
+	})
+
+	t.Run("Max in flight", func(t *testing.T) {
+		release := make(chan struct{})
+		blocking := client.InvokerFunc(func(context.Context, string, interface{}, interface{}, ...grpc.CallOption) error {
+			<-release
+			return nil
+		})
+
+		metrics := &inFlightRecorder{}
+		p := server.NewProxy(blocking, server.Config{MaxInFlight: 1, Metrics: metrics})
+
+		done := make(chan error)
+		go func() {
+			_, err := p.Ping(ctx, &access.PingRequest{})
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool { return metrics.max() == 1 }, time.Second, time.Millisecond)
+
+		_, err := p.Ping(ctx, &access.PingRequest{})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.True(t, errors.Is(client.RPCError{GRPCErr: err}, client.ErrBackpressure))
+
+		_, ok := client.RetryAfter(err)
+		assert.True(t, ok)
+
+		close(release)
+		require.NoError(t, <-done)
+		assert.Equal(t, []int{1, 0}, metrics.values())
+	})
+}
+
+// inFlightRecorder records the numbers of calls in flight.
+type inFlightRecorder struct {
+	mu       sync.Mutex
+	inFlight []int
+}
+
+func (r *inFlightRecorder) ObserveCall(string, time.Duration, bool, error) {}
+
+func (r *inFlightRecorder) ObserveInFlight(inFlight int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight = append(r.inFlight, inFlight)
+}
+
+func (r *inFlightRecorder) values() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.inFlight...)
+}
+
+func (r *inFlightRecorder) max() int {
+	max := 0
+	for _, inFlight := range r.values() {
+		if inFlight > max {
+			max = inFlight
+		}
+	}
+	return max
+}
+
+func TestProxyServer(t *testing.T) {
+	u := newUpstream()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	server.NewProxy(u, server.Config{}).Register(s)
+
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	c, err := client.New(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := client.WithRequestID(context.Background(), "abc")
+
+	header, err := c.GetLatestBlockHeader(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), header.Height)
+
+	// the request ID of the caller is forwarded upstream
+	assert.Equal(t, []string{"abc"}, u.requestIDs)
+}
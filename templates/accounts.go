@@ -135,6 +135,31 @@ func AddAccountKey(address flow.Address, accountKey *flow.AccountKey) *flow.Tran
 		AddAuthorizer(address)
 }
 
+const addAccountKeysTemplate = `
+transaction(publicKeys: [[UInt8]]) {
+  prepare(signer: AuthAccount) {
+	for key in publicKeys {
+	  signer.addPublicKey(key)
+	}
+  }
+}
+`
+
+// AddAccountKeys generates a transaction that adds public keys to an account.
+func AddAccountKeys(address flow.Address, accountKeys []*flow.AccountKey) *flow.Transaction {
+	publicKeys := make([]cadence.Value, len(accountKeys))
+	for i, accountKey := range accountKeys {
+		publicKeys[i] = bytesToCadenceArray(accountKey.Encode())
+	}
+
+	cadencePublicKeys := cadence.NewArray(publicKeys)
+
+	return flow.NewTransaction().
+		SetScript([]byte(addAccountKeysTemplate)).
+		AddRawArgument(jsoncdc.MustEncode(cadencePublicKeys)).
+		AddAuthorizer(address)
+}
+
 const removeAccountKeyTemplate = `
 transaction(keyIndex: Int) {
   prepare(signer: AuthAccount) {
@@ -0,0 +1,180 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates
+
+import (
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+// A Capability describes a private capability that is handed off between accounts
+// through the account inbox.
+type Capability struct {
+	// Type is the reference type of the capability,
+	// e.g. "&ExampleNFT.Collection{NonFungibleToken.CollectionPublic}".
+	Type string
+	// Imports are the contracts referenced by Type.
+	Imports []script.Import
+	// StoragePath is the identifier of the storage path of the linked object.
+	StoragePath string
+	// PrivatePath is the identifier of the private path at which the capability is linked.
+	PrivatePath string
+}
+
+// build renders a template with the imports and validated type of this capability.
+func (c Capability) build(template string, values script.Values) ([]byte, error) {
+	referenceType, err := script.ReferenceType(c.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	values["type"] = referenceType
+
+	b := script.NewBuilder()
+	for _, imp := range c.Imports {
+		b.Import(imp.Contract, imp.Address)
+	}
+
+	return b.Body(script.Fragment{Source: template}, values).Build()
+}
+
+const publishCapabilityTemplate = `
+transaction(name: String, recipient: Address) {
+  prepare(signer: AuthAccount) {
+    if !signer.getCapability<{{type}}>({{privatePath}}).check() {
+      if let target = signer.getLinkTarget({{privatePath}}) {
+        if target != {{storagePath}} {
+          panic("the private path is linked to another object")
+        }
+        signer.unlink({{privatePath}})
+      }
+      signer.link<{{type}}>({{privatePath}}, target: {{storagePath}})
+    }
+
+    let capability = signer.getCapability<{{type}}>({{privatePath}})
+    signer.inbox.publish(capability, name: name, recipient: recipient)
+  }
+}
+`
+
+// PublishCapability generates a transaction that links a private capability to the
+// object in storage and publishes it to the inbox of the recipient under the given name.
+//
+// An existing link at the private path is replaced only if it targets the same
+// object; the transaction fails if the path links another object.
+//
+// The provider account must sign the resulting transaction.
+func PublishCapability(
+	provider flow.Address,
+	capability Capability,
+	name string,
+	recipient flow.Address,
+) (*flow.Transaction, error) {
+	storagePath, err := script.Path(script.PathDomainStorage, capability.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+
+	privatePath, err := script.Path(script.PathDomainPrivate, capability.PrivatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := capability.build(publishCapabilityTemplate, script.Values{
+		"storagePath": storagePath,
+		"privatePath": privatePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return flow.NewTransaction().
+		SetScript(code).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewString(name))).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewAddress(recipient))).
+		AddAuthorizer(provider), nil
+}
+
+const claimCapabilityTemplate = `
+transaction(name: String, provider: Address) {
+  prepare(signer: AuthAccount) {
+    let capability = signer.inbox.claim<{{type}}>(name, provider: provider)
+      ?? panic("no capability is published under this name")
+
+    signer.save(capability, to: {{savePath}})
+  }
+}
+`
+
+// ClaimCapability generates a transaction that claims a capability published by the
+// provider under the given name and saves it in the storage of the recipient at savePath.
+//
+// The recipient account must sign the resulting transaction.
+func ClaimCapability(
+	recipient flow.Address,
+	capability Capability,
+	name string,
+	provider flow.Address,
+	savePath string,
+) (*flow.Transaction, error) {
+	path, err := script.Path(script.PathDomainStorage, savePath)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := capability.build(claimCapabilityTemplate, script.Values{
+		"savePath": path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return flow.NewTransaction().
+		SetScript(code).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewString(name))).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewAddress(provider))).
+		AddAuthorizer(recipient), nil
+}
+
+const unpublishCapabilityTemplate = `
+transaction(name: String) {
+  prepare(signer: AuthAccount) {
+    signer.inbox.unpublish<{{type}}>(name)
+  }
+}
+`
+
+// UnpublishCapability generates a transaction that withdraws a capability that was
+// published by the provider and not yet claimed.
+//
+// The provider account must sign the resulting transaction.
+func UnpublishCapability(provider flow.Address, capability Capability, name string) (*flow.Transaction, error) {
+	code, err := capability.build(unpublishCapabilityTemplate, script.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	return flow.NewTransaction().
+		SetScript(code).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewString(name))).
+		AddAuthorizer(provider), nil
+}
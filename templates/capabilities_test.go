@@ -0,0 +1,114 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package templates_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/templates"
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+func TestCapabilities(t *testing.T) {
+	provider := flow.HexToAddress("01")
+	recipient := flow.HexToAddress("02")
+
+	capability := templates.Capability{
+		Type: "&ExampleNFT.Collection{NonFungibleToken.CollectionPublic}",
+		Imports: []script.Import{
+			{Contract: "NonFungibleToken", Address: flow.HexToAddress("f8d6e0586b0a20c7")},
+			{Contract: "ExampleNFT", Address: flow.HexToAddress("01cf0e2f2f715450")},
+		},
+		StoragePath: "exampleNFTCollection",
+		PrivatePath: "exampleNFTProvider",
+	}
+
+	t.Run("Scripts", func(t *testing.T) {
+		publish, err := templates.PublishCapability(provider, capability, "nft", recipient)
+		require.NoError(t, err)
+
+		claim, err := templates.ClaimCapability(recipient, capability, "nft", provider, "exampleNFTCapability")
+		require.NoError(t, err)
+
+		unpublish, err := templates.UnpublishCapability(provider, capability, "nft")
+		require.NoError(t, err)
+
+		for _, test := range []struct {
+			tx         *flow.Transaction
+			authorizer flow.Address
+			arguments  int
+		}{
+			{publish, provider, 2},
+			{claim, recipient, 2},
+			{unpublish, provider, 1},
+		} {
+			program, err := parser2.ParseProgram(string(test.tx.Script))
+			require.NoError(t, err)
+
+			declarations := program.TransactionDeclarations()
+			require.Len(t, declarations, 1)
+			assert.Len(t, declarations[0].ParameterList.Parameters, test.arguments)
+			assert.Len(t, test.tx.Arguments, test.arguments)
+			assert.Equal(t, []flow.Address{test.authorizer}, test.tx.Authorizers)
+			assert.Contains(t, string(test.tx.Script), "import ExampleNFT from 0x01cf0e2f2f715450")
+		}
+	})
+
+	t.Run("Guards existing links", func(t *testing.T) {
+		tx, err := templates.PublishCapability(provider, capability, "nft", recipient)
+		require.NoError(t, err)
+
+		code := string(tx.Script)
+		guard := strings.Index(code, "if target != /storage/exampleNFTCollection")
+		unlink := strings.Index(code, "signer.unlink(/private/exampleNFTProvider)")
+
+		require.NotEqual(t, -1, guard)
+		require.NotEqual(t, -1, unlink)
+		assert.Less(t, guard, unlink)
+	})
+
+	t.Run("Invalid capability", func(t *testing.T) {
+		invalid := capability
+		invalid.Type = "&ExampleNFT.Collection) {}"
+
+		_, err := templates.PublishCapability(provider, invalid, "nft", recipient)
+		assert.Error(t, err)
+
+		_, err = templates.ClaimCapability(recipient, invalid, "nft", provider, "exampleNFTCapability")
+		assert.Error(t, err)
+
+		_, err = templates.UnpublishCapability(provider, invalid, "nft")
+		assert.Error(t, err)
+
+		invalid = capability
+		invalid.PrivatePath = "exampleNFTProvider)"
+
+		_, err = templates.PublishCapability(provider, invalid, "nft", recipient)
+		assert.Error(t, err)
+
+		_, err = templates.ClaimCapability(recipient, capability, "nft", provider, "/storage/x")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,231 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package script
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// The functions in this file produce Cadence source text that is safe to splice
+// into a Fragment as a placeholder value.
+//
+// Values that can be passed as script or transaction arguments should always be
+// passed as arguments instead; these helpers exist for the cases where Cadence
+// does not accept a runtime value (e.g. identifiers, paths and type names).
+
+// keywords is the list of reserved Cadence keywords, which are not valid identifiers.
+var keywords = map[string]struct{}{
+	"if": {}, "else": {}, "while": {}, "for": {}, "in": {}, "break": {}, "continue": {},
+	"return": {}, "true": {}, "false": {}, "nil": {}, "let": {}, "var": {}, "fun": {},
+	"as": {}, "create": {}, "destroy": {}, "emit": {}, "auth": {}, "priv": {}, "pub": {},
+	"access": {}, "all": {}, "contract": {}, "account": {}, "self": {}, "init": {},
+	"struct": {}, "resource": {}, "interface": {}, "transaction": {}, "prepare": {},
+	"execute": {}, "pre": {}, "post": {}, "import": {}, "from": {}, "event": {},
+	"switch": {}, "case": {}, "default": {}, "enum": {},
+}
+
+// Identifier validates that s is a valid, non-reserved Cadence identifier and returns it.
+func Identifier(s string) (string, error) {
+	if !identifierPattern.MatchString(s) {
+		return "", fmt.Errorf("script: %q is not a valid identifier", s)
+	}
+
+	if _, ok := keywords[s]; ok {
+		return "", fmt.Errorf("script: %q is a reserved keyword", s)
+	}
+
+	return s, nil
+}
+
+// QualifiedIdentifier validates a dot-separated identifier (e.g. FungibleToken.Vault)
+// and returns it.
+func QualifiedIdentifier(s string) (string, error) {
+	parts := strings.Split(s, ".")
+	for _, part := range parts {
+		if _, err := Identifier(part); err != nil {
+			return "", fmt.Errorf("script: %q is not a valid qualified identifier", s)
+		}
+	}
+
+	return s, nil
+}
+
+// ReferenceType validates a Cadence reference type, optionally authorized and
+// restricted (e.g. &ExampleNFT.Collection{NonFungibleToken.CollectionPublic}),
+// and returns it in normalized form.
+func ReferenceType(s string) (string, error) {
+	invalid := fmt.Errorf("script: %q is not a valid reference type", s)
+
+	t := strings.TrimSpace(s)
+
+	auth := strings.HasPrefix(t, "auth ")
+	if auth {
+		t = strings.TrimSpace(strings.TrimPrefix(t, "auth "))
+	}
+
+	if !strings.HasPrefix(t, "&") {
+		return "", invalid
+	}
+	t = strings.TrimSpace(t[1:])
+
+	var restrictions []string
+
+	if i := strings.IndexByte(t, '{'); i >= 0 {
+		if !strings.HasSuffix(t, "}") {
+			return "", invalid
+		}
+
+		for _, restriction := range strings.Split(t[i+1:len(t)-1], ",") {
+			restriction, err := QualifiedIdentifier(strings.TrimSpace(restriction))
+			if err != nil {
+				return "", invalid
+			}
+			restrictions = append(restrictions, restriction)
+		}
+
+		t = strings.TrimSpace(t[:i])
+	}
+
+	base, err := QualifiedIdentifier(t)
+	if err != nil {
+		return "", invalid
+	}
+
+	var sb strings.Builder
+	if auth {
+		sb.WriteString("auth ")
+	}
+	sb.WriteString("&")
+	sb.WriteString(base)
+	if len(restrictions) > 0 {
+		sb.WriteString("{")
+		sb.WriteString(strings.Join(restrictions, ", "))
+		sb.WriteString("}")
+	}
+
+	return sb.String(), nil
+}
+
+// A PathDomain is the domain of a Cadence storage path.
+type PathDomain string
+
+const (
+	PathDomainStorage PathDomain = "storage"
+	PathDomainPublic  PathDomain = "public"
+	PathDomainPrivate PathDomain = "private"
+)
+
+// Path returns the Cadence path literal for the given domain and identifier
+// (e.g. /public/flowTokenReceiver).
+func Path(domain PathDomain, identifier string) (string, error) {
+	switch domain {
+	case PathDomainStorage, PathDomainPublic, PathDomainPrivate:
+	default:
+		return "", fmt.Errorf("script: invalid path domain %q", domain)
+	}
+
+	if _, err := Identifier(identifier); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("/%s/%s", domain, identifier), nil
+}
+
+// Address returns the Cadence address literal for the given address.
+func Address(address flow.Address) string {
+	return "0x" + address.Hex()
+}
+
+// String returns the Cadence string literal for s, escaping all special
+// and non-printable characters.
+//
+// An error is returned if s is not valid UTF-8.
+func String(s string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("script: string literal is not valid UTF-8")
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case 0:
+			sb.WriteString(`\0`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if unicode.IsPrint(r) {
+				sb.WriteRune(r)
+			} else {
+				fmt.Fprintf(&sb, `\u{%X}`, r)
+			}
+		}
+	}
+
+	sb.WriteByte('"')
+	return sb.String(), nil
+}
+
+// MustIdentifier is like Identifier but panics if s is not a valid identifier.
+func MustIdentifier(s string) string {
+	return must(Identifier(s))
+}
+
+// MustQualifiedIdentifier is like QualifiedIdentifier but panics if s is invalid.
+func MustQualifiedIdentifier(s string) string {
+	return must(QualifiedIdentifier(s))
+}
+
+// MustReferenceType is like ReferenceType but panics if s is not a valid reference type.
+func MustReferenceType(s string) string {
+	return must(ReferenceType(s))
+}
+
+// MustPath is like Path but panics if the path is invalid.
+func MustPath(domain PathDomain, identifier string) string {
+	return must(Path(domain, identifier))
+}
+
+// MustString is like String but panics if s is not valid UTF-8.
+func MustString(s string) string {
+	return must(String(s))
+}
+
+func must(s string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
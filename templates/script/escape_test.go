@@ -0,0 +1,117 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package script_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+func TestIdentifier(t *testing.T) {
+	valid := []string{"a", "_vault", "FlowToken", "x1"}
+	invalid := []string{"", "1x", "a b", "a.b", "x}", "pub", "self", "a\nb", "fun"}
+
+	for _, s := range valid {
+		id, err := script.Identifier(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, s, id)
+	}
+
+	for _, s := range invalid {
+		_, err := script.Identifier(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestQualifiedIdentifier(t *testing.T) {
+	_, err := script.QualifiedIdentifier("FungibleToken.Vault")
+	assert.NoError(t, err)
+
+	_, err = script.QualifiedIdentifier("FungibleToken.")
+	assert.Error(t, err)
+
+	_, err = script.QualifiedIdentifier("FungibleToken.Vault{}")
+	assert.Error(t, err)
+}
+
+func TestReferenceType(t *testing.T) {
+	valid := map[string]string{
+		"&ExampleNFT.Collection": "&ExampleNFT.Collection",
+		"&ExampleNFT.Collection{NonFungibleToken.CollectionPublic,ExampleNFT.Public}": "&ExampleNFT.Collection{NonFungibleToken.CollectionPublic, ExampleNFT.Public}",
+		"auth & FlowToken.Vault": "auth &FlowToken.Vault",
+	}
+
+	for input, expected := range valid {
+		actual, err := script.ReferenceType(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, actual)
+	}
+
+	invalid := []string{
+		"ExampleNFT.Collection",
+		"&ExampleNFT.Collection{",
+		"&ExampleNFT.Collection{}",
+		"&Vault}\nlog(1)//{A}",
+		"&A{B} {C}",
+	}
+
+	for _, input := range invalid {
+		_, err := script.ReferenceType(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestPath(t *testing.T) {
+	p, err := script.Path(script.PathDomainPublic, "flowTokenReceiver")
+	require.NoError(t, err)
+	assert.Equal(t, "/public/flowTokenReceiver", p)
+
+	_, err = script.Path("contract", "flowTokenReceiver")
+	assert.Error(t, err)
+
+	_, err = script.Path(script.PathDomainStorage, "vault)\nlog(1")
+	assert.Error(t, err)
+}
+
+func TestString(t *testing.T) {
+	tests := map[string]string{
+		"hello":             `"hello"`,
+		`say "hi"`:          `"say \"hi\""`,
+		"line\nbreak":       `"line\nbreak"`,
+		`back\slash`:        `"back\\slash"`,
+		"nul\x00":           `"nul\0"`,
+		"bell\a":            `"bell\u{7}"`,
+		"héllo wörld":       `"héllo wörld"`,
+		`") log("injected`:  `"\") log(\"injected"`,
+		"tab\tand\rreturn'": `"tab\tand\rreturn\'"`,
+	}
+
+	for input, expected := range tests {
+		s, err := script.String(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, s)
+	}
+
+	_, err := script.String("\xff")
+	assert.Error(t, err)
+}
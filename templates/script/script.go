@@ -0,0 +1,348 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package script provides utilities for composing Cadence scripts and transactions
+// from reusable fragments.
+//
+// Programmatically generated Cadence (e.g. a balance query covering a dynamic list of
+// tokens) should be assembled with a Builder rather than with fmt.Sprintf, so that
+// imports are deduplicated, placeholders are fully resolved and identifiers declared
+// by different fragments cannot silently shadow each other.
+package script
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// An Import is a contract imported from an on-chain address.
+type Import struct {
+	Contract string
+	Address  flow.Address
+}
+
+// String returns the Cadence import declaration for this import.
+func (i Import) String() string {
+	return fmt.Sprintf("import %s from 0x%s", i.Contract, i.Address.Hex())
+}
+
+// A Fragment is a reusable piece of Cadence source code.
+//
+// A fragment may contain placeholders of the form {{name}}, which are substituted
+// with the values supplied when the fragment is added to a Builder.
+type Fragment struct {
+	// Source is the Cadence source code of this fragment.
+	Source string
+	// Imports lists the contracts this fragment depends on.
+	Imports []Import
+	// Declares lists the identifiers introduced by this fragment.
+	//
+	// Declared identifiers may themselves contain placeholders. A Builder
+	// rejects fragments that declare an identifier already declared by another fragment.
+	Declares []string
+}
+
+// Values maps placeholder names to their substituted values.
+//
+// Values are spliced into the source verbatim. Use the helpers in this package
+// (Identifier, Path, String, etc.) to produce values derived from untrusted input.
+type Values map[string]string
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Render substitutes the placeholders in this fragment with the given values.
+//
+// An error is returned if a placeholder has no value or if a value does not
+// correspond to any placeholder.
+func (f Fragment) Render(values Values) (string, error) {
+	return render(f.Source, values, true)
+}
+
+func render(source string, values Values, strict bool) (string, error) {
+	used := make(map[string]struct{})
+	var missing []string
+
+	result := placeholderPattern.ReplaceAllStringFunc(source, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+
+		value, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+
+		used[name] = struct{}{}
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("script: no value for placeholder %s", strings.Join(missing, ", "))
+	}
+
+	if strict {
+		var unused []string
+		for name := range values {
+			if _, ok := used[name]; !ok {
+				unused = append(unused, name)
+			}
+		}
+
+		if len(unused) > 0 {
+			sort.Strings(unused)
+			return "", fmt.Errorf("script: unknown placeholder %s", strings.Join(unused, ", "))
+		}
+	}
+
+	return result, nil
+}
+
+// A Builder assembles a Cadence program from an imports section, prelude
+// fragments and body fragments.
+//
+// Prelude fragments are emitted at the top level (e.g. helper functions and
+// type declarations). Body fragments are emitted after the prelude, or inside the
+// main function if one is declared with Main.
+//
+// The first error encountered is retained and returned by Build; subsequent
+// calls on a failed builder have no effect.
+type Builder struct {
+	imports    []Import
+	importMap  map[string]flow.Address
+	prelude    []string
+	body       []string
+	declared   map[string]struct{}
+	main       bool
+	params     string
+	returnType string
+	err        error
+}
+
+// NewBuilder returns an empty script builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		importMap: make(map[string]flow.Address),
+		declared:  make(map[string]struct{}),
+	}
+}
+
+// Import adds a contract import to this script.
+//
+// Importing the same contract from the same address more than once is allowed;
+// importing the same contract name from two different addresses is an error.
+func (b *Builder) Import(contract string, address flow.Address) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	if !identifierPattern.MatchString(contract) {
+		b.err = fmt.Errorf("script: invalid contract name %q", contract)
+		return b
+	}
+
+	existing, ok := b.importMap[contract]
+	if ok {
+		if existing != address {
+			b.err = fmt.Errorf(
+				"script: contract %s imported from both 0x%s and 0x%s",
+				contract,
+				existing.Hex(),
+				address.Hex(),
+			)
+		}
+		return b
+	}
+
+	b.importMap[contract] = address
+	b.imports = append(b.imports, Import{Contract: contract, Address: address})
+
+	return b
+}
+
+// ImportCore adds an import of a core contract of the given chain, e.g.
+// flow.ContractFungibleToken, from the address defined for the chain. Chains
+// registered with flow.RegisterChain are supported.
+func (b *Builder) ImportCore(contract string, chain flow.ChainID) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	definition, ok := flow.LookupChain(chain)
+	if !ok {
+		b.err = fmt.Errorf("script: unknown chain %s", chain)
+		return b
+	}
+
+	address, ok := definition.Contract(contract)
+	if !ok {
+		b.err = fmt.Errorf("script: no %s contract on chain %s", contract, chain)
+		return b
+	}
+
+	return b.Import(contract, address)
+}
+
+// Main declares a main function with the given parameter list and return type
+// that wraps all body fragments.
+//
+// An empty return type declares a function without a return value.
+func (b *Builder) Main(params, returnType string) *Builder {
+	b.main = true
+	b.params = params
+	b.returnType = returnType
+	return b
+}
+
+// Prelude renders a fragment and adds it to the top-level prelude.
+func (b *Builder) Prelude(f Fragment, values Values) *Builder {
+	source, ok := b.add(f, values)
+	if ok {
+		b.prelude = append(b.prelude, source)
+	}
+	return b
+}
+
+// Body renders a fragment and adds it to the script body.
+func (b *Builder) Body(f Fragment, values Values) *Builder {
+	source, ok := b.add(f, values)
+	if ok {
+		b.body = append(b.body, source)
+	}
+	return b
+}
+
+func (b *Builder) add(f Fragment, values Values) (string, bool) {
+	if b.err != nil {
+		return "", false
+	}
+
+	source, err := f.Render(values)
+	if err != nil {
+		b.err = err
+		return "", false
+	}
+
+	for _, imp := range f.Imports {
+		b.Import(imp.Contract, imp.Address)
+	}
+
+	for _, declaration := range f.Declares {
+		// declarations only need to be resolved, not all values must appear in them
+		name, err := render(declaration, values, false)
+		if err != nil {
+			b.err = err
+			return "", false
+		}
+
+		if _, ok := b.declared[name]; ok {
+			b.err = fmt.Errorf("script: identifier %s is declared more than once", name)
+			return "", false
+		}
+
+		b.declared[name] = struct{}{}
+	}
+
+	if b.err != nil {
+		return "", false
+	}
+
+	return strings.TrimSpace(source), true
+}
+
+// Err returns the first error encountered by this builder, if any.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Build returns the assembled Cadence source code.
+func (b *Builder) Build() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var sb strings.Builder
+
+	for _, imp := range b.imports {
+		sb.WriteString(imp.String())
+		sb.WriteString("\n")
+	}
+
+	for _, source := range b.prelude {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(source)
+		sb.WriteString("\n")
+	}
+
+	if b.main {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("pub fun main(")
+		sb.WriteString(b.params)
+		sb.WriteString(")")
+		if b.returnType != "" {
+			sb.WriteString(": ")
+			sb.WriteString(b.returnType)
+		}
+		sb.WriteString(" {\n")
+
+		for _, source := range b.body {
+			sb.WriteString(indent(source))
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString("}\n")
+	} else {
+		for _, source := range b.body {
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(source)
+			sb.WriteString("\n")
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// MustBuild is like Build but panics if the script cannot be assembled.
+func (b *Builder) MustBuild() []byte {
+	script, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return script
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func indent(source string) string {
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,129 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package script_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+var balanceFragment = script.Fragment{
+	Source: `
+let {{name}}Ref = getAccount(address).getCapability({{path}})!
+  .borrow<&{FungibleToken.Balance}>()
+balances["{{name}}"] = {{name}}Ref?.balance ?? 0.0`,
+	Imports: []script.Import{
+		{Contract: "FungibleToken", Address: flow.HexToAddress("ee82856bf20e2aa6")},
+	},
+	Declares: []string{"{{name}}Ref"},
+}
+
+func TestBuilder_Build(t *testing.T) {
+	t.Run("Multiple body fragments", func(t *testing.T) {
+		code, err := script.NewBuilder().
+			Main("address: Address", "{String: UFix64}").
+			Body(script.Fragment{Source: "let balances: {String: UFix64} = {}", Declares: []string{"balances"}}, nil).
+			Body(balanceFragment, script.Values{"name": "flow", "path": "/public/flowTokenBalance"}).
+			Body(balanceFragment, script.Values{"name": "fusd", "path": "/public/fusdBalance"}).
+			Body(script.Fragment{Source: "return balances"}, nil).
+			Build()
+		require.NoError(t, err)
+
+		expected := `import FungibleToken from 0xee82856bf20e2aa6
+
+pub fun main(address: Address): {String: UFix64} {
+  let balances: {String: UFix64} = {}
+  let flowRef = getAccount(address).getCapability(/public/flowTokenBalance)!
+    .borrow<&{FungibleToken.Balance}>()
+  balances["flow"] = flowRef?.balance ?? 0.0
+  let fusdRef = getAccount(address).getCapability(/public/fusdBalance)!
+    .borrow<&{FungibleToken.Balance}>()
+  balances["fusd"] = fusdRef?.balance ?? 0.0
+  return balances
+}
+`
+		assert.Equal(t, expected, string(code))
+	})
+
+	t.Run("Prelude without main", func(t *testing.T) {
+		code, err := script.NewBuilder().
+			Prelude(script.Fragment{Source: "pub fun double(_ x: Int): Int { return x * 2 }"}, nil).
+			Body(script.Fragment{Source: "pub fun main(): Int { return double({{x}}) }"}, script.Values{"x": "21"}).
+			Build()
+		require.NoError(t, err)
+
+		assert.Equal(
+			t,
+			"pub fun double(_ x: Int): Int { return x * 2 }\n\npub fun main(): Int { return double(21) }\n",
+			string(code),
+		)
+	})
+
+	t.Run("Missing placeholder value", func(t *testing.T) {
+		_, err := script.NewBuilder().
+			Body(balanceFragment, script.Values{"name": "flow"}).
+			Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown placeholder value", func(t *testing.T) {
+		_, err := script.NewBuilder().
+			Body(script.Fragment{Source: "return {{x}}"}, script.Values{"x": "1", "y": "2"}).
+			Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("Declaration collision", func(t *testing.T) {
+		_, err := script.NewBuilder().
+			Body(balanceFragment, script.Values{"name": "flow", "path": "/public/a"}).
+			Body(balanceFragment, script.Values{"name": "flow", "path": "/public/b"}).
+			Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("Import collision", func(t *testing.T) {
+		b := script.NewBuilder().
+			Import("FungibleToken", flow.HexToAddress("01")).
+			Import("FungibleToken", flow.HexToAddress("01"))
+		require.NoError(t, b.Err())
+
+		b.Import("FungibleToken", flow.HexToAddress("02"))
+		assert.Error(t, b.Err())
+	})
+}
+
+func TestBuilder_ImportCore(t *testing.T) {
+	code, err := script.NewBuilder().
+		ImportCore(flow.ContractFungibleToken, flow.Testnet).
+		Main("", "").
+		Build()
+	require.NoError(t, err)
+	assert.Contains(t, string(code), "import FungibleToken from 0x9a0766d93b6608b7")
+
+	_, err = script.NewBuilder().ImportCore("Missing", flow.Testnet).Build()
+	assert.EqualError(t, err, "script: no Missing contract on chain flow-testnet")
+
+	_, err = script.NewBuilder().ImportCore(flow.ContractFungibleToken, "flow-unknown").Build()
+	assert.EqualError(t, err, "script: unknown chain flow-unknown")
+}
@@ -19,7 +19,6 @@
 package test
 
 import (
-	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +26,7 @@ import (
 
 	"github.com/portto/blocto-flow-go-sdk"
 	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
 )
 
 type Accounts struct {
@@ -358,7 +358,7 @@ func (g *TransactionResults) New() flow.TransactionResult {
 
 	return flow.TransactionResult{
 		Status: flow.TransactionStatusSealed,
-		Error:  errors.New("transaction execution error"),
+		Error:  errcode.New(errcode.TransactionFailed, "transaction execution error"),
 		Events: []flow.Event{
 			eventA,
 			eventB,
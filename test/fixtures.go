@@ -0,0 +1,320 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package test
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+)
+
+// A FixtureKey is an account key that signs a transaction fixture.
+type FixtureKey struct {
+	Address    flow.Address
+	KeyIndex   int
+	SigAlgo    crypto.SignatureAlgorithm
+	HashAlgo   crypto.HashAlgorithm
+	PrivateKey string
+}
+
+// Decode returns the private key of this fixture key.
+func (k FixtureKey) Decode() crypto.PrivateKey {
+	privKey, err := crypto.DecodePrivateKeyHex(k.SigAlgo, k.PrivateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return privKey
+}
+
+// Signer returns a signer for this key whose signatures are deterministic, so that
+// fixtures signed with it are reproducible.
+func (k FixtureKey) Signer(fixture string) crypto.Signer {
+	signer := crypto.NewInMemorySigner(k.Decode(), k.HashAlgo)
+	signer.Random = crypto.NewDeterministicRandomSource(
+		[]byte(fmt.Sprintf("%s/%s/%d", fixture, k.Address.Hex(), k.KeyIndex)),
+	)
+
+	return signer
+}
+
+// A TransactionFixture is a known transaction together with its expected encodings,
+// as hex strings.
+//
+// Wallets validate their signing implementation by building the same transaction,
+// comparing their payload and envelope messages with PayloadMessage and EnvelopeMessage,
+// and checking that their signatures verify with the public keys of PayloadKeys and
+// EnvelopeKeys. ECDSA signatures are randomized, so signature bytes, and therefore
+// Encoded and ID, only match for signatures produced by this SDK.
+type TransactionFixture struct {
+	Name string
+	// Transaction is the signed transaction.
+	Transaction *flow.Transaction
+	// PayloadKeys are the keys that sign the payload.
+	PayloadKeys []FixtureKey
+	// EnvelopeKeys are the keys that sign the envelope.
+	EnvelopeKeys    []FixtureKey
+	PayloadMessage  string
+	EnvelopeMessage string
+	Encoded         string
+	ID              string
+}
+
+// Verify returns an error if the encodings of tx do not match this fixture. Only
+// the payload and envelope messages are compared if tx is signed with other signatures.
+func (f TransactionFixture) Verify(tx *flow.Transaction) error {
+	if got := hex.EncodeToString(tx.PayloadMessage()); got != f.PayloadMessage {
+		return fmt.Errorf("test: payload message of %s is %s, expected %s", f.Name, got, f.PayloadMessage)
+	}
+
+	if got := hex.EncodeToString(tx.EnvelopeMessage()); got != f.EnvelopeMessage {
+		return fmt.Errorf("test: envelope message of %s is %s, expected %s", f.Name, got, f.EnvelopeMessage)
+	}
+
+	if err := verifySignatures(tx.PayloadSignatures, f.PayloadKeys, tx.PayloadMessage()); err != nil {
+		return fmt.Errorf("test: payload signatures of %s: %w", f.Name, err)
+	}
+
+	if err := verifySignatures(tx.EnvelopeSignatures, f.EnvelopeKeys, tx.EnvelopeMessage()); err != nil {
+		return fmt.Errorf("test: envelope signatures of %s: %w", f.Name, err)
+	}
+
+	return nil
+}
+
+func verifySignatures(sigs []flow.TransactionSignature, keys []FixtureKey, message []byte) error {
+	if len(sigs) != len(keys) {
+		return fmt.Errorf("got %d signatures, expected %d", len(sigs), len(keys))
+	}
+
+	for _, sig := range sigs {
+		key, ok := findFixtureKey(keys, sig.Address, sig.KeyIndex)
+		if !ok {
+			return fmt.Errorf("unexpected signature of key %d of %s", sig.KeyIndex, sig.Address)
+		}
+
+		hasher, err := crypto.NewHasher(key.HashAlgo)
+		if err != nil {
+			return err
+		}
+
+		valid, err := key.Decode().PublicKey().Verify(sig.Signature, message, hasher)
+		if err != nil {
+			return err
+		}
+
+		if !valid {
+			return fmt.Errorf("invalid signature of key %d of %s", sig.KeyIndex, sig.Address)
+		}
+	}
+
+	return nil
+}
+
+func findFixtureKey(keys []FixtureKey, address flow.Address, keyIndex int) (FixtureKey, bool) {
+	for _, key := range keys {
+		if key.Address == address && key.KeyIndex == keyIndex {
+			return key, true
+		}
+	}
+
+	return FixtureKey{}, false
+}
+
+var (
+	fixtureAddressA = flow.HexToAddress("f8d6e0586b0a20c7")
+	fixtureAddressB = flow.HexToAddress("01cf0e2f2f715450")
+	fixtureAddressC = flow.HexToAddress("179b6b1cb6755e31")
+
+	fixtureKeyA = FixtureKey{
+		Address:    fixtureAddressA,
+		KeyIndex:   0,
+		SigAlgo:    crypto.ECDSA_P256,
+		HashAlgo:   crypto.SHA3_256,
+		PrivateKey: "ccd2efccdb5ef1d196d87a721d0d7c917019dbac6ffa84398fb40ff9f1a9616f",
+	}
+	fixtureKeyB = FixtureKey{
+		Address:    fixtureAddressB,
+		KeyIndex:   0,
+		SigAlgo:    crypto.ECDSA_secp256k1,
+		HashAlgo:   crypto.SHA2_256,
+		PrivateKey: "f8d34aa1fc00cc3280ccc0aa30493645096ec61ac8710a2ab4cd98a1328f33cb",
+	}
+	fixtureKeyC0 = FixtureKey{
+		Address:    fixtureAddressC,
+		KeyIndex:   0,
+		SigAlgo:    crypto.ECDSA_P256,
+		HashAlgo:   crypto.SHA2_256,
+		PrivateKey: "db355e3f712a1658b2677db67a996d2ad479c084bc693d929a276f8505ef5997",
+	}
+	fixtureKeyC1 = FixtureKey{
+		Address:    fixtureAddressC,
+		KeyIndex:   1,
+		SigAlgo:    crypto.ECDSA_secp256k1,
+		HashAlgo:   crypto.SHA3_256,
+		PrivateKey: "6914677d7a45f504b7133aaea74396320976ce298a3226bcad0b294b517b6fd3",
+	}
+)
+
+const fixtureTransferScript = `import FungibleToken from 0xee82856bf20e2aa6
+import FlowToken from 0x0ae53cb6e3f42a79
+
+transaction(amount: UFix64, to: Address) {
+    let vault: @FungibleToken.Vault
+
+    prepare(signer: AuthAccount) {
+        self.vault <- signer
+            .borrow<&FlowToken.Vault>(from: /storage/flowTokenVault)!
+            .withdraw(amount: amount)
+    }
+
+    execute {
+        getAccount(to)
+            .getCapability(/public/flowTokenReceiver)
+            .borrow<&{FungibleToken.Receiver}>()!
+            .deposit(from: <-self.vault)
+    }
+}
+`
+
+// TransactionFixtures returns deterministic signed transactions with their expected
+// encodings:
+//
+//	single-party: one account is proposer, authorizer and payer
+//	multi-party:  distinct proposer, authorizer and payer, with arguments
+//	multisig:     as multi-party, with a payer that signs with two keys
+//
+// Each call returns new transactions.
+func TransactionFixtures() []TransactionFixture {
+	return []TransactionFixture{
+		singlePartyFixture(),
+		multiPartyFixture(),
+		multisigFixture(),
+	}
+}
+
+// TransactionFixtureByName returns the transaction fixture with the given name.
+func TransactionFixtureByName(name string) (TransactionFixture, bool) {
+	for _, fixture := range TransactionFixtures() {
+		if fixture.Name == name {
+			return fixture, true
+		}
+	}
+
+	return TransactionFixture{}, false
+}
+
+func singlePartyFixture() TransactionFixture {
+	const name = "single-party"
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(`transaction { prepare(signer: AuthAccount) { log(signer.address) } }`)).
+		SetReferenceBlockID(flow.HexToID("a7f4c4b63c6f2e6d0b1e6b0b5d7f3c9a2e1d0c9b8a7f6e5d4c3b2a1908f7e6d5")).
+		SetGasLimit(100).
+		SetProposalKey(fixtureAddressA, fixtureKeyA.KeyIndex, 42).
+		SetPayer(fixtureAddressA).
+		AddAuthorizer(fixtureAddressA)
+
+	signFixtureEnvelope(name, tx, fixtureKeyA)
+
+	return TransactionFixture{
+		Name:            name,
+		Transaction:     tx,
+		EnvelopeKeys:    []FixtureKey{fixtureKeyA},
+		PayloadMessage:  "f887b8447472616e73616374696f6e207b2070726570617265287369676e65723a20417574684163636f756e7429207b206c6f67287369676e65722e6164647265737329207d207dc0a0a7f4c4b63c6f2e6d0b1e6b0b5d7f3c9a2e1d0c9b8a7f6e5d4c3b2a1908f7e6d56488f8d6e0586b0a20c7802a88f8d6e0586b0a20c7c988f8d6e0586b0a20c7",
+		EnvelopeMessage: "f88af887b8447472616e73616374696f6e207b2070726570617265287369676e65723a20417574684163636f756e7429207b206c6f67287369676e65722e6164647265737329207d207dc0a0a7f4c4b63c6f2e6d0b1e6b0b5d7f3c9a2e1d0c9b8a7f6e5d4c3b2a1908f7e6d56488f8d6e0586b0a20c7802a88f8d6e0586b0a20c7c988f8d6e0586b0a20c7c0",
+		Encoded:         "f8d2f887b8447472616e73616374696f6e207b2070726570617265287369676e65723a20417574684163636f756e7429207b206c6f67287369676e65722e6164647265737329207d207dc0a0a7f4c4b63c6f2e6d0b1e6b0b5d7f3c9a2e1d0c9b8a7f6e5d4c3b2a1908f7e6d56488f8d6e0586b0a20c7802a88f8d6e0586b0a20c7c988f8d6e0586b0a20c7c0f846f8448080b8403d79241a5ec5d8401cdb2a3dea296232b045556de244b4c9340dcd2feaf8074e86563361b09c73a56ece5ac7e755ea80399c07524a640a45950cb27e9c771f7b",
+		ID:              "c9ab193f6da3b73708ab4001db653c4e47ee4172398eefbf3d9a96777c90b8d5",
+	}
+}
+
+func multiPartyFixture() TransactionFixture {
+	const name = "multi-party"
+
+	tx := newFixtureTransfer().
+		SetProposalKey(fixtureAddressA, fixtureKeyA.KeyIndex, 7).
+		SetPayer(fixtureAddressC).
+		AddAuthorizer(fixtureAddressB)
+
+	signFixturePayload(name, tx, fixtureKeyA, fixtureKeyB)
+	signFixtureEnvelope(name, tx, fixtureKeyC0)
+
+	return TransactionFixture{
+		Name:            name,
+		Transaction:     tx,
+		PayloadKeys:     []FixtureKey{fixtureKeyA, fixtureKeyB},
+		EnvelopeKeys:    []FixtureKey{fixtureKeyC0},
+		PayloadMessage:  "f902b7b90218696d706f72742046756e6769626c65546f6b656e2066726f6d203078656538323835366266323065326161360a696d706f727420466c6f77546f6b656e2066726f6d203078306165353363623665336634326137390a0a7472616e73616374696f6e28616d6f756e743a205546697836342c20746f3a204164647265737329207b0a202020206c6574207661756c743a204046756e6769626c65546f6b656e2e5661756c740a0a2020202070726570617265287369676e65723a20417574684163636f756e7429207b0a202020202020202073656c662e7661756c74203c2d207369676e65720a2020202020202020202020202e626f72726f773c26466c6f77546f6b656e2e5661756c743e2866726f6d3a202f73746f726167652f666c6f77546f6b656e5661756c7429210a2020202020202020202020202e776974686472617728616d6f756e743a20616d6f756e74290a202020207d0a0a2020202065786563757465207b0a20202020202020206765744163636f756e7428746f290a2020202020202020202020202e6765744361706162696c697479282f7075626c69632f666c6f77546f6b656e5265636569766572290a2020202020202020202020202e626f72726f773c267b46756e6769626c65546f6b656e2e52656365697665727d3e2829210a2020202020202020202020202e6465706f7369742866726f6d3a203c2d73656c662e7661756c74290a202020207d0a7d0af858a77b2274797065223a22554669783634222c2276616c7565223a2231322e3334353030303030227daf7b2274797065223a2241646472657373222c2276616c7565223a22307831373962366231636236373535653331227da03c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e82270f88f8d6e0586b0a20c7800788179b6b1cb6755e31c98801cf0e2f2f715450",
+		EnvelopeMessage: "f90348f902b7b90218696d706f72742046756e6769626c65546f6b656e2066726f6d203078656538323835366266323065326161360a696d706f727420466c6f77546f6b656e2066726f6d203078306165353363623665336634326137390a0a7472616e73616374696f6e28616d6f756e743a205546697836342c20746f3a204164647265737329207b0a202020206c6574207661756c743a204046756e6769626c65546f6b656e2e5661756c740a0a2020202070726570617265287369676e65723a20417574684163636f756e7429207b0a202020202020202073656c662e7661756c74203c2d207369676e65720a2020202020202020202020202e626f72726f773c26466c6f77546f6b656e2e5661756c743e2866726f6d3a202f73746f726167652f666c6f77546f6b656e5661756c7429210a2020202020202020202020202e776974686472617728616d6f756e743a20616d6f756e74290a202020207d0a0a2020202065786563757465207b0a20202020202020206765744163636f756e7428746f290a2020202020202020202020202e6765744361706162696c697479282f7075626c69632f666c6f77546f6b656e5265636569766572290a2020202020202020202020202e626f72726f773c267b46756e6769626c65546f6b656e2e52656365697665727d3e2829210a2020202020202020202020202e6465706f7369742866726f6d3a203c2d73656c662e7661756c74290a202020207d0a7d0af858a77b2274797065223a22554669783634222c2276616c7565223a2231322e3334353030303030227daf7b2274797065223a2241646472657373222c2276616c7565223a22307831373962366231636236373535653331227da03c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e82270f88f8d6e0586b0a20c7800788179b6b1cb6755e31c98801cf0e2f2f715450f88cf8448080b84040466dad442242c8fcbeee99d46de2f541840e2bfd67b1847f85eb699773180c41093da1bcc7ce9ee2f21e678442226c6f09fac7923b6cf329549d69a1d073a8f8440280b840e3d41b5ba8598d62a32f3afcd0bace0a7547cf595e177cf0839f3f6dde2c59575b4ddaea1d58e5994d747c340e4e0c94ef9700c5b6fb24e7e54d04897804bc55",
+		Encoded:         "f90390f902b7b90218696d706f72742046756e6769626c65546f6b656e2066726f6d203078656538323835366266323065326161360a696d706f727420466c6f77546f6b656e2066726f6d203078306165353363623665336634326137390a0a7472616e73616374696f6e28616d6f756e743a205546697836342c20746f3a204164647265737329207b0a202020206c6574207661756c743a204046756e6769626c65546f6b656e2e5661756c740a0a2020202070726570617265287369676e65723a20417574684163636f756e7429207b0a202020202020202073656c662e7661756c74203c2d207369676e65720a2020202020202020202020202e626f72726f773c26466c6f77546f6b656e2e5661756c743e2866726f6d3a202f73746f726167652f666c6f77546f6b656e5661756c7429210a2020202020202020202020202e776974686472617728616d6f756e743a20616d6f756e74290a202020207d0a0a2020202065786563757465207b0a20202020202020206765744163636f756e7428746f290a2020202020202020202020202e6765744361706162696c697479282f7075626c69632f666c6f77546f6b656e5265636569766572290a2020202020202020202020202e626f72726f773c267b46756e6769626c65546f6b656e2e52656365697665727d3e2829210a2020202020202020202020202e6465706f7369742866726f6d3a203c2d73656c662e7661756c74290a202020207d0a7d0af858a77b2274797065223a22554669783634222c2276616c7565223a2231322e3334353030303030227daf7b2274797065223a2241646472657373222c2276616c7565223a22307831373962366231636236373535653331227da03c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e82270f88f8d6e0586b0a20c7800788179b6b1cb6755e31c98801cf0e2f2f715450f88cf8448080b84040466dad442242c8fcbeee99d46de2f541840e2bfd67b1847f85eb699773180c41093da1bcc7ce9ee2f21e678442226c6f09fac7923b6cf329549d69a1d073a8f8440280b840e3d41b5ba8598d62a32f3afcd0bace0a7547cf595e177cf0839f3f6dde2c59575b4ddaea1d58e5994d747c340e4e0c94ef9700c5b6fb24e7e54d04897804bc55f846f8440180b840b04247b4c626d2c95771e4f72aa8939465fac0d6801ac00d4367dfb8fae211a081fd39d680dc2bbdd3da9cbd671c2c21ba497c4a016525870bea276212365d30",
+		ID:              "5d387ddecbd126e75786814c98397813d5603ecc0731a2507f577e4c56d26374",
+	}
+}
+
+func multisigFixture() TransactionFixture {
+	const name = "multisig"
+
+	tx := newFixtureTransfer().
+		SetProposalKey(fixtureAddressB, fixtureKeyB.KeyIndex, 1000).
+		SetPayer(fixtureAddressC).
+		AddAuthorizer(fixtureAddressB)
+
+	signFixturePayload(name, tx, fixtureKeyB)
+	signFixtureEnvelope(name, tx, fixtureKeyC0, fixtureKeyC1)
+
+	return TransactionFixture{
+		Name:            name,
+		Transaction:     tx,
+		PayloadKeys:     []FixtureKey{fixtureKeyB},
+		EnvelopeKeys:    []FixtureKey{fixtureKeyC0, fixtureKeyC1},
+		PayloadMessage:  "f902b9b90218696d706f72742046756e6769626c65546f6b656e2066726f6d203078656538323835366266323065326161360a696d706f727420466c6f77546f6b656e2066726f6d203078306165353363623665336634326137390a0a7472616e73616374696f6e28616d6f756e743a205546697836342c20746f3a204164647265737329207b0a202020206c6574207661756c743a204046756e6769626c65546f6b656e2e5661756c740a0a2020202070726570617265287369676e65723a20417574684163636f756e7429207b0a202020202020202073656c662e7661756c74203c2d207369676e65720a2020202020202020202020202e626f72726f773c26466c6f77546f6b656e2e5661756c743e2866726f6d3a202f73746f726167652f666c6f77546f6b656e5661756c7429210a2020202020202020202020202e776974686472617728616d6f756e743a20616d6f756e74290a202020207d0a0a2020202065786563757465207b0a20202020202020206765744163636f756e7428746f290a2020202020202020202020202e6765744361706162696c697479282f7075626c69632f666c6f77546f6b656e5265636569766572290a2020202020202020202020202e626f72726f773c267b46756e6769626c65546f6b656e2e52656365697665727d3e2829210a2020202020202020202020202e6465706f7369742866726f6d3a203c2d73656c662e7661756c74290a202020207d0a7d0af858a77b2274797065223a22554669783634222c2276616c7565223a2231322e3334353030303030227daf7b2274797065223a2241646472657373222c2276616c7565223a22307831373962366231636236373535653331227da03c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e82270f8801cf0e2f2f715450808203e888179b6b1cb6755e31c98801cf0e2f2f715450",
+		EnvelopeMessage: "f90304f902b9b90218696d706f72742046756e6769626c65546f6b656e2066726f6d203078656538323835366266323065326161360a696d706f727420466c6f77546f6b656e2066726f6d203078306165353363623665336634326137390a0a7472616e73616374696f6e28616d6f756e743a205546697836342c20746f3a204164647265737329207b0a202020206c6574207661756c743a204046756e6769626c65546f6b656e2e5661756c740a0a2020202070726570617265287369676e65723a20417574684163636f756e7429207b0a202020202020202073656c662e7661756c74203c2d207369676e65720a2020202020202020202020202e626f72726f773c26466c6f77546f6b656e2e5661756c743e2866726f6d3a202f73746f726167652f666c6f77546f6b656e5661756c7429210a2020202020202020202020202e776974686472617728616d6f756e743a20616d6f756e74290a202020207d0a0a2020202065786563757465207b0a20202020202020206765744163636f756e7428746f290a2020202020202020202020202e6765744361706162696c697479282f7075626c69632f666c6f77546f6b656e5265636569766572290a2020202020202020202020202e626f72726f773c267b46756e6769626c65546f6b656e2e52656365697665727d3e2829210a2020202020202020202020202e6465706f7369742866726f6d3a203c2d73656c662e7661756c74290a202020207d0a7d0af858a77b2274797065223a22554669783634222c2276616c7565223a2231322e3334353030303030227daf7b2274797065223a2241646472657373222c2276616c7565223a22307831373962366231636236373535653331227da03c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e82270f8801cf0e2f2f715450808203e888179b6b1cb6755e31c98801cf0e2f2f715450f846f8448080b840077080ca0151520677aa68a997bca0b9db60ecc0f74bbe3b4023b18367e3fa2a488debee66c3de3ca14bc63b9bde1b628fbbba44a80e3a65d22076699832d626",
+		Encoded:         "f90392f902b9b90218696d706f72742046756e6769626c65546f6b656e2066726f6d203078656538323835366266323065326161360a696d706f727420466c6f77546f6b656e2066726f6d203078306165353363623665336634326137390a0a7472616e73616374696f6e28616d6f756e743a205546697836342c20746f3a204164647265737329207b0a202020206c6574207661756c743a204046756e6769626c65546f6b656e2e5661756c740a0a2020202070726570617265287369676e65723a20417574684163636f756e7429207b0a202020202020202073656c662e7661756c74203c2d207369676e65720a2020202020202020202020202e626f72726f773c26466c6f77546f6b656e2e5661756c743e2866726f6d3a202f73746f726167652f666c6f77546f6b656e5661756c7429210a2020202020202020202020202e776974686472617728616d6f756e743a20616d6f756e74290a202020207d0a0a2020202065786563757465207b0a20202020202020206765744163636f756e7428746f290a2020202020202020202020202e6765744361706162696c697479282f7075626c69632f666c6f77546f6b656e5265636569766572290a2020202020202020202020202e626f72726f773c267b46756e6769626c65546f6b656e2e52656365697665727d3e2829210a2020202020202020202020202e6465706f7369742866726f6d3a203c2d73656c662e7661756c74290a202020207d0a7d0af858a77b2274797065223a22554669783634222c2276616c7565223a2231322e3334353030303030227daf7b2274797065223a2241646472657373222c2276616c7565223a22307831373962366231636236373535653331227da03c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e82270f8801cf0e2f2f715450808203e888179b6b1cb6755e31c98801cf0e2f2f715450f846f8448080b840077080ca0151520677aa68a997bca0b9db60ecc0f74bbe3b4023b18367e3fa2a488debee66c3de3ca14bc63b9bde1b628fbbba44a80e3a65d22076699832d626f88cf8440180b840695b8c39a27d0bfdf634a036f0a28496072d9c56a30044bbd630fa88a2a505859d5b7e99126296ce23441e126b95905f2abc7b46dd6919d3f8e3df1f4c1e7529f8440101b840ba63bda6fc6063842d93bc6d341955424155454597425eb0321672c360b903ce16a521bce6b8907b0cc14ea2a08b92c0eda9a8c8db561d8e99a7887dc3e1b3ae",
+		ID:              "a2c0b69a9347dd5f2bb1cbe95eb1de7e6fa0ad96da6ecdc943e5b93769aa93a6",
+	}
+}
+
+func newFixtureTransfer() *flow.Transaction {
+	return flow.NewTransaction().
+		SetScript([]byte(fixtureTransferScript)).
+		AddRawArgument([]byte(`{"type":"UFix64","value":"12.34500000"}`)).
+		AddRawArgument([]byte(`{"type":"Address","value":"0x179b6b1cb6755e31"}`)).
+		SetReferenceBlockID(flow.HexToID("3c1e2f0a9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e")).
+		SetGasLimit(9999)
+}
+
+func signFixturePayload(name string, tx *flow.Transaction, keys ...FixtureKey) {
+	for _, key := range keys {
+		err := tx.SignPayload(key.Address, key.KeyIndex, key.Signer(name))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func signFixtureEnvelope(name string, tx *flow.Transaction, keys ...FixtureKey) {
+	for _, key := range keys {
+		err := tx.SignEnvelope(key.Address, key.KeyIndex, key.Signer(name))
+		if err != nil {
+			panic(err)
+		}
+	}
+}
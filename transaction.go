@@ -512,6 +512,59 @@ func (t *Transaction) DecodeFromPayloadBytes(bs []byte) error {
 	return nil
 }
 
+// ValidateKeyWeights returns ErrInsufficientKeyWeight if the keys that signed the
+// transaction for its payer or for one of its authorizers do not reach
+// AccountKeyWeightThreshold, e.g. if an authorizer only signed with a proposer-only
+// key. The keys of the payer and authorizers are looked up in the given accounts.
+//
+// Revoked keys, and keys that signed more than once, do not count.
+func (t *Transaction) ValidateKeyWeights(accounts ...*Account) error {
+	keys := make(map[Address]map[int]*AccountKey, len(accounts))
+	for _, account := range accounts {
+		accountKeys := make(map[int]*AccountKey, len(account.Keys))
+		for _, key := range account.Keys {
+			accountKeys[key.Index] = key
+		}
+		keys[account.Address] = accountKeys
+	}
+
+	weights := make(map[Address]int)
+	signed := make(map[Address]map[int]bool)
+
+	signatures := append(append([]TransactionSignature(nil), t.PayloadSignatures...), t.EnvelopeSignatures...)
+	for _, sig := range signatures {
+		if signed[sig.Address] == nil {
+			signed[sig.Address] = make(map[int]bool)
+		}
+
+		key, ok := keys[sig.Address][sig.KeyIndex]
+		if !ok || key.Revoked || signed[sig.Address][sig.KeyIndex] {
+			continue
+		}
+
+		signed[sig.Address][sig.KeyIndex] = true
+		weights[sig.Address] += key.Weight
+	}
+
+	for _, address := range append([]Address{t.Payer}, t.Authorizers...) {
+		if _, ok := keys[address]; !ok {
+			return fmt.Errorf("flow: no keys for account %s", address)
+		}
+
+		if weights[address] < AccountKeyWeightThreshold {
+			return fmt.Errorf(
+				"%w: keys of account %s that signed the transaction have weight %d, %d is required",
+				ErrInsufficientKeyWeight,
+				address,
+				weights[address],
+				AccountKeyWeightThreshold,
+			)
+		}
+	}
+
+	return nil
+}
+
 // A ProposalKey is the key that specifies the proposal key and sequence number for a transaction.
 type ProposalKey struct {
 	Address        Address
@@ -573,3 +573,33 @@ func TestTransaction_Encode(t *testing.T) {
 	assert.Equal(t, tx.EnvelopeSignatures, newTx.EnvelopeSignatures)
 	assert.Equal(t, tx.PayloadSignatures, newTx.PayloadSignatures)
 }
+
+func TestTransactionFixtures(t *testing.T) {
+	for _, fixture := range test.TransactionFixtures() {
+		fixture := fixture
+
+		t.Run(fixture.Name, func(t *testing.T) {
+			tx := fixture.Transaction
+
+			assert.Equal(t, fixture.PayloadMessage, hex.EncodeToString(tx.PayloadMessage()))
+			assert.Equal(t, fixture.EnvelopeMessage, hex.EncodeToString(tx.EnvelopeMessage()))
+			assert.Equal(t, fixture.Encoded, hex.EncodeToString(tx.Encode()))
+			assert.Equal(t, fixture.ID, tx.ID().Hex())
+			assert.NoError(t, fixture.Verify(tx))
+
+			decoded := new(flow.Transaction)
+			require.NoError(t, decoded.DecodeFromBytes(tx.Encode()))
+			assert.NoError(t, fixture.Verify(decoded))
+		})
+	}
+
+	t.Run("Tampered", func(t *testing.T) {
+		fixture, ok := test.TransactionFixtureByName("multi-party")
+		require.True(t, ok)
+
+		tx := fixture.Transaction
+		tx.EnvelopeSignatures[0].Signature[0] ^= 0xff
+
+		assert.Error(t, fixture.Verify(tx))
+	})
+}
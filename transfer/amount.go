@@ -0,0 +1,201 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// MaxDecimals is the number of decimal places of UFix64 amounts.
+const MaxDecimals = 8
+
+// ErrInvalidAmount is returned when a token amount cannot be parsed.
+var ErrInvalidAmount = errcode.New(errcode.InvalidAmount, "transfer: invalid amount")
+
+// scale is the factor between UFix64 amounts and their integer representation.
+const scale = 100000000
+
+// An AmountFormat is the notation of token amounts entered by or shown to users.
+type AmountFormat struct {
+	// DecimalSeparator separates the integer and fractional parts of amounts.
+	//
+	// Defaults to '.'.
+	DecimalSeparator rune
+	// GroupSeparator separates groups of three digits in the integer part of
+	// amounts, e.g. ',' in "1,000.5". Parsed amounts may omit group separators,
+	// but groups must be complete when they are used.
+	//
+	// Amounts are not grouped if GroupSeparator is zero.
+	GroupSeparator rune
+}
+
+// DefaultAmountFormat is the notation of amounts in Cadence, e.g. "1000.5".
+var DefaultAmountFormat = AmountFormat{DecimalSeparator: '.'}
+
+// ParseTokenAmount parses a decimal token amount in DefaultAmountFormat, e.g.
+// "12.345", for a token with the given number of decimal places.
+//
+// Parsing is exact and strict: amounts with more decimal places than the token
+// has, exponents, signs, or values too large for a UFix64 are rejected rather than
+// rounded. Surrounding whitespace is ignored.
+func ParseTokenAmount(s string, decimals int) (cadence.UFix64, error) {
+	return DefaultAmountFormat.Parse(s, decimals)
+}
+
+// FormatTokenAmount formats an amount in DefaultAmountFormat with the given number
+// of decimal places.
+func FormatTokenAmount(amount cadence.UFix64, precision int) string {
+	return DefaultAmountFormat.Format(amount, precision)
+}
+
+func (f AmountFormat) decimalSeparator() rune {
+	if f.DecimalSeparator == 0 {
+		return '.'
+	}
+	return f.DecimalSeparator
+}
+
+// Parse parses a token amount in this format for a token with the given number of
+// decimal places, as ParseTokenAmount.
+func (f AmountFormat) Parse(s string, decimals int) (cadence.UFix64, error) {
+	if decimals < 0 || decimals > MaxDecimals {
+		return 0, fmt.Errorf("transfer: decimals must be between 0 and %d, got %d", MaxDecimals, decimals)
+	}
+
+	input := strings.TrimSpace(s)
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidAmount, s, reason)
+	}
+
+	integer, fraction := input, ""
+	if i := strings.IndexRune(input, f.decimalSeparator()); i >= 0 {
+		integer, fraction = input[:i], input[i+utf8.RuneLen(f.decimalSeparator()):]
+		if fraction == "" {
+			return 0, invalid("missing decimal places")
+		}
+	}
+
+	if integer == "" {
+		return 0, invalid("missing integer part")
+	}
+
+	integer, err := f.ungroup(integer)
+	if err != nil {
+		return 0, invalid(err.Error())
+	}
+
+	if !isDigits(integer) || !isDigits(fraction) {
+		return 0, invalid("not a decimal number")
+	}
+
+	if len(fraction) > decimals {
+		return 0, invalid(fmt.Sprintf("more than %d decimal places", decimals))
+	}
+
+	var value uint64
+	for _, digit := range integer {
+		d := uint64(digit - '0')
+		if value > (math.MaxUint64/scale-d)/10 {
+			return 0, invalid("too large")
+		}
+		value = value*10 + d
+	}
+
+	var fractionValue uint64
+	unit := uint64(scale)
+	for _, digit := range fraction {
+		unit /= 10
+		fractionValue += uint64(digit-'0') * unit
+	}
+
+	value *= scale
+	if fractionValue > math.MaxUint64-value {
+		return 0, invalid("too large")
+	}
+
+	return cadence.UFix64(value + fractionValue), nil
+}
+
+// ungroup removes the group separators of the integer part of an amount, and
+// verifies that groups are complete.
+func (f AmountFormat) ungroup(integer string) (string, error) {
+	if f.GroupSeparator == 0 || !strings.ContainsRune(integer, f.GroupSeparator) {
+		return integer, nil
+	}
+
+	groups := strings.Split(integer, string(f.GroupSeparator))
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", fmt.Errorf("misplaced group separator")
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return "", fmt.Errorf("misplaced group separator")
+		}
+	}
+
+	return strings.Join(groups, ""), nil
+}
+
+// Format formats an amount in this format with the given number of decimal
+// places, between 0 and MaxDecimals. Decimal places beyond the precision are
+// truncated, never rounded up.
+func (f AmountFormat) Format(amount cadence.UFix64, precision int) string {
+	if precision < 0 {
+		precision = 0
+	}
+	if precision > MaxDecimals {
+		precision = MaxDecimals
+	}
+
+	integer := fmt.Sprintf("%d", uint64(amount)/scale)
+	fraction := fmt.Sprintf("%08d", uint64(amount)%scale)[:precision]
+
+	if f.GroupSeparator != 0 {
+		var b strings.Builder
+		for i, digit := range integer {
+			if i > 0 && (len(integer)-i)%3 == 0 {
+				b.WriteRune(f.GroupSeparator)
+			}
+			b.WriteRune(digit)
+		}
+		integer = b.String()
+	}
+
+	if fraction == "" {
+		return integer
+	}
+
+	return integer + string(f.decimalSeparator()) + fraction
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
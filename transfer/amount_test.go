@@ -0,0 +1,118 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+func TestParseTokenAmount(t *testing.T) {
+	valid := []struct {
+		input    string
+		decimals int
+		amount   cadence.UFix64
+	}{
+		{"0", 8, 0},
+		{"1", 0, 100000000},
+		{"12.345", 8, 1234500000},
+		{" 12.345\n", 3, 1234500000},
+		{"0.00000001", 8, 1},
+		{"007.10", 2, 710000000},
+		{"184467440737.09551615", 8, 18446744073709551615},
+	}
+
+	for _, test := range valid {
+		amount, err := transfer.ParseTokenAmount(test.input, test.decimals)
+		require.NoError(t, err, test.input)
+		assert.Equal(t, test.amount, amount, test.input)
+	}
+
+	invalid := []struct {
+		input    string
+		decimals int
+	}{
+		{"", 8},
+		{"abc", 8},
+		{"1.", 8},
+		{".5", 8},
+		{"-1", 8},
+		{"+1", 8},
+		{"1e5", 8},
+		{"1.2.3", 8},
+		{"1,000", 8},
+		{"0.000000001", 8},
+		{"1.234", 2},
+		{"1.5", 0},
+		{"184467440737.09551616", 8},
+		{"184467440738", 8},
+		{"99999999999999999999999", 8},
+	}
+
+	for _, test := range invalid {
+		_, err := transfer.ParseTokenAmount(test.input, test.decimals)
+		assert.True(t, errors.Is(err, transfer.ErrInvalidAmount), test.input)
+		assert.Equal(t, errcode.InvalidAmount, errcode.Of(err), test.input)
+	}
+
+	_, err := transfer.ParseTokenAmount("1", 9)
+	assert.Error(t, err)
+}
+
+func TestAmountFormat(t *testing.T) {
+	european := transfer.AmountFormat{DecimalSeparator: ',', GroupSeparator: '.'}
+
+	amount, err := european.Parse("1.234.567,89", 8)
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(123456789000000), amount)
+
+	amount, err = european.Parse("1234567,89", 8)
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(123456789000000), amount)
+
+	for _, input := range []string{"1.23.567,89", "1234.567", ".123", "1..000"} {
+		_, err := european.Parse(input, 8)
+		assert.True(t, errors.Is(err, transfer.ErrInvalidAmount), input)
+	}
+
+	assert.Equal(t, "1.234.567,89", european.Format(amount, 2))
+	assert.Equal(t, "1.234.567", european.Format(amount, 0))
+	assert.Equal(t, "123", european.Format(12300000000, 0))
+
+	assert.Equal(t, "1234567.8900", transfer.FormatTokenAmount(amount, 4))
+	assert.Equal(t, "0.00000001", transfer.FormatTokenAmount(1, 8))
+	assert.Equal(t, "0.99", transfer.FormatTokenAmount(99999999, 2))
+	assert.Equal(t, "0", transfer.FormatTokenAmount(0, 0))
+
+	for _, input := range []string{"0", "12.5", "184467440737.09551615"} {
+		amount, err := transfer.ParseTokenAmount(input, 8)
+		require.NoError(t, err)
+
+		parsed, err := transfer.ParseTokenAmount(transfer.FormatTokenAmount(amount, 8), 8)
+		require.NoError(t, err)
+		assert.Equal(t, amount, parsed)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrDuplicatePayment is returned by PaymentGuard.Submit for a payment that was
+// already submitted with another transaction.
+var ErrDuplicatePayment = errcode.New(errcode.DuplicatePayment, "transfer: payment was already submitted")
+
+// A DuplicatePaymentError is returned when a second transaction is submitted for a
+// payment.
+//
+// Use errors.Is(err, ErrDuplicatePayment) to test for it.
+type DuplicatePaymentError struct {
+	// Key is the idempotency key of the payment.
+	Key string
+	// TransactionID is the ID of the transaction first submitted for the payment.
+	TransactionID flow.Identifier
+	// Conflicting is true if the transactions make different payments, i.e. the key
+	// was reused for another payment.
+	Conflicting bool
+}
+
+func (e *DuplicatePaymentError) Error() string {
+	if e.Conflicting {
+		return fmt.Sprintf(
+			"transfer: payment key %s is already used by transaction %s for a different payment",
+			e.Key,
+			e.TransactionID,
+		)
+	}
+	return fmt.Sprintf("transfer: payment %s was already submitted with transaction %s", e.Key, e.TransactionID)
+}
+
+func (e *DuplicatePaymentError) Unwrap() error {
+	return ErrDuplicatePayment
+}
+
+// ErrorCode returns the code of ErrDuplicatePayment.
+func (e *DuplicatePaymentError) ErrorCode() errcode.Code {
+	return errcode.DuplicatePayment
+}
+
+// Fingerprint returns the semantic fingerprint of a transaction: a hex-encoded
+// SHA-256 hash of its script, arguments and payer.
+//
+// Arguments are normalized by decoding and re-encoding them, so that transactions
+// with differently formatted but equal arguments have the same fingerprint. The
+// reference block, proposal key and signatures are not part of the fingerprint, so
+// a payment that is signed again, e.g. after its first transaction expired, keeps
+// its fingerprint.
+func Fingerprint(tx *flow.Transaction) (string, error) {
+	scriptHash := sha256.Sum256(tx.Script)
+
+	var buf bytes.Buffer
+	buf.Write(scriptHash[:])
+
+	for i, arg := range tx.Arguments {
+		value, err := jsoncdc.Decode(arg)
+		if err != nil {
+			return "", fmt.Errorf("transfer: invalid argument %d: %w", i, err)
+		}
+
+		normalized, err := jsoncdc.Encode(value)
+		if err != nil {
+			return "", fmt.Errorf("transfer: invalid argument %d: %w", i, err)
+		}
+
+		argHash := sha256.Sum256(bytes.TrimSpace(normalized))
+		buf.Write(argHash[:])
+	}
+
+	buf.Write(tx.Payer.Bytes())
+
+	fingerprint := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(fingerprint[:]), nil
+}
+
+// A Payment is the record of a transaction submitted for a business payment.
+type Payment struct {
+	// Key is the idempotency key of the payment, e.g. the ID of a payout.
+	Key string `json:"key"`
+	// Fingerprint is the fingerprint of the transaction, as returned by Fingerprint.
+	Fingerprint string `json:"fingerprint"`
+	// TransactionID is the ID of the transaction.
+	TransactionID flow.Identifier `json:"transactionID"`
+}
+
+// A PaymentStore records the payments submitted by a PaymentGuard.
+//
+// Payouts are only protected across restarts and service instances if the store is
+// durable and shared, e.g. backed by a database table with a unique key.
+type PaymentStore interface {
+	// Reserve records the payment if no payment is recorded for its key, and returns
+	// true. Otherwise, it returns the recorded payment and false.
+	//
+	// Reserve must be atomic: of concurrent calls with the same key, only one records
+	// its payment.
+	Reserve(payment Payment) (Payment, bool, error)
+	// Release removes the payment recorded for the key, if any.
+	Release(key string) error
+}
+
+// MemoryPaymentStore is a PaymentStore that keeps payments in memory.
+type MemoryPaymentStore struct {
+	mu       sync.Mutex
+	payments map[string]Payment
+}
+
+var _ PaymentStore = &MemoryPaymentStore{}
+
+// NewMemoryPaymentStore returns an empty in-memory payment store.
+func NewMemoryPaymentStore() *MemoryPaymentStore {
+	return &MemoryPaymentStore{
+		payments: make(map[string]Payment),
+	}
+}
+
+// Reserve records the payment if no payment is recorded for its key, and returns
+// true. Otherwise, it returns the recorded payment and false.
+func (s *MemoryPaymentStore) Reserve(payment Payment) (Payment, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if recorded, ok := s.payments[payment.Key]; ok {
+		return recorded, false, nil
+	}
+
+	s.payments[payment.Key] = payment
+	return payment, true, nil
+}
+
+// Release removes the payment recorded for the key, if any.
+func (s *MemoryPaymentStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.payments, key)
+	return nil
+}
+
+// A PaymentGuard submits at most one transaction per business payment.
+//
+// Payout services retry payments that appear to fail, e.g. after a timeout or a
+// restart, and a retry that signs a new transaction pays twice if the first one was
+// executed. A PaymentGuard records the transaction submitted for the idempotency key
+// of each payment before sending it, and refuses to submit any other transaction for
+// the same key.
+//
+// Sending the recorded transaction again is allowed, as the network executes a
+// transaction at most once.
+type PaymentGuard struct {
+	store PaymentStore
+}
+
+// NewPaymentGuard returns a guard that records payments in the provided store.
+//
+// If store is nil, payments are kept in memory.
+func NewPaymentGuard(store PaymentStore) *PaymentGuard {
+	if store == nil {
+		store = NewMemoryPaymentStore()
+	}
+
+	return &PaymentGuard{store: store}
+}
+
+// Submit records tx as the transaction of the payment with the given key, and sends
+// it with c.
+//
+// A DuplicatePaymentError is returned, and tx is not sent, if another transaction
+// was recorded for the key.
+func (g *PaymentGuard) Submit(ctx context.Context, c flow.Client, key string, tx *flow.Transaction) error {
+	if err := g.Reserve(key, tx); err != nil {
+		return err
+	}
+
+	return c.SendTransaction(ctx, *tx)
+}
+
+// Reserve records tx as the transaction of the payment with the given key, without
+// sending it, e.g. for services that send transactions through a queue.
+//
+// A DuplicatePaymentError is returned if another transaction was recorded for the
+// key.
+func (g *PaymentGuard) Reserve(key string, tx *flow.Transaction) error {
+	fingerprint, err := Fingerprint(tx)
+	if err != nil {
+		return err
+	}
+
+	payment := Payment{
+		Key:           key,
+		Fingerprint:   fingerprint,
+		TransactionID: tx.ID(),
+	}
+
+	recorded, ok, err := g.store.Reserve(payment)
+	if err != nil {
+		return fmt.Errorf("transfer: failed to record payment: %w", err)
+	}
+
+	if ok || recorded.TransactionID == payment.TransactionID {
+		return nil
+	}
+
+	return &DuplicatePaymentError{
+		Key:           key,
+		TransactionID: recorded.TransactionID,
+		Conflicting:   recorded.Fingerprint != payment.Fingerprint,
+	}
+}
+
+// Release removes the record of the payment with the given key, so that another
+// transaction can be submitted for it.
+//
+// Only release a payment once its transaction is known to never execute, e.g. after
+// it expired or its execution failed.
+func (g *PaymentGuard) Release(key string) error {
+	if err := g.store.Release(key); err != nil {
+		return fmt.Errorf("transfer: failed to release payment: %w", err)
+	}
+	return nil
+}
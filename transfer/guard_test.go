@@ -0,0 +1,122 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client/mocks"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+func TestPaymentGuard(t *testing.T) {
+	ctx := context.Background()
+
+	token, err := transfer.FlowToken(flow.Emulator)
+	require.NoError(t, err)
+
+	sender := flow.HexToAddress("01")
+	recipient := flow.HexToAddress("02")
+
+	payout := func(amount cadence.UFix64, sequenceNumber uint64) *flow.Transaction {
+		tx, err := transfer.TransferTokens(token, amount, recipient, sender)
+		require.NoError(t, err)
+
+		return tx.
+			SetPayer(sender).
+			SetProposalKey(sender, 0, sequenceNumber)
+	}
+
+	t.Run("Fingerprint", func(t *testing.T) {
+		first, err := transfer.Fingerprint(payout(10, 1))
+		require.NoError(t, err)
+
+		// signing the payment again does not change its fingerprint
+		resigned, err := transfer.Fingerprint(payout(10, 2))
+		require.NoError(t, err)
+		assert.Equal(t, first, resigned)
+
+		// arguments are normalized
+		tx := payout(10, 1)
+		tx.Arguments[1] = []byte(" {\"value\": \"0x0000000000000002\", \"type\": \"Address\"}\n")
+		reformatted, err := transfer.Fingerprint(tx)
+		require.NoError(t, err)
+		assert.Equal(t, first, reformatted)
+
+		other, err := transfer.Fingerprint(payout(20, 1))
+		require.NoError(t, err)
+		assert.NotEqual(t, first, other)
+
+		tx = payout(10, 1)
+		tx.Arguments[0] = []byte("not json")
+		_, err = transfer.Fingerprint(tx)
+		assert.Error(t, err)
+	})
+
+	t.Run("Submit", func(t *testing.T) {
+		c := mocks.New()
+		guard := transfer.NewPaymentGuard(nil)
+
+		first := payout(10, 1)
+		require.NoError(t, guard.Submit(ctx, c, "payout-1", first))
+
+		// the recorded transaction may be sent again
+		require.NoError(t, guard.Submit(ctx, c, "payout-1", first))
+
+		err := guard.Submit(ctx, c, "payout-1", payout(10, 2))
+		assert.True(t, errors.Is(err, transfer.ErrDuplicatePayment))
+		assert.Equal(t, errcode.DuplicatePayment, errcode.Of(err))
+
+		var duplicate *transfer.DuplicatePaymentError
+		require.True(t, errors.As(err, &duplicate))
+		assert.Equal(t, "payout-1", duplicate.Key)
+		assert.Equal(t, first.ID(), duplicate.TransactionID)
+		assert.False(t, duplicate.Conflicting)
+
+		err = guard.Submit(ctx, c, "payout-1", payout(20, 2))
+		require.True(t, errors.As(err, &duplicate))
+		assert.True(t, duplicate.Conflicting)
+
+		require.NoError(t, guard.Submit(ctx, c, "payout-2", payout(20, 2)))
+
+		sent := c.SentTransactions()
+		require.Len(t, sent, 3)
+		assert.Equal(t, first.ID(), sent[0].ID())
+		assert.Equal(t, first.ID(), sent[1].ID())
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		c := mocks.New()
+		guard := transfer.NewPaymentGuard(transfer.NewMemoryPaymentStore())
+
+		require.NoError(t, guard.Submit(ctx, c, "payout-1", payout(10, 1)))
+		require.Error(t, guard.Submit(ctx, c, "payout-1", payout(10, 2)))
+
+		require.NoError(t, guard.Release("payout-1"))
+		assert.NoError(t, guard.Submit(ctx, c, "payout-1", payout(10, 2)))
+	})
+}
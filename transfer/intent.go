@@ -0,0 +1,326 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser2"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+// ErrUnrecognized is returned by AnalyzeTransaction for transactions that are not
+// built from a known transfer template.
+var ErrUnrecognized = errcode.New(errcode.UnrecognizedTransfer, "transfer: transaction is not a recognized token transfer")
+
+// An IntentKind is the kind of token moved by a transfer.
+type IntentKind string
+
+const (
+	// FungibleTransfer moves an amount of a fungible token.
+	FungibleTransfer IntentKind = "fungible"
+	// NonFungibleTransfer moves a single NFT.
+	NonFungibleTransfer IntentKind = "nonFungible"
+)
+
+// A TransferIntent is what a token transfer transaction does.
+type TransferIntent struct {
+	Kind IntentKind `json:"kind"`
+	// Contract is the name of the token contract.
+	Contract string `json:"contract,omitempty"`
+	// ContractAddress is the address the token contract is imported from.
+	ContractAddress flow.Address `json:"contractAddress"`
+	// Amount is the amount of a fungible token transfer.
+	Amount cadence.UFix64 `json:"amount,omitempty"`
+	// TokenID is the ID of the NFT of a non-fungible token transfer.
+	TokenID uint64 `json:"tokenID,omitempty"`
+	// Sender is the account the token is withdrawn from.
+	Sender flow.Address `json:"sender"`
+	// Recipient is the account the token is deposited to.
+	Recipient flow.Address `json:"recipient"`
+}
+
+const (
+	fungibleTokenContract    = "FungibleToken"
+	nonFungibleTokenContract = "NonFungibleToken"
+)
+
+// A Template is a transfer script whose effect is known, and the positions of the
+// arguments it binds to the transfer.
+type Template struct {
+	Kind IntentKind
+	// Script is the source of the template.
+	Script []byte
+	// Contract is the name of the token contract the template moves.
+	Contract string
+	// ContractAddress is the address of the token contract.
+	ContractAddress flow.Address
+	// TokenArgument is the index of the UFix64 amount of fungible transfers, or of
+	// the UInt64 ID of NFT transfers.
+	TokenArgument int
+	// RecipientArgument is the index of the Address argument of the recipient.
+	RecipientArgument int
+}
+
+// TokenTemplate returns the template of the transactions built by TransferTokens
+// for a token.
+func TokenTemplate(token Token) (Template, error) {
+	code, err := TransferTokensScript(token)
+	if err != nil {
+		return Template{}, err
+	}
+
+	return Template{
+		Kind:              FungibleTransfer,
+		Script:            code,
+		Contract:          token.Contract,
+		ContractAddress:   token.Address,
+		TokenArgument:     0,
+		RecipientArgument: 1,
+	}, nil
+}
+
+// A TemplateSet recognizes transfers built from a set of templates.
+//
+// Transactions are matched by the hash of their normalized script, so only the
+// exact template source is recognized; leading and trailing whitespace of lines
+// and blank lines are ignored.
+type TemplateSet struct {
+	mu        sync.RWMutex
+	templates map[[sha256.Size]byte]Template
+}
+
+// NewTemplateSet returns an empty template set.
+func NewTemplateSet() *TemplateSet {
+	return &TemplateSet{templates: make(map[[sha256.Size]byte]Template)}
+}
+
+// DefaultTemplates returns a template set with the FLOW token transfer template of
+// every built-in chain.
+func DefaultTemplates() *TemplateSet {
+	set := NewTemplateSet()
+
+	for _, chain := range []flow.ChainID{flow.Mainnet, flow.Testnet, flow.Emulator} {
+		token, err := FlowToken(chain)
+		if err != nil {
+			continue
+		}
+
+		template, err := TokenTemplate(token)
+		if err != nil {
+			continue
+		}
+
+		_ = set.Add(template)
+	}
+
+	return set
+}
+
+// Add adds a template to the set.
+//
+// An error is returned if the template is not a single transaction with two
+// parameters of the types its kind binds, or if it does not import exactly one of
+// the FungibleToken and NonFungibleToken standards, matching its kind.
+func (s *TemplateSet) Add(template Template) error {
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.templates[templateHash(template.Script)] = template
+
+	return nil
+}
+
+// Analyze extracts the intent of a transaction built from one of the templates of
+// the set.
+//
+// ErrUnrecognized is returned for any other transaction, for transactions with
+// more than one authorizer, and for arguments that do not decode to the types of
+// the template parameters.
+func (s *TemplateSet) Analyze(tx *flow.Transaction) (*TransferIntent, error) {
+	s.mu.RLock()
+	template, ok := s.templates[templateHash(tx.Script)]
+	s.mu.RUnlock()
+
+	if !ok || len(tx.Authorizers) != 1 || len(tx.Arguments) != 2 {
+		return nil, ErrUnrecognized
+	}
+
+	intent := &TransferIntent{
+		Kind:            template.Kind,
+		Contract:        template.Contract,
+		ContractAddress: template.ContractAddress,
+		Sender:          tx.Authorizers[0],
+	}
+
+	recipient, err := tx.Argument(template.RecipientArgument)
+	if err != nil {
+		return nil, fmt.Errorf("transfer: invalid recipient argument: %w", err)
+	}
+
+	address, ok := recipient.(cadence.Address)
+	if !ok {
+		return nil, ErrUnrecognized
+	}
+	intent.Recipient = flow.BytesToAddress(address.Bytes())
+
+	token, err := tx.Argument(template.TokenArgument)
+	if err != nil {
+		return nil, fmt.Errorf("transfer: invalid token argument: %w", err)
+	}
+
+	switch value := token.(type) {
+	case cadence.UFix64:
+		if template.Kind != FungibleTransfer {
+			return nil, ErrUnrecognized
+		}
+		intent.Amount = value
+	case cadence.UInt64:
+		if template.Kind != NonFungibleTransfer {
+			return nil, ErrUnrecognized
+		}
+		intent.TokenID = uint64(value)
+	default:
+		return nil, ErrUnrecognized
+	}
+
+	return intent, nil
+}
+
+// AnalyzeTransaction extracts the intent of a transaction built by TransferTokens
+// for the FLOW token of a built-in chain. Other templates are recognized by a
+// TemplateSet.
+//
+// ErrUnrecognized is returned for any other transaction.
+func AnalyzeTransaction(tx *flow.Transaction) (*TransferIntent, error) {
+	return defaultTemplates().Analyze(tx)
+}
+
+var (
+	defaultTemplatesOnce sync.Once
+	defaultTemplateSet   *TemplateSet
+)
+
+func defaultTemplates() *TemplateSet {
+	defaultTemplatesOnce.Do(func() {
+		defaultTemplateSet = DefaultTemplates()
+	})
+
+	return defaultTemplateSet
+}
+
+// templateHash returns the hash of a script with the leading and trailing
+// whitespace of its lines and its blank lines removed.
+func templateHash(code []byte) [sha256.Size]byte {
+	var normalized bytes.Buffer
+
+	for _, line := range bytes.Split(code, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		normalized.Write(line)
+		normalized.WriteByte('\n')
+	}
+
+	return sha256.Sum256(normalized.Bytes())
+}
+
+func validateTemplate(template Template) error {
+	var standard, tokenType string
+
+	switch template.Kind {
+	case FungibleTransfer:
+		standard, tokenType = fungibleTokenContract, "UFix64"
+	case NonFungibleTransfer:
+		standard, tokenType = nonFungibleTokenContract, "UInt64"
+	default:
+		return fmt.Errorf("transfer: unknown template kind %q", template.Kind)
+	}
+
+	program, err := parser2.ParseProgram(string(template.Script))
+	if err != nil {
+		return fmt.Errorf("transfer: invalid template: %w", err)
+	}
+
+	declarations := program.TransactionDeclarations()
+	if len(declarations) != 1 {
+		return errors.New("transfer: template must declare a single transaction")
+	}
+
+	var imported []string
+	for _, declaration := range program.ImportDeclarations() {
+		for _, identifier := range declaration.Identifiers {
+			switch identifier.Identifier {
+			case fungibleTokenContract, nonFungibleTokenContract:
+				imported = append(imported, identifier.Identifier)
+			}
+		}
+	}
+
+	if len(imported) != 1 || imported[0] != standard {
+		return fmt.Errorf("transfer: %s template must import %s and no other token standard", template.Kind, standard)
+	}
+
+	parameters := declarations[0].ParameterList
+	if parameters == nil || len(parameters.Parameters) != 2 {
+		return errors.New("transfer: template must declare two parameters")
+	}
+
+	if template.TokenArgument == template.RecipientArgument ||
+		template.TokenArgument < 0 || template.TokenArgument > 1 ||
+		template.RecipientArgument < 0 || template.RecipientArgument > 1 {
+		return errors.New("transfer: invalid template argument indexes")
+	}
+
+	if typeName(parameters.Parameters[template.TokenArgument]) != tokenType {
+		return fmt.Errorf("transfer: token parameter of %s template must be a %s", template.Kind, tokenType)
+	}
+
+	if typeName(parameters.Parameters[template.RecipientArgument]) != "Address" {
+		return errors.New("transfer: recipient parameter of template must be an Address")
+	}
+
+	return nil
+}
+
+func typeName(parameter *ast.Parameter) string {
+	if parameter.TypeAnnotation == nil {
+		return ""
+	}
+
+	nominal, ok := parameter.TypeAnnotation.Type.(*ast.NominalType)
+	if !ok || len(nominal.NestedIdentifiers) > 0 {
+		return ""
+	}
+
+	return nominal.Identifier.Identifier
+}
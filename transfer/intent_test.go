@@ -0,0 +1,215 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+const transferNFTScript = `
+import NonFungibleToken from 0x631e88ae7f1d7c20
+import ExampleNFT from 0x01cf0e2f2f715450
+
+transaction(withdrawID: UInt64, recipient: Address) {
+  prepare(signer: AuthAccount) {
+    let collection = signer.borrow<&ExampleNFT.Collection>(from: /storage/NFTCollection)!
+    let receiver = getAccount(recipient).getCapability(/public/NFTReceiver)
+      .borrow<&{NonFungibleToken.CollectionPublic}>()!
+
+    receiver.deposit(token: <-collection.withdraw(withdrawID: withdrawID))
+  }
+}
+`
+
+func TestAnalyzeTransaction(t *testing.T) {
+	token, err := transfer.FlowToken(flow.Emulator)
+	require.NoError(t, err)
+
+	sender := flow.HexToAddress("01")
+	recipient := flow.HexToAddress("02")
+
+	t.Run("Fungible", func(t *testing.T) {
+		amount, err := cadence.NewUFix64("12.5")
+		require.NoError(t, err)
+
+		tx, err := transfer.TransferTokens(token, amount, recipient, sender)
+		require.NoError(t, err)
+
+		intent, err := transfer.AnalyzeTransaction(tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, &transfer.TransferIntent{
+			Kind:            transfer.FungibleTransfer,
+			Contract:        "FlowToken",
+			ContractAddress: token.Address,
+			Amount:          amount,
+			Sender:          sender,
+			Recipient:       recipient,
+		}, intent)
+	})
+
+	t.Run("Non-fungible", func(t *testing.T) {
+		set := transfer.NewTemplateSet()
+		require.NoError(t, set.Add(transfer.Template{
+			Kind:              transfer.NonFungibleTransfer,
+			Script:            []byte(transferNFTScript),
+			Contract:          "ExampleNFT",
+			ContractAddress:   flow.HexToAddress("01cf0e2f2f715450"),
+			TokenArgument:     0,
+			RecipientArgument: 1,
+		}))
+
+		tx := flow.NewTransaction().
+			SetScript([]byte(transferNFTScript)).
+			AddAuthorizer(sender)
+		require.NoError(t, tx.AddArgument(cadence.NewUInt64(7)))
+		require.NoError(t, tx.AddArgument(cadence.NewAddress(recipient)))
+
+		intent, err := set.Analyze(tx)
+		require.NoError(t, err)
+
+		assert.Equal(t, transfer.NonFungibleTransfer, intent.Kind)
+		assert.Equal(t, "ExampleNFT", intent.Contract)
+		assert.Equal(t, uint64(7), intent.TokenID)
+		assert.Equal(t, sender, intent.Sender)
+		assert.Equal(t, recipient, intent.Recipient)
+
+		// NFT templates are not recognized unless registered
+		_, err = transfer.AnalyzeTransaction(tx)
+		assert.Equal(t, transfer.ErrUnrecognized, err)
+	})
+
+	t.Run("Normalized whitespace", func(t *testing.T) {
+		amount, err := cadence.NewUFix64("1.0")
+		require.NoError(t, err)
+
+		tx, err := transfer.TransferTokens(token, amount, recipient, sender)
+		require.NoError(t, err)
+
+		tx.SetScript([]byte("\n\n" + strings.Replace(string(tx.Script), "\n  ", "\n\t\t", -1) + "\n"))
+
+		intent, err := transfer.AnalyzeTransaction(tx)
+		require.NoError(t, err)
+		assert.Equal(t, amount, intent.Amount)
+	})
+
+	t.Run("Unrecognized", func(t *testing.T) {
+		setup, err := transfer.SetupVault(token, recipient)
+		require.NoError(t, err)
+
+		_, err = transfer.AnalyzeTransaction(setup)
+		assert.Equal(t, transfer.ErrUnrecognized, err)
+
+		amount, err := cadence.NewUFix64("1.0")
+		require.NoError(t, err)
+
+		// the canonical script with more than one authorizer
+		tx, err := transfer.TransferTokens(token, amount, recipient, sender)
+		require.NoError(t, err)
+		tx.AddAuthorizer(recipient)
+
+		_, err = transfer.AnalyzeTransaction(tx)
+		assert.Equal(t, transfer.ErrUnrecognized, err)
+	})
+
+	t.Run("Spoofed", func(t *testing.T) {
+		amount, err := cadence.NewUFix64("1.0")
+		require.NoError(t, err)
+
+		canonical, err := transfer.TransferTokensScript(token)
+		require.NoError(t, err)
+
+		scripts := map[string]string{
+			// the parameters of the template, but a hard-coded amount and recipient
+			"hard-coded": fmt.Sprintf(`
+				import FungibleToken from 0x%s
+				import FlowToken from 0x%s
+
+				transaction(amount: UFix64, to: Address) {
+				  prepare(signer: AuthAccount) {
+				    let vault = signer.borrow<&FlowToken.Vault>(from: /storage/flowTokenVault)!
+				    let receiver = getAccount(0x03).getCapability(/public/flowTokenReceiver)
+				      .borrow<&{FungibleToken.Receiver}>()!
+				    receiver.deposit(from: <-vault.withdraw(amount: 1000.0))
+				  }
+				}`, token.FungibleTokenAddress.Hex(), token.Address.Hex()),
+			// the canonical template with an extra statement
+			"modified": strings.Replace(
+				string(canonical),
+				"self.sentVault <- vault.withdraw(amount: amount)",
+				"self.sentVault <- vault.withdraw(amount: amount)\n    signer.unlink(/public/flowTokenReceiver)",
+				1,
+			),
+		}
+
+		for name, script := range scripts {
+			tx := flow.NewTransaction().
+				SetScript([]byte(script)).
+				AddAuthorizer(sender)
+			require.NoError(t, tx.AddArgument(amount))
+			require.NoError(t, tx.AddArgument(cadence.NewAddress(recipient)))
+
+			_, err := transfer.AnalyzeTransaction(tx)
+			assert.Equal(t, transfer.ErrUnrecognized, err, name)
+		}
+
+		// the canonical script with arguments of the wrong types
+		tx := flow.NewTransaction().
+			SetScript(canonical).
+			AddAuthorizer(sender)
+		require.NoError(t, tx.AddArgument(cadence.NewAddress(recipient)))
+		require.NoError(t, tx.AddArgument(amount))
+
+		_, err = transfer.AnalyzeTransaction(tx)
+		assert.Equal(t, transfer.ErrUnrecognized, err)
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		set := transfer.NewTemplateSet()
+
+		// importing both token standards is ambiguous
+		err := set.Add(transfer.Template{
+			Kind: transfer.NonFungibleTransfer,
+			Script: []byte(`
+				import FungibleToken from 0xee82856bf20e2aa6
+				` + transferNFTScript),
+			TokenArgument:     0,
+			RecipientArgument: 1,
+		})
+		assert.Error(t, err)
+
+		// token and recipient arguments swapped
+		err = set.Add(transfer.Template{
+			Kind:              transfer.NonFungibleTransfer,
+			Script:            []byte(transferNFTScript),
+			TokenArgument:     1,
+			RecipientArgument: 0,
+		})
+		assert.Error(t, err)
+	})
+}
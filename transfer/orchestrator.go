@@ -0,0 +1,423 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/addressbook"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+)
+
+const (
+	// DefaultGasLimit is the gas limit of transactions sent by an Orchestrator.
+	DefaultGasLimit = 9999
+	// DefaultMaxSetupAttempts is the number of failed setup transactions after which
+	// an Orchestrator abandons an operation.
+	DefaultMaxSetupAttempts = 3
+	// DefaultPollInterval is the time between two status requests of an Orchestrator
+	// waiting for a transaction to be sealed.
+	DefaultPollInterval = time.Second
+)
+
+// ErrTransferFailed is returned by Orchestrator.Run for operations that were
+// abandoned after a transaction failed.
+var ErrTransferFailed = errcode.New(errcode.TransferFailed, "transfer: operation failed")
+
+// A Signer signs transactions with a key of an account.
+type Signer struct {
+	Address  flow.Address
+	KeyIndex int
+	Signer   crypto.Signer
+}
+
+// A Step is the next step of a transfer Operation.
+type Step string
+
+const (
+	// StepCheck verifies that the recipient can receive the token.
+	StepCheck Step = "check"
+	// StepSetup sends the transaction that sets up the vault of the recipient.
+	StepSetup Step = "setup"
+	// StepTransfer sends the transfer transaction.
+	StepTransfer Step = "transfer"
+	// StepDone means that the transfer transaction is sealed.
+	StepDone Step = "done"
+	// StepFailed means that the operation was abandoned after a transaction failed.
+	StepFailed Step = "failed"
+)
+
+// An Operation is the state of a transfer performed by an Orchestrator.
+//
+// Operations can be marshaled to JSON and persisted between steps, so that an
+// interrupted transfer is resumed where it stopped instead of being sent twice.
+type Operation struct {
+	Recipient flow.Address `json:"recipient"`
+	// RecipientName is the name the recipient address was resolved from, if any.
+	RecipientName string         `json:"recipientName,omitempty"`
+	Amount        cadence.UFix64 `json:"amount"`
+	Step          Step           `json:"step"`
+	// SetupTransaction is the encoded, signed setup transaction, once it is created.
+	SetupTransaction []byte `json:"setupTransaction,omitempty"`
+	// TransferTransaction is the encoded, signed transfer transaction, once it is created.
+	TransferTransaction []byte `json:"transferTransaction,omitempty"`
+	// SetupAttempts is the number of setup transactions that failed.
+	SetupAttempts int `json:"setupAttempts,omitempty"`
+	// Error is the error of the transaction that failed the operation.
+	Error string `json:"error,omitempty"`
+}
+
+// NewOperation returns an operation that transfers an amount of tokens to the recipient.
+func NewOperation(recipient flow.Address, amount cadence.UFix64) *Operation {
+	return &Operation{
+		Recipient: recipient,
+		Amount:    amount,
+		Step:      StepCheck,
+	}
+}
+
+// NewNamedOperation returns an operation that transfers an amount of tokens to a
+// recipient given as a 0x-prefixed address or as a name resolved by r.
+//
+// The name is resolved once, when the operation is created, so that a resumed
+// operation always pays the same address.
+func NewNamedOperation(
+	ctx context.Context,
+	r addressbook.Resolver,
+	recipient string,
+	amount cadence.UFix64,
+) (*Operation, error) {
+	address, err := addressbook.Resolve(ctx, r, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	op := NewOperation(address, amount)
+	if !strings.HasPrefix(recipient, "0x") {
+		op.RecipientName = recipient
+	}
+
+	return op, nil
+}
+
+// An Orchestrator performs token transfers, and sets up the vault of recipients
+// that cannot receive the token before transferring to them.
+type Orchestrator struct {
+	Client flow.Client
+	Token  Token
+	// Sender authorizes and pays for transfer transactions.
+	Sender Signer
+	// Sponsor pays for setup transactions.
+	//
+	// Recipients are not set up if Sponsor or RecipientSigner is nil.
+	Sponsor *Signer
+	// RecipientSigner returns the signer that authorizes the setup transaction of a
+	// recipient, e.g. the custodial key of a user account.
+	RecipientSigner func(ctx context.Context, recipient flow.Address) (*Signer, error)
+	// MinAvailableStorage is the storage, in bytes, that recipients must have available.
+	MinAvailableStorage uint64
+	// GasLimit is the gas limit of transactions.
+	//
+	// Defaults to DefaultGasLimit.
+	GasLimit uint64
+	// MaxSetupAttempts is the number of failed setup transactions after which an
+	// operation is abandoned.
+	//
+	// Defaults to DefaultMaxSetupAttempts.
+	MaxSetupAttempts int
+	// PollInterval is the time between two status requests while waiting for a
+	// transaction to be sealed.
+	//
+	// Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+	// Save persists the operation, and is called before each transaction is sent and
+	// after each step. An operation that failed to save is not continued.
+	Save func(op *Operation) error
+}
+
+// Run performs the remaining steps of an operation.
+//
+// A transaction that was created, but may not have been received by the network,
+// is sent again when its operation is resumed. A setup or transfer transaction that
+// expires is recreated.
+//
+// A failed setup transaction sends the operation back to StepCheck, and is
+// recreated if the recipient still cannot receive the token. The operation moves to
+// StepFailed after MaxSetupAttempts failed setup transactions, or when the transfer
+// transaction fails, and Run returns an error wrapping ErrTransferFailed, for this
+// call and for any later one.
+func (o *Orchestrator) Run(ctx context.Context, op *Operation) error {
+	for {
+		var err error
+
+		switch op.Step {
+		case "", StepCheck:
+			err = o.check(ctx, op)
+		case StepSetup:
+			err = o.setup(ctx, op)
+		case StepTransfer:
+			err = o.transfer(ctx, op)
+		case StepDone:
+			return nil
+		case StepFailed:
+			return fmt.Errorf("%w: %s", ErrTransferFailed, op.Error)
+		default:
+			return fmt.Errorf("transfer: unknown step %q", op.Step)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := o.save(op); err != nil {
+			return err
+		}
+	}
+}
+
+func (o *Orchestrator) check(ctx context.Context, op *Operation) error {
+	err := CheckTransferPreconditions(ctx, o.Client, o.Token, op.Recipient, o.MinAvailableStorage)
+	if errors.Is(err, ErrNoReceiver) && o.Sponsor != nil && o.RecipientSigner != nil {
+		op.Step = StepSetup
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	op.Step = StepTransfer
+	return nil
+}
+
+func (o *Orchestrator) setup(ctx context.Context, op *Operation) error {
+	if op.SetupTransaction == nil {
+		recipient, err := o.RecipientSigner(ctx, op.Recipient)
+		if err != nil {
+			return err
+		}
+
+		tx, err := SetupVault(o.Token, op.Recipient)
+		if err != nil {
+			return err
+		}
+
+		op.SetupTransaction, err = o.sign(ctx, tx, *recipient, *o.Sponsor)
+		if err != nil {
+			return err
+		}
+
+		if err := o.save(op); err != nil {
+			return err
+		}
+	}
+
+	result, err := o.submit(ctx, op.SetupTransaction)
+	if err != nil {
+		return err
+	}
+
+	if result.Status == flow.TransactionStatusExpired {
+		op.SetupTransaction = nil
+		op.Step = StepCheck
+		return nil
+	}
+
+	if result.Error != nil {
+		op.SetupTransaction = nil
+		op.SetupAttempts++
+
+		if op.SetupAttempts >= o.maxSetupAttempts() {
+			op.fail(fmt.Errorf("setup transaction failed: %w", result.Error))
+			return nil
+		}
+
+		op.Step = StepCheck
+		return nil
+	}
+
+	op.Step = StepTransfer
+	return nil
+}
+
+func (o *Orchestrator) transfer(ctx context.Context, op *Operation) error {
+	if op.TransferTransaction == nil {
+		tx, err := TransferTokens(o.Token, op.Amount, op.Recipient, o.Sender.Address)
+		if err != nil {
+			return err
+		}
+
+		op.TransferTransaction, err = o.sign(ctx, tx, o.Sender, o.Sender)
+		if err != nil {
+			return err
+		}
+
+		if err := o.save(op); err != nil {
+			return err
+		}
+	}
+
+	result, err := o.submit(ctx, op.TransferTransaction)
+	if err != nil {
+		return err
+	}
+
+	if result.Status == flow.TransactionStatusExpired {
+		op.TransferTransaction = nil
+		return nil
+	}
+
+	if result.Error != nil {
+		op.fail(fmt.Errorf("transfer transaction failed: %w", result.Error))
+		return nil
+	}
+
+	op.Step = StepDone
+	return nil
+}
+
+// sign completes a transaction with the proposer key of the authorizer, signs it and
+// returns its encoding.
+func (o *Orchestrator) sign(
+	ctx context.Context,
+	tx *flow.Transaction,
+	authorizer Signer,
+	payer Signer,
+) ([]byte, error) {
+	header, err := o.Client.GetLatestBlockHeader(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := accountKey(ctx, o.Client, authorizer.Address, authorizer.KeyIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit := o.GasLimit
+	if gasLimit == 0 {
+		gasLimit = DefaultGasLimit
+	}
+
+	tx.SetReferenceBlockID(header.ID).
+		SetGasLimit(gasLimit).
+		SetProposalKey(authorizer.Address, key.Index, key.SequenceNumber).
+		SetPayer(payer.Address)
+
+	if authorizer.Address != payer.Address {
+		err = tx.SignPayload(authorizer.Address, authorizer.KeyIndex, authorizer.Signer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.SignEnvelope(payer.Address, payer.KeyIndex, payer.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.Encode(), nil
+}
+
+// submit sends an encoded transaction unless the network already knows it, and waits
+// for it to be sealed or to expire.
+func (o *Orchestrator) submit(ctx context.Context, encoded []byte) (*flow.TransactionResult, error) {
+	var tx flow.Transaction
+	if err := tx.DecodeFromBytes(encoded); err != nil {
+		return nil, fmt.Errorf("transfer: invalid transaction: %w", err)
+	}
+
+	_, err := o.Client.GetTransactionResult(ctx, tx.ID())
+	if errors.Is(err, client.ErrNotFound) {
+		err = o.Client.SendTransaction(ctx, tx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return o.wait(ctx, tx.ID())
+}
+
+// wait polls the result of a transaction until it is sealed or expires.
+func (o *Orchestrator) wait(ctx context.Context, id flow.Identifier) (*flow.TransactionResult, error) {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	for {
+		result, err := o.Client.GetTransactionResult(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Status == flow.TransactionStatusSealed || result.Status == flow.TransactionStatusExpired {
+			return result, nil
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (o *Orchestrator) maxSetupAttempts() int {
+	if o.MaxSetupAttempts <= 0 {
+		return DefaultMaxSetupAttempts
+	}
+	return o.MaxSetupAttempts
+}
+
+func (o *Orchestrator) save(op *Operation) error {
+	if o.Save == nil {
+		return nil
+	}
+	return o.Save(op)
+}
+
+// fail abandons the operation.
+func (op *Operation) fail(err error) {
+	op.Step = StepFailed
+	op.Error = err.Error()
+}
+
+func accountKey(ctx context.Context, c flow.Client, address flow.Address, index int) (*flow.AccountKey, error) {
+	account, err := c.GetAccount(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range account.Keys {
+		if key.Index == index {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("transfer: account %s has no key at index %d", address, index)
+}
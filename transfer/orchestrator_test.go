@@ -0,0 +1,310 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/addressbook"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/client/convert"
+	"github.com/portto/blocto-flow-go-sdk/crypto"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+// network is a fake Access API node that seals every transaction it receives.
+type network struct {
+	key      *flow.AccountKey
+	receiver bool
+	sent     []flow.Transaction
+	fail     bool
+	// failSetup fails the setup transactions
+	failSetup bool
+}
+
+func (n *network) Invoke(
+	_ context.Context,
+	method string,
+	args interface{},
+	reply interface{},
+	_ ...grpc.CallOption,
+) error {
+	switch method {
+	case client.MethodGetLatestBlockHeader:
+		reply.(*access.BlockHeaderResponse).Block = &entities.BlockHeader{Id: make([]byte, 32), Height: 10}
+	case client.MethodGetAccountAtLatestBlock:
+		address := flow.BytesToAddress(args.(*access.GetAccountAtLatestBlockRequest).Address)
+		reply.(*access.AccountResponse).Account = convert.AccountToMessage(flow.Account{
+			Address: address,
+			Keys:    []*flow.AccountKey{n.key},
+		})
+	case client.MethodExecuteScriptAtLatestBlock:
+		var receiver uint64
+		if n.receiver {
+			receiver = 1
+		}
+		reply.(*access.ExecuteScriptResponse).Value = jsoncdc.MustEncode(cadence.NewDictionary([]cadence.KeyValuePair{
+			{Key: cadence.NewString("receiver"), Value: cadence.NewUInt64(receiver)},
+			{Key: cadence.NewString("storageUsed"), Value: cadence.NewUInt64(100)},
+			{Key: cadence.NewString("storageCapacity"), Value: cadence.NewUInt64(1000)},
+		}))
+	case client.MethodSendTransaction:
+		tx, err := convert.MessageToTransaction(args.(*access.SendTransactionRequest).Transaction)
+		if err != nil {
+			return err
+		}
+		n.sent = append(n.sent, tx)
+		if strings.Contains(string(tx.Script), "createEmptyVault") && !n.failSetup {
+			n.receiver = true
+		}
+	case client.MethodGetTransactionResult:
+		id := flow.HashToID(args.(*access.GetTransactionRequest).Id)
+		for _, tx := range n.sent {
+			if tx.ID() == id {
+				res := reply.(*access.TransactionResultResponse)
+				res.Status = entities.TransactionStatus_SEALED
+				if n.fail || n.failSetup && strings.Contains(string(tx.Script), "createEmptyVault") {
+					res.StatusCode = 1
+					res.ErrorMessage = "panic"
+				}
+				return nil
+			}
+		}
+		return status.Error(codes.NotFound, "not found")
+	}
+	return nil
+}
+
+func newSigner(t *testing.T, address flow.Address) (transfer.Signer, *flow.AccountKey) {
+	seed := make([]byte, crypto.MinSeedLength)
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed)
+	require.NoError(t, err)
+
+	key := flow.NewAccountKey().
+		FromPrivateKey(privateKey).
+		SetHashAlgo(crypto.SHA3_256).
+		SetWeight(flow.AccountKeyWeightThreshold)
+
+	return transfer.Signer{
+		Address: address,
+		Signer:  crypto.NewInMemorySigner(privateKey, crypto.SHA3_256),
+	}, key
+}
+
+func TestOrchestrator(t *testing.T) {
+	ctx := context.Background()
+
+	token, err := transfer.FlowToken(flow.Emulator)
+	require.NoError(t, err)
+
+	sender, key := newSigner(t, flow.HexToAddress("01"))
+	sponsor, _ := newSigner(t, flow.HexToAddress("02"))
+	recipient, _ := newSigner(t, flow.HexToAddress("03"))
+
+	newOrchestrator := func(n *network, setup bool) *transfer.Orchestrator {
+		o := &transfer.Orchestrator{
+			Client: client.NewFromEndpoint(client.NewEndpoint("node", n)),
+			Token:  token,
+			Sender: sender,
+		}
+		if setup {
+			o.Sponsor = &sponsor
+			o.RecipientSigner = func(context.Context, flow.Address) (*transfer.Signer, error) {
+				return &recipient, nil
+			}
+		}
+		return o
+	}
+
+	amount, err := cadence.NewUFix64("1.5")
+	require.NoError(t, err)
+
+	t.Run("Scripts", func(t *testing.T) {
+		for _, build := range []func(transfer.Token) ([]byte, error){
+			transfer.SetupVaultScript,
+			transfer.TransferTokensScript,
+		} {
+			code, err := build(token)
+			require.NoError(t, err)
+
+			_, err = parser2.ParseProgram(string(code))
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Sets up recipient", func(t *testing.T) {
+		n := &network{key: key}
+		o := newOrchestrator(n, true)
+
+		var steps []transfer.Step
+		o.Save = func(op *transfer.Operation) error {
+			steps = append(steps, op.Step)
+			return nil
+		}
+
+		op := transfer.NewOperation(recipient.Address, amount)
+		require.NoError(t, o.Run(ctx, op))
+
+		assert.Equal(t, transfer.StepDone, op.Step)
+		require.Len(t, n.sent, 2)
+
+		setup := n.sent[0]
+		assert.Equal(t, recipient.Address, setup.ProposalKey.Address)
+		assert.Equal(t, sponsor.Address, setup.Payer)
+		assert.Equal(t, []flow.Address{recipient.Address}, setup.Authorizers)
+
+		assert.Equal(t, sender.Address, n.sent[1].Payer)
+		assert.Equal(t, []transfer.Step{
+			transfer.StepSetup,
+			transfer.StepSetup,
+			transfer.StepTransfer,
+			transfer.StepTransfer,
+			transfer.StepDone,
+		}, steps)
+	})
+
+	t.Run("Named recipient", func(t *testing.T) {
+		book := addressbook.Book{"alice": recipient.Address}
+
+		op, err := transfer.NewNamedOperation(ctx, book, "alice", amount)
+		require.NoError(t, err)
+		assert.Equal(t, recipient.Address, op.Recipient)
+		assert.Equal(t, "alice", op.RecipientName)
+
+		op, err = transfer.NewNamedOperation(ctx, book, "0x03", amount)
+		require.NoError(t, err)
+		assert.Equal(t, recipient.Address, op.Recipient)
+		assert.Empty(t, op.RecipientName)
+
+		_, err = transfer.NewNamedOperation(ctx, book, "bob", amount)
+		assert.True(t, errors.Is(err, addressbook.ErrNotFound))
+	})
+
+	t.Run("Without sponsor", func(t *testing.T) {
+		n := &network{key: key}
+		o := newOrchestrator(n, false)
+
+		op := transfer.NewOperation(recipient.Address, amount)
+		err := o.Run(ctx, op)
+
+		assert.True(t, errors.Is(err, transfer.ErrNoReceiver))
+		assert.Equal(t, transfer.StepCheck, op.Step)
+		assert.Empty(t, n.sent)
+	})
+
+	t.Run("Resumes without sending twice", func(t *testing.T) {
+		n := &network{key: key, receiver: true}
+		o := newOrchestrator(n, false)
+
+		stop := errors.New("stop")
+		o.Save = func(op *transfer.Operation) error {
+			// stop right before the transfer transaction is sent
+			if op.TransferTransaction != nil {
+				return stop
+			}
+			return nil
+		}
+
+		op := transfer.NewOperation(recipient.Address, amount)
+		require.Equal(t, stop, o.Run(ctx, op))
+		require.Empty(t, n.sent)
+
+		// the transaction reached the network before the process stopped
+		var tx flow.Transaction
+		require.NoError(t, tx.DecodeFromBytes(op.TransferTransaction))
+		n.sent = append(n.sent, tx)
+
+		// the operation survives a round trip through its persisted form
+		data, err := json.Marshal(op)
+		require.NoError(t, err)
+
+		var resumed transfer.Operation
+		require.NoError(t, json.Unmarshal(data, &resumed))
+
+		o.Save = nil
+		require.NoError(t, o.Run(ctx, &resumed))
+
+		assert.Equal(t, transfer.StepDone, resumed.Step)
+		assert.Len(t, n.sent, 1)
+	})
+
+	t.Run("Failed transfer", func(t *testing.T) {
+		n := &network{key: key, receiver: true, fail: true}
+		o := newOrchestrator(n, false)
+
+		op := transfer.NewOperation(recipient.Address, amount)
+		err := o.Run(ctx, op)
+
+		assert.True(t, errors.Is(err, transfer.ErrTransferFailed))
+		assert.Equal(t, transfer.StepFailed, op.Step)
+		assert.Contains(t, op.Error, "panic")
+		assert.Len(t, n.sent, 1)
+
+		// a failed operation is not sent again
+		err = o.Run(ctx, op)
+		assert.True(t, errors.Is(err, transfer.ErrTransferFailed))
+		assert.Len(t, n.sent, 1)
+	})
+
+	t.Run("Failed setup", func(t *testing.T) {
+		n := &network{key: key, failSetup: true}
+		o := newOrchestrator(n, true)
+		o.MaxSetupAttempts = 2
+
+		var steps []transfer.Step
+		o.Save = func(op *transfer.Operation) error {
+			steps = append(steps, op.Step)
+			return nil
+		}
+
+		op := transfer.NewOperation(recipient.Address, amount)
+		err := o.Run(ctx, op)
+
+		assert.True(t, errors.Is(err, transfer.ErrTransferFailed))
+		assert.Equal(t, transfer.StepFailed, op.Step)
+		assert.Equal(t, 2, op.SetupAttempts)
+		assert.Nil(t, op.TransferTransaction)
+
+		// the setup is checked and recreated after the first failure
+		assert.Equal(t, []transfer.Step{
+			transfer.StepSetup,
+			transfer.StepSetup,
+			transfer.StepCheck,
+			transfer.StepSetup,
+			transfer.StepSetup,
+			transfer.StepFailed,
+		}, steps)
+	})
+}
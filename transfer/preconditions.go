@@ -0,0 +1,177 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onflow/cadence"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/errcode"
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+var (
+	// ErrNoReceiver means that the recipient has no valid receiver capability for the token.
+	ErrNoReceiver = errcode.New(errcode.NoReceiver, "transfer: recipient has no receiver for this token")
+	// ErrInsufficientStorage means that the recipient does not have enough available storage.
+	ErrInsufficientStorage = errcode.New(errcode.InsufficientStorage, "transfer: recipient has insufficient storage")
+)
+
+// A PreconditionError is returned when a transfer to a recipient would fail.
+//
+// Use errors.Is to test for the reason, e.g. errors.Is(err, ErrNoReceiver).
+type PreconditionError struct {
+	// Reason is ErrNoReceiver or ErrInsufficientStorage.
+	Reason    error
+	Recipient flow.Address
+	// StorageUsed and StorageCapacity are the storage of the recipient, in bytes.
+	StorageUsed     uint64
+	StorageCapacity uint64
+}
+
+func (e *PreconditionError) Error() string {
+	if e.Reason == ErrInsufficientStorage {
+		return fmt.Sprintf(
+			"%s (0x%s uses %d of %d bytes)",
+			e.Reason,
+			e.Recipient.Hex(),
+			e.StorageUsed,
+			e.StorageCapacity,
+		)
+	}
+	return fmt.Sprintf("%s (0x%s)", e.Reason, e.Recipient.Hex())
+}
+
+func (e *PreconditionError) Unwrap() error {
+	return e.Reason
+}
+
+// ErrorCode returns the code of the reason of this error.
+func (e *PreconditionError) ErrorCode() errcode.Code {
+	return errcode.Of(e.Reason)
+}
+
+const recipientStatusFragment = `
+let account = getAccount(recipient)
+let receiver = account.getCapability<&{FungibleToken.Receiver}>({{receiverPath}})
+
+return {
+  "receiver": receiver.check() ? 1 as UInt64 : 0 as UInt64,
+  "storageUsed": account.storageUsed,
+  "storageCapacity": account.storageCapacity
+}`
+
+// RecipientStatusScript returns a script that reports whether an account can receive
+// the given token, along with its storage usage and capacity.
+func RecipientStatusScript(token Token) ([]byte, error) {
+	receiverPath, err := script.Path(script.PathDomainPublic, token.ReceiverPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return script.NewBuilder().
+		Import("FungibleToken", token.FungibleTokenAddress).
+		Main("recipient: Address", "{String: UInt64}").
+		Body(script.Fragment{Source: recipientStatusFragment}, script.Values{
+			"receiverPath": receiverPath,
+		}).
+		Build()
+}
+
+// CheckTransferPreconditions verifies that the recipient can receive the given token.
+//
+// A PreconditionError is returned if the recipient has no receiver capability for the
+// token, or has less than minAvailableStorage bytes of storage available. Other
+// errors are returned if the recipient cannot be inspected.
+func CheckTransferPreconditions(
+	ctx context.Context,
+	c flow.Client,
+	token Token,
+	recipient flow.Address,
+	minAvailableStorage uint64,
+) error {
+	code, err := RecipientStatusScript(token)
+	if err != nil {
+		return err
+	}
+
+	value, err := c.ExecuteScriptAtLatestBlock(ctx, code, []cadence.Value{cadence.NewAddress(recipient)})
+	if err != nil {
+		return err
+	}
+
+	status, err := decodeStatus(value)
+	if err != nil {
+		return err
+	}
+
+	if status["receiver"] == 0 {
+		return &PreconditionError{
+			Reason:          ErrNoReceiver,
+			Recipient:       recipient,
+			StorageUsed:     status["storageUsed"],
+			StorageCapacity: status["storageCapacity"],
+		}
+	}
+
+	used, capacity := status["storageUsed"], status["storageCapacity"]
+	if used > capacity || capacity-used < minAvailableStorage {
+		return &PreconditionError{
+			Reason:          ErrInsufficientStorage,
+			Recipient:       recipient,
+			StorageUsed:     used,
+			StorageCapacity: capacity,
+		}
+	}
+
+	return nil
+}
+
+func decodeStatus(value cadence.Value) (map[string]uint64, error) {
+	dictionary, ok := value.(cadence.Dictionary)
+	if !ok {
+		return nil, fmt.Errorf("transfer: unexpected recipient status %T", value)
+	}
+
+	status := make(map[string]uint64, len(dictionary.Pairs))
+	for _, pair := range dictionary.Pairs {
+		key, ok := pair.Key.(cadence.String)
+		if !ok {
+			return nil, fmt.Errorf("transfer: unexpected recipient status key %T", pair.Key)
+		}
+
+		v, ok := pair.Value.(cadence.UInt64)
+		if !ok {
+			return nil, fmt.Errorf("transfer: unexpected recipient status value %T", pair.Value)
+		}
+
+		status[string(key)] = uint64(v)
+	}
+
+	for _, key := range []string{"receiver", "storageUsed", "storageCapacity"} {
+		if _, ok := status[key]; !ok {
+			return nil, fmt.Errorf("transfer: recipient status is missing %s", key)
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/parser2"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/client"
+	"github.com/portto/blocto-flow-go-sdk/transfer"
+)
+
+// statusClient returns a client whose scripts return the given recipient status.
+func statusClient(receiver bool, used, capacity uint64) *client.Client {
+	var hasReceiver uint64
+	if receiver {
+		hasReceiver = 1
+	}
+
+	status := cadence.NewDictionary([]cadence.KeyValuePair{
+		{Key: cadence.NewString("receiver"), Value: cadence.NewUInt64(hasReceiver)},
+		{Key: cadence.NewString("storageUsed"), Value: cadence.NewUInt64(used)},
+		{Key: cadence.NewString("storageCapacity"), Value: cadence.NewUInt64(capacity)},
+	})
+
+	invoker := client.InvokerFunc(func(
+		_ context.Context,
+		method string,
+		_ interface{},
+		reply interface{},
+		_ ...grpc.CallOption,
+	) error {
+		if method == client.MethodExecuteScriptAtLatestBlock {
+			reply.(*access.ExecuteScriptResponse).Value = jsoncdc.MustEncode(status)
+		}
+		return nil
+	})
+
+	return client.NewFromEndpoint(client.NewEndpoint("node", invoker))
+}
+
+func TestCheckTransferPreconditions(t *testing.T) {
+	ctx := context.Background()
+	recipient := flow.HexToAddress("01")
+
+	token, err := transfer.FlowToken(flow.Emulator)
+	require.NoError(t, err)
+
+	t.Run("Script", func(t *testing.T) {
+		code, err := transfer.RecipientStatusScript(token)
+		require.NoError(t, err)
+
+		_, err = parser2.ParseProgram(string(code))
+		require.NoError(t, err)
+		assert.Contains(t, string(code), "/public/flowTokenReceiver")
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		err := transfer.CheckTransferPreconditions(ctx, statusClient(true, 100, 1000), token, recipient, 500)
+		assert.NoError(t, err)
+	})
+
+	t.Run("No receiver", func(t *testing.T) {
+		err := transfer.CheckTransferPreconditions(ctx, statusClient(false, 100, 1000), token, recipient, 0)
+		assert.True(t, errors.Is(err, transfer.ErrNoReceiver))
+	})
+
+	t.Run("Insufficient storage", func(t *testing.T) {
+		err := transfer.CheckTransferPreconditions(ctx, statusClient(true, 900, 1000), token, recipient, 500)
+		require.True(t, errors.Is(err, transfer.ErrInsufficientStorage))
+
+		var preconditionErr *transfer.PreconditionError
+		require.True(t, errors.As(err, &preconditionErr))
+		assert.Equal(t, uint64(900), preconditionErr.StorageUsed)
+		assert.EqualError(t, err, "transfer: recipient has insufficient storage (0x0000000000000001 uses 900 of 1000 bytes)")
+	})
+}
@@ -0,0 +1,169 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transfer
+
+import (
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+
+	"github.com/portto/blocto-flow-go-sdk"
+	"github.com/portto/blocto-flow-go-sdk/templates/script"
+)
+
+const transferTokensTemplate = `
+transaction(amount: UFix64, to: Address) {
+  let sentVault: @FungibleToken.Vault
+
+  prepare(signer: AuthAccount) {
+    let vault = signer.borrow<&{{vaultType}}>(from: {{vaultPath}})
+      ?? panic("could not borrow reference to the sender vault")
+
+    self.sentVault <- vault.withdraw(amount: amount)
+  }
+
+  execute {
+    let receiver = getAccount(to).getCapability({{receiverPath}})
+      .borrow<&{FungibleToken.Receiver}>()
+      ?? panic("could not borrow reference to the recipient receiver")
+
+    receiver.deposit(from: <-self.sentVault)
+  }
+}
+`
+
+const setupVaultTemplate = `
+transaction {
+  prepare(signer: AuthAccount) {
+    if signer.borrow<&{{vaultType}}>(from: {{vaultPath}}) == nil {
+      signer.save(<-{{contract}}.createEmptyVault(), to: {{vaultPath}})
+    }
+
+    signer.unlink({{receiverPath}})
+    signer.link<&{{vaultType}}{FungibleToken.Receiver}>({{receiverPath}}, target: {{vaultPath}})
+
+    signer.unlink({{balancePath}})
+    signer.link<&{{vaultType}}{FungibleToken.Balance}>({{balancePath}}, target: {{vaultPath}})
+  }
+}
+`
+
+// build renders a template with the imports of this token and the named
+// placeholder values.
+func (t Token) build(template string, names ...string) ([]byte, error) {
+	all, err := t.values()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(script.Values, len(names))
+	for _, name := range names {
+		values[name] = all[name]
+	}
+
+	return script.NewBuilder().
+		Import("FungibleToken", t.FungibleTokenAddress).
+		Import(t.Contract, t.Address).
+		Body(script.Fragment{Source: template}, values).
+		Build()
+}
+
+// values returns the escaped identifiers and paths of this token.
+func (t Token) values() (script.Values, error) {
+	contract, err := script.Identifier(t.Contract)
+	if err != nil {
+		return nil, err
+	}
+
+	vaultPath, err := script.Path(script.PathDomainStorage, t.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	receiverPath, err := script.Path(script.PathDomainPublic, t.ReceiverPath)
+	if err != nil {
+		return nil, err
+	}
+
+	balancePath, err := script.Path(script.PathDomainPublic, t.BalancePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return script.Values{
+		"contract":     contract,
+		"vaultType":    contract + ".Vault",
+		"vaultPath":    vaultPath,
+		"receiverPath": receiverPath,
+		"balancePath":  balancePath,
+	}, nil
+}
+
+// TransferTokensScript returns the script of token transfer transactions.
+func TransferTokensScript(token Token) ([]byte, error) {
+	return token.build(transferTokensTemplate, "vaultType", "vaultPath", "receiverPath")
+}
+
+// SetupVaultScript returns the script of vault setup transactions.
+func SetupVaultScript(token Token) ([]byte, error) {
+	return token.build(
+		setupVaultTemplate,
+		"contract",
+		"vaultType",
+		"vaultPath",
+		"receiverPath",
+		"balancePath",
+	)
+}
+
+// TransferTokens generates a transaction that transfers an amount of tokens from the
+// sender to the recipient.
+//
+// The sender account must sign the resulting transaction.
+func TransferTokens(
+	token Token,
+	amount cadence.UFix64,
+	recipient flow.Address,
+	sender flow.Address,
+) (*flow.Transaction, error) {
+	code, err := TransferTokensScript(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return flow.NewTransaction().
+		SetScript(code).
+		AddRawArgument(jsoncdc.MustEncode(amount)).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewAddress(recipient))).
+		AddAuthorizer(sender), nil
+}
+
+// SetupVault generates a transaction that creates an empty vault for the token in the
+// account, if it has none, and links its receiver and balance capabilities.
+//
+// The account must sign the resulting transaction.
+func SetupVault(token Token, account flow.Address) (*flow.Transaction, error) {
+	code, err := SetupVaultScript(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return flow.NewTransaction().
+		SetScript(code).
+		AddAuthorizer(account), nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Flow Go SDK
+ *
+ * Copyright 2019-2020 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package transfer provides helpers for fungible token transfers: pre-flight
+// checks of the recipient account, recipient setup and transfer analysis.
+package transfer
+
+import (
+	"fmt"
+
+	"github.com/portto/blocto-flow-go-sdk"
+)
+
+// A Token is a fungible token contract and the storage conventions of its vaults.
+type Token struct {
+	// Contract is the name of the token contract, e.g. "FlowToken".
+	Contract string
+	// Address is the address of the token contract.
+	Address flow.Address
+	// FungibleTokenAddress is the address of the FungibleToken standard contract.
+	FungibleTokenAddress flow.Address
+	// VaultPath is the identifier of the storage path of vaults.
+	VaultPath string
+	// ReceiverPath is the identifier of the public path of the receiver capability.
+	ReceiverPath string
+	// BalancePath is the identifier of the public path of the balance capability.
+	BalancePath string
+}
+
+// VaultType returns the qualified type of the vaults of this token.
+func (t Token) VaultType() string {
+	return t.Contract + ".Vault"
+}
+
+// FlowToken returns the FLOW token on the given chain.
+func FlowToken(chain flow.ChainID) (Token, error) {
+	definition, ok := flow.LookupChain(chain)
+	if !ok {
+		return Token{}, fmt.Errorf("transfer: unknown chain %s", chain)
+	}
+
+	fungibleToken, ok := definition.Contract(flow.ContractFungibleToken)
+	if !ok {
+		return Token{}, fmt.Errorf("transfer: no %s contract on chain %s", flow.ContractFungibleToken, chain)
+	}
+
+	flowToken, ok := definition.Contract(flow.ContractFlowToken)
+	if !ok {
+		return Token{}, fmt.Errorf("transfer: no %s contract on chain %s", flow.ContractFlowToken, chain)
+	}
+
+	return Token{
+		Contract:             "FlowToken",
+		Address:              flowToken,
+		FungibleTokenAddress: fungibleToken,
+		VaultPath:            "flowTokenVault",
+		ReceiverPath:         "flowTokenReceiver",
+		BalancePath:          "flowTokenBalance",
+	}, nil
+}